@@ -0,0 +1,52 @@
+package bench
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/bigint"
+)
+
+// randomBigInt returns a random non-negative integer of exactly limbs
+// 64-bit limbs.
+func randomBigInt(rng *rand.Rand, limbs int) *bigint.Int {
+	words := make([]big.Word, limbs)
+	for i := range words {
+		words[i] = big.Word(rng.Uint64())
+	}
+	words[limbs-1] |= 1 << 63
+	x, err := bigint.Parse(new(big.Int).SetBits(words).String())
+	if err != nil {
+		panic(err)
+	}
+	return x
+}
+
+// BenchmarkBigIntMul multiplies equal-length operands by schoolbook and by
+// Karatsuba with several cutoffs below which it falls back to schoolbook.
+// Recursing all the way down is slowest of all, since each level allocates;
+// the cutoff whose line first beats schoolbook marks the crossover that
+// KaratsubaCutoff should sit near.
+func BenchmarkBigIntMul(b *testing.B) {
+	rng := rand.New(rand.NewSource(1))
+	for _, limbs := range []int{16, 32, 64, 128, 256, 1024} {
+		x, y := randomBigInt(rng, limbs), randomBigInt(rng, limbs)
+		b.Run(fmt.Sprintf("impl=schoolbook/limbs=%d", limbs), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				x.MulSchoolbook(y)
+			}
+		})
+		for _, cutoff := range []int{2, 16, 32, 64, 128} {
+			if cutoff > limbs {
+				continue
+			}
+			b.Run(fmt.Sprintf("impl=karatsuba/cutoff=%d/limbs=%d", cutoff, limbs), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					x.MulKaratsuba(y, cutoff)
+				}
+			})
+		}
+	}
+}
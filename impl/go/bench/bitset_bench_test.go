@@ -0,0 +1,78 @@
+package bench
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/bitset"
+)
+
+// BenchmarkDenseMembership compares a bitset with a builtin map[int]struct{}
+// for membership over a dense integer range: random lookups, counting, and
+// intersecting two half-full sets.
+func BenchmarkDenseMembership(b *testing.B) {
+	for _, size := range []int{10_000, 1_000_000} {
+		rng := rand.New(rand.NewSource(1))
+		x, y := bitset.MustNew(size), bitset.MustNew(size)
+		mx, my := make(map[int]struct{}), make(map[int]struct{})
+		for i := 0; i < size; i++ {
+			if rng.Intn(2) == 0 {
+				x.Set(i)
+				mx[i] = struct{}{}
+			}
+			if rng.Intn(2) == 0 {
+				y.Set(i)
+				my[i] = struct{}{}
+			}
+		}
+		probes := make([]int, 1<<16)
+		for i := range probes {
+			probes[i] = rng.Intn(size)
+		}
+
+		b.Run(fmt.Sprintf("op=contains/impl=bitset/size=%d", size), func(b *testing.B) {
+			hits := 0
+			for i := 0; i < b.N; i++ {
+				if x.Test(probes[i&(len(probes)-1)]) {
+					hits++
+				}
+			}
+			_ = hits
+		})
+		b.Run(fmt.Sprintf("op=contains/impl=map/size=%d", size), func(b *testing.B) {
+			hits := 0
+			for i := 0; i < b.N; i++ {
+				if _, ok := mx[probes[i&(len(probes)-1)]]; ok {
+					hits++
+				}
+			}
+			_ = hits
+		})
+
+		b.Run(fmt.Sprintf("op=intersect/impl=bitset/size=%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				z := x.Clone()
+				z.And(y)
+			}
+		})
+		b.Run(fmt.Sprintf("op=intersect/impl=map/size=%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				z := make(map[int]struct{})
+				for k := range mx {
+					if _, ok := my[k]; ok {
+						z[k] = struct{}{}
+					}
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("op=rank/impl=bitset/size=%d", size), func(b *testing.B) {
+			sum := 0
+			for i := 0; i < b.N; i++ {
+				sum += x.Rank(probes[i&(len(probes)-1)])
+			}
+			_ = sum
+		})
+	}
+}
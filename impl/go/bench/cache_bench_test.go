@@ -0,0 +1,67 @@
+package bench
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/cache"
+)
+
+// cacheTraces returns access traces of n lookups for a cache of the given
+// capacity: Zipf-skewed keys, a loop slightly larger than the cache (the
+// worst case for LRU), and Zipf traffic interrupted by one-off scans.
+func cacheTraces(n, capacity int) map[string][]string {
+	rng := rand.New(rand.NewSource(1))
+	zipf := rand.NewZipf(rng, 1.1, 1, uint64(100*capacity))
+	key := func(i uint64) string { return fmt.Sprint("key_", i) }
+
+	traces := map[string][]string{}
+	for i := 0; i < n; i++ {
+		traces["zipf"] = append(traces["zipf"], key(zipf.Uint64()))
+		traces["loop"] = append(traces["loop"], key(uint64(i%(capacity*3/2))))
+	}
+	scan := uint64(1 << 40)
+	for len(traces["zipf+scan"]) < n {
+		for i := 0; i < 4*capacity; i++ {
+			traces["zipf+scan"] = append(traces["zipf+scan"], key(zipf.Uint64()))
+		}
+		for i := 0; i < capacity; i++ {
+			traces["zipf+scan"] = append(traces["zipf+scan"], key(scan))
+			scan++
+		}
+	}
+	traces["zipf+scan"] = traces["zipf+scan"][:n]
+	return traces
+}
+
+// replayTrace looks up every key, inserting it on a miss as a read-through
+// cache would.
+func replayTrace(c cache.Cache, trace []string) {
+	for _, key := range trace {
+		if _, found := c.Get(key); !found {
+			c.Put(key, key)
+		}
+	}
+}
+
+// BenchmarkCacheHitRate replays each trace through every eviction policy and
+// reports the hit rate alongside the time per replay.
+func BenchmarkCacheHitRate(b *testing.B) {
+	for _, capacity := range []int{100, 1000} {
+		traces := cacheTraces(100_000, capacity)
+		for _, name := range []string{"zipf", "loop", "zipf+scan"} {
+			for _, p := range cache.Policies() {
+				b.Run(fmt.Sprintf("trace=%s/capacity=%d/policy=%s", name, capacity, p.Name), func(b *testing.B) {
+					var stats cache.Stats
+					for i := 0; i < b.N; i++ {
+						c := p.MustNew(capacity)
+						replayTrace(c, traces[name])
+						stats = c.Stats()
+					}
+					b.ReportMetric(stats.HitRate(), "hit-rate")
+				})
+			}
+		}
+	}
+}
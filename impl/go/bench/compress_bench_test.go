@@ -0,0 +1,68 @@
+package bench
+
+import (
+	"bytes"
+	"compress/flate"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/compress"
+)
+
+// BenchmarkCompress compares the lab's compressors with compress/flate on
+// workload JSON, log-like text and incompressible bytes. Besides throughput
+// (MB/s of input) it reports ratio, compressed over original size, so lower
+// is better. flate runs LZ77 with lazy matching plus dynamic Huffman codes
+// for lengths and distances; the gap in ratio to the lab's greedy LZ77 with
+// plain 15-bit distances is what those refinements buy. The gap in speed is
+// mostly the match finder's string-keyed hashmap lookup per position, which
+// flate replaces with an array indexed by a hash of the prefix; random input,
+// where nearly every prefix is new, shows it worst.
+func BenchmarkCompress(b *testing.B) {
+	json, err := os.ReadFile(filepath.Join("..", "..", "..", "workloads", "map", "mixed_zipf_medium.json"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	rng := rand.New(rand.NewSource(1))
+	paths := rand.NewZipf(rng, 1.1, 1, 999)
+	var logs bytes.Buffer
+	for i := 0; logs.Len() < 256<<10; i++ {
+		fmt.Fprintf(&logs, "10.0.%d.%d - - [16/Oct/2026:10:%02d:%02d +0000] \"GET /api/v2/resources/%d HTTP/1.1\" %d %d\n",
+			rng.Intn(4), rng.Intn(256), i/60%60, i%60, paths.Uint64(), []int{200, 200, 200, 304, 404}[rng.Intn(5)], rng.Intn(50_000))
+	}
+	random := make([]byte, 256<<10)
+	rng.Read(random)
+
+	impls := []struct {
+		name   string
+		encode func([]byte) []byte
+	}{
+		{"huffman", compress.HuffmanEncode},
+		{"lz77", compress.LZ77Encode},
+		{"flate", func(data []byte) []byte {
+			var buf bytes.Buffer
+			w, _ := flate.NewWriter(&buf, flate.DefaultCompression)
+			w.Write(data)
+			w.Close()
+			return buf.Bytes()
+		}},
+	}
+	for _, input := range []struct {
+		name string
+		data []byte
+	}{{"json", json}, {"logs", logs.Bytes()}, {"random", random}} {
+		for _, impl := range impls {
+			b.Run("input="+input.name+"/impl="+impl.name, func(b *testing.B) {
+				b.SetBytes(int64(len(input.data)))
+				var size int
+				for i := 0; i < b.N; i++ {
+					size = len(impl.encode(input.data))
+				}
+				b.ReportMetric(float64(size)/float64(len(input.data)), "ratio")
+			})
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package bench
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dsa-lab/go/internal/concurrent/rcu"
+	"github.com/dsa-lab/go/internal/concurrent/sharded"
+	"github.com/dsa-lab/go/internal/kv"
+)
+
+// concurrentWorkload is a mixed read/write load shared by several goroutines
+// over one key space. Each operation is a Get with probability ReadPct/100,
+// otherwise an Insert or Remove with equal odds, so the map stays near half
+// full.
+type concurrentWorkload struct {
+	ReadPct  int
+	KeySpace int
+}
+
+// run performs ops operations split across goroutines against m and returns
+// the wall-clock time they took.
+func (w concurrentWorkload) run(m kv.Map, keys []string, goroutines, ops int) time.Duration {
+	per := (ops + goroutines - 1) / goroutines
+	var wg sync.WaitGroup
+	start := time.Now()
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(seed))
+			for i := 0; i < per; i++ {
+				key := keys[rng.Intn(w.KeySpace)]
+				switch r := rng.Intn(100); {
+				case r < w.ReadPct:
+					m.Get(key)
+				case r%2 == 0:
+					m.Insert(key, "v")
+				default:
+					m.Remove(key)
+				}
+			}
+		}(int64(g))
+	}
+	wg.Wait()
+	return time.Since(start)
+}
+
+// BenchmarkConcurrentMaps drives each concurrency-safe map from 1 to 64
+// goroutines at several read ratios and reports throughput in ops/s plus the
+// speedup over the single-goroutine run of the same map and ratio, giving one
+// scaling curve per pair. The lock-free map is only included in builds with
+// the dsa_experimental tag.
+func BenchmarkConcurrentMaps(b *testing.B) {
+	const keySpace = 1 << 14
+	keys := make([]string, keySpace)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key_%d", i)
+	}
+
+	for _, name := range []string{"sharded", "syncmap", "lockfree"} {
+		impl, ok := kv.Lookup(name)
+		if !ok {
+			continue
+		}
+		for _, readPct := range []int{50, 90, 99} {
+			w := concurrentWorkload{ReadPct: readPct, KeySpace: keySpace}
+			var baseline float64
+			for _, goroutines := range []int{1, 2, 4, 8, 16, 32, 64} {
+				b.Run(fmt.Sprintf("impl=%s/reads=%d/goroutines=%d", name, readPct, goroutines), func(b *testing.B) {
+					m := impl.New()
+					for i := 0; i < keySpace/2; i++ {
+						m.Insert(keys[i], "v")
+					}
+					b.ResetTimer()
+					elapsed := w.run(m, keys, goroutines, b.N)
+					b.StopTimer()
+
+					throughput := float64(b.N) / elapsed.Seconds()
+					if goroutines == 1 {
+						baseline = throughput
+					}
+					b.ReportMetric(throughput, "ops/s")
+					if baseline > 0 {
+						b.ReportMetric(throughput/baseline, "speedup")
+					}
+				})
+			}
+		}
+	}
+}
+
+// BenchmarkReadMostlyMaps compares the copy-on-write rcu map, with and
+// without write batching, against the sharded map on 99%-read loads. rcu
+// reads take no lock but each write copies the whole table, so the outcome
+// turns on the key space as much as on the read ratio.
+func BenchmarkReadMostlyMaps(b *testing.B) {
+	impls := []struct {
+		name string
+		new  func() kv.Map
+	}{
+		{"sharded", func() kv.Map { return sharded.MustNew() }},
+		{"rcu", func() kv.Map { return rcu.New() }},
+		{"rcu_batched", func() kv.Map { return rcu.New(rcu.WithWriteBatching()) }},
+	}
+	for _, keySpace := range []int{1 << 10, 1 << 14} {
+		keys := make([]string, keySpace)
+		for i := range keys {
+			keys[i] = fmt.Sprintf("key_%d", i)
+		}
+		w := concurrentWorkload{ReadPct: 99, KeySpace: keySpace}
+		for _, impl := range impls {
+			for _, goroutines := range []int{1, 4, 16, 64} {
+				b.Run(fmt.Sprintf("impl=%s/keys=%d/goroutines=%d", impl.name, keySpace, goroutines), func(b *testing.B) {
+					m := impl.new()
+					for i := 0; i < keySpace/2; i++ {
+						m.Insert(keys[i], "v")
+					}
+					b.ResetTimer()
+					elapsed := w.run(m, keys, goroutines, b.N)
+					b.StopTimer()
+					b.ReportMetric(float64(b.N)/elapsed.Seconds(), "ops/s")
+				})
+			}
+		}
+	}
+}
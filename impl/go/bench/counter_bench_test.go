@@ -0,0 +1,71 @@
+package bench
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/concurrent/counter"
+)
+
+type benchCounter interface {
+	Add(delta int64)
+	Load() int64
+}
+
+type atomicCounter struct{ n atomic.Int64 }
+
+func (c *atomicCounter) Add(delta int64) { c.n.Add(delta) }
+func (c *atomicCounter) Load() int64     { return c.n.Load() }
+
+type mutexCounter struct {
+	mu sync.Mutex
+	n  int64
+}
+
+func (c *mutexCounter) Add(delta int64) {
+	c.mu.Lock()
+	c.n += delta
+	c.mu.Unlock()
+}
+
+func (c *mutexCounter) Load() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.n
+}
+
+// BenchmarkCounterContention increments a shared counter from every
+// goroutine of b.RunParallel, reading it back on a fraction of operations.
+// The single atomic and the mutex serialize on one cache line; the striped
+// counter scales with cores until reads, which sum every stripe, dominate.
+func BenchmarkCounterContention(b *testing.B) {
+	impls := []struct {
+		name string
+		new  func() benchCounter
+	}{
+		{"atomic", func() benchCounter { return &atomicCounter{} }},
+		{"mutex", func() benchCounter { return &mutexCounter{} }},
+		{"striped", func() benchCounter { return counter.MustNew() }},
+	}
+	for _, impl := range impls {
+		for _, readEvery := range []int{0, 100, 10} {
+			b.Run(fmt.Sprintf("impl=%s/read_every=%d", impl.name, readEvery), func(b *testing.B) {
+				c := impl.new()
+				b.SetParallelism(4)
+				b.RunParallel(func(pb *testing.PB) {
+					i := 0
+					for pb.Next() {
+						i++
+						if readEvery > 0 && i%readEvery == 0 {
+							c.Load()
+						} else {
+							c.Add(1)
+						}
+					}
+				})
+			})
+		}
+	}
+}
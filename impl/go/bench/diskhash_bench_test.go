@@ -0,0 +1,78 @@
+package bench
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/diskhash"
+	"github.com/dsa-lab/go/internal/hashmap"
+	"github.com/dsa-lab/go/internal/workload"
+)
+
+// replayDisk applies every operation in the workload to d.
+func replayDisk(b *testing.B, d *diskhash.DiskHash, ops []workload.Operation) {
+	for _, op := range ops {
+		var err error
+		switch op.Op {
+		case workload.OpInsert:
+			_, _, err = d.Insert(op.Key, op.Value)
+		case workload.OpGet:
+			_, _, err = d.Get(op.Key)
+		case workload.OpDelete:
+			_, _, err = d.Remove(op.Key)
+		}
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func removeIndex(path string) {
+	os.Remove(path)
+	os.Remove(path + ".wal")
+}
+
+// BenchmarkDiskHashLarge compares the on-disk index with the in-memory
+// hashmap on the large workloads. The disk index starts from an empty file
+// each iteration and is closed (checkpointed) before the timer stops, so the
+// figures include the cost of making the final state durable.
+func BenchmarkDiskHashLarge(b *testing.B) {
+	for _, name := range []string{"insert_heavy_uniform_large", "mixed_uniform_large", "delete_heavy_zipf_large"} {
+		w, ok := loadWorkload(b, name)
+		if !ok {
+			b.Logf("skipping %s: not generated", name)
+			continue
+		}
+		perOp := func(b *testing.B) {
+			b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*len(w.Operations)), "ns/workload-op")
+		}
+
+		b.Run("workload="+name+"/store=memory", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				replay(hashmap.MustNew(), w.Operations)
+			}
+			perOp(b)
+		})
+
+		b.Run("workload="+name+"/store=disk", func(b *testing.B) {
+			dir := b.TempDir()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				path := filepath.Join(dir, "index.db")
+				removeIndex(path)
+				b.StartTimer()
+
+				d, err := diskhash.Open(path)
+				if err != nil {
+					b.Fatal(err)
+				}
+				replayDisk(b, d, w.Operations)
+				if err := d.Close(); err != nil {
+					b.Fatal(err)
+				}
+			}
+			perOp(b)
+		})
+	}
+}
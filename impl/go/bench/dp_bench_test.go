@@ -0,0 +1,59 @@
+package bench
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/dp"
+)
+
+// BenchmarkDP runs each dynamic program bottom-up and top-down on the same
+// input. Both visit about the same states here, so the difference is the
+// memo table: every lookup encodes a key, hashes it and parses a decimal
+// value, where the bottom-up table is a slice index. Expect the memoized
+// versions to run 40 to 400 times slower and allocate on every state.
+func BenchmarkDP(b *testing.B) {
+	rng := rand.New(rand.NewSource(1))
+	text := func(n int) string {
+		s := make([]byte, n)
+		for i := range s {
+			s[i] = "acgt"[rng.Intn(4)]
+		}
+		return string(s)
+	}
+	x, y := text(300), text(300)
+	items := make([]dp.Item, 100)
+	for i := range items {
+		items[i] = dp.Item{Weight: 1 + rng.Intn(50), Value: rng.Intn(100)}
+	}
+	dims := make([]int, 61)
+	for i := range dims {
+		dims[i] = 1 + rng.Intn(100)
+	}
+
+	cases := []struct {
+		name     string
+		bottomUp func()
+		topDown  func()
+	}{
+		{"edit", func() { dp.EditDistance(x, y) }, func() { dp.EditDistanceMemo(x, y) }},
+		{"lcs", func() { dp.LCSLength(x, y) }, func() { dp.LCSLengthMemo(x, y) }},
+		{"knapsack", func() { dp.Knapsack(items, 1000) }, func() { dp.KnapsackMemo(items, 1000) }},
+		{"chain", func() { dp.MatrixChain(dims) }, func() { dp.MatrixChainMemo(dims) }},
+	}
+	for _, c := range cases {
+		b.Run(fmt.Sprintf("problem=%s/impl=bottom-up", c.name), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				c.bottomUp()
+			}
+		})
+		b.Run(fmt.Sprintf("problem=%s/impl=memo", c.name), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				c.topDown()
+			}
+		})
+	}
+}
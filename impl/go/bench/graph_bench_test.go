@@ -0,0 +1,213 @@
+package bench
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/graph"
+	"github.com/dsa-lab/go/internal/heap"
+	"github.com/dsa-lab/go/internal/order"
+)
+
+// benchGraphs returns the synthetic inputs of the graph benchmarks, about
+// 250k vertices each with random weights in [1, 100], plus the DIMACS file
+// named by DSA_GRAPH, such as a road network from the 9th DIMACS challenge,
+// when set.
+func benchGraphs(b *testing.B) map[string]*graph.Graph {
+	rng := rand.New(rand.NewSource(1))
+	graphs := map[string]*graph.Graph{
+		"er":   graph.ErdosRenyi(250_000, 8.0/250_000, true, rng),
+		"ba":   graph.BarabasiAlbert(250_000, 4, rng),
+		"grid": graph.Grid(500, 500),
+	}
+	if path := os.Getenv("DSA_GRAPH"); path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			b.Fatal(err)
+		}
+		defer f.Close()
+		g, _, err := graph.ReadDIMACS(f)
+		if err != nil {
+			b.Fatalf("%s: %v", path, err)
+		}
+		graphs["file"] = g
+	}
+	for name, g := range graphs {
+		if name != "file" {
+			g.Reweight(func(graph.Edge) int64 { return 1 + rng.Int63n(100) })
+		}
+	}
+	return graphs
+}
+
+// dijkstraKeyed computes distances from source with a keyed heap holding
+// each vertex at most once and lowering its priority in place.
+func dijkstraKeyed(g *graph.Graph, source int) []int64 {
+	dist := make([]int64, g.NumVertices())
+	for i := range dist {
+		dist[i] = math.MaxInt64
+	}
+	dist[source] = 0
+	h := heap.NewKeyedOrdered[int, int64]()
+	h.Push(source, 0)
+	for h.Len() > 0 {
+		u, d, _ := h.Pop()
+		for _, e := range g.Neighbors(u) {
+			if nd := d + e.Weight; nd < dist[e.To] {
+				dist[e.To] = nd
+				h.Push(e.To, nd)
+			}
+		}
+	}
+	return dist
+}
+
+// dijkstraLazy computes distances from source with a plain binary heap,
+// pushing a vertex again on every improvement and skipping stale entries.
+func dijkstraLazy(g *graph.Graph, source int) []int64 {
+	type item struct {
+		v int
+		d int64
+	}
+	dist := make([]int64, g.NumVertices())
+	for i := range dist {
+		dist[i] = math.MaxInt64
+	}
+	dist[source] = 0
+	h := heap.New(order.By(func(x item) int64 { return x.d }))
+	h.Push(item{source, 0})
+	for h.Len() > 0 {
+		it, _ := h.Pop()
+		if it.d > dist[it.v] {
+			continue
+		}
+		for _, e := range g.Neighbors(it.v) {
+			if nd := it.d + e.Weight; nd < dist[e.To] {
+				dist[e.To] = nd
+				h.Push(item{e.To, nd})
+			}
+		}
+	}
+	return dist
+}
+
+// BenchmarkShortestPaths runs single-source Dijkstra on a sparse random
+// graph, a scale-free graph and a grid, comparing the keyed heap's
+// decrease-key with the lazy binary heap that tolerates duplicates.
+func BenchmarkShortestPaths(b *testing.B) {
+	algorithms := map[string]func(*graph.Graph, int) []int64{
+		"keyed": dijkstraKeyed,
+		"lazy":  dijkstraLazy,
+	}
+	for name, g := range benchGraphs(b) {
+		g.Neighbors(0) // build the adjacency index outside the timer
+		for _, algo := range []string{"keyed", "lazy"} {
+			b.Run("graph="+name+"/heap="+algo, func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					algorithms[algo](g, i%g.NumVertices())
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkGraphFormats writes each synthetic graph in every supported
+// format and measures reading it back; MB/s is the parse rate.
+func BenchmarkGraphFormats(b *testing.B) {
+	type format struct {
+		write func(*bytes.Buffer, *graph.Graph) error
+		read  func(*bytes.Reader, *graph.Graph) error
+	}
+	formats := map[string]format{
+		"edgelist": {
+			func(w *bytes.Buffer, g *graph.Graph) error { return graph.WriteEdgeList(w, g) },
+			func(r *bytes.Reader, g *graph.Graph) error {
+				_, err := graph.ReadEdgeList(r, g.Directed())
+				return err
+			},
+		},
+		"dimacs": {
+			func(w *bytes.Buffer, g *graph.Graph) error {
+				return graph.WriteDIMACS(w, g, graph.DIMACS{Source: -1, Sink: -1})
+			},
+			func(r *bytes.Reader, _ *graph.Graph) error {
+				_, _, err := graph.ReadDIMACS(r)
+				return err
+			},
+		},
+		"json": {
+			func(w *bytes.Buffer, g *graph.Graph) error { return graph.WriteJSON(w, g) },
+			func(r *bytes.Reader, _ *graph.Graph) error {
+				_, err := graph.ReadJSON(r)
+				return err
+			},
+		},
+	}
+	for name, g := range benchGraphs(b) {
+		for _, fname := range []string{"edgelist", "dimacs", "json"} {
+			f := formats[fname]
+			var buf bytes.Buffer
+			if err := f.write(&buf, g); err != nil {
+				b.Fatal(err)
+			}
+			b.Run("graph="+name+"/format="+fname, func(b *testing.B) {
+				b.SetBytes(int64(buf.Len()))
+				for i := 0; i < b.N; i++ {
+					if err := f.read(bytes.NewReader(buf.Bytes()), g); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkMaxFlow compares Dinic with FIFO push-relabel on a sparse random
+// network and a grid with random capacities, from vertex 0 to the last
+// vertex, and on unit-capacity bipartite matching.
+func BenchmarkMaxFlow(b *testing.B) {
+	rng := rand.New(rand.NewSource(1))
+	networks := map[string]*graph.Graph{
+		"er":   graph.ErdosRenyi(20_000, 8.0/20_000, true, rng),
+		"grid": graph.Grid(150, 150),
+	}
+	for _, g := range networks {
+		g.Reweight(func(graph.Edge) int64 { return 1 + rng.Int63n(100) })
+	}
+	matching := graph.New(20_000, true)
+	for l := 0; l < 10_000; l++ {
+		for i := 0; i < 4; i++ {
+			matching.AddEdge(l, 10_000+rng.Intn(10_000), 1)
+		}
+	}
+	// The matching network gets its source and sink as two extra vertices.
+	s, t := matching.AddVertex(), matching.AddVertex()
+	for v := 0; v < 10_000; v++ {
+		matching.AddEdge(s, v, 1)
+		matching.AddEdge(10_000+v, t, 1)
+	}
+	networks["matching"] = matching
+
+	algorithms := map[string]func(*graph.Graph, int, int) *graph.Flow{
+		"dinic":       graph.Dinic,
+		"pushrelabel": graph.PushRelabel,
+	}
+	for name, g := range networks {
+		s, t := 0, g.NumVertices()-1
+		if name == "matching" {
+			s, t = g.NumVertices()-2, g.NumVertices()-1
+		}
+		for _, algo := range []string{"dinic", "pushrelabel"} {
+			b.Run("network="+name+"/algo="+algo, func(b *testing.B) {
+				var value int64
+				for i := 0; i < b.N; i++ {
+					value = algorithms[algo](g, s, t).Value
+				}
+				b.ReportMetric(float64(value), "flow")
+			})
+		}
+	}
+}
@@ -1,156 +1,403 @@
 package bench
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/dsa-lab/go/internal/hashmap"
+	"github.com/dsa-lab/go/internal/kv"
+	"github.com/dsa-lab/go/internal/streaming"
+	"github.com/dsa-lab/go/internal/workload"
 )
 
-type Operation struct {
-	Op    string `json:"op"`
-	Key   string `json:"key"`
-	Value string `json:"value,omitempty"`
-}
-
-type Workload struct {
-	Name       string      `json:"name"`
-	Size       int         `json:"size"`
-	Operations []Operation `json:"operations"`
+// loadWorkload loads the named map workload, returning false when it has not
+// been generated. A workload that exists but breaks the schema fails the
+// benchmark, since replay would silently skip the operations it does not
+// understand.
+func loadWorkload(b *testing.B, name string) (*workload.Workload, bool) {
+	path, err := workloadPath(name)
+	if err != nil {
+		return nil, false
+	}
+	w, err := workload.Load(path)
+	if err != nil {
+		b.Fatalf("%s: %v", path, err)
+	}
+	if problems := workload.SchemaV2.Validate(w); problems != nil {
+		b.Fatalf("%s: %v (run cmd/workloadcheck for the full list)", path, problems[0])
+	}
+	return w, true
 }
 
-func loadWorkload(name string) (*Workload, error) {
-	// Try multiple paths
+// workloadPath finds the named map workload from either the module root or
+// the bench directory.
+func workloadPath(name string) (string, error) {
 	paths := []string{
 		filepath.Join("..", "..", "workloads", "map", name+".json"),
 		filepath.Join("..", "..", "..", "workloads", "map", name+".json"),
 	}
-
-	var data []byte
 	var err error
 	for _, path := range paths {
-		data, err = os.ReadFile(path)
-		if err == nil {
-			break
+		if _, err = os.Stat(path); err == nil {
+			return path, nil
 		}
 	}
-	if err != nil {
-		return nil, err
-	}
+	return "", err
+}
+
+func BenchmarkInsert(b *testing.B) {
+	sizes := []int{100, 1000, 10000}
 
-	var workload Workload
-	if err := json.Unmarshal(data, &workload); err != nil {
-		return nil, err
+	for _, impl := range kv.Implementations() {
+		for _, size := range sizes {
+			keys := make([]string, size)
+			values := make([]string, size)
+			for i := 0; i < size; i++ {
+				keys[i] = fmt.Sprintf("key_%d", i)
+				values[i] = fmt.Sprintf("value_%d", i)
+			}
+
+			b.Run(fmt.Sprintf("impl=%s/size=%d", impl.Name, size), func(b *testing.B) {
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					m := impl.New()
+					for j := 0; j < size; j++ {
+						m.Insert(keys[j], values[j])
+					}
+				}
+			})
+		}
 	}
-	return &workload, nil
 }
 
-func BenchmarkInsert(b *testing.B) {
+func BenchmarkGet(b *testing.B) {
 	sizes := []int{100, 1000, 10000}
 
-	for _, size := range sizes {
-		keys := make([]string, size)
-		values := make([]string, size)
-		for i := 0; i < size; i++ {
-			keys[i] = fmt.Sprintf("key_%d", i)
-			values[i] = fmt.Sprintf("value_%d", i)
+	for _, impl := range kv.Implementations() {
+		for _, size := range sizes {
+			keys := make([]string, size)
+			m := impl.New()
+			for i := 0; i < size; i++ {
+				keys[i] = fmt.Sprintf("key_%d", i)
+				m.Insert(keys[i], fmt.Sprintf("value_%d", i))
+			}
+
+			b.Run(fmt.Sprintf("impl=%s/size=%d", impl.Name, size), func(b *testing.B) {
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					for _, key := range keys {
+						m.Get(key)
+					}
+				}
+			})
 		}
+	}
+}
 
-		b.Run(fmt.Sprintf("size=%d", size), func(b *testing.B) {
+// BenchmarkInsertMaxLatency reports the latency distribution of single
+// Inserts while growing a map, up to the worst one observed, comparing
+// stop-the-world resizing with incremental rehashing.
+func BenchmarkInsertMaxLatency(b *testing.B) {
+	const size = 100000
+	keys := make([]string, size)
+	for i := 0; i < size; i++ {
+		keys[i] = fmt.Sprintf("key_%d", i)
+	}
+
+	modes := []struct {
+		name string
+		opts []hashmap.Option
+	}{
+		{"mode=full", nil},
+		{"mode=incremental", []hashmap.Option{hashmap.WithIncrementalResize(4)}},
+	}
+
+	for _, mode := range modes {
+		b.Run(mode.name, func(b *testing.B) {
+			lat := newLatencies()
 			b.ResetTimer()
 			for i := 0; i < b.N; i++ {
-				m := hashmap.New()
-				for j := 0; j < size; j++ {
-					m.Insert(keys[j], values[j])
+				m := hashmap.MustNew(mode.opts...)
+				for _, key := range keys {
+					start := time.Now()
+					m.Insert(key, key)
+					lat.record(time.Since(start))
 				}
 			}
+			b.StopTimer()
+			lat.report(b, "insert")
 		})
 	}
 }
 
-func BenchmarkGet(b *testing.B) {
-	sizes := []int{100, 1000, 10000}
-
-	for _, size := range sizes {
-		keys := make([]string, size)
-		m := hashmap.New()
-		for i := 0; i < size; i++ {
-			keys[i] = fmt.Sprintf("key_%d", i)
-			m.Insert(keys[i], fmt.Sprintf("value_%d", i))
+// BenchmarkResizeCachedHashes replays the inserts of the 10k-operation
+// insert-heavy workload and reports the time spent in the inserts that
+// doubled the table, with and without per-entry cached hashes.
+func BenchmarkResizeCachedHashes(b *testing.B) {
+	w, ok := loadWorkload(b, "insert_heavy_uniform_medium")
+	if !ok {
+		b.Skip("workload not generated")
+	}
+	var inserts []workload.Operation
+	for _, op := range w.Operations {
+		if op.Op == workload.OpInsert {
+			inserts = append(inserts, op)
 		}
+	}
 
-		b.Run(fmt.Sprintf("size=%d", size), func(b *testing.B) {
+	modes := []struct {
+		name string
+		opts []hashmap.Option
+	}{
+		{"hashes=recomputed", nil},
+		{"hashes=cached", []hashmap.Option{hashmap.WithCachedHashes()}},
+	}
+
+	for _, mode := range modes {
+		b.Run(mode.name, func(b *testing.B) {
+			var resizeTime time.Duration
 			b.ResetTimer()
 			for i := 0; i < b.N; i++ {
-				for _, key := range keys {
-					m.Get(key)
+				m := hashmap.MustNew(mode.opts...)
+				for _, op := range inserts {
+					capacity := m.Capacity()
+					start := time.Now()
+					m.Insert(op.Key, op.Value)
+					if m.Capacity() != capacity {
+						resizeTime += time.Since(start)
+					}
 				}
 			}
+			b.ReportMetric(float64(resizeTime.Nanoseconds())/float64(b.N), "resize-ns/op")
 		})
 	}
 }
 
-func BenchmarkMixedUniformMedium(b *testing.B) {
-	workload, err := loadWorkload("mixed_uniform_medium")
-	if err != nil {
-		b.Skip("workload not found:", err)
-		return
-	}
-
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		m := hashmap.New()
-		for _, op := range workload.Operations {
-			switch op.Op {
-			case "insert":
-				m.Insert(op.Key, op.Value)
-			case "get":
-				m.Get(op.Key)
-			case "delete":
-				m.Remove(op.Key)
+// BenchmarkBulkLoad builds a map from the inserts of the 10k and 100k
+// insert-heavy workloads, by inserting them one at a time into a growing
+// table, into a presized one, and with hashmap.BulkLoad on GOMAXPROCS
+// goroutines. The bulk variant also reports its speedup over the median of
+// a few sequential builds.
+func BenchmarkBulkLoad(b *testing.B) {
+	for _, name := range []string{"insert_heavy_uniform_medium", "insert_heavy_uniform_large"} {
+		w, ok := loadWorkload(b, name)
+		if !ok {
+			b.Skip("workload not generated")
+		}
+		var pairs []hashmap.Pair
+		for _, op := range w.Operations {
+			if op.Op == workload.OpInsert {
+				pairs = append(pairs, hashmap.Pair{Key: op.Key, Value: op.Value})
 			}
 		}
+		insert := func(m *hashmap.HashMap) *hashmap.HashMap {
+			for _, p := range pairs {
+				m.Insert(p.Key, p.Value)
+			}
+			return m
+		}
+		b.Run("workload="+name+"/mode=insert", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				insert(hashmap.MustNew())
+			}
+		})
+		b.Run("workload="+name+"/mode=presized", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				insert(hashmap.MustNewWithCapacity(len(pairs)*4/3 + 1))
+			}
+		})
+		b.Run("workload="+name+"/mode=bulk", func(b *testing.B) {
+			sequential := streaming.NewMedianOrdered[time.Duration]()
+			for i := 0; i < 5; i++ {
+				start := time.Now()
+				insert(hashmap.MustNew())
+				sequential.Add(time.Since(start))
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				hashmap.MustBulkLoad(pairs)
+			}
+			b.StopTimer()
+			median, _, _ := sequential.Median()
+			b.ReportMetric(float64(median.Nanoseconds())/(float64(b.Elapsed().Nanoseconds())/float64(b.N)), "x-vs-insert")
+		})
 	}
 }
 
-func BenchmarkInsertHeavyUniformMedium(b *testing.B) {
-	workload, err := loadWorkload("insert_heavy_uniform_medium")
-	if err != nil {
-		b.Skip("workload not found:", err)
-		return
+// replay applies every operation in the workload to m.
+func replay(m kv.Map, ops []workload.Operation) {
+	for _, op := range ops {
+		switch op.Op {
+		case workload.OpInsert:
+			m.Insert(op.Key, op.Value)
+		case workload.OpGet:
+			m.Get(op.Key)
+		case workload.OpDelete:
+			m.Remove(op.Key)
+		}
+	}
+}
+
+// benchmarkWorkload replays the named workload against every registered
+// implementation, skipping when the workload file has not been generated.
+func benchmarkWorkload(b *testing.B, name string) {
+	workload, ok := loadWorkload(b, name)
+	if !ok {
+		b.Skip("workload not generated")
+	}
+
+	for _, impl := range kv.Implementations() {
+		b.Run("impl="+impl.Name, func(b *testing.B) {
+			mem := startMemProfile(b)
+			mem.peakHeap(func() any {
+				m := impl.New()
+				replay(m, workload.Operations)
+				return m
+			})
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				replay(impl.New(), workload.Operations)
+			}
+			b.StopTimer()
+			mem.stop(b)
+		})
 	}
+}
 
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		m := hashmap.New()
-		for _, op := range workload.Operations {
-			if op.Op == "insert" {
-				m.Insert(op.Key, op.Value)
+// BenchmarkTinyMaps builds many maps of a few entries each and reads every
+// entry back once, the pattern of per-record attribute maps, where creating
+// the table costs more than using it. Sizes straddle smallmap's promotion
+// threshold of eight entries.
+func BenchmarkTinyMaps(b *testing.B) {
+	const maps = 1000
+	for _, size := range []int{2, 8, 16} {
+		keys := make([]string, size)
+		for i := range keys {
+			keys[i] = fmt.Sprintf("attr_%d", i)
+		}
+		build := func(impl kv.Implementation) []kv.Map {
+			ms := make([]kv.Map, maps)
+			for i := range ms {
+				m := impl.New()
+				for _, key := range keys {
+					m.Insert(key, key)
+				}
+				for _, key := range keys {
+					m.Get(key)
+				}
+				ms[i] = m
 			}
+			return ms
+		}
+		for _, impl := range kv.Implementations() {
+			b.Run(fmt.Sprintf("impl=%s/size=%d", impl.Name, size), func(b *testing.B) {
+				mem := startMemProfile(b)
+				mem.peakHeap(func() any { return build(impl) })
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					build(impl)
+				}
+				b.StopTimer()
+				mem.stop(b)
+			})
 		}
 	}
 }
 
+func BenchmarkMixedUniformMedium(b *testing.B) {
+	benchmarkWorkload(b, "mixed_uniform_medium")
+}
+
+func BenchmarkInsertHeavyUniformMedium(b *testing.B) {
+	benchmarkWorkload(b, "insert_heavy_uniform_medium")
+}
+
 func BenchmarkReadHeavyUniformMedium(b *testing.B) {
-	workload, err := loadWorkload("read_heavy_uniform_medium")
+	benchmarkWorkload(b, "read_heavy_uniform_medium")
+}
+
+func BenchmarkTraceURLShortener(b *testing.B) {
+	benchmarkWorkload(b, "trace_urlshortener")
+}
+
+// BenchmarkExternalWorkload replays a real-world key stream named by the
+// DSA_WORKLOAD environment variable: a JSON workload, a CSV file or a
+// line-delimited list of keys (see workload.Open). DSA_WORKLOAD_COLUMNS maps
+// CSV columns, e.g. "op=0,key=1". It is skipped when DSA_WORKLOAD is unset.
+func BenchmarkExternalWorkload(b *testing.B) {
+	path := os.Getenv("DSA_WORKLOAD")
+	if path == "" {
+		b.Skip("DSA_WORKLOAD not set")
+	}
+	var w *workload.Workload
+	var err error
+	if spec := os.Getenv("DSA_WORKLOAD_COLUMNS"); spec != "" {
+		in := workload.DefaultIngest(path)
+		if err := in.ParseColumns(spec); err != nil {
+			b.Fatal(err)
+		}
+		w, err = in.Load(path)
+	} else {
+		w, err = workload.Open(path)
+	}
 	if err != nil {
-		b.Skip("workload not found:", err)
-		return
-	}
-
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		m := hashmap.New()
-		for _, op := range workload.Operations {
-			switch op.Op {
-			case "insert":
-				m.Insert(op.Key, op.Value)
-			case "get":
-				m.Get(op.Key)
+		b.Fatal(err)
+	}
+
+	for _, impl := range kv.Implementations() {
+		b.Run("impl="+impl.Name, func(b *testing.B) {
+			mem := startMemProfile(b)
+			mem.peakHeap(func() any {
+				m := impl.New()
+				replay(m, w.Operations)
+				return m
+			})
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				replay(impl.New(), w.Operations)
 			}
+			b.StopTimer()
+			mem.stop(b)
+			b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*len(w.Operations)), "ns/workload-op")
+		})
+	}
+}
+
+// BenchmarkRangeScan replays the range-scan workloads against every
+// registered implementation that supports ordered scans.
+func BenchmarkRangeScan(b *testing.B) {
+	for _, size := range []string{"small", "medium"} {
+		w, err := workload.Load(filepath.Join("..", "..", "..", "workloads", "range", "scan_uniform_"+size+".json"))
+		if err != nil {
+			b.Skip("workload not found:", err)
+			return
+		}
+		for _, impl := range kv.Implementations() {
+			if _, ok := impl.New().(kv.Scanner); !ok {
+				continue
+			}
+			b.Run(fmt.Sprintf("impl=%s/size=%s", impl.Name, size), func(b *testing.B) {
+				run := func() any {
+					m := impl.New()
+					for _, op := range w.Operations {
+						if _, err := workload.Apply(m, op); err != nil {
+							b.Fatal(err)
+						}
+					}
+					return m
+				}
+				mem := startMemProfile(b)
+				mem.peakHeap(run)
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					run()
+				}
+				b.StopTimer()
+				mem.stop(b)
+			})
 		}
 	}
 }
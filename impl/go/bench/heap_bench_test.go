@@ -0,0 +1,83 @@
+package bench
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/heap"
+)
+
+// meldQueue is a priority queue that can absorb another of its kind.
+type meldQueue interface {
+	Push(x int)
+	Len() int
+	meld(other meldQueue)
+}
+
+type leftistQueue struct{ *heap.Leftist[int] }
+
+func (q leftistQueue) meld(other meldQueue) { q.Meld(other.(leftistQueue).Leftist) }
+
+type skewQueue struct{ *heap.Skew[int] }
+
+func (q skewQueue) meld(other meldQueue) { q.Meld(other.(skewQueue).Skew) }
+
+// binaryQueue merges the only way a binary heap can: popping every element
+// of one queue and pushing it into the other.
+type binaryQueue struct{ *heap.Heap[int] }
+
+func (q binaryQueue) meld(other meldQueue) {
+	o := other.(binaryQueue)
+	for o.Len() > 0 {
+		x, _ := o.Pop()
+		q.Push(x)
+	}
+}
+
+// BenchmarkMeld builds k queues of m random elements each, outside the
+// timer, and then melds them pairwise, round after round, until one queue
+// holds all k*m, as an external merge or a work-stealing scheduler folding
+// per-worker queues does. ns/op is the cost of merging all k queues. The
+// binary heap moves one queue of each pair element by element, so
+// it does O(n log n) work per round to the meldable heaps' O(k log n).
+func BenchmarkMeld(b *testing.B) {
+	impls := []struct {
+		name string
+		new  func() meldQueue
+	}{
+		{"leftist", func() meldQueue { return leftistQueue{heap.NewLeftistOrdered[int]()} }},
+		{"skew", func() meldQueue { return skewQueue{heap.NewSkewOrdered[int]()} }},
+		{"binary", func() meldQueue { return binaryQueue{heap.NewOrdered[int]()} }},
+	}
+	for _, shape := range []struct{ k, m int }{{1024, 16}, {64, 1024}} {
+		for _, impl := range impls {
+			b.Run(fmt.Sprintf("impl=%s/k=%d/m=%d", impl.name, shape.k, shape.m), func(b *testing.B) {
+				rng := rand.New(rand.NewSource(1))
+				queues := make([]meldQueue, shape.k)
+				for i := 0; i < b.N; i++ {
+					b.StopTimer()
+					for q := range queues {
+						queues[q] = impl.new()
+						for j := 0; j < shape.m; j++ {
+							queues[q].Push(rng.Int())
+						}
+					}
+					b.StartTimer()
+					for live := shape.k; live > 1; live = (live + 1) / 2 {
+						for q := 0; q+1 < live; q += 2 {
+							queues[q].meld(queues[q+1])
+							queues[q/2] = queues[q]
+						}
+						if live%2 == 1 {
+							queues[live/2] = queues[live-1]
+						}
+					}
+					if queues[0].Len() != shape.k*shape.m {
+						b.Fatalf("expected %d elements, got %d", shape.k*shape.m, queues[0].Len())
+					}
+				}
+			})
+		}
+	}
+}
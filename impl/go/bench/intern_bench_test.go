@@ -0,0 +1,119 @@
+package bench
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/hashmap"
+	"github.com/dsa-lab/go/internal/intern"
+	"github.com/dsa-lab/go/internal/symtab"
+)
+
+// BenchmarkInterning replays a parsed access log, where every key and value
+// is a fresh allocation, into eight per-window maps as a rolling aggregation
+// would. Keys are request paths, Zipf-distributed over 20k resources so hot
+// paths appear in every window, and values are one of 64 user agents. The
+// retained-B metric is what the maps keep alive: without interning each
+// window holds its own copy of every hot path and every entry its own agent
+// string. The interner's table costs roughly 50 bytes per distinct string,
+// so interning short strings that rarely repeat does not pay.
+func BenchmarkInterning(b *testing.B) {
+	const (
+		users   = 20_000
+		windows = 8
+	)
+	rng := rand.New(rand.NewSource(1))
+	keys := rand.NewZipf(rng, 1.1, 1, users-1)
+	agents := rand.NewZipf(rng, 1.1, 1, 63)
+	type record struct{ key, value []byte }
+	stream := make([]record, 200_000)
+	for i := range stream {
+		stream[i] = record{
+			key:   []byte("/api/v2/resources/" + strconv.FormatUint(keys.Uint64(), 10) + "/summary"),
+			value: []byte(fmt.Sprintf("Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 Chrome/%d.0", 60+agents.Uint64())),
+		}
+	}
+
+	for _, interned := range []bool{false, true} {
+		b.Run(fmt.Sprintf("interned=%v", interned), func(b *testing.B) {
+			build := func() any {
+				var opts []hashmap.Option
+				if interned {
+					opts = append(opts, hashmap.WithInterner(intern.New()))
+				}
+				maps := make([]*hashmap.HashMap, windows)
+				for w := range maps {
+					maps[w] = hashmap.MustNew(opts...)
+				}
+				per := len(stream) / windows
+				for i, r := range stream {
+					// string() copies, as decoding a log line would.
+					maps[i/per%windows].Insert(string(r.key), string(r.value))
+				}
+				return maps
+			}
+			mem := startMemProfile(b)
+			mem.peakHeap(build)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				build()
+			}
+			b.StopTimer()
+			mem.stop(b)
+		})
+	}
+}
+
+// BenchmarkSymbolTable decodes the key stream of a graph, an edge list over
+// 50k node names with Zipf-distributed endpoints, and keeps it as a replay or
+// graph build would. Holding each endpoint as a decoded string keeps one copy
+// per occurrence; holding IDs keeps four bytes per occurrence plus one arena
+// copy of each distinct name. Lookups hash every name with SipHash, so the
+// symbol table is also the slower of the two to build.
+func BenchmarkSymbolTable(b *testing.B) {
+	const nodes = 50_000
+	rng := rand.New(rand.NewSource(1))
+	pick := rand.NewZipf(rng, 1.2, 1, nodes-1)
+	stream := make([][]byte, 500_000)
+	for i := range stream {
+		stream[i] = []byte("host-" + strconv.FormatUint(pick.Uint64(), 10) + ".cluster.example.internal")
+	}
+
+	b.Run("repr=strings", func(b *testing.B) {
+		build := func() any {
+			edges := make([]string, len(stream))
+			for i, name := range stream {
+				edges[i] = string(name)
+			}
+			return edges
+		}
+		mem := startMemProfile(b)
+		mem.peakHeap(build)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			build()
+		}
+		b.StopTimer()
+		mem.stop(b)
+	})
+	b.Run("repr=symtab", func(b *testing.B) {
+		build := func() any {
+			tab := symtab.New()
+			edges := make([]symtab.ID, len(stream))
+			for i, name := range stream {
+				edges[i] = tab.Intern(string(name))
+			}
+			return []any{tab, edges}
+		}
+		mem := startMemProfile(b)
+		mem.peakHeap(build)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			build()
+		}
+		b.StopTimer()
+		mem.stop(b)
+	})
+}
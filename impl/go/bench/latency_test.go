@@ -0,0 +1,61 @@
+package bench
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/dsa-lab/go/internal/sketch"
+	"github.com/dsa-lab/go/internal/streaming"
+)
+
+// slowestKept is how many of the slowest latencies are kept exactly: enough
+// for an exact p99.9 over about four million operations.
+const slowestKept = 4096
+
+// latencies collects per-operation latencies in a t-digest, so benchmarks
+// can report tail percentiles of millions of operations in constant memory.
+// The slowest ones are also kept exactly, and a tail percentile that falls
+// among them is reported from them rather than estimated.
+type latencies struct {
+	digest  *sketch.TDigest
+	slowest *streaming.TopK[int64]
+}
+
+func newLatencies() *latencies {
+	return &latencies{
+		digest:  sketch.MustNewTDigest(0),
+		slowest: streaming.MustNewTopKOrdered[int64](slowestKept),
+	}
+}
+
+// record adds one operation's latency.
+func (l *latencies) record(d time.Duration) {
+	l.digest.Add(float64(d.Nanoseconds()))
+	l.slowest.Add(d.Nanoseconds())
+}
+
+// quantile returns the nearest-rank q-quantile, exact if it is among the
+// slowest latencies kept.
+func (l *latencies) quantile(q float64, slowest []int64) float64 {
+	n := l.slowest.Seen()
+	// The nearest rank counts from 1 in ascending order; i counts from 0
+	// in descending order.
+	if i := n - int(math.Ceil(q*float64(n))); i >= 0 && i < len(slowest) {
+		return float64(slowest[i])
+	}
+	return l.digest.Quantile(q)
+}
+
+// report publishes p50, p99, p99.9 and the maximum as <pct>-ns/<unit>
+// metrics, e.g. "p99-ns/insert".
+func (l *latencies) report(b *testing.B, unit string) {
+	if l.digest.Count() == 0 {
+		return
+	}
+	slowest := l.slowest.Sorted()
+	b.ReportMetric(l.digest.Quantile(0.5), "p50-ns/"+unit)
+	b.ReportMetric(l.quantile(0.99, slowest), "p99-ns/"+unit)
+	b.ReportMetric(l.quantile(0.999, slowest), "p99.9-ns/"+unit)
+	b.ReportMetric(float64(slowest[0]), "max-ns/"+unit)
+}
@@ -0,0 +1,66 @@
+package bench
+
+import (
+	"os"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/lsm"
+	"github.com/dsa-lab/go/internal/workload"
+)
+
+// replayLSM applies every operation in the workload to db. Deletes are blind
+// tombstone writes, so unlike the maps the store does not report whether the
+// key existed.
+func replayLSM(b *testing.B, db *lsm.DB, ops []workload.Operation) {
+	for _, op := range ops {
+		var err error
+		switch op.Op {
+		case workload.OpInsert:
+			err = db.Put(op.Key, op.Value)
+		case workload.OpGet:
+			_, _, err = db.Get(op.Key)
+		case workload.OpDelete:
+			err = db.Delete(op.Key)
+		}
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkLSMLarge replays the large workloads into a fresh LSM store with
+// small tables, so flushes and multi-level compaction happen within a single
+// run, and reports write amplification alongside time.
+func BenchmarkLSMLarge(b *testing.B) {
+	for _, name := range []string{"insert_heavy_uniform_large", "mixed_uniform_large", "delete_heavy_zipf_large"} {
+		w, ok := loadWorkload(b, name)
+		if !ok {
+			b.Logf("skipping %s: not generated", name)
+			continue
+		}
+
+		b.Run("workload="+name, func(b *testing.B) {
+			var m lsm.Metrics
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				dir, err := os.MkdirTemp(b.TempDir(), "lsm")
+				if err != nil {
+					b.Fatal(err)
+				}
+				b.StartTimer()
+
+				db, err := lsm.Open(dir, lsm.WithMemtableSize(256<<10), lsm.WithTableSize(512<<10))
+				if err != nil {
+					b.Fatal(err)
+				}
+				replayLSM(b, db, w.Operations)
+				m = db.Metrics()
+				if err := db.Close(); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*len(w.Operations)), "ns/workload-op")
+			b.ReportMetric(m.WriteAmplification(), "write-amp")
+		})
+	}
+}
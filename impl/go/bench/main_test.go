@@ -0,0 +1,18 @@
+package bench
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/features"
+)
+
+// TestMain prints the build's feature configuration ahead of the results so
+// saved benchmark output records which variants were compiled in.
+func TestMain(m *testing.M) {
+	for _, line := range features.ConfigLines() {
+		fmt.Println(line)
+	}
+	os.Exit(m.Run())
+}
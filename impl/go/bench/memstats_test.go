@@ -0,0 +1,59 @@
+package bench
+
+import (
+	"runtime"
+	"testing"
+)
+
+// memProfile records the allocator and collector activity of a benchmark so
+// results show the GC cost that ns/op alone hides. B/op and allocs/op come
+// from testing.B; gc/op, peak-heap-B and retained-B are reported on top.
+type memProfile struct {
+	before   runtime.MemStats
+	peak     uint64
+	retained uint64
+}
+
+// startMemProfile enables allocation reporting and snapshots the heap. Call
+// it before b.ResetTimer and any untimed measurement via peakHeap.
+func startMemProfile(b *testing.B) *memProfile {
+	b.ReportAllocs()
+	p := &memProfile{}
+	runtime.GC()
+	runtime.ReadMemStats(&p.before)
+	return p
+}
+
+// peakHeap runs one untimed iteration and records how far the heap grew
+// over the post-GC baseline while the value returned by run is still live.
+// The figure includes garbage not yet collected, as a real run would; the
+// heap still live after a collection is recorded separately as retained.
+func (p *memProfile) peakHeap(run func() any) {
+	runtime.GC()
+	var base, after runtime.MemStats
+	runtime.ReadMemStats(&base)
+	live := run()
+	runtime.ReadMemStats(&after)
+	if after.HeapAlloc > base.HeapAlloc {
+		p.peak = max(p.peak, after.HeapAlloc-base.HeapAlloc)
+	}
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	runtime.KeepAlive(live)
+	if after.HeapAlloc > base.HeapAlloc {
+		p.retained = max(p.retained, after.HeapAlloc-base.HeapAlloc)
+	}
+	runtime.ReadMemStats(&p.before)
+}
+
+// stop reports GC cycles per operation, and the peak and retained heap when
+// peakHeap was used. Call it after b.StopTimer.
+func (p *memProfile) stop(b *testing.B) {
+	var after runtime.MemStats
+	runtime.ReadMemStats(&after)
+	b.ReportMetric(float64(after.NumGC-p.before.NumGC)/float64(b.N), "gc/op")
+	if p.peak > 0 {
+		b.ReportMetric(float64(p.peak), "peak-heap-B")
+		b.ReportMetric(float64(p.retained), "retained-B")
+	}
+}
@@ -0,0 +1,67 @@
+package bench
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/cache"
+	"github.com/dsa-lab/go/internal/skiplist"
+)
+
+// BenchmarkNodeChurn deletes and reinserts random keys of a 100k-key skip
+// list, with and without the node pool, and drives a full LRU cache with
+// misses so every Put evicts.
+func BenchmarkNodeChurn(b *testing.B) {
+	const size = 100_000
+	rng := rand.New(rand.NewSource(1))
+	keys := make([]int, 1<<16)
+	for i := range keys {
+		keys[i] = rng.Intn(size)
+	}
+
+	lists := []struct {
+		name string
+		new  func() *skiplist.SkipList[int, int]
+	}{
+		{"skiplist", func() *skiplist.SkipList[int, int] { return skiplist.MustNew[int, int]() }},
+		{"skiplist-pooled", func() *skiplist.SkipList[int, int] {
+			return skiplist.MustNew(skiplist.WithNodePool[int, int](1024))
+		}},
+	}
+	for _, l := range lists {
+		s := l.new()
+		for i := 0; i < size; i++ {
+			s.Insert(i, i)
+		}
+		b.Run(fmt.Sprintf("impl=%s", l.name), func(b *testing.B) {
+			mem := startMemProfile(b)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				key := keys[i&(len(keys)-1)]
+				s.Remove(key)
+				s.Insert(key, i)
+			}
+			b.StopTimer()
+			mem.stop(b)
+		})
+	}
+
+	b.Run("impl=lru", func(b *testing.B) {
+		c := cache.MustNewLRU(1024)
+		names := make([]string, len(keys))
+		for i := range names {
+			names[i] = fmt.Sprint("key_", i)
+		}
+		mem := startMemProfile(b)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			key := names[i&(len(names)-1)]
+			if _, found := c.Get(key); !found {
+				c.Put(key, key)
+			}
+		}
+		b.StopTimer()
+		mem.stop(b)
+	})
+}
@@ -0,0 +1,70 @@
+package bench
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/pvector"
+)
+
+// BenchmarkVectorUpdate measures the cost of one random-index update that
+// must keep the previous version intact. The persistent vector copies a
+// root-to-leaf path; a plain slice has to be cloned whole ("slice-copy"). The
+// in-place slice update is included as the floor, though it destroys the old
+// version.
+func BenchmarkVectorUpdate(b *testing.B) {
+	for _, size := range []int{1_000, 100_000, 1_000_000} {
+		v := pvector.New[int]()
+		s := make([]int, size)
+		for i := 0; i < size; i++ {
+			v = v.PushBack(i)
+			s[i] = i
+		}
+		idx := rand.New(rand.NewSource(1)).Perm(size)
+
+		b.Run(fmt.Sprintf("impl=pvector/size=%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				v = v.Set(idx[i%size], i)
+			}
+		})
+		b.Run(fmt.Sprintf("impl=slice-copy/size=%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				c := slices.Clone(s)
+				c[idx[i%size]] = i
+			}
+		})
+		b.Run(fmt.Sprintf("impl=slice-inplace/size=%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				s[idx[i%size]] = i
+			}
+		})
+	}
+}
+
+// BenchmarkVectorPushBack compares building a vector by appending.
+func BenchmarkVectorPushBack(b *testing.B) {
+	const size = 100_000
+	b.Run("impl=pvector", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			v := pvector.New[int]()
+			for j := 0; j < size; j++ {
+				v = v.PushBack(j)
+			}
+		}
+	})
+	b.Run("impl=slice", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var s []int
+			for j := 0; j < size; j++ {
+				s = append(s, j)
+			}
+		}
+	})
+}
@@ -0,0 +1,59 @@
+package bench
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/rangequery"
+)
+
+// BenchmarkRangeMin compares a sparse table with a segment tree on a static
+// range-minimum workload: building over the array, then random queries of
+// random length. The sparse table spends O(n log n) memory to answer in O(1);
+// the segment tree builds in O(n) and answers in O(log n).
+func BenchmarkRangeMin(b *testing.B) {
+	minOf := func(a, b int) int { return min(a, b) }
+	for _, size := range []int{1_000, 1_000_000} {
+		rng := rand.New(rand.NewSource(1))
+		xs := make([]int, size)
+		for i := range xs {
+			xs[i] = rng.Int()
+		}
+		queries := make([][2]int, 1<<16)
+		for i := range queries {
+			lo := rng.Intn(size)
+			queries[i] = [2]int{lo, lo + 1 + rng.Intn(size-lo)}
+		}
+
+		b.Run(fmt.Sprintf("op=build/impl=sparse/size=%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				rangequery.NewMinTable(xs)
+			}
+		})
+		b.Run(fmt.Sprintf("op=build/impl=segment/size=%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				rangequery.NewSegmentTree(xs, minOf)
+			}
+		})
+
+		sparse := rangequery.NewMinTable(xs)
+		segment := rangequery.NewSegmentTree(xs, minOf)
+		b.Run(fmt.Sprintf("op=query/impl=sparse/size=%d", size), func(b *testing.B) {
+			sum := 0
+			for i := 0; i < b.N; i++ {
+				q := queries[i&(len(queries)-1)]
+				sum += sparse.Query(q[0], q[1])
+			}
+			_ = sum
+		})
+		b.Run(fmt.Sprintf("op=query/impl=segment/size=%d", size), func(b *testing.B) {
+			sum := 0
+			for i := 0; i < b.N; i++ {
+				q := queries[i&(len(queries)-1)]
+				sum += segment.Query(q[0], q[1])
+			}
+			_ = sum
+		})
+	}
+}
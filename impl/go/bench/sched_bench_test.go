@@ -0,0 +1,103 @@
+package bench
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/heap"
+	"github.com/dsa-lab/go/internal/order"
+	"github.com/dsa-lab/go/internal/sched"
+)
+
+// heapTimer is a timer in the binary-heap baseline, which cancels lazily:
+// a cancelled timer stays in the heap until it reaches the top.
+type heapTimer struct {
+	deadline  uint64
+	cancelled bool
+}
+
+type heapScheduler struct {
+	h   *heap.Heap[*heapTimer]
+	now uint64
+}
+
+func newHeapScheduler() *heapScheduler {
+	return &heapScheduler{h: heap.New(order.By(func(t *heapTimer) uint64 { return t.deadline }))}
+}
+
+func (s *heapScheduler) add(deadline uint64) *heapTimer {
+	t := &heapTimer{deadline: deadline}
+	s.h.Push(t)
+	return t
+}
+
+func (s *heapScheduler) advance(now uint64) int {
+	fired := 0
+	for {
+		t, ok := s.h.Peek()
+		if !ok || t.deadline > now {
+			break
+		}
+		s.h.Pop()
+		if !t.cancelled {
+			fired++
+		}
+	}
+	s.now = now
+	return fired
+}
+
+// BenchmarkTimers keeps n timers outstanding on a clock advancing one tick
+// per iteration. In the "fire" workload every timer fires and is rescheduled
+// up to n ticks ahead, about one per tick. In the "timeout" workload each
+// tick also adds a timeout and cancels it before it fires, as request
+// deadlines usually are; the heap can only mark it and carries it until its
+// deadline. ns/op is the cost of one tick.
+func BenchmarkTimers(b *testing.B) {
+	for _, n := range []int{1_000, 100_000, 1_000_000} {
+		span := uint64(n)
+		for _, workload := range []string{"fire", "timeout"} {
+			newSchedulers := map[string]func() sched.Scheduler[int]{
+				"wheel":    func() sched.Scheduler[int] { return sched.NewWheel[int](0) },
+				"calendar": func() sched.Scheduler[int] { return sched.NewCalendar[int](0) },
+			}
+			for _, name := range []string{"wheel", "calendar"} {
+				b.Run(fmt.Sprintf("impl=%s/workload=%s/n=%d", name, workload, n), func(b *testing.B) {
+					rng := rand.New(rand.NewSource(1))
+					s := newSchedulers[name]()
+					for i := 0; i < n; i++ {
+						s.Add(1+uint64(rng.Int63n(int64(span))), i)
+					}
+					b.ResetTimer()
+					for i := 0; i < b.N; i++ {
+						now := s.Now() + 1
+						if workload == "timeout" {
+							s.Cancel(s.Add(now+span, i))
+						}
+						for range s.Advance(now) {
+							s.Add(now+1+uint64(rng.Int63n(int64(span))), i)
+						}
+					}
+				})
+			}
+			b.Run(fmt.Sprintf("impl=heap/workload=%s/n=%d", workload, n), func(b *testing.B) {
+				rng := rand.New(rand.NewSource(1))
+				s := newHeapScheduler()
+				for i := 0; i < n; i++ {
+					s.add(1 + uint64(rng.Int63n(int64(span))))
+				}
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					now := s.now + 1
+					if workload == "timeout" {
+						s.add(now + span).cancelled = true
+					}
+					for fired := s.advance(now); fired > 0; fired-- {
+						s.add(now + 1 + uint64(rng.Int63n(int64(span))))
+					}
+				}
+			})
+		}
+	}
+}
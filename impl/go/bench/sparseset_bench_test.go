@@ -0,0 +1,118 @@
+package bench
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/hashmap"
+	"github.com/dsa-lab/go/internal/sparseset"
+)
+
+// intSet is the membership interface the dense-integer benchmarks drive.
+type intSet interface {
+	insert(key int)
+	remove(key int)
+	contains(key int) bool
+	each(f func(key int))
+}
+
+type sparseIntSet struct{ s *sparseset.Set }
+
+func (s sparseIntSet) insert(key int)        { s.s.Insert(key) }
+func (s sparseIntSet) remove(key int)        { s.s.Remove(key) }
+func (s sparseIntSet) contains(key int) bool { return s.s.Contains(key) }
+func (s sparseIntSet) each(f func(int)) {
+	for _, key := range s.s.Keys() {
+		f(key)
+	}
+}
+
+// hashIntSet stores decimal keys in the lab hashmap. The key strings are
+// formatted up front so updates measure the map, not strconv; iteration
+// still parses each key back, a real cost of string-keyed storage.
+type hashIntSet struct {
+	m    *hashmap.HashMap
+	keys []string
+}
+
+func (s hashIntSet) insert(key int)        { s.m.Insert(s.keys[key], "") }
+func (s hashIntSet) remove(key int)        { s.m.Remove(s.keys[key]) }
+func (s hashIntSet) contains(key int) bool { return s.m.Contains(s.keys[key]) }
+func (s hashIntSet) each(f func(int)) {
+	s.m.Range(func(key, _ string) bool {
+		n, _ := strconv.Atoi(key)
+		f(n)
+		return true
+	})
+}
+
+type builtinIntSet map[int]struct{}
+
+func (s builtinIntSet) insert(key int) { s[key] = struct{}{} }
+func (s builtinIntSet) remove(key int) { delete(s, key) }
+func (s builtinIntSet) contains(key int) bool {
+	_, found := s[key]
+	return found
+}
+func (s builtinIntSet) each(f func(int)) {
+	for key := range s {
+		f(key)
+	}
+}
+
+// BenchmarkDenseIntegerSet models entity ids in a game or simulation: ids
+// come from a compact range, entities spawn and despawn constantly, and
+// systems iterate every live entity. "churn" mixes random inserts, removes
+// and lookups; "iterate" visits every member of a half-full set.
+func BenchmarkDenseIntegerSet(b *testing.B) {
+	for _, size := range []int{1_000, 100_000} {
+		keys := make([]string, size)
+		for i := range keys {
+			keys[i] = strconv.Itoa(i)
+		}
+		impls := []struct {
+			name string
+			new  func() intSet
+		}{
+			{"sparseset", func() intSet { return sparseIntSet{sparseset.New(size)} }},
+			{"hashmap", func() intSet { return hashIntSet{hashmap.MustNew(), keys} }},
+			{"builtin", func() intSet { return builtinIntSet{} }},
+		}
+
+		rng := rand.New(rand.NewSource(1))
+		ops := make([]int, 1<<16)
+		for i := range ops {
+			ops[i] = rng.Intn(size)
+		}
+
+		for _, impl := range impls {
+			s := impl.new()
+			for i := 0; i < size; i += 2 {
+				s.insert(i)
+			}
+
+			b.Run(fmt.Sprintf("op=churn/impl=%s/size=%d", impl.name, size), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					key := ops[i&(len(ops)-1)]
+					switch i % 4 {
+					case 0:
+						s.insert(key)
+					case 1:
+						s.remove(key)
+					default:
+						s.contains(key)
+					}
+				}
+			})
+			b.Run(fmt.Sprintf("op=iterate/impl=%s/size=%d", impl.name, size), func(b *testing.B) {
+				sum := 0
+				for i := 0; i < b.N; i++ {
+					s.each(func(key int) { sum += key })
+				}
+				_ = sum
+			})
+		}
+	}
+}
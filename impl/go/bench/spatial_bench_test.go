@@ -0,0 +1,193 @@
+package bench
+
+import (
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/spatial"
+	"github.com/dsa-lab/go/internal/workload"
+)
+
+func uniformPoints(rng *rand.Rand, n, dims int) []spatial.Point {
+	points := make([]spatial.Point, n)
+	for i := range points {
+		p := make(spatial.Point, dims)
+		for j := range p {
+			p[j] = rng.Float64()
+		}
+		points[i] = p
+	}
+	return points
+}
+
+// scanNearest is the linear-scan baseline: the index of the closest point.
+func scanNearest(points []spatial.Point, q spatial.Point) int {
+	best, bestDist := -1, 0.0
+	for i, p := range points {
+		if d := spatial.Dist2(q, p); best < 0 || d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+	return best
+}
+
+// BenchmarkNearestByDimension compares 1-NN queries on a k-d tree against a
+// linear scan over uniformly random points. In low dimensions the tree prunes
+// almost everything; as the dimension grows the query ball crosses most
+// splitting planes, the tree visits nearly every node, and the scan's tight
+// loop wins.
+func BenchmarkNearestByDimension(b *testing.B) {
+	const n = 20000
+	for _, dims := range []int{2, 4, 8, 16, 32} {
+		rng := rand.New(rand.NewSource(1))
+		points := uniformPoints(rng, n, dims)
+		queries := uniformPoints(rng, 256, dims)
+		tree := spatial.MustNewKDTree[int](dims)
+		for i, p := range points {
+			tree.Insert(p, i)
+		}
+
+		b.Run(fmt.Sprintf("dims=%d/impl=kdtree", dims), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := tree.Nearest(queries[i%len(queries)], 1); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("dims=%d/impl=scan", dims), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				scanNearest(points, queries[i%len(queries)])
+			}
+		})
+	}
+}
+
+func loadSpatialWorkload(name string) (*workload.Workload, error) {
+	paths := []string{
+		filepath.Join("..", "..", "workloads", "spatial", name+".json"),
+		filepath.Join("..", "..", "..", "workloads", "spatial", name+".json"),
+	}
+	var w *workload.Workload
+	var err error
+	for _, path := range paths {
+		if w, err = workload.Load(path); err == nil {
+			break
+		}
+	}
+	return w, err
+}
+
+// spatialOp is a workload operation with its geometry parsed up front, so the
+// benchmarks time the index rather than float parsing.
+type spatialOp struct {
+	op   string
+	id   string
+	rect spatial.Rect // points are stored as degenerate boxes
+}
+
+func parseSpatialOps(b *testing.B, w *workload.Workload) []spatialOp {
+	ops := make([]spatialOp, len(w.Operations))
+	geometry := make(map[string]spatial.Rect)
+	for i, op := range w.Operations {
+		ops[i] = spatialOp{op: op.Op, id: op.Key}
+		switch op.Op {
+		case workload.OpInsert:
+			if p, err := spatial.ParsePoint(op.Value); err == nil && len(p) == 2 {
+				ops[i].rect = spatial.Rect{Min: p, Max: p}
+			} else if ops[i].rect, err = spatial.ParseRect(op.Value); err != nil {
+				b.Fatal(err)
+			}
+			geometry[op.Key] = ops[i].rect
+		case workload.OpDelete:
+			ops[i].rect = geometry[op.Key]
+		case spatial.OpQuery:
+			var err error
+			if ops[i].rect, err = spatial.ParseRect(op.Value); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	return ops
+}
+
+// scanIndex is the linear-scan baseline for the spatial workloads.
+type scanIndex map[string]spatial.Rect
+
+func (s scanIndex) replay(ops []spatialOp) int {
+	found := 0
+	for _, op := range ops {
+		switch op.op {
+		case workload.OpInsert:
+			s[op.id] = op.rect
+		case workload.OpDelete:
+			delete(s, op.id)
+		case spatial.OpQuery:
+			for _, r := range s {
+				if op.rect.Intersects(r) {
+					found++
+				}
+			}
+		}
+	}
+	return found
+}
+
+func replayQuadtree(ops []spatialOp) {
+	q := spatial.MustNewQuadtree[string](spatial.Rect{Min: spatial.Point{0, 0}, Max: spatial.Point{1000, 1000}}, 0)
+	for _, op := range ops {
+		switch op.op {
+		case workload.OpInsert:
+			q.Insert(op.rect.Min, op.id)
+		case workload.OpDelete:
+			q.Remove(op.rect.Min, op.id)
+		case spatial.OpQuery:
+			q.Search(op.rect, func(spatial.Point, string) bool { return true })
+		}
+	}
+}
+
+func replayRTree(ops []spatialOp) {
+	t := spatial.MustNewRTree[string](2, 0)
+	for _, op := range ops {
+		switch op.op {
+		case workload.OpInsert:
+			t.Insert(op.rect, op.id)
+		case workload.OpDelete:
+			t.Remove(op.rect, op.id)
+		case spatial.OpQuery:
+			t.Search(op.rect, func(spatial.Rect, string) bool { return true })
+		}
+	}
+}
+
+// BenchmarkSpatialWorkloads replays the clustered point workloads on the
+// quadtree and the rect workloads on the R-tree, each against a linear scan.
+// The large workloads are left out: the scan needs tens of seconds for them.
+func BenchmarkSpatialWorkloads(b *testing.B) {
+	for _, name := range []string{"points_clustered_small", "points_clustered_medium", "rects_clustered_small", "rects_clustered_medium"} {
+		w, err := loadSpatialWorkload(name)
+		if err != nil {
+			b.Logf("skipping %s: %v", name, err)
+			continue
+		}
+		ops := parseSpatialOps(b, w)
+
+		index, replay := "rtree", replayRTree
+		if strings.HasPrefix(name, "points") {
+			index, replay = "quadtree", replayQuadtree
+		}
+		b.Run(fmt.Sprintf("workload=%s/impl=%s", name, index), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				replay(ops)
+			}
+		})
+		b.Run(fmt.Sprintf("workload=%s/impl=scan", name), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				scanIndex{}.replay(ops)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package bench
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/hashmap"
+	"github.com/dsa-lab/go/internal/skiplist"
+	"github.com/dsa-lab/go/internal/succinct"
+)
+
+// BenchmarkStaticDictionary builds a read-only dictionary of 200k URL paths,
+// which share long prefixes, and looks keys up in it. retained-B compares the
+// LOUDS trie with the pointer-based skip list and the hashmap holding the
+// same keys; the keys are built outside the measurement, so the hashmap and
+// skip list are charged only for their nodes and tables, while the trie
+// stores the key bytes itself.
+func BenchmarkStaticDictionary(b *testing.B) {
+	rng := rand.New(rand.NewSource(1))
+	sections := []string{"users", "orders", "products", "reviews", "carts"}
+	keys := make([]string, 200_000)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("/api/v2/%s/%d/%s", sections[rng.Intn(len(sections))], rng.Intn(1_000_000), sections[rng.Intn(len(sections))])
+	}
+	probes := make([]string, 4096)
+	for i := range probes {
+		probes[i] = keys[rng.Intn(len(keys))]
+	}
+
+	type dictionary struct {
+		name  string
+		build func() any
+		get   func(d any, key string) bool
+	}
+	dicts := []dictionary{
+		{"louds", func() any { return succinct.Build(keys, nil) },
+			func(d any, key string) bool { return d.(*succinct.Trie).Contains(key) }},
+		{"skiplist", func() any {
+			s := skiplist.MustNew[string, struct{}]()
+			for _, k := range keys {
+				s.Insert(k, struct{}{})
+			}
+			return s
+		}, func(d any, key string) bool { return d.(*skiplist.SkipList[string, struct{}]).Contains(key) }},
+		{"hashmap", func() any {
+			m := hashmap.MustNew()
+			for _, k := range keys {
+				m.Insert(k, "")
+			}
+			return m
+		}, func(d any, key string) bool { return d.(*hashmap.HashMap).Contains(key) }},
+	}
+	for _, d := range dicts {
+		b.Run("impl="+d.name, func(b *testing.B) {
+			mem := startMemProfile(b)
+			mem.peakHeap(d.build)
+			dict := d.build()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if !d.get(dict, probes[i%len(probes)]) {
+					b.Fatal("expected key present")
+				}
+			}
+			b.StopTimer()
+			mem.stop(b)
+		})
+	}
+}
+
+// BenchmarkPatternCount counts a 12-byte pattern in 2 MB of DNA-like text,
+// with an FM-index and by scanning with strings.Count. The index answers in
+// time independent of the text's length once built; retained-B is its size,
+// which includes no copy of the text.
+func BenchmarkPatternCount(b *testing.B) {
+	rng := rand.New(rand.NewSource(1))
+	buf := make([]byte, 2<<20)
+	for i := range buf {
+		buf[i] = "acgt"[rng.Intn(4)]
+	}
+	text := string(buf)
+	patterns := make([]string, 256)
+	for i := range patterns {
+		start := rng.Intn(len(text) - 12)
+		patterns[i] = text[start : start+12]
+	}
+
+	b.Run("impl=fmindex", func(b *testing.B) {
+		mem := startMemProfile(b)
+		mem.peakHeap(func() any { return succinct.NewFMIndex(text, 0) })
+		x := succinct.NewFMIndex(text, 0)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if x.Count(patterns[i%len(patterns)]) == 0 {
+				b.Fatal("expected an occurrence")
+			}
+		}
+		b.StopTimer()
+		mem.stop(b)
+	})
+	b.Run("impl=scan", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if strings.Count(text, patterns[i%len(patterns)]) == 0 {
+				b.Fatal("expected an occurrence")
+			}
+		}
+	})
+}
@@ -0,0 +1,34 @@
+package bench
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/text"
+)
+
+// BenchmarkEditorSession replays the same simulated typing session on every
+// text buffer, over a small and a large document. The gap buffer pays for
+// each cursor jump in bytes moved, the piece table for each lookup in pieces
+// walked, and the rope a logarithmic cost on every edit.
+func BenchmarkEditorSession(b *testing.B) {
+	for _, size := range []int{10_000, 1_000_000} {
+		initial := strings.Repeat("lorem ipsum dolor sit amet\n", size/27)
+		edits := text.EditorSession(rand.New(rand.NewSource(1)), len(initial), 10_000)
+
+		for _, impl := range text.Implementations() {
+			b.Run(fmt.Sprintf("impl=%s/size=%d", impl.Name, size), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					b.StopTimer()
+					t := impl.New(initial)
+					b.StartTimer()
+					if err := text.Apply(t, edits); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
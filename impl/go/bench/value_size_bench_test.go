@@ -0,0 +1,58 @@
+package bench
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/kv"
+)
+
+// BenchmarkValueSize measures lookup latency with 16-byte values, 4 KiB
+// values and a 90/10 mix of the two. Go strings are a pointer and a length,
+// so every table already stores its values out of line and keeps the same
+// slot size whatever the value length; the mixes should cost the same unless
+// a structure copies values.
+func BenchmarkValueSize(b *testing.B) {
+	const n = 10_000
+	small := strings.Repeat("s", 16)
+	large := strings.Repeat("L", 4096)
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key_%d", i)
+	}
+	rng := rand.New(rand.NewSource(1))
+	order := make([]int, 1<<16)
+	for i := range order {
+		order[i] = rng.Intn(n)
+	}
+
+	mixes := []struct {
+		name     string
+		largePct int
+	}{
+		{"small", 0},
+		{"mixed", 10},
+		{"large", 100},
+	}
+	for _, impl := range kv.Implementations() {
+		for _, mix := range mixes {
+			m := impl.New()
+			for i, key := range keys {
+				value := small
+				if i%100 < mix.largePct {
+					// Distinct copies, as values read from input would be.
+					value = strings.Clone(large)
+				}
+				m.Insert(key, value)
+			}
+			b.Run(fmt.Sprintf("impl=%s/values=%s", impl.Name, mix.name), func(b *testing.B) {
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					m.Get(keys[order[i&(len(order)-1)]])
+				}
+			})
+		}
+	}
+}
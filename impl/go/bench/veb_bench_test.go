@@ -0,0 +1,101 @@
+package bench
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/bitset"
+	"github.com/dsa-lab/go/internal/skiplist"
+	"github.com/dsa-lab/go/internal/veb"
+)
+
+// BenchmarkIntegerSuccessor compares successor queries over a dense set of
+// integer keys: a quarter of a 2^20 universe is present. The tree has no
+// balanced BST, so the ordered skip list stands in for O(log n) structures;
+// a sorted slice is the static floor, and the bitset scans words.
+func BenchmarkIntegerSuccessor(b *testing.B) {
+	const bits = 20
+	rng := rand.New(rand.NewSource(1))
+	v, _ := veb.New(bits)
+	s := skiplist.MustNew[uint64, struct{}]()
+	set := bitset.MustNew(1 << bits)
+	for i := 0; i < 1<<(bits-2); i++ {
+		x := uint64(rng.Intn(1 << bits))
+		v.Insert(x)
+		s.Insert(x, struct{}{})
+		set.Set(int(x))
+	}
+	var sorted []uint64
+	s.Range(func(x uint64, _ struct{}) bool {
+		sorted = append(sorted, x)
+		return true
+	})
+	queries := make([]uint64, 1<<16)
+	for i := range queries {
+		queries[i] = uint64(rng.Intn(1 << bits))
+	}
+
+	b.Run("impl=veb", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			v.Successor(queries[i&(len(queries)-1)])
+		}
+	})
+	b.Run("impl=skiplist", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			s.Higher(queries[i&(len(queries)-1)])
+		}
+	})
+	b.Run("impl=sorted-slice", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			q := queries[i&(len(queries)-1)]
+			j, found := slices.BinarySearch(sorted, q)
+			if found {
+				j++
+			}
+			_ = j
+		}
+	})
+	b.Run("impl=bitset", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			set.NextSet(int(queries[i&(len(queries)-1)]) + 1)
+		}
+	})
+}
+
+// BenchmarkIntegerChurn alternates inserts and deletes of random keys in a
+// 2^20 universe holding about 2^18 keys.
+func BenchmarkIntegerChurn(b *testing.B) {
+	const bits = 20
+	for _, impl := range []string{"veb", "skiplist"} {
+		rng := rand.New(rand.NewSource(1))
+		var insert, remove func(x uint64)
+		switch impl {
+		case "veb":
+			v, _ := veb.New(bits)
+			insert, remove = func(x uint64) { v.Insert(x) }, func(x uint64) { v.Delete(x) }
+		case "skiplist":
+			s := skiplist.MustNew[uint64, struct{}]()
+			insert, remove = func(x uint64) { s.Insert(x, struct{}{}) }, func(x uint64) { s.Remove(x) }
+		}
+		for i := 0; i < 1<<(bits-2); i++ {
+			insert(uint64(rng.Intn(1 << bits)))
+		}
+		keys := make([]uint64, 1<<16)
+		for i := range keys {
+			keys[i] = uint64(rng.Intn(1 << bits))
+		}
+
+		b.Run(fmt.Sprintf("impl=%s", impl), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				x := keys[i&(len(keys)-1)]
+				if i%2 == 0 {
+					insert(x)
+				} else {
+					remove(x)
+				}
+			}
+		})
+	}
+}
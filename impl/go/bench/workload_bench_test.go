@@ -0,0 +1,67 @@
+package bench
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/kv"
+	"github.com/dsa-lab/go/internal/workload"
+)
+
+// BenchmarkWorkloadReplay compares loading a workload before replaying it
+// with decoding it during the replay. Loading keeps every operation live
+// for the whole replay, which retained-B shows; streaming holds a few
+// batches, at the price of decoding inside the timed loop.
+func BenchmarkWorkloadReplay(b *testing.B) {
+	path, err := workloadPath("mixed_zipf_large")
+	if err != nil {
+		b.Skip("workload not found:", err)
+	}
+	ctx := context.Background()
+	modes := []struct {
+		name string
+		run  func() (any, error)
+	}{
+		{"load", func() (any, error) {
+			w, err := workload.Load(path)
+			if err != nil {
+				return nil, err
+			}
+			m := kv.NewBuiltin()
+			_, err = workload.Replay(ctx, m, w.Operations)
+			return w, err
+		}},
+		{"stream", func() (any, error) {
+			f, err := os.Open(path)
+			if err != nil {
+				return nil, err
+			}
+			defer f.Close()
+			m := kv.NewBuiltin()
+			_, err = workload.ReplayStream(ctx, m, bufio.NewReaderSize(f, 1<<16))
+			return m, err
+		}},
+	}
+	for _, mode := range modes {
+		b.Run("mode="+mode.name, func(b *testing.B) {
+			mem := startMemProfile(b)
+			mem.peakHeap(func() any {
+				live, err := mode.run()
+				if err != nil {
+					b.Fatal(err)
+				}
+				return live
+			})
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := mode.run(); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.StopTimer()
+			mem.stop(b)
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Results holds the median of every metric reported for each benchmark.
+type Results struct {
+	// Config records the "key: value" header lines of the run, such as goos,
+	// cpu and the dsa-features line, so a baseline describes its machine.
+	Config     map[string]string             `json:"config"`
+	Benchmarks map[string]map[string]float64 `json:"benchmarks"`
+}
+
+var (
+	procsSuffix = regexp.MustCompile(`-\d+$`)
+	configLine  = regexp.MustCompile(`^([a-z][a-z-]*):\s+(.*)$`)
+)
+
+// Parse reads `go test -bench` output. Repeated runs of a benchmark (from
+// -count) are reduced to the median per metric, and the GOMAXPROCS suffix is
+// dropped from names so results compare across machines.
+func Parse(r io.Reader) (*Results, error) {
+	samples := make(map[string]map[string][]float64)
+	res := &Results{Config: make(map[string]string)}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if m := configLine.FindStringSubmatch(line); m != nil {
+			res.Config[m[1]] = m[2]
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 4 || !strings.HasPrefix(fields[0], "Benchmark") {
+			continue
+		}
+		if _, err := strconv.Atoi(fields[1]); err != nil {
+			continue
+		}
+		name := procsSuffix.ReplaceAllString(fields[0], "")
+		if samples[name] == nil {
+			samples[name] = make(map[string][]float64)
+		}
+		for i := 2; i+1 < len(fields); i += 2 {
+			value, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s: bad value %q for %s", name, fields[i], fields[i+1])
+			}
+			samples[name][fields[i+1]] = append(samples[name][fields[i+1]], value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	res.Benchmarks = make(map[string]map[string]float64, len(samples))
+	for name, metrics := range samples {
+		res.Benchmarks[name] = make(map[string]float64, len(metrics))
+		for metric, values := range metrics {
+			res.Benchmarks[name][metric] = median(values)
+		}
+	}
+	return res, nil
+}
+
+func median(values []float64) float64 {
+	slices.Sort(values)
+	n := len(values)
+	if n%2 == 1 {
+		return values[n/2]
+	}
+	return (values[n/2-1] + values[n/2]) / 2
+}
+
+// Load reads a baseline written by Save.
+func Load(path string) (*Results, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var res Results
+	if err := json.Unmarshal(data, &res); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &res, nil
+}
+
+// Save writes the results as indented JSON, creating the directory if needed.
+func (r *Results) Save(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Row compares one metric of one benchmark. Delta is the fractional change
+// from the baseline; every gated metric is treated as lower-is-better.
+type Row struct {
+	Name      string
+	Metric    string
+	Old, New  float64
+	Delta     float64
+	Regressed bool
+}
+
+// Report is the outcome of comparing a run against a baseline.
+type Report struct {
+	Rows        []Row
+	Regressions int
+	Removed     []string // in the baseline only
+	Added       []string // in the current run only
+	// ConfigChanges lists header lines that differ between the runs, which
+	// usually means the comparison crosses machines or build variants.
+	ConfigChanges []string
+}
+
+// Compare checks each of metrics for the benchmarks present in both runs and
+// flags a regression when the current median exceeds the baseline by more
+// than threshold. A metric that rises from zero, such as allocs/op, always
+// counts as a regression.
+func Compare(base, current *Results, metrics []string, threshold float64) *Report {
+	report := &Report{}
+	for _, name := range sortedKeys(base.Benchmarks) {
+		now, ok := current.Benchmarks[name]
+		if !ok {
+			report.Removed = append(report.Removed, name)
+			continue
+		}
+		for _, metric := range metrics {
+			metric = strings.TrimSpace(metric)
+			old, okOld := base.Benchmarks[name][metric]
+			cur, okNew := now[metric]
+			if !okOld || !okNew {
+				continue
+			}
+			row := Row{Name: name, Metric: metric, Old: old, New: cur}
+			switch {
+			case old != 0:
+				row.Delta = (cur - old) / old
+			case cur > 0:
+				row.Delta = math.Inf(1)
+			}
+			row.Regressed = row.Delta > threshold
+			if row.Regressed {
+				report.Regressions++
+			}
+			report.Rows = append(report.Rows, row)
+		}
+	}
+	for _, name := range sortedKeys(current.Benchmarks) {
+		if _, ok := base.Benchmarks[name]; !ok {
+			report.Added = append(report.Added, name)
+		}
+	}
+	for _, key := range sortedKeys(base.Config) {
+		if now, ok := current.Config[key]; ok && now != base.Config[key] {
+			report.ConfigChanges = append(report.ConfigChanges, fmt.Sprintf("%s: %q -> %q", key, base.Config[key], now))
+		}
+	}
+	return report
+}
+
+// Print writes the comparison as an aligned table followed by a summary.
+func (r *Report) Print(w io.Writer) {
+	for _, change := range r.ConfigChanges {
+		fmt.Fprintln(w, "warning: config changed:", change)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "benchmark\tmetric\tbaseline\tcurrent\tdelta\t")
+	for _, row := range r.Rows {
+		status := ""
+		if row.Regressed {
+			status = "REGRESSION"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%.4g\t%.4g\t%+.1f%%\t%s\n", row.Name, row.Metric, row.Old, row.New, row.Delta*100, status)
+	}
+	tw.Flush()
+
+	for _, name := range r.Removed {
+		fmt.Fprintln(w, "missing from current run:", name)
+	}
+	for _, name := range r.Added {
+		fmt.Fprintln(w, "not in baseline:", name)
+	}
+	fmt.Fprintf(w, "%d comparisons, %d regressions\n", len(r.Rows), r.Regressions)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
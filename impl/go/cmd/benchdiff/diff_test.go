@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const baseOutput = `goos: linux
+cpu: Test CPU
+BenchmarkGet/impl=hashmap-8     1000    100 ns/op    0 B/op    0 allocs/op
+BenchmarkGet/impl=hashmap-8     1000    120 ns/op    0 B/op    0 allocs/op
+BenchmarkGet/impl=hashmap-8     1000    110 ns/op    0 B/op    0 allocs/op
+BenchmarkGet/impl=builtin-8     1000     50 ns/op    0 B/op    0 allocs/op
+BenchmarkOld-8                  1000     10 ns/op
+PASS
+`
+
+const newOutput = `goos: linux
+cpu: Other CPU
+BenchmarkGet/impl=hashmap-4     1000    115 ns/op    16 B/op    1 allocs/op
+BenchmarkGet/impl=builtin-4     1000     70 ns/op    0 B/op    0 allocs/op
+BenchmarkNew-4                  1000     10 ns/op
+`
+
+func TestParse(t *testing.T) {
+	res, err := Parse(strings.NewReader(baseOutput))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := res.Benchmarks["BenchmarkGet/impl=hashmap"]["ns/op"]; got != 110 {
+		t.Errorf("expected median 110 ns/op, got %v", got)
+	}
+	if len(res.Benchmarks) != 3 {
+		t.Errorf("expected 3 benchmarks, got %d", len(res.Benchmarks))
+	}
+	if res.Config["cpu"] != "Test CPU" {
+		t.Errorf("expected cpu config line, got %q", res.Config["cpu"])
+	}
+}
+
+func TestCompare(t *testing.T) {
+	base, _ := Parse(strings.NewReader(baseOutput))
+	current, _ := Parse(strings.NewReader(newOutput))
+
+	report := Compare(base, current, []string{"ns/op"}, 0.10)
+	if report.Regressions != 1 {
+		t.Fatalf("expected 1 regression, got %d", report.Regressions)
+	}
+	for _, row := range report.Rows {
+		if want := row.Name == "BenchmarkGet/impl=builtin"; row.Regressed != want {
+			t.Errorf("%s: expected regressed=%v, delta %.2f", row.Name, want, row.Delta)
+		}
+	}
+	if len(report.Removed) != 1 || report.Removed[0] != "BenchmarkOld" {
+		t.Errorf("expected BenchmarkOld removed, got %v", report.Removed)
+	}
+	if len(report.Added) != 1 || report.Added[0] != "BenchmarkNew" {
+		t.Errorf("expected BenchmarkNew added, got %v", report.Added)
+	}
+	if len(report.ConfigChanges) != 1 {
+		t.Errorf("expected cpu change to be reported, got %v", report.ConfigChanges)
+	}
+
+	report = Compare(base, current, []string{"allocs/op"}, 0.10)
+	if report.Regressions != 1 {
+		t.Errorf("expected allocations rising from zero to regress, got %d", report.Regressions)
+	}
+
+	var buf bytes.Buffer
+	report.Print(&buf)
+	if !strings.Contains(buf.String(), "REGRESSION") {
+		t.Errorf("expected printed report to flag the regression:\n%s", buf.String())
+	}
+}
+
+func TestBaselineRoundTrip(t *testing.T) {
+	res, _ := Parse(strings.NewReader(baseOutput))
+	path := filepath.Join(t.TempDir(), "baseline", "go_bench.json")
+	if err := res.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report := Compare(res, loaded, []string{"ns/op", "B/op"}, 0); report.Regressions != 0 || len(report.Rows) != 5 {
+		t.Errorf("expected identical round trip, got %d regressions over %d rows", report.Regressions, len(report.Rows))
+	}
+}
@@ -0,0 +1,95 @@
+// Command benchdiff is a regression gate for the Go benchmarks. It runs the
+// bench suite (or reads saved `go test -bench` output), takes the median of
+// each benchmark's samples, and compares them against a baseline JSON file.
+// It exits with status 1 when any gated metric is worse than the baseline by
+// more than the threshold, so a CI job can fail on performance regressions.
+//
+// Usage:
+//
+//	go run ./cmd/benchdiff -update                 # record a baseline
+//	go run ./cmd/benchdiff                         # run and compare
+//	go run ./cmd/benchdiff -input new.txt -threshold 0.05 -metrics ns/op,B/op
+//
+// The input format is the standard benchmark text format read by benchstat,
+// so output saved for benchstat can be gated directly. Benchmarks present on
+// only one side are listed but never fail the gate.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+func main() {
+	baseline := flag.String("baseline", "../../reports/baseline/go_bench.json", "baseline JSON file")
+	input := flag.String("input", "", "read benchmark output from this file instead of running the suite")
+	bench := flag.String("bench", ".", "benchmark pattern passed to go test -bench")
+	pkgs := flag.String("pkgs", "./bench", "packages to benchmark")
+	count := flag.Int("count", 5, "samples per benchmark passed to go test -count")
+	threshold := flag.Float64("threshold", 0.10, "fractional slowdown that counts as a regression")
+	metrics := flag.String("metrics", "ns/op", "comma-separated metrics to gate on")
+	update := flag.Bool("update", false, "write the results as the new baseline instead of comparing")
+	flag.Parse()
+
+	var out []byte
+	var err error
+	if *input != "" {
+		out, err = os.ReadFile(*input)
+	} else {
+		out, err = run(*bench, *pkgs, *count)
+	}
+	if err != nil {
+		fatal(err)
+	}
+
+	current, err := Parse(bytes.NewReader(out))
+	if err != nil {
+		fatal(err)
+	}
+	if len(current.Benchmarks) == 0 {
+		fatal(fmt.Errorf("no benchmark results in input"))
+	}
+
+	if *update {
+		if err := current.Save(*baseline); err != nil {
+			fatal(err)
+		}
+		fmt.Printf("wrote %d benchmarks to %s\n", len(current.Benchmarks), *baseline)
+		return
+	}
+
+	base, err := Load(*baseline)
+	if err != nil {
+		fatal(fmt.Errorf("%w (record one with -update)", err))
+	}
+	report := Compare(base, current, strings.Split(*metrics, ","), *threshold)
+	report.Print(os.Stdout)
+	if report.Regressions > 0 {
+		os.Exit(1)
+	}
+}
+
+// run executes the bench suite with memory statistics enabled, echoing the
+// raw output to stderr so it can be saved for benchstat.
+func run(pattern, pkgs string, count int) ([]byte, error) {
+	args := []string{"test", "-run", "^$", "-bench", pattern, "-benchmem", "-count", fmt.Sprint(count)}
+	args = append(args, strings.Fields(pkgs)...)
+	var buf bytes.Buffer
+	cmd := exec.Command("go", args...)
+	cmd.Stdout = io.MultiWriter(&buf, os.Stderr)
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("go %s: %w", strings.Join(args, " "), err)
+	}
+	return buf.Bytes(), nil
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "benchdiff:", err)
+	os.Exit(2)
+}
@@ -0,0 +1,260 @@
+// Command benchrun replays workload files against the registered map
+// implementations outside of `go test`, printing time and allocations per
+// workload and implementation pair, along with the slowest, median and
+// fastest round's throughput to show run-to-run spread. With -profile it also captures a CPU
+// profile and heap profiles for every pair, named consistently so hotspots
+// can be compared across implementations without juggling go test flags:
+//
+//	<dir>/<workload>.<impl>.cpu.pprof        CPU samples of the replays
+//	<dir>/<workload>.<impl>.heap.pprof       heap with the last replay's map live
+//	<dir>/<workload>.<impl>.heap-base.pprof  heap before the pair started
+//
+// The in-use views of a heap profile describe the pair alone; allocation
+// totals accumulate over the whole run, so subtract the base profile:
+//
+//	go tool pprof -http : -diff_base d/w.hashmap.heap-base.pprof d/w.hashmap.heap.pprof
+//	go tool pprof -http : d/w.hashmap.cpu.pprof   # flame graph under View
+//
+// Usage:
+//
+//	go run ./cmd/benchrun -profile ../../reports/profiles
+//	go run ./cmd/benchrun -impls hashmap,builtin -rounds 20 words.txt
+//	go run ./cmd/benchrun -stream -rounds 1 huge.json
+//
+// Arguments are workload files in any format workload.Open accepts; without
+// arguments every JSON workload under workloads/map is replayed.
+//
+// With -stream, JSON workloads are decoded while they are replayed instead of
+// loaded up front, so a workload too large to hold in memory can still run.
+// Each round rereads the file, and decoding on another goroutine shares the
+// CPU with the replay, so streamed figures are not comparable with loaded
+// ones; the bytes and allocations columns then include the decoder's.
+//
+// An interrupt or the -timeout deadline stops the replay in progress. Rows
+// already printed stand; the interrupted pair's profiles are deleted rather
+// than left describing a partial run.
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+	"time"
+
+	"github.com/dsa-lab/go/internal/kv"
+	"github.com/dsa-lab/go/internal/streaming"
+	"github.com/dsa-lab/go/internal/window"
+	"github.com/dsa-lab/go/internal/workload"
+)
+
+func main() {
+	impls := flag.String("impls", "", "comma-separated implementations (default all registered)")
+	rounds := flag.Int("rounds", 5, "replays of each workload per implementation")
+	profile := flag.String("profile", "", "write CPU and heap profiles for each pair into this directory")
+	timeout := flag.Duration("timeout", 0, "stop after this long (default no limit)")
+	stream := flag.Bool("stream", false, "decode JSON workloads during each replay instead of loading them first")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	paths := flag.Args()
+	if len(paths) == 0 {
+		var err error
+		paths, err = filepath.Glob(filepath.Join("..", "..", "workloads", "map", "*.json"))
+		if err != nil || len(paths) == 0 {
+			fatal(fmt.Errorf("no workloads given and none found under workloads/map"))
+		}
+		paths = withoutManifest(paths)
+	}
+
+	selected := kv.Implementations()
+	if *impls != "" {
+		selected = nil
+		for _, name := range strings.Split(*impls, ",") {
+			impl, ok := kv.Lookup(strings.TrimSpace(name))
+			if !ok {
+				fatal(fmt.Errorf("unknown implementation %q", name))
+			}
+			selected = append(selected, impl)
+		}
+	}
+	if *profile != "" {
+		if err := os.MkdirAll(*profile, 0o755); err != nil {
+			fatal(err)
+		}
+	}
+
+	fmt.Println("| Workload | Impl | Ops | ns/op | Min ops/s | Median ops/s | Max ops/s | Bytes/replay | Allocs/replay |")
+	fmt.Println("|----------|------|-----|-------|-----------|--------------|-----------|--------------|---------------|")
+	for _, path := range paths {
+		src, err := open(path, *stream)
+		if err != nil {
+			fatal(fmt.Errorf("%s: %w", path, err))
+		}
+		for _, impl := range selected {
+			r, err := runPair(ctx, src, impl, *rounds, *profile)
+			if err != nil {
+				fatal(err)
+			}
+			fmt.Printf("| %s | %s | %d | %.1f | %.0f | %.0f | %.0f | %d | %d |\n", src.name, impl.Name, r.ops,
+				r.nsPerOp, r.minOpsPerSec, r.medianOpsPerSec, r.maxOpsPerSec, r.bytesPerRound, r.allocsPerRound)
+		}
+	}
+}
+
+// source is a workload ready to replay, either loaded or streamed from its
+// file on every replay.
+type source struct {
+	name   string
+	replay func(ctx context.Context, m kv.Map) (int, error)
+}
+
+func open(path string, stream bool) (source, error) {
+	if !stream {
+		w, err := workload.Open(path)
+		if err != nil {
+			return source{}, err
+		}
+		return source{name: w.Name, replay: func(ctx context.Context, m kv.Map) (int, error) {
+			return workload.Replay(ctx, m, w.Operations)
+		}}, nil
+	}
+	if !strings.EqualFold(filepath.Ext(path), ".json") {
+		return source{}, fmt.Errorf("-stream needs a JSON workload")
+	}
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return source{name: name, replay: func(ctx context.Context, m kv.Map) (int, error) {
+		f, err := os.Open(path)
+		if err != nil {
+			return 0, err
+		}
+		defer f.Close()
+		return workload.ReplayStream(ctx, m, bufio.NewReaderSize(f, 1<<16))
+	}}, nil
+}
+
+type result struct {
+	ops             int
+	nsPerOp         float64
+	minOpsPerSec    float64
+	medianOpsPerSec float64
+	maxOpsPerSec    float64
+	bytesPerRound   uint64
+	allocsPerRound  uint64
+}
+
+// runPair replays src against fresh maps of impl, profiling the replays when
+// dir is set. If ctx is done first, the pair's profiles are removed.
+func runPair(ctx context.Context, src source, impl kv.Implementation, rounds int, dir string) (res result, err error) {
+	throughput, err := window.New(window.WithLastN(rounds))
+	if err != nil {
+		return result{}, fmt.Errorf("-rounds %d: %w", rounds, err)
+	}
+	prefix := filepath.Join(dir, src.name+"."+impl.Name)
+	runtime.GC()
+	if dir != "" {
+		defer func() {
+			if err != nil {
+				for _, suffix := range []string{".heap-base.pprof", ".cpu.pprof", ".heap.pprof"} {
+					os.Remove(prefix + suffix)
+				}
+			}
+		}()
+		if err := writeHeap(prefix + ".heap-base.pprof"); err != nil {
+			return result{}, err
+		}
+		f, err := os.Create(prefix + ".cpu.pprof")
+		if err != nil {
+			return result{}, err
+		}
+		defer f.Close()
+		if err := pprof.StartCPUProfile(f); err != nil {
+			return result{}, err
+		}
+	}
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	var m kv.Map
+	var ops int
+	median := streaming.NewMedianOrdered[float64]()
+	start := time.Now()
+	for r := 0; r < rounds; r++ {
+		roundStart := time.Now()
+		m = impl.New()
+		if ops, err = src.replay(ctx, m); err != nil {
+			pprof.StopCPUProfile()
+			return result{}, fmt.Errorf("%s/%s: %w", src.name, impl.Name, err)
+		}
+		opsPerSec := float64(ops) / time.Since(roundStart).Seconds()
+		throughput.Add(opsPerSec)
+		median.Add(opsPerSec)
+	}
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	if dir != "" {
+		pprof.StopCPUProfile()
+		if err := writeHeap(prefix + ".heap.pprof"); err != nil {
+			return result{}, err
+		}
+	}
+	runtime.KeepAlive(m)
+
+	n := uint64(rounds)
+	slowest, _ := throughput.Min()
+	fastest, _ := throughput.Max()
+	lo, hi, _ := median.Median()
+	return result{
+		ops:             ops,
+		minOpsPerSec:    slowest,
+		medianOpsPerSec: (lo + hi) / 2,
+		maxOpsPerSec:    fastest,
+		nsPerOp:         float64(elapsed.Nanoseconds()) / float64(rounds*max(1, ops)),
+		bytesPerRound:   (after.TotalAlloc - before.TotalAlloc) / n,
+		allocsPerRound:  (after.Mallocs - before.Mallocs) / n,
+	}, nil
+}
+
+// writeHeap writes a heap profile after a collection, so in-use figures
+// count only live objects.
+func writeHeap(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	runtime.GC()
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func withoutManifest(paths []string) []string {
+	kept := paths[:0]
+	for _, p := range paths {
+		if filepath.Base(p) != "manifest.json" {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "benchrun:", err)
+	os.Exit(2)
+}
@@ -0,0 +1,138 @@
+// Command cachesim replays an access trace through every registered cache
+// eviction policy at a range of capacities and prints the hit rate of each,
+// one row per capacity, giving a hit-rate curve per policy. Each key in the
+// trace is a lookup that inserts the key on a miss, as a read-through cache
+// would.
+//
+// Usage:
+//
+//	go run ./cmd/cachesim -trace access.log -capacities 10,100,1000,10000
+//	go run ./cmd/cachesim -zipf 1.1 -keys 100000 -n 1000000 -format csv
+//
+// A trace is any file workload.Open accepts: a JSON workload, a CSV file or
+// one key per line. Only the keys matter; operation types are ignored.
+// Without -trace a Zipf-distributed trace is generated.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/dsa-lab/go/internal/cache"
+	"github.com/dsa-lab/go/internal/workload"
+)
+
+func main() {
+	tracePath := flag.String("trace", "", "access trace file (default: generated Zipf trace)")
+	capacities := flag.String("capacities", "10,100,1000,10000", "comma-separated cache capacities")
+	policies := flag.String("policies", "", "comma-separated policies (default all registered)")
+	format := flag.String("format", "markdown", "output format: markdown or csv")
+	s := flag.Float64("zipf", 1.1, "Zipf exponent of the generated trace (> 1)")
+	keys := flag.Uint64("keys", 100_000, "distinct keys in the generated trace")
+	n := flag.Int("n", 1_000_000, "accesses in the generated trace")
+	seed := flag.Int64("seed", 1, "seed of the generated trace")
+	flag.Parse()
+
+	var sizes []int
+	for _, field := range strings.Split(*capacities, ",") {
+		c, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || c < 1 {
+			fatal(fmt.Errorf("invalid capacity %q", field))
+		}
+		sizes = append(sizes, c)
+	}
+
+	selected := cache.Policies()
+	if *policies != "" {
+		selected = nil
+		for _, name := range strings.Split(*policies, ",") {
+			p, ok := cache.LookupPolicy(strings.TrimSpace(name))
+			if !ok {
+				fatal(fmt.Errorf("unknown policy %q", name))
+			}
+			selected = append(selected, p)
+		}
+	}
+
+	var trace []string
+	if *tracePath != "" {
+		w, err := workload.Open(*tracePath)
+		if err != nil {
+			fatal(err)
+		}
+		for _, op := range w.Operations {
+			trace = append(trace, op.Key)
+		}
+	} else {
+		if *s <= 1 || *keys < 1 {
+			fatal(fmt.Errorf("-zipf must exceed 1 and -keys must be positive"))
+		}
+		zipf := rand.NewZipf(rand.New(rand.NewSource(*seed)), *s, 1, *keys-1)
+		trace = make([]string, *n)
+		for i := range trace {
+			trace[i] = "key_" + strconv.FormatUint(zipf.Uint64(), 10)
+		}
+	}
+	if len(trace) == 0 {
+		fatal(fmt.Errorf("empty trace"))
+	}
+
+	header := []string{"capacity"}
+	for _, p := range selected {
+		header = append(header, p.Name)
+	}
+	switch *format {
+	case "csv":
+		fmt.Println(strings.Join(header, ","))
+	case "markdown":
+		fmt.Printf("# Hit rate over %d accesses, %d distinct keys\n\n", len(trace), distinct(trace))
+		fmt.Println("| " + strings.Join(header, " | ") + " |")
+		fmt.Println("|" + strings.Repeat("---|", len(header)))
+	default:
+		fatal(fmt.Errorf("unknown format %q", *format))
+	}
+
+	for _, capacity := range sizes {
+		row := []string{strconv.Itoa(capacity)}
+		for _, p := range selected {
+			c, err := p.New(capacity)
+			if err != nil {
+				fatal(err)
+			}
+			row = append(row, fmt.Sprintf("%.4f", simulate(c, trace)))
+		}
+		if *format == "csv" {
+			fmt.Println(strings.Join(row, ","))
+		} else {
+			fmt.Println("| " + strings.Join(row, " | ") + " |")
+		}
+	}
+}
+
+// simulate replays trace through c as a read-through cache and returns the
+// hit rate.
+func simulate(c cache.Cache, trace []string) float64 {
+	for _, key := range trace {
+		if _, found := c.Get(key); !found {
+			c.Put(key, key)
+		}
+	}
+	return c.Stats().HitRate()
+}
+
+func distinct(trace []string) int {
+	seen := make(map[string]struct{})
+	for _, key := range trace {
+		seen[key] = struct{}{}
+	}
+	return len(seen)
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "cachesim:", err)
+	os.Exit(2)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/dsa-lab/go/internal/hashmap"
+	"github.com/dsa-lab/go/internal/kv"
+)
+
+// dumper is implemented by structures that can describe their internal layout.
+type dumper interface {
+	Dump(w io.Writer) error
+}
+
+// visualizer is implemented by structures that can draw themselves as DOT.
+type visualizer interface {
+	Visualize(w io.Writer) error
+}
+
+func (s *Shell) stats([]string) error {
+	inst := s.instance()
+	h, ok := inst.m.(*hashmap.HashMap)
+	if !ok {
+		fmt.Fprintf(s.out, "len: %d\n", inst.m.Len())
+		return nil
+	}
+
+	st := h.Stats()
+	fmt.Fprintf(s.out, "len: %d\n", st.Len)
+	fmt.Fprintf(s.out, "capacity: %d\n", st.Capacity)
+	fmt.Fprintf(s.out, "tombstones: %d\n", st.Tombstones)
+	fmt.Fprintf(s.out, "load factor: %.3f\n", st.LoadFactor)
+	fmt.Fprintf(s.out, "avg probe: %.3f\n", st.AvgProbe)
+	fmt.Fprintf(s.out, "max probe: %d\n", st.MaxProbe)
+	fmt.Fprintf(s.out, "clusters: %d (longest %d)\n", st.Clusters, st.LongestCluster)
+	if h.Resizing() {
+		fmt.Fprintln(s.out, "resizing: true")
+	}
+	return nil
+}
+
+// dump prints the internal layout when the structure can describe it, and
+// otherwise its entries in key order. "dump dot" writes a Graphviz graph
+// instead.
+func (s *Shell) dump(args []string) error {
+	inst := s.instance()
+	m := inst.m
+	if len(args) > 0 {
+		if args[0] != "dot" {
+			return fmt.Errorf("unknown dump format %q", args[0])
+		}
+		if inst.v != nil {
+			return inst.v.Visualize(s.out)
+		}
+		v, ok := m.(visualizer)
+		if !ok {
+			return fmt.Errorf("%s has no DOT visualization", s.instance().kind)
+		}
+		return v.Visualize(s.out)
+	}
+	if d, ok := m.(dumper); ok {
+		return d.Dump(s.out)
+	}
+
+	r, ok := m.(kv.Ranger)
+	if !ok {
+		return fmt.Errorf("%s cannot be dumped", s.instance().kind)
+	}
+	type pair struct{ key, value string }
+	var pairs []pair
+	r.Range(func(key, value string) bool {
+		pairs = append(pairs, pair{key, value})
+		return true
+	})
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].key < pairs[j].key })
+	for _, p := range pairs {
+		fmt.Fprintf(s.out, "%q = %q\n", p.key, p.value)
+	}
+	return nil
+}
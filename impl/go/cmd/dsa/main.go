@@ -0,0 +1,41 @@
+// Command dsa is an interactive shell for poking at the lab's data structures.
+// Create named instances of any registered map implementation and run
+// operations against them, inspecting layout and statistics as you go. The
+// trees, heaps and graph of package viz can be created too; they take
+// inserts and draw themselves with "dump dot".
+//
+// Usage:
+//
+//	go run ./cmd/dsa
+//	dsa> new h hashmap
+//	dsa> insert apple red
+//	dsa> get apple
+//	dsa> stats
+//	dsa> dump
+//	dsa> dump dot
+//	dsa> new t interval
+//	dsa> insert 3,9 meeting
+//	dsa> dump dot
+//
+// Commands are read one per line from standard input; type "help" for the
+// full list. With -q the prompt is suppressed, which is convenient when
+// piping in a script.
+package main
+
+import (
+	"flag"
+	"os"
+)
+
+func main() {
+	quiet := flag.Bool("q", false, "suppress the prompt")
+	flag.Parse()
+
+	prompt := "dsa> "
+	if *quiet {
+		prompt = ""
+	}
+	if err := NewShell(os.Stdout).Run(os.Stdin, prompt); err != nil {
+		os.Exit(1)
+	}
+}
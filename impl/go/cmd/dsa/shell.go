@@ -0,0 +1,254 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/dsa-lab/go/internal/kv"
+	"github.com/dsa-lab/go/internal/viz"
+)
+
+// errQuit is returned by Exec when the session should end.
+var errQuit = errors.New("quit")
+
+// instance is a map, or a drawable structure when v is set and m is nil.
+type instance struct {
+	kind string
+	m    kv.Map
+	v    viz.Structure
+}
+
+func (inst *instance) len() int {
+	if inst.v != nil {
+		return inst.v.Len()
+	}
+	return inst.m.Len()
+}
+
+// Shell holds the named instances of a session and the one commands act on.
+type Shell struct {
+	out       io.Writer
+	instances map[string]*instance
+	current   string
+}
+
+// NewShell creates a shell that writes command output to out.
+func NewShell(out io.Writer) *Shell {
+	return &Shell{out: out, instances: make(map[string]*instance)}
+}
+
+// Run reads commands from in until EOF or "quit", printing prompt before each.
+// Command errors are reported and the session continues; only read errors are
+// returned.
+func (s *Shell) Run(in io.Reader, prompt string) error {
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(s.out, prompt)
+		if !scanner.Scan() {
+			if prompt != "" {
+				fmt.Fprintln(s.out)
+			}
+			return scanner.Err()
+		}
+		err := s.Exec(scanner.Text())
+		if err == errQuit {
+			return nil
+		}
+		if err != nil {
+			fmt.Fprintf(s.out, "error: %v\n", err)
+		}
+	}
+}
+
+// Exec runs a single command line. Blank lines and lines starting with # are
+// ignored.
+func (s *Shell) Exec(line string) error {
+	fields := strings.Fields(line)
+	if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+		return nil
+	}
+	name, args := fields[0], fields[1:]
+
+	cmd, ok := commands[name]
+	if !ok {
+		return fmt.Errorf("unknown command %q (try \"help\")", name)
+	}
+	if len(args) < len(cmd.args) || len(args) > len(cmd.args)+len(cmd.optional) {
+		return fmt.Errorf("usage: %s", cmd.usage(name))
+	}
+	if cmd.needsInstance && s.current == "" {
+		return errors.New("no instance selected (use \"new <name> <kind>\")")
+	}
+	if cmd.needsMap && s.instance().m == nil {
+		return fmt.Errorf("%s is not a map (it supports insert, len, list and dump dot)", s.instance().kind)
+	}
+	return cmd.run(s, args)
+}
+
+func (s *Shell) instance() *instance {
+	return s.instances[s.current]
+}
+
+type command struct {
+	args          []string
+	optional      []string
+	help          string
+	needsInstance bool
+	needsMap      bool
+	run           func(s *Shell, args []string) error
+}
+
+func (c command) usage(name string) string {
+	var b strings.Builder
+	b.WriteString(name)
+	for _, arg := range c.args {
+		b.WriteString(" <" + arg + ">")
+	}
+	for _, arg := range c.optional {
+		b.WriteString(" [" + arg + "]")
+	}
+	return b.String()
+}
+
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"help":  {help: "show this help", run: (*Shell).help},
+		"quit":  {help: "end the session", run: func(*Shell, []string) error { return errQuit }},
+		"kinds": {help: "list registered implementations", run: (*Shell).kinds},
+		"new":   {args: []string{"name", "kind"}, help: "create an instance and select it", run: (*Shell).create},
+		"use":   {args: []string{"name"}, help: "select an existing instance", run: (*Shell).use},
+		"list":  {help: "list instances", run: (*Shell).list},
+
+		"insert":   {args: []string{"key", "value"}, help: "insert or update a key", needsInstance: true, run: (*Shell).insert},
+		"get":      {args: []string{"key"}, help: "look up a key", needsInstance: true, needsMap: true, run: (*Shell).get},
+		"remove":   {args: []string{"key"}, help: "remove a key", needsInstance: true, needsMap: true, run: (*Shell).remove},
+		"contains": {args: []string{"key"}, help: "report whether a key is present", needsInstance: true, needsMap: true, run: (*Shell).contains},
+		"len":      {help: "print the number of entries", needsInstance: true, run: (*Shell).length},
+		"clear":    {help: "remove all entries", needsInstance: true, needsMap: true, run: (*Shell).clear},
+		"stats":    {help: "print structural statistics", needsInstance: true, needsMap: true, run: (*Shell).stats},
+		"dump":     {optional: []string{"dot"}, help: "print the internal layout, or the entries; dot emits Graphviz", needsInstance: true, run: (*Shell).dump},
+	}
+}
+
+func (s *Shell) help([]string) error {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(s.out, "  %-22s %s\n", commands[name].usage(name), commands[name].help)
+	}
+	return nil
+}
+
+// kinds lists the maps, then the drawable structures with the keys they
+// take.
+func (s *Shell) kinds([]string) error {
+	for _, impl := range kv.Implementations() {
+		fmt.Fprintln(s.out, impl.Name)
+	}
+	for _, impl := range viz.Implementations() {
+		fmt.Fprintf(s.out, "%s (key: %s)\n", impl.Name, impl.Key)
+	}
+	return nil
+}
+
+func (s *Shell) create(args []string) error {
+	name, kind := args[0], args[1]
+	inst := &instance{kind: kind}
+	if impl, ok := kv.Lookup(kind); ok {
+		inst.m = impl.New()
+	} else if impl, ok := viz.Lookup(kind); ok {
+		inst.v = impl.New()
+	} else {
+		return fmt.Errorf("unknown kind %q (see \"kinds\")", kind)
+	}
+	if _, exists := s.instances[name]; exists {
+		return fmt.Errorf("instance %q already exists", name)
+	}
+	s.instances[name] = inst
+	s.current = name
+	fmt.Fprintf(s.out, "created %s (%s)\n", name, kind)
+	return nil
+}
+
+func (s *Shell) use(args []string) error {
+	if _, ok := s.instances[args[0]]; !ok {
+		return fmt.Errorf("no instance %q", args[0])
+	}
+	s.current = args[0]
+	return nil
+}
+
+func (s *Shell) list([]string) error {
+	names := make([]string, 0, len(s.instances))
+	for name := range s.instances {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		marker := " "
+		if name == s.current {
+			marker = "*"
+		}
+		inst := s.instances[name]
+		fmt.Fprintf(s.out, "%s %s (%s, %d entries)\n", marker, name, inst.kind, inst.len())
+	}
+	return nil
+}
+
+func (s *Shell) insert(args []string) error {
+	if v := s.instance().v; v != nil {
+		if err := v.Insert(args[0], args[1]); err != nil {
+			return err
+		}
+		fmt.Fprintln(s.out, "inserted")
+		return nil
+	}
+	if old, ok := s.instance().m.Insert(args[0], args[1]); ok {
+		fmt.Fprintf(s.out, "updated (was %q)\n", old)
+	} else {
+		fmt.Fprintln(s.out, "inserted")
+	}
+	return nil
+}
+
+func (s *Shell) get(args []string) error {
+	if value, ok := s.instance().m.Get(args[0]); ok {
+		fmt.Fprintf(s.out, "%q\n", value)
+	} else {
+		fmt.Fprintln(s.out, "not found")
+	}
+	return nil
+}
+
+func (s *Shell) remove(args []string) error {
+	if value, ok := s.instance().m.Remove(args[0]); ok {
+		fmt.Fprintf(s.out, "removed %q\n", value)
+	} else {
+		fmt.Fprintln(s.out, "not found")
+	}
+	return nil
+}
+
+func (s *Shell) contains(args []string) error {
+	fmt.Fprintln(s.out, s.instance().m.Contains(args[0]))
+	return nil
+}
+
+func (s *Shell) length([]string) error {
+	fmt.Fprintln(s.out, s.instance().len())
+	return nil
+}
+
+func (s *Shell) clear([]string) error {
+	s.instance().m.Clear()
+	return nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func run(t *testing.T, script string) string {
+	t.Helper()
+	var out strings.Builder
+	if err := NewShell(&out).Run(strings.NewReader(script), ""); err != nil {
+		t.Fatal(err)
+	}
+	return out.String()
+}
+
+func TestSession(t *testing.T) {
+	got := run(t, `
+# comment lines and blanks are ignored
+new h hashmap
+insert apple red
+insert apple green
+get apple
+get pear
+contains apple
+remove apple
+len
+new s skiplist
+insert b 2
+insert a 1
+dump
+use h
+list
+quit
+insert never run
+`)
+	want := `created h (hashmap)
+inserted
+updated (was "red")
+"green"
+not found
+true
+removed "green"
+0
+created s (skiplist)
+inserted
+inserted
+"a" = "1"
+"b" = "2"
+* h (hashmap, 0 entries)
+  s (skiplist, 2 entries)
+`
+	if got != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	cases := []struct {
+		script string
+		want   string
+	}{
+		{"get a", "error: no instance selected"},
+		{"frobnicate", `error: unknown command "frobnicate"`},
+		{"new h nosuchkind", `error: unknown kind "nosuchkind"`},
+		{"new h hashmap\nnew h builtin", `error: instance "h" already exists`},
+		{"new h hashmap\ninsert a", "error: usage: insert <key> <value>"},
+		{"use missing", `error: no instance "missing"`},
+		{"new b builtin\ndump dot", "error: builtin has no DOT visualization"},
+		{"new h hashmap\ndump svg", `error: unknown dump format "svg"`},
+		{"new t interval\nget 1,2", "error: interval is not a map"},
+		{"new t interval\ninsert 1 a", `error: viz: key "1" has 1 comma-separated fields, want 2`},
+	}
+	for _, c := range cases {
+		if got := run(t, c.script); !strings.Contains(got, c.want) {
+			t.Errorf("%q: expected output containing %q, got %q", c.script, c.want, got)
+		}
+	}
+}
+
+func TestStructures(t *testing.T) {
+	got := run(t, "kinds\nnew t interval\ninsert 3,9 a\ninsert 1,4 b\nlen\ndump dot\nnew g graph\ninsert 0,2 5\nlist\ndump dot")
+	for _, want := range []string{"rtree (key: minx,miny,maxx,maxy)", "inserted\ninserted\n2\n", "digraph interval {", "[1, 4)", "* g (graph, 1 entries)", "digraph g {", `0 -> 2 [label="5"]`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected output containing %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestStatsAndDump(t *testing.T) {
+	got := run(t, "new h hashmap\ninsert a 1\nstats\ndump\ndump dot\nnew s skiplist\ndump dot")
+	for _, want := range []string{"capacity: 16", "load factor: 0.062", "table: 16 slots", `"a" = "1" (home`, "digraph hashmap {", "digraph skiplist {"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected output containing %q, got:\n%s", want, got)
+		}
+	}
+}
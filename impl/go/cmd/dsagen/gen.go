@@ -0,0 +1,422 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// method is one method of a generated wrapper's interface, described once
+// so the interface, the mock's fields and the mock's methods agree.
+type method struct {
+	Name    string
+	Params  string // parameter list, without parentheses
+	Args    string // the parameter names, to forward a call
+	Results string // result list as written after the parameters
+}
+
+// wrapper is a map or store prepared for the template.
+type wrapper struct {
+	Name     string
+	Recv     string
+	Skiplist bool
+	Key      string
+	Value    string
+	Fields   []FieldSpec
+	Mock     bool
+	Methods  []method
+}
+
+// Generate returns the formatted source of the package described by s, which
+// must have been validated by ReadSchema. source names the schema in the
+// generated header.
+func Generate(s *Schema, source string) ([]byte, error) {
+	data := struct {
+		Source   string
+		Package  string
+		Maps     []wrapper
+		Stores   []wrapper
+		Codecs   []codecView
+		Hashmap  bool
+		Skiplist bool
+	}{Source: source, Package: s.Package}
+
+	used := make(map[string]bool)
+	for _, m := range s.Maps {
+		w := wrapper{
+			Name:     m.Name,
+			Recv:     receiver(m.Name),
+			Skiplist: m.Structure == "skiplist",
+			Key:      m.Key,
+			Value:    m.Value,
+			Mock:     m.Mock,
+			Methods:  mapMethods(m),
+		}
+		if w.Skiplist {
+			data.Skiplist = true
+		} else {
+			data.Hashmap = true
+			used[m.Key], used[m.Value] = true, true
+		}
+		data.Maps = append(data.Maps, w)
+	}
+	for _, st := range s.Stores {
+		data.Hashmap = true
+		for _, f := range st.Fields {
+			used[f.Type] = true
+		}
+		data.Stores = append(data.Stores, wrapper{
+			Name:    st.Name,
+			Recv:    receiver(st.Name),
+			Fields:  st.Fields,
+			Mock:    st.Mock,
+			Methods: storeMethods(st),
+		})
+	}
+	for typ := range used {
+		if c := codecs[typ]; c.format != "" {
+			data.Codecs = append(data.Codecs, codecView{Type: typ, codec: c})
+		}
+	}
+	sort.Slice(data.Codecs, func(i, j int) bool { return data.Codecs[i].Type < data.Codecs[j].Type })
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("dsagen: %w", err)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("dsagen: formatting generated code: %w", err)
+	}
+	return src, nil
+}
+
+// receiver returns the receiver name for a wrapper's methods: its initial,
+// unless that would shadow a parameter of the generated methods.
+func receiver(name string) string {
+	r := strings.ToLower(name[:1])
+	if r == "f" || r == "k" || r == "v" {
+		return "w"
+	}
+	return r
+}
+
+func mapMethods(m MapSpec) []method {
+	k, v := m.Key, m.Value
+	rangeFunc := "func(key " + k + ", value " + v + ") bool"
+	ms := []method{
+		{"Len", "", "", "int"},
+		{"Get", "key " + k, "key", "(" + v + ", bool)"},
+		{"Insert", "key " + k + ", value " + v, "key, value", "(" + v + ", bool)"},
+		{"Remove", "key " + k, "key", "(" + v + ", bool)"},
+		{"Contains", "key " + k, "key", "bool"},
+		{"Clear", "", "", ""},
+		{"Range", "f " + rangeFunc, "f", ""},
+	}
+	if m.Structure == "skiplist" {
+		ms = append(ms,
+			method{"Floor", "key " + k, "key", "(" + k + ", " + v + ", bool)"},
+			method{"Ceiling", "key " + k, "key", "(" + k + ", " + v + ", bool)"},
+			method{"Scan", "lo, hi " + k + ", f " + rangeFunc, "lo, hi, f", ""},
+		)
+	}
+	return ms
+}
+
+func storeMethods(st StoreSpec) []method {
+	var ms []method
+	for _, f := range st.Fields {
+		ms = append(ms,
+			method{f.Name, "", "", "(" + f.Type + ", bool)"},
+			method{"Set" + f.Name, "v " + f.Type, "v", ""},
+			method{"Clear" + f.Name, "", "", "bool"},
+		)
+	}
+	return ms
+}
+
+// codecView is a codec with the type it encodes, for the template.
+type codecView struct {
+	Type string
+	codec
+}
+
+func (c codecView) Name() string { return c.name }
+
+func (c codecView) Format(expr string) string { return fmt.Sprintf(c.format, expr) }
+
+func (c codecView) Parse(expr string) string { return fmt.Sprintf(c.parse, expr) }
+
+func (c codecView) Convert() bool { return c.convert }
+
+var tmpl = template.Must(template.New("dsagen").Funcs(template.FuncMap{
+	// enc and dec return the expression that converts expr of type typ to
+	// its stored string and back.
+	"enc": func(typ, expr string) string {
+		if typ == "string" {
+			return expr
+		}
+		return "encode" + codecs[typ].name + "(" + expr + ")"
+	},
+	"dec": func(typ, expr string) string {
+		if typ == "string" {
+			return expr
+		}
+		return "decode" + codecs[typ].name + "(" + expr + ")"
+	},
+}).Parse(source))
+
+const source = `// Code generated by dsagen from {{.Source}}. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- if .Codecs}}
+	"strconv"
+{{end}}
+{{- if .Hashmap}}
+	"github.com/dsa-lab/go/pkg/hashmap"
+{{- end}}
+{{- if .Skiplist}}
+	"github.com/dsa-lab/go/pkg/skiplist"
+{{- end}}
+)
+{{range .Maps}}{{$w := .}}
+{{- if .Skiplist}}
+// {{.Name}} maps {{.Key}} keys to {{.Value}} values in a skiplist.List, in
+// ascending key order.
+type {{.Name}} struct {
+	m *skiplist.List[{{.Key}}, {{.Value}}]
+}
+
+// New{{.Name}} creates an empty {{.Name}}.
+func New{{.Name}}() *{{.Name}} {
+	return &{{.Name}}{m: skiplist.New[{{.Key}}, {{.Value}}]()}
+}
+
+// Len returns the number of entries.
+func ({{.Recv}} *{{.Name}}) Len() int {
+	return {{.Recv}}.m.Len()
+}
+
+// Get returns the value for key and whether it is present.
+func ({{.Recv}} *{{.Name}}) Get(key {{.Key}}) ({{.Value}}, bool) {
+	return {{.Recv}}.m.Get(key)
+}
+
+// Insert sets the value for key, returning the previous value and whether
+// there was one.
+func ({{.Recv}} *{{.Name}}) Insert(key {{.Key}}, value {{.Value}}) ({{.Value}}, bool) {
+	return {{.Recv}}.m.Insert(key, value)
+}
+
+// Remove deletes key, returning its value and whether it was present.
+func ({{.Recv}} *{{.Name}}) Remove(key {{.Key}}) ({{.Value}}, bool) {
+	return {{.Recv}}.m.Remove(key)
+}
+
+// Contains reports whether key is present.
+func ({{.Recv}} *{{.Name}}) Contains(key {{.Key}}) bool {
+	return {{.Recv}}.m.Contains(key)
+}
+
+// Clear removes every entry.
+func ({{.Recv}} *{{.Name}}) Clear() {
+	{{.Recv}}.m.Clear()
+}
+
+// Range calls f for each entry in key order until f returns false.
+func ({{.Recv}} *{{.Name}}) Range(f func(key {{.Key}}, value {{.Value}}) bool) {
+	{{.Recv}}.m.Range(f)
+}
+
+// Floor returns the entry with the greatest key at most key.
+func ({{.Recv}} *{{.Name}}) Floor(key {{.Key}}) ({{.Key}}, {{.Value}}, bool) {
+	return {{.Recv}}.m.Floor(key)
+}
+
+// Ceiling returns the entry with the least key at least key.
+func ({{.Recv}} *{{.Name}}) Ceiling(key {{.Key}}) ({{.Key}}, {{.Value}}, bool) {
+	return {{.Recv}}.m.Ceiling(key)
+}
+
+// Scan calls f for each entry with lo <= key < hi in key order until f
+// returns false.
+func ({{.Recv}} *{{.Name}}) Scan(lo, hi {{.Key}}, f func(key {{.Key}}, value {{.Value}}) bool) {
+	{{.Recv}}.m.Scan(lo, hi, f)
+}
+{{- else}}
+// {{.Name}} maps {{.Key}} keys to {{.Value}} values in a hashmap.Map, which
+// holds both encoded as strings.
+type {{.Name}} struct {
+	m *hashmap.Map
+}
+
+// New{{.Name}} creates an empty {{.Name}} configured by opts.
+func New{{.Name}}(opts ...hashmap.Option) (*{{.Name}}, error) {
+	m, err := hashmap.New(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &{{.Name}}{m: m}, nil
+}
+
+// MustNew{{.Name}} is like New{{.Name}} but panics if an option is invalid.
+func MustNew{{.Name}}(opts ...hashmap.Option) *{{.Name}} {
+	return &{{.Name}}{m: hashmap.MustNew(opts...)}
+}
+
+// Len returns the number of entries.
+func ({{.Recv}} *{{.Name}}) Len() int {
+	return {{.Recv}}.m.Len()
+}
+
+// Get returns the value for key and whether it is present.
+func ({{.Recv}} *{{.Name}}) Get(key {{.Key}}) ({{.Value}}, bool) {
+	v, ok := {{.Recv}}.m.Get({{enc .Key "key"}})
+	if !ok {
+		var zero {{.Value}}
+		return zero, false
+	}
+	return {{dec .Value "v"}}, true
+}
+
+// Insert sets the value for key, returning the previous value and whether
+// there was one.
+func ({{.Recv}} *{{.Name}}) Insert(key {{.Key}}, value {{.Value}}) ({{.Value}}, bool) {
+	v, ok := {{.Recv}}.m.Insert({{enc .Key "key"}}, {{enc .Value "value"}})
+	if !ok {
+		var zero {{.Value}}
+		return zero, false
+	}
+	return {{dec .Value "v"}}, true
+}
+
+// Remove deletes key, returning its value and whether it was present.
+func ({{.Recv}} *{{.Name}}) Remove(key {{.Key}}) ({{.Value}}, bool) {
+	v, ok := {{.Recv}}.m.Remove({{enc .Key "key"}})
+	if !ok {
+		var zero {{.Value}}
+		return zero, false
+	}
+	return {{dec .Value "v"}}, true
+}
+
+// Contains reports whether key is present.
+func ({{.Recv}} *{{.Name}}) Contains(key {{.Key}}) bool {
+	return {{.Recv}}.m.Contains({{enc .Key "key"}})
+}
+
+// Clear removes every entry.
+func ({{.Recv}} *{{.Name}}) Clear() {
+	{{.Recv}}.m.Clear()
+}
+
+// Range calls f for each entry, in no particular order, until f returns
+// false.
+func ({{.Recv}} *{{.Name}}) Range(f func(key {{.Key}}, value {{.Value}}) bool) {
+	{{.Recv}}.m.Range(func(k, v string) bool {
+		return f({{dec .Key "k"}}, {{dec .Value "v"}})
+	})
+}
+{{- end}}
+{{template "mock" .}}
+{{end}}
+{{- range .Stores}}
+// {{.Name}} is a record of typed fields held in a hashmap.Map, each under
+// its own key. A field is unset until its setter is called.
+type {{.Name}} struct {
+	m *hashmap.Map
+}
+
+// New{{.Name}} creates a {{.Name}} with every field unset.
+func New{{.Name}}(opts ...hashmap.Option) (*{{.Name}}, error) {
+	m, err := hashmap.New(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &{{.Name}}{m: m}, nil
+}
+
+// MustNew{{.Name}} is like New{{.Name}} but panics if an option is invalid.
+func MustNew{{.Name}}(opts ...hashmap.Option) *{{.Name}} {
+	return &{{.Name}}{m: hashmap.MustNew(opts...)}
+}
+{{$w := .}}{{range .Fields}}
+// {{.Name}} returns the {{.Name}} field and whether it is set.
+func ({{$w.Recv}} *{{$w.Name}}) {{.Name}}() ({{.Type}}, bool) {
+	v, ok := {{$w.Recv}}.m.Get({{printf "%q" .Key}})
+	if !ok {
+		var zero {{.Type}}
+		return zero, false
+	}
+	return {{dec .Type "v"}}, true
+}
+
+// Set{{.Name}} sets the {{.Name}} field to v.
+func ({{$w.Recv}} *{{$w.Name}}) Set{{.Name}}(v {{.Type}}) {
+	{{$w.Recv}}.m.Insert({{printf "%q" .Key}}, {{enc .Type "v"}})
+}
+
+// Clear{{.Name}} unsets the {{.Name}} field, reporting whether it was set.
+func ({{$w.Recv}} *{{$w.Name}}) Clear{{.Name}}() bool {
+	_, ok := {{$w.Recv}}.m.Remove({{printf "%q" .Key}})
+	return ok
+}
+{{end}}
+{{- template "mock" .}}
+{{end}}
+{{- range .Codecs}}
+func encode{{.Name}}(v {{.Type}}) string {
+	return {{.Format "v"}}
+}
+
+// decode{{.Name}} parses a value written by encode{{.Name}}. Only the
+// generated wrappers write to their maps, so a failure means memory was
+// corrupted.
+func decode{{.Name}}(s string) {{.Type}} {
+	v, err := {{.Parse "s"}}
+	if err != nil {
+		panic("{{$.Package}}: stored {{.Type}} " + strconv.Quote(s) + " does not parse")
+	}
+	return {{if .Convert}}{{.Type}}(v){{else}}v{{end}}
+}
+{{end}}
+
+{{- define "mock"}}
+{{- if .Mock}}
+// {{.Name}}API is the method set of {{.Name}}, for code that should also
+// accept Mock{{.Name}}.
+type {{.Name}}API interface {
+{{- range .Methods}}
+	{{.Name}}({{.Params}}) {{.Results}}
+{{- end}}
+}
+
+var (
+	_ {{.Name}}API = (*{{.Name}})(nil)
+	_ {{.Name}}API = (*Mock{{.Name}})(nil)
+)
+
+// Mock{{.Name}} implements {{.Name}}API by calling the function field
+// named after each method. Calling a method whose field is nil panics.
+type Mock{{.Name}} struct {
+{{- range .Methods}}
+	{{.Name}}Func func({{.Params}}) {{.Results}}
+{{- end}}
+}
+{{$w := .}}{{range .Methods}}
+// {{.Name}} calls {{.Name}}Func.
+func (m *Mock{{$w.Name}}) {{.Name}}({{.Params}}) {{.Results}} {
+	if m.{{.Name}}Func == nil {
+		panic("Mock{{$w.Name}}.{{.Name}} called with nil {{.Name}}Func")
+	}
+	{{if .Results}}return {{end}}m.{{.Name}}Func({{.Args}})
+}
+{{end}}
+{{- end}}
+{{- end}}
+`
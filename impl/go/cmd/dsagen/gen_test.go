@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestGoldenProfile checks that the committed wrappers of examples/profile
+// are what the generator currently emits for its schema.
+func TestGoldenProfile(t *testing.T) {
+	dir := filepath.Join("..", "..", "examples", "profile")
+	f, err := os.Open(filepath.Join(dir, "schema.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	s, err := ReadSchema(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Generate(s, "schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile(filepath.Join(dir, "profile_gen.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("expected examples/profile/profile_gen.go to be up to date; run go generate ./examples/profile")
+	}
+}
+
+func TestGenerateEveryType(t *testing.T) {
+	var maps []string
+	for typ, c := range codecs {
+		maps = append(maps, `{"name": "M`+c.name+`", "key": "`+typ+`", "value": "`+typ+`", "mock": true}`)
+	}
+	s, err := ReadSchema(strings.NewReader(`{"package": "p", "maps": [` + strings.Join(maps, ",") + `]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := Generate(s, "all.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"func decodeInt8(s string) int8 {",
+		"return int8(v)",
+		"func decodeUint(s string) uint {",
+		"strconv.ParseFloat(s, 32)",
+		"type MFloat32API interface",
+	} {
+		if !bytes.Contains(src, []byte(want)) {
+			t.Errorf("expected generated code to contain %q", want)
+		}
+	}
+	if bytes.Contains(src, []byte("pkg/skiplist")) {
+		t.Error("expected no skiplist import without a skiplist map")
+	}
+}
+
+func TestReadSchemaRejects(t *testing.T) {
+	cases := map[string]string{
+		"bad package":       `{"package": "a-b", "maps": [{"name": "M", "key": "string", "value": "string"}]}`,
+		"empty":             `{"package": "p"}`,
+		"unknown field":     `{"package": "p", "maps": [{"name": "M", "key": "string", "value": "string", "kind": 1}]}`,
+		"unexported name":   `{"package": "p", "maps": [{"name": "m", "key": "string", "value": "string"}]}`,
+		"duplicate name":    `{"package": "p", "maps": [{"name": "M", "key": "string", "value": "string"}], "stores": [{"name": "M", "fields": [{"name": "F", "type": "int"}]}]}`,
+		"unknown structure": `{"package": "p", "maps": [{"name": "M", "structure": "btree", "key": "string", "value": "string"}]}`,
+		"unsupported key":   `{"package": "p", "maps": [{"name": "M", "key": "[]byte", "value": "string"}]}`,
+		"unordered key":     `{"package": "p", "maps": [{"name": "M", "structure": "skiplist", "key": "bool", "value": "string"}]}`,
+		"no fields":         `{"package": "p", "stores": [{"name": "S"}]}`,
+		"duplicate key":     `{"package": "p", "stores": [{"name": "S", "fields": [{"name": "A", "type": "int", "key": "k"}, {"name": "B", "type": "int", "key": "k"}]}]}`,
+	}
+	for name, schema := range cases {
+		if _, err := ReadSchema(strings.NewReader(schema)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		} else if !strings.HasPrefix(err.Error(), "dsagen: ") {
+			t.Errorf("%s: expected a dsagen error, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+// Command dsagen generates typed wrappers around the lab's public structures.
+// The structures are either string-keyed (pkg/hashmap) or generic
+// (pkg/skiplist); a schema names the key and value types a program actually
+// uses, and dsagen emits a wrapper per map with those types in every
+// signature, so a mistyped key is a compile error rather than a missing
+// entry at run time.
+//
+// Usage:
+//
+//	go run ./cmd/dsagen -schema schema.json -out types_gen.go
+//
+// or, from the package that uses the wrappers:
+//
+//	//go:generate go run github.com/dsa-lab/go/cmd/dsagen -schema schema.json -out types_gen.go
+//
+// A schema declares maps, each a hashmap (keys and values encoded as
+// strings) or a skiplist (ordered keys, extra Floor, Ceiling and Scan
+// methods), and stores: records in the style of context keys, where each
+// field has its own type and a getter, setter and clearer. Setting "mock"
+// on either also emits an interface of its methods and a Mock type whose
+// behaviour is set per method, for tests of code that takes the interface.
+//
+//	{
+//	  "package": "profile",
+//	  "maps": [
+//	    {"name": "Scores", "key": "string", "value": "int64", "mock": true},
+//	    {"name": "History", "structure": "skiplist", "key": "int64", "value": "string"}
+//	  ],
+//	  "stores": [
+//	    {"name": "Session", "fields": [
+//	      {"name": "UserID", "type": "uint64", "key": "uid"},
+//	      {"name": "Admin", "type": "bool"}
+//	    ]}
+//	  ]
+//	}
+//
+// Supported types are string, bool, the sized and unsized integers and the
+// floats; a skiplist key may be any of them but bool.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	schema := flag.String("schema", "", "schema JSON file (required)")
+	out := flag.String("out", "", "write the generated code to this file instead of stdout")
+	flag.Parse()
+	if *schema == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	f, err := os.Open(*schema)
+	if err != nil {
+		fatal(fmt.Errorf("dsagen: %w", err))
+	}
+	s, err := ReadSchema(f)
+	f.Close()
+	if err != nil {
+		fatal(err)
+	}
+	src, err := Generate(s, filepath.Base(*schema))
+	if err != nil {
+		fatal(err)
+	}
+	if *out == "" {
+		_, err = os.Stdout.Write(src)
+	} else {
+		err = os.WriteFile(*out, src, 0o644)
+	}
+	if err != nil {
+		fatal(fmt.Errorf("dsagen: %w", err))
+	}
+}
+
+// fatal reports err, which already names dsagen, and exits.
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(2)
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"io"
+)
+
+// Schema declares the typed wrappers to generate into one package.
+type Schema struct {
+	// Package is the name of the generated package.
+	Package string `json:"package"`
+	// Maps are typed key/value maps over one of the lab's structures.
+	Maps []MapSpec `json:"maps"`
+	// Stores are records of named fields, each with its own value type,
+	// kept in a hashmap under one key per field.
+	Stores []StoreSpec `json:"stores"`
+}
+
+// MapSpec declares a map from Key to Value.
+type MapSpec struct {
+	Name string `json:"name"`
+	// Structure is "hashmap" (the default) or "skiplist". A hashmap stores
+	// keys and values as strings, so both are encoded; a skiplist holds
+	// them as they are and keeps keys ordered.
+	Structure string `json:"structure"`
+	Key       string `json:"key"`
+	Value     string `json:"value"`
+	// Mock also generates an interface of the map's methods and a mock
+	// implementing it.
+	Mock bool `json:"mock"`
+}
+
+// StoreSpec declares a record of typed fields.
+type StoreSpec struct {
+	Name   string      `json:"name"`
+	Fields []FieldSpec `json:"fields"`
+	Mock   bool        `json:"mock"`
+}
+
+// FieldSpec declares one field of a store. Key is the string it is stored
+// under, the field name by default.
+type FieldSpec struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Key  string `json:"key"`
+}
+
+// codec describes how a hashmap-backed wrapper stores a Go type as a string.
+// Strings are stored as they are and need no codec functions.
+type codec struct {
+	// name is the type's name in generated function names.
+	name string
+	// format and parse are fmt formats of expressions that turn a value
+	// named by the argument into a string and back; parse yields the value
+	// and an error.
+	format, parse string
+	// convert is set when parse yields a wider type than the codec's.
+	convert bool
+	ordered bool
+}
+
+// codecs lists the supported key and value types.
+var codecs = map[string]codec{
+	"string":  {name: "String", ordered: true},
+	"bool":    {name: "Bool", format: "strconv.FormatBool(%s)", parse: "strconv.ParseBool(%s)"},
+	"int":     intCodec("Int", 0),
+	"int8":    intCodec("Int8", 8),
+	"int16":   intCodec("Int16", 16),
+	"int32":   intCodec("Int32", 32),
+	"int64":   intCodec("Int64", 64),
+	"uint":    uintCodec("Uint", 0),
+	"uint8":   uintCodec("Uint8", 8),
+	"uint16":  uintCodec("Uint16", 16),
+	"uint32":  uintCodec("Uint32", 32),
+	"uint64":  uintCodec("Uint64", 64),
+	"float32": floatCodec("Float32", 32),
+	"float64": floatCodec("Float64", 64),
+}
+
+func intCodec(name string, bits int) codec {
+	return codec{
+		name:    name,
+		format:  "strconv.FormatInt(int64(%s), 10)",
+		parse:   fmt.Sprintf("strconv.ParseInt(%%s, 10, %d)", bits),
+		convert: bits != 64,
+		ordered: true,
+	}
+}
+
+func uintCodec(name string, bits int) codec {
+	return codec{
+		name:    name,
+		format:  "strconv.FormatUint(uint64(%s), 10)",
+		parse:   fmt.Sprintf("strconv.ParseUint(%%s, 10, %d)", bits),
+		convert: bits != 64,
+		ordered: true,
+	}
+}
+
+func floatCodec(name string, bits int) codec {
+	return codec{
+		name:    name,
+		format:  fmt.Sprintf("strconv.FormatFloat(float64(%%s), 'g', -1, %d)", bits),
+		parse:   fmt.Sprintf("strconv.ParseFloat(%%s, %d)", bits),
+		convert: bits != 64,
+		ordered: true,
+	}
+}
+
+// ReadSchema decodes and validates a schema.
+func ReadSchema(r io.Reader) (*Schema, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	var s Schema
+	if err := dec.Decode(&s); err != nil {
+		return nil, fmt.Errorf("dsagen: schema: %w", err)
+	}
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (s *Schema) validate() error {
+	if !token.IsIdentifier(s.Package) {
+		return fmt.Errorf("dsagen: package %q is not a Go identifier", s.Package)
+	}
+	if len(s.Maps)+len(s.Stores) == 0 {
+		return fmt.Errorf("dsagen: schema declares no maps or stores")
+	}
+	// Every wrapper defines New<Name>, MustNew<Name> and, with a mock,
+	// <Name>API and Mock<Name>; requiring distinct names keeps them apart.
+	names := make(map[string]bool)
+	declare := func(name string) error {
+		if !token.IsIdentifier(name) || !token.IsExported(name) {
+			return fmt.Errorf("dsagen: name %q is not an exported Go identifier", name)
+		}
+		if names[name] {
+			return fmt.Errorf("dsagen: %s declared twice", name)
+		}
+		names[name] = true
+		return nil
+	}
+	for i := range s.Maps {
+		m := &s.Maps[i]
+		if err := declare(m.Name); err != nil {
+			return err
+		}
+		if m.Structure == "" {
+			m.Structure = "hashmap"
+		}
+		if m.Structure != "hashmap" && m.Structure != "skiplist" {
+			return fmt.Errorf("dsagen: %s: unknown structure %q, want hashmap or skiplist", m.Name, m.Structure)
+		}
+		key, ok := codecs[m.Key]
+		if !ok {
+			return fmt.Errorf("dsagen: %s: unsupported key type %q", m.Name, m.Key)
+		}
+		if _, ok := codecs[m.Value]; !ok {
+			return fmt.Errorf("dsagen: %s: unsupported value type %q", m.Name, m.Value)
+		}
+		if m.Structure == "skiplist" && !key.ordered {
+			return fmt.Errorf("dsagen: %s: skiplist keys must be ordered, got %s", m.Name, m.Key)
+		}
+	}
+	for i := range s.Stores {
+		st := &s.Stores[i]
+		if err := declare(st.Name); err != nil {
+			return err
+		}
+		if len(st.Fields) == 0 {
+			return fmt.Errorf("dsagen: %s: store has no fields", st.Name)
+		}
+		fields, keys := make(map[string]bool), make(map[string]bool)
+		for j := range st.Fields {
+			f := &st.Fields[j]
+			if !token.IsIdentifier(f.Name) || !token.IsExported(f.Name) {
+				return fmt.Errorf("dsagen: %s: field %q is not an exported Go identifier", st.Name, f.Name)
+			}
+			if _, ok := codecs[f.Type]; !ok {
+				return fmt.Errorf("dsagen: %s.%s: unsupported type %q", st.Name, f.Name, f.Type)
+			}
+			if f.Key == "" {
+				f.Key = f.Name
+			}
+			if fields[f.Name] || keys[f.Key] {
+				return fmt.Errorf("dsagen: %s.%s: field name or key declared twice", st.Name, f.Name)
+			}
+			fields[f.Name], keys[f.Key] = true, true
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+// Command dsaserver exposes the registered Go map implementations over
+// HTTP/JSON, so the Rust, C++ and Python implementations in the lab can be
+// differential-tested against the Go versions over the wire.
+//
+// Usage:
+//
+//	go run ./cmd/dsaserver -addr localhost:8080
+//
+// Endpoints (request and response bodies are JSON):
+//
+//	GET    /kinds                       {"kinds": [...]}
+//	GET    /instances                   {"instances": [{"id", "kind", "len"}]}
+//	POST   /instances                   {"kind"} -> {"id", "kind", "len"}
+//	DELETE /instances/{id}
+//	POST   /instances/{id}/insert       {"key", "value"} -> {"value", "found"}
+//	POST   /instances/{id}/get          {"key"} -> {"value", "found"}
+//	POST   /instances/{id}/remove       {"key"} -> {"value", "found"}
+//	POST   /instances/{id}/contains     {"key"} -> {"found"}
+//	POST   /instances/{id}/clear
+//	GET    /instances/{id}/len          {"len"}
+//	GET    /instances/{id}/stats        {"len", "hashmap": {...}}
+//	POST   /instances/{id}/ops          {"operations": [...]} -> {"results": [...]}
+//
+// For insert, get and remove, "value" and "found" follow docs/CONTRACT.md: the
+// previous, current or removed value and whether the key was present. The ops
+// endpoint applies a batch of workload operations ("insert", "get", "delete")
+// in order and returns one result per operation. Errors are reported as
+// {"error": "..."} with a 4xx status.
+//
+// The same server with a /metrics endpoint in the Prometheus text format is
+// in the contrib/promexport module, so the core module does not depend on
+// the Prometheus client:
+//
+//	cd contrib/promexport && go run ./cmd/dsaserver -addr localhost:8080
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"github.com/dsa-lab/go/internal/dsaserver"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:8080", "listen address")
+	flag.Parse()
+
+	log.Printf("dsaserver listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, dsaserver.NewServer()))
+}
@@ -0,0 +1,64 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"fmt"
+	"syscall/js"
+)
+
+// main publishes the playground as the global object dsa and then waits
+// forever, serving calls from the page.
+func main() {
+	p := NewPlayground()
+	js.Global().Set("dsa", js.ValueOf(map[string]any{
+		"kinds": function(nil, func([]js.Value) (map[string]any, error) {
+			kinds := make([]any, 0)
+			for _, k := range p.Kinds() {
+				kinds = append(kinds, k)
+			}
+			return map[string]any{"kinds": kinds}, nil
+		}),
+		"create": function([]js.Type{js.TypeString}, func(args []js.Value) (map[string]any, error) {
+			id, err := p.Create(args[0].String())
+			return map[string]any{"id": id}, err
+		}),
+		"delete": function([]js.Type{js.TypeNumber}, func(args []js.Value) (map[string]any, error) {
+			return map[string]any{}, p.Delete(args[0].Int())
+		}),
+		"insert": function([]js.Type{js.TypeNumber, js.TypeString, js.TypeString}, func(args []js.Value) (map[string]any, error) {
+			r, err := p.Insert(args[0].Int(), args[1].String(), args[2].String())
+			return r.object(), err
+		}),
+		"get": function([]js.Type{js.TypeNumber, js.TypeString}, func(args []js.Value) (map[string]any, error) {
+			r, err := p.Get(args[0].Int(), args[1].String())
+			return r.object(), err
+		}),
+		"remove": function([]js.Type{js.TypeNumber, js.TypeString}, func(args []js.Value) (map[string]any, error) {
+			r, err := p.Remove(args[0].Int(), args[1].String())
+			return r.object(), err
+		}),
+		"visualize": function([]js.Type{js.TypeNumber}, func(args []js.Value) (map[string]any, error) {
+			dot, err := p.Visualize(args[0].Int())
+			return map[string]any{"dot": dot}, err
+		}),
+	}))
+	select {}
+}
+
+// function wraps f as a JavaScript function taking arguments of the given
+// types. Arguments of the wrong number or type are reported as {error}
+// rather than panicking, which would stop the program.
+func function(types []js.Type, f func(args []js.Value) (map[string]any, error)) js.Func {
+	return js.FuncOf(func(_ js.Value, args []js.Value) any {
+		if len(args) != len(types) {
+			return reply(nil, fmt.Errorf("expected %d arguments, got %d", len(types), len(args)))
+		}
+		for i, a := range args {
+			if a.Type() != types[i] {
+				return reply(nil, fmt.Errorf("argument %d: expected a %s, got a %s", i+1, types[i], a.Type()))
+			}
+		}
+		return reply(f(args))
+	})
+}
@@ -0,0 +1,13 @@
+//go:build !(js && wasm)
+
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func main() {
+	fmt.Fprintln(os.Stderr, "dsawasm: build with GOOS=js GOARCH=wasm and load it from web/index.html")
+	os.Exit(2)
+}
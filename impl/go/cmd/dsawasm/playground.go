@@ -0,0 +1,223 @@
+// Command dsawasm is the lab's browser playground. Built for WebAssembly, it
+// publishes a global dsa object whose functions create map instances, run
+// operations on them and draw them, so web/index.html can show each probe of
+// a hash map operation step by step next to a Graphviz rendering of the
+// table. The trees, heaps and graph of package viz can be created and drawn
+// as well; they take inserts only.
+//
+// Build and serve it with
+//
+//	just playground
+//
+// or by hand:
+//
+//	GOOS=js GOARCH=wasm go build -o cmd/dsawasm/web/dsa.wasm ./cmd/dsawasm
+//	cp "$(go env GOROOT)/lib/wasm/wasm_exec.js" cmd/dsawasm/web/  # misc/wasm before Go 1.24
+//
+// The object's functions, all returning {error} on failure:
+//
+//	dsa.kinds()                  {kinds}
+//	dsa.create(kind)             {id}
+//	dsa.delete(id)               {}
+//	dsa.insert(id, key, value)   {value, found, steps}
+//	dsa.get(id, key)             {value, found, steps}
+//	dsa.remove(id, key)          {value, found, steps}
+//	dsa.visualize(id)            {dot}
+//
+// "value" and "found" follow docs/CONTRACT.md. Each step is a hash map event
+// {kind, key, slot, step, capacity}, as reported to a hashmap.Observer; steps
+// is empty for the other kinds, which are not traced. Inserting into a
+// drawable structure returns no value, and get and remove fail on one.
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dsa-lab/go/internal/hashmap"
+	"github.com/dsa-lab/go/internal/kv"
+	"github.com/dsa-lab/go/internal/viz"
+)
+
+// visualizer is implemented by structures that can draw themselves as DOT.
+type visualizer interface {
+	Visualize(w io.Writer) error
+}
+
+// recorder collects the hash map events of the operation in progress.
+type recorder struct {
+	events []hashmap.Event
+}
+
+func (r *recorder) Observe(e hashmap.Event) {
+	r.events = append(r.events, e)
+}
+
+// take returns the events recorded since the last call and starts afresh.
+func (r *recorder) take() []hashmap.Event {
+	events := r.events
+	r.events = nil
+	return events
+}
+
+// instance is a map, or a drawable structure when v is set and m is nil.
+type instance struct {
+	kind string
+	m    kv.Map
+	v    viz.Structure
+	// rec is set for hash maps, whose operations are traced step by step.
+	rec *recorder
+}
+
+// Playground holds the instances created from the browser, numbered from 1.
+// Every operation on a hash map instance also returns the probe and resize
+// steps it took, so the page can replay them.
+type Playground struct {
+	instances map[int]*instance
+	nextID    int
+}
+
+// NewPlayground creates a playground with no instances.
+func NewPlayground() *Playground {
+	return &Playground{instances: make(map[int]*instance)}
+}
+
+// Result is the outcome of a map operation, following docs/CONTRACT.md, and
+// the steps it took.
+type Result struct {
+	Value string
+	Found bool
+	Steps []hashmap.Event
+}
+
+// Kinds returns the names of the registered map implementations, then those
+// of the drawable structures.
+func (p *Playground) Kinds() []string {
+	var names []string
+	for _, impl := range kv.Implementations() {
+		names = append(names, impl.Name)
+	}
+	for _, impl := range viz.Implementations() {
+		names = append(names, impl.Name)
+	}
+	return names
+}
+
+// Create makes an empty instance of kind and returns its id.
+func (p *Playground) Create(kind string) (int, error) {
+	inst := &instance{kind: kind}
+	if impl, ok := kv.Lookup(kind); ok {
+		if kind == "hashmap" {
+			inst.rec = &recorder{}
+			inst.m = hashmap.MustNew(hashmap.WithObserver(inst.rec))
+		} else {
+			inst.m = impl.New()
+		}
+	} else if impl, ok := viz.Lookup(kind); ok {
+		inst.v = impl.New()
+	} else {
+		return 0, fmt.Errorf("unknown kind %q", kind)
+	}
+	p.nextID++
+	p.instances[p.nextID] = inst
+	return p.nextID, nil
+}
+
+// Delete discards instance id.
+func (p *Playground) Delete(id int) error {
+	if _, err := p.lookup(id); err != nil {
+		return err
+	}
+	delete(p.instances, id)
+	return nil
+}
+
+// Insert sets key to value in instance id, or adds the item key spells to a
+// drawable structure.
+func (p *Playground) Insert(id int, key, value string) (Result, error) {
+	if inst, err := p.lookup(id); err == nil && inst.v != nil {
+		return Result{}, inst.v.Insert(key, value)
+	}
+	return p.do(id, func(m kv.Map) (string, bool) { return m.Insert(key, value) })
+}
+
+// Get looks key up in instance id.
+func (p *Playground) Get(id int, key string) (Result, error) {
+	return p.do(id, func(m kv.Map) (string, bool) { return m.Get(key) })
+}
+
+// Remove deletes key from instance id.
+func (p *Playground) Remove(id int, key string) (Result, error) {
+	return p.do(id, func(m kv.Map) (string, bool) { return m.Remove(key) })
+}
+
+// Visualize returns instance id drawn as a Graphviz DOT graph.
+func (p *Playground) Visualize(id int) (string, error) {
+	inst, err := p.lookup(id)
+	if err != nil {
+		return "", err
+	}
+	var v visualizer = inst.v
+	if inst.v == nil {
+		var ok bool
+		if v, ok = inst.m.(visualizer); !ok {
+			return "", fmt.Errorf("%s has no DOT visualization", inst.kind)
+		}
+	}
+	var b strings.Builder
+	err = v.Visualize(&b)
+	return b.String(), err
+}
+
+func (p *Playground) do(id int, op func(kv.Map) (string, bool)) (Result, error) {
+	inst, err := p.lookup(id)
+	if err != nil {
+		return Result{}, err
+	}
+	if inst.m == nil {
+		return Result{}, fmt.Errorf("%s is not a map", inst.kind)
+	}
+	value, found := op(inst.m)
+	r := Result{Value: value, Found: found}
+	if inst.rec != nil {
+		r.Steps = inst.rec.take()
+	}
+	return r, nil
+}
+
+func (p *Playground) lookup(id int) (*instance, error) {
+	inst, ok := p.instances[id]
+	if !ok {
+		return nil, fmt.Errorf("no instance %d", id)
+	}
+	return inst, nil
+}
+
+// The functions below shape results as the plain maps and slices that
+// syscall/js converts to JavaScript objects and arrays.
+
+// object returns r as {value, found, steps}, each step being
+// {kind, key, slot, step, capacity}.
+func (r Result) object() map[string]any {
+	steps := make([]any, len(r.Steps))
+	for i, e := range r.Steps {
+		steps[i] = map[string]any{
+			"kind":     e.Kind.String(),
+			"key":      e.Key,
+			"slot":     e.Slot,
+			"step":     e.Step,
+			"capacity": e.Capacity,
+		}
+	}
+	return map[string]any{"value": r.Value, "found": r.Found, "steps": steps}
+}
+
+// reply returns v, or {error} if err is set, mirroring dsaserver's error
+// responses so a page can check every call the same way.
+func reply(v map[string]any, err error) map[string]any {
+	if err != nil {
+		return map[string]any{"error": err.Error()}
+	}
+	return v
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/kv"
+	"github.com/dsa-lab/go/internal/viz"
+)
+
+func TestPlaygroundTracesHashMap(t *testing.T) {
+	p := NewPlayground()
+	id, err := p.Create("hashmap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := p.Insert(id, "apple", "red")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Found || len(r.Steps) == 0 || r.Steps[0].Key != "apple" {
+		t.Errorf("expected a fresh insert traced by its probes, got %+v", r)
+	}
+
+	r, _ = p.Get(id, "apple")
+	if r.Value != "red" || !r.Found {
+		t.Errorf("expected red, got %q (found=%v)", r.Value, r.Found)
+	}
+	// Steps belong to one operation; the insert's must not carry over.
+	if len(r.Steps) != 1 {
+		t.Errorf("expected a single probe for an uncontended get, got %d", len(r.Steps))
+	}
+
+	dot, err := p.Visualize(id)
+	if err != nil || !strings.HasPrefix(dot, "digraph hashmap {") {
+		t.Errorf("expected a DOT graph, got %q, %v", dot, err)
+	}
+}
+
+func TestPlaygroundResizeSteps(t *testing.T) {
+	p := NewPlayground()
+	id, _ := p.Create("hashmap")
+	resized := false
+	for i := 0; i < 20 && !resized; i++ {
+		r, _ := p.Insert(id, strings.Repeat("k", i+1), "v")
+		for _, s := range r.object()["steps"].([]any) {
+			if s.(map[string]any)["kind"] == "resize-start" {
+				resized = true
+			}
+		}
+	}
+	if !resized {
+		t.Error("expected a resize-start step within 20 inserts")
+	}
+}
+
+func TestPlaygroundUntracedKinds(t *testing.T) {
+	p := NewPlayground()
+	for _, impl := range kv.Implementations() {
+		kind := impl.Name
+		id, err := p.Create(kind)
+		if err != nil {
+			t.Fatalf("%s: %v", kind, err)
+		}
+		p.Insert(id, "a", "1")
+		if r, _ := p.Remove(id, "a"); r.Value != "1" || !r.Found {
+			t.Errorf("%s: expected to remove 1, got %q (found=%v)", kind, r.Value, r.Found)
+		}
+	}
+	id, _ := p.Create("builtin")
+	if r, _ := p.Get(id, "x"); r.Steps != nil {
+		t.Errorf("expected no steps for builtin, got %v", r.Steps)
+	}
+	if _, err := p.Visualize(id); err == nil {
+		t.Error("expected builtin to have no visualization")
+	}
+}
+
+func TestPlaygroundStructures(t *testing.T) {
+	p := NewPlayground()
+	for _, impl := range viz.Implementations() {
+		if !strings.Contains(strings.Join(p.Kinds(), " "), impl.Name) {
+			t.Errorf("%s: expected it among the kinds", impl.Name)
+		}
+	}
+	id, err := p.Create("rtree")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Insert(id, "1,2,3,4", "a"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Insert(id, "1,2", "a"); err == nil {
+		t.Error("expected an error for a malformed box")
+	}
+	if _, err := p.Get(id, "1,2,3,4"); err == nil || err.Error() != "rtree is not a map" {
+		t.Errorf("expected get to fail on a structure, got %v", err)
+	}
+	dot, err := p.Visualize(id)
+	if err != nil || !strings.HasPrefix(dot, "digraph rtree {") || !strings.Contains(dot, "[1 2]-[3 4]") {
+		t.Errorf("expected the box drawn, got %q, %v", dot, err)
+	}
+}
+
+func TestPlaygroundErrors(t *testing.T) {
+	p := NewPlayground()
+	if _, err := p.Create("nosuchkind"); err == nil {
+		t.Error("expected an error for an unknown kind")
+	}
+	id, _ := p.Create("skiplist")
+	if err := p.Delete(id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Get(id, "a"); err == nil {
+		t.Error("expected an error for a deleted instance")
+	}
+	if got := reply(nil, p.Delete(id)); got["error"] != "no instance 1" {
+		t.Errorf("expected {error: no instance 1}, got %v", got)
+	}
+}
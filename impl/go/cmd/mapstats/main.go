@@ -0,0 +1,102 @@
+// Command mapstats compares the structure of the lab's hashmap with a model of
+// Go's builtin map for the same keys, so benchmark reports can explain why one
+// is faster rather than only that it is. For each size it prints probe lengths,
+// load factor and slot counts for both maps plus measured heap bytes per entry.
+//
+// Usage:
+//
+//	go run ./cmd/mapstats -sizes 1000,10000,100000 -layout auto
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/dsa-lab/go/internal/gomap"
+	"github.com/dsa-lab/go/internal/hashmap"
+)
+
+func main() {
+	sizes := flag.String("sizes", "1000,10000,100000", "comma-separated key counts")
+	layout := flag.String("layout", "auto", "builtin map layout to model: auto, buckets or swiss")
+	flag.Parse()
+
+	model := gomap.RuntimeLayout()
+	switch *layout {
+	case "auto":
+	case string(gomap.Buckets), string(gomap.Swiss):
+		model = gomap.Layout(*layout)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown layout %q\n", *layout)
+		os.Exit(2)
+	}
+
+	var counts []int
+	for _, field := range strings.Split(*sizes, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || n < 0 {
+			fmt.Fprintf(os.Stderr, "invalid size %q\n", field)
+			os.Exit(2)
+		}
+		counts = append(counts, n)
+	}
+
+	fmt.Printf("# Map structure (%s, builtin modeled as %s)\n\n", runtime.Version(), model)
+	fmt.Println("| Size | Map | Slots | Load | Avg probe | Max probe | Extra | Bytes/entry |")
+	fmt.Println("|------|-----|-------|------|-----------|-----------|-------|-------------|")
+
+	for _, n := range counts {
+		keys := make([]string, n)
+		for i := range keys {
+			keys[i] = fmt.Sprintf("key_%d", i)
+		}
+
+		var lab *hashmap.HashMap
+		labBytes := measure(n, func() {
+			lab = hashmap.MustNew()
+			for _, key := range keys {
+				lab.Insert(key, key)
+			}
+		})
+		ls := lab.Stats()
+		fmt.Printf("| %d | hashmap | %d | %.3f | %.2f slots | %d slots | %d clusters, longest %d | %.1f |\n",
+			n, ls.Capacity, ls.LoadFactor, ls.AvgProbe, ls.MaxProbe, ls.Clusters, ls.LongestCluster, labBytes)
+
+		var builtin map[string]string
+		builtinBytes := measure(n, func() {
+			builtin = make(map[string]string)
+			for _, key := range keys {
+				builtin[key] = key
+			}
+		})
+		runtime.KeepAlive(builtin)
+
+		gs := gomap.Model(model, keys)
+		unit := "buckets"
+		extra := fmt.Sprintf("%d overflow", gs.Overflow)
+		if gs.Layout == gomap.Swiss {
+			unit = "groups"
+			extra = fmt.Sprintf("%d tables", gs.Tables)
+		}
+		fmt.Printf("| %d | builtin | %d | %.3f | %.2f %s | %d %s | %s | %.1f |\n",
+			n, gs.Slots, gs.LoadFactor, gs.AvgProbe, unit, gs.MaxProbe, unit, extra, builtinBytes)
+	}
+}
+
+// measure returns heap bytes allocated by build per entry. Keys are allocated
+// up front, so only table storage is counted.
+func measure(n int, build func()) float64 {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	build()
+	runtime.ReadMemStats(&after)
+	if n == 0 {
+		return 0
+	}
+	return float64(after.TotalAlloc-before.TotalAlloc) / float64(n)
+}
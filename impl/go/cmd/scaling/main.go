@@ -0,0 +1,187 @@
+// Command scaling sweeps every registered map implementation across a range of
+// sizes, measures the per-operation cost of inserts, lookups and removals at
+// each size, and fits the curve to a growth class. The output shows whether a
+// structure scales the way its documentation claims, and where cache effects
+// bend the curve: a hash map whose table outgrows the CPU caches can look
+// closer to O(log n) than O(1) on real hardware.
+//
+// Usage:
+//
+//	go run ./cmd/scaling -sizes 100,1000,10000,100000,1000000 -impls hashmap,skiplist
+//
+// Sizes up to 1e7 work but need several gigabytes of memory for the keys and
+// the map itself.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dsa-lab/go/internal/complexity"
+	"github.com/dsa-lab/go/internal/kv"
+)
+
+// operation measures the mean nanoseconds per operation at size n, repeating
+// until at least minOps operations have run so small sizes are not all noise.
+type operation struct {
+	name    string
+	measure func(impl kv.Implementation, keys []string, minOps int) float64
+}
+
+var operations = []operation{
+	{"insert", measureInsert},
+	{"get", measureGet},
+	{"remove", measureRemove},
+}
+
+func main() {
+	sizes := flag.String("sizes", "100,1000,10000,100000,1000000", "comma-separated sizes to sweep")
+	impls := flag.String("impls", "", "comma-separated implementations (default all registered)")
+	ops := flag.String("ops", "insert,get,remove", "comma-separated operations: insert, get, remove")
+	minOps := flag.Int("min-ops", 1_000_000, "minimum operations timed per size")
+	flag.Parse()
+
+	var counts []int
+	for _, field := range strings.Split(*sizes, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || n < 2 {
+			fmt.Fprintf(os.Stderr, "invalid size %q\n", field)
+			os.Exit(2)
+		}
+		counts = append(counts, n)
+	}
+
+	var selected []kv.Implementation
+	if *impls == "" {
+		selected = kv.Implementations()
+	} else {
+		for _, name := range strings.Split(*impls, ",") {
+			impl, ok := kv.Lookup(strings.TrimSpace(name))
+			if !ok {
+				fmt.Fprintf(os.Stderr, "unknown implementation %q\n", name)
+				os.Exit(2)
+			}
+			selected = append(selected, impl)
+		}
+	}
+
+	var selectedOps []operation
+	for _, name := range strings.Split(*ops, ",") {
+		found := false
+		for _, op := range operations {
+			if op.name == strings.TrimSpace(name) {
+				selectedOps = append(selectedOps, op)
+				found = true
+			}
+		}
+		if !found {
+			fmt.Fprintf(os.Stderr, "unknown operation %q\n", name)
+			os.Exit(2)
+		}
+	}
+
+	largest := 0
+	for _, n := range counts {
+		largest = max(largest, n)
+	}
+	keys := make([]string, largest)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key_%d", i)
+	}
+
+	header := "| Impl | Op |"
+	rule := "|------|----|"
+	for _, n := range counts {
+		header += fmt.Sprintf(" n=%d |", n)
+		rule += "------|"
+	}
+	fmt.Println("# Scaling (ns/op)")
+	fmt.Println()
+	fmt.Println(header + " Exponent | Fit | Constant | Error |")
+	fmt.Println(rule + "----------|-----|----------|-------|")
+
+	for _, impl := range selected {
+		for _, op := range selectedOps {
+			row := fmt.Sprintf("| %s | %s |", impl.Name, op.name)
+			var samples []complexity.Sample
+			for _, n := range counts {
+				cost := op.measure(impl, keys[:n], *minOps)
+				samples = append(samples, complexity.Sample{N: n, Cost: cost})
+				row += fmt.Sprintf(" %.1f |", cost)
+			}
+			fit, err := complexity.Fit(samples)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			fmt.Printf("%s %.3f | %s | %.3g | %.1f%% |\n", row, fit.Exponent, fit.Model, fit.Constant, fit.Error*100)
+		}
+	}
+}
+
+// fill returns a map of impl holding keys.
+func fill(impl kv.Implementation, keys []string) kv.Map {
+	m := impl.New()
+	for _, key := range keys {
+		m.Insert(key, key)
+	}
+	return m
+}
+
+// measureInsert times building the map from empty, so the cost includes the
+// amortised resizes an insert-only workload pays.
+func measureInsert(impl kv.Implementation, keys []string, minOps int) float64 {
+	rounds := max(1, minOps/len(keys))
+	var elapsed time.Duration
+	for r := 0; r < rounds; r++ {
+		m := impl.New()
+		start := time.Now()
+		for _, key := range keys {
+			m.Insert(key, key)
+		}
+		elapsed += time.Since(start)
+	}
+	return float64(elapsed.Nanoseconds()) / float64(rounds*len(keys))
+}
+
+// measureGet times successful lookups in shuffled order, so the sweep does
+// not reward structures for sequential access.
+func measureGet(impl kv.Implementation, keys []string, minOps int) float64 {
+	m := fill(impl, keys)
+	order := shuffled(keys)
+	total := max(minOps, len(keys))
+	start := time.Now()
+	for i := 0; i < total; i++ {
+		m.Get(order[i%len(order)])
+	}
+	return float64(time.Since(start).Nanoseconds()) / float64(total)
+}
+
+// measureRemove times removing every key of a full map in shuffled order.
+func measureRemove(impl kv.Implementation, keys []string, minOps int) float64 {
+	order := shuffled(keys)
+	rounds := max(1, minOps/len(keys))
+	var elapsed time.Duration
+	for r := 0; r < rounds; r++ {
+		m := fill(impl, keys)
+		start := time.Now()
+		for _, key := range order {
+			m.Remove(key)
+		}
+		elapsed += time.Since(start)
+	}
+	return float64(elapsed.Nanoseconds()) / float64(rounds*len(keys))
+}
+
+func shuffled(keys []string) []string {
+	order := append([]string(nil), keys...)
+	rand.New(rand.NewSource(1)).Shuffle(len(order), func(i, j int) {
+		order[i], order[j] = order[j], order[i]
+	})
+	return order
+}
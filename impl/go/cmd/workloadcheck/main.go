@@ -0,0 +1,210 @@
+// Command workloadcheck validates workload files against the workload schema
+// and reports what they contain, so a malformed file fails loudly here rather
+// than replaying as a benchmark that silently skips operations it does not
+// understand.
+//
+// Usage:
+//
+//	go run ./cmd/workloadcheck                          # every map workload
+//	go run ./cmd/workloadcheck -stats ../../workloads/range/*.json
+//	go run ./cmd/workloadcheck -ops update,rank,top ../../workloads/leaderboard/*.json
+//	go run ./cmd/workloadcheck -schema 1 old.json
+//	go run ./cmd/workloadcheck -format minify -w ../../workloads/map/*_large.json
+//
+// Each problem is printed as "file: operation N: message". -stats adds the
+// op mix next to the declared weights, and the key distribution: distinct
+// keys, the share of accesses that go to the hottest key and the hottest 1%
+// of keys, and key and value lengths.
+//
+// -format canonical re-encodes a file as workload.Write does: two-space
+// indent, fields in schema order and operation weights sorted by op, so two
+// canonical files differ only where their contents do. -format minify drops
+// the whitespace, roughly halving the file. Output goes to stdout or, with
+// -w, over the file. Files with problems are never rewritten.
+//
+// The exit status is 1 if any file has problems and 2 if a file could not be
+// read.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/dsa-lab/go/internal/multiset"
+	"github.com/dsa-lab/go/internal/workload"
+)
+
+func main() {
+	version := flag.Int("schema", 2, "schema version to check against: 1 or 2")
+	extraOps := flag.String("ops", "", "comma-separated domain ops to accept besides the map ops")
+	stats := flag.Bool("stats", false, "print op mix and key distribution statistics")
+	maxProblems := flag.Int("max", 20, "problems to print per file (0 for all)")
+	format := flag.String("format", "", "re-encode valid files: canonical or minify")
+	write := flag.Bool("w", false, "with -format, rewrite files in place instead of printing them")
+	flag.Parse()
+
+	schema := workload.Schema{Version: *version}
+	if *extraOps != "" {
+		for _, op := range strings.Split(*extraOps, ",") {
+			schema.ExtraOps = append(schema.ExtraOps, strings.TrimSpace(op))
+		}
+	}
+	if *format != "" && *format != "canonical" && *format != "minify" {
+		fatal(fmt.Errorf("unknown format %q, want canonical or minify", *format))
+	}
+
+	paths := flag.Args()
+	if len(paths) == 0 {
+		paths, _ = filepath.Glob(filepath.Join("..", "..", "workloads", "map", "*.json"))
+		paths = slices.DeleteFunc(paths, func(p string) bool { return filepath.Base(p) == "manifest.json" })
+		if len(paths) == 0 {
+			fatal(fmt.Errorf("no workloads given and none found under workloads/map"))
+		}
+	}
+	if *format != "" && !*write && len(paths) > 1 {
+		fatal(fmt.Errorf("-format without -w prints one file; got %d", len(paths)))
+	}
+
+	status := 0
+	for _, path := range paths {
+		w, err := workload.Load(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			status = 2
+			continue
+		}
+		problems := schema.Validate(w)
+		for i, p := range problems {
+			if *maxProblems > 0 && i == *maxProblems {
+				fmt.Printf("%s: ... and %d more\n", path, len(problems)-i)
+				break
+			}
+			fmt.Printf("%s: %s\n", path, p)
+		}
+		if len(problems) > 0 {
+			status = max(status, 1)
+		}
+		if *stats {
+			printStats(path, w)
+		}
+		if *format == "" || len(problems) > 0 {
+			continue
+		}
+		if err := reformat(path, w, *format == "minify", *write); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			status = 2
+		}
+	}
+	os.Exit(status)
+}
+
+// printStats prints the op mix and key distribution of w.
+func printStats(path string, w *workload.Workload) {
+	fmt.Printf("%s: %d operations\n", path, len(w.Operations))
+
+	mix := multiset.New()
+	keys := multiset.New()
+	var keyBytes, valueBytes, values int
+	minKey, maxKey := -1, 0
+	for _, op := range w.Operations {
+		mix.Add(op.Op)
+		keys.Add(op.Key)
+		keyBytes += len(op.Key)
+		if minKey < 0 || len(op.Key) < minKey {
+			minKey = len(op.Key)
+		}
+		maxKey = max(maxKey, len(op.Key))
+		if op.Op == workload.OpInsert {
+			valueBytes += len(op.Value)
+			values++
+		}
+	}
+	n := max(1, len(w.Operations))
+
+	var ops []string
+	mix.Range(func(op string, _ int) bool {
+		ops = append(ops, op)
+		return true
+	})
+	for op := range w.OperationWeights {
+		if !mix.Contains(op) {
+			ops = append(ops, op)
+		}
+	}
+	slices.Sort(ops)
+	for _, op := range ops {
+		line := fmt.Sprintf("  %-8s %6.2f%%", op, 100*float64(mix.Count(op))/float64(n))
+		if weight, ok := w.OperationWeights[op]; ok {
+			line += fmt.Sprintf("  (declared %.2f%%)", 100*weight)
+		}
+		fmt.Println(line)
+	}
+
+	if keys.Distinct() == 0 {
+		return
+	}
+	counts := make([]int, 0, keys.Distinct())
+	keys.Range(func(_ string, count int) bool {
+		counts = append(counts, count)
+		return true
+	})
+	slices.Sort(counts)
+	slices.Reverse(counts)
+	hot := 0
+	for _, c := range counts[:max(1, len(counts)/100)] {
+		hot += c
+	}
+	fmt.Printf("  keys     %d distinct, hottest %.2f%% of accesses, hottest 1%% of keys %.2f%%\n",
+		len(counts), share(counts[0], n), share(hot, n))
+	fmt.Printf("  key len  min %d, mean %.1f, max %d\n", max(0, minKey), float64(keyBytes)/float64(n), maxKey)
+	if values > 0 {
+		fmt.Printf("  value len mean %.1f over %d inserts\n", float64(valueBytes)/float64(values), values)
+	}
+}
+
+func share(part, total int) float64 {
+	return 100 * float64(part) / float64(total)
+}
+
+// reformat encodes w, canonically indented or minified, to stdout or
+// atomically over path.
+func reformat(path string, w *workload.Workload, minify, inPlace bool) error {
+	encode := func(out io.Writer) error {
+		if minify {
+			return json.NewEncoder(out).Encode(w)
+		}
+		return workload.Write(out, w)
+	}
+	if !inPlace {
+		return encode(os.Stdout)
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	if err := encode(f); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Chmod(f.Name(), 0o644); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "workloadcheck:", err)
+	os.Exit(2)
+}
@@ -0,0 +1,45 @@
+// Command dsaserver runs the core module's cmd/dsaserver with a /metrics
+// endpoint added, in the Prometheus text format. It lives in this module so
+// the core module does not depend on the Prometheus client.
+//
+// Usage:
+//
+//	go run ./cmd/dsaserver -addr localhost:8080
+//
+// The other endpoints are those of the core command. /metrics exports the
+// size of every instance, labelled "kind/id", and the capacity, load factor
+// and resize count of hashmap instances, for monitoring a long-running
+// server.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/dsa-lab/go/contrib/promexport"
+	"github.com/dsa-lab/go/internal/dsaserver"
+)
+
+// newServer returns the core server with every instance exported at
+// /metrics. A scrape holds the server's lock while it reads the instances.
+func newServer() *dsaserver.Server {
+	s := dsaserver.NewServer()
+	c := promexport.NewCollector(promexport.WithLocker(s.Locker()))
+	s.Track(c)
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(c)
+	s.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+	return s
+}
+
+func main() {
+	addr := flag.String("addr", "localhost:8080", "listen address")
+	flag.Parse()
+
+	log.Printf("dsaserver listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, newServer()))
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	srv := httptest.NewServer(newServer())
+	t.Cleanup(srv.Close)
+
+	// send makes a request with a JSON body and returns the decoded response.
+	send := func(method, path, body string) map[string]any {
+		req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var out map[string]any
+		if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+			t.Fatalf("%s %s: decoding response: %v", method, path, err)
+		}
+		return out
+	}
+	hm := send("POST", "/instances", `{"kind": "hashmap"}`)["id"].(string)
+	sl := send("POST", "/instances", `{"kind": "skiplist"}`)["id"].(string)
+	for i := 0; i < 20; i++ {
+		send("POST", "/instances/"+hm+"/insert", `{"key": "`+strconv.Itoa(i)+`", "value": "v"}`)
+	}
+
+	scrape := func() string {
+		resp, err := http.Get(srv.URL + "/metrics")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(body)
+	}
+	body := scrape()
+	for _, want := range []string{
+		`dsa_size{structure="hashmap/` + hm + `"} 20`,
+		`dsa_resizes_total{structure="hashmap/` + hm + `"} 1`,
+		`dsa_size{structure="skiplist/` + sl + `"} 0`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected metrics to contain %q, got:\n%s", want, body)
+		}
+	}
+
+	send("DELETE", "/instances/"+sl, "")
+	if body := scrape(); strings.Contains(body, "skiplist/") {
+		t.Errorf("expected deleted instance to leave the metrics, got:\n%s", body)
+	}
+}
@@ -0,0 +1,210 @@
+// Package promexport publishes live statistics of the lab's structures as
+// Prometheus metrics, so a long-running service embedding them can be
+// monitored alongside everything else it exports. It is a module of its own,
+// github.com/dsa-lab/go/contrib/promexport, so that only programs exporting
+// metrics depend on the Prometheus client.
+//
+// A Collector holds named structures and reads them at scrape time. It
+// exports whatever each structure can report, found by the methods it has:
+//
+//	Len() int                              <ns>_size
+//	Capacity() int                         <ns>_capacity, and with Len <ns>_load_factor
+//	Resizes() int                          <ns>_resizes_total
+//	ProbeQuantile(float64) (float64, bool) <ns>_probe_length{quantile="0.5|0.9|0.99"}
+//	Stats() cache.Stats                    <ns>_cache_{hits,misses,evictions}_total, <ns>_cache_hit_ratio
+//
+// Every metric carries a "structure" label with the name the structure was
+// added under. The hash map only estimates probe quantiles when created
+// with WithProbeQuantiles; without it, or before the first lookup, the
+// probe metrics are omitted. Register a Collector like any other:
+//
+//	m := hashmap.MustNew(hashmap.WithProbeQuantiles())
+//	c := promexport.NewCollector()
+//	c.Add("sessions", m)
+//	prometheus.MustRegister(c)
+//
+// The structures are not safe for concurrent use, so a scrape must not
+// overlap a write; pass the mutex guarding them to WithLocker.
+package promexport
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/dsa-lab/go/internal/cache"
+	"github.com/dsa-lab/go/internal/errs"
+)
+
+// ErrInvalidOption is matched, via errors.Is, by the error Add returns for a
+// structure that reports no metrics.
+var ErrInvalidOption = errs.ErrInvalidOption
+
+// The capabilities a structure may have.
+type (
+	sizer     interface{ Len() int }
+	capacitor interface{ Capacity() int }
+	resizer   interface{ Resizes() int }
+	prober    interface {
+		ProbeQuantile(q float64) (float64, bool)
+	}
+	hitCounter interface{ Stats() cache.Stats }
+)
+
+// probeQuantiles are the quantiles of probe length exported when tracked.
+var probeQuantiles = []float64{0.5, 0.9, 0.99}
+
+// Collector is a prometheus.Collector over a set of named structures. Its
+// methods are safe for concurrent use, but reading the structures is not:
+// unless they are only touched while holding the lock given to WithLocker,
+// scrapes must not overlap with writes.
+type Collector struct {
+	mu      sync.Mutex
+	sources map[string]any
+	lock    sync.Locker
+
+	namespace string
+
+	size, capacity, loadFactor, resizes, probeLength *prometheus.Desc
+	hits, misses, evictions, hitRatio                *prometheus.Desc
+}
+
+// Option configures a Collector.
+type Option func(*Collector)
+
+// WithNamespace sets the prefix of every metric name. The default is "dsa".
+func WithNamespace(ns string) Option {
+	return func(c *Collector) {
+		c.namespace = ns
+	}
+}
+
+// WithLocker makes Collect hold l while it reads the structures, for
+// services that already guard them with a mutex.
+func WithLocker(l sync.Locker) Option {
+	return func(c *Collector) {
+		c.lock = l
+	}
+}
+
+// NewCollector creates a Collector with no structures. Register it with a
+// prometheus.Registerer to serve its metrics.
+func NewCollector(opts ...Option) *Collector {
+	c := &Collector{sources: make(map[string]any), namespace: "dsa"}
+	for _, opt := range opts {
+		opt(c)
+	}
+	desc := func(name, help string, labels ...string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(c.namespace, "", name), help,
+			append([]string{"structure"}, labels...), nil)
+	}
+	c.size = desc("size", "Number of entries held.")
+	c.capacity = desc("capacity", "Number of entries or slots the structure has room for before it grows or evicts.")
+	c.loadFactor = desc("load_factor", "Size divided by capacity.")
+	c.resizes = desc("resizes_total", "Times the structure has replaced its table.")
+	c.probeLength = desc("probe_length", "Estimated quantile of slots examined per lookup.", "quantile")
+	c.hits = desc("cache_hits_total", "Cache lookups that found their key.")
+	c.misses = desc("cache_misses_total", "Cache lookups that missed.")
+	c.evictions = desc("cache_evictions_total", "Entries evicted to make room.")
+	c.hitRatio = desc("cache_hit_ratio", "Fraction of cache lookups that were hits.")
+	return c
+}
+
+// Add exports s under name, replacing any structure added under the same
+// name. It returns an error matching errs.ErrInvalidOption if s has none of
+// the methods listed in the package documentation.
+func (c *Collector) Add(name string, s any) error {
+	switch s.(type) {
+	case sizer, capacitor, resizer, prober, hitCounter:
+	default:
+		return fmt.Errorf("promexport: Add(%q): %T reports no metrics: %w", name, s, errs.ErrInvalidOption)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sources[name] = s
+	return nil
+}
+
+// Remove stops exporting the structure added under name, reporting whether
+// there was one.
+func (c *Collector) Remove(name string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.sources[name]
+	delete(c.sources, name)
+	return ok
+}
+
+// Describe sends the descriptors of every metric the Collector can export.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{
+		c.size, c.capacity, c.loadFactor, c.resizes, c.probeLength,
+		c.hits, c.misses, c.evictions, c.hitRatio,
+	} {
+		ch <- d
+	}
+}
+
+// Collect reads every structure, in name order, and sends its metrics.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	names := make([]string, 0, len(c.sources))
+	for name := range c.sources {
+		names = append(names, name)
+	}
+	sources := make([]any, len(names))
+	sort.Strings(names)
+	for i, name := range names {
+		sources[i] = c.sources[name]
+	}
+	c.mu.Unlock()
+
+	if c.lock != nil {
+		c.lock.Lock()
+		defer c.lock.Unlock()
+	}
+	for i, s := range sources {
+		c.collect(ch, names[i], s)
+	}
+}
+
+func (c *Collector) collect(ch chan<- prometheus.Metric, name string, s any) {
+	gauge := func(d *prometheus.Desc, v float64, labels ...string) {
+		ch <- prometheus.MustNewConstMetric(d, prometheus.GaugeValue, v, append([]string{name}, labels...)...)
+	}
+	counter := func(d *prometheus.Desc, v float64) {
+		ch <- prometheus.MustNewConstMetric(d, prometheus.CounterValue, v, name)
+	}
+
+	size, hasSize := s.(sizer)
+	if hasSize {
+		gauge(c.size, float64(size.Len()))
+	}
+	if cp, ok := s.(capacitor); ok {
+		capacity := cp.Capacity()
+		gauge(c.capacity, float64(capacity))
+		if hasSize && capacity > 0 {
+			gauge(c.loadFactor, float64(size.Len())/float64(capacity))
+		}
+	}
+	if r, ok := s.(resizer); ok {
+		counter(c.resizes, float64(r.Resizes()))
+	}
+	if p, ok := s.(prober); ok {
+		for _, q := range probeQuantiles {
+			if v, ok := p.ProbeQuantile(q); ok && !math.IsNaN(v) {
+				gauge(c.probeLength, v, fmt.Sprint(q))
+			}
+		}
+	}
+	if h, ok := s.(hitCounter); ok {
+		st := h.Stats()
+		counter(c.hits, float64(st.Hits))
+		counter(c.misses, float64(st.Misses))
+		counter(c.evictions, float64(st.Evictions))
+		gauge(c.hitRatio, st.HitRate())
+	}
+}
@@ -0,0 +1,151 @@
+package promexport
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/dsa-lab/go/internal/cache"
+	"github.com/dsa-lab/go/internal/hashmap"
+	"github.com/dsa-lab/go/internal/skiplist"
+)
+
+func TestHashMapMetrics(t *testing.T) {
+	m := hashmap.MustNew(hashmap.WithProbeQuantiles())
+	for i := 0; i < 100; i++ {
+		m.Insert(strconv.Itoa(i), "v")
+	}
+	c := NewCollector()
+	if err := c.Add("users", m); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `
+# HELP dsa_size Number of entries held.
+# TYPE dsa_size gauge
+dsa_size{structure="users"} 100
+# HELP dsa_resizes_total Times the structure has replaced its table.
+# TYPE dsa_resizes_total counter
+dsa_resizes_total{structure="users"} ` + strconv.Itoa(m.Resizes()) + `
+# HELP dsa_capacity Number of entries or slots the structure has room for before it grows or evicts.
+# TYPE dsa_capacity gauge
+dsa_capacity{structure="users"} ` + strconv.Itoa(m.Capacity()) + `
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want), "dsa_size", "dsa_resizes_total", "dsa_capacity"); err != nil {
+		t.Error(err)
+	}
+	if m.Resizes() == 0 {
+		t.Error("expected 100 inserts to resize a 16-slot table")
+	}
+	// Every insert looks its key up, so all three quantiles are estimated.
+	if n := testutil.CollectAndCount(c, "dsa_probe_length"); n != 3 {
+		t.Errorf("expected 3 probe quantiles, got %d", n)
+	}
+	if lf := testutil.ToFloat64(gaugeOnly(c, "dsa_load_factor")); lf <= 0 || lf >= 1 {
+		t.Errorf("expected a load factor in (0, 1), got %v", lf)
+	}
+}
+
+func TestCacheAndSizeOnly(t *testing.T) {
+	lru := cache.MustNewLRU(2)
+	lru.Put("a", "1")
+	lru.Get("a")
+	lru.Get("b")
+	l := skiplist.MustNew[string, string]()
+	l.Insert("k", "v")
+
+	c := NewCollector(WithNamespace("app"))
+	c.Add("lru", lru)
+	c.Add("index", l)
+
+	want := `
+# HELP app_cache_hit_ratio Fraction of cache lookups that were hits.
+# TYPE app_cache_hit_ratio gauge
+app_cache_hit_ratio{structure="lru"} 0.5
+# HELP app_size Number of entries held.
+# TYPE app_size gauge
+app_size{structure="index"} 1
+app_size{structure="lru"} 1
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want), "app_cache_hit_ratio", "app_size"); err != nil {
+		t.Error(err)
+	}
+	// The skip list reports only its size; the cache adds capacity, load
+	// factor and three counters.
+	if n := testutil.CollectAndCount(c); n != 1+7 {
+		t.Errorf("expected 8 metrics, got %d", n)
+	}
+}
+
+func TestAddRemove(t *testing.T) {
+	c := NewCollector()
+	if err := c.Add("x", 42); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("expected ErrInvalidOption, got %v", err)
+	}
+	c.Add("x", hashmap.MustNew())
+	if !c.Remove("x") || c.Remove("x") {
+		t.Error("expected Remove to report true then false")
+	}
+	if n := testutil.CollectAndCount(c); n != 0 {
+		t.Errorf("expected no metrics after Remove, got %d", n)
+	}
+	// The collector must be accepted by a registry, which checks Describe.
+	if err := prometheus.NewRegistry().Register(c); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWithLocker(t *testing.T) {
+	var mu sync.Mutex
+	m := hashmap.MustNew()
+	c := NewCollector(WithLocker(&mu))
+	c.Add("m", m)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			mu.Lock()
+			m.Insert(strconv.Itoa(i), "v")
+			mu.Unlock()
+		}
+	}()
+	for i := 0; i < 20; i++ {
+		testutil.CollectAndCount(c)
+	}
+	wg.Wait()
+	if got := testutil.ToFloat64(gaugeOnly(c, "dsa_size")); got != 1000 {
+		t.Errorf("expected size 1000, got %v", got)
+	}
+}
+
+// gaugeOnly narrows c to the single metric named name, for testutil.ToFloat64.
+func gaugeOnly(c *Collector, name string) prometheus.Collector {
+	return filtered{c, name}
+}
+
+type filtered struct {
+	c    *Collector
+	name string
+}
+
+func (f filtered) Describe(ch chan<- *prometheus.Desc) { f.c.Describe(ch) }
+
+func (f filtered) Collect(ch chan<- prometheus.Metric) {
+	all := make(chan prometheus.Metric)
+	go func() {
+		f.c.Collect(all)
+		close(all)
+	}()
+	for m := range all {
+		if strings.Contains(m.Desc().String(), `"`+f.name+`"`) {
+			ch <- m
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"github.com/dsa-lab/go/internal/order"
+	"github.com/dsa-lab/go/internal/skiplist"
+)
+
+// Entry is a player's standing on the leaderboard. Rank is 1-based.
+type Entry struct {
+	Player string
+	Score  int64
+	Rank   int
+}
+
+// standing orders the skip list: higher scores first, ties broken by name.
+type standing struct {
+	score  int64
+	player string
+}
+
+var compareStanding = order.By(func(s standing) int64 { return s.score }).Reverse().
+	Then(order.By(func(s standing) string { return s.player }))
+
+// Leaderboard ranks players by score. Scores are indexed by player for O(1)
+// lookup, and standings live in an indexable skip list so rank queries,
+// top-N pages and score updates are all O(log n).
+type Leaderboard struct {
+	scores    map[string]int64
+	standings *skiplist.SkipList[standing, struct{}]
+}
+
+// NewLeaderboard creates an empty leaderboard.
+func NewLeaderboard() *Leaderboard {
+	return &Leaderboard{
+		scores:    make(map[string]int64),
+		standings: skiplist.MustNewFunc[standing, struct{}](compareStanding),
+	}
+}
+
+// Len returns the number of ranked players.
+func (l *Leaderboard) Len() int {
+	return len(l.scores)
+}
+
+// Submit sets player's score, replacing any previous score.
+func (l *Leaderboard) Submit(player string, score int64) {
+	if old, found := l.scores[player]; found {
+		l.standings.Remove(standing{old, player})
+	}
+	l.scores[player] = score
+	l.standings.Insert(standing{score, player}, struct{}{})
+}
+
+// Add adjusts player's score by delta and returns the new score.
+func (l *Leaderboard) Add(player string, delta int64) int64 {
+	score := l.scores[player] + delta
+	l.Submit(player, score)
+	return score
+}
+
+// Remove drops player from the leaderboard, reporting whether it was ranked.
+func (l *Leaderboard) Remove(player string) bool {
+	score, found := l.scores[player]
+	if !found {
+		return false
+	}
+	delete(l.scores, player)
+	l.standings.Remove(standing{score, player})
+	return true
+}
+
+// Score returns player's current score.
+func (l *Leaderboard) Score(player string) (int64, bool) {
+	score, found := l.scores[player]
+	return score, found
+}
+
+// Rank returns player's 1-based rank.
+func (l *Leaderboard) Rank(player string) (int, bool) {
+	score, found := l.scores[player]
+	if !found {
+		return 0, false
+	}
+	rank, _ := l.standings.Rank(standing{score, player})
+	return rank + 1, true
+}
+
+// Top returns the n highest-ranked players.
+func (l *Leaderboard) Top(n int) []Entry {
+	return l.Page(0, n)
+}
+
+// Page returns up to n entries starting at zero-based position offset. The
+// page is empty if n < 1 or offset < 0.
+func (l *Leaderboard) Page(offset, n int) []Entry {
+	if n < 1 || offset < 0 {
+		return nil
+	}
+	entries := make([]Entry, 0, n)
+	rank := offset
+	l.standings.RangeFrom(offset, func(s standing, _ struct{}) bool {
+		if len(entries) == n {
+			return false
+		}
+		rank++
+		entries = append(entries, Entry{Player: s.player, Score: s.score, Rank: rank})
+		return true
+	})
+	return entries
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/workload"
+)
+
+func TestLeaderboardRanking(t *testing.T) {
+	l := NewLeaderboard()
+	l.Submit("alice", 300)
+	l.Submit("bob", 500)
+	l.Submit("carol", 300)
+
+	if rank, _ := l.Rank("bob"); rank != 1 {
+		t.Errorf("expected bob rank 1, got %d", rank)
+	}
+	// Ties are broken alphabetically.
+	if rank, _ := l.Rank("alice"); rank != 2 {
+		t.Errorf("expected alice rank 2, got %d", rank)
+	}
+
+	if score := l.Add("carol", 250); score != 550 {
+		t.Errorf("expected carol score 550, got %d", score)
+	}
+	top := l.Top(2)
+	if len(top) != 2 || top[0].Player != "carol" || top[1].Player != "bob" {
+		t.Errorf("unexpected top 2: %+v", top)
+	}
+
+	if !l.Remove("carol") || l.Remove("carol") {
+		t.Error("remove should succeed exactly once")
+	}
+	if _, found := l.Rank("carol"); found {
+		t.Error("removed player should not be ranked")
+	}
+	if l.Len() != 2 {
+		t.Errorf("expected 2 players, got %d", l.Len())
+	}
+}
+
+func TestLeaderboardOracle(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	l := NewLeaderboard()
+	scores := make(map[string]int64)
+
+	for i := 0; i < 5000; i++ {
+		player := fmt.Sprintf("p%d", rng.Intn(200))
+		delta := int64(rng.Intn(100))
+		l.Add(player, delta)
+		scores[player] += delta
+	}
+
+	players := make([]string, 0, len(scores))
+	for p := range scores {
+		players = append(players, p)
+	}
+	sort.Slice(players, func(i, j int) bool {
+		a, b := players[i], players[j]
+		if scores[a] != scores[b] {
+			return scores[a] > scores[b]
+		}
+		return a < b
+	})
+
+	for i, p := range players {
+		if rank, _ := l.Rank(p); rank != i+1 {
+			t.Fatalf("rank(%s) = %d; want %d", p, rank, i+1)
+		}
+	}
+	page := l.Page(10, 5)
+	for i, e := range page {
+		if e.Player != players[10+i] || e.Rank != 11+i {
+			t.Errorf("page entry %d = %+v; want %s at rank %d", i, e, players[10+i], 11+i)
+		}
+	}
+	for _, bad := range [][2]int{{0, 0}, {0, -1}, {-1, 5}} {
+		if page := l.Page(bad[0], bad[1]); len(page) != 0 {
+			t.Errorf("Page(%d, %d) = %+v; want an empty page", bad[0], bad[1], page)
+		}
+	}
+}
+
+func BenchmarkReplay(b *testing.B) {
+	path := filepath.Join("..", "..", "..", "..", "workloads", "leaderboard", "score_updates_medium.json")
+	w, err := workload.Load(path)
+	if err != nil {
+		b.Skip("workload not found:", err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := replay(NewLeaderboard(), w.Operations); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+// Command leaderboard is an end-to-end demo of the lab's ordered structures:
+// a game leaderboard answering rank, top-N and score-update requests on an
+// indexable skip list. It replays a score-update workload produced by
+// tools/gen_workloads.py and prints the final standings.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/dsa-lab/go/internal/workload"
+)
+
+// Operation names used in leaderboard workloads.
+const (
+	OpUpdate = "update"
+	OpRank   = "rank"
+	OpTop    = "top"
+)
+
+func main() {
+	path := flag.String("workload", "../../workloads/leaderboard/score_updates_medium.json", "leaderboard workload file")
+	top := flag.Int("top", 10, "number of leaders to print")
+	flag.Parse()
+
+	w, err := workload.Load(*path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "load:", err)
+		os.Exit(1)
+	}
+
+	l := NewLeaderboard()
+	start := time.Now()
+	if err := replay(l, w.Operations); err != nil {
+		fmt.Fprintln(os.Stderr, "replay:", err)
+		os.Exit(1)
+	}
+	elapsed := time.Since(start)
+
+	fmt.Printf("%s: %d ops over %d players in %v\n", w.Name, len(w.Operations), l.Len(), elapsed)
+	for _, e := range l.Top(*top) {
+		fmt.Printf("%4d  %-16s %d\n", e.Rank, e.Player, e.Score)
+	}
+}
+
+// replay applies leaderboard operations. Update values are score deltas and
+// top values are page sizes.
+func replay(l *Leaderboard, ops []workload.Operation) error {
+	for i, op := range ops {
+		switch op.Op {
+		case OpUpdate:
+			delta, err := strconv.ParseInt(op.Value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("operation %d: %w", i, err)
+			}
+			l.Add(op.Key, delta)
+		case OpRank:
+			l.Rank(op.Key)
+		case OpTop:
+			n, err := strconv.Atoi(op.Value)
+			if err != nil {
+				return fmt.Errorf("operation %d: %w", i, err)
+			}
+			l.Top(n)
+		default:
+			return fmt.Errorf("operation %d: unknown op %q", i, op.Op)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+// Command profile is a demo of cmd/dsagen: a user-profile service written
+// against typed wrappers generated from schema.json instead of the
+// string-keyed hashmap and the generic skip list they wrap. It records a few
+// sessions, scores and logins and prints a summary.
+//
+// After editing schema.json, regenerate the wrappers with go generate.
+package main
+
+//go:generate go run ../../cmd/dsagen -schema schema.json -out profile_gen.go
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Award adds points to player's score, returning the new total. It takes
+// ScoresAPI so tests can substitute a MockScores.
+func Award(s ScoresAPI, player string, points int64) int64 {
+	total, _ := s.Get(player)
+	total += points
+	s.Insert(player, total)
+	return total
+}
+
+// CanSpend reports whether the session may spend cost from its quota: admins
+// always may, and a session without a quota may not.
+func CanSpend(s SessionAPI, cost float64) bool {
+	if admin, _ := s.Admin(); admin {
+		return true
+	}
+	quota, ok := s.Quota()
+	return ok && quota >= cost
+}
+
+// LoginsBetween returns the users who logged in during [from, to), in time
+// order.
+func LoginsBetween(l *Logins, from, to int64) []string {
+	var users []string
+	l.Scan(from, to, func(_ int64, user string) bool {
+		users = append(users, user)
+		return true
+	})
+	return users
+}
+
+func main() {
+	scores := MustNewScores()
+	for _, p := range []string{"alice", "bob", "alice", "carol", "alice", "bob"} {
+		Award(scores, p, 10)
+	}
+	var players []string
+	scores.Range(func(player string, _ int64) bool {
+		players = append(players, player)
+		return true
+	})
+	sort.Strings(players)
+	for _, p := range players {
+		total, _ := scores.Get(p)
+		fmt.Printf("%-6s %3d points\n", p, total)
+	}
+
+	logins := NewLogins()
+	for i, user := range []string{"alice", "bob", "carol", "bob"} {
+		logins.Insert(int64(1000+60*i), user)
+	}
+	fmt.Println("logins in [1000, 1120):", LoginsBetween(logins, 1000, 1120))
+	if at, user, ok := logins.Floor(1150); ok {
+		fmt.Printf("last login by t=1150: %s at %d\n", user, at)
+	}
+
+	session := MustNewSession()
+	session.SetUserID(42)
+	session.SetQuota(2.5)
+	uid, _ := session.UserID()
+	fmt.Printf("user %d can spend 2: %v, can spend 3: %v\n", uid, CanSpend(session, 2), CanSpend(session, 3))
+}
@@ -0,0 +1,480 @@
+// Code generated by dsagen from schema.json. DO NOT EDIT.
+
+package main
+
+import (
+	"strconv"
+
+	"github.com/dsa-lab/go/pkg/hashmap"
+	"github.com/dsa-lab/go/pkg/skiplist"
+)
+
+// Scores maps string keys to int64 values in a hashmap.Map, which
+// holds both encoded as strings.
+type Scores struct {
+	m *hashmap.Map
+}
+
+// NewScores creates an empty Scores configured by opts.
+func NewScores(opts ...hashmap.Option) (*Scores, error) {
+	m, err := hashmap.New(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Scores{m: m}, nil
+}
+
+// MustNewScores is like NewScores but panics if an option is invalid.
+func MustNewScores(opts ...hashmap.Option) *Scores {
+	return &Scores{m: hashmap.MustNew(opts...)}
+}
+
+// Len returns the number of entries.
+func (s *Scores) Len() int {
+	return s.m.Len()
+}
+
+// Get returns the value for key and whether it is present.
+func (s *Scores) Get(key string) (int64, bool) {
+	v, ok := s.m.Get(key)
+	if !ok {
+		var zero int64
+		return zero, false
+	}
+	return decodeInt64(v), true
+}
+
+// Insert sets the value for key, returning the previous value and whether
+// there was one.
+func (s *Scores) Insert(key string, value int64) (int64, bool) {
+	v, ok := s.m.Insert(key, encodeInt64(value))
+	if !ok {
+		var zero int64
+		return zero, false
+	}
+	return decodeInt64(v), true
+}
+
+// Remove deletes key, returning its value and whether it was present.
+func (s *Scores) Remove(key string) (int64, bool) {
+	v, ok := s.m.Remove(key)
+	if !ok {
+		var zero int64
+		return zero, false
+	}
+	return decodeInt64(v), true
+}
+
+// Contains reports whether key is present.
+func (s *Scores) Contains(key string) bool {
+	return s.m.Contains(key)
+}
+
+// Clear removes every entry.
+func (s *Scores) Clear() {
+	s.m.Clear()
+}
+
+// Range calls f for each entry, in no particular order, until f returns
+// false.
+func (s *Scores) Range(f func(key string, value int64) bool) {
+	s.m.Range(func(k, v string) bool {
+		return f(k, decodeInt64(v))
+	})
+}
+
+// ScoresAPI is the method set of Scores, for code that should also
+// accept MockScores.
+type ScoresAPI interface {
+	Len() int
+	Get(key string) (int64, bool)
+	Insert(key string, value int64) (int64, bool)
+	Remove(key string) (int64, bool)
+	Contains(key string) bool
+	Clear()
+	Range(f func(key string, value int64) bool)
+}
+
+var (
+	_ ScoresAPI = (*Scores)(nil)
+	_ ScoresAPI = (*MockScores)(nil)
+)
+
+// MockScores implements ScoresAPI by calling the function field
+// named after each method. Calling a method whose field is nil panics.
+type MockScores struct {
+	LenFunc      func() int
+	GetFunc      func(key string) (int64, bool)
+	InsertFunc   func(key string, value int64) (int64, bool)
+	RemoveFunc   func(key string) (int64, bool)
+	ContainsFunc func(key string) bool
+	ClearFunc    func()
+	RangeFunc    func(f func(key string, value int64) bool)
+}
+
+// Len calls LenFunc.
+func (m *MockScores) Len() int {
+	if m.LenFunc == nil {
+		panic("MockScores.Len called with nil LenFunc")
+	}
+	return m.LenFunc()
+}
+
+// Get calls GetFunc.
+func (m *MockScores) Get(key string) (int64, bool) {
+	if m.GetFunc == nil {
+		panic("MockScores.Get called with nil GetFunc")
+	}
+	return m.GetFunc(key)
+}
+
+// Insert calls InsertFunc.
+func (m *MockScores) Insert(key string, value int64) (int64, bool) {
+	if m.InsertFunc == nil {
+		panic("MockScores.Insert called with nil InsertFunc")
+	}
+	return m.InsertFunc(key, value)
+}
+
+// Remove calls RemoveFunc.
+func (m *MockScores) Remove(key string) (int64, bool) {
+	if m.RemoveFunc == nil {
+		panic("MockScores.Remove called with nil RemoveFunc")
+	}
+	return m.RemoveFunc(key)
+}
+
+// Contains calls ContainsFunc.
+func (m *MockScores) Contains(key string) bool {
+	if m.ContainsFunc == nil {
+		panic("MockScores.Contains called with nil ContainsFunc")
+	}
+	return m.ContainsFunc(key)
+}
+
+// Clear calls ClearFunc.
+func (m *MockScores) Clear() {
+	if m.ClearFunc == nil {
+		panic("MockScores.Clear called with nil ClearFunc")
+	}
+	m.ClearFunc()
+}
+
+// Range calls RangeFunc.
+func (m *MockScores) Range(f func(key string, value int64) bool) {
+	if m.RangeFunc == nil {
+		panic("MockScores.Range called with nil RangeFunc")
+	}
+	m.RangeFunc(f)
+}
+
+// Logins maps int64 keys to string values in a skiplist.List, in
+// ascending key order.
+type Logins struct {
+	m *skiplist.List[int64, string]
+}
+
+// NewLogins creates an empty Logins.
+func NewLogins() *Logins {
+	return &Logins{m: skiplist.New[int64, string]()}
+}
+
+// Len returns the number of entries.
+func (l *Logins) Len() int {
+	return l.m.Len()
+}
+
+// Get returns the value for key and whether it is present.
+func (l *Logins) Get(key int64) (string, bool) {
+	return l.m.Get(key)
+}
+
+// Insert sets the value for key, returning the previous value and whether
+// there was one.
+func (l *Logins) Insert(key int64, value string) (string, bool) {
+	return l.m.Insert(key, value)
+}
+
+// Remove deletes key, returning its value and whether it was present.
+func (l *Logins) Remove(key int64) (string, bool) {
+	return l.m.Remove(key)
+}
+
+// Contains reports whether key is present.
+func (l *Logins) Contains(key int64) bool {
+	return l.m.Contains(key)
+}
+
+// Clear removes every entry.
+func (l *Logins) Clear() {
+	l.m.Clear()
+}
+
+// Range calls f for each entry in key order until f returns false.
+func (l *Logins) Range(f func(key int64, value string) bool) {
+	l.m.Range(f)
+}
+
+// Floor returns the entry with the greatest key at most key.
+func (l *Logins) Floor(key int64) (int64, string, bool) {
+	return l.m.Floor(key)
+}
+
+// Ceiling returns the entry with the least key at least key.
+func (l *Logins) Ceiling(key int64) (int64, string, bool) {
+	return l.m.Ceiling(key)
+}
+
+// Scan calls f for each entry with lo <= key < hi in key order until f
+// returns false.
+func (l *Logins) Scan(lo, hi int64, f func(key int64, value string) bool) {
+	l.m.Scan(lo, hi, f)
+}
+
+// Session is a record of typed fields held in a hashmap.Map, each under
+// its own key. A field is unset until its setter is called.
+type Session struct {
+	m *hashmap.Map
+}
+
+// NewSession creates a Session with every field unset.
+func NewSession(opts ...hashmap.Option) (*Session, error) {
+	m, err := hashmap.New(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Session{m: m}, nil
+}
+
+// MustNewSession is like NewSession but panics if an option is invalid.
+func MustNewSession(opts ...hashmap.Option) *Session {
+	return &Session{m: hashmap.MustNew(opts...)}
+}
+
+// UserID returns the UserID field and whether it is set.
+func (s *Session) UserID() (uint64, bool) {
+	v, ok := s.m.Get("uid")
+	if !ok {
+		var zero uint64
+		return zero, false
+	}
+	return decodeUint64(v), true
+}
+
+// SetUserID sets the UserID field to v.
+func (s *Session) SetUserID(v uint64) {
+	s.m.Insert("uid", encodeUint64(v))
+}
+
+// ClearUserID unsets the UserID field, reporting whether it was set.
+func (s *Session) ClearUserID() bool {
+	_, ok := s.m.Remove("uid")
+	return ok
+}
+
+// Admin returns the Admin field and whether it is set.
+func (s *Session) Admin() (bool, bool) {
+	v, ok := s.m.Get("Admin")
+	if !ok {
+		var zero bool
+		return zero, false
+	}
+	return decodeBool(v), true
+}
+
+// SetAdmin sets the Admin field to v.
+func (s *Session) SetAdmin(v bool) {
+	s.m.Insert("Admin", encodeBool(v))
+}
+
+// ClearAdmin unsets the Admin field, reporting whether it was set.
+func (s *Session) ClearAdmin() bool {
+	_, ok := s.m.Remove("Admin")
+	return ok
+}
+
+// Quota returns the Quota field and whether it is set.
+func (s *Session) Quota() (float64, bool) {
+	v, ok := s.m.Get("Quota")
+	if !ok {
+		var zero float64
+		return zero, false
+	}
+	return decodeFloat64(v), true
+}
+
+// SetQuota sets the Quota field to v.
+func (s *Session) SetQuota(v float64) {
+	s.m.Insert("Quota", encodeFloat64(v))
+}
+
+// ClearQuota unsets the Quota field, reporting whether it was set.
+func (s *Session) ClearQuota() bool {
+	_, ok := s.m.Remove("Quota")
+	return ok
+}
+
+// SessionAPI is the method set of Session, for code that should also
+// accept MockSession.
+type SessionAPI interface {
+	UserID() (uint64, bool)
+	SetUserID(v uint64)
+	ClearUserID() bool
+	Admin() (bool, bool)
+	SetAdmin(v bool)
+	ClearAdmin() bool
+	Quota() (float64, bool)
+	SetQuota(v float64)
+	ClearQuota() bool
+}
+
+var (
+	_ SessionAPI = (*Session)(nil)
+	_ SessionAPI = (*MockSession)(nil)
+)
+
+// MockSession implements SessionAPI by calling the function field
+// named after each method. Calling a method whose field is nil panics.
+type MockSession struct {
+	UserIDFunc      func() (uint64, bool)
+	SetUserIDFunc   func(v uint64)
+	ClearUserIDFunc func() bool
+	AdminFunc       func() (bool, bool)
+	SetAdminFunc    func(v bool)
+	ClearAdminFunc  func() bool
+	QuotaFunc       func() (float64, bool)
+	SetQuotaFunc    func(v float64)
+	ClearQuotaFunc  func() bool
+}
+
+// UserID calls UserIDFunc.
+func (m *MockSession) UserID() (uint64, bool) {
+	if m.UserIDFunc == nil {
+		panic("MockSession.UserID called with nil UserIDFunc")
+	}
+	return m.UserIDFunc()
+}
+
+// SetUserID calls SetUserIDFunc.
+func (m *MockSession) SetUserID(v uint64) {
+	if m.SetUserIDFunc == nil {
+		panic("MockSession.SetUserID called with nil SetUserIDFunc")
+	}
+	m.SetUserIDFunc(v)
+}
+
+// ClearUserID calls ClearUserIDFunc.
+func (m *MockSession) ClearUserID() bool {
+	if m.ClearUserIDFunc == nil {
+		panic("MockSession.ClearUserID called with nil ClearUserIDFunc")
+	}
+	return m.ClearUserIDFunc()
+}
+
+// Admin calls AdminFunc.
+func (m *MockSession) Admin() (bool, bool) {
+	if m.AdminFunc == nil {
+		panic("MockSession.Admin called with nil AdminFunc")
+	}
+	return m.AdminFunc()
+}
+
+// SetAdmin calls SetAdminFunc.
+func (m *MockSession) SetAdmin(v bool) {
+	if m.SetAdminFunc == nil {
+		panic("MockSession.SetAdmin called with nil SetAdminFunc")
+	}
+	m.SetAdminFunc(v)
+}
+
+// ClearAdmin calls ClearAdminFunc.
+func (m *MockSession) ClearAdmin() bool {
+	if m.ClearAdminFunc == nil {
+		panic("MockSession.ClearAdmin called with nil ClearAdminFunc")
+	}
+	return m.ClearAdminFunc()
+}
+
+// Quota calls QuotaFunc.
+func (m *MockSession) Quota() (float64, bool) {
+	if m.QuotaFunc == nil {
+		panic("MockSession.Quota called with nil QuotaFunc")
+	}
+	return m.QuotaFunc()
+}
+
+// SetQuota calls SetQuotaFunc.
+func (m *MockSession) SetQuota(v float64) {
+	if m.SetQuotaFunc == nil {
+		panic("MockSession.SetQuota called with nil SetQuotaFunc")
+	}
+	m.SetQuotaFunc(v)
+}
+
+// ClearQuota calls ClearQuotaFunc.
+func (m *MockSession) ClearQuota() bool {
+	if m.ClearQuotaFunc == nil {
+		panic("MockSession.ClearQuota called with nil ClearQuotaFunc")
+	}
+	return m.ClearQuotaFunc()
+}
+
+func encodeBool(v bool) string {
+	return strconv.FormatBool(v)
+}
+
+// decodeBool parses a value written by encodeBool. Only the
+// generated wrappers write to their maps, so a failure means memory was
+// corrupted.
+func decodeBool(s string) bool {
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		panic("main: stored bool " + strconv.Quote(s) + " does not parse")
+	}
+	return v
+}
+
+func encodeFloat64(v float64) string {
+	return strconv.FormatFloat(float64(v), 'g', -1, 64)
+}
+
+// decodeFloat64 parses a value written by encodeFloat64. Only the
+// generated wrappers write to their maps, so a failure means memory was
+// corrupted.
+func decodeFloat64(s string) float64 {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		panic("main: stored float64 " + strconv.Quote(s) + " does not parse")
+	}
+	return v
+}
+
+func encodeInt64(v int64) string {
+	return strconv.FormatInt(int64(v), 10)
+}
+
+// decodeInt64 parses a value written by encodeInt64. Only the
+// generated wrappers write to their maps, so a failure means memory was
+// corrupted.
+func decodeInt64(s string) int64 {
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		panic("main: stored int64 " + strconv.Quote(s) + " does not parse")
+	}
+	return v
+}
+
+func encodeUint64(v uint64) string {
+	return strconv.FormatUint(uint64(v), 10)
+}
+
+// decodeUint64 parses a value written by encodeUint64. Only the
+// generated wrappers write to their maps, so a failure means memory was
+// corrupted.
+func decodeUint64(s string) uint64 {
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		panic("main: stored uint64 " + strconv.Quote(s) + " does not parse")
+	}
+	return v
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/dsa-lab/go/pkg/hashmap"
+)
+
+func TestScoresRoundTrip(t *testing.T) {
+	s := MustNewScores()
+	for _, v := range []int64{0, -1, math.MaxInt64, math.MinInt64} {
+		if _, existed := s.Insert("p", v); existed != (v != 0) {
+			t.Errorf("expected existed=%v inserting %d, got %v", v != 0, v, existed)
+		}
+		if got, ok := s.Get("p"); !ok || got != v {
+			t.Errorf("expected %d, got %d (ok=%v)", v, got, ok)
+		}
+	}
+	if old, ok := s.Remove("p"); !ok || old != math.MinInt64 {
+		t.Errorf("expected to remove %d, got %d (ok=%v)", int64(math.MinInt64), old, ok)
+	}
+	if _, ok := s.Get("p"); ok || s.Len() != 0 {
+		t.Errorf("expected an empty map after Remove, got len %d", s.Len())
+	}
+}
+
+func TestNewScoresRejectsInvalidOption(t *testing.T) {
+	if _, err := NewScores(hashmap.WithCapacity(-1)); !errors.Is(err, hashmap.ErrInvalidOption) {
+		t.Errorf("expected ErrInvalidOption, got %v", err)
+	}
+}
+
+func TestAward(t *testing.T) {
+	s := MustNewScores()
+	Award(s, "alice", 5)
+	if got := Award(s, "alice", 7); got != 12 {
+		t.Errorf("expected 12, got %d", got)
+	}
+}
+
+func TestAwardWithMock(t *testing.T) {
+	var stored int64
+	m := &MockScores{
+		GetFunc: func(key string) (int64, bool) { return 40, true },
+		InsertFunc: func(key string, value int64) (int64, bool) {
+			stored = value
+			return 40, true
+		},
+	}
+	if got := Award(m, "bob", 2); got != 42 || stored != 42 {
+		t.Errorf("expected 42 returned and stored, got %d and %d", got, stored)
+	}
+}
+
+func TestMockPanicsOnUnsetFunc(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic calling a method with no func set")
+		}
+	}()
+	(&MockScores{}).Len()
+}
+
+func TestSessionFields(t *testing.T) {
+	s := MustNewSession()
+	if _, ok := s.UserID(); ok {
+		t.Error("expected UserID unset on a new session")
+	}
+	s.SetUserID(math.MaxUint64)
+	s.SetAdmin(true)
+	s.SetQuota(0.1)
+	if uid, ok := s.UserID(); !ok || uid != math.MaxUint64 {
+		t.Errorf("expected UserID %d, got %d (ok=%v)", uint64(math.MaxUint64), uid, ok)
+	}
+	if q, ok := s.Quota(); !ok || q != 0.1 {
+		t.Errorf("expected Quota 0.1, got %v (ok=%v)", q, ok)
+	}
+	if !s.ClearAdmin() || s.ClearAdmin() {
+		t.Error("expected ClearAdmin to report true then false")
+	}
+	if admin, ok := s.Admin(); ok || admin {
+		t.Errorf("expected Admin unset, got %v (ok=%v)", admin, ok)
+	}
+}
+
+func TestCanSpendWithMock(t *testing.T) {
+	m := &MockSession{
+		AdminFunc: func() (bool, bool) { return false, false },
+		QuotaFunc: func() (float64, bool) { return 0, false },
+	}
+	if CanSpend(m, 0) {
+		t.Error("expected a session without a quota not to spend")
+	}
+	m.AdminFunc = func() (bool, bool) { return true, true }
+	if !CanSpend(m, 1e9) {
+		t.Error("expected an admin to spend anything")
+	}
+}
+
+func TestLoginsOrdered(t *testing.T) {
+	l := NewLogins()
+	l.Insert(30, "carol")
+	l.Insert(10, "alice")
+	l.Insert(20, "bob")
+	if got := LoginsBetween(l, 10, 30); !reflect.DeepEqual(got, []string{"alice", "bob"}) {
+		t.Errorf("expected [alice bob], got %v", got)
+	}
+	if at, user, ok := l.Ceiling(11); !ok || at != 20 || user != "bob" {
+		t.Errorf("expected bob at 20, got %s at %d (ok=%v)", user, at, ok)
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/dsa-lab/go/internal/cache"
+	"github.com/dsa-lab/go/internal/kv"
+	"github.com/dsa-lab/go/internal/wal"
+)
+
+const codeAlphabet = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// Shortener maps short codes to URLs. The store holds every mapping, an LRU
+// cache absorbs hot lookups, and every mutation is appended to a write-ahead
+// journal so the store can be rebuilt after a restart.
+//
+// A journal record is "+" code " " URL for a new mapping and "-" code for a
+// deletion. The log checksums each record, so a torn or damaged one is caught
+// on restore rather than replayed.
+type Shortener struct {
+	store   kv.Map
+	cache   *cache.LRU
+	journal *wal.Log
+	next    uint64
+}
+
+// NewShortener creates a Shortener over store with an LRU of cacheSize entries.
+// Mutations are appended to journal and durable once it is synced; a nil
+// journal disables durability.
+func NewShortener(store kv.Map, cacheSize int, journal *wal.Log) *Shortener {
+	return &Shortener{
+		store:   store,
+		cache:   cache.MustNewLRU(cacheSize),
+		journal: journal,
+	}
+}
+
+// encode renders n in base 62.
+func encode(n uint64) string {
+	if n == 0 {
+		return codeAlphabet[:1]
+	}
+	var buf [11]byte
+	i := len(buf)
+	for n > 0 {
+		i--
+		buf[i] = codeAlphabet[n%62]
+		n /= 62
+	}
+	return string(buf[i:])
+}
+
+// decode parses a base 62 code, reporting whether it is well formed.
+func decode(code string) (uint64, bool) {
+	if code == "" || len(code) > 11 {
+		return 0, false
+	}
+	var n uint64
+	for i := 0; i < len(code); i++ {
+		d := strings.IndexByte(codeAlphabet, code[i])
+		if d < 0 {
+			return 0, false
+		}
+		n = n*62 + uint64(d)
+	}
+	return n, true
+}
+
+func (s *Shortener) log(record string) error {
+	if s.journal == nil {
+		return nil
+	}
+	return s.journal.Append([]byte(record))
+}
+
+// Shorten stores url under a fresh code and returns the code. A URL holding
+// control characters is rejected: valid URLs percent-encode them.
+func (s *Shortener) Shorten(url string) (string, error) {
+	if strings.ContainsFunc(url, unicode.IsControl) {
+		return "", fmt.Errorf("shorten %q: URL contains a control character", url)
+	}
+	code := encode(s.next)
+	s.next++
+	if err := s.log("+" + code + " " + url); err != nil {
+		return "", err
+	}
+	s.store.Insert(code, url)
+	return code, nil
+}
+
+// Resolve returns the URL for code, consulting the cache before the store.
+func (s *Shortener) Resolve(code string) (string, bool) {
+	if url, found := s.cache.Get(code); found {
+		return url, true
+	}
+	url, found := s.store.Get(code)
+	if found {
+		s.cache.Put(code, url)
+	}
+	return url, found
+}
+
+// Delete removes code, reporting whether it existed. Only a deletion of an
+// existing code is journaled, so a request naming a malformed or unknown
+// code leaves no record for Restore to trip over.
+func (s *Shortener) Delete(code string) (bool, error) {
+	if _, ok := decode(code); !ok || !s.store.Contains(code) {
+		return false, nil
+	}
+	if err := s.log("-" + code); err != nil {
+		return false, err
+	}
+	s.cache.Remove(code)
+	s.store.Remove(code)
+	return true, nil
+}
+
+// CacheStats returns the LRU hit/miss counters.
+func (s *Shortener) CacheStats() cache.Stats {
+	return s.cache.Stats()
+}
+
+// Restore replays journal into the store and advances the code counter past
+// every code seen, deleted or not, so new codes never collide with restored
+// ones. A damaged record fails the restore with an error matching
+// wal.ErrCorrupt.
+func (s *Shortener) Restore(journal *wal.Log) error {
+	it := journal.Iterator()
+	defer it.Close()
+	for i := 1; it.Next(); i++ {
+		record := string(it.Record())
+		op, body := "", record
+		if record != "" {
+			op, body = record[:1], record[1:]
+		}
+		code, url, hasURL := strings.Cut(body, " ")
+		n, ok := decode(code)
+		switch {
+		case ok && op == "+" && hasURL:
+			s.store.Insert(code, url)
+		case ok && op == "-" && !hasURL:
+			s.store.Remove(code)
+		default:
+			return fmt.Errorf("journal record %d: malformed record %q", i, record)
+		}
+		s.next = max(s.next, n+1)
+	}
+	return it.Err()
+}
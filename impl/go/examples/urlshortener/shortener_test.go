@@ -0,0 +1,176 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/hashmap"
+	"github.com/dsa-lab/go/internal/wal"
+	"github.com/dsa-lab/go/internal/workload"
+)
+
+func TestShortenAndResolve(t *testing.T) {
+	s := NewShortener(hashmap.MustNew(), 2, nil)
+	code, err := s.Shorten("https://example.com/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		url, found := s.Resolve(code)
+		if !found || url != "https://example.com/a" {
+			t.Fatalf("resolve returned %q, %v", url, found)
+		}
+	}
+	if stats := s.CacheStats(); stats.Hits != 2 || stats.Misses != 1 {
+		t.Errorf("unexpected cache stats %+v", stats)
+	}
+
+	if existed, _ := s.Delete(code); !existed {
+		t.Error("delete should report existing code")
+	}
+	if _, found := s.Resolve(code); found {
+		t.Error("deleted code should not resolve from cache or store")
+	}
+}
+
+// openJournal opens a write-ahead log in a fresh directory, returning its
+// directory so a test can reopen it as a restart would.
+func openJournal(t *testing.T) (*wal.Log, string) {
+	t.Helper()
+	dir := t.TempDir()
+	journal, err := wal.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { journal.Close() })
+	return journal, dir
+}
+
+// reopen closes journal and restores a new Shortener from its directory.
+func reopen(t *testing.T, journal *wal.Log, dir string) *Shortener {
+	t.Helper()
+	if err := journal.Close(); err != nil {
+		t.Fatal(err)
+	}
+	journal, err := wal.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { journal.Close() })
+	restored := NewShortener(hashmap.MustNew(), 4, journal)
+	if err := restored.Restore(journal); err != nil {
+		t.Fatal(err)
+	}
+	return restored
+}
+
+func TestJournalRestore(t *testing.T) {
+	journal, dir := openJournal(t)
+	s := NewShortener(hashmap.MustNew(), 4, journal)
+	a, _ := s.Shorten("https://example.com/a")
+	b, _ := s.Shorten("https://example.com/b")
+	s.Delete(a)
+
+	restored := reopen(t, journal, dir)
+	if _, found := restored.Resolve(a); found {
+		t.Error("deleted code should stay deleted after restore")
+	}
+	if url, found := restored.Resolve(b); !found || url != "https://example.com/b" {
+		t.Errorf("restored resolve returned %q, %v", url, found)
+	}
+	if c, _ := restored.Shorten("https://example.com/c"); c == a || c == b {
+		t.Errorf("new code %s collides with restored codes", c)
+	}
+}
+
+// TestRestoreSkipsDeletedCodes restores a journal holding codes other than
+// 0, 1, 2 and so on, the highest of them deleted. Counting additions would
+// eventually hand out 5 again; the counter must instead move past z.
+func TestRestoreSkipsDeletedCodes(t *testing.T) {
+	journal, dir := openJournal(t)
+	for _, record := range []string{"+z https://example.com/z", "-z", "+5 https://example.com/5"} {
+		if err := journal.Append([]byte(record)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	restored := reopen(t, journal, dir)
+	if url, found := restored.Resolve("5"); !found || url != "https://example.com/5" {
+		t.Errorf("restored resolve returned %q, %v", url, found)
+	}
+	if code, _ := restored.Shorten("https://example.com/new"); code != "A" {
+		t.Errorf("expected the code after z, A, got %s", code)
+	}
+}
+
+func TestRestoreRejectsMalformedRecords(t *testing.T) {
+	for _, record := range []string{"", "+", "+z", "-", "-z extra", "*z", "+z! https://example.com"} {
+		journal, _ := openJournal(t)
+		journal.Append([]byte(record))
+		if err := NewShortener(hashmap.MustNew(), 4, nil).Restore(journal); err == nil {
+			t.Errorf("record %q: expected an error", record)
+		}
+	}
+}
+
+// TestDeleteInvalidCodes deletes codes that are malformed, contain a space,
+// or were never issued; none may reach the journal and break a restore.
+func TestDeleteInvalidCodes(t *testing.T) {
+	journal, dir := openJournal(t)
+	s := NewShortener(hashmap.MustNew(), 4, journal)
+	code, _ := s.Shorten("https://example.com/a")
+	size := journal.Size()
+	for _, bad := range []string{"", "a b", "z!", "zzzzzzzzzzzz", "9"} {
+		if existed, err := s.Delete(bad); existed || err != nil {
+			t.Errorf("Delete(%q): expected (false, nil), got (%v, %v)", bad, existed, err)
+		}
+	}
+	if journal.Size() != size {
+		t.Errorf("expected the journal to stay at %d bytes, got %d", size, journal.Size())
+	}
+
+	restored := reopen(t, journal, dir)
+	if url, found := restored.Resolve(code); !found || url != "https://example.com/a" {
+		t.Errorf("restored resolve returned %q, %v", url, found)
+	}
+}
+
+func TestShortenRejectsControlCharacters(t *testing.T) {
+	journal, dir := openJournal(t)
+	s := NewShortener(hashmap.MustNew(), 4, journal)
+	for _, url := range []string{"https://example.com/a\tb", "https://example.com/a\nb", "https://example.com/\x00"} {
+		if code, err := s.Shorten(url); err == nil {
+			t.Errorf("Shorten(%q): expected an error, got code %s", url, code)
+		}
+	}
+	if journal.Size() != 0 {
+		t.Errorf("expected an empty journal, got %d bytes", journal.Size())
+	}
+	code, err := s.Shorten("https://example.com/a%09b")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restored := reopen(t, journal, dir)
+	if url, found := restored.Resolve(code); !found || url != "https://example.com/a%09b" {
+		t.Errorf("restored resolve returned %q, %v", url, found)
+	}
+}
+
+func TestSimulateRecordsTraffic(t *testing.T) {
+	recorder := workload.NewRecorder(hashmap.MustNew())
+	s := NewShortener(recorder, 16, nil)
+	if err := simulate(s, rand.New(rand.NewSource(1)), 1000); err != nil {
+		t.Fatal(err)
+	}
+
+	w := recorder.Workload("trace", "")
+	if len(w.Operations) == 0 {
+		t.Fatal("expected recorded operations")
+	}
+	// Cache hits never reach the store, so the trace has fewer gets than requests.
+	if w.OperationWeights[workload.OpInsert] == 0 || w.OperationWeights[workload.OpGet] == 0 {
+		t.Errorf("expected inserts and gets in trace, got %v", w.OperationWeights)
+	}
+}
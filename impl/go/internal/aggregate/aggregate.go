@@ -0,0 +1,119 @@
+// Package aggregate provides constraint-typed aggregate helpers over the lab's
+// containers, so analysis code over numeric-valued maps and trees does not
+// need per-type conversion loops.
+package aggregate
+
+import (
+	"cmp"
+)
+
+// Integer is any built-in integer type.
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// Float is any built-in floating-point type.
+type Float interface {
+	~float32 | ~float64
+}
+
+// Number is any type supporting arithmetic aggregation.
+type Number interface {
+	Integer | Float
+}
+
+// Ranger is any container that iterates its key/value pairs, stopping early
+// when f returns false. HashMap and SkipList both satisfy it.
+type Ranger[K, V any] interface {
+	Range(f func(key K, value V) bool)
+}
+
+// Map adapts a builtin map to Ranger.
+type Map[K comparable, V any] map[K]V
+
+// Range iterates over all key/value pairs in unspecified order.
+func (m Map[K, V]) Range(f func(key K, value V) bool) {
+	for k, v := range m {
+		if !f(k, v) {
+			return
+		}
+	}
+}
+
+// SumValues returns the sum of all values in c.
+func SumValues[K any, V Number](c Ranger[K, V]) V {
+	var sum V
+	c.Range(func(_ K, value V) bool {
+		sum += value
+		return true
+	})
+	return sum
+}
+
+// MeanValues returns the arithmetic mean of all values in c as a float64,
+// or false if c is empty.
+func MeanValues[K any, V Number](c Ranger[K, V]) (float64, bool) {
+	var sum float64
+	n := 0
+	c.Range(func(_ K, value V) bool {
+		sum += float64(value)
+		n++
+		return true
+	})
+	if n == 0 {
+		return 0, false
+	}
+	return sum / float64(n), true
+}
+
+// MinKey returns the smallest key in c by the natural order of K, or false if
+// c is empty. It scans every key even in a sorted container, whose own order
+// may differ from the natural one, as in a skip list built with NewFunc.
+func MinKey[K cmp.Ordered, V any](c Ranger[K, V]) (K, bool) {
+	return extremeKey(c, -1)
+}
+
+// MaxKey returns the largest key in c by the natural order of K, or false if
+// c is empty. Like MinKey, it scans every key.
+func MaxKey[K cmp.Ordered, V any](c Ranger[K, V]) (K, bool) {
+	return extremeKey(c, 1)
+}
+
+// extremeKey scans c for the key that compares to every other key with the
+// given sign: -1 for the minimum, 1 for the maximum.
+func extremeKey[K cmp.Ordered, V any](c Ranger[K, V], sign int) (K, bool) {
+	var best K
+	found := false
+	c.Range(func(key K, _ V) bool {
+		if !found || cmp.Compare(key, best) == sign {
+			best = key
+			found = true
+		}
+		return true
+	})
+	return best, found
+}
+
+// MinValue returns the smallest value in c, or false if c is empty.
+func MinValue[K any, V Number](c Ranger[K, V]) (V, bool) {
+	return extremeValue(c, -1)
+}
+
+// MaxValue returns the largest value in c, or false if c is empty.
+func MaxValue[K any, V Number](c Ranger[K, V]) (V, bool) {
+	return extremeValue(c, 1)
+}
+
+func extremeValue[K any, V Number](c Ranger[K, V], sign int) (V, bool) {
+	var best V
+	found := false
+	c.Range(func(_ K, value V) bool {
+		if !found || cmp.Compare(value, best) == sign {
+			best = value
+			found = true
+		}
+		return true
+	})
+	return best, found
+}
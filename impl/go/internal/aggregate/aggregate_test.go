@@ -0,0 +1,83 @@
+package aggregate
+
+import (
+	"testing"
+
+	"github.com/dsa-lab/go/internal/hashmap"
+	"github.com/dsa-lab/go/internal/order"
+	"github.com/dsa-lab/go/internal/skiplist"
+)
+
+func TestSkipListAggregates(t *testing.T) {
+	s := skiplist.MustNew[int, float64]()
+	for _, k := range []int{5, 3, 9, 1} {
+		s.Insert(k, float64(k)/2)
+	}
+
+	if sum := SumValues[int, float64](s); sum != 9 {
+		t.Errorf("expected sum 9, got %v", sum)
+	}
+	if mean, _ := MeanValues[int, float64](s); mean != 2.25 {
+		t.Errorf("expected mean 2.25, got %v", mean)
+	}
+	if k, _ := MinKey[int, float64](s); k != 1 {
+		t.Errorf("expected min key 1, got %d", k)
+	}
+	if k, _ := MaxKey[int, float64](s); k != 9 {
+		t.Errorf("expected max key 9, got %d", k)
+	}
+	if v, _ := MaxValue[int, float64](s); v != 4.5 {
+		t.Errorf("expected max value 4.5, got %v", v)
+	}
+}
+
+func TestReversedSkipListKeys(t *testing.T) {
+	s := skiplist.MustNewFunc[int, int](order.Natural[int]().Reverse())
+	for _, k := range []int{5, 3, 9, 1} {
+		s.Insert(k, k)
+	}
+
+	if k, _ := MinKey[int, int](s); k != 1 {
+		t.Errorf("expected min key 1, got %d", k)
+	}
+	if k, _ := MaxKey[int, int](s); k != 9 {
+		t.Errorf("expected max key 9, got %d", k)
+	}
+}
+
+func TestHashMapKeys(t *testing.T) {
+	m := hashmap.MustNew()
+	for _, k := range []string{"pear", "apple", "zucchini", "fig"} {
+		m.Insert(k, "")
+	}
+
+	if k, _ := MinKey[string, string](m); k != "apple" {
+		t.Errorf("expected min key apple, got %s", k)
+	}
+	if k, _ := MaxKey[string, string](m); k != "zucchini" {
+		t.Errorf("expected max key zucchini, got %s", k)
+	}
+}
+
+func TestBuiltinMapAndEmpty(t *testing.T) {
+	type celsius float32
+	m := Map[string, celsius]{"mon": 12.5, "tue": -3, "wed": 20}
+
+	if sum := SumValues[string, celsius](m); sum != 29.5 {
+		t.Errorf("expected sum 29.5, got %v", sum)
+	}
+	if v, _ := MinValue[string, celsius](m); v != -3 {
+		t.Errorf("expected min -3, got %v", v)
+	}
+
+	empty := Map[uint8, int]{}
+	if _, found := MinKey[uint8, int](empty); found {
+		t.Error("min key of empty container should report false")
+	}
+	if _, found := MaxKey[int, int](skiplist.MustNew[int, int]()); found {
+		t.Error("max key of empty skip list should report false")
+	}
+	if _, found := MeanValues[uint8, int](empty); found {
+		t.Error("mean of empty container should report false")
+	}
+}
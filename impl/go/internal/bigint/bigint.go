@@ -0,0 +1,188 @@
+// Package bigint implements arbitrary-precision signed integers from scratch:
+// a sign and a vector of 64-bit limbs, with schoolbook and Karatsuba
+// multiplication. It shows how math/big represents numbers and where
+// Karatsuba's three-multiplication split starts to pay; use math/big for
+// real work.
+package bigint
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrSyntax is returned by Parse for a string that is not a decimal integer.
+var ErrSyntax = errors.New("bigint: invalid syntax")
+
+// KaratsubaCutoff is the operand size, in limbs, below which Mul uses
+// schoolbook multiplication. `go test ./bench -bench BigIntMul` measures the
+// crossover; on amd64, cutoffs from 64 to 128 limbs are fastest, about 2.5x
+// schoolbook at 1024 limbs.
+const KaratsubaCutoff = 64
+
+// Int is an immutable signed integer. Operations return new values and
+// never modify their operands, so Ints may be shared freely. The zero value
+// is 0.
+type Int struct {
+	neg bool
+	mag nat
+}
+
+// NewInt returns x as an Int.
+func NewInt(x int64) *Int {
+	if x >= 0 {
+		return &Int{mag: nat{uint64(x)}.norm()}
+	}
+	// -x overflows for math.MinInt64, but its uint64 conversion is exact.
+	return &Int{neg: true, mag: nat{uint64(-(x + 1)) + 1}}
+}
+
+func newInt(neg bool, mag nat) *Int {
+	return &Int{neg: neg && len(mag) > 0, mag: mag}
+}
+
+// decimal limbs: Parse and String work in chunks of 19 digits, the largest
+// power of ten that fits in a limb.
+const (
+	chunkDigits = 19
+	chunkBase   = 1e19
+)
+
+// Parse reads a decimal integer with an optional leading sign.
+func Parse(s string) (*Int, error) {
+	digits := s
+	neg := false
+	if len(digits) > 0 && (digits[0] == '-' || digits[0] == '+') {
+		neg = digits[0] == '-'
+		digits = digits[1:]
+	}
+	if digits == "" {
+		return nil, ErrSyntax
+	}
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return nil, ErrSyntax
+		}
+	}
+	var mag nat
+	// The first chunk takes the leftover digits so the rest are full.
+	first := len(digits) % chunkDigits
+	if first == 0 {
+		first = chunkDigits
+	}
+	for i := 0; i < len(digits); {
+		end := i + first
+		if i > 0 {
+			end = i + chunkDigits
+		}
+		chunk, _ := strconv.ParseUint(digits[i:end], 10, 64)
+		scale := uint64(chunkBase)
+		if i == 0 {
+			scale = 1
+		}
+		mag = mulAddWord(mag, scale, chunk)
+		i = end
+	}
+	return newInt(neg, mag), nil
+}
+
+// String returns x in decimal.
+func (x *Int) String() string {
+	if len(x.mag) == 0 {
+		return "0"
+	}
+	var chunks []uint64
+	for q := x.mag; len(q) > 0; {
+		var r uint64
+		q, r = divWord(q, chunkBase)
+		chunks = append(chunks, r)
+	}
+	var b strings.Builder
+	if x.neg {
+		b.WriteByte('-')
+	}
+	b.WriteString(strconv.FormatUint(chunks[len(chunks)-1], 10))
+	for i := len(chunks) - 2; i >= 0; i-- {
+		s := strconv.FormatUint(chunks[i], 10)
+		b.WriteString(strings.Repeat("0", chunkDigits-len(s)))
+		b.WriteString(s)
+	}
+	return b.String()
+}
+
+// Sign returns -1, 0 or +1 according to the sign of x.
+func (x *Int) Sign() int {
+	switch {
+	case len(x.mag) == 0:
+		return 0
+	case x.neg:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// Limbs returns the number of 64-bit limbs in the magnitude of x.
+func (x *Int) Limbs() int {
+	return len(x.mag)
+}
+
+// Cmp returns -1, 0 or +1 as x is less than, equal to or greater than y.
+func (x *Int) Cmp(y *Int) int {
+	if x.neg != y.neg {
+		if x.neg {
+			return -1
+		}
+		return 1
+	}
+	c := cmpNat(x.mag, y.mag)
+	if x.neg {
+		return -c
+	}
+	return c
+}
+
+// Neg returns -x.
+func (x *Int) Neg() *Int {
+	return newInt(!x.neg, x.mag)
+}
+
+// Abs returns |x|.
+func (x *Int) Abs() *Int {
+	return newInt(false, x.mag)
+}
+
+// Add returns x + y.
+func (x *Int) Add(y *Int) *Int {
+	if x.neg == y.neg {
+		return newInt(x.neg, addNat(x.mag, y.mag))
+	}
+	// Opposite signs: subtract the smaller magnitude from the larger, which
+	// lends the result its sign.
+	if cmpNat(x.mag, y.mag) >= 0 {
+		return newInt(x.neg, subNat(x.mag, y.mag))
+	}
+	return newInt(y.neg, subNat(y.mag, x.mag))
+}
+
+// Sub returns x - y.
+func (x *Int) Sub(y *Int) *Int {
+	return x.Add(y.Neg())
+}
+
+// Mul returns x · y, choosing Karatsuba for operands of at least
+// KaratsubaCutoff limbs.
+func (x *Int) Mul(y *Int) *Int {
+	return x.MulKaratsuba(y, KaratsubaCutoff)
+}
+
+// MulSchoolbook returns x · y by long multiplication, O(n·m) limb products.
+func (x *Int) MulSchoolbook(y *Int) *Int {
+	return newInt(x.neg != y.neg, mulSchoolbook(x.mag, y.mag))
+}
+
+// MulKaratsuba returns x · y by Karatsuba's method, O(n^1.585), recursing
+// until the shorter operand has fewer than cutoff limbs.
+func (x *Int) MulKaratsuba(y *Int, cutoff int) *Int {
+	return newInt(x.neg != y.neg, mulKaratsuba(x.mag, y.mag, cutoff))
+}
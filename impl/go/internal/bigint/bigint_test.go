@@ -0,0 +1,127 @@
+package bigint
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+// randomPair returns the same random integer of up to limbs limbs as an Int
+// and as a big.Int. Some limbs are all ones or zero, to exercise carries.
+func randomPair(rng *rand.Rand, limbs int) (*Int, *big.Int) {
+	mag := make(nat, rng.Intn(limbs+1))
+	words := make([]big.Word, len(mag))
+	for i := range mag {
+		switch rng.Intn(4) {
+		case 0:
+			mag[i] = math.MaxUint64
+		case 1:
+			mag[i] = 0
+		default:
+			mag[i] = rng.Uint64()
+		}
+		words[i] = big.Word(mag[i])
+	}
+	neg := rng.Intn(2) == 0
+	x := newInt(neg, mag.norm())
+	b := new(big.Int).SetBits(words)
+	if neg {
+		b.Neg(b)
+	}
+	return x, b
+}
+
+func check(t *testing.T, op string, got *Int, want *big.Int) {
+	t.Helper()
+	if got.String() != want.String() {
+		t.Fatalf("%s: expected %s, got %s", op, want, got)
+	}
+	if got.Sign() != want.Sign() {
+		t.Fatalf("%s: expected sign %d, got %d", op, want.Sign(), got.Sign())
+	}
+}
+
+func TestArithmeticAgainstMathBig(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 2000; trial++ {
+		x, bx := randomPair(rng, 8)
+		y, by := randomPair(rng, 8)
+		check(t, "add", x.Add(y), new(big.Int).Add(bx, by))
+		check(t, "sub", x.Sub(y), new(big.Int).Sub(bx, by))
+		check(t, "neg", x.Neg(), new(big.Int).Neg(bx))
+		check(t, "abs", x.Abs(), new(big.Int).Abs(bx))
+		want := new(big.Int).Mul(bx, by)
+		check(t, "schoolbook", x.MulSchoolbook(y), want)
+		check(t, "karatsuba", x.MulKaratsuba(y, 2), want)
+		if got := x.Cmp(y); got != bx.Cmp(by) {
+			t.Fatalf("cmp %s, %s: expected %d, got %d", bx, by, bx.Cmp(by), got)
+		}
+	}
+}
+
+// TestKaratsubaLargeAndUnbalanced multiplies operands well past the cutoff,
+// including very different lengths, where one split half is empty.
+func TestKaratsubaLargeAndUnbalanced(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	for _, sizes := range [][2]int{{100, 100}, {300, 7}, {257, 129}, {64, 1000}} {
+		for trial := 0; trial < 5; trial++ {
+			x, bx := randomPair(rng, sizes[0])
+			y, by := randomPair(rng, sizes[1])
+			want := new(big.Int).Mul(bx, by)
+			for _, cutoff := range []int{0, 4, KaratsubaCutoff} {
+				check(t, "karatsuba/"+strconv.Itoa(cutoff), x.MulKaratsuba(y, cutoff), want)
+			}
+			check(t, "mul", x.Mul(y), want)
+		}
+	}
+}
+
+func TestParseAndString(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	for trial := 0; trial < 500; trial++ {
+		_, b := randomPair(rng, 6)
+		s := b.String()
+		x, err := Parse(s)
+		if err != nil {
+			t.Fatalf("parse %s: %v", s, err)
+		}
+		check(t, "parse", x, b)
+	}
+	for _, s := range []string{"0", "-0", "+42", "10000000000000000000", "-9223372036854775808", "007"} {
+		want, _ := new(big.Int).SetString(s, 10)
+		x, err := Parse(s)
+		if err != nil {
+			t.Fatalf("parse %s: %v", s, err)
+		}
+		check(t, "parse "+s, x, want)
+	}
+	for _, s := range []string{"", "-", "12a", "1 2", "0x10"} {
+		if _, err := Parse(s); !errors.Is(err, ErrSyntax) {
+			t.Errorf("parse %q: expected ErrSyntax, got %v", s, err)
+		}
+	}
+}
+
+func TestNewInt(t *testing.T) {
+	for _, v := range []int64{0, 1, -1, math.MaxInt64, math.MinInt64, -1 << 40} {
+		check(t, "new "+strconv.FormatInt(v, 10), NewInt(v), big.NewInt(v))
+	}
+	var zero Int
+	if zero.String() != "0" || zero.Sign() != 0 || zero.Cmp(NewInt(0)) != 0 {
+		t.Errorf("expected the zero value to be 0, got %s", zero.String())
+	}
+}
+
+func TestOperandsUnchanged(t *testing.T) {
+	x, _ := Parse("-123456789012345678901234567890")
+	y, _ := Parse("987654321098765432109876543210")
+	x.Add(y)
+	x.Mul(y)
+	y.Sub(x)
+	if x.String() != "-123456789012345678901234567890" || y.String() != "987654321098765432109876543210" {
+		t.Errorf("expected operands unchanged, got %s and %s", x, y)
+	}
+}
@@ -0,0 +1,156 @@
+package bigint
+
+import "math/bits"
+
+// nat is an unsigned magnitude: 64-bit limbs, least significant first, with
+// no zero limbs at the top. Zero is the empty slice.
+type nat []uint64
+
+func (z nat) norm() nat {
+	i := len(z)
+	for i > 0 && z[i-1] == 0 {
+		i--
+	}
+	return z[:i]
+}
+
+func cmpNat(x, y nat) int {
+	if len(x) != len(y) {
+		if len(x) < len(y) {
+			return -1
+		}
+		return 1
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		if x[i] != y[i] {
+			if x[i] < y[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func addNat(x, y nat) nat {
+	if len(x) < len(y) {
+		x, y = y, x
+	}
+	z := make(nat, len(x)+1)
+	var carry uint64
+	for i := range x {
+		var yi uint64
+		if i < len(y) {
+			yi = y[i]
+		}
+		z[i], carry = bits.Add64(x[i], yi, carry)
+	}
+	z[len(x)] = carry
+	return z.norm()
+}
+
+// subNat returns x - y, which must not be negative.
+func subNat(x, y nat) nat {
+	z := make(nat, len(x))
+	var borrow uint64
+	for i := range x {
+		var yi uint64
+		if i < len(y) {
+			yi = y[i]
+		}
+		z[i], borrow = bits.Sub64(x[i], yi, borrow)
+	}
+	return z.norm()
+}
+
+// addAt adds x into z starting at limb shift. z must be long enough to hold
+// the sum.
+func addAt(z, x nat, shift int) {
+	var carry uint64
+	i := 0
+	for ; i < len(x); i++ {
+		z[shift+i], carry = bits.Add64(z[shift+i], x[i], carry)
+	}
+	for ; carry != 0; i++ {
+		z[shift+i], carry = bits.Add64(z[shift+i], 0, carry)
+	}
+}
+
+// mulSchoolbook multiplies limb by limb, O(len(x) * len(y)).
+func mulSchoolbook(x, y nat) nat {
+	if len(x) == 0 || len(y) == 0 {
+		return nil
+	}
+	z := make(nat, len(x)+len(y))
+	for i, xi := range x {
+		var carry uint64
+		for j, yj := range y {
+			hi, lo := bits.Mul64(xi, yj)
+			var c uint64
+			lo, c = bits.Add64(lo, z[i+j], 0)
+			hi += c
+			lo, c = bits.Add64(lo, carry, 0)
+			hi += c
+			z[i+j], carry = lo, hi
+		}
+		z[i+len(y)] = carry
+	}
+	return z.norm()
+}
+
+// mulKaratsuba splits each operand at m limbs, x = x1·B^m + x0, and forms
+// the product from three half-size products instead of four:
+//
+//	x·y = z2·B^2m + (z1 - z2 - z0)·B^m + z0
+//
+// where z0 = x0·y0, z2 = x1·y1 and z1 = (x0+x1)(y0+y1). Operands shorter
+// than cutoff limbs are multiplied by schoolbook, which is faster for small
+// sizes since it does no additions or allocation per level.
+func mulKaratsuba(x, y nat, cutoff int) nat {
+	if len(x) < len(y) {
+		x, y = y, x
+	}
+	if len(y) < max(cutoff, 2) {
+		return mulSchoolbook(x, y)
+	}
+	m := len(x) / 2
+	x0, x1 := x[:m].norm(), x[m:]
+	y0, y1 := y[:min(m, len(y))].norm(), nat(nil)
+	if len(y) > m {
+		y1 = y[m:]
+	}
+	z0 := mulKaratsuba(x0, y0, cutoff)
+	z2 := mulKaratsuba(x1, y1, cutoff)
+	z1 := mulKaratsuba(addNat(x0, x1), addNat(y0, y1), cutoff)
+	z1 = subNat(subNat(z1, z0), z2)
+
+	z := make(nat, len(x)+len(y)+1)
+	addAt(z, z0, 0)
+	addAt(z, z1, m)
+	addAt(z, z2, 2*m)
+	return z.norm()
+}
+
+// mulAddWord returns x·m + a.
+func mulAddWord(x nat, m, a uint64) nat {
+	z := make(nat, len(x)+1)
+	carry := a
+	for i, xi := range x {
+		hi, lo := bits.Mul64(xi, m)
+		var c uint64
+		z[i], c = bits.Add64(lo, carry, 0)
+		carry = hi + c
+	}
+	z[len(x)] = carry
+	return z.norm()
+}
+
+// divWord returns the quotient and remainder of x divided by d.
+func divWord(x nat, d uint64) (nat, uint64) {
+	q := make(nat, len(x))
+	var r uint64
+	for i := len(x) - 1; i >= 0; i-- {
+		q[i], r = bits.Div64(r, x[i], d)
+	}
+	return q.norm(), r
+}
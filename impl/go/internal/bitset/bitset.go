@@ -0,0 +1,289 @@
+// Package bitset provides a growable bitset with set algebra, iteration and
+// rank/select queries.
+//
+// Rank and select use a succinct index: one cumulative population count per
+// 512-bit block, about 6% on top of the bits themselves, and for select the
+// block holding every 512th set bit. The index is built on the first query
+// after a modification.
+package bitset
+
+import (
+	"fmt"
+	"math/bits"
+
+	"github.com/dsa-lab/go/internal/errs"
+)
+
+const (
+	wordBits       = 64
+	blockWords     = 8
+	blockBits      = blockWords * wordBits
+	wordShift      = 6
+	wordIndexMask  = wordBits - 1
+	blockWordShift = 3
+
+	// selectSample is the spacing, in set bits, of the select hints.
+	selectSample = 512
+)
+
+// Bitset is a sequence of bits indexed from 0. Setting a bit past Len grows
+// the bitset; reading one reports false.
+type Bitset struct {
+	words []uint64
+	n     int
+
+	// ranks[b] is the number of set bits before block b; nil when stale.
+	ranks []uint32
+	// hints[j] is the block holding the set bit of rank j*selectSample.
+	hints []uint32
+}
+
+// New creates a bitset of n zero bits. A negative n is an error matching
+// errs.ErrInvalidOption.
+func New(n int) (*Bitset, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("bitset: length %d: %w", n, errs.ErrInvalidOption)
+	}
+	return &Bitset{words: make([]uint64, (n+wordBits-1)/wordBits), n: n}, nil
+}
+
+// MustNew is like New but panics if n is negative.
+func MustNew(n int) *Bitset {
+	return errs.Must(New(n))
+}
+
+// Len returns the number of bits, set or not.
+func (b *Bitset) Len() int {
+	return b.n
+}
+
+// grow extends the bitset to hold at least n bits.
+func (b *Bitset) grow(n int) {
+	if n <= b.n {
+		return
+	}
+	if need := (n + wordBits - 1) / wordBits; need > len(b.words) {
+		b.words = append(b.words, make([]uint64, need-len(b.words))...)
+	}
+	b.n = n
+}
+
+// Set sets bit i to 1, growing the bitset if needed. It panics if i is
+// negative.
+func (b *Bitset) Set(i int) {
+	if i < 0 {
+		panic("bitset: negative index")
+	}
+	b.grow(i + 1)
+	b.words[i>>wordShift] |= 1 << (i & wordIndexMask)
+	b.ranks = nil
+}
+
+// Unset sets bit i to 0.
+func (b *Bitset) Unset(i int) {
+	if i < 0 || i >= b.n {
+		return
+	}
+	b.words[i>>wordShift] &^= 1 << (i & wordIndexMask)
+	b.ranks = nil
+}
+
+// Test reports whether bit i is 1.
+func (b *Bitset) Test(i int) bool {
+	if i < 0 || i >= b.n {
+		return false
+	}
+	return b.words[i>>wordShift]&(1<<(i&wordIndexMask)) != 0
+}
+
+// Count returns the number of set bits.
+func (b *Bitset) Count() int {
+	n := 0
+	for _, w := range b.words {
+		n += bits.OnesCount64(w)
+	}
+	return n
+}
+
+// Clone returns an independent copy of b.
+func (b *Bitset) Clone() *Bitset {
+	return &Bitset{words: append([]uint64(nil), b.words...), n: b.n}
+}
+
+// And clears every bit of b that is not set in other.
+func (b *Bitset) And(other *Bitset) {
+	for i := range b.words {
+		if i < len(other.words) {
+			b.words[i] &= other.words[i]
+		} else {
+			b.words[i] = 0
+		}
+	}
+	b.ranks = nil
+}
+
+// Or sets every bit of b that is set in other, growing b to other's length.
+func (b *Bitset) Or(other *Bitset) {
+	b.grow(other.n)
+	for i, w := range other.words {
+		b.words[i] |= w
+	}
+	b.ranks = nil
+}
+
+// Xor flips every bit of b that is set in other, growing b to other's length.
+func (b *Bitset) Xor(other *Bitset) {
+	b.grow(other.n)
+	for i, w := range other.words {
+		b.words[i] ^= w
+	}
+	b.ranks = nil
+}
+
+// AndNot clears every bit of b that is set in other.
+func (b *Bitset) AndNot(other *Bitset) {
+	for i := 0; i < len(b.words) && i < len(other.words); i++ {
+		b.words[i] &^= other.words[i]
+	}
+	b.ranks = nil
+}
+
+// Not flips every bit below Len.
+func (b *Bitset) Not() {
+	for i := range b.words {
+		b.words[i] = ^b.words[i]
+	}
+	b.trim()
+	b.ranks = nil
+}
+
+// trim clears the bits of the last word at or beyond Len.
+func (b *Bitset) trim() {
+	if r := b.n & wordIndexMask; r != 0 {
+		b.words[len(b.words)-1] &= 1<<r - 1
+	}
+}
+
+// NextSet returns the first set bit at or after i, and false if there is none.
+func (b *Bitset) NextSet(i int) (int, bool) {
+	i = max(i, 0)
+	if i >= b.n {
+		return 0, false
+	}
+	w := i >> wordShift
+	word := b.words[w] >> (i & wordIndexMask)
+	if word != 0 {
+		return i + bits.TrailingZeros64(word), true
+	}
+	for w++; w < len(b.words); w++ {
+		if b.words[w] != 0 {
+			return w*wordBits + bits.TrailingZeros64(b.words[w]), true
+		}
+	}
+	return 0, false
+}
+
+// Range calls f for each set bit in increasing order. If f returns false,
+// iteration stops.
+func (b *Bitset) Range(f func(i int) bool) {
+	for w, word := range b.words {
+		for word != 0 {
+			if !f(w*wordBits + bits.TrailingZeros64(word)) {
+				return
+			}
+			word &= word - 1
+		}
+	}
+}
+
+// index builds the rank directory if it is stale.
+func (b *Bitset) index() {
+	if b.ranks != nil {
+		return
+	}
+	blocks := (len(b.words) + blockWords - 1) / blockWords
+	b.ranks = make([]uint32, blocks+1)
+	total := uint32(0)
+	for blk := 0; blk < blocks; blk++ {
+		b.ranks[blk] = total
+		for _, w := range b.words[blk*blockWords : min((blk+1)*blockWords, len(b.words))] {
+			total += uint32(bits.OnesCount64(w))
+		}
+	}
+	b.ranks[blocks] = total
+
+	b.hints = b.hints[:0]
+	for blk := 0; blk < blocks; blk++ {
+		for uint32(len(b.hints))*selectSample < b.ranks[blk+1] {
+			b.hints = append(b.hints, uint32(blk))
+		}
+	}
+}
+
+// Rank returns the number of set bits strictly before position i.
+func (b *Bitset) Rank(i int) int {
+	if i <= 0 {
+		return 0
+	}
+	if i >= b.n {
+		i = b.n
+	}
+	b.index()
+	w := i >> wordShift
+	r := int(b.ranks[w>>blockWordShift])
+	for j := w &^ (blockWords - 1); j < w; j++ {
+		r += bits.OnesCount64(b.words[j])
+	}
+	if rem := i & wordIndexMask; rem != 0 {
+		r += bits.OnesCount64(b.words[w] & (1<<rem - 1))
+	}
+	return r
+}
+
+// Select returns the position of the set bit with rank k, that is the
+// (k+1)-th set bit, and false if fewer than k+1 bits are set.
+func (b *Bitset) Select(k int) (int, bool) {
+	b.index()
+	blocks := len(b.ranks) - 1
+	if k < 0 || k >= int(b.ranks[blocks]) {
+		return 0, false
+	}
+
+	// Find the last block whose preceding count is at most k, between the
+	// hints on either side of k.
+	lo, hi := int(b.hints[k/selectSample]), blocks-1
+	if j := k/selectSample + 1; j < len(b.hints) {
+		hi = int(b.hints[j])
+	}
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if int(b.ranks[mid]) <= k {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	k -= int(b.ranks[lo])
+	for w := lo * blockWords; ; w++ {
+		c := bits.OnesCount64(b.words[w])
+		if k < c {
+			return w*wordBits + selectInWord(b.words[w], k), true
+		}
+		k -= c
+	}
+}
+
+// selectInWord returns the position of the set bit of rank k within w,
+// skipping whole bytes before clearing bits one at a time.
+func selectInWord(w uint64, k int) int {
+	shift := 0
+	for c := bits.OnesCount8(uint8(w)); k >= c; c = bits.OnesCount8(uint8(w)) {
+		k -= c
+		w >>= 8
+		shift += 8
+	}
+	for ; k > 0; k-- {
+		w &= w - 1
+	}
+	return shift + bits.TrailingZeros64(w)
+}
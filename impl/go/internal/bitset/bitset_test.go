@@ -0,0 +1,172 @@
+package bitset
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/errs"
+)
+
+// randomPair returns a bitset of n bits with density p and its []bool model.
+func randomPair(rng *rand.Rand, n int, p float64) (*Bitset, []bool) {
+	b := MustNew(n)
+	ref := make([]bool, n)
+	for i := range ref {
+		if rng.Float64() < p {
+			b.Set(i)
+			ref[i] = true
+		}
+	}
+	return b, ref
+}
+
+func expectBits(t *testing.T, name string, b *Bitset, ref []bool) {
+	t.Helper()
+	if b.Len() != len(ref) {
+		t.Fatalf("%s: expected len %d, got %d", name, len(ref), b.Len())
+	}
+	count := 0
+	for i, want := range ref {
+		if b.Test(i) != want {
+			t.Fatalf("%s: bit %d expected %v", name, i, want)
+		}
+		if want {
+			count++
+		}
+	}
+	if b.Count() != count {
+		t.Fatalf("%s: expected count %d, got %d", name, count, b.Count())
+	}
+}
+
+func TestSetUnsetGrow(t *testing.T) {
+	b := MustNew(0)
+	b.Set(130)
+	b.Set(3)
+	b.Unset(3)
+	b.Unset(1000)
+	if b.Len() != 131 || !b.Test(130) || b.Test(3) || b.Test(-1) || b.Test(5000) {
+		t.Errorf("unexpected state: len %d", b.Len())
+	}
+}
+
+func TestAlgebra(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, sizes := range [][2]int{{1000, 1000}, {700, 1300}, {1300, 64}} {
+		a, ra := randomPair(rng, sizes[0], 0.4)
+		b, rb := randomPair(rng, sizes[1], 0.6)
+		n := max(len(ra), len(rb))
+		bit := func(r []bool, i int) bool { return i < len(r) && r[i] }
+
+		ops := []struct {
+			name  string
+			apply func(x *Bitset)
+			model func(x, y bool) bool
+			len   int
+		}{
+			{"and", func(x *Bitset) { x.And(b) }, func(x, y bool) bool { return x && y }, len(ra)},
+			{"or", func(x *Bitset) { x.Or(b) }, func(x, y bool) bool { return x || y }, n},
+			{"xor", func(x *Bitset) { x.Xor(b) }, func(x, y bool) bool { return x != y }, n},
+			{"andnot", func(x *Bitset) { x.AndNot(b) }, func(x, y bool) bool { return x && !y }, len(ra)},
+		}
+		for _, op := range ops {
+			x := a.Clone()
+			op.apply(x)
+			want := make([]bool, op.len)
+			for i := range want {
+				want[i] = op.model(bit(ra, i), bit(rb, i))
+			}
+			expectBits(t, op.name, x, want)
+		}
+
+		x := a.Clone()
+		x.Not()
+		want := make([]bool, len(ra))
+		for i := range want {
+			want[i] = !ra[i]
+		}
+		expectBits(t, "not", x, want)
+		expectBits(t, "original", a, ra)
+	}
+}
+
+func TestIteration(t *testing.T) {
+	b, ref := randomPair(rand.New(rand.NewSource(2)), 2000, 0.05)
+	var want []int
+	for i, set := range ref {
+		if set {
+			want = append(want, i)
+		}
+	}
+
+	var got []int
+	b.Range(func(i int) bool {
+		got = append(got, i)
+		return true
+	})
+	if len(got) != len(want) {
+		t.Fatalf("expected %d set bits, got %d", len(want), len(got))
+	}
+	for k, i := range want {
+		if got[k] != i {
+			t.Fatalf("Range yielded %d at %d, expected %d", got[k], k, i)
+		}
+	}
+
+	k := 0
+	for i, ok := b.NextSet(0); ok; i, ok = b.NextSet(i + 1) {
+		if i != want[k] {
+			t.Fatalf("NextSet yielded %d, expected %d", i, want[k])
+		}
+		k++
+	}
+	if k != len(want) {
+		t.Errorf("NextSet visited %d bits, expected %d", k, len(want))
+	}
+}
+
+func TestRankSelect(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	for _, p := range []float64{0.01, 0.5, 0.99} {
+		b, ref := randomPair(rng, 50000, p)
+		rank := 0
+		for i, set := range ref {
+			if got := b.Rank(i); got != rank {
+				t.Fatalf("p=%v: Rank(%d) = %d, expected %d", p, i, got, rank)
+			}
+			if set {
+				if pos, ok := b.Select(rank); !ok || pos != i {
+					t.Fatalf("p=%v: Select(%d) = %d, %v; expected %d", p, rank, pos, ok, i)
+				}
+				rank++
+			}
+		}
+		if b.Rank(len(ref)) != rank || b.Rank(1<<20) != rank {
+			t.Errorf("p=%v: expected total rank %d", p, rank)
+		}
+		if _, ok := b.Select(rank); ok {
+			t.Errorf("p=%v: expected Select past the last set bit to fail", p)
+		}
+	}
+
+	// The index follows modifications.
+	b := MustNew(100)
+	b.Set(10)
+	if b.Rank(50) != 1 {
+		t.Fatal("expected rank 1")
+	}
+	b.Set(20)
+	if b.Rank(50) != 2 {
+		t.Error("expected rank to reflect a later Set")
+	}
+	if pos, _ := b.Select(1); pos != 20 {
+		t.Errorf("expected Select(1) = 20, got %d", pos)
+	}
+}
+
+func TestNegativeLength(t *testing.T) {
+	if _, err := New(-1); !errors.Is(err, errs.ErrInvalidOption) {
+		t.Errorf("expected ErrInvalidOption, got %v", err)
+	}
+}
@@ -0,0 +1,11 @@
+//go:build go1.23
+
+package bitset
+
+import "iter"
+
+// All returns an iterator over the indexes of the set bits in ascending
+// order.
+func (b *Bitset) All() iter.Seq[int] {
+	return b.Range
+}
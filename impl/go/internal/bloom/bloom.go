@@ -0,0 +1,122 @@
+// Package bloom provides a Bloom filter: a compact set that answers membership
+// queries with no false negatives and a tunable false-positive rate.
+package bloom
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/cespare/xxhash/v2"
+
+	"github.com/dsa-lab/go/internal/errs"
+)
+
+var (
+	errTruncated = errs.New(errs.ErrCorruptSnapshot, "bloom: truncated filter")
+	errMalformed = errs.New(errs.ErrCorruptSnapshot, "bloom: malformed filter")
+)
+
+// Filter is a Bloom filter over string keys.
+type Filter struct {
+	bits []uint64
+	m    uint64
+	k    uint32
+}
+
+// New creates a filter sized for n keys at false-positive rate p, using the
+// optimal bit count m = -n ln p / (ln 2)^2 and k = m/n ln 2 hash functions.
+// It returns an error matching errs.ErrInvalidOption unless n >= 1 and
+// 0 < p < 1.
+func New(n int, p float64) (*Filter, error) {
+	if n < 1 || !(p > 0 && p < 1) {
+		return nil, fmt.Errorf("bloom: New(%d, %v): %w", n, p, errs.ErrInvalidOption)
+	}
+	m := uint64(math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2)))
+	if m < 64 {
+		m = 64
+	}
+	k := uint32(math.Round(float64(m) / float64(n) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+	return &Filter{bits: make([]uint64, (m+63)/64), m: m, k: k}, nil
+}
+
+// MustNew is like New but panics if n or p is invalid.
+func MustNew(n int, p float64) *Filter {
+	return errs.Must(New(n, p))
+}
+
+// locations derives the k bit positions for key from a single 64-bit hash,
+// using the Kirsch-Mitzenmacher double-hashing scheme.
+func (f *Filter) locations(key string, visit func(bit uint64) bool) {
+	h := xxhash.Sum64String(key)
+	h1, h2 := h&0xffffffff, h>>32|1
+	for i := uint64(0); i < uint64(f.k); i++ {
+		if !visit((h1 + i*h2) % f.m) {
+			return
+		}
+	}
+}
+
+// Add inserts key into the filter.
+func (f *Filter) Add(key string) {
+	f.locations(key, func(bit uint64) bool {
+		f.bits[bit/64] |= 1 << (bit % 64)
+		return true
+	})
+}
+
+// MayContain reports whether key may have been added. A false result is
+// definite; a true result is wrong with roughly the configured probability.
+func (f *Filter) MayContain(key string) bool {
+	found := true
+	f.locations(key, func(bit uint64) bool {
+		if f.bits[bit/64]&(1<<(bit%64)) == 0 {
+			found = false
+		}
+		return found
+	})
+	return found
+}
+
+// Bits returns the number of bits in the filter.
+func (f *Filter) Bits() int {
+	return int(f.m)
+}
+
+// Hashes returns the number of hash functions.
+func (f *Filter) Hashes() int {
+	return int(f.k)
+}
+
+// MarshalBinary encodes the filter as m and k followed by the bit array.
+func (f *Filter) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, 12+8*len(f.bits))
+	binary.LittleEndian.PutUint64(buf, f.m)
+	binary.LittleEndian.PutUint32(buf[8:], f.k)
+	for i, w := range f.bits {
+		binary.LittleEndian.PutUint64(buf[12+8*i:], w)
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary decodes a filter written by MarshalBinary.
+func (f *Filter) UnmarshalBinary(data []byte) error {
+	if len(data) < 12 {
+		return errTruncated
+	}
+	m := binary.LittleEndian.Uint64(data)
+	k := binary.LittleEndian.Uint32(data[8:])
+	words := (m + 63) / 64
+	if m == 0 || k == 0 || uint64(len(data)-12) != 8*words {
+		return errMalformed
+	}
+	f.m, f.k = m, k
+	f.bits = make([]uint64, words)
+	for i := range f.bits {
+		f.bits[i] = binary.LittleEndian.Uint64(data[12+8*i:])
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package bloom
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/errs"
+)
+
+func TestNoFalseNegatives(t *testing.T) {
+	f := MustNew(1000, 0.01)
+	for i := 0; i < 1000; i++ {
+		f.Add(fmt.Sprintf("key_%d", i))
+	}
+	for i := 0; i < 1000; i++ {
+		if !f.MayContain(fmt.Sprintf("key_%d", i)) {
+			t.Fatalf("false negative for key_%d", i)
+		}
+	}
+}
+
+func TestFalsePositiveRate(t *testing.T) {
+	for _, p := range []float64{0.1, 0.01, 0.001} {
+		const n = 10000
+		f := MustNew(n, p)
+		for i := 0; i < n; i++ {
+			f.Add(fmt.Sprintf("key_%d", i))
+		}
+
+		falsePositives := 0
+		const trials = 100000
+		for i := 0; i < trials; i++ {
+			if f.MayContain(fmt.Sprintf("absent_%d", i)) {
+				falsePositives++
+			}
+		}
+		rate := float64(falsePositives) / trials
+		if rate > 2*p {
+			t.Errorf("p=%v: expected false-positive rate near target, got %.4f (m=%d, k=%d)", p, rate, f.Bits(), f.Hashes())
+		}
+	}
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	f := MustNew(100, 0.01)
+	for i := 0; i < 100; i++ {
+		f.Add(fmt.Sprintf("key_%d", i))
+	}
+	data, err := f.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var g Filter
+	if err := g.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if g.Bits() != f.Bits() || g.Hashes() != f.Hashes() {
+		t.Fatalf("expected m=%d k=%d, got m=%d k=%d", f.Bits(), f.Hashes(), g.Bits(), g.Hashes())
+	}
+	for i := 0; i < 200; i++ {
+		key := fmt.Sprintf("key_%d", i)
+		if f.MayContain(key) != g.MayContain(key) {
+			t.Errorf("decoded filter disagrees on %s", key)
+		}
+	}
+
+	if err := g.UnmarshalBinary(data[:len(data)-1]); !errors.Is(err, errs.ErrCorruptSnapshot) {
+		t.Errorf("expected ErrCorruptSnapshot for truncated filter, got %v", err)
+	}
+}
+
+func TestNewRejectsInvalid(t *testing.T) {
+	for _, c := range []struct {
+		n int
+		p float64
+	}{{0, 0.01}, {-1, 0.01}, {10, 0}, {10, 1}, {10, -0.5}} {
+		if _, err := New(c.n, c.p); !errors.Is(err, errs.ErrInvalidOption) {
+			t.Errorf("New(%d, %v): expected ErrInvalidOption, got %v", c.n, c.p, err)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package cache
+
+import "github.com/dsa-lab/go/internal/errs"
+
+// arcNode is an entry of one of ARC's four lists. Ghost entries, in b1 and
+// b2, keep only their key.
+type arcNode struct {
+	key, value string
+	weight     int64
+	list       *arcList
+	prev, next *arcNode
+}
+
+// arcList is a recency list with its length; root.next is most recent.
+type arcList struct {
+	root arcNode
+	len  int
+}
+
+func (l *arcList) init() {
+	l.root.next = &l.root
+	l.root.prev = &l.root
+	l.len = 0
+}
+
+func (l *arcList) pushFront(n *arcNode) {
+	n.list = l
+	n.prev = &l.root
+	n.next = l.root.next
+	l.root.next.prev = n
+	l.root.next = n
+	l.len++
+}
+
+func (l *arcList) remove(n *arcNode) {
+	n.prev.next = n.next
+	n.next.prev = n.prev
+	n.list = nil
+	l.len--
+}
+
+// back returns the least recent entry.
+func (l *arcList) back() *arcNode {
+	return l.root.prev
+}
+
+// ARC is an Adaptive Replacement Cache (Megiddo and Modha, 2003). Resident
+// entries are split between t1, seen once recently, and t2, seen at least
+// twice. The ghost lists b1 and b2 remember the keys recently evicted from
+// each, and a hit on a ghost shifts the target size p of t1 toward the list
+// that would have kept it. The cache thereby balances recency against
+// frequency by itself: a one-off scan flows through t1 without flushing the
+// frequently used entries in t2. All operations are O(1).
+type ARC struct {
+	capacity int
+	p        int // target size of t1
+	items    map[string]*arcNode
+	t1, t2   arcList
+	b1, b2   arcList
+	stats    Stats
+	weights  weights
+}
+
+// NewARC creates an ARC cache holding at most capacity entries, which must be
+// at least 1; it also remembers up to capacity evicted keys. Under a weight
+// budget the ghost lists still hold keys, not weight, so ARC adapts by entry
+// count alone.
+func NewARC(capacity int, opts ...Option) (*ARC, error) {
+	ws, err := newWeights(capacity, opts)
+	if err != nil {
+		return nil, err
+	}
+	c := &ARC{capacity: capacity, weights: ws}
+	c.Clear()
+	return c, nil
+}
+
+// MustNewARC is like NewARC but panics if capacity or an option is invalid.
+func MustNewARC(capacity int, opts ...Option) *ARC {
+	return errs.Must(NewARC(capacity, opts...))
+}
+
+// Len returns the number of cached entries, excluding ghosts.
+func (c *ARC) Len() int {
+	return c.t1.len + c.t2.len
+}
+
+// Capacity returns the maximum number of entries.
+func (c *ARC) Capacity() int {
+	return c.capacity
+}
+
+// Weight returns the total weight of the cached entries, excluding ghosts.
+func (c *ARC) Weight() int64 {
+	return c.weights.total
+}
+
+// Stats returns the hit, miss and eviction counters.
+func (c *ARC) Stats() Stats {
+	return c.stats
+}
+
+// Target returns the current target size of the recency list, between 0
+// (favour frequency) and Capacity (favour recency).
+func (c *ARC) Target() int {
+	return c.p
+}
+
+func (c *ARC) resident(n *arcNode) bool {
+	return n.list == &c.t1 || n.list == &c.t2
+}
+
+// Get returns the cached value for key, promoting it to the frequency list.
+func (c *ARC) Get(key string) (string, bool) {
+	n, found := c.items[key]
+	if !found || !c.resident(n) {
+		c.stats.Misses++
+		return "", false
+	}
+	c.stats.Hits++
+	n.list.remove(n)
+	c.t2.pushFront(n)
+	return n.value, true
+}
+
+// Peek returns the cached value for key without updating the lists or stats.
+func (c *ARC) Peek(key string) (string, bool) {
+	if n, found := c.items[key]; found && c.resident(n) {
+		return n.value, true
+	}
+	return "", false
+}
+
+// replace evicts one resident entry if the cache is full by count. inB2
+// reports whether the key being admitted was a b2 ghost.
+func (c *ARC) replace(inB2 bool) {
+	if c.Len() < c.capacity {
+		return
+	}
+	c.demote(inB2)
+}
+
+// demote evicts one resident entry, turning it into a ghost: the LRU end of
+// t1 when t1 exceeds its target, otherwise that of t2.
+func (c *ARC) demote(inB2 bool) {
+	var victim *arcNode
+	var ghosts *arcList
+	if c.t1.len > 0 && (c.t2.len == 0 || c.t1.len > c.p || (inB2 && c.t1.len == c.p)) {
+		victim, ghosts = c.t1.back(), &c.b1
+	} else {
+		victim, ghosts = c.t2.back(), &c.b2
+	}
+	victim.list.remove(victim)
+	c.weights.total -= victim.weight
+	victim.value, victim.weight = "", 0
+	ghosts.pushFront(victim)
+	c.stats.Evictions++
+}
+
+// drop forgets the least recent entry of l entirely.
+func (c *ARC) drop(l *arcList) {
+	n := l.back()
+	if l == &c.t1 || l == &c.t2 {
+		c.weights.total -= n.weight
+		c.stats.Evictions++
+	}
+	l.remove(n)
+	delete(c.items, n.key)
+}
+
+// Put inserts or updates key. A key remembered as a ghost is readmitted to
+// the frequency list and adapts the recency target. Under a weight budget,
+// further entries are demoted in the same order until the key fits.
+func (c *ARC) Put(key, value string) {
+	w := c.weights.of(key, value)
+	if !c.weights.fits(w) {
+		c.Remove(key)
+		c.stats.Rejections++
+		return
+	}
+
+	if n, found := c.items[key]; found {
+		inB2 := false
+		switch n.list {
+		case &c.t1, &c.t2:
+			n.list.remove(n)
+			c.weights.total -= n.weight
+		case &c.b1:
+			c.p = min(c.capacity, c.p+max(c.b2.len/c.b1.len, 1))
+			c.replace(false)
+			c.b1.remove(n)
+		case &c.b2:
+			c.p = max(0, c.p-max(c.b1.len/c.b2.len, 1))
+			c.replace(true)
+			c.b2.remove(n)
+			inB2 = true
+		}
+		for c.weights.over(w) {
+			c.demote(inB2)
+		}
+		n.value, n.weight = value, w
+		c.weights.total += w
+		c.t2.pushFront(n)
+		return
+	}
+
+	if c.t1.len+c.b1.len >= c.capacity {
+		if c.t1.len < c.capacity {
+			c.drop(&c.b1)
+			c.replace(false)
+		} else {
+			c.drop(&c.t1)
+		}
+	} else if total := c.t1.len + c.t2.len + c.b1.len + c.b2.len; total >= c.capacity {
+		if total >= 2*c.capacity {
+			c.drop(&c.b2)
+		}
+		c.replace(false)
+	}
+	for c.weights.over(w) {
+		c.demote(false)
+	}
+	n := &arcNode{key: key, value: value, weight: w}
+	c.t1.pushFront(n)
+	c.items[key] = n
+	c.weights.total += w
+}
+
+// Remove deletes key from the cache, reporting whether it was resident. A
+// ghost of key is forgotten as well.
+func (c *ARC) Remove(key string) bool {
+	n, found := c.items[key]
+	if !found {
+		return false
+	}
+	wasResident := c.resident(n)
+	if wasResident {
+		c.weights.total -= n.weight
+	}
+	n.list.remove(n)
+	delete(c.items, key)
+	return wasResident
+}
+
+// Clear removes all entries and ghosts and resets the adaptation. Stats are
+// preserved.
+func (c *ARC) Clear() {
+	c.items = make(map[string]*arcNode, 2*c.capacity)
+	c.p = 0
+	c.weights.total = 0
+	c.t1.init()
+	c.t2.init()
+	c.b1.init()
+	c.b2.init()
+}
@@ -0,0 +1,82 @@
+// Package cache provides bounded string key/value caches with eviction policies.
+package cache
+
+import "github.com/dsa-lab/go/internal/errs"
+
+// Cache is the operation set shared by every eviction policy. Get counts a hit
+// or a miss and updates the policy's bookkeeping; Peek does neither. Weight
+// is the total weight of the cached entries, which equals Len unless the
+// cache was built WithMaxWeight.
+type Cache interface {
+	Get(key string) (string, bool)
+	Peek(key string) (string, bool)
+	Put(key, value string)
+	Remove(key string) bool
+	Len() int
+	Capacity() int
+	Weight() int64
+	Stats() Stats
+	Clear()
+}
+
+// Stats holds cache effectiveness counters.
+type Stats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	// Rejections counts Puts of entries too heavy to cache at all.
+	Rejections uint64
+}
+
+// HitRate returns the fraction of lookups that were hits.
+func (s Stats) HitRate() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// Policy pairs a stable name with a constructor for a Cache of a given
+// capacity.
+type Policy struct {
+	Name string
+	New  func(capacity int, opts ...Option) (Cache, error)
+}
+
+// MustNew is like p.New but panics if capacity or an option is invalid.
+func (p Policy) MustNew(capacity int, opts ...Option) Cache {
+	return errs.Must(p.New(capacity, opts...))
+}
+
+// policy adapts a constructor returning a concrete cache type to Policy.New.
+func policy[C Cache](name string, newCache func(int, ...Option) (C, error)) Policy {
+	return Policy{Name: name, New: func(capacity int, opts ...Option) (Cache, error) {
+		c, err := newCache(capacity, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return c, nil
+	}}
+}
+
+// Policies returns every eviction policy, for tests, benchmarks and the
+// simulator to compare on the same traces.
+func Policies() []Policy {
+	return []Policy{
+		policy("lru", NewLRU),
+		policy("lfu", NewLFU),
+		policy("arc", NewARC),
+		policy("clock", NewClock),
+	}
+}
+
+// LookupPolicy returns the policy with the given name.
+func LookupPolicy(name string) (Policy, bool) {
+	for _, p := range Policies() {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Policy{}, false
+}
@@ -0,0 +1,176 @@
+package cache
+
+import "github.com/dsa-lab/go/internal/errs"
+
+// clockSlot is one position of the clock. An empty key with used false
+// marks a free slot left by Remove.
+type clockSlot struct {
+	key, value string
+	weight     int64
+	referenced bool
+	used       bool
+}
+
+// Clock is a second-chance cache, the approximation of LRU used by operating
+// system page replacement. Entries sit in a fixed ring with a reference bit
+// that Get sets. To evict, the hand sweeps the ring, clearing set bits and
+// stopping at the first entry whose bit is already clear, so an entry read
+// since the last sweep survives one more revolution. A hit only sets a bit,
+// with no list to reorder, which makes hits cheaper than in LRU.
+type Clock struct {
+	capacity int
+	items    map[string]int // key to slot index
+	slots    []clockSlot
+	free     []int // indices of slots emptied by Remove
+	hand     int
+	stats    Stats
+	weights  weights
+}
+
+// NewClock creates a clock cache holding at most capacity entries, which must be
+// at least 1.
+func NewClock(capacity int, opts ...Option) (*Clock, error) {
+	ws, err := newWeights(capacity, opts)
+	if err != nil {
+		return nil, err
+	}
+	c := &Clock{capacity: capacity, weights: ws}
+	c.Clear()
+	return c, nil
+}
+
+// MustNewClock is like NewClock but panics if capacity or an option is invalid.
+func MustNewClock(capacity int, opts ...Option) *Clock {
+	return errs.Must(NewClock(capacity, opts...))
+}
+
+// Len returns the number of cached entries.
+func (c *Clock) Len() int {
+	return len(c.items)
+}
+
+// Capacity returns the maximum number of entries.
+func (c *Clock) Capacity() int {
+	return c.capacity
+}
+
+// Weight returns the total weight of the cached entries.
+func (c *Clock) Weight() int64 {
+	return c.weights.total
+}
+
+// Stats returns the hit, miss and eviction counters.
+func (c *Clock) Stats() Stats {
+	return c.stats
+}
+
+// Get returns the cached value for key and sets its reference bit.
+func (c *Clock) Get(key string) (string, bool) {
+	i, found := c.items[key]
+	if !found {
+		c.stats.Misses++
+		return "", false
+	}
+	c.stats.Hits++
+	c.slots[i].referenced = true
+	return c.slots[i].value, true
+}
+
+// Peek returns the cached value for key without touching its reference bit
+// or the stats.
+func (c *Clock) Peek(key string) (string, bool) {
+	if i, found := c.items[key]; found {
+		return c.slots[i].value, true
+	}
+	return "", false
+}
+
+// Put inserts or updates key. A new key takes a free slot if there is one,
+// otherwise the slot of the entry the hand evicts; the hand keeps evicting
+// while the key does not fit the weight budget. New entries start with
+// their reference bit clear.
+func (c *Clock) Put(key, value string) {
+	w := c.weights.of(key, value)
+	if !c.weights.fits(w) {
+		c.Remove(key)
+		c.stats.Rejections++
+		return
+	}
+
+	if i, found := c.items[key]; found {
+		s := &c.slots[i]
+		c.weights.total -= s.weight
+		// The hand skips unused slots, so the entry cannot be its own victim.
+		s.used = false
+		for c.weights.over(w) {
+			c.evict()
+		}
+		*s = clockSlot{key: key, value: value, weight: w, referenced: true, used: true}
+		c.weights.total += w
+		return
+	}
+
+	for len(c.items) >= c.capacity || c.weights.over(w) {
+		c.evict()
+	}
+	var i int
+	if n := len(c.free); n > 0 {
+		i = c.free[n-1]
+		c.free = c.free[:n-1]
+	} else {
+		c.slots = append(c.slots, clockSlot{})
+		i = len(c.slots) - 1
+	}
+	c.slots[i] = clockSlot{key: key, value: value, weight: w, used: true}
+	c.items[key] = i
+	c.weights.total += w
+}
+
+// evict removes the entry the hand stops at and frees its slot.
+func (c *Clock) evict() {
+	i := c.sweep()
+	delete(c.items, c.slots[i].key)
+	c.weights.total -= c.slots[i].weight
+	c.slots[i] = clockSlot{}
+	c.free = append(c.free, i)
+	c.stats.Evictions++
+}
+
+// sweep advances the hand to the first used slot with a clear reference
+// bit, clearing the bits it passes, and returns that slot's index.
+func (c *Clock) sweep() int {
+	for {
+		s := &c.slots[c.hand]
+		i := c.hand
+		c.hand = (c.hand + 1) % len(c.slots)
+		if !s.used {
+			continue
+		}
+		if !s.referenced {
+			return i
+		}
+		s.referenced = false
+	}
+}
+
+// Remove deletes key from the cache, reporting whether it was present.
+func (c *Clock) Remove(key string) bool {
+	i, found := c.items[key]
+	if !found {
+		return false
+	}
+	c.weights.total -= c.slots[i].weight
+	c.slots[i] = clockSlot{}
+	c.free = append(c.free, i)
+	delete(c.items, key)
+	return true
+}
+
+// Clear removes all entries. Stats are preserved.
+func (c *Clock) Clear() {
+	c.items = make(map[string]int, c.capacity)
+	c.slots = make([]clockSlot, 0, c.capacity)
+	c.free = nil
+	c.hand = 0
+	c.weights.total = 0
+}
@@ -0,0 +1,87 @@
+//go:build go1.23
+
+package cache
+
+import "iter"
+
+// The iterators below visit the cached entries like Peek does, without
+// counting hits or updating the policy's bookkeeping. The cache must not be
+// modified during iteration.
+
+// All returns an iterator over the cached entries from most to least
+// recently used, so the next victim comes last.
+func (c *LRU) All() iter.Seq2[string, string] {
+	return func(yield func(string, string) bool) {
+		for n := c.root.next; n != &c.root; n = n.next {
+			if !yield(n.key, n.value) {
+				return
+			}
+		}
+	}
+}
+
+// All returns an iterator over the cached entries from the lowest access
+// count to the highest, most recently used first among equal counts.
+func (c *LFU) All() iter.Seq2[string, string] {
+	return func(yield func(string, string) bool) {
+		for b := c.buckets.next; b != &c.buckets; b = b.next {
+			for n := b.root.next; n != &b.root; n = n.next {
+				if !yield(n.key, n.value) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// All returns an iterator over the resident entries: those seen once, then
+// those seen at least twice, each from most to least recently used. Ghost
+// keys are not included.
+func (c *ARC) All() iter.Seq2[string, string] {
+	return func(yield func(string, string) bool) {
+		for _, l := range []*arcList{&c.t1, &c.t2} {
+			for n := l.root.next; n != &l.root; n = n.next {
+				if !yield(n.key, n.value) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// All returns an iterator over the cached entries in ring order, starting at
+// the hand, which is the order the next sweep will consider them.
+func (c *Clock) All() iter.Seq2[string, string] {
+	return func(yield func(string, string) bool) {
+		for i := range c.slots {
+			s := &c.slots[(c.hand+i)%len(c.slots)]
+			if s.used && !yield(s.key, s.value) {
+				return
+			}
+		}
+	}
+}
+
+// All returns an iterator over the unexpired values in unspecified order.
+// Negative entries and stale values are not included. It neither counts
+// lookups nor starts refreshes, and it reads a snapshot taken when iteration
+// starts, so unlike the iterators above the loop body may modify the TTL.
+func (m *TTL) All() iter.Seq2[string, string] {
+	return func(yield func(string, string) bool) {
+		type pair struct{ key, value string }
+		m.mu.Lock()
+		now := m.now()
+		fresh := make([]pair, 0, len(m.entries))
+		for key, e := range m.entries {
+			if !e.negative && now.Before(e.expires) {
+				fresh = append(fresh, pair{key, e.value})
+			}
+		}
+		m.mu.Unlock()
+		for _, p := range fresh {
+			if !yield(p.key, p.value) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package cache
+
+import "github.com/dsa-lab/go/internal/errs"
+
+// lfuNode is a cached entry, linked into the list of its frequency bucket.
+type lfuNode struct {
+	key, value string
+	weight     int64
+	bucket     *freqBucket
+	prev, next *lfuNode
+}
+
+// freqBucket groups the entries accessed exactly count times. Its entry list
+// is a ring around the sentinel root, most recently used at root.next.
+type freqBucket struct {
+	count      uint64
+	root       lfuNode
+	prev, next *freqBucket
+}
+
+func newFreqBucket(count uint64) *freqBucket {
+	b := &freqBucket{count: count}
+	b.root.next = &b.root
+	b.root.prev = &b.root
+	return b
+}
+
+func (b *freqBucket) empty() bool {
+	return b.root.next == &b.root
+}
+
+func (b *freqBucket) pushFront(n *lfuNode) {
+	n.bucket = b
+	n.prev = &b.root
+	n.next = b.root.next
+	b.root.next.prev = n
+	b.root.next = n
+}
+
+// LFU is a least-frequently-used cache using the O(1) scheme of Shah, Mitra
+// and Matani (2010). Buckets of equal access count form an ascending list, so
+// the victim, the least recently used entry of the lowest bucket, is found
+// without a heap, and an access moves its entry to the neighbouring bucket.
+// Frequencies are never aged, so keys that were hot long ago can crowd out
+// new ones; ARC adapts to such shifts.
+type LFU struct {
+	capacity int
+	items    map[string]*lfuNode
+	buckets  freqBucket // sentinel: buckets.next has the lowest count
+	stats    Stats
+	weights  weights
+}
+
+// NewLFU creates an LFU cache holding at most capacity entries, which must be
+// at least 1.
+func NewLFU(capacity int, opts ...Option) (*LFU, error) {
+	ws, err := newWeights(capacity, opts)
+	if err != nil {
+		return nil, err
+	}
+	c := &LFU{
+		capacity: capacity,
+		items:    make(map[string]*lfuNode, capacity),
+		weights:  ws,
+	}
+	c.buckets.next = &c.buckets
+	c.buckets.prev = &c.buckets
+	return c, nil
+}
+
+// MustNewLFU is like NewLFU but panics if capacity or an option is invalid.
+func MustNewLFU(capacity int, opts ...Option) *LFU {
+	return errs.Must(NewLFU(capacity, opts...))
+}
+
+// Len returns the number of cached entries.
+func (c *LFU) Len() int {
+	return len(c.items)
+}
+
+// Capacity returns the maximum number of entries.
+func (c *LFU) Capacity() int {
+	return c.capacity
+}
+
+// Weight returns the total weight of the cached entries.
+func (c *LFU) Weight() int64 {
+	return c.weights.total
+}
+
+// Stats returns the hit, miss and eviction counters.
+func (c *LFU) Stats() Stats {
+	return c.stats
+}
+
+// Frequency returns the number of accesses recorded for key, counting the
+// Put that inserted it, or 0 if key is not cached.
+func (c *LFU) Frequency(key string) uint64 {
+	if n, found := c.items[key]; found {
+		return n.bucket.count
+	}
+	return 0
+}
+
+// insertAfter links a new bucket with count after b.
+func (c *LFU) insertAfter(b *freqBucket, count uint64) *freqBucket {
+	nb := newFreqBucket(count)
+	nb.prev = b
+	nb.next = b.next
+	b.next.prev = nb
+	b.next = nb
+	return nb
+}
+
+// unlink removes n from its bucket, dropping the bucket once empty.
+func (c *LFU) unlink(n *lfuNode) {
+	n.prev.next = n.next
+	n.next.prev = n.prev
+	if b := n.bucket; b.empty() {
+		b.prev.next = b.next
+		b.next.prev = b.prev
+	}
+}
+
+// touch moves n to the bucket one count higher.
+func (c *LFU) touch(n *lfuNode) {
+	b := n.bucket
+	next := b.next
+	if next == &c.buckets || next.count != b.count+1 {
+		next = c.insertAfter(b, b.count+1)
+	}
+	c.unlink(n)
+	next.pushFront(n)
+}
+
+// Get returns the cached value for key and counts an access to it.
+func (c *LFU) Get(key string) (string, bool) {
+	n, found := c.items[key]
+	if !found {
+		c.stats.Misses++
+		return "", false
+	}
+	c.stats.Hits++
+	c.touch(n)
+	return n.value, true
+}
+
+// Peek returns the cached value for key without counting an access.
+func (c *LFU) Peek(key string) (string, bool) {
+	if n, found := c.items[key]; found {
+		return n.value, true
+	}
+	return "", false
+}
+
+// Put inserts or updates key. Updating counts as an access; inserting into a
+// full cache first evicts the least recently used of the least frequently
+// used entries, as many as it takes to fit the new entry's weight.
+func (c *LFU) Put(key, value string) {
+	w := c.weights.of(key, value)
+	if !c.weights.fits(w) {
+		c.Remove(key)
+		c.stats.Rejections++
+		return
+	}
+
+	if n, found := c.items[key]; found {
+		c.weights.total += w - n.weight
+		n.value, n.weight = value, w
+		c.touch(n)
+		for c.weights.over(0) {
+			c.evict(n)
+		}
+		return
+	}
+
+	for len(c.items) >= c.capacity || c.weights.over(w) {
+		c.evict(nil)
+	}
+	first := c.buckets.next
+	if first == &c.buckets || first.count != 1 {
+		first = c.insertAfter(&c.buckets, 1)
+	}
+	n := &lfuNode{key: key, value: value, weight: w}
+	first.pushFront(n)
+	c.items[key] = n
+	c.weights.total += w
+}
+
+// evict removes the least recently used entry of the lowest bucket, passing
+// over keep, the entry being updated.
+func (c *LFU) evict(keep *lfuNode) {
+	b := c.buckets.next
+	victim := b.root.prev
+	if victim == keep {
+		if victim = keep.prev; victim == &b.root {
+			victim = b.next.root.prev
+		}
+	}
+	c.unlink(victim)
+	delete(c.items, victim.key)
+	c.weights.total -= victim.weight
+	c.stats.Evictions++
+}
+
+// Remove deletes key from the cache, reporting whether it was present.
+func (c *LFU) Remove(key string) bool {
+	n, found := c.items[key]
+	if !found {
+		return false
+	}
+	c.unlink(n)
+	delete(c.items, key)
+	c.weights.total -= n.weight
+	return true
+}
+
+// Clear removes all entries. Stats are preserved.
+func (c *LFU) Clear() {
+	c.items = make(map[string]*lfuNode, c.capacity)
+	c.weights.total = 0
+	c.buckets.next = &c.buckets
+	c.buckets.prev = &c.buckets
+}
@@ -0,0 +1,167 @@
+package cache
+
+import (
+	"github.com/dsa-lab/go/internal/errs"
+	"github.com/dsa-lab/go/internal/pool"
+)
+
+// lruNode is an element of the LRU recency list.
+type lruNode struct {
+	key        string
+	value      string
+	weight     int64
+	prev, next *lruNode
+}
+
+// LRU is a least-recently-used cache. Entries live in a doubly-linked recency
+// list indexed by key; Get and Put move an entry to the front and the entry at
+// the back is evicted when the cache is full. All operations are O(1).
+type LRU struct {
+	capacity int
+	items    map[string]*lruNode
+	root     lruNode // sentinel: root.next is most recent, root.prev least recent
+	stats    Stats
+	weights  weights
+
+	// free recycles evicted and removed nodes, so a full cache under churn
+	// reuses its victim's node for each new entry instead of allocating.
+	free *pool.Freelist[lruNode]
+}
+
+// NewLRU creates an LRU cache holding at most capacity entries, which must be
+// at least 1.
+func NewLRU(capacity int, opts ...Option) (*LRU, error) {
+	ws, err := newWeights(capacity, opts)
+	if err != nil {
+		return nil, err
+	}
+	c := &LRU{
+		capacity: capacity,
+		items:    make(map[string]*lruNode, capacity),
+		free:     pool.MustNew(pool.WithLimit[lruNode](capacity)),
+		weights:  ws,
+	}
+	c.root.next = &c.root
+	c.root.prev = &c.root
+	return c, nil
+}
+
+// MustNewLRU is like NewLRU but panics if capacity or an option is invalid.
+func MustNewLRU(capacity int, opts ...Option) *LRU {
+	return errs.Must(NewLRU(capacity, opts...))
+}
+
+// Len returns the number of cached entries.
+func (c *LRU) Len() int {
+	return len(c.items)
+}
+
+// Capacity returns the maximum number of entries.
+func (c *LRU) Capacity() int {
+	return c.capacity
+}
+
+// Weight returns the total weight of the cached entries.
+func (c *LRU) Weight() int64 {
+	return c.weights.total
+}
+
+// Stats returns the hit, miss and eviction counters.
+func (c *LRU) Stats() Stats {
+	return c.stats
+}
+
+func (c *LRU) unlink(n *lruNode) {
+	n.prev.next = n.next
+	n.next.prev = n.prev
+}
+
+func (c *LRU) pushFront(n *lruNode) {
+	n.prev = &c.root
+	n.next = c.root.next
+	c.root.next.prev = n
+	c.root.next = n
+}
+
+// Get returns the cached value for key and marks it most recently used.
+func (c *LRU) Get(key string) (string, bool) {
+	n, found := c.items[key]
+	if !found {
+		c.stats.Misses++
+		return "", false
+	}
+	c.stats.Hits++
+	c.unlink(n)
+	c.pushFront(n)
+	return n.value, true
+}
+
+// Peek returns the cached value for key without updating recency or stats.
+func (c *LRU) Peek(key string) (string, bool) {
+	if n, found := c.items[key]; found {
+		return n.value, true
+	}
+	return "", false
+}
+
+// Put inserts or updates key, evicting least recently used entries until
+// the cache has room for it by count and by weight.
+func (c *LRU) Put(key, value string) {
+	w := c.weights.of(key, value)
+	if !c.weights.fits(w) {
+		c.Remove(key)
+		c.stats.Rejections++
+		return
+	}
+
+	n, found := c.items[key]
+	if found {
+		// Unlinked while evicting, so the entry cannot be its own victim.
+		c.unlink(n)
+		c.weights.total -= n.weight
+	} else {
+		for len(c.items) >= c.capacity {
+			c.evict()
+		}
+		n = c.free.Get()
+		n.key = key
+		c.items[key] = n
+	}
+	for c.weights.over(w) {
+		c.evict()
+	}
+	n.value, n.weight = value, w
+	c.weights.total += w
+	c.pushFront(n)
+}
+
+// evict removes the least recently used entry.
+func (c *LRU) evict() {
+	victim := c.root.prev
+	c.unlink(victim)
+	delete(c.items, victim.key)
+	c.weights.total -= victim.weight
+	c.free.Put(victim)
+	c.stats.Evictions++
+}
+
+// Remove deletes key from the cache, reporting whether it was present.
+func (c *LRU) Remove(key string) bool {
+	n, found := c.items[key]
+	if !found {
+		return false
+	}
+	c.unlink(n)
+	delete(c.items, key)
+	c.weights.total -= n.weight
+	c.free.Put(n)
+	return true
+}
+
+// Clear removes all entries. Stats are preserved.
+func (c *LRU) Clear() {
+	c.items = make(map[string]*lruNode, c.capacity)
+	c.weights.total = 0
+	c.root.next = &c.root
+	c.root.prev = &c.root
+}
@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestLRUEviction(t *testing.T) {
+	c := MustNewLRU(2)
+	c.Put("a", "1")
+	c.Put("b", "2")
+	c.Get("a") // b becomes least recently used
+	c.Put("c", "3")
+
+	if _, found := c.Get("b"); found {
+		t.Error("b should have been evicted")
+	}
+	if value, found := c.Get("a"); !found || value != "1" {
+		t.Errorf("expected a=1, got %q, %v", value, found)
+	}
+	if value, found := c.Get("c"); !found || value != "3" {
+		t.Errorf("expected c=3, got %q, %v", value, found)
+	}
+	if c.Len() != 2 {
+		t.Errorf("expected length 2, got %d", c.Len())
+	}
+
+	stats := c.Stats()
+	if stats.Hits != 3 || stats.Misses != 1 || stats.Evictions != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestLRUUpdateAndRemove(t *testing.T) {
+	c := MustNewLRU(2)
+	c.Put("a", "1")
+	c.Put("b", "2")
+	c.Put("a", "updated") // refreshes a
+	c.Put("c", "3")       // evicts b
+
+	if value, _ := c.Peek("a"); value != "updated" {
+		t.Errorf("expected updated, got %s", value)
+	}
+	if _, found := c.Peek("b"); found {
+		t.Error("b should have been evicted")
+	}
+	if !c.Remove("a") || c.Remove("a") {
+		t.Error("remove should succeed exactly once")
+	}
+	c.Clear()
+	if c.Len() != 0 {
+		t.Errorf("expected empty cache, got %d", c.Len())
+	}
+}
+
+func TestLRUOracle(t *testing.T) {
+	const capacity = 8
+	c := MustNewLRU(capacity)
+	var order []string // least recent first
+	values := make(map[string]string)
+
+	touch := func(key string) {
+		for i, k := range order {
+			if k == key {
+				order = append(order[:i], order[i+1:]...)
+				break
+			}
+		}
+		order = append(order, key)
+	}
+
+	for i := 0; i < 2000; i++ {
+		key := fmt.Sprintf("k%d", (i*7)%13)
+		if i%3 == 0 {
+			value := fmt.Sprintf("v%d", i)
+			c.Put(key, value)
+			if _, found := values[key]; !found && len(order) == capacity {
+				delete(values, order[0])
+				order = order[1:]
+			}
+			values[key] = value
+			touch(key)
+			continue
+		}
+
+		got, found := c.Get(key)
+		want, wantFound := values[key]
+		if found != wantFound || got != want {
+			t.Fatalf("iteration %d: get(%s) = %q, %v; want %q, %v", i, key, got, found, want, wantFound)
+		}
+		if found {
+			touch(key)
+		}
+	}
+}
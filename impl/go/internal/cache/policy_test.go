@@ -0,0 +1,279 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/errs"
+)
+
+func TestPoliciesConform(t *testing.T) {
+	for _, p := range Policies() {
+		const capacity = 16
+		c := p.MustNew(capacity)
+		latest := make(map[string]string)
+		rng := rand.New(rand.NewSource(1))
+		var gets uint64
+
+		for i := 0; i < 20_000; i++ {
+			key := fmt.Sprint("k", rng.Intn(48))
+			switch r := rng.Intn(10); {
+			case r < 5:
+				gets++
+				if value, found := c.Get(key); found && value != latest[key] {
+					t.Fatalf("%s: get %s returned stale %q, want %q", p.Name, key, value, latest[key])
+				}
+			case r < 9:
+				value := fmt.Sprint(i)
+				c.Put(key, value)
+				latest[key] = value
+				if got, found := c.Peek(key); !found || got != value {
+					t.Fatalf("%s: expected %s=%s right after put, got %q, %v", p.Name, key, value, got, found)
+				}
+			default:
+				_, resident := c.Peek(key)
+				if c.Remove(key) != resident {
+					t.Fatalf("%s: remove %s should report %v", p.Name, key, resident)
+				}
+			}
+			if c.Len() > capacity {
+				t.Fatalf("%s: length %d exceeds capacity %d", p.Name, c.Len(), capacity)
+			}
+		}
+
+		s := c.Stats()
+		if s.Hits+s.Misses != gets || s.Evictions == 0 {
+			t.Errorf("%s: unexpected stats %+v for %d gets", p.Name, s, gets)
+		}
+		c.Clear()
+		if c.Len() != 0 || c.Capacity() != capacity {
+			t.Errorf("%s: expected empty cache of capacity %d after clear", p.Name, capacity)
+		}
+		if _, ok := LookupPolicy(p.Name); !ok {
+			t.Errorf("%s: lookup failed", p.Name)
+		}
+	}
+}
+
+func TestLFUEviction(t *testing.T) {
+	c := MustNewLFU(3)
+	c.Put("a", "1")
+	c.Put("b", "2")
+	c.Put("c", "3")
+	c.Get("a")
+	c.Get("a")
+	c.Get("c")
+	// b has the lowest frequency.
+	c.Put("d", "4")
+	if _, found := c.Peek("b"); found {
+		t.Error("expected b, the least frequently used, to be evicted")
+	}
+	// c has 2 accesses and d only 1, so d goes next.
+	c.Put("e", "5")
+	if _, found := c.Peek("d"); found {
+		t.Error("expected d to be evicted")
+	}
+	c.Get("e")
+	// c and e both have 2 accesses; c is the less recently used.
+	c.Put("f", "6")
+	if _, found := c.Peek("c"); found {
+		t.Error("expected the least recent of equally frequent entries to be evicted")
+	}
+	if c.Frequency("a") != 3 || c.Frequency("f") != 1 || c.Frequency("c") != 0 {
+		t.Errorf("unexpected frequencies a=%d f=%d c=%d", c.Frequency("a"), c.Frequency("f"), c.Frequency("c"))
+	}
+}
+
+func TestARCScanResistance(t *testing.T) {
+	const capacity = 100
+	hot := make([]string, capacity/2)
+	for i := range hot {
+		hot[i] = fmt.Sprint("hot", i)
+	}
+
+	survivors := func(c Cache) int {
+		for _, key := range hot {
+			c.Put(key, key)
+		}
+		for round := 0; round < 3; round++ {
+			for _, key := range hot {
+				c.Get(key)
+			}
+		}
+		for i := 0; i < 10*capacity; i++ {
+			c.Put(fmt.Sprint("scan", i), "x")
+		}
+		n := 0
+		for _, key := range hot {
+			if _, found := c.Peek(key); found {
+				n++
+			}
+		}
+		return n
+	}
+
+	if n := survivors(MustNewLRU(capacity)); n != 0 {
+		t.Errorf("expected a long scan to flush LRU, %d hot keys survived", n)
+	}
+	if n := survivors(MustNewARC(capacity)); n != len(hot) {
+		t.Errorf("expected ARC to keep all %d hot keys through a scan, kept %d", len(hot), n)
+	}
+}
+
+func TestARCAdaptsTarget(t *testing.T) {
+	c := MustNewARC(4)
+	c.Put("a", "a")
+	c.Put("b", "b")
+	c.Get("a")
+	c.Get("b") // a and b move to the frequency list
+	c.Put("c", "c")
+	c.Put("d", "d")
+	c.Put("e", "e") // evicts c, the oldest of the recency list, to a ghost
+
+	if c.Target() != 0 {
+		t.Fatalf("expected initial target 0, got %d", c.Target())
+	}
+	if _, found := c.Peek("c"); found {
+		t.Fatal("expected c to be evicted")
+	}
+	c.Put("c", "again")
+	if c.Target() != 1 {
+		t.Errorf("expected a ghost hit on the recency side to raise the target to 1, got %d", c.Target())
+	}
+	if v, found := c.Get("c"); !found || v != "again" {
+		t.Errorf("expected readmitted c=again, got %q, %v", v, found)
+	}
+	if c.Len() != 4 {
+		t.Errorf("expected a full cache of 4, got %d", c.Len())
+	}
+}
+
+func TestClockSecondChance(t *testing.T) {
+	c := MustNewClock(3)
+	c.Put("a", "1")
+	c.Put("b", "2")
+	c.Put("c", "3")
+	c.Get("a")
+	// The hand passes a, clearing its bit, and evicts b.
+	c.Put("d", "4")
+	if _, found := c.Peek("b"); found {
+		t.Error("expected b to be evicted")
+	}
+	if _, found := c.Peek("a"); !found {
+		t.Error("expected referenced a to get a second chance")
+	}
+	// a has used its chance; the hand now stops at c.
+	c.Put("e", "5")
+	if _, found := c.Peek("c"); found {
+		t.Error("expected c to be evicted next")
+	}
+
+	c.Remove("a")
+	c.Put("f", "6")
+	if c.Len() != 3 || c.Stats().Evictions != 2 {
+		t.Errorf("expected f to reuse a's slot without eviction, got len %d, %d evictions", c.Len(), c.Stats().Evictions)
+	}
+}
+
+func TestPoliciesRespectWeight(t *testing.T) {
+	const capacity, budget = 64, 1000
+	for _, p := range Policies() {
+		c := p.MustNew(capacity, WithMaxWeight(budget, Bytes))
+		rng := rand.New(rand.NewSource(2))
+		keys := make(map[string]bool)
+
+		for i := 0; i < 20_000; i++ {
+			key := fmt.Sprint("k", rng.Intn(96))
+			keys[key] = true
+			switch r := rng.Intn(10); {
+			case r < 5:
+				c.Get(key)
+			case r < 9:
+				// Mostly small values with the occasional one near the budget.
+				size := 1 + rng.Intn(40)
+				if rng.Intn(50) == 0 {
+					size = 900 + rng.Intn(200)
+				}
+				value := string(make([]byte, size))
+				c.Put(key, value)
+				if _, found := c.Peek(key); found != (Bytes(key, value) <= budget) {
+					t.Fatalf("%s: expected %s resident iff it fits the budget, got %v", p.Name, key, found)
+				}
+			default:
+				c.Remove(key)
+			}
+
+			var total int64
+			for key := range keys {
+				if value, found := c.Peek(key); found {
+					total += Bytes(key, value)
+				}
+			}
+			if c.Weight() != total || total > budget || c.Len() > capacity {
+				t.Fatalf("%s: weight %d (recomputed %d) over %d entries breaks budget %d", p.Name, c.Weight(), total, c.Len(), budget)
+			}
+		}
+		if s := c.Stats(); s.Rejections == 0 || s.Evictions == 0 {
+			t.Errorf("%s: expected rejections and evictions, got %+v", p.Name, s)
+		}
+		c.Clear()
+		if c.Weight() != 0 {
+			t.Errorf("%s: expected zero weight after clear, got %d", p.Name, c.Weight())
+		}
+	}
+}
+
+func TestWeightedEvictsUntilFit(t *testing.T) {
+	weigh := func(key, value string) int64 { return int64(len(value)) }
+	c := MustNewLRU(100, WithMaxWeight(10, weigh))
+	c.Put("a", "xxx")
+	c.Put("b", "xxx")
+	c.Put("c", "xxx")
+	c.Get("a")
+	// Fitting 6 more units means evicting both b and c, the least recent.
+	c.Put("d", "xxxxxx")
+	if c.Len() != 2 || c.Weight() != 9 {
+		t.Errorf("expected a and d with weight 9, got %d entries of weight %d", c.Len(), c.Weight())
+	}
+	if _, found := c.Peek("a"); !found {
+		t.Error("expected a, recently read, to survive")
+	}
+	// Growing an entry evicts others, never the entry itself.
+	c.Put("d", "xxxxxxxxx")
+	if _, found := c.Peek("d"); !found || c.Len() != 1 || c.Weight() != 9 {
+		t.Errorf("expected only d of weight 9, got %d entries of weight %d", c.Len(), c.Weight())
+	}
+	// An entry heavier than the whole budget is not cached, and replaces
+	// nothing but its own stale value.
+	c.Put("d", "xxxxxxxxxxx")
+	if _, found := c.Peek("d"); found || c.Stats().Rejections != 1 {
+		t.Errorf("expected the oversized update to drop d, got %+v", c.Stats())
+	}
+
+	unweighted := MustNewLRU(4)
+	unweighted.Put("a", "a long value that would be heavy")
+	if unweighted.Weight() != 1 {
+		t.Errorf("expected unit weights without a budget, got %d", unweighted.Weight())
+	}
+}
+
+func TestPoliciesRejectInvalid(t *testing.T) {
+	for _, p := range Policies() {
+		for name, build := range map[string]func() (Cache, error){
+			"capacity 0":   func() (Cache, error) { return p.New(0) },
+			"capacity -1":  func() (Cache, error) { return p.New(-1) },
+			"max weight 0": func() (Cache, error) { return p.New(4, WithMaxWeight(0, Bytes)) },
+			"nil weigher":  func() (Cache, error) { return p.New(4, WithMaxWeight(10, nil)) },
+		} {
+			c, err := build()
+			if !errors.Is(err, errs.ErrInvalidOption) {
+				t.Errorf("%s, %s: expected ErrInvalidOption, got %v", p.Name, name, err)
+			}
+			if c != nil {
+				t.Errorf("%s, %s: expected a nil cache, got %T", p.Name, name, c)
+			}
+		}
+	}
+}
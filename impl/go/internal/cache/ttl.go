@@ -0,0 +1,292 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dsa-lab/go/internal/errs"
+)
+
+// State describes the outcome of a TTL lookup.
+type State int
+
+const (
+	// Miss means nothing is cached for the key, or its entry has expired.
+	Miss State = iota
+	// Hit means a fresh value was returned.
+	Hit
+	// Negative means the key is cached as absent: a recent lookup of the
+	// backing store found nothing, so the caller need not ask again.
+	Negative
+	// Stale means an expired value was returned within the stale window and
+	// a refresh has been started in the background.
+	Stale
+)
+
+// String returns the name of the state.
+func (s State) String() string {
+	switch s {
+	case Hit:
+		return "hit"
+	case Negative:
+		return "negative"
+	case Stale:
+		return "stale"
+	default:
+		return "miss"
+	}
+}
+
+// Refresher reloads key from the backing store. found false means the key no
+// longer exists; a non-nil error leaves the stale entry in place so a later
+// lookup retries.
+type Refresher func(key string) (value string, found bool, err error)
+
+// TTLStats counts TTL lookups by outcome, along with background refreshes.
+type TTLStats struct {
+	Hits          uint64
+	Misses        uint64
+	NegativeHits  uint64
+	StaleHits     uint64
+	Refreshes     uint64
+	RefreshErrors uint64
+}
+
+// ttlEntry is a cached value, or a cached absence when negative is set.
+type ttlEntry struct {
+	value    string
+	negative bool
+	expires  time.Time
+}
+
+// TTL is an expiring map: each entry lives for a fixed time after it is
+// written and then reads as a miss. Two optional extensions serve a cache in
+// front of a slow store. Negative caching remembers keys the store lacks, with
+// their own, usually shorter, TTL, so repeated lookups of a missing key do not
+// all reach the store. Stale-while-revalidate keeps serving an expired value
+// for a grace window while one background call to the Refresher fetches a new
+// one, so readers never wait on the store for a key they recently had.
+//
+// Expired entries are dropped lazily by lookups and by Purge. A TTL is safe
+// for concurrent use.
+type TTL struct {
+	mu          sync.Mutex
+	ttl         time.Duration
+	negativeTTL time.Duration
+	staleFor    time.Duration
+	refresh     Refresher
+	now         func() time.Time
+	entries     map[string]*ttlEntry
+	refreshing  map[string]bool
+	pending     sync.WaitGroup
+	stats       TTLStats
+
+	// optErr is set by an option given an invalid argument.
+	optErr error
+}
+
+// TTLOption configures a TTL map at construction time.
+type TTLOption func(*TTL)
+
+// WithNegativeTTL enables negative caching: SetMissing records a key as
+// absent for d, which must be positive.
+func WithNegativeTTL(d time.Duration) TTLOption {
+	return func(m *TTL) {
+		if d <= 0 {
+			m.optErr = fmt.Errorf("cache: WithNegativeTTL(%v): %w", d, errs.ErrInvalidOption)
+			return
+		}
+		m.negativeTTL = d
+	}
+}
+
+// WithStaleWhileRevalidate keeps serving a value for up to window after it
+// expires, calling refresh in a new goroutine on the first such read. A
+// refresh that finds the key gone removes it, or caches it as absent when
+// negative caching is enabled. The window must be positive and refresh not
+// nil.
+func WithStaleWhileRevalidate(window time.Duration, refresh Refresher) TTLOption {
+	return func(m *TTL) {
+		if window <= 0 || refresh == nil {
+			m.optErr = fmt.Errorf("cache: WithStaleWhileRevalidate(%v, refresh set %v): %w", window, refresh != nil, errs.ErrInvalidOption)
+			return
+		}
+		m.staleFor = window
+		m.refresh = refresh
+	}
+}
+
+// WithClock replaces time.Now, for tests and simulations.
+func WithClock(now func() time.Time) TTLOption {
+	return func(m *TTL) {
+		m.now = now
+	}
+}
+
+// NewTTL creates an empty map whose entries expire ttl after being set. It
+// returns an error matching errs.ErrInvalidOption unless ttl is positive, or
+// if an option is given an invalid argument.
+func NewTTL(ttl time.Duration, opts ...TTLOption) (*TTL, error) {
+	if ttl <= 0 {
+		return nil, fmt.Errorf("cache: NewTTL(%v): %w", ttl, errs.ErrInvalidOption)
+	}
+	m := &TTL{
+		ttl:        ttl,
+		now:        time.Now,
+		entries:    make(map[string]*ttlEntry),
+		refreshing: make(map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	if m.optErr != nil {
+		return nil, m.optErr
+	}
+	return m, nil
+}
+
+// MustNewTTL is like NewTTL but panics if ttl or an option is invalid.
+func MustNewTTL(ttl time.Duration, opts ...TTLOption) *TTL {
+	return errs.Must(NewTTL(ttl, opts...))
+}
+
+// Set stores value for key with the map's TTL.
+func (m *TTL) Set(key, value string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.set(key, value)
+}
+
+func (m *TTL) set(key, value string) {
+	m.entries[key] = &ttlEntry{value: value, expires: m.now().Add(m.ttl)}
+}
+
+// SetMissing records that key is absent from the backing store. It reports
+// false, and caches nothing, unless negative caching is enabled.
+func (m *TTL) SetMissing(key string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.setMissing(key)
+}
+
+func (m *TTL) setMissing(key string) bool {
+	if m.negativeTTL <= 0 {
+		return false
+	}
+	m.entries[key] = &ttlEntry{negative: true, expires: m.now().Add(m.negativeTTL)}
+	return true
+}
+
+// Get looks up key. The value is meaningful only for Hit and Stale.
+func (m *TTL) Get(key string) (string, State) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, found := m.entries[key]
+	if !found {
+		m.stats.Misses++
+		return "", Miss
+	}
+	now := m.now()
+	if now.Before(e.expires) {
+		if e.negative {
+			m.stats.NegativeHits++
+			return "", Negative
+		}
+		m.stats.Hits++
+		return e.value, Hit
+	}
+	if !e.negative && m.refresh != nil && now.Before(e.expires.Add(m.staleFor)) {
+		m.stats.StaleHits++
+		m.revalidate(key, e)
+		return e.value, Stale
+	}
+	delete(m.entries, key)
+	m.stats.Misses++
+	return "", Miss
+}
+
+// revalidate starts a refresh of key, whose entry is e, unless one is
+// already running. The result is dropped if the entry has been replaced or
+// deleted by the time it arrives, since that write is newer than the
+// refresh.
+func (m *TTL) revalidate(key string, e *ttlEntry) {
+	if m.refreshing[key] {
+		return
+	}
+	m.refreshing[key] = true
+	m.stats.Refreshes++
+	m.pending.Add(1)
+	go func() {
+		defer m.pending.Done()
+		value, found, err := m.refresh(key)
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		delete(m.refreshing, key)
+		switch {
+		case m.entries[key] != e:
+			// Set or deleted during the refresh; that write stands.
+		case err != nil:
+			m.stats.RefreshErrors++
+		case found:
+			m.set(key, value)
+		case !m.setMissing(key):
+			delete(m.entries, key)
+		}
+	}()
+}
+
+// Wait blocks until every refresh started so far has finished.
+func (m *TTL) Wait() {
+	m.pending.Wait()
+}
+
+// Delete removes key, positive or negative, reporting whether it was present
+// and unexpired.
+func (m *TTL) Delete(key string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, found := m.entries[key]
+	if !found {
+		return false
+	}
+	delete(m.entries, key)
+	return m.now().Before(e.expires)
+}
+
+// Purge drops every entry that can no longer be served, including stale
+// values past their window, and returns how many it dropped.
+func (m *TTL) Purge() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	dropped := 0
+	for key, e := range m.entries {
+		deadline := e.expires
+		if !e.negative && m.refresh != nil {
+			deadline = deadline.Add(m.staleFor)
+		}
+		if !now.Before(deadline) {
+			delete(m.entries, key)
+			dropped++
+		}
+	}
+	return dropped
+}
+
+// Len returns the number of stored entries, negative ones and expired ones
+// not yet purged included.
+func (m *TTL) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.entries)
+}
+
+// Stats returns the lookup and refresh counters.
+func (m *TTL) Stats() TTLStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stats
+}
@@ -0,0 +1,206 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dsa-lab/go/internal/errs"
+)
+
+// fakeClock is a manually advanced time source.
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func expectState(t *testing.T, m *TTL, key string, want State, wantValue string) {
+	t.Helper()
+	value, state := m.Get(key)
+	if state != want || value != wantValue {
+		t.Errorf("expected %s %q for %s, got %s %q", want, wantValue, key, state, value)
+	}
+}
+
+func TestNewTTLRejectsNonPositive(t *testing.T) {
+	for _, ttl := range []time.Duration{0, -time.Second} {
+		if _, err := NewTTL(ttl); !errors.Is(err, errs.ErrInvalidOption) {
+			t.Errorf("NewTTL(%v): expected ErrInvalidOption, got %v", ttl, err)
+		}
+	}
+}
+
+func TestNewTTLRejectsInvalidOptions(t *testing.T) {
+	refresh := func(string) (string, bool, error) { return "", false, nil }
+	for name, opt := range map[string]TTLOption{
+		"zero negative TTL":     WithNegativeTTL(0),
+		"negative negative TTL": WithNegativeTTL(-time.Second),
+		"zero stale window":     WithStaleWhileRevalidate(0, refresh),
+		"nil refresher":         WithStaleWhileRevalidate(time.Second, nil),
+	} {
+		if _, err := NewTTL(time.Minute, opt); !errors.Is(err, errs.ErrInvalidOption) {
+			t.Errorf("%s: expected ErrInvalidOption, got %v", name, err)
+		}
+	}
+	// A valid option given later does not hide an invalid one.
+	if _, err := NewTTL(time.Minute, WithNegativeTTL(0), WithNegativeTTL(time.Second)); !errors.Is(err, errs.ErrInvalidOption) {
+		t.Errorf("expected ErrInvalidOption despite a later valid option, got %v", err)
+	}
+}
+
+func TestTTLExpiry(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	m := MustNewTTL(time.Minute, WithClock(clock.now))
+	m.Set("a", "1")
+	expectState(t, m, "a", Hit, "1")
+
+	clock.advance(time.Minute)
+	expectState(t, m, "a", Miss, "")
+	if m.Len() != 0 {
+		t.Errorf("expected the expired entry to be dropped, got length %d", m.Len())
+	}
+	if m.SetMissing("b") {
+		t.Error("expected SetMissing to be a no-op without negative caching")
+	}
+}
+
+func TestTTLNegativeCaching(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	m := MustNewTTL(time.Minute, WithNegativeTTL(10*time.Second), WithClock(clock.now))
+	if !m.SetMissing("gone") {
+		t.Fatal("expected SetMissing to cache the absence")
+	}
+	expectState(t, m, "gone", Negative, "")
+
+	clock.advance(10 * time.Second)
+	expectState(t, m, "gone", Miss, "")
+
+	// A value replaces a negative entry.
+	m.SetMissing("k")
+	m.Set("k", "v")
+	expectState(t, m, "k", Hit, "v")
+
+	s := m.Stats()
+	if s.NegativeHits != 1 || s.Misses != 1 || s.Hits != 1 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+}
+
+func TestTTLStaleWhileRevalidate(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	calls := 0
+	store := map[string]string{"a": "2"}
+	failing := false
+	refresh := func(key string) (string, bool, error) {
+		calls++
+		if failing {
+			return "", false, errors.New("store down")
+		}
+		value, found := store[key]
+		return value, found, nil
+	}
+	m := MustNewTTL(time.Minute,
+		WithStaleWhileRevalidate(30*time.Second, refresh),
+		WithNegativeTTL(5*time.Second),
+		WithClock(clock.now))
+
+	m.Set("a", "1")
+	clock.advance(time.Minute)
+	expectState(t, m, "a", Stale, "1")
+	m.Wait()
+	expectState(t, m, "a", Hit, "2")
+
+	// A failed refresh keeps serving the stale value until the window ends.
+	failing = true
+	clock.advance(time.Minute)
+	expectState(t, m, "a", Stale, "2")
+	m.Wait()
+	expectState(t, m, "a", Stale, "2")
+	m.Wait()
+	clock.advance(30 * time.Second)
+	expectState(t, m, "a", Miss, "")
+
+	// A refresh that finds the key gone caches its absence.
+	failing = false
+	m.Set("b", "1")
+	clock.advance(time.Minute)
+	expectState(t, m, "b", Stale, "1")
+	m.Wait()
+	expectState(t, m, "b", Negative, "")
+
+	s := m.Stats()
+	if calls != 4 || s.Refreshes != 4 || s.RefreshErrors != 2 || s.StaleHits != 4 {
+		t.Errorf("unexpected %d refresh calls and stats %+v", calls, s)
+	}
+}
+
+func TestTTLRefreshOnce(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	release := make(chan struct{})
+	calls := 0
+	refresh := func(key string) (string, bool, error) {
+		calls++
+		<-release
+		return "new", true, nil
+	}
+	m := MustNewTTL(time.Second, WithStaleWhileRevalidate(time.Minute, refresh), WithClock(clock.now))
+	m.Set("k", "old")
+	clock.advance(time.Second)
+	for i := 0; i < 5; i++ {
+		expectState(t, m, "k", Stale, "old")
+	}
+	close(release)
+	m.Wait()
+	if calls != 1 {
+		t.Errorf("expected stale reads to share one refresh, got %d", calls)
+	}
+	expectState(t, m, "k", Hit, "new")
+
+	m.Set("x", "1")
+	clock.advance(2 * time.Minute)
+	if n := m.Purge(); n != 2 || m.Len() != 0 {
+		t.Errorf("expected both entries purged past the stale window, got %d and length %d", n, m.Len())
+	}
+}
+
+// TestTTLWritesDuringRefresh checks that a refresh finishing after a Set or
+// Delete of its key does not overwrite or resurrect it.
+func TestTTLWritesDuringRefresh(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	started := make(chan string)
+	release := make(chan struct{})
+	found := true
+	refresh := func(key string) (string, bool, error) {
+		started <- key
+		<-release
+		return "refreshed", found, nil
+	}
+	m := MustNewTTL(time.Second,
+		WithStaleWhileRevalidate(time.Minute, refresh),
+		WithNegativeTTL(time.Minute),
+		WithClock(clock.now))
+
+	for _, c := range []struct {
+		name  string
+		found bool
+		write func(key string)
+		state State
+		value string
+	}{
+		{"set, refresh found", true, func(key string) { m.Set(key, "written") }, Hit, "written"},
+		{"set, refresh missing", false, func(key string) { m.Set(key, "written") }, Hit, "written"},
+		{"delete, refresh found", true, func(key string) { m.Delete(key) }, Miss, ""},
+		{"delete, refresh missing", false, func(key string) { m.Delete(key) }, Miss, ""},
+	} {
+		key := c.name
+		m.Set(key, "old")
+		clock.advance(time.Second)
+		found = c.found
+		expectState(t, m, key, Stale, "old")
+		<-started
+		c.write(key)
+		release <- struct{}{}
+		m.Wait()
+		expectState(t, m, key, c.state, c.value)
+	}
+}
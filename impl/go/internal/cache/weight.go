@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"fmt"
+
+	"github.com/dsa-lab/go/internal/errs"
+)
+
+// Weigher returns the cost of an entry against a cache's weight budget,
+// typically its size in bytes.
+type Weigher func(key, value string) int64
+
+// Bytes weighs an entry by the length of its key and value.
+func Bytes(key, value string) int64 {
+	return int64(len(key) + len(value))
+}
+
+// Option configures a cache at construction time.
+type Option func(*weights)
+
+// WithMaxWeight bounds the total weight of the cached entries, as measured by
+// w, in addition to the entry count. Inserts evict entries in policy order
+// until the new entry fits, and an entry heavier than max is not cached at
+// all, which Stats counts as a rejection. Use it when values range from bytes
+// to megabytes and an entry count says little about memory. max must be
+// positive and w non-nil.
+func WithMaxWeight(max int64, w Weigher) Option {
+	return func(ws *weights) {
+		if max < 1 || w == nil {
+			ws.err = fmt.Errorf("cache: WithMaxWeight(%d): %w", max, errs.ErrInvalidOption)
+			return
+		}
+		ws.max = max
+		ws.weigh = w
+	}
+}
+
+// weights tracks the total weight of a cache's entries against its budget.
+// Without a budget every entry weighs 1, so Weight equals Len.
+type weights struct {
+	max   int64
+	weigh Weigher
+	total int64
+
+	// err is set by an option given an invalid argument.
+	err error
+}
+
+// newWeights applies opts for a cache of capacity entries, returning an
+// error matching errs.ErrInvalidOption if capacity is below 1 or an option
+// is invalid.
+func newWeights(capacity int, opts []Option) (weights, error) {
+	if capacity < 1 {
+		return weights{}, fmt.Errorf("cache: capacity %d: %w", capacity, errs.ErrInvalidOption)
+	}
+	var ws weights
+	for _, opt := range opts {
+		opt(&ws)
+	}
+	return ws, ws.err
+}
+
+func (ws *weights) of(key, value string) int64 {
+	if ws.weigh == nil {
+		return 1
+	}
+	return ws.weigh(key, value)
+}
+
+// fits reports whether an entry of weight w could be cached at all.
+func (ws *weights) fits(w int64) bool {
+	return ws.max <= 0 || w <= ws.max
+}
+
+// over reports whether adding extra weight would exceed the budget.
+func (ws *weights) over(extra int64) bool {
+	return ws.max > 0 && ws.total+extra > ws.max
+}
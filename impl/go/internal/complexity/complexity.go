@@ -0,0 +1,106 @@
+// Package complexity fits measured cost curves to the growth classes the lab's
+// structures claim, so a size sweep can check empirically that a lookup is
+// O(1) or O(log n) rather than taking the documentation's word for it.
+package complexity
+
+import (
+	"errors"
+	"math"
+)
+
+// ErrSamples is returned when a curve has too few distinct sizes to fit.
+var ErrSamples = errors.New("complexity: need at least two distinct sizes")
+
+// Sample is the measured cost of one operation at size N.
+type Sample struct {
+	N    int
+	Cost float64
+}
+
+// Model is a candidate growth class: cost(n) ≈ c·Shape(n).
+type Model struct {
+	Name  string
+	Shape func(n float64) float64
+}
+
+// Models are the growth classes Fit chooses between, cheapest first.
+var Models = []Model{
+	{"O(1)", func(float64) float64 { return 1 }},
+	{"O(log n)", func(n float64) float64 { return math.Log2(n) }},
+	{"O(n)", func(n float64) float64 { return n }},
+	{"O(n log n)", func(n float64) float64 { return n * math.Log2(n) }},
+}
+
+// Result describes how a cost curve grows.
+type Result struct {
+	// Exponent is the slope k of the least-squares line through
+	// (log n, log cost), i.e. cost ∝ n^k: near 0 for O(1) and O(log n),
+	// near 1 for O(n).
+	Exponent float64
+	// Model is the best-fitting growth class and Constant its c.
+	Model    string
+	Constant float64
+	// Error is the root-mean-square relative error of the chosen model.
+	Error float64
+}
+
+// Fit fits samples against every model and returns the one with the smallest
+// relative error, alongside the power-law exponent. Sizes below 2 are ignored
+// since log n is not positive there.
+func Fit(samples []Sample) (Result, error) {
+	var pts []Sample
+	distinct := make(map[int]bool)
+	for _, s := range samples {
+		if s.N >= 2 && s.Cost > 0 {
+			pts = append(pts, s)
+			distinct[s.N] = true
+		}
+	}
+	if len(distinct) < 2 {
+		return Result{}, ErrSamples
+	}
+
+	res := Result{Exponent: slope(pts), Error: math.Inf(1)}
+	for _, m := range Models {
+		c, e := fitModel(pts, m)
+		if e < res.Error {
+			res.Model, res.Constant, res.Error = m.Name, c, e
+		}
+	}
+	return res, nil
+}
+
+// slope returns the least-squares slope of log cost against log n.
+func slope(pts []Sample) float64 {
+	var sx, sy, sxx, sxy float64
+	for _, p := range pts {
+		x, y := math.Log(float64(p.N)), math.Log(p.Cost)
+		sx += x
+		sy += y
+		sxx += x * x
+		sxy += x * y
+	}
+	n := float64(len(pts))
+	return (n*sxy - sx*sy) / (n*sxx - sx*sx)
+}
+
+// fitModel picks c to minimise the squared relative error of c·shape(n)
+// against each cost, weighting small and large sizes equally, and returns c
+// with the resulting RMS relative error.
+func fitModel(pts []Sample, m Model) (float64, float64) {
+	// Minimising Σ(c·r_i − 1)² over r_i = shape(n_i)/cost_i gives
+	// c = Σr_i / Σr_i².
+	var sr, srr float64
+	for _, p := range pts {
+		r := m.Shape(float64(p.N)) / p.Cost
+		sr += r
+		srr += r * r
+	}
+	c := sr / srr
+	var sum float64
+	for _, p := range pts {
+		e := c*m.Shape(float64(p.N))/p.Cost - 1
+		sum += e * e
+	}
+	return c, math.Sqrt(sum / float64(len(pts)))
+}
@@ -0,0 +1,58 @@
+package complexity
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func curve(f func(n float64) float64, noise float64) []Sample {
+	rng := rand.New(rand.NewSource(1))
+	var samples []Sample
+	for n := 100; n <= 10_000_000; n *= 10 {
+		jitter := 1 + noise*(2*rng.Float64()-1)
+		samples = append(samples, Sample{N: n, Cost: f(float64(n)) * jitter})
+	}
+	return samples
+}
+
+func TestFitClassifies(t *testing.T) {
+	tests := []struct {
+		model    string
+		cost     func(n float64) float64
+		exponent float64
+	}{
+		{"O(1)", func(float64) float64 { return 40 }, 0},
+		{"O(log n)", func(n float64) float64 { return 12 * math.Log2(n) }, 0.1},
+		{"O(n)", func(n float64) float64 { return 3 * n }, 1},
+		{"O(n log n)", func(n float64) float64 { return 0.5 * n * math.Log2(n) }, 1.1},
+	}
+	for _, tt := range tests {
+		res, err := Fit(curve(tt.cost, 0.05))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Model != tt.model {
+			t.Errorf("expected %s, got %s (exponent %.2f, error %.3f)", tt.model, res.Model, res.Exponent, res.Error)
+		}
+		if math.Abs(res.Exponent-tt.exponent) > 0.1 {
+			t.Errorf("%s: expected exponent near %.1f, got %.2f", tt.model, tt.exponent, res.Exponent)
+		}
+	}
+}
+
+func TestFitConstant(t *testing.T) {
+	res, err := Fit(curve(func(n float64) float64 { return 3 * n }, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(res.Constant-3) > 1e-9 || res.Error > 1e-9 {
+		t.Errorf("expected exact fit with constant 3, got %v (error %v)", res.Constant, res.Error)
+	}
+}
+
+func TestFitNeedsTwoSizes(t *testing.T) {
+	if _, err := Fit([]Sample{{N: 100, Cost: 5}, {N: 100, Cost: 6}, {N: 1, Cost: 1}}); err != ErrSamples {
+		t.Errorf("expected ErrSamples, got %v", err)
+	}
+}
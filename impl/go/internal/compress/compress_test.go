@@ -0,0 +1,151 @@
+package compress
+
+import (
+	"bytes"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// corpus returns inputs with different statistics: edge cases, English-like
+// text, random bytes, and the JSON workload files as real text.
+func corpus(t *testing.T) map[string][]byte {
+	t.Helper()
+	rng := rand.New(rand.NewSource(1))
+	random := make([]byte, 4096)
+	rng.Read(random)
+	all := make([]byte, 256)
+	for i := range all {
+		all[i] = byte(i)
+	}
+	words := strings.Fields("the quick brown fox jumps over the lazy dog and then the dog sleeps")
+	var text strings.Builder
+	for text.Len() < 20000 {
+		text.WriteString(words[rng.Intn(len(words))])
+		text.WriteByte(' ')
+	}
+
+	inputs := map[string][]byte{
+		"empty":  {},
+		"single": []byte("a"),
+		"repeat": bytes.Repeat([]byte("z"), 1000),
+		"all":    all,
+		"random": random,
+		"text":   []byte(text.String()),
+	}
+	for _, name := range []string{"mixed_zipf_small.json", "read_heavy_uniform_small.json"} {
+		data, err := os.ReadFile(filepath.Join("..", "..", "..", "..", "workloads", "map", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		inputs[name] = data
+	}
+	return inputs
+}
+
+func TestHuffmanRoundTrip(t *testing.T) {
+	for name, data := range corpus(t) {
+		enc := HuffmanEncode(data)
+		dec, err := HuffmanDecode(enc)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !bytes.Equal(dec, data) {
+			t.Fatalf("%s: round trip changed the data", name)
+		}
+		if strings.HasSuffix(name, ".json") && len(enc) > len(data)*7/10 {
+			t.Errorf("%s: expected text to shrink by at least 30%%, got %d of %d bytes", name, len(enc), len(data))
+		}
+	}
+}
+
+func TestLZ77RoundTrip(t *testing.T) {
+	for name, data := range corpus(t) {
+		enc := LZ77Encode(data)
+		dec, err := LZ77Decode(enc)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !bytes.Equal(dec, data) {
+			t.Fatalf("%s: round trip changed the data", name)
+		}
+		// Back-references beat a byte-wise code on repetitive input.
+		if name == "repeat" || strings.HasSuffix(name, ".json") {
+			if huff := len(HuffmanEncode(data)); len(enc) >= huff {
+				t.Errorf("%s: expected LZ77 below Huffman's %d bytes, got %d", name, huff, len(enc))
+			}
+		}
+	}
+	// Matches reaching back nearly the whole window, and overlapping runs.
+	rng := rand.New(rand.NewSource(2))
+	block := make([]byte, windowSize-100)
+	rng.Read(block)
+	data := append(append(append([]byte{}, block...), block...), bytes.Repeat([]byte("ab"), 500)...)
+	dec, err := LZ77Decode(LZ77Encode(data))
+	if err != nil || !bytes.Equal(dec, data) {
+		t.Fatalf("expected a long-distance round trip, got error %v", err)
+	}
+}
+
+func TestLZ77RejectsCorruptInput(t *testing.T) {
+	enc := LZ77Encode([]byte("abcabcabcabcabcabc"))
+	for _, bad := range [][]byte{nil, enc[:1], enc[:len(enc)/2], enc[:len(enc)-1]} {
+		if _, err := LZ77Decode(bad); err != ErrCorrupt {
+			t.Errorf("expected ErrCorrupt for %d bytes, got %v", len(bad), err)
+		}
+	}
+}
+
+func TestCanonicalCodes(t *testing.T) {
+	// Frequencies 1, 1, 2, 4 give the lengths 3, 3, 2, 1.
+	lengths := CodeLengths([]uint64{4, 2, 1, 1, 0})
+	want := []uint8{1, 2, 3, 3, 0}
+	if !bytes.Equal(lengths, want) {
+		t.Fatalf("expected lengths %v, got %v", want, lengths)
+	}
+	codes := CanonicalCodes(lengths)
+	wantCodes := []Code{{0b0, 1}, {0b10, 2}, {0b110, 3}, {0b111, 3}, {}}
+	for i, c := range codes {
+		if c != wantCodes[i] {
+			t.Errorf("symbol %d: expected %+v, got %+v", i, wantCodes[i], c)
+		}
+	}
+}
+
+func TestCodeLengthLimit(t *testing.T) {
+	// Fibonacci frequencies make the deepest possible Huffman tree.
+	freqs := make([]uint64, 40)
+	freqs[0], freqs[1] = 1, 1
+	for i := 2; i < len(freqs); i++ {
+		freqs[i] = freqs[i-1] + freqs[i-2]
+	}
+	lengths := CodeLengths(freqs)
+	kraft := 0.0
+	for _, n := range lengths {
+		if n == 0 || n > MaxCodeLen {
+			t.Fatalf("expected every length in [1, %d], got %v", MaxCodeLen, lengths)
+		}
+		kraft += 1 / float64(uint64(1)<<n)
+	}
+	if kraft > 1 {
+		t.Errorf("expected a prefix code, got Kraft sum %v", kraft)
+	}
+	if _, err := newDecoder(lengths); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHuffmanRejectsCorruptInput(t *testing.T) {
+	enc := HuffmanEncode([]byte("hello, world"))
+	for _, bad := range [][]byte{nil, enc[:1], enc[:3], enc[:len(enc)-1]} {
+		if _, err := HuffmanDecode(bad); err != ErrCorrupt {
+			t.Errorf("expected ErrCorrupt for %d bytes, got %v", len(bad), err)
+		}
+	}
+	// Three codes of length one cannot form a prefix code.
+	if _, err := newDecoder([]uint8{1, 1, 1}); err != ErrCorrupt {
+		t.Errorf("expected ErrCorrupt for oversubscribed lengths, got %v", err)
+	}
+}
@@ -0,0 +1,309 @@
+// Package compress implements textbook lossless compressors on top of the
+// lab's structures. They favour clarity over speed and use their own
+// container formats; they are for study and comparison, not interchange.
+package compress
+
+import (
+	"encoding/binary"
+	"errors"
+	"slices"
+
+	"github.com/dsa-lab/go/internal/heap"
+	"github.com/dsa-lab/go/internal/order"
+)
+
+// ErrCorrupt is returned when compressed input is malformed or truncated.
+var ErrCorrupt = errors.New("compress: corrupt input")
+
+// MaxCodeLen bounds the length of any Huffman code, so a code always fits in
+// a Code and a decoder's tables stay small.
+const MaxCodeLen = 24
+
+// Code is a prefix code: its Len low bits of Bits, most significant first.
+type Code struct {
+	Bits uint32
+	Len  uint8
+}
+
+// huffNode is a leaf (symbol >= 0) or an internal node of a Huffman tree.
+// seq orders nodes of equal weight by creation, so the same frequencies
+// always build the same tree.
+type huffNode struct {
+	weight      uint64
+	seq         int
+	symbol      int
+	left, right *huffNode
+}
+
+// CodeLengths returns the length of each symbol's Huffman code for the given
+// frequencies; symbols with frequency zero get length zero. Huffman's
+// algorithm repeatedly merges the two lightest trees, taken from a min-heap.
+// If a code would exceed MaxCodeLen, the frequencies are flattened by
+// halving and the tree rebuilt, trading a little compression for the bound.
+func CodeLengths(freqs []uint64) []uint8 {
+	weights := slices.Clone(freqs)
+	for {
+		lengths, longest := huffmanLengths(weights)
+		if longest <= MaxCodeLen {
+			return lengths
+		}
+		for i, w := range weights {
+			if w > 0 {
+				weights[i] = w/2 + 1
+			}
+		}
+	}
+}
+
+func huffmanLengths(freqs []uint64) ([]uint8, int) {
+	lengths := make([]uint8, len(freqs))
+	h := heap.New(order.By(func(n *huffNode) uint64 { return n.weight }).
+		Then(order.By(func(n *huffNode) int { return n.seq })))
+	seq := 0
+	for symbol, f := range freqs {
+		if f > 0 {
+			h.Push(&huffNode{weight: f, seq: seq, symbol: symbol})
+			seq++
+		}
+	}
+	switch h.Len() {
+	case 0:
+		return lengths, 0
+	case 1:
+		// A lone symbol still needs one bit per occurrence.
+		only, _ := h.Pop()
+		lengths[only.symbol] = 1
+		return lengths, 1
+	}
+	for h.Len() > 1 {
+		a, _ := h.Pop()
+		b, _ := h.Pop()
+		h.Push(&huffNode{weight: a.weight + b.weight, seq: seq, symbol: -1, left: a, right: b})
+		seq++
+	}
+	root, _ := h.Pop()
+
+	longest := 0
+	var walk func(n *huffNode, depth int)
+	walk = func(n *huffNode, depth int) {
+		if n.symbol >= 0 {
+			lengths[n.symbol] = uint8(min(depth, 255))
+			longest = max(longest, depth)
+			return
+		}
+		walk(n.left, depth+1)
+		walk(n.right, depth+1)
+	}
+	walk(root, 0)
+	return lengths, longest
+}
+
+// CanonicalCodes assigns canonical codes to the given lengths: codes of each
+// length are consecutive in symbol order, and shorter codes precede longer
+// ones. A canonical code is determined by its lengths alone, so a compressed
+// stream need only store the lengths.
+func CanonicalCodes(lengths []uint8) []Code {
+	codes := make([]Code, len(lengths))
+	code := uint32(0)
+	prevLen := uint8(0)
+	for _, symbol := range canonicalOrder(lengths) {
+		n := lengths[symbol]
+		code <<= n - prevLen
+		codes[symbol] = Code{Bits: code, Len: n}
+		code++
+		prevLen = n
+	}
+	return codes
+}
+
+// canonicalOrder returns the coded symbols sorted by length, then symbol.
+func canonicalOrder(lengths []uint8) []int {
+	var symbols []int
+	for symbol, n := range lengths {
+		if n > 0 {
+			symbols = append(symbols, symbol)
+		}
+	}
+	slices.SortStableFunc(symbols, func(a, b int) int {
+		return int(lengths[a]) - int(lengths[b])
+	})
+	return symbols
+}
+
+// decoder decodes a canonical prefix code one bit at a time. For each length
+// it knows the first code and how many codes have it; a prefix of l bits is
+// a complete code exactly when it falls in that length's range.
+type decoder struct {
+	first   [MaxCodeLen + 1]uint32 // first code of each length
+	count   [MaxCodeLen + 1]int    // number of codes of each length
+	offset  [MaxCodeLen + 1]int    // index in symbols of the first code of each length
+	symbols []int
+}
+
+// newDecoder builds a decoder for the canonical code with the given lengths.
+// It reports ErrCorrupt if a length exceeds MaxCodeLen or the lengths do not
+// form a prefix code.
+func newDecoder(lengths []uint8) (*decoder, error) {
+	d := &decoder{symbols: canonicalOrder(lengths)}
+	for _, symbol := range d.symbols {
+		if lengths[symbol] > MaxCodeLen {
+			return nil, ErrCorrupt
+		}
+		d.count[lengths[symbol]]++
+	}
+	code, index := uint32(0), 0
+	for n := 1; n <= MaxCodeLen; n++ {
+		code <<= 1
+		d.first[n], d.offset[n] = code, index
+		code += uint32(d.count[n])
+		index += d.count[n]
+		if code > 1<<n {
+			return nil, ErrCorrupt
+		}
+	}
+	return d, nil
+}
+
+// decode reads one symbol from r.
+func (d *decoder) decode(r *bitReader) (int, error) {
+	code := uint32(0)
+	for n := 1; n <= MaxCodeLen; n++ {
+		bit, ok := r.readBit()
+		if !ok {
+			return 0, ErrCorrupt
+		}
+		code = code<<1 | bit
+		if i := code - d.first[n]; code >= d.first[n] && int(i) < d.count[n] {
+			return d.symbols[d.offset[n]+int(i)], nil
+		}
+	}
+	return 0, ErrCorrupt
+}
+
+// bitWriter packs codes into bytes, most significant bit first.
+type bitWriter struct {
+	buf []byte
+	acc uint64
+	n   uint
+}
+
+func (w *bitWriter) write(c Code) {
+	w.acc = w.acc<<c.Len | uint64(c.Bits)
+	w.n += uint(c.Len)
+	for w.n >= 8 {
+		w.n -= 8
+		w.buf = append(w.buf, byte(w.acc>>w.n))
+	}
+	w.acc &= 1<<w.n - 1
+}
+
+// bytes pads the last byte with zeros and returns the output.
+func (w *bitWriter) bytes() []byte {
+	if w.n > 0 {
+		w.buf = append(w.buf, byte(w.acc<<(8-w.n)))
+		w.acc, w.n = 0, 0
+	}
+	return w.buf
+}
+
+// bitReader reads bits most significant first.
+type bitReader struct {
+	data []byte
+	pos  int // bit position
+}
+
+func (r *bitReader) readBit() (uint32, bool) {
+	if r.pos >= 8*len(r.data) {
+		return 0, false
+	}
+	bit := uint32(r.data[r.pos/8]>>(7-r.pos%8)) & 1
+	r.pos++
+	return bit, true
+}
+
+// readBits reads an n-bit number.
+func (r *bitReader) readBits(n int) (uint32, bool) {
+	v := uint32(0)
+	for i := 0; i < n; i++ {
+		bit, ok := r.readBit()
+		if !ok {
+			return 0, false
+		}
+		v = v<<1 | bit
+	}
+	return v, true
+}
+
+// HuffmanEncode compresses data with a canonical Huffman code over bytes.
+// The output holds the input length, the code length of each byte value
+// that occurs, and the coded bits.
+func HuffmanEncode(data []byte) []byte {
+	freqs := make([]uint64, 256)
+	for _, b := range data {
+		freqs[b]++
+	}
+	lengths := CodeLengths(freqs)
+	codes := CanonicalCodes(lengths)
+
+	w := &bitWriter{buf: binary.AppendUvarint(nil, uint64(len(data)))}
+	if len(data) == 0 {
+		return w.buf
+	}
+	used := 0
+	for _, n := range lengths {
+		if n > 0 {
+			used++
+		}
+	}
+	w.buf = append(w.buf, byte(used-1))
+	for symbol, n := range lengths {
+		if n > 0 {
+			w.buf = append(w.buf, byte(symbol), n)
+		}
+	}
+	for _, b := range data {
+		w.write(codes[b])
+	}
+	return w.bytes()
+}
+
+// HuffmanDecode reverses HuffmanEncode.
+func HuffmanDecode(enc []byte) ([]byte, error) {
+	size, n := binary.Uvarint(enc)
+	if n <= 0 {
+		return nil, ErrCorrupt
+	}
+	enc = enc[n:]
+	if size == 0 {
+		return []byte{}, nil
+	}
+	if len(enc) < 1 {
+		return nil, ErrCorrupt
+	}
+	used := int(enc[0]) + 1
+	if len(enc) < 1+2*used {
+		return nil, ErrCorrupt
+	}
+	lengths := make([]uint8, 256)
+	for i := 0; i < used; i++ {
+		lengths[enc[1+2*i]] = enc[2+2*i]
+	}
+	d, err := newDecoder(lengths)
+	if err != nil {
+		return nil, err
+	}
+	r := &bitReader{data: enc[1+2*used:]}
+	// Every symbol takes at least one bit, which bounds a plausible size.
+	if size > uint64(8*len(r.data)) {
+		return nil, ErrCorrupt
+	}
+	out := make([]byte, 0, size)
+	for uint64(len(out)) < size {
+		symbol, err := d.decode(r)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, byte(symbol))
+	}
+	return out, nil
+}
@@ -0,0 +1,216 @@
+package compress
+
+import (
+	"encoding/binary"
+	"strconv"
+
+	"github.com/dsa-lab/go/internal/hashmap"
+)
+
+// LZ77 parameters, as in DEFLATE: matches of 3 to 258 bytes reach back at
+// most 32 KiB.
+const (
+	windowBits = 15
+	windowSize = 1 << windowBits
+	minMatch   = 3
+	maxMatch   = 258
+	// maxChain bounds the candidates examined per position, capping the
+	// cost of long chains in repetitive input.
+	maxChain = 64
+)
+
+// The token alphabet: symbols below 256 are literal bytes, and symbol
+// 256+k starts a match of length minMatch+k.
+const (
+	matchBase   = 256
+	alphabetLen = matchBase + maxMatch - minMatch + 1
+)
+
+// matchFinder indexes every position of the input by its next three bytes.
+// The lab hashmap maps each distinct 3-byte prefix to a dense slot, as in
+// multiset, and head holds the latest position of each slot; prev links
+// each position to the previous one with the same prefix, forming the hash
+// chain that the finder walks from newest to oldest.
+type matchFinder struct {
+	data  []byte
+	text  string // data as a string, so prefixes are substrings, not copies
+	slots *hashmap.HashMap
+	head  []int32
+	prev  []int32 // indexed by position mod windowSize
+}
+
+func newMatchFinder(data []byte) *matchFinder {
+	prev := make([]int32, windowSize)
+	for i := range prev {
+		prev[i] = -1
+	}
+	return &matchFinder{data: data, text: string(data), slots: hashmap.MustNew(), prev: prev}
+}
+
+// insert records position i, returning the previous head of its chain.
+func (f *matchFinder) insert(i int) int32 {
+	key := f.text[i : i+minMatch]
+	var slot int
+	if v, found := f.slots.Get(key); found {
+		slot, _ = strconv.Atoi(v)
+	} else {
+		slot = len(f.head)
+		f.slots.Insert(key, strconv.Itoa(slot))
+		f.head = append(f.head, -1)
+	}
+	candidate := f.head[slot]
+	f.prev[i%windowSize] = candidate
+	f.head[slot] = int32(i)
+	return candidate
+}
+
+// longest inserts position i and returns the longest earlier match for the
+// bytes at i within the window, walking at most maxChain candidates.
+func (f *matchFinder) longest(i int) (length, distance int) {
+	if i+minMatch > len(f.data) {
+		return 0, 0
+	}
+	limit := min(maxMatch, len(f.data)-i)
+	candidate := f.insert(i)
+	for chain := 0; candidate >= 0 && chain < maxChain; chain++ {
+		p := int(candidate)
+		if i-p > windowSize-1 {
+			break
+		}
+		n := 0
+		for n < limit && f.data[p+n] == f.data[i+n] {
+			n++
+		}
+		if n > length {
+			length, distance = n, i-p
+			if n == limit {
+				break
+			}
+		}
+		candidate = f.prev[p%windowSize]
+	}
+	if length < minMatch {
+		return 0, 0
+	}
+	return length, distance
+}
+
+// token is a literal byte (length zero) or a back-reference.
+type token struct {
+	literal          byte
+	length, distance int
+}
+
+// LZ77Encode compresses data by replacing repeated strings with references
+// to an earlier occurrence, found greedily through hash chains, then codes
+// the literals and match lengths with a canonical Huffman code. Distances
+// are written as plain 15-bit numbers. The output holds the input length,
+// the code lengths of the symbols in use, and the coded tokens.
+func LZ77Encode(data []byte) []byte {
+	f := newMatchFinder(data)
+	var tokens []token
+	freqs := make([]uint64, alphabetLen)
+	for i := 0; i < len(data); {
+		length, distance := f.longest(i)
+		if length == 0 {
+			tokens = append(tokens, token{literal: data[i]})
+			freqs[data[i]]++
+			i++
+			continue
+		}
+		tokens = append(tokens, token{length: length, distance: distance})
+		freqs[matchBase+length-minMatch]++
+		// Index the positions inside the match so later matches can
+		// start there.
+		for j := i + 1; j < i+length && j+minMatch <= len(data); j++ {
+			f.insert(j)
+		}
+		i += length
+	}
+
+	lengths := CodeLengths(freqs)
+	codes := CanonicalCodes(lengths)
+	w := &bitWriter{buf: binary.AppendUvarint(nil, uint64(len(data)))}
+	used := 0
+	for _, n := range lengths {
+		if n > 0 {
+			used++
+		}
+	}
+	w.buf = binary.AppendUvarint(w.buf, uint64(used))
+	for symbol, n := range lengths {
+		if n > 0 {
+			w.buf = binary.AppendUvarint(w.buf, uint64(symbol))
+			w.buf = append(w.buf, n)
+		}
+	}
+	for _, t := range tokens {
+		if t.length == 0 {
+			w.write(codes[t.literal])
+			continue
+		}
+		w.write(codes[matchBase+t.length-minMatch])
+		w.write(Code{Bits: uint32(t.distance - 1), Len: windowBits})
+	}
+	return w.bytes()
+}
+
+// LZ77Decode reverses LZ77Encode.
+func LZ77Decode(enc []byte) ([]byte, error) {
+	size, n := binary.Uvarint(enc)
+	if n <= 0 {
+		return nil, ErrCorrupt
+	}
+	enc = enc[n:]
+	used, n := binary.Uvarint(enc)
+	if n <= 0 || used > alphabetLen {
+		return nil, ErrCorrupt
+	}
+	enc = enc[n:]
+	lengths := make([]uint8, alphabetLen)
+	for i := uint64(0); i < used; i++ {
+		symbol, n := binary.Uvarint(enc)
+		if n <= 0 || symbol >= alphabetLen || len(enc) <= n {
+			return nil, ErrCorrupt
+		}
+		lengths[symbol] = enc[n]
+		enc = enc[n+1:]
+	}
+	d, err := newDecoder(lengths)
+	if err != nil {
+		return nil, err
+	}
+	r := &bitReader{data: enc}
+	// A token takes at least one bit and yields at most maxMatch bytes.
+	if size > uint64(8*len(enc))*maxMatch {
+		return nil, ErrCorrupt
+	}
+
+	out := make([]byte, 0, size)
+	for uint64(len(out)) < size {
+		symbol, err := d.decode(r)
+		if err != nil {
+			return nil, err
+		}
+		if symbol < matchBase {
+			out = append(out, byte(symbol))
+			continue
+		}
+		length := symbol - matchBase + minMatch
+		bits, ok := r.readBits(windowBits)
+		if !ok {
+			return nil, ErrCorrupt
+		}
+		distance := int(bits) + 1
+		if distance > len(out) || uint64(len(out)+length) > size {
+			return nil, ErrCorrupt
+		}
+		// Copy byte by byte: a match may overlap the bytes it produces,
+		// which is how runs are encoded.
+		start := len(out) - distance
+		for k := 0; k < length; k++ {
+			out = append(out, out[start+k])
+		}
+	}
+	return out, nil
+}
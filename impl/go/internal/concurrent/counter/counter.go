@@ -0,0 +1,106 @@
+// Package counter provides a striped counter for hot, write-mostly counts
+// such as the size of a concurrent map. A single atomic integer serializes
+// every increment on one cache line; spreading the count over padded cells
+// lets concurrent writers mostly touch different lines, at the cost of
+// summing all cells to read the total.
+package counter
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sync/atomic"
+
+	"github.com/dsa-lab/go/internal/errs"
+)
+
+// cell is padded to a full cache line so neighbouring cells do not share one.
+type cell struct {
+	n atomic.Int64
+	_ [56]byte
+}
+
+// Counter is a striped int64 counter in the style of Java's LongAdder. Adds
+// are spread across cells and Load sums them, so Load is not an atomic
+// snapshot: adds that overlap it may or may not be included. It is safe for
+// concurrent use.
+type Counter struct {
+	cells []cell
+	mask  uint64
+
+	// optErr is set by an option given an invalid argument.
+	optErr error
+}
+
+// Option configures a Counter.
+type Option func(*Counter)
+
+// WithStripes sets the number of cells, rounded up to a power of two. n must
+// be at least 1.
+func WithStripes(n int) Option {
+	return func(c *Counter) {
+		if n < 1 {
+			c.optErr = fmt.Errorf("counter: WithStripes(%d): %w", n, errs.ErrInvalidOption)
+			return
+		}
+		size := 1
+		for size < n {
+			size <<= 1
+		}
+		c.cells = make([]cell, size)
+	}
+}
+
+// New creates a counter at zero with four cells per available processor by
+// default. It returns an error matching errs.ErrInvalidOption if WithStripes
+// is given fewer than one stripe.
+func New(opts ...Option) (*Counter, error) {
+	c := &Counter{}
+	WithStripes(4 * runtime.GOMAXPROCS(0))(c)
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.optErr != nil {
+		return nil, c.optErr
+	}
+	c.mask = uint64(len(c.cells) - 1)
+	return c, nil
+}
+
+// MustNew is like New but panics if an option is invalid.
+func MustNew(opts ...Option) *Counter {
+	return errs.Must(New(opts...))
+}
+
+// Add adds delta to a randomly chosen cell.
+func (c *Counter) Add(delta int64) {
+	c.cells[uint64(rand.Uint32())&c.mask].n.Add(delta)
+}
+
+// AddHash adds delta to the cell selected by hash. Callers that already hold
+// a well-mixed hash, such as a map key's, avoid drawing a random number.
+func (c *Counter) AddHash(hash uint64, delta int64) {
+	// Use the high bits; hash tables typically index by the low ones.
+	c.cells[(hash>>32)&c.mask].n.Add(delta)
+}
+
+// Load returns the sum of all cells.
+func (c *Counter) Load() int64 {
+	var sum int64
+	for i := range c.cells {
+		sum += c.cells[i].n.Load()
+	}
+	return sum
+}
+
+// Reset sets every cell to zero. Adds racing with Reset may survive it.
+func (c *Counter) Reset() {
+	for i := range c.cells {
+		c.cells[i].n.Store(0)
+	}
+}
+
+// Stripes returns the number of cells.
+func (c *Counter) Stripes() int {
+	return len(c.cells)
+}
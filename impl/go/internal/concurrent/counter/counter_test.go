@@ -0,0 +1,54 @@
+package counter
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/errs"
+)
+
+func TestConcurrentAdds(t *testing.T) {
+	c := MustNew()
+	const goroutines, perG = 16, 10000
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < perG; i++ {
+				if i%2 == 0 {
+					c.Add(1)
+				} else {
+					c.AddHash(uint64(g*perG+i)*0x9e3779b97f4a7c15, 2)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	if want := int64(goroutines * perG / 2 * 3); c.Load() != want {
+		t.Errorf("expected %d, got %d", want, c.Load())
+	}
+	c.Reset()
+	if c.Load() != 0 {
+		t.Errorf("expected 0 after Reset, got %d", c.Load())
+	}
+}
+
+func TestStripes(t *testing.T) {
+	if got := MustNew(WithStripes(5)).Stripes(); got != 8 {
+		t.Errorf("expected 8 stripes, got %d", got)
+	}
+	c := MustNew(WithStripes(1))
+	c.Add(-3)
+	c.AddHash(^uint64(0), 1)
+	if c.Load() != -2 {
+		t.Errorf("expected -2, got %d", c.Load())
+	}
+	if _, err := New(WithStripes(0)); !errors.Is(err, errs.ErrInvalidOption) {
+		t.Errorf("WithStripes(0): expected ErrInvalidOption, got %v", err)
+	}
+	if _, err := New(WithStripes(0), WithStripes(4)); !errors.Is(err, errs.ErrInvalidOption) {
+		t.Errorf("WithStripes(0), WithStripes(4): expected ErrInvalidOption, got %v", err)
+	}
+}
@@ -0,0 +1,261 @@
+// Package lockfree provides an experimental concurrent map in which no
+// operation ever blocks on a lock. Every bucket is an immutable snapshot
+// published through an atomic pointer: a writer copies the bucket, applies
+// its change and swaps the copy in with compare-and-swap, retrying if another
+// writer got there first. Readers load the pointer and never retry.
+//
+// Growing the table is cooperative. A resize installs a table twice as large
+// beside the current one; from then on each operation first migrates the
+// bucket it needs, by freezing the old bucket so no further writes can land
+// in it and copying its entries across. Buckets nobody touches are migrated
+// by the goroutine that started the resize, and the new table takes over once
+// every bucket has moved.
+package lockfree
+
+import (
+	"sync/atomic"
+
+	"github.com/cespare/xxhash/v2"
+
+	"github.com/dsa-lab/go/internal/concurrent/counter"
+)
+
+const (
+	initialBuckets = 16
+	// maxLoad is the average number of entries per bucket that triggers a
+	// resize. Buckets are copied on every write, so they must stay short.
+	maxLoad = 2
+	// checkLoad is the bucket length above which an insert sums the size
+	// counter to decide whether to grow. Reading the striped counter on
+	// every insert would undo the point of striping it.
+	checkLoad = 2 * maxLoad
+)
+
+type entry struct {
+	hash       uint64
+	key, value string
+}
+
+// bucket is never modified after it has been published.
+type bucket struct {
+	entries []entry
+	// frozen marks a bucket whose contents have been handed to the next
+	// table; writers must go there instead.
+	frozen bool
+}
+
+var emptyBucket = &bucket{}
+
+type table struct {
+	buckets []atomic.Pointer[bucket]
+	mask    uint64
+	// next is the table being migrated to, if a resize is in progress.
+	next atomic.Pointer[table]
+	// migrated counts buckets of this table filled from its predecessor.
+	migrated atomic.Int64
+}
+
+func newTable(n int, fill bool) *table {
+	t := &table{buckets: make([]atomic.Pointer[bucket], n), mask: uint64(n - 1)}
+	if fill {
+		for i := range t.buckets {
+			t.buckets[i].Store(emptyBucket)
+		}
+	}
+	return t
+}
+
+// Map is a lock-free concurrent string map. It is safe for concurrent use.
+type Map struct {
+	table atomic.Pointer[table]
+	size  *counter.Counter
+}
+
+// New creates an empty map.
+func New() *Map {
+	m := &Map{size: counter.MustNew()}
+	m.table.Store(newTable(initialBuckets, true))
+	return m
+}
+
+// slot returns the live bucket pointer for hash, migrating it into the next
+// table first if a resize is under way.
+func (m *Map) slot(hash uint64) *atomic.Pointer[bucket] {
+	t := m.table.Load()
+	if next := t.next.Load(); next != nil {
+		j := hash & next.mask
+		m.migrate(t, next, j)
+		return &next.buckets[j]
+	}
+	return &t.buckets[hash&t.mask]
+}
+
+// migrate fills bucket j of next from the matching bucket of t, freezing that
+// bucket so that later writes fail their compare-and-swap and retry against
+// next. Any number of goroutines may migrate the same bucket; all compute the
+// same contents from the frozen snapshot and only the first install wins.
+func (m *Map) migrate(t, next *table, j uint64) {
+	if next.buckets[j].Load() != nil {
+		return
+	}
+	src := &t.buckets[j&t.mask]
+	b := src.Load()
+	for !b.frozen {
+		frozen := &bucket{entries: b.entries, frozen: true}
+		if src.CompareAndSwap(b, frozen) {
+			b = frozen
+			break
+		}
+		b = src.Load()
+	}
+
+	var entries []entry
+	for _, e := range b.entries {
+		if e.hash&next.mask == j {
+			entries = append(entries, e)
+		}
+	}
+	moved := emptyBucket
+	if len(entries) > 0 {
+		moved = &bucket{entries: entries}
+	}
+	if next.buckets[j].CompareAndSwap(nil, moved) {
+		if next.migrated.Add(1) == int64(len(next.buckets)) {
+			m.table.CompareAndSwap(t, next)
+		}
+	}
+}
+
+// grow starts a resize of t if none is running and t is still current, then
+// migrates every bucket.
+func (m *Map) grow(t *table) {
+	if m.table.Load() != t || t.next.Load() != nil {
+		return
+	}
+	next := newTable(2*len(t.buckets), false)
+	if !t.next.CompareAndSwap(nil, next) {
+		return
+	}
+	for j := range next.buckets {
+		m.migrate(t, next, uint64(j))
+	}
+}
+
+func find(b *bucket, hash uint64, key string) int {
+	for i, e := range b.entries {
+		if e.hash == hash && e.key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// Insert inserts or replaces the value for key.
+// Returns the previous value and true if the key existed.
+func (m *Map) Insert(key, value string) (string, bool) {
+	hash := xxhash.Sum64String(key)
+	for {
+		s := m.slot(hash)
+		b := s.Load()
+		if b.frozen {
+			continue // a resize began; slot will route to the next table
+		}
+		i := find(b, hash, key)
+		entries := make([]entry, len(b.entries), len(b.entries)+1)
+		copy(entries, b.entries)
+		if i >= 0 {
+			entries[i].value = value
+		} else {
+			entries = append(entries, entry{hash: hash, key: key, value: value})
+		}
+		if !s.CompareAndSwap(b, &bucket{entries: entries}) {
+			continue
+		}
+		if i >= 0 {
+			return b.entries[i].value, true
+		}
+		m.size.AddHash(hash, 1)
+		if len(entries) > checkLoad {
+			if t := m.table.Load(); m.size.Load() > maxLoad*int64(len(t.buckets)) {
+				m.grow(t)
+			}
+		}
+		return "", false
+	}
+}
+
+// Get returns the value for key.
+func (m *Map) Get(key string) (string, bool) {
+	hash := xxhash.Sum64String(key)
+	b := m.slot(hash).Load()
+	if i := find(b, hash, key); i >= 0 {
+		return b.entries[i].value, true
+	}
+	return "", false
+}
+
+// Remove removes key.
+// Returns the removed value and true if the key existed.
+func (m *Map) Remove(key string) (string, bool) {
+	hash := xxhash.Sum64String(key)
+	for {
+		s := m.slot(hash)
+		b := s.Load()
+		if b.frozen {
+			continue
+		}
+		i := find(b, hash, key)
+		if i < 0 {
+			return "", false
+		}
+		replacement := emptyBucket
+		if len(b.entries) > 1 {
+			entries := make([]entry, 0, len(b.entries)-1)
+			entries = append(entries, b.entries[:i]...)
+			entries = append(entries, b.entries[i+1:]...)
+			replacement = &bucket{entries: entries}
+		}
+		if s.CompareAndSwap(b, replacement) {
+			m.size.AddHash(hash, -1)
+			return b.entries[i].value, true
+		}
+	}
+}
+
+// Contains reports whether key is present.
+func (m *Map) Contains(key string) bool {
+	_, ok := m.Get(key)
+	return ok
+}
+
+// Len returns the number of entries.
+func (m *Map) Len() int {
+	return int(m.size.Load())
+}
+
+// Clear removes all entries by installing a fresh table. Writes racing with
+// Clear may land in the discarded table and be lost.
+func (m *Map) Clear() {
+	m.table.Store(newTable(initialBuckets, true))
+	m.size.Reset()
+}
+
+// Range calls f for each entry until f returns false. Each bucket is read as
+// a snapshot; entries changed during the walk may or may not be seen.
+func (m *Map) Range(f func(key, value string) bool) {
+	t := m.table.Load()
+	next := t.next.Load()
+	if next != nil {
+		for j := range next.buckets {
+			m.migrate(t, next, uint64(j))
+		}
+		t = next
+	}
+	for i := range t.buckets {
+		for _, e := range t.buckets[i].Load().entries {
+			if !f(e.key, e.value) {
+				return
+			}
+		}
+	}
+}
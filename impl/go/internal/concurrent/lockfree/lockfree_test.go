@@ -0,0 +1,98 @@
+package lockfree
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestResizeMigratesEverything(t *testing.T) {
+	m := New()
+	const n = 10000
+	for i := 0; i < n; i++ {
+		m.Insert(fmt.Sprintf("k%d", i), fmt.Sprintf("v%d", i))
+	}
+	tab := m.table.Load()
+	if tab.next.Load() != nil {
+		t.Fatalf("expected resize to have completed")
+	}
+	if len(tab.buckets) < n/maxLoad {
+		t.Errorf("expected at least %d buckets, got %d", n/maxLoad, len(tab.buckets))
+	}
+	for i := 0; i < n; i++ {
+		if v, ok := m.Get(fmt.Sprintf("k%d", i)); !ok || v != fmt.Sprintf("v%d", i) {
+			t.Fatalf("k%d: got (%q, %v)", i, v, ok)
+		}
+	}
+	count := 0
+	m.Range(func(string, string) bool {
+		count++
+		return true
+	})
+	if count != n || m.Len() != n {
+		t.Errorf("expected %d entries, ranged %d with Len %d", n, count, m.Len())
+	}
+}
+
+func TestMigrationDuringResize(t *testing.T) {
+	// Start a resize by hand and leave it unfinished, so every operation has
+	// to migrate the bucket it touches.
+	m := New()
+	for i := 0; i < 20; i++ {
+		m.Insert(fmt.Sprintf("k%d", i), "old")
+	}
+	t0 := m.table.Load()
+	next := newTable(2*len(t0.buckets), false)
+	t0.next.Store(next)
+
+	if v, ok := m.Get("k3"); !ok || v != "old" {
+		t.Fatalf("expected old value mid-resize, got (%q, %v)", v, ok)
+	}
+	m.Insert("k3", "new")
+	m.Remove("k4")
+	m.Insert("fresh", "x")
+
+	for j := range next.buckets {
+		m.migrate(t0, next, uint64(j))
+	}
+	if m.table.Load() != next {
+		t.Fatalf("expected next table to be promoted once fully migrated")
+	}
+	for i := 0; i < len(t0.buckets); i++ {
+		if !t0.buckets[i].Load().frozen {
+			t.Fatalf("expected old bucket %d to be frozen", i)
+		}
+	}
+	if v, _ := m.Get("k3"); v != "new" {
+		t.Errorf("expected k3=new, got %q", v)
+	}
+	if m.Contains("k4") || !m.Contains("fresh") || m.Len() != 20 {
+		t.Errorf("unexpected contents after migration: len %d", m.Len())
+	}
+}
+
+func TestConcurrentGrowth(t *testing.T) {
+	m := New()
+	const goroutines, perG = 8, 5000
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < perG; i++ {
+				m.Insert(fmt.Sprintf("g%d_%d", g, i), "v")
+			}
+		}(g)
+	}
+	wg.Wait()
+	if m.Len() != goroutines*perG {
+		t.Fatalf("expected %d entries, got %d", goroutines*perG, m.Len())
+	}
+	for g := 0; g < goroutines; g++ {
+		for i := 0; i < perG; i++ {
+			if !m.Contains(fmt.Sprintf("g%d_%d", g, i)) {
+				t.Fatalf("missing g%d_%d", g, i)
+			}
+		}
+	}
+}
@@ -0,0 +1,12 @@
+//go:build go1.23
+
+package rcu
+
+import "iter"
+
+// All returns an iterator over the entries of the snapshot current when
+// iteration starts, in unspecified order. Like Range, it does not see writes
+// made during iteration.
+func (m *Map) All() iter.Seq2[string, string] {
+	return m.Range
+}
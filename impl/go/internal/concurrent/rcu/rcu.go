@@ -0,0 +1,165 @@
+// Package rcu provides a concurrent map for read-mostly data, in the style
+// of read-copy-update. The contents live in an immutable snapshot behind an
+// atomic pointer: a reader loads the pointer and looks the key up with no
+// lock, no atomic write and no retry, so reads scale with cores like reads of
+// a plain map. A writer copies the current snapshot, changes the copy and
+// publishes it with one pointer store; readers still holding the old
+// snapshot finish on it, and the garbage collector reclaims it after them.
+//
+// Every write copies the whole table, O(n) time and memory, so the map suits
+// configuration, routing tables and similar data read far more often than
+// written. WithWriteBatching lets concurrent writers share one copy.
+package rcu
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/dsa-lab/go/internal/hashmap"
+)
+
+// Map is a concurrent string map with lock-free reads and copy-on-write
+// updates. It is safe for concurrent use.
+type Map struct {
+	// snap is never modified after it is published.
+	snap atomic.Pointer[hashmap.HashMap]
+	// mu serializes writers.
+	mu sync.Mutex
+
+	batching bool
+	// pending holds the writes queued for the next copy when batching.
+	pendingMu sync.Mutex
+	pending   []*write
+}
+
+// write is one queued Insert or Remove. Its result fields and done are
+// written by whichever writer applies it, under mu.
+type write struct {
+	key, value string
+	remove     bool
+
+	old     string
+	existed bool
+	done    bool
+}
+
+// Option configures a Map.
+type Option func(*Map)
+
+// WithWriteBatching makes concurrent writers combine their writes: each
+// queues its write and then takes the writer lock, and whichever holds it
+// applies every write queued so far to one copy of the table. The others
+// find their write already published when they get the lock. Under a burst
+// of n concurrent writes this makes as few as one copy instead of n; a lone
+// writer pays a little extra for the queue.
+func WithWriteBatching() Option {
+	return func(m *Map) {
+		m.batching = true
+	}
+}
+
+// New creates an empty map.
+func New(opts ...Option) *Map {
+	m := &Map{}
+	for _, opt := range opts {
+		opt(m)
+	}
+	m.snap.Store(hashmap.MustNew())
+	return m
+}
+
+// Get returns the value for key.
+func (m *Map) Get(key string) (string, bool) {
+	return m.snap.Load().Get(key)
+}
+
+// Contains reports whether key is present.
+func (m *Map) Contains(key string) bool {
+	return m.snap.Load().Contains(key)
+}
+
+// Len returns the number of entries.
+func (m *Map) Len() int {
+	return m.snap.Load().Len()
+}
+
+// Range calls f for each entry of the current snapshot until f returns
+// false. Writes made during the call, by f or anyone else, are not seen.
+func (m *Map) Range(f func(key, value string) bool) {
+	m.snap.Load().Range(f)
+}
+
+// Insert inserts or replaces the value for key.
+// Returns the previous value and true if the key existed.
+func (m *Map) Insert(key, value string) (string, bool) {
+	return m.apply(&write{key: key, value: value})
+}
+
+// Remove removes key.
+// Returns the removed value and true if the key existed.
+func (m *Map) Remove(key string) (string, bool) {
+	return m.apply(&write{key: key, remove: true})
+}
+
+// Update applies f to a private copy of the map and publishes the result,
+// so readers see all of f's changes at once or none of them, for the price
+// of a single copy. f must not keep next or call m's write methods.
+func (m *Map) Update(f func(next *hashmap.HashMap)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	next := m.snap.Load().Clone()
+	f(next)
+	m.snap.Store(next)
+}
+
+// Clear removes all entries.
+func (m *Map) Clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.snap.Store(hashmap.MustNew())
+}
+
+func (m *Map) apply(w *write) (string, bool) {
+	if !m.batching {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		next := m.snap.Load().Clone()
+		w.do(next)
+		m.snap.Store(next)
+		return w.old, w.existed
+	}
+
+	m.pendingMu.Lock()
+	m.pending = append(m.pending, w)
+	m.pendingMu.Unlock()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !w.done {
+		m.pendingMu.Lock()
+		batch := m.pending
+		m.pending = nil
+		m.pendingMu.Unlock()
+
+		next := m.snap.Load().Clone()
+		for _, b := range batch {
+			b.do(next)
+		}
+		m.snap.Store(next)
+		// Mark the batch done only once it is visible, so no writer
+		// returns before its write can be read.
+		for _, b := range batch {
+			b.done = true
+		}
+	}
+	return w.old, w.existed
+}
+
+// do applies w to next and records the result.
+func (w *write) do(next *hashmap.HashMap) {
+	if w.remove {
+		w.old, w.existed = next.Remove(w.key)
+	} else {
+		w.old, w.existed = next.Insert(w.key, w.value)
+	}
+}
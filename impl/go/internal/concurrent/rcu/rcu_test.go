@@ -0,0 +1,99 @@
+package rcu
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/hashmap"
+)
+
+func TestConcurrentWriters(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithWriteBatching()}} {
+		m := New(opts...)
+		const writers, perWriter = 8, 300
+		var wg sync.WaitGroup
+		for g := 0; g < writers; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				for i := 0; i < perWriter; i++ {
+					key := fmt.Sprintf("%d/%d", g, i)
+					if old, existed := m.Insert(key, "a"); existed {
+						t.Errorf("expected %s to be new, got %q", key, old)
+					}
+					if old, existed := m.Insert(key, "b"); !existed || old != "a" {
+						t.Errorf("expected (a, true) for %s, got (%q, %v)", key, old, existed)
+					}
+					if v, ok := m.Get(key); !ok || v != "b" {
+						t.Errorf("expected to read back b for %s, got (%q, %v)", key, v, ok)
+					}
+					if i%3 == 0 {
+						if old, existed := m.Remove(key); !existed || old != "b" {
+							t.Errorf("expected to remove b for %s, got (%q, %v)", key, old, existed)
+						}
+					}
+				}
+			}(g)
+		}
+		wg.Wait()
+		if want := writers * (perWriter - perWriter/3); m.Len() != want {
+			t.Errorf("expected %d entries, got %d", want, m.Len())
+		}
+	}
+}
+
+// TestReadersSeeWholeUpdates keeps two keys equal through Update while
+// readers check that they never see one changed without the other.
+func TestReadersSeeWholeUpdates(t *testing.T) {
+	m := New()
+	m.Update(func(next *hashmap.HashMap) {
+		next.Insert("a", "0")
+		next.Insert("b", "0")
+	})
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				a, b := "", ""
+				m.Range(func(key, value string) bool {
+					if key == "a" {
+						a = value
+					} else {
+						b = value
+					}
+					return true
+				})
+				if a != b {
+					t.Errorf("expected a snapshot with a == b, got %q and %q", a, b)
+					return
+				}
+			}
+		}()
+	}
+	for i := 1; i <= 1000; i++ {
+		v := strconv.Itoa(i)
+		m.Update(func(next *hashmap.HashMap) {
+			next.Insert("a", v)
+			next.Insert("b", v)
+		})
+	}
+	close(done)
+	wg.Wait()
+	if v, _ := m.Get("a"); v != "1000" {
+		t.Errorf("expected 1000, got %q", v)
+	}
+	m.Clear()
+	if m.Len() != 0 || m.Contains("a") {
+		t.Error("expected an empty map after Clear")
+	}
+}
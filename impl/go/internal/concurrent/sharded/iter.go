@@ -0,0 +1,11 @@
+//go:build go1.23
+
+package sharded
+
+import "iter"
+
+// All returns an iterator over the key-value pairs, with the same
+// consistency as Range under concurrent updates.
+func (m *Map) All() iter.Seq2[string, string] {
+	return m.Range
+}
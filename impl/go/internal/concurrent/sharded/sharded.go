@@ -0,0 +1,164 @@
+// Package sharded provides a concurrent map that partitions keys across
+// independently locked shards, each an open-addressing hashmap.HashMap.
+// Operations on different shards never contend, so throughput scales with
+// the number of shards until one hot key or shard dominates.
+package sharded
+
+import (
+	"fmt"
+	"math/bits"
+	"sync"
+
+	"github.com/cespare/xxhash/v2"
+
+	"github.com/dsa-lab/go/internal/concurrent/counter"
+	"github.com/dsa-lab/go/internal/errs"
+	"github.com/dsa-lab/go/internal/hashmap"
+)
+
+const defaultShards = 32
+
+type shard struct {
+	mu sync.RWMutex
+	m  *hashmap.HashMap
+	_  [40]byte // keep neighbouring shard locks off the same cache line
+}
+
+// Map is a concurrent string map guarded by one read-write mutex per shard.
+// It is safe for concurrent use.
+type Map struct {
+	shards []shard
+	shift  uint
+	// size mirrors the total entry count so Len need not lock every shard.
+	size *counter.Counter
+
+	// optErr is set by an option given an invalid argument.
+	optErr error
+}
+
+// Option configures a Map.
+type Option func(*Map)
+
+// WithShards sets the number of shards, rounded up to a power of two. n must
+// be at least 1.
+func WithShards(n int) Option {
+	return func(m *Map) {
+		if n < 1 {
+			m.optErr = fmt.Errorf("sharded: WithShards(%d): %w", n, errs.ErrInvalidOption)
+			return
+		}
+		size := 1
+		for size < n {
+			size <<= 1
+		}
+		m.shards = make([]shard, size)
+	}
+}
+
+// New creates an empty sharded map. It returns an error matching
+// errs.ErrInvalidOption if WithShards is given fewer than one shard.
+func New(opts ...Option) (*Map, error) {
+	m := &Map{size: counter.MustNew()}
+	WithShards(defaultShards)(m)
+	for _, opt := range opts {
+		opt(m)
+	}
+	if m.optErr != nil {
+		return nil, m.optErr
+	}
+	for i := range m.shards {
+		m.shards[i].m = hashmap.MustNew()
+	}
+	m.shift = uint(64 - bits.TrailingZeros(uint(len(m.shards))))
+	return m, nil
+}
+
+// MustNew is like New but panics if an option is invalid.
+func MustNew(opts ...Option) *Map {
+	return errs.Must(New(opts...))
+}
+
+// shardFor picks a shard from the top bits of the key's hash; the shard's
+// own table indexes by the low bits, so the two choices stay independent.
+func (m *Map) shardFor(key string) (*shard, uint64) {
+	h := xxhash.Sum64String(key)
+	return &m.shards[h>>m.shift], h
+}
+
+// Insert inserts or replaces the value for key.
+// Returns the previous value and true if the key existed.
+func (m *Map) Insert(key, value string) (string, bool) {
+	s, h := m.shardFor(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old, existed := s.m.Insert(key, value)
+	if !existed {
+		m.size.AddHash(h, 1)
+	}
+	return old, existed
+}
+
+// Get returns the value for key.
+func (m *Map) Get(key string) (string, bool) {
+	s, _ := m.shardFor(key)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.Get(key)
+}
+
+// Remove removes key.
+// Returns the removed value and true if the key existed.
+func (m *Map) Remove(key string) (string, bool) {
+	s, h := m.shardFor(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old, existed := s.m.Remove(key)
+	if existed {
+		m.size.AddHash(h, -1)
+	}
+	return old, existed
+}
+
+// Contains reports whether key is present.
+func (m *Map) Contains(key string) bool {
+	s, _ := m.shardFor(key)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.Contains(key)
+}
+
+// Len returns the number of entries. It reads a striped counter without
+// taking any shard lock, so under concurrent writes the result is not a
+// consistent snapshot.
+func (m *Map) Len() int {
+	return int(m.size.Load())
+}
+
+// Clear removes all entries, one shard at a time.
+func (m *Map) Clear() {
+	for i := range m.shards {
+		s := &m.shards[i]
+		s.mu.Lock()
+		m.size.Add(-int64(s.m.Len()))
+		s.m.Clear()
+		s.mu.Unlock()
+	}
+}
+
+// Range calls f for each entry until f returns false. Each shard is read
+// under its lock, so f must not modify the map.
+func (m *Map) Range(f func(key, value string) bool) {
+	for i := range m.shards {
+		s := &m.shards[i]
+		s.mu.RLock()
+		stop := false
+		s.m.Range(func(k, v string) bool {
+			stop = !f(k, v)
+			return !stop
+		})
+		s.mu.RUnlock()
+		if stop {
+			return
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package sharded
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/errs"
+)
+
+func TestShardsOption(t *testing.T) {
+	for _, tc := range []struct{ requested, want int }{{1, 1}, {5, 8}, {64, 64}} {
+		m := MustNew(WithShards(tc.requested))
+		if len(m.shards) != tc.want {
+			t.Errorf("WithShards(%d): expected %d shards, got %d", tc.requested, tc.want, len(m.shards))
+		}
+		for i := 0; i < 100; i++ {
+			m.Insert(fmt.Sprintf("k%d", i), "v")
+		}
+		if m.Len() != 100 {
+			t.Errorf("expected 100 entries, got %d", m.Len())
+		}
+	}
+	if _, err := New(WithShards(0)); !errors.Is(err, errs.ErrInvalidOption) {
+		t.Errorf("WithShards(0): expected ErrInvalidOption, got %v", err)
+	}
+	if _, err := New(WithShards(0), WithShards(4)); !errors.Is(err, errs.ErrInvalidOption) {
+		t.Errorf("WithShards(0), WithShards(4): expected ErrInvalidOption, got %v", err)
+	}
+}
+
+func TestKeysSpreadAcrossShards(t *testing.T) {
+	m := MustNew(WithShards(16))
+	for i := 0; i < 16000; i++ {
+		m.Insert(fmt.Sprintf("key_%d", i), "v")
+	}
+	for i := range m.shards {
+		if n := m.shards[i].m.Len(); n < 700 || n > 1300 {
+			t.Errorf("shard %d holds %d keys, expected about 1000", i, n)
+		}
+	}
+}
+
+func TestRangeStopsEarly(t *testing.T) {
+	m := MustNew()
+	for i := 0; i < 100; i++ {
+		m.Insert(fmt.Sprintf("k%d", i), "v")
+	}
+	calls := 0
+	m.Range(func(string, string) bool {
+		calls++
+		return calls < 10
+	})
+	if calls != 10 {
+		t.Errorf("expected 10 calls, got %d", calls)
+	}
+	m.Clear()
+	if m.Len() != 0 {
+		t.Errorf("expected empty map after Clear, got %d", m.Len())
+	}
+}
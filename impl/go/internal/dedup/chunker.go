@@ -0,0 +1,122 @@
+// Package dedup splits byte streams into content-defined chunks and stores
+// each distinct chunk once. Chunk boundaries fall where a rolling hash of the
+// last few bytes matches a pattern, so they depend on content, not offset:
+// an insertion early in a file moves the boundaries near it and leaves the
+// rest where they were, and the chunks after it still deduplicate against
+// the previous version. Fixed-size blocks would all shift and none match.
+package dedup
+
+import (
+	"bufio"
+	"io"
+	"math/bits"
+
+	"github.com/dsa-lab/go/internal/errs"
+	"github.com/dsa-lab/go/internal/rolling"
+)
+
+// ErrSizes is returned for chunk sizes that are not 0 < min <= avg <= max with
+// avg a power of two.
+var ErrSizes = errs.New(errs.ErrInvalidOption, "dedup: invalid chunk sizes")
+
+// Default chunk sizes, and the width of the hashed window.
+const (
+	DefaultMinSize = 2 << 10
+	DefaultAvgSize = 8 << 10
+	DefaultMaxSize = 64 << 10
+	windowWidth    = 48
+)
+
+// DefaultSeed keys the boundary hash. Chunking is only useful if the same
+// content always splits the same way, so the seed is fixed rather than
+// random; an index must be filled with one seed throughout.
+const DefaultSeed = 0x6364632d64656475
+
+type config struct {
+	minSize, avgSize, maxSize int
+	seed                      uint64
+}
+
+// Option configures a Chunker or an Index.
+type Option func(*config)
+
+// WithSizes sets the minimum, average and maximum chunk sizes. avg must be a
+// power of two: a boundary is declared where the low log2(avg) bits of the
+// hash are all set, which happens once per avg bytes on average.
+func WithSizes(minSize, avgSize, maxSize int) Option {
+	return func(c *config) {
+		c.minSize, c.avgSize, c.maxSize = minSize, avgSize, maxSize
+	}
+}
+
+// WithSeed replaces DefaultSeed.
+func WithSeed(seed uint64) Option {
+	return func(c *config) {
+		c.seed = seed
+	}
+}
+
+func newConfig(opts []Option) (config, error) {
+	c := config{minSize: DefaultMinSize, avgSize: DefaultAvgSize, maxSize: DefaultMaxSize, seed: DefaultSeed}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if c.minSize < 1 || c.minSize > c.avgSize || c.avgSize > c.maxSize || bits.OnesCount(uint(c.avgSize)) != 1 {
+		return config{}, ErrSizes
+	}
+	return c, nil
+}
+
+// Chunker reads a stream and returns it in content-defined chunks.
+type Chunker struct {
+	r    *bufio.Reader
+	cfg  config
+	mask uint64
+	hash *rolling.Cyclic
+	buf  []byte
+	err  error
+}
+
+// NewChunker creates a chunker reading from r.
+func NewChunker(r io.Reader, opts ...Option) (*Chunker, error) {
+	cfg, err := newConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Chunker{
+		r:    bufio.NewReader(r),
+		cfg:  cfg,
+		mask: uint64(cfg.avgSize - 1),
+		hash: rolling.MustNewCyclic(windowWidth, cfg.seed),
+		buf:  make([]byte, 0, cfg.maxSize),
+	}, nil
+}
+
+// Next returns the next chunk, or io.EOF after the last. The chunk is only
+// valid until the following call. Chunks are at least the minimum size,
+// except the last, and at most the maximum.
+func (c *Chunker) Next() ([]byte, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	c.buf = c.buf[:0]
+	c.hash.Reset()
+	for len(c.buf) < c.cfg.maxSize {
+		b, err := c.r.ReadByte()
+		if err != nil {
+			c.err = err
+			if len(c.buf) > 0 && err == io.EOF {
+				return c.buf, nil
+			}
+			return nil, err
+		}
+		c.buf = append(c.buf, b)
+		// The hash restarts with each chunk, and the minimum size exceeds
+		// the window in any sensible configuration, so a boundary depends
+		// only on the window's bytes.
+		if h := c.hash.Roll(b); len(c.buf) >= c.cfg.minSize && h&c.mask == c.mask {
+			break
+		}
+	}
+	return c.buf, nil
+}
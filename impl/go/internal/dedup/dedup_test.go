@@ -0,0 +1,177 @@
+package dedup
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+// syntheticFile returns size bytes of text-like data: random words from a
+// small vocabulary, so it has no long exact repeats of its own.
+func syntheticFile(rng *rand.Rand, size int) []byte {
+	words := []string{"alpha", "beta", "gamma", "delta", "epsilon", "zeta", "eta", "theta", "iota", "kappa", "\n"}
+	var b bytes.Buffer
+	for b.Len() < size {
+		b.WriteString(words[rng.Intn(len(words))])
+		b.WriteByte(' ')
+	}
+	return b.Bytes()[:size]
+}
+
+// edit returns a copy of data with n small insertions and deletions at
+// random offsets.
+func edit(rng *rand.Rand, data []byte, n int) []byte {
+	out := bytes.Clone(data)
+	for i := 0; i < n; i++ {
+		at := rng.Intn(len(out))
+		if rng.Intn(2) == 0 {
+			out = append(out[:at], append([]byte("<edit>"), out[at:]...)...)
+		} else {
+			out = append(out[:at], out[min(at+10, len(out)):]...)
+		}
+	}
+	return out
+}
+
+func chunks(t *testing.T, data []byte, opts ...Option) [][]byte {
+	t.Helper()
+	c, err := NewChunker(bytes.NewReader(data), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out [][]byte
+	for {
+		chunk, err := c.Next()
+		if err == io.EOF {
+			return out
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, bytes.Clone(chunk))
+	}
+}
+
+func TestChunkSizes(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	data := syntheticFile(rng, 4<<20)
+	cs := chunks(t, data)
+	if got := bytes.Join(cs, nil); !bytes.Equal(got, data) {
+		t.Fatal("expected chunks to concatenate to the input")
+	}
+	for i, c := range cs {
+		if len(c) > DefaultMaxSize || (len(c) < DefaultMinSize && i != len(cs)-1) {
+			t.Fatalf("chunk %d: expected size in [%d, %d], got %d", i, DefaultMinSize, DefaultMaxSize, len(c))
+		}
+	}
+	// Boundaries are sought only after the minimum, so the mean is about
+	// min + avg.
+	mean := len(data) / len(cs)
+	if mean < DefaultAvgSize || mean > DefaultMinSize+2*DefaultAvgSize {
+		t.Errorf("expected a mean chunk size near %d, got %d", DefaultMinSize+DefaultAvgSize, mean)
+	}
+}
+
+func TestChunkerRejectsBadSizes(t *testing.T) {
+	for _, s := range [][3]int{{0, 8, 16}, {16, 8, 32}, {4, 12, 32}, {4, 16, 8}} {
+		if _, err := NewChunker(bytes.NewReader(nil), WithSizes(s[0], s[1], s[2])); !errors.Is(err, ErrSizes) {
+			t.Errorf("sizes %v: expected ErrSizes, got %v", s, err)
+		}
+	}
+}
+
+func TestEmptyInput(t *testing.T) {
+	if cs := chunks(t, nil); len(cs) != 0 {
+		t.Errorf("expected no chunks, got %d", len(cs))
+	}
+}
+
+// TestDedupRatioAfterEdits stores a file and then an edited copy. Content-
+// defined chunks survive the shifts caused by insertions and deletions, so
+// the second version should cost little; fixed-size blocks of the same
+// average size lose almost everything after the first edit.
+func TestDedupRatioAfterEdits(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	v1 := syntheticFile(rng, 2<<20)
+	const edits = 20
+	v2 := edit(rng, v1, edits)
+
+	x, err := NewIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r1, err := x.Add(bytes.NewReader(v1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := x.Stats()
+	r2, err := x.Add(bytes.NewReader(v2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	after := x.Stats()
+	added := after.StoredBytes - before.StoredBytes
+	// An edit changes the chunk it falls in and sometimes a neighbour.
+	const block = DefaultMinSize + DefaultAvgSize
+	if limit := int64(2 * edits * block); added > limit {
+		t.Errorf("expected the edited copy to add under %d bytes, added %d", limit, added)
+	}
+	if r := after.Ratio(); r < 1.5 {
+		t.Errorf("expected a dedup ratio above 1.5, got %.2f", r)
+	}
+
+	seen := make(map[[sha256.Size]byte]bool)
+	for i := 0; i < len(v1); i += block {
+		seen[sha256.Sum256(v1[i:min(i+block, len(v1))])] = true
+	}
+	fixedAdded := 0
+	for i := 0; i < len(v2); i += block {
+		if !seen[sha256.Sum256(v2[i:min(i+block, len(v2))])] {
+			fixedAdded += min(block, len(v2)-i)
+		}
+	}
+	if int64(fixedAdded) < 4*added {
+		t.Errorf("expected fixed-size blocks to store far more than %d bytes, stored %d", added, fixedAdded)
+	}
+
+	for i, want := range [][]byte{v1, v2} {
+		var out bytes.Buffer
+		if err := x.Restore([]Recipe{r1, r2}[i], &out); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(out.Bytes(), want) {
+			t.Errorf("version %d: expected the restored stream to equal the input", i+1)
+		}
+	}
+}
+
+func TestIndexCountsReferences(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	data := syntheticFile(rng, 256<<10)
+	x, err := NewIndex(WithSizes(512, 2048, 8192))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var recipe Recipe
+	for i := 0; i < 3; i++ {
+		if recipe, err = x.Add(bytes.NewReader(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := x.Stats()
+	if s.UniqueChunks != len(recipe) || s.Chunks != 3*len(recipe) {
+		t.Errorf("expected %d unique of %d chunks, got %d of %d", len(recipe), 3*len(recipe), s.UniqueChunks, s.Chunks)
+	}
+	if s.StoredBytes != int64(len(data)) || s.LogicalBytes != 3*int64(len(data)) {
+		t.Errorf("expected %d stored of %d logical bytes, got %d of %d", len(data), 3*len(data), s.StoredBytes, s.LogicalBytes)
+	}
+	if got := x.Refs(recipe[0]); got < 3 {
+		t.Errorf("expected the first chunk referenced at least 3 times, got %d", got)
+	}
+	if err := x.Restore(Recipe{{1, 2, 3}}, io.Discard); !errors.Is(err, ErrUnknownChunk) {
+		t.Errorf("expected ErrUnknownChunk, got %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+package dedup
+
+import (
+	"crypto/sha256"
+	"errors"
+	"io"
+	"slices"
+	"strconv"
+
+	"github.com/dsa-lab/go/internal/hashmap"
+)
+
+// ErrUnknownChunk is returned when restoring a recipe that names a chunk the
+// index does not hold.
+var ErrUnknownChunk = errors.New("dedup: unknown chunk")
+
+// Fingerprint identifies a chunk by its SHA-256. A cryptographic hash lets
+// the index treat equal fingerprints as equal chunks without comparing them.
+type Fingerprint [sha256.Size]byte
+
+// Recipe lists the chunks of one stream in order.
+type Recipe []Fingerprint
+
+// Stats reports how much an index has saved.
+type Stats struct {
+	// LogicalBytes is the total size of every stream added.
+	LogicalBytes int64
+	// StoredBytes is the total size of the distinct chunks.
+	StoredBytes int64
+	// Chunks counts chunk references across all recipes.
+	Chunks int
+	// UniqueChunks counts distinct chunks.
+	UniqueChunks int
+}
+
+// Ratio returns logical over stored bytes: 2 means half the input was
+// duplicate.
+func (s Stats) Ratio() float64 {
+	if s.StoredBytes == 0 {
+		return 1
+	}
+	return float64(s.LogicalBytes) / float64(s.StoredBytes)
+}
+
+// Index is an in-memory deduplicating chunk store. The hashmap maps each
+// fingerprint to a dense slot in the chunk and reference-count slices.
+type Index struct {
+	opts   []Option
+	slots  *hashmap.HashMap
+	chunks [][]byte
+	refs   []int
+	stats  Stats
+}
+
+// NewIndex creates an empty index whose streams are chunked with opts.
+func NewIndex(opts ...Option) (*Index, error) {
+	if _, err := newConfig(opts); err != nil {
+		return nil, err
+	}
+	return &Index{opts: opts, slots: hashmap.MustNew()}, nil
+}
+
+// Add chunks r, stores the chunks not already present, and returns the
+// stream's recipe.
+func (x *Index) Add(r io.Reader) (Recipe, error) {
+	c, err := NewChunker(r, x.opts...)
+	if err != nil {
+		return nil, err
+	}
+	var recipe Recipe
+	for {
+		chunk, err := c.Next()
+		if err == io.EOF {
+			return recipe, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		fp := Fingerprint(sha256.Sum256(chunk))
+		recipe = append(recipe, fp)
+		x.stats.LogicalBytes += int64(len(chunk))
+		x.stats.Chunks++
+		if slot, found := x.lookup(fp); found {
+			x.refs[slot]++
+			continue
+		}
+		x.slots.Insert(string(fp[:]), strconv.Itoa(len(x.chunks)))
+		x.chunks = append(x.chunks, slices.Clone(chunk))
+		x.refs = append(x.refs, 1)
+		x.stats.StoredBytes += int64(len(chunk))
+		x.stats.UniqueChunks++
+	}
+}
+
+func (x *Index) lookup(fp Fingerprint) (int, bool) {
+	v, found := x.slots.Get(string(fp[:]))
+	if !found {
+		return 0, false
+	}
+	slot, _ := strconv.Atoi(v)
+	return slot, true
+}
+
+// Restore writes the stream described by recipe to w.
+func (x *Index) Restore(recipe Recipe, w io.Writer) error {
+	for _, fp := range recipe {
+		slot, found := x.lookup(fp)
+		if !found {
+			return ErrUnknownChunk
+		}
+		if _, err := w.Write(x.chunks[slot]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Refs returns how many times the chunk with fingerprint fp has been added.
+func (x *Index) Refs(fp Fingerprint) int {
+	if slot, found := x.lookup(fp); found {
+		return x.refs[slot]
+	}
+	return 0
+}
+
+// Stats returns the index's size counters.
+func (x *Index) Stats() Stats {
+	return x.stats
+}
@@ -0,0 +1,535 @@
+// Package diskhash provides a persistent hash index stored in fixed-size pages
+// in a file, the external-memory counterpart of the in-memory hashmap.
+//
+// Keys hash to a primary bucket page; when a bucket page fills, records spill
+// into a chain of overflow pages. Once overflow pages make up a quarter of the
+// bucket count, the index doubles its bucket count, splitting every chain in
+// two. Every update is first written to a write-ahead log (package wal) as
+// full page images and only then applied to the data file, so a crash at any
+// point recovers to the state after the last logged operation.
+package diskhash
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/cespare/xxhash/v2"
+
+	"github.com/dsa-lab/go/internal/errs"
+	"github.com/dsa-lab/go/internal/vfs"
+	"github.com/dsa-lab/go/internal/wal"
+)
+
+const (
+	defaultBuckets    = 64
+	defaultCheckpoint = 4 << 20
+)
+
+var (
+	// ErrTooLarge is returned for records longer than MaxRecordSize.
+	ErrTooLarge = errs.New(errs.ErrKeyTooLarge, "diskhash: record too large for a page")
+	// ErrCorrupt is returned when the data file cannot be decoded.
+	ErrCorrupt = errs.New(errs.ErrCorruptSnapshot, "diskhash: corrupt file")
+	// ErrClosed is returned by operations on a closed index.
+	ErrClosed = errors.New("diskhash: index is closed")
+)
+
+// DiskHash is a hash index from string keys to string values persisted in a
+// file. It is not safe for concurrent use.
+type DiskHash struct {
+	fs   vfs.FS
+	path string
+	data vfs.File
+	log  *wal.Log
+	hdr  header
+	size int
+
+	buckets    uint32
+	syncWrites bool
+	checkpoint int64
+	buf        []byte
+
+	// optErr is set by an option given an invalid argument.
+	optErr error
+}
+
+// Option configures a DiskHash when it is opened.
+type Option func(*DiskHash)
+
+// WithBuckets sets the initial number of bucket pages for a new index, at
+// least 1. It has no effect when opening an existing file.
+func WithBuckets(n int) Option {
+	return func(d *DiskHash) {
+		if n < 1 || n > math.MaxUint32 {
+			d.optErr = fmt.Errorf("diskhash: WithBuckets(%d): %w", n, errs.ErrInvalidOption)
+			return
+		}
+		d.buckets = uint32(n)
+	}
+}
+
+// WithSyncWrites syncs the log after every update, so acknowledged writes
+// survive an operating system crash and not only a process crash. This is
+// much slower and off by default.
+func WithSyncWrites() Option {
+	return func(d *DiskHash) {
+		d.syncWrites = true
+	}
+}
+
+// WithFS stores the index and its log through fsys instead of the operating
+// system's file system.
+func WithFS(fsys vfs.FS) Option {
+	return func(d *DiskHash) {
+		d.fs = fsys
+	}
+}
+
+// WithCheckpointSize sets how large the log may grow, in bytes, before the
+// data file is synced and the log truncated. It must be positive.
+func WithCheckpointSize(bytes int64) Option {
+	return func(d *DiskHash) {
+		if bytes < 1 {
+			d.optErr = fmt.Errorf("diskhash: WithCheckpointSize(%d): %w", bytes, errs.ErrInvalidOption)
+			return
+		}
+		d.checkpoint = bytes
+	}
+}
+
+// Open opens the index stored at path, creating it if it does not exist. The
+// log lives next to it in the directory path + ".wal". Any updates left in the
+// log by a crash are applied before Open returns. An invalid option makes Open
+// fail with an error matching errs.ErrInvalidOption before touching the file.
+func Open(path string, opts ...Option) (*DiskHash, error) {
+	d := &DiskHash{
+		fs:         vfs.OS,
+		path:       path,
+		buckets:    defaultBuckets,
+		checkpoint: defaultCheckpoint,
+		buf:        make([]byte, pageSize),
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	if d.optErr != nil {
+		return nil, d.optErr
+	}
+
+	// A leftover from a split interrupted before its rename is never live.
+	d.fs.Remove(path + ".grow")
+
+	var err error
+	if d.data, err = d.fs.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644); err != nil {
+		return nil, err
+	}
+	if d.log, err = wal.Open(path+".wal", d.logOptions()...); err != nil {
+		d.data.Close()
+		return nil, err
+	}
+	if err := d.recover(); err != nil {
+		d.data.Close()
+		d.log.Close()
+		return nil, err
+	}
+	return d, nil
+}
+
+func (d *DiskHash) recover() error {
+	info, err := d.data.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() == 0 && d.log.Size() == 0 {
+		return d.create()
+	}
+
+	it := d.log.Iterator()
+	defer it.Close()
+	for it.Next() {
+		images, err := decodeImages(it.Record())
+		if err != nil {
+			return err
+		}
+		for _, img := range images {
+			if _, err := d.data.WriteAt(img.data, int64(img.id)*pageSize); err != nil {
+				return err
+			}
+		}
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+
+	if _, err := d.data.ReadAt(d.buf, 0); err != nil {
+		return fmt.Errorf("%w: reading header: %v", ErrCorrupt, err)
+	}
+	if d.hdr, err = decodeHeader(d.buf); err != nil {
+		return err
+	}
+
+	// Record counts live in the page headers; summing them is cheap compared
+	// with keeping a global count current on every update.
+	for id := uint32(1); id < d.hdr.pages; id++ {
+		p, err := d.readPage(id)
+		if err != nil {
+			return err
+		}
+		d.size += len(p.records)
+	}
+	return d.checkpointNow()
+}
+
+func (d *DiskHash) create() error {
+	d.hdr = header{buckets: d.buckets, pages: 1 + d.buckets}
+	if err := d.data.Truncate(int64(d.hdr.pages) * pageSize); err != nil {
+		return err
+	}
+	d.hdr.encode(d.buf)
+	if _, err := d.data.WriteAt(d.buf, 0); err != nil {
+		return err
+	}
+	if err := d.data.Sync(); err != nil {
+		return err
+	}
+	// Updates logged from now on are replayed onto this file, so its name
+	// must be durable before the first of them is.
+	return d.fs.SyncDir(filepath.Dir(d.path))
+}
+
+// Len returns the number of records in the index.
+func (d *DiskHash) Len() int {
+	return d.size
+}
+
+// Stats describes the file layout.
+type Stats struct {
+	Len           int
+	Buckets       int
+	Pages         int
+	OverflowPages int
+	// LogBytes is the current size of the redo log.
+	LogBytes int64
+}
+
+// Stats returns the current layout statistics.
+func (d *DiskHash) Stats() Stats {
+	return Stats{
+		Len:           d.size,
+		Buckets:       int(d.hdr.buckets),
+		Pages:         int(d.hdr.pages),
+		OverflowPages: int(d.hdr.pages - 1 - d.hdr.buckets),
+		LogBytes:      d.log.Size(),
+	}
+}
+
+func (d *DiskHash) bucketPage(key string) uint32 {
+	return 1 + uint32(xxhash.Sum64String(key)%uint64(d.hdr.buckets))
+}
+
+func (d *DiskHash) readPage(id uint32) (*page, error) {
+	if _, err := d.data.ReadAt(d.buf, int64(id)*pageSize); err != nil {
+		return nil, fmt.Errorf("reading page %d: %w", id, err)
+	}
+	return decodePage(id, d.buf)
+}
+
+// find walks the chain for key. It returns the pages read and, if the key was
+// found, the index of its page in the chain and of its record in that page.
+// When the key is absent the whole chain is returned.
+func (d *DiskHash) find(key string) (chain []*page, pageIndex, recordIndex int, err error) {
+	if d.data == nil {
+		return nil, -1, -1, ErrClosed
+	}
+	for id := d.bucketPage(key); id != 0; {
+		p, err := d.readPage(id)
+		if err != nil {
+			return nil, -1, -1, err
+		}
+		chain = append(chain, p)
+		if i := p.indexOf(key); i >= 0 {
+			return chain, len(chain) - 1, i, nil
+		}
+		id = p.overflow
+	}
+	return chain, -1, -1, nil
+}
+
+// commit logs the given pages (and the header, if headerDirty) as one record,
+// then writes them to the data file.
+func (d *DiskHash) commit(pages []*page, headerDirty bool) error {
+	images := make([]pageImage, 0, len(pages)+1)
+	for _, p := range pages {
+		buf := make([]byte, pageSize)
+		p.encode(buf)
+		images = append(images, pageImage{id: p.id, data: buf})
+	}
+	if headerDirty {
+		buf := make([]byte, pageSize)
+		d.hdr.encode(buf)
+		images = append(images, pageImage{id: 0, data: buf})
+	}
+
+	if err := d.log.Append(encodeImages(images)); err != nil {
+		return err
+	}
+	for _, img := range images {
+		if _, err := d.data.WriteAt(img.data, int64(img.id)*pageSize); err != nil {
+			return err
+		}
+	}
+
+	if d.log.Size() >= d.checkpoint {
+		return d.checkpointNow()
+	}
+	return nil
+}
+
+// checkpointNow makes the data file durable and empties the log.
+func (d *DiskHash) checkpointNow() error {
+	if err := d.data.Sync(); err != nil {
+		return err
+	}
+	return d.log.Reset()
+}
+
+// Insert inserts or replaces the value for key.
+// Returns the previous value and true if the key existed, empty string and false otherwise.
+func (d *DiskHash) Insert(key, value string) (string, bool, error) {
+	r := record{key: key, value: value}
+	if len(key)+len(value) > MaxRecordSize {
+		return "", false, ErrTooLarge
+	}
+	chain, pi, ri, err := d.find(key)
+	if err != nil {
+		return "", false, err
+	}
+
+	var old string
+	found := pi >= 0
+	var dirty []*page
+	if found {
+		p := chain[pi]
+		old = p.records[ri].value
+		p.records[ri].value = value
+		if p.size() <= pageSize {
+			return old, true, d.commit([]*page{p}, false)
+		}
+		// The longer value no longer fits; move the record elsewhere in the
+		// chain. find stopped at p, so load the pages after it first.
+		p.removeAt(ri)
+		dirty = append(dirty, p)
+		for id := p.overflow; id != 0; {
+			next, err := d.readPage(id)
+			if err != nil {
+				return "", false, err
+			}
+			chain = append(chain, next)
+			id = next.overflow
+		}
+	}
+
+	headerDirty := false
+	placed := false
+	for _, p := range chain {
+		if p.fits(r) {
+			p.records = append(p.records, r)
+			dirty = appendUnique(dirty, p)
+			placed = true
+			break
+		}
+	}
+	if !placed {
+		last := chain[len(chain)-1]
+		p := &page{id: d.hdr.pages, records: []record{r}}
+		d.hdr.pages++
+		last.overflow = p.id
+		dirty = append(appendUnique(dirty, last), p)
+		headerDirty = true
+	}
+
+	if err := d.commit(dirty, headerDirty); err != nil {
+		return "", false, err
+	}
+	if found {
+		return old, true, nil
+	}
+	d.size++
+	if d.hdr.pages-1-d.hdr.buckets > d.hdr.buckets/4 {
+		return "", false, d.split()
+	}
+	return "", false, nil
+}
+
+func appendUnique(pages []*page, p *page) []*page {
+	for _, q := range pages {
+		if q == p {
+			return pages
+		}
+	}
+	return append(pages, p)
+}
+
+// Get retrieves the value associated with the key.
+// Returns the value and true if found, empty string and false otherwise.
+func (d *DiskHash) Get(key string) (string, bool, error) {
+	chain, pi, ri, err := d.find(key)
+	if err != nil || pi < 0 {
+		return "", false, err
+	}
+	return chain[pi].records[ri].value, true, nil
+}
+
+// Remove removes the key from the index. Emptied overflow pages stay in their
+// chain and are reused by later inserts; a split compacts them away.
+// Returns the removed value and true if the key existed, empty string and false otherwise.
+func (d *DiskHash) Remove(key string) (string, bool, error) {
+	chain, pi, ri, err := d.find(key)
+	if err != nil || pi < 0 {
+		return "", false, err
+	}
+	p := chain[pi]
+	value := p.records[ri].value
+	p.removeAt(ri)
+	if err := d.commit([]*page{p}, false); err != nil {
+		return "", false, err
+	}
+	d.size--
+	return value, true, nil
+}
+
+// Range calls f for every record in bucket order. If f returns false,
+// iteration stops.
+func (d *DiskHash) Range(f func(key, value string) bool) error {
+	if d.data == nil {
+		return ErrClosed
+	}
+	for b := uint32(1); b <= d.hdr.buckets; b++ {
+		for id := b; id != 0; {
+			p, err := d.readPage(id)
+			if err != nil {
+				return err
+			}
+			for _, r := range p.records {
+				if !f(r.key, r.value) {
+					return nil
+				}
+			}
+			id = p.overflow
+		}
+	}
+	return nil
+}
+
+// split doubles the bucket count. Records in bucket b of an index with n
+// buckets move to bucket b or b+n of the new one, so each old chain is read
+// once and written out as two new chains. The new file is built beside the
+// old one and renamed over it, after a checkpoint has emptied the log.
+func (d *DiskHash) split() error {
+	if err := d.checkpointNow(); err != nil {
+		return err
+	}
+
+	tmpPath := d.path + ".grow"
+	tmp, err := vfs.Create(d.fs, tmpPath)
+	if err != nil {
+		return err
+	}
+	defer d.fs.Remove(tmpPath)
+
+	n := d.hdr.buckets
+	hdr := header{buckets: 2 * n, pages: 1 + 2*n}
+	buf := make([]byte, pageSize)
+	writeChain := func(first uint32, records []record) error {
+		p := &page{id: first}
+		for _, r := range records {
+			if !p.fits(r) {
+				p.overflow = hdr.pages
+				hdr.pages++
+				p.encode(buf)
+				if _, err := tmp.WriteAt(buf, int64(p.id)*pageSize); err != nil {
+					return err
+				}
+				p = &page{id: p.overflow}
+			}
+			p.records = append(p.records, r)
+		}
+		p.encode(buf)
+		_, err := tmp.WriteAt(buf, int64(p.id)*pageSize)
+		return err
+	}
+
+	for b := uint32(1); b <= n; b++ {
+		var low, high []record
+		for id := b; id != 0; {
+			p, err := d.readPage(id)
+			if err != nil {
+				tmp.Close()
+				return err
+			}
+			for _, r := range p.records {
+				if uint32(xxhash.Sum64String(r.key)%uint64(2*n)) < n {
+					low = append(low, r)
+				} else {
+					high = append(high, r)
+				}
+			}
+			id = p.overflow
+		}
+		if err := writeChain(b, low); err != nil {
+			tmp.Close()
+			return err
+		}
+		if err := writeChain(b+n, high); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+
+	hdr.encode(buf)
+	if _, err := tmp.WriteAt(buf, 0); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := d.fs.Rename(tmpPath, d.path); err != nil {
+		tmp.Close()
+		return err
+	}
+	d.data.Close()
+	d.data = tmp
+	d.hdr = hdr
+	// Pages logged after the split describe the new layout; replaying them
+	// onto the old file, should the rename be lost, would corrupt it.
+	return d.fs.SyncDir(filepath.Dir(d.path))
+}
+
+func (d *DiskHash) logOptions() []wal.Option {
+	opts := []wal.Option{wal.WithFS(d.fs)}
+	if d.syncWrites {
+		opts = append(opts, wal.WithSyncWrites())
+	}
+	return opts
+}
+
+// Close checkpoints the index and closes its files.
+func (d *DiskHash) Close() error {
+	if d.data == nil {
+		return ErrClosed
+	}
+	err := d.checkpointNow()
+	if cerr := d.data.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := d.log.Close(); err == nil {
+		err = cerr
+	}
+	d.data = nil
+	return err
+}
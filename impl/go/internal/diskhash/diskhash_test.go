@@ -0,0 +1,230 @@
+package diskhash
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/errs"
+	"github.com/dsa-lab/go/internal/vfs"
+	"github.com/dsa-lab/go/internal/vfs/faultfs"
+)
+
+func openTemp(t *testing.T, opts ...Option) (*DiskHash, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "index.db")
+	d, err := Open(path, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d, path
+}
+
+// openFault opens an index in fsys that syncs its log on every update.
+func openFault(t *testing.T, fsys vfs.FS, opts ...Option) *DiskHash {
+	t.Helper()
+	d, err := Open("index.db", append(opts, WithFS(fsys), WithSyncWrites())...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+// crashAfterLog arms fsys to cut the power once the next update is logged,
+// before it reaches the data file: logging it is one write and one sync.
+func crashAfterLog(fsys *faultfs.FS) {
+	fsys.CrashAfter(2)
+}
+
+func checkContents(t *testing.T, d *DiskHash, want map[string]string) {
+	t.Helper()
+	if d.Len() != len(want) {
+		t.Errorf("expected len %d, got %d", len(want), d.Len())
+	}
+	for k, v := range want {
+		got, found, err := d.Get(k)
+		if err != nil || !found || got != v {
+			t.Fatalf("get(%q): expected %q, got %q found=%v err=%v", k, v, got, found, err)
+		}
+	}
+	seen := 0
+	if err := d.Range(func(k, v string) bool {
+		if want[k] != v {
+			t.Errorf("range yielded %q=%q, want %q", k, v, want[k])
+		}
+		seen++
+		return true
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if seen != len(want) {
+		t.Errorf("range visited %d records, want %d", seen, len(want))
+	}
+}
+
+func TestOracle(t *testing.T) {
+	d, path := openTemp(t, WithBuckets(2), WithCheckpointSize(64<<10))
+	ref := make(map[string]string)
+	rng := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 20000; i++ {
+		key := fmt.Sprintf("key_%d", rng.Intn(3000))
+		switch rng.Intn(10) {
+		case 0, 1, 2, 3, 4:
+			// Occasionally long values force records to move between pages.
+			value := fmt.Sprintf("v%d", i) + strings.Repeat("x", rng.Intn(4)*300)
+			old, found, err := d.Insert(key, value)
+			refOld, refFound := ref[key]
+			if err != nil || found != refFound || old != refOld {
+				t.Fatalf("op %d insert(%q): got (%q, %v, %v), want (%q, %v)", i, key, old, found, err, refOld, refFound)
+			}
+			ref[key] = value
+		case 5, 6:
+			old, found, err := d.Remove(key)
+			refOld, refFound := ref[key]
+			if err != nil || found != refFound || old != refOld {
+				t.Fatalf("op %d remove(%q): got (%q, %v, %v), want (%q, %v)", i, key, old, found, err, refOld, refFound)
+			}
+			delete(ref, key)
+		default:
+			got, found, err := d.Get(key)
+			refValue, refFound := ref[key]
+			if err != nil || found != refFound || got != refValue {
+				t.Fatalf("op %d get(%q): got (%q, %v, %v), want (%q, %v)", i, key, got, found, err, refValue, refFound)
+			}
+		}
+
+		if i%5000 == 4999 {
+			if err := d.Close(); err != nil {
+				t.Fatal(err)
+			}
+			var err error
+			if d, err = Open(path); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	checkContents(t, d, ref)
+	if st := d.Stats(); st.Buckets <= 2 {
+		t.Errorf("expected the index to have split, got %+v", st)
+	}
+	if err := d.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := d.Get("key_1"); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected ErrClosed after close, got %v", err)
+	}
+}
+
+func TestRecoverLoggedUpdate(t *testing.T) {
+	fsys := faultfs.New(1)
+	d := openFault(t, fsys, WithBuckets(1))
+	want := make(map[string]string)
+	// Enough records to need an overflow page, so the crashed insert spans
+	// several pages and the header.
+	for i := 0; i < 150; i++ {
+		key := fmt.Sprintf("key_%03d", i)
+		want[key] = strings.Repeat("v", 20)
+		if _, _, err := d.Insert(key, want[key]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pagesBefore := d.Stats().Pages
+
+	crashAfterLog(fsys)
+	if _, _, err := d.Insert("logged", strings.Repeat("z", 2000)); !errors.Is(err, faultfs.ErrCrashed) {
+		t.Fatalf("expected simulated crash, got %v", err)
+	}
+	want["logged"] = strings.Repeat("z", 2000)
+
+	d = openFault(t, fsys.Crash())
+	defer d.Close()
+	checkContents(t, d, want)
+	if st := d.Stats(); st.Pages <= pagesBefore || st.LogBytes != 0 {
+		t.Errorf("expected replayed allocation and an empty log, got %+v", st)
+	}
+}
+
+func TestTornLogTail(t *testing.T) {
+	for _, cut := range []int64{1, 100, pageSize} {
+		fsys := faultfs.New(cut)
+		d := openFault(t, fsys)
+		want := make(map[string]string)
+		for i := 0; i < 50; i++ {
+			key := fmt.Sprintf("key_%d", i)
+			want[key] = "before"
+			if _, _, err := d.Insert(key, "before"); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// The last update reaches the log but is torn before it completes, so
+		// neither the log nor the data file holds it.
+		crashAfterLog(fsys)
+		if _, _, err := d.Insert("key_0", "torn"); !errors.Is(err, faultfs.ErrCrashed) {
+			t.Fatalf("expected simulated crash, got %v", err)
+		}
+		after := fsys.Crash()
+		segments, _ := vfs.Glob(after, "index.db.wal", "*.wal")
+		f, err := after.OpenFile(segments[len(segments)-1], os.O_RDWR, 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		info, _ := f.Stat()
+		if err := f.Truncate(info.Size() - cut); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+
+		d = openFault(t, after)
+		checkContents(t, d, want)
+		d.Close()
+	}
+}
+
+func TestRecordSizeLimit(t *testing.T) {
+	d, _ := openTemp(t)
+	defer d.Close()
+
+	_, _, err := d.Insert("k", strings.Repeat("v", MaxRecordSize))
+	if !errors.Is(err, ErrTooLarge) || !errors.Is(err, errs.ErrKeyTooLarge) {
+		t.Errorf("expected ErrTooLarge, got %v", err)
+	}
+	value := strings.Repeat("v", MaxRecordSize-1)
+	if _, _, err := d.Insert("k", value); err != nil {
+		t.Fatalf("expected a full-page record to fit, got %v", err)
+	}
+	if got, _, _ := d.Get("k"); got != value {
+		t.Errorf("expected full-page value back, got %d bytes", len(got))
+	}
+}
+
+func TestCorruptHeader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index.db")
+	if err := os.WriteFile(path, make([]byte, pageSize), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(path); !errors.Is(err, ErrCorrupt) || !errors.Is(err, errs.ErrCorruptSnapshot) {
+		t.Errorf("expected ErrCorrupt, got %v", err)
+	}
+}
+
+func TestInvalidOptions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index.db")
+	for name, opt := range map[string]Option{
+		"WithBuckets(0)":        WithBuckets(0),
+		"WithCheckpointSize(0)": WithCheckpointSize(0),
+	} {
+		if _, err := Open(path, opt); !errors.Is(err, errs.ErrInvalidOption) {
+			t.Errorf("%s: expected ErrInvalidOption, got %v", name, err)
+		}
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected no file after a rejected Open, got %v", err)
+	}
+}
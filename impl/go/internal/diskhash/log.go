@@ -0,0 +1,45 @@
+package diskhash
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// pageImage is the full new contents of one page.
+type pageImage struct {
+	id   uint32
+	data []byte
+}
+
+// encodeImages packs every page touched by one operation into a single log
+// record, so an update spanning several pages is replayed all or nothing:
+//
+//	page count uint32 | (page id uint32 | page bytes)...
+func encodeImages(images []pageImage) []byte {
+	buf := make([]byte, 4, 4+len(images)*(4+pageSize))
+	binary.LittleEndian.PutUint32(buf, uint32(len(images)))
+	for _, img := range images {
+		buf = binary.LittleEndian.AppendUint32(buf, img.id)
+		buf = append(buf, img.data...)
+	}
+	return buf
+}
+
+func decodeImages(record []byte) ([]pageImage, error) {
+	if len(record) < 4 {
+		return nil, fmt.Errorf("%w: short log record", ErrCorrupt)
+	}
+	count := int(binary.LittleEndian.Uint32(record))
+	if len(record) != 4+count*(4+pageSize) {
+		return nil, fmt.Errorf("%w: log record of %d bytes for %d pages", ErrCorrupt, len(record), count)
+	}
+	images := make([]pageImage, count)
+	for i, off := 0, 4; i < count; i++ {
+		images[i] = pageImage{
+			id:   binary.LittleEndian.Uint32(record[off:]),
+			data: record[off+4 : off+4+pageSize],
+		}
+		off += 4 + pageSize
+	}
+	return images, nil
+}
@@ -0,0 +1,135 @@
+package diskhash
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Page layout. Every page, including the header, is pageSize bytes.
+//
+//	bucket page: count uint16 | unused uint16 | overflow uint32 | records...
+//	record:      key length uint16 | value length uint16 | key | value
+//	header page: magic [8]byte | page size uint32 | buckets uint32 | pages uint32
+//
+// Page 0 is the header, pages 1..buckets are the primary bucket pages and
+// overflow pages are appended after them. An overflow link of 0 ends a chain.
+const (
+	pageSize         = 4096
+	pageHeaderSize   = 8
+	recordHeaderSize = 4
+
+	// MaxRecordSize is the largest combined key and value length that fits in
+	// a single page.
+	MaxRecordSize = pageSize - pageHeaderSize - recordHeaderSize
+
+	magic = "DSAHASH1"
+)
+
+type record struct {
+	key   string
+	value string
+}
+
+func (r record) size() int {
+	return recordHeaderSize + len(r.key) + len(r.value)
+}
+
+type page struct {
+	id       uint32
+	overflow uint32
+	records  []record
+}
+
+// size returns the encoded length of the page's contents.
+func (p *page) size() int {
+	n := pageHeaderSize
+	for _, r := range p.records {
+		n += r.size()
+	}
+	return n
+}
+
+func (p *page) fits(r record) bool {
+	return p.size()+r.size() <= pageSize
+}
+
+func (p *page) indexOf(key string) int {
+	for i, r := range p.records {
+		if r.key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func (p *page) removeAt(i int) {
+	p.records = append(p.records[:i], p.records[i+1:]...)
+}
+
+func (p *page) encode(buf []byte) {
+	clear(buf)
+	binary.LittleEndian.PutUint16(buf[0:], uint16(len(p.records)))
+	binary.LittleEndian.PutUint32(buf[4:], p.overflow)
+	off := pageHeaderSize
+	for _, r := range p.records {
+		binary.LittleEndian.PutUint16(buf[off:], uint16(len(r.key)))
+		binary.LittleEndian.PutUint16(buf[off+2:], uint16(len(r.value)))
+		off += recordHeaderSize
+		off += copy(buf[off:], r.key)
+		off += copy(buf[off:], r.value)
+	}
+}
+
+func decodePage(id uint32, buf []byte) (*page, error) {
+	p := &page{id: id, overflow: binary.LittleEndian.Uint32(buf[4:])}
+	count := int(binary.LittleEndian.Uint16(buf[0:]))
+	p.records = make([]record, 0, count)
+	off := pageHeaderSize
+	for i := 0; i < count; i++ {
+		if off+recordHeaderSize > len(buf) {
+			return nil, fmt.Errorf("%w: page %d record %d header out of bounds", ErrCorrupt, id, i)
+		}
+		klen := int(binary.LittleEndian.Uint16(buf[off:]))
+		vlen := int(binary.LittleEndian.Uint16(buf[off+2:]))
+		off += recordHeaderSize
+		if off+klen+vlen > len(buf) {
+			return nil, fmt.Errorf("%w: page %d record %d out of bounds", ErrCorrupt, id, i)
+		}
+		key := string(buf[off : off+klen])
+		off += klen
+		value := string(buf[off : off+vlen])
+		off += vlen
+		p.records = append(p.records, record{key: key, value: value})
+	}
+	return p, nil
+}
+
+type header struct {
+	buckets uint32
+	pages   uint32
+}
+
+func (h header) encode(buf []byte) {
+	clear(buf)
+	copy(buf, magic)
+	binary.LittleEndian.PutUint32(buf[8:], pageSize)
+	binary.LittleEndian.PutUint32(buf[12:], h.buckets)
+	binary.LittleEndian.PutUint32(buf[16:], h.pages)
+}
+
+func decodeHeader(buf []byte) (header, error) {
+	if string(buf[:len(magic)]) != magic {
+		return header{}, fmt.Errorf("%w: bad magic", ErrCorrupt)
+	}
+	if size := binary.LittleEndian.Uint32(buf[8:]); size != pageSize {
+		return header{}, fmt.Errorf("%w: page size %d, want %d", ErrCorrupt, size, pageSize)
+	}
+	h := header{
+		buckets: binary.LittleEndian.Uint32(buf[12:]),
+		pages:   binary.LittleEndian.Uint32(buf[16:]),
+	}
+	if h.buckets == 0 || h.pages < 1+h.buckets {
+		return header{}, fmt.Errorf("%w: %d buckets in %d pages", ErrCorrupt, h.buckets, h.pages)
+	}
+	return h, nil
+}
@@ -0,0 +1,49 @@
+// Package dp collects classic dynamic programming algorithms, each in two
+// forms: a bottom-up version that fills a table in dependency order, and a
+// top-down version that recurses on the definition and memoizes subproblem
+// results in the lab hashmap. Both compute the same answers; the pair shows
+// what the memo table costs compared with an array indexed by state.
+package dp
+
+import (
+	"encoding/binary"
+	"strconv"
+
+	"github.com/dsa-lab/go/internal/hashmap"
+)
+
+// memo caches subproblem results in a hashmap. A state is a tuple of small
+// integers, encoded as concatenated varints to form the key; results are
+// stored in decimal, since the map holds strings.
+type memo struct {
+	table *hashmap.HashMap
+	buf   []byte
+}
+
+func newMemo() *memo {
+	return &memo{table: hashmap.MustNew()}
+}
+
+func (m *memo) key(state []int) string {
+	m.buf = m.buf[:0]
+	for _, s := range state {
+		m.buf = binary.AppendVarint(m.buf, int64(s))
+	}
+	return string(m.buf)
+}
+
+// get returns the cached result for state.
+func (m *memo) get(state ...int) (int, bool) {
+	v, found := m.table.Get(m.key(state))
+	if !found {
+		return 0, false
+	}
+	n, _ := strconv.Atoi(v)
+	return n, true
+}
+
+// put caches result for state and returns it.
+func (m *memo) put(result int, state ...int) int {
+	m.table.Insert(m.key(state), strconv.Itoa(result))
+	return result
+}
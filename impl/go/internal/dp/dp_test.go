@@ -0,0 +1,145 @@
+package dp
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+func randomString(rng *rand.Rand, n int, alphabet string) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = alphabet[rng.Intn(len(alphabet))]
+	}
+	return string(b)
+}
+
+func isSubsequence(s, of string) bool {
+	i := 0
+	for j := 0; i < len(s) && j < len(of); j++ {
+		if s[i] == of[j] {
+			i++
+		}
+	}
+	return i == len(s)
+}
+
+func TestEditDistanceKnown(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0}, {"", "abc", 3}, {"abc", "", 3}, {"kitten", "sitting", 3},
+		{"flaw", "lawn", 2}, {"intention", "execution", 5}, {"same", "same", 0},
+	}
+	for _, c := range cases {
+		if got := EditDistance(c.a, c.b); got != c.want {
+			t.Errorf("EditDistance(%q, %q): expected %d, got %d", c.a, c.b, c.want, got)
+		}
+		if got := EditDistanceMemo(c.a, c.b); got != c.want {
+			t.Errorf("EditDistanceMemo(%q, %q): expected %d, got %d", c.a, c.b, c.want, got)
+		}
+	}
+}
+
+func TestStringsTopDownMatchesBottomUp(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 200; trial++ {
+		a := randomString(rng, rng.Intn(30), "abcd")
+		b := randomString(rng, rng.Intn(30), "abcd")
+		if bu, td := EditDistance(a, b), EditDistanceMemo(a, b); bu != td {
+			t.Fatalf("edit distance of %q, %q: bottom-up %d, top-down %d", a, b, bu, td)
+		}
+		n := LCSLength(a, b)
+		if td := LCSLengthMemo(a, b); td != n {
+			t.Fatalf("LCS length of %q, %q: bottom-up %d, top-down %d", a, b, n, td)
+		}
+		s := LCS(a, b)
+		if len(s) != n || !isSubsequence(s, a) || !isSubsequence(s, b) {
+			t.Fatalf("LCS of %q, %q: expected a common subsequence of length %d, got %q", a, b, n, s)
+		}
+	}
+}
+
+// bruteKnapsack tries every subset of items.
+func bruteKnapsack(items []Item, capacity int) int {
+	best := 0
+	for mask := 0; mask < 1<<len(items); mask++ {
+		w, v := 0, 0
+		for i, it := range items {
+			if mask&(1<<i) != 0 {
+				w, v = w+it.Weight, v+it.Value
+			}
+		}
+		if w <= capacity {
+			best = max(best, v)
+		}
+	}
+	return best
+}
+
+func TestKnapsackAgainstBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	for trial := 0; trial < 200; trial++ {
+		items := make([]Item, rng.Intn(12))
+		for i := range items {
+			items[i] = Item{Weight: rng.Intn(20), Value: rng.Intn(50)}
+		}
+		capacity := rng.Intn(60)
+		want := bruteKnapsack(items, capacity)
+		bu, err := Knapsack(items, capacity)
+		if err != nil || bu != want {
+			t.Fatalf("Knapsack: expected %d, got %d (err %v)", want, bu, err)
+		}
+		td, err := KnapsackMemo(items, capacity)
+		if err != nil || td != want {
+			t.Fatalf("KnapsackMemo: expected %d, got %d (err %v)", want, td, err)
+		}
+	}
+}
+
+func TestKnapsackRejectsInvalidInput(t *testing.T) {
+	if _, err := Knapsack(nil, -1); !errors.Is(err, ErrInput) {
+		t.Errorf("expected ErrInput for negative capacity, got %v", err)
+	}
+	if _, err := KnapsackMemo([]Item{{Weight: -2, Value: 1}}, 5); !errors.Is(err, ErrInput) {
+		t.Errorf("expected ErrInput for negative weight, got %v", err)
+	}
+}
+
+func TestMatrixChainTextbook(t *testing.T) {
+	dims := []int{30, 35, 15, 5, 10, 20, 25}
+	cost, order, err := MatrixChain(dims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cost != 15125 {
+		t.Errorf("expected cost 15125, got %d", cost)
+	}
+	if order != "((A1(A2A3))((A4A5)A6))" {
+		t.Errorf("expected ((A1(A2A3))((A4A5)A6)), got %s", order)
+	}
+	if td, _ := MatrixChainMemo(dims); td != cost {
+		t.Errorf("expected top-down cost %d, got %d", cost, td)
+	}
+}
+
+func TestMatrixChainTopDownMatchesBottomUp(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	for trial := 0; trial < 100; trial++ {
+		dims := make([]int, 2+rng.Intn(12))
+		for i := range dims {
+			dims[i] = 1 + rng.Intn(40)
+		}
+		bu, _, err := MatrixChain(dims)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if td, _ := MatrixChainMemo(dims); td != bu {
+			t.Fatalf("dims %v: bottom-up %d, top-down %d", dims, bu, td)
+		}
+	}
+	if _, _, err := MatrixChain([]int{4}); !errors.Is(err, ErrInput) {
+		t.Errorf("expected ErrInput for a single dimension, got %v", err)
+	}
+}
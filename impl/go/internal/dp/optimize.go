@@ -0,0 +1,139 @@
+package dp
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ErrInput is returned for inputs an algorithm cannot interpret, such as
+// mismatched slice lengths or negative weights.
+var ErrInput = errors.New("dp: invalid input")
+
+// Item is a knapsack item.
+type Item struct {
+	Weight int
+	Value  int
+}
+
+func checkItems(items []Item, capacity int) error {
+	if capacity < 0 {
+		return ErrInput
+	}
+	for _, it := range items {
+		if it.Weight < 0 {
+			return ErrInput
+		}
+	}
+	return nil
+}
+
+// Knapsack returns the greatest total value of items, each used at most
+// once, whose weights sum to at most capacity. best[w] holds the best value
+// within weight w using the items seen so far; iterating w downwards keeps
+// each item from being counted twice. Time is O(len(items) * capacity).
+func Knapsack(items []Item, capacity int) (int, error) {
+	if err := checkItems(items, capacity); err != nil {
+		return 0, err
+	}
+	best := make([]int, capacity+1)
+	for _, it := range items {
+		for w := capacity; w >= it.Weight; w-- {
+			best[w] = max(best[w], best[w-it.Weight]+it.Value)
+		}
+	}
+	return best[capacity], nil
+}
+
+// KnapsackMemo computes Knapsack top-down, deciding item by item whether to
+// take it. Only the (item, remaining capacity) states reachable from the
+// full capacity are visited, which can be far fewer than the table holds
+// when weights are large.
+func KnapsackMemo(items []Item, capacity int) (int, error) {
+	if err := checkItems(items, capacity); err != nil {
+		return 0, err
+	}
+	m := newMemo()
+	var best func(i, room int) int
+	best = func(i, room int) int {
+		if i == len(items) {
+			return 0
+		}
+		if v, found := m.get(i, room); found {
+			return v
+		}
+		v := best(i+1, room)
+		if it := items[i]; it.Weight <= room {
+			v = max(v, best(i+1, room-it.Weight)+it.Value)
+		}
+		return m.put(v, i, room)
+	}
+	return best(0, capacity), nil
+}
+
+// MatrixChain returns the fewest scalar multiplications needed to multiply a
+// chain of matrices, where matrix i is dims[i] x dims[i+1], together with an
+// optimal parenthesization such as "((A1A2)A3)". It fills cost[i][j] for
+// chains of increasing length, trying every split point: O(n³) time.
+func MatrixChain(dims []int) (int, string, error) {
+	if len(dims) < 2 {
+		return 0, "", ErrInput
+	}
+	n := len(dims) - 1
+	cost := make([][]int, n)
+	split := make([][]int, n)
+	for i := range cost {
+		cost[i] = make([]int, n)
+		split[i] = make([]int, n)
+	}
+	for length := 2; length <= n; length++ {
+		for i := 0; i+length-1 < n; i++ {
+			j := i + length - 1
+			cost[i][j] = math.MaxInt
+			for k := i; k < j; k++ {
+				c := cost[i][k] + cost[k+1][j] + dims[i]*dims[k+1]*dims[j+1]
+				if c < cost[i][j] {
+					cost[i][j], split[i][j] = c, k
+				}
+			}
+		}
+	}
+	var b strings.Builder
+	var write func(i, j int)
+	write = func(i, j int) {
+		if i == j {
+			b.WriteString("A" + strconv.Itoa(i+1))
+			return
+		}
+		b.WriteByte('(')
+		write(i, split[i][j])
+		write(split[i][j]+1, j)
+		b.WriteByte(')')
+	}
+	write(0, n-1)
+	return cost[0][n-1], b.String(), nil
+}
+
+// MatrixChainMemo computes the cost of MatrixChain top-down.
+func MatrixChainMemo(dims []int) (int, error) {
+	if len(dims) < 2 {
+		return 0, ErrInput
+	}
+	m := newMemo()
+	var cost func(i, j int) int
+	cost = func(i, j int) int {
+		if i == j {
+			return 0
+		}
+		if c, found := m.get(i, j); found {
+			return c
+		}
+		best := math.MaxInt
+		for k := i; k < j; k++ {
+			best = min(best, cost(i, k)+cost(k+1, j)+dims[i]*dims[k+1]*dims[j+1])
+		}
+		return m.put(best, i, j)
+	}
+	return cost(0, len(dims)-2), nil
+}
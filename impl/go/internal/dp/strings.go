@@ -0,0 +1,118 @@
+package dp
+
+// EditDistance returns the Levenshtein distance between a and b: the fewest
+// single-byte insertions, deletions and substitutions turning a into b. It
+// keeps two rows of the (len(a)+1) x (len(b)+1) table, using O(len(b)) space.
+func EditDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// EditDistanceMemo computes EditDistance top-down, memoizing the distance
+// between each pair of prefixes.
+func EditDistanceMemo(a, b string) int {
+	m := newMemo()
+	var dist func(i, j int) int
+	dist = func(i, j int) int {
+		if i == 0 {
+			return j
+		}
+		if j == 0 {
+			return i
+		}
+		if d, found := m.get(i, j); found {
+			return d
+		}
+		if a[i-1] == b[j-1] {
+			return m.put(dist(i-1, j-1), i, j)
+		}
+		return m.put(1+min(dist(i-1, j), dist(i, j-1), dist(i-1, j-1)), i, j)
+	}
+	return dist(len(a), len(b))
+}
+
+// LCS returns a longest common subsequence of a and b. It fills the full
+// table of prefix LCS lengths, then walks back from the corner to recover
+// one subsequence.
+func LCS(a, b string) string {
+	n, m := len(a), len(b)
+	table := make([][]int, n+1)
+	for i := range table {
+		table[i] = make([]int, m+1)
+	}
+	for i := 1; i <= n; i++ {
+		for j := 1; j <= m; j++ {
+			if a[i-1] == b[j-1] {
+				table[i][j] = table[i-1][j-1] + 1
+			} else {
+				table[i][j] = max(table[i-1][j], table[i][j-1])
+			}
+		}
+	}
+	out := make([]byte, table[n][m])
+	for i, j, k := n, m, len(out); k > 0; {
+		switch {
+		case a[i-1] == b[j-1]:
+			k--
+			out[k] = a[i-1]
+			i, j = i-1, j-1
+		case table[i-1][j] >= table[i][j-1]:
+			i--
+		default:
+			j--
+		}
+	}
+	return string(out)
+}
+
+// LCSLength returns the length of a longest common subsequence of a and b,
+// in O(len(b)) space.
+func LCSLength(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			if a[i-1] == b[j-1] {
+				cur[j] = prev[j-1] + 1
+			} else {
+				cur[j] = max(prev[j], cur[j-1])
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// LCSLengthMemo computes LCSLength top-down.
+func LCSLengthMemo(a, b string) int {
+	m := newMemo()
+	var lcs func(i, j int) int
+	lcs = func(i, j int) int {
+		if i == 0 || j == 0 {
+			return 0
+		}
+		if n, found := m.get(i, j); found {
+			return n
+		}
+		if a[i-1] == b[j-1] {
+			return m.put(lcs(i-1, j-1)+1, i, j)
+		}
+		return m.put(max(lcs(i-1, j), lcs(i, j-1)), i, j)
+	}
+	return lcs(len(a), len(b))
+}
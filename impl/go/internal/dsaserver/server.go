@@ -0,0 +1,385 @@
+// Package dsaserver serves the registered Go map implementations over
+// HTTP/JSON for cross-language differential testing. The endpoints are
+// documented in cmd/dsaserver, which runs the server; a metrics exporter
+// built outside the core module follows the instances through Track and
+// mounts its endpoint with Handle.
+package dsaserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/dsa-lab/go/internal/hashmap"
+	"github.com/dsa-lab/go/internal/kv"
+	"github.com/dsa-lab/go/internal/workload"
+)
+
+// maxBody bounds request bodies; batches of a few hundred thousand operations
+// fit comfortably.
+const maxBody = 64 << 20
+
+type instance struct {
+	id   string
+	kind string
+	m    kv.Map
+}
+
+// Tracker is told about every instance as it is created and deleted, under
+// the server's lock, with the instance's name, "kind/id", and its map. A
+// Prometheus collector satisfies it.
+type Tracker interface {
+	Add(name string, s any) error
+	Remove(name string) bool
+}
+
+// Server is an http.Handler serving the endpoints described in the
+// cmd/dsaserver documentation. Requests are serialized by a single lock,
+// which keeps the unsynchronized lab maps safe and makes results
+// deterministic.
+type Server struct {
+	mu        sync.Mutex
+	nextID    int
+	instances map[string]*instance
+
+	trackers []Tracker
+	handlers map[string]http.Handler
+}
+
+// NewServer creates a server with no instances.
+func NewServer() *Server {
+	return &Server{instances: make(map[string]*instance), handlers: make(map[string]http.Handler)}
+}
+
+// Locker returns the lock serializing requests. Anything reading the
+// instances outside a request, such as a metrics scrape, must hold it.
+func (s *Server) Locker() sync.Locker {
+	return &s.mu
+}
+
+// Track registers t to follow the instances. It must be called before the
+// server handles its first request.
+func (s *Server) Track(t Tracker) {
+	s.trackers = append(s.trackers, t)
+}
+
+// Handle serves path with h instead of the JSON endpoints. The server's lock
+// is not held while h runs. It must be called before the server handles its
+// first request.
+func (s *Server) Handle(path string, h http.Handler) {
+	s.handlers[path] = h
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+type kindsResponse struct {
+	Kinds []string `json:"kinds"`
+}
+
+type createRequest struct {
+	Kind string `json:"kind"`
+}
+
+type instanceResponse struct {
+	ID   string `json:"id"`
+	Kind string `json:"kind"`
+	Len  int    `json:"len"`
+}
+
+type listResponse struct {
+	Instances []instanceResponse `json:"instances"`
+}
+
+type keyRequest struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type result struct {
+	Value string `json:"value"`
+	Found bool   `json:"found"`
+}
+
+type foundResponse struct {
+	Found bool `json:"found"`
+}
+
+type lenResponse struct {
+	Len int `json:"len"`
+}
+
+type opsRequest struct {
+	Operations []workload.Operation `json:"operations"`
+}
+
+type opsResponse struct {
+	Results []result `json:"results"`
+}
+
+type hashmapStats struct {
+	Capacity       int     `json:"capacity"`
+	Tombstones     int     `json:"tombstones"`
+	LoadFactor     float64 `json:"load_factor"`
+	AvgProbe       float64 `json:"avg_probe"`
+	MaxProbe       int     `json:"max_probe"`
+	Clusters       int     `json:"clusters"`
+	LongestCluster int     `json:"longest_cluster"`
+}
+
+type statsResponse struct {
+	Len     int           `json:"len"`
+	Hashmap *hashmapStats `json:"hashmap,omitempty"`
+}
+
+// httpError carries a status code alongside the message.
+type httpError struct {
+	status int
+	msg    string
+}
+
+func (e *httpError) Error() string {
+	return e.msg
+}
+
+func errorf(status int, format string, args ...any) error {
+	return &httpError{status: status, msg: fmt.Sprintf(format, args...)}
+}
+
+// ServeHTTP routes the request and writes its JSON response.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h, ok := s.handlers[r.URL.Path]; ok {
+		h.ServeHTTP(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	resp, err := s.route(r)
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		status := http.StatusBadRequest
+		var he *httpError
+		if errors.As(err, &he) {
+			status = he.status
+		}
+		w.WriteHeader(status)
+		resp = errorResponse{Error: err.Error()}
+	} else if r.Method == http.MethodPost && r.URL.Path == "/instances" {
+		w.WriteHeader(http.StatusCreated)
+	}
+	if resp == nil {
+		resp = struct{}{}
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (s *Server) route(r *http.Request) (any, error) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(parts) == 1 && parts[0] == "kinds":
+		if err := requireMethod(r, http.MethodGet); err != nil {
+			return nil, err
+		}
+		return s.kinds(), nil
+
+	case len(parts) == 1 && parts[0] == "instances":
+		switch r.Method {
+		case http.MethodGet:
+			return s.list(), nil
+		case http.MethodPost:
+			var req createRequest
+			if err := decode(r, &req); err != nil {
+				return nil, err
+			}
+			return s.create(req.Kind)
+		}
+		return nil, errorf(http.StatusMethodNotAllowed, "method %s not allowed", r.Method)
+
+	case len(parts) == 2 && parts[0] == "instances":
+		if err := requireMethod(r, http.MethodDelete); err != nil {
+			return nil, err
+		}
+		inst, err := s.lookup(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		delete(s.instances, inst.id)
+		for _, t := range s.trackers {
+			t.Remove(trackedName(inst))
+		}
+		return nil, nil
+
+	case len(parts) == 3 && parts[0] == "instances":
+		inst, err := s.lookup(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		return s.operate(inst, parts[2], r)
+	}
+	return nil, errorf(http.StatusNotFound, "no route for %s", r.URL.Path)
+}
+
+func (s *Server) kinds() kindsResponse {
+	var names []string
+	for _, impl := range kv.Implementations() {
+		names = append(names, impl.Name)
+	}
+	return kindsResponse{Kinds: names}
+}
+
+func (s *Server) list() listResponse {
+	resp := listResponse{Instances: []instanceResponse{}}
+	for _, inst := range s.instances {
+		resp.Instances = append(resp.Instances, describe(inst))
+	}
+	sort.Slice(resp.Instances, func(i, j int) bool {
+		a, _ := strconv.Atoi(resp.Instances[i].ID)
+		b, _ := strconv.Atoi(resp.Instances[j].ID)
+		return a < b
+	})
+	return resp
+}
+
+func (s *Server) create(kind string) (instanceResponse, error) {
+	impl, ok := kv.Lookup(kind)
+	if !ok {
+		return instanceResponse{}, errorf(http.StatusBadRequest, "unknown kind %q", kind)
+	}
+	s.nextID++
+	inst := &instance{id: strconv.Itoa(s.nextID), kind: kind, m: impl.New()}
+	for i, t := range s.trackers {
+		if err := t.Add(trackedName(inst), inst.m); err != nil {
+			for _, added := range s.trackers[:i] {
+				added.Remove(trackedName(inst))
+			}
+			return instanceResponse{}, err
+		}
+	}
+	s.instances[inst.id] = inst
+	return describe(inst), nil
+}
+
+// trackedName is the name trackers know an instance by.
+func trackedName(inst *instance) string {
+	return inst.kind + "/" + inst.id
+}
+
+func (s *Server) lookup(id string) (*instance, error) {
+	inst, ok := s.instances[id]
+	if !ok {
+		return nil, errorf(http.StatusNotFound, "no instance %q", id)
+	}
+	return inst, nil
+}
+
+func (s *Server) operate(inst *instance, op string, r *http.Request) (any, error) {
+	m := inst.m
+	switch op {
+	case "len", "stats":
+		if err := requireMethod(r, http.MethodGet); err != nil {
+			return nil, err
+		}
+		if op == "len" {
+			return lenResponse{Len: m.Len()}, nil
+		}
+		return stats(m), nil
+	}
+
+	if err := requireMethod(r, http.MethodPost); err != nil {
+		return nil, err
+	}
+	switch op {
+	case "clear":
+		m.Clear()
+		return nil, nil
+	case "ops":
+		var req opsRequest
+		if err := decode(r, &req); err != nil {
+			return nil, err
+		}
+		return apply(m, req.Operations)
+	}
+
+	var req keyRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	switch op {
+	case "insert":
+		value, found := m.Insert(req.Key, req.Value)
+		return result{Value: value, Found: found}, nil
+	case "get":
+		value, found := m.Get(req.Key)
+		return result{Value: value, Found: found}, nil
+	case "remove":
+		value, found := m.Remove(req.Key)
+		return result{Value: value, Found: found}, nil
+	case "contains":
+		return foundResponse{Found: m.Contains(req.Key)}, nil
+	}
+	return nil, errorf(http.StatusNotFound, "unknown operation %q", op)
+}
+
+// apply runs a batch of workload operations. The batch is validated before any
+// operation runs, so a malformed request leaves the instance untouched.
+func apply(m kv.Map, ops []workload.Operation) (opsResponse, error) {
+	for i, op := range ops {
+		switch op.Op {
+		case workload.OpInsert, workload.OpGet, workload.OpDelete:
+		default:
+			return opsResponse{}, errorf(http.StatusBadRequest, "operation %d: unknown op %q", i, op.Op)
+		}
+	}
+
+	results := make([]result, len(ops))
+	for i, op := range ops {
+		r, _ := workload.Apply(m, op)
+		results[i] = result{Value: r.Value, Found: r.Found}
+	}
+	return opsResponse{Results: results}, nil
+}
+
+func stats(m kv.Map) statsResponse {
+	resp := statsResponse{Len: m.Len()}
+	if h, ok := m.(*hashmap.HashMap); ok {
+		st := h.Stats()
+		resp.Hashmap = &hashmapStats{
+			Capacity:       st.Capacity,
+			Tombstones:     st.Tombstones,
+			LoadFactor:     st.LoadFactor,
+			AvgProbe:       st.AvgProbe,
+			MaxProbe:       st.MaxProbe,
+			Clusters:       st.Clusters,
+			LongestCluster: st.LongestCluster,
+		}
+	}
+	return resp
+}
+
+func describe(inst *instance) instanceResponse {
+	return instanceResponse{ID: inst.id, Kind: inst.kind, Len: inst.m.Len()}
+}
+
+func requireMethod(r *http.Request, method string) error {
+	if r.Method != method {
+		return errorf(http.StatusMethodNotAllowed, "method %s not allowed", r.Method)
+	}
+	return nil
+}
+
+func decode(r *http.Request, v any) error {
+	dec := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return errorf(http.StatusBadRequest, "invalid request body: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package dsaserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/kv"
+	"github.com/dsa-lab/go/internal/workload"
+)
+
+type client struct {
+	t   *testing.T
+	srv *httptest.Server
+}
+
+// do sends body as JSON and decodes the response into out, returning the
+// status code.
+func (c *client) do(method, path string, body, out any) int {
+	c.t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			c.t.Fatal(err)
+		}
+	}
+	req, err := http.NewRequest(method, c.srv.URL+path, &buf)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			c.t.Fatalf("%s %s: decoding response: %v", method, path, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func newClient(t *testing.T) *client {
+	srv := httptest.NewServer(NewServer())
+	t.Cleanup(srv.Close)
+	return &client{t: t, srv: srv}
+}
+
+func TestSingleOperations(t *testing.T) {
+	c := newClient(t)
+	for _, impl := range kv.Implementations() {
+		var inst instanceResponse
+		if status := c.do("POST", "/instances", createRequest{Kind: impl.Name}, &inst); status != http.StatusCreated {
+			t.Fatalf("create %s: expected 201, got %d", impl.Name, status)
+		}
+		base := "/instances/" + inst.ID
+
+		var res result
+		c.do("POST", base+"/insert", keyRequest{Key: "a", Value: "1"}, &res)
+		if res.Found {
+			t.Errorf("%s: expected fresh insert, got %+v", impl.Name, res)
+		}
+		c.do("POST", base+"/insert", keyRequest{Key: "a", Value: "2"}, &res)
+		if !res.Found || res.Value != "1" {
+			t.Errorf("%s: expected previous value 1, got %+v", impl.Name, res)
+		}
+		c.do("POST", base+"/get", keyRequest{Key: "a"}, &res)
+		if !res.Found || res.Value != "2" {
+			t.Errorf("%s: expected get to return 2, got %+v", impl.Name, res)
+		}
+
+		var found foundResponse
+		c.do("POST", base+"/contains", keyRequest{Key: "b"}, &found)
+		if found.Found {
+			t.Errorf("%s: expected b to be absent", impl.Name)
+		}
+
+		var n lenResponse
+		c.do("GET", base+"/len", nil, &n)
+		if n.Len != 1 {
+			t.Errorf("%s: expected len 1, got %d", impl.Name, n.Len)
+		}
+
+		c.do("POST", base+"/remove", keyRequest{Key: "a"}, &res)
+		if !res.Found || res.Value != "2" {
+			t.Errorf("%s: expected remove to return 2, got %+v", impl.Name, res)
+		}
+
+		var st statsResponse
+		c.do("GET", base+"/stats", nil, &st)
+		if st.Len != 0 || strings.HasPrefix(impl.Name, "hashmap") != (st.Hashmap != nil) {
+			t.Errorf("%s: unexpected stats %+v", impl.Name, st)
+		}
+		if status := c.do("DELETE", base, nil, nil); status != http.StatusOK {
+			t.Errorf("%s: expected delete to succeed, got %d", impl.Name, status)
+		}
+	}
+
+	var list listResponse
+	c.do("GET", "/instances", nil, &list)
+	if len(list.Instances) != 0 {
+		t.Errorf("expected no instances after deletes, got %+v", list.Instances)
+	}
+}
+
+func TestBatchMatchesBuiltin(t *testing.T) {
+	ops := []workload.Operation{
+		{Op: workload.OpInsert, Key: "k1", Value: "a"},
+		{Op: workload.OpInsert, Key: "k2", Value: "b"},
+		{Op: workload.OpGet, Key: "k1"},
+		{Op: workload.OpDelete, Key: "k1"},
+		{Op: workload.OpGet, Key: "k1"},
+		{Op: workload.OpInsert, Key: "k2", Value: "c"},
+		{Op: workload.OpDelete, Key: "missing"},
+	}
+	want, _ := apply(kv.NewBuiltin(), ops)
+
+	c := newClient(t)
+	var inst instanceResponse
+	c.do("POST", "/instances", createRequest{Kind: "hashmap"}, &inst)
+	var got opsResponse
+	if status := c.do("POST", "/instances/"+inst.ID+"/ops", opsRequest{Operations: ops}, &got); status != http.StatusOK {
+		t.Fatalf("expected 200, got %d", status)
+	}
+	if len(got.Results) != len(want.Results) {
+		t.Fatalf("expected %d results, got %d", len(want.Results), len(got.Results))
+	}
+	for i := range want.Results {
+		if got.Results[i] != want.Results[i] {
+			t.Errorf("op %d: expected %+v, got %+v", i, want.Results[i], got.Results[i])
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	c := newClient(t)
+	var inst instanceResponse
+	c.do("POST", "/instances", createRequest{Kind: "builtin"}, &inst)
+	base := "/instances/" + inst.ID
+
+	cases := []struct {
+		method, path string
+		body         any
+		status       int
+	}{
+		{"POST", "/instances", createRequest{Kind: "nosuchkind"}, http.StatusBadRequest},
+		{"POST", "/instances", map[string]string{"type": "hashmap"}, http.StatusBadRequest},
+		{"GET", "/instances/99/len", nil, http.StatusNotFound},
+		{"POST", base + "/frobnicate", keyRequest{}, http.StatusNotFound},
+		{"GET", base + "/get", nil, http.StatusMethodNotAllowed},
+		{"POST", base + "/ops", opsRequest{Operations: []workload.Operation{{Op: "insert", Key: "x"}, {Op: "upsert"}}}, http.StatusBadRequest},
+		{"GET", "/nowhere", nil, http.StatusNotFound},
+	}
+	for _, tc := range cases {
+		var resp errorResponse
+		if status := c.do(tc.method, tc.path, tc.body, &resp); status != tc.status || resp.Error == "" {
+			t.Errorf("%s %s: expected %d with an error, got %d %+v", tc.method, tc.path, tc.status, status, resp)
+		}
+	}
+
+	// The rejected batch must not have been partially applied.
+	var n lenResponse
+	c.do("GET", base+"/len", nil, &n)
+	if n.Len != 0 {
+		t.Errorf("expected rejected batch to leave instance empty, got len %d", n.Len)
+	}
+}
+
+// fakeTracker records the names of the instances it is told about.
+type fakeTracker struct {
+	names map[string]bool
+	fail  bool
+}
+
+func (f *fakeTracker) Add(name string, s any) error {
+	if f.fail {
+		return errors.New("tracker full")
+	}
+	f.names[name] = true
+	return nil
+}
+
+func (f *fakeTracker) Remove(name string) bool {
+	found := f.names[name]
+	delete(f.names, name)
+	return found
+}
+
+func TestTrackAndHandle(t *testing.T) {
+	s := NewServer()
+	tracker := &fakeTracker{names: map[string]bool{}}
+	s.Track(tracker)
+	s.Handle("/extra", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "extra")
+	}))
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+	c := &client{t: t, srv: srv}
+
+	var hm, sl instanceResponse
+	c.do("POST", "/instances", createRequest{Kind: "hashmap"}, &hm)
+	c.do("POST", "/instances", createRequest{Kind: "skiplist"}, &sl)
+	c.do("DELETE", "/instances/"+sl.ID, nil, nil)
+	if want := "hashmap/" + hm.ID; len(tracker.names) != 1 || !tracker.names[want] {
+		t.Errorf("expected the tracker to hold only %s, got %v", want, tracker.names)
+	}
+
+	tracker.fail = true
+	var failed errorResponse
+	if status := c.do("POST", "/instances", createRequest{Kind: "hashmap"}, &failed); status != http.StatusBadRequest {
+		t.Errorf("expected 400 when a tracker refuses an instance, got %d", status)
+	}
+	var list listResponse
+	if c.do("GET", "/instances", nil, &list); len(list.Instances) != 1 {
+		t.Errorf("expected the refused instance not to be created, got %v", list.Instances)
+	}
+
+	resp, err := http.Get(srv.URL + "/extra")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if body, _ := io.ReadAll(resp.Body); string(body) != "extra" {
+		t.Errorf("expected the extra handler's response, got %q", body)
+	}
+}
@@ -0,0 +1,50 @@
+// Package errs defines the error kinds shared by every structure in the lab.
+// Each package keeps its own errors, with messages naming the package and
+// the problem, but wraps one of the kinds below, so a caller can test what
+// went wrong with errors.Is without knowing which structure reported it:
+//
+//	if errors.Is(err, errs.ErrInvalidOption) { ... }
+//
+// Constructors that validate their arguments return an error of kind
+// ErrInvalidOption rather than adjusting a bad value, and each has a Must
+// variant, built on Must, for arguments known to be valid.
+package errs
+
+import "errors"
+
+// The error kinds.
+var (
+	// ErrCapacityExceeded reports that a structure is full and cannot grow.
+	ErrCapacityExceeded = errors.New("capacity exceeded")
+	// ErrInvalidOption reports a constructor argument or option outside its
+	// documented range.
+	ErrInvalidOption = errors.New("invalid option")
+	// ErrKeyTooLarge reports a key or value larger than a format can encode.
+	ErrKeyTooLarge = errors.New("key too large")
+	// ErrCorruptSnapshot reports persisted or serialized state that fails to
+	// decode or verify.
+	ErrCorruptSnapshot = errors.New("corrupt snapshot")
+)
+
+// kindError is an error with its own message that matches a kind.
+type kindError struct {
+	msg  string
+	kind error
+}
+
+func (e *kindError) Error() string { return e.msg }
+
+func (e *kindError) Unwrap() error { return e.kind }
+
+// New returns an error with message msg that errors.Is reports as kind.
+func New(kind error, msg string) error {
+	return &kindError{msg: msg, kind: kind}
+}
+
+// Must returns v, panicking if err is not nil.
+func Must[T any](v T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
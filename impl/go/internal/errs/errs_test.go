@@ -0,0 +1,36 @@
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestNewMatchesKind(t *testing.T) {
+	err := New(ErrCorruptSnapshot, "pkg: bad header")
+	if err.Error() != "pkg: bad header" {
+		t.Errorf("expected message %q, got %q", "pkg: bad header", err.Error())
+	}
+	if !errors.Is(err, ErrCorruptSnapshot) {
+		t.Error("expected the error to match ErrCorruptSnapshot")
+	}
+	if errors.Is(err, ErrInvalidOption) {
+		t.Error("expected the error not to match ErrInvalidOption")
+	}
+	wrapped := fmt.Errorf("reading page 3: %w", err)
+	if !errors.Is(wrapped, err) || !errors.Is(wrapped, ErrCorruptSnapshot) {
+		t.Error("expected a wrapped error to match both the error and its kind")
+	}
+}
+
+func TestMust(t *testing.T) {
+	if got := Must(7, nil); got != 7 {
+		t.Errorf("expected 7, got %d", got)
+	}
+	defer func() {
+		if r := recover(); r != ErrInvalidOption {
+			t.Errorf("expected a panic with ErrInvalidOption, got %v", r)
+		}
+	}()
+	Must(0, ErrInvalidOption)
+}
@@ -0,0 +1,6 @@
+//go:build dsa_experimental
+
+package features
+
+// Experimental reports whether the dsa_experimental build tag is set.
+const Experimental = true
@@ -0,0 +1,78 @@
+// Package features records which optional features and experimental variants
+// were compiled into the binary. Unstable structures and fast paths live in
+// files guarded by the dsa_experimental build tag and register themselves here
+// from init, so benchmark output and result files can state exactly which
+// variants produced them.
+//
+// Build with experimental code enabled:
+//
+//	go test -tags dsa_experimental ./...
+package features
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Tag is the build tag that enables experimental code.
+const Tag = "dsa_experimental"
+
+var (
+	mu         sync.Mutex
+	registered = make(map[string]bool)
+)
+
+// Register records that the named feature was compiled in. It is intended to
+// be called from init functions in build-tagged files.
+func Register(name string) {
+	mu.Lock()
+	defer mu.Unlock()
+	registered[name] = true
+}
+
+// Enabled reports whether the named feature was compiled in.
+func Enabled(name string) bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return registered[name]
+}
+
+// List returns the names of all compiled-in features in sorted order.
+func List() []string {
+	mu.Lock()
+	defer mu.Unlock()
+	names := make([]string, 0, len(registered))
+	for name := range registered {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// String returns the compiled-in features as a comma-separated list, or
+// "none" when only stable code was built.
+func String() string {
+	names := List()
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ",")
+}
+
+// ConfigLines returns benchfmt configuration lines ("key: value") describing
+// the build, suitable for printing ahead of benchmark results so benchstat
+// and the report tooling keep them with the numbers.
+func ConfigLines() []string {
+	return []string{
+		"dsa-experimental: " + boolString(Experimental),
+		"dsa-features: " + String(),
+	}
+}
+
+func boolString(b bool) string {
+	if b {
+		return "true"
+	}
+	return "false"
+}
@@ -0,0 +1,45 @@
+package features
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegister(t *testing.T) {
+	Register("test/zeta")
+	Register("test/alpha")
+
+	if !Enabled("test/alpha") || Enabled("test/missing") {
+		t.Error("enabled mismatch")
+	}
+
+	var got []string
+	for _, name := range List() {
+		if strings.HasPrefix(name, "test/") {
+			got = append(got, name)
+		}
+	}
+	if len(got) != 2 || got[0] != "test/alpha" || got[1] != "test/zeta" {
+		t.Errorf("expected sorted test features, got %v", got)
+	}
+	if !strings.Contains(String(), "test/alpha,") {
+		t.Errorf("unexpected feature string %q", String())
+	}
+}
+
+func TestConfigLines(t *testing.T) {
+	lines := ConfigLines()
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 config lines, got %d", len(lines))
+	}
+	want := "dsa-experimental: false"
+	if Experimental {
+		want = "dsa-experimental: true"
+	}
+	if lines[0] != want {
+		t.Errorf("expected %q, got %q", want, lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "dsa-features: ") {
+		t.Errorf("unexpected features line %q", lines[1])
+	}
+}
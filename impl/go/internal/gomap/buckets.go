@@ -0,0 +1,46 @@
+package gomap
+
+// Constants from runtime/map.go (Go 1.23 and earlier).
+const (
+	bucketCnt     = 8
+	loadFactorNum = 13
+	loadFactorDen = 2
+)
+
+// modelBuckets places hashes into 2^B buckets of eight slots, chaining
+// overflow buckets when a bucket fills. B is the smallest value that keeps the
+// average load at or below 6.5 entries per bucket, matching the final size of
+// a map grown by insertion.
+func modelBuckets(hashes []uint64) Stats {
+	n := len(hashes)
+	b := uint(0)
+	for n > bucketCnt && uint64(n) > loadFactorNum*((uint64(1)<<b)/loadFactorDen) {
+		b++
+	}
+
+	buckets := 1 << b
+	counts := make([]int, buckets)
+	totalProbe := 0
+	s := Stats{Layout: Buckets, Len: n, Tables: 1}
+
+	for _, h := range hashes {
+		bucket := h & uint64(buckets-1)
+		pos := counts[bucket]
+		counts[bucket]++
+
+		probe := pos/bucketCnt + 1
+		totalProbe += probe
+		if probe > s.MaxProbe {
+			s.MaxProbe = probe
+		}
+	}
+
+	for _, c := range counts {
+		if c > bucketCnt {
+			s.Overflow += (c - 1) / bucketCnt
+		}
+	}
+	s.Units = buckets + s.Overflow
+	s.Slots = s.Units * bucketCnt
+	return finish(s, totalProbe)
+}
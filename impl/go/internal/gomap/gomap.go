@@ -0,0 +1,79 @@
+// Package gomap models the internal layout of Go's builtin map so reports can
+// compare it structurally with the lab's maps. The runtime does not expose its
+// bucket or group occupancy, so the models replay the runtime's placement
+// rules over hash/maphash (the runtime's own hash function) for a set of keys.
+// Two layouts are modeled: the bucket-and-overflow design used up to Go 1.23
+// and the Swiss-table design used from Go 1.24.
+package gomap
+
+import (
+	"fmt"
+	"hash/maphash"
+	"runtime"
+)
+
+// Layout identifies a builtin map implementation.
+type Layout string
+
+const (
+	Buckets Layout = "buckets"
+	Swiss   Layout = "swiss"
+)
+
+// Stats summarizes a modeled builtin map layout.
+type Stats struct {
+	Layout Layout
+	Len    int
+	// Slots is the total number of key slots allocated.
+	Slots int
+	// LoadFactor is Len divided by Slots.
+	LoadFactor float64
+	// Units is the number of buckets (including overflow) or groups.
+	Units int
+	// Overflow is the number of overflow buckets; always zero for Swiss.
+	Overflow int
+	// AvgProbe is the mean number of buckets or groups a successful lookup visits.
+	AvgProbe float64
+	// MaxProbe is the most buckets or groups any successful lookup visits.
+	MaxProbe int
+	// Tables is the number of Swiss tables in the directory; one for Buckets.
+	Tables int
+}
+
+// RuntimeLayout returns the layout used by the running Go version.
+func RuntimeLayout() Layout {
+	var major, minor int
+	if _, err := fmt.Sscanf(runtime.Version(), "go%d.%d", &major, &minor); err != nil {
+		// Development builds ("devel ...") are assumed current.
+		return Swiss
+	}
+	if major == 1 && minor < 24 {
+		return Buckets
+	}
+	return Swiss
+}
+
+// Model computes the layout statistics for keys inserted in order into an
+// empty map with the given layout.
+func Model(layout Layout, keys []string) Stats {
+	seed := maphash.MakeSeed()
+	hashes := make([]uint64, len(keys))
+	for i, key := range keys {
+		hashes[i] = maphash.String(seed, key)
+	}
+
+	if layout == Buckets {
+		return modelBuckets(hashes)
+	}
+	return modelSwiss(hashes)
+}
+
+func finish(s Stats, totalProbe int) Stats {
+	if s.Slots > 0 {
+		s.LoadFactor = float64(s.Len) / float64(s.Slots)
+	}
+	if s.Len > 0 {
+		s.AvgProbe = float64(totalProbe) / float64(s.Len)
+	}
+	return s
+}
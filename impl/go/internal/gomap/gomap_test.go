@@ -0,0 +1,62 @@
+package gomap
+
+import (
+	"fmt"
+	"testing"
+)
+
+func keys(n int) []string {
+	out := make([]string, n)
+	for i := range out {
+		out[i] = fmt.Sprintf("key_%d", i)
+	}
+	return out
+}
+
+func TestBucketModel(t *testing.T) {
+	s := Model(Buckets, keys(1000))
+	// 1000 entries need 2^8 buckets to stay under 6.5 per bucket.
+	if s.Units-s.Overflow != 256 {
+		t.Errorf("expected 256 primary buckets, got %d", s.Units-s.Overflow)
+	}
+	if s.Len != 1000 || s.LoadFactor <= 0 || s.LoadFactor > 1 {
+		t.Errorf("unexpected stats: %+v", s)
+	}
+	if s.AvgProbe < 1 || s.MaxProbe < 1 {
+		t.Errorf("unexpected probe stats: %+v", s)
+	}
+
+	small := Model(Buckets, keys(8))
+	if small.Units != 1 || small.Overflow != 0 || small.MaxProbe != 1 {
+		t.Errorf("8 keys should fit one bucket: %+v", small)
+	}
+}
+
+func TestSwissModel(t *testing.T) {
+	small := Model(Swiss, keys(5))
+	if small.Units != 1 || small.Slots != 8 {
+		t.Errorf("small map should use a single group: %+v", small)
+	}
+
+	for _, n := range []int{100, 5000, 50000} {
+		s := Model(Swiss, keys(n))
+		if s.Len != n || s.Slots < n {
+			t.Fatalf("n=%d: slots %d cannot hold entries", n, s.Slots)
+		}
+		if s.LoadFactor > float64(maxAvgGroupLoad)/groupSlots {
+			t.Errorf("n=%d: load factor %.3f exceeds 7/8", n, s.LoadFactor)
+		}
+		if n > maxTableCapacity && s.Tables < 2 {
+			t.Errorf("n=%d: expected table splits, got %d tables", n, s.Tables)
+		}
+		if s.AvgProbe < 1 {
+			t.Errorf("n=%d: unexpected probe stats %+v", n, s)
+		}
+	}
+}
+
+func TestRuntimeLayout(t *testing.T) {
+	if layout := RuntimeLayout(); layout != Swiss && layout != Buckets {
+		t.Errorf("unexpected layout %q", layout)
+	}
+}
@@ -0,0 +1,152 @@
+package gomap
+
+// Constants from internal/runtime/maps (Go 1.24 and later).
+const (
+	groupSlots       = 8
+	maxAvgGroupLoad  = 7
+	maxTableCapacity = 1024
+)
+
+// swissTable is one table of the extendible-hashing directory.
+type swissTable struct {
+	localDepth uint
+	capacity   int
+	hashes     []uint64
+	used       []int // slots used per group
+	probes     []int // groups visited to place each hash
+}
+
+func newSwissTable(capacity int, localDepth uint) *swissTable {
+	return &swissTable{
+		localDepth: localDepth,
+		capacity:   capacity,
+		used:       make([]int, capacity/groupSlots),
+	}
+}
+
+// place puts h into the first group with a free slot along the triangular
+// probe sequence, returning the number of groups visited.
+func (t *swissTable) place(h uint64) int {
+	mask := uint64(len(t.used) - 1)
+	offset := (h >> 7) & mask
+	for i := uint64(1); ; i++ {
+		if t.used[offset] < groupSlots {
+			t.used[offset]++
+			return int(i)
+		}
+		offset = (offset + i) & mask
+	}
+}
+
+func (t *swissTable) insert(h uint64) {
+	t.hashes = append(t.hashes, h)
+	t.probes = append(t.probes, t.place(h))
+}
+
+func (t *swissTable) full() bool {
+	return len(t.hashes)*groupSlots >= t.capacity*maxAvgGroupLoad
+}
+
+func (t *swissTable) rebuild(capacity int) {
+	t.capacity = capacity
+	t.used = make([]int, capacity/groupSlots)
+	for i, h := range t.hashes {
+		t.probes[i] = t.place(h)
+	}
+}
+
+// modelSwiss replays insertion into a Swiss-table map: a single group for up
+// to eight entries, then a directory of tables indexed by the top hash bits.
+// Tables double until they reach 1024 slots, after which they split.
+func modelSwiss(hashes []uint64) Stats {
+	n := len(hashes)
+	s := Stats{Layout: Swiss, Len: n}
+
+	if n <= groupSlots {
+		s.Units, s.Slots, s.Tables = 1, groupSlots, 0
+		if n > 0 {
+			s.MaxProbe = 1
+		}
+		return finish(s, n)
+	}
+
+	globalDepth := uint(0)
+	directory := []*swissTable{newSwissTable(2*groupSlots, 0)}
+	index := func(h uint64) int {
+		if globalDepth == 0 {
+			return 0
+		}
+		return int(h >> (64 - globalDepth))
+	}
+
+	for _, h := range hashes {
+		t := directory[index(h)]
+		if t.full() {
+			if t.capacity < maxTableCapacity {
+				t.rebuild(t.capacity * 2)
+			} else {
+				directory, globalDepth = split(directory, globalDepth, index(h))
+				t = directory[index(h)]
+			}
+		}
+		t.insert(h)
+	}
+
+	seen := make(map[*swissTable]bool)
+	totalProbe := 0
+	for _, t := range directory {
+		if seen[t] {
+			continue
+		}
+		seen[t] = true
+		s.Tables++
+		s.Units += len(t.used)
+		for _, p := range t.probes {
+			totalProbe += p
+			if p > s.MaxProbe {
+				s.MaxProbe = p
+			}
+		}
+	}
+	s.Slots = s.Units * groupSlots
+	return finish(s, totalProbe)
+}
+
+// split divides the table at directory index i into two tables one bit
+// deeper, doubling the directory first if the table is already at global depth.
+func split(directory []*swissTable, globalDepth uint, i int) ([]*swissTable, uint) {
+	t := directory[i]
+	if t.localDepth == globalDepth {
+		grown := make([]*swissTable, len(directory)*2)
+		for j, d := range directory {
+			grown[2*j] = d
+			grown[2*j+1] = d
+		}
+		directory = grown
+		globalDepth++
+		i *= 2
+	}
+
+	depth := t.localDepth + 1
+	left := newSwissTable(t.capacity, depth)
+	right := newSwissTable(t.capacity, depth)
+	bit := uint64(1) << (64 - depth)
+	for _, h := range t.hashes {
+		if h&bit == 0 {
+			left.insert(h)
+		} else {
+			right.insert(h)
+		}
+	}
+
+	// The directory entries pointing at t form a contiguous, aligned run.
+	width := 1 << (globalDepth - t.localDepth)
+	start := i &^ (width - 1)
+	for j := start; j < start+width/2; j++ {
+		directory[j] = left
+	}
+	for j := start + width/2; j < start+width; j++ {
+		directory[j] = right
+	}
+	return directory, globalDepth
+}
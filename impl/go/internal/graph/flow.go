@@ -0,0 +1,380 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+)
+
+// maxCapacity bounds the flow Dinic tries to push from the source at once.
+const maxCapacity = 1<<63 - 1
+
+// network is the residual network of a graph for flow algorithms. Edge i
+// becomes arcs 2i, along the edge, and 2i+1, against it, so an arc's
+// partner is a^1. An undirected edge gives both arcs its capacity; a
+// directed one gives the reverse arc none.
+type network struct {
+	n int
+	// head[a] is the vertex arc a leads to, and residual[a] its remaining
+	// capacity.
+	head     []int
+	residual []int64
+	// arcs[offsets[v]:offsets[v+1]] are the arcs leaving v.
+	offsets []int
+	arcs    []int
+}
+
+func newNetwork(g *Graph, s, t int) *network {
+	if s < 0 || s >= g.n || t < 0 || t >= g.n || s == t {
+		panic(fmt.Sprintf("graph: bad terminals %d and %d for %d vertices", s, t, g.n))
+	}
+	m := len(g.edges)
+	nw := &network{
+		n:        g.n,
+		head:     make([]int, 2*m),
+		residual: make([]int64, 2*m),
+		offsets:  make([]int, g.n+1),
+		arcs:     make([]int, 2*m),
+	}
+	for i, e := range g.edges {
+		if e.Weight < 0 {
+			panic(fmt.Sprintf("graph: negative capacity %d on edge %d-%d", e.Weight, e.From, e.To))
+		}
+		nw.head[2*i], nw.head[2*i+1] = e.To, e.From
+		nw.residual[2*i] = e.Weight
+		if !g.directed {
+			nw.residual[2*i+1] = e.Weight
+		}
+		nw.offsets[e.From+1]++
+		nw.offsets[e.To+1]++
+	}
+	for v := 0; v < g.n; v++ {
+		nw.offsets[v+1] += nw.offsets[v]
+	}
+	next := append([]int(nil), nw.offsets[:g.n]...)
+	for a := range nw.head {
+		tail := nw.head[a^1]
+		nw.arcs[next[tail]] = a
+		next[tail]++
+	}
+	return nw
+}
+
+// push moves amount units along arc a.
+func (nw *network) push(a int, amount int64) {
+	nw.residual[a] -= amount
+	nw.residual[a^1] += amount
+}
+
+// Flow is a maximum flow from a source to a sink, found by Dinic or
+// PushRelabel. Edge weights are capacities.
+type Flow struct {
+	// Value is the amount of flow from the source to the sink.
+	Value int64
+	// EdgeFlow[i] is the flow along the graph's i-th edge, from its From to
+	// its To. On an undirected edge it is negative when the flow runs the
+	// other way.
+	EdgeFlow []int64
+
+	g      *Graph
+	source int
+	nw     *network
+}
+
+func newFlow(g *Graph, s int, nw *network, value int64) *Flow {
+	f := &Flow{Value: value, EdgeFlow: make([]int64, len(g.edges)), g: g, source: s, nw: nw}
+	for i, e := range g.edges {
+		f.EdgeFlow[i] = e.Weight - nw.residual[2*i]
+	}
+	return f
+}
+
+// SourceSide returns the source side of a minimum cut: the vertices still
+// reachable from the source through edges with spare capacity.
+func (f *Flow) SourceSide() []bool {
+	seen := make([]bool, f.nw.n)
+	seen[f.source] = true
+	queue := []int{f.source}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for _, a := range f.nw.arcs[f.nw.offsets[v]:f.nw.offsets[v+1]] {
+			if w := f.nw.head[a]; f.nw.residual[a] > 0 && !seen[w] {
+				seen[w] = true
+				queue = append(queue, w)
+			}
+		}
+	}
+	return seen
+}
+
+// MinCut returns the indices of the edges of a minimum cut, those crossing
+// from SourceSide to the rest. Their capacities add up to Value.
+func (f *Flow) MinCut() []int {
+	side := f.SourceSide()
+	var cut []int
+	for i, e := range f.g.edges {
+		if side[e.From] != side[e.To] && (side[e.From] || !f.g.directed) {
+			cut = append(cut, i)
+		}
+	}
+	return cut
+}
+
+// Dinic returns a maximum flow from s to t (Dinic, 1970). Each phase
+// layers the vertices by their distance from s in the residual network and
+// then saturates every shortest path at once with a blocking flow found by
+// depth-first search, skipping arcs known to be dead. The distance to t
+// grows every phase, so there are fewer than n phases, each O(nm): O(n²m)
+// in all, and O(m·sqrt(n)) when every capacity is 1, as in matching. It
+// panics if s or t is not a vertex, if s == t, or on a negative capacity.
+func Dinic(g *Graph, s, t int) *Flow {
+	f, _ := DinicContext(context.Background(), g, s, t)
+	return f
+}
+
+// DinicContext is Dinic for large networks, checking ctx before each phase.
+// If ctx is done it returns ctx's error and no flow.
+func DinicContext(ctx context.Context, g *Graph, s, t int) (*Flow, error) {
+	nw := newNetwork(g, s, t)
+	level := make([]int, nw.n)
+	next := make([]int, nw.n)
+	queue := make([]int, 0, nw.n)
+
+	// augment pushes up to limit units from v to t along arcs that go one
+	// level deeper, returning the amount pushed.
+	var augment func(v int, limit int64) int64
+	augment = func(v int, limit int64) int64 {
+		if v == t {
+			return limit
+		}
+		for ; next[v] < nw.offsets[v+1]; next[v]++ {
+			a := nw.arcs[next[v]]
+			w := nw.head[a]
+			if nw.residual[a] == 0 || level[w] != level[v]+1 {
+				continue
+			}
+			if pushed := augment(w, min(limit, nw.residual[a])); pushed > 0 {
+				nw.push(a, pushed)
+				return pushed
+			}
+		}
+		return 0
+	}
+
+	var value int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		for v := range level {
+			level[v] = -1
+		}
+		level[s] = 0
+		queue = append(queue[:0], s)
+		for i := 0; i < len(queue) && level[t] < 0; i++ {
+			v := queue[i]
+			for _, a := range nw.arcs[nw.offsets[v]:nw.offsets[v+1]] {
+				if w := nw.head[a]; nw.residual[a] > 0 && level[w] < 0 {
+					level[w] = level[v] + 1
+					queue = append(queue, w)
+				}
+			}
+		}
+		if level[t] < 0 {
+			return newFlow(g, s, nw, value), nil
+		}
+		copy(next, nw.offsets[:nw.n])
+		for {
+			pushed := augment(s, maxCapacity)
+			if pushed == 0 {
+				break
+			}
+			value += pushed
+		}
+	}
+}
+
+// PushRelabel returns a maximum flow from s to t by the push-relabel method
+// (Goldberg and Tarjan, 1988). It floods the source's edges and lets
+// vertices with excess flow push it downhill along a height function,
+// raising a vertex when it has nowhere to push. Active vertices are
+// discharged in FIFO order, which bounds the work by O(n³). Heights start
+// at the exact distance to t, and the gap heuristic lifts every vertex
+// above an emptied height straight past n, where its excess drains back to
+// s. It panics in the same cases as Dinic.
+func PushRelabel(g *Graph, s, t int) *Flow {
+	f, _ := PushRelabelContext(context.Background(), g, s, t)
+	return f
+}
+
+// PushRelabelContext is PushRelabel for large networks, checking ctx before
+// each pass over the vertices active when the pass starts. If ctx is done it
+// returns ctx's error and no flow.
+func PushRelabelContext(ctx context.Context, g *Graph, s, t int) (*Flow, error) {
+	nw := newNetwork(g, s, t)
+	n := nw.n
+	height := make([]int, n)
+	excess := make([]int64, n)
+	next := make([]int, n)
+	// count[h] is the number of vertices at height h, for the gap heuristic.
+	count := make([]int, 2*n+1)
+
+	// Start every vertex at its distance to t, or n if it cannot reach t.
+	for v := range height {
+		height[v] = n
+	}
+	height[t] = 0
+	queue := []int{t}
+	for i := 0; i < len(queue); i++ {
+		w := queue[i]
+		for _, a := range nw.arcs[nw.offsets[w]:nw.offsets[w+1]] {
+			// a leaves w, so its partner enters w from head[a].
+			if v := nw.head[a]; nw.residual[a^1] > 0 && height[v] == n && v != t {
+				height[v] = height[w] + 1
+				queue = append(queue, v)
+			}
+		}
+	}
+	height[s] = n
+	for v := range height {
+		count[height[v]]++
+	}
+	copy(next, nw.offsets[:n])
+
+	active, pass := make([]int, 0, n), make([]int, 0, n)
+	activate := func(v int) {
+		if excess[v] == 0 && v != s && v != t {
+			active = append(active, v)
+		}
+	}
+	for _, a := range nw.arcs[nw.offsets[s]:nw.offsets[s+1]] {
+		if c := nw.residual[a]; c > 0 {
+			w := nw.head[a]
+			activate(w)
+			nw.push(a, c)
+			excess[w] += c
+			excess[s] -= c
+		}
+	}
+
+	// discharge pushes v's excess downhill, relabelling v whenever it has no
+	// admissible arc left, until the excess is gone.
+	discharge := func(v int) {
+		for excess[v] > 0 {
+			if next[v] == nw.offsets[v+1] {
+				// Relabel: rise just above the lowest residual neighbour.
+				old := height[v]
+				lowest := 2 * n
+				for _, a := range nw.arcs[nw.offsets[v]:nw.offsets[v+1]] {
+					if nw.residual[a] > 0 {
+						lowest = min(lowest, height[nw.head[a]])
+					}
+				}
+				count[old]--
+				height[v] = lowest + 1
+				count[height[v]]++
+				next[v] = nw.offsets[v]
+				if count[old] == 0 && old < n {
+					// Gap: nothing at height old is left, so nothing above
+					// it can reach t; lift it all to above n.
+					for u := range height {
+						if height[u] > old && height[u] < n && u != s {
+							count[height[u]]--
+							height[u] = n + 1
+							count[height[u]]++
+							next[u] = nw.offsets[u]
+						}
+					}
+				}
+				continue
+			}
+			a := nw.arcs[next[v]]
+			w := nw.head[a]
+			if nw.residual[a] > 0 && height[v] == height[w]+1 {
+				amount := min(excess[v], nw.residual[a])
+				activate(w)
+				nw.push(a, amount)
+				excess[v] -= amount
+				excess[w] += amount
+				continue
+			}
+			next[v]++
+		}
+	}
+
+	for len(active) > 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		// Vertices activated during the pass wait for the next one, which
+		// keeps the discharges in FIFO order.
+		pass, active = active, pass[:0]
+		for _, v := range pass {
+			discharge(v)
+		}
+	}
+	return newFlow(g, s, nw, excess[t]), nil
+}
+
+// BipartiteMatching returns a maximum matching between left vertices
+// 0..left-1 and right vertices 0..right-1, where pairs lists the allowed
+// pairs as {l, r}. The result holds the matched pairs in the same form. It
+// runs Dinic on the unit-capacity network source→left→right→sink, which
+// behaves as Hopcroft-Karp does: O(m·sqrt(n)).
+func BipartiteMatching(left, right int, pairs [][2]int) [][2]int {
+	s, t := left+right, left+right+1
+	g := New(left+right+2, true)
+	for l := 0; l < left; l++ {
+		g.AddEdge(s, l, 1)
+	}
+	for r := 0; r < right; r++ {
+		g.AddEdge(left+r, t, 1)
+	}
+	first := len(g.edges)
+	for _, p := range pairs {
+		g.AddEdge(p[0], left+p[1], 1)
+	}
+	f := Dinic(g, s, t)
+	matched := make([][2]int, 0, f.Value)
+	for i, p := range pairs {
+		if f.EdgeFlow[first+i] > 0 {
+			matched = append(matched, p)
+		}
+	}
+	return matched
+}
+
+// MaxMatching returns a maximum matching of the bipartite graph g as edge
+// indices, given which vertices are on the left. Every edge must join a
+// left vertex to a right one; weights and direction are ignored. It panics
+// otherwise.
+func MaxMatching(g *Graph, isLeft []bool) []int {
+	index := make([]int, g.n) // a vertex's number within its side
+	var left, right int
+	for v := 0; v < g.n; v++ {
+		if isLeft[v] {
+			index[v], left = left, left+1
+		} else {
+			index[v], right = right, right+1
+		}
+	}
+	pairs := make([][2]int, len(g.edges))
+	edgeOf := make(map[[2]int]int, len(g.edges))
+	for i, e := range g.edges {
+		l, r := e.From, e.To
+		if !isLeft[l] {
+			l, r = r, l
+		}
+		if !isLeft[l] || isLeft[r] {
+			panic(fmt.Sprintf("graph: edge %d-%d does not cross the bipartition", e.From, e.To))
+		}
+		pairs[i] = [2]int{index[l], index[r]}
+		edgeOf[pairs[i]] = i
+	}
+	matched := BipartiteMatching(left, right, pairs)
+	edges := make([]int, len(matched))
+	for i, p := range matched {
+		edges[i] = edgeOf[p]
+	}
+	return edges
+}
@@ -0,0 +1,302 @@
+package graph
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// lines calls f with each line of r and its 1-based number, stopping at the
+// first error.
+func lines(r io.Reader, f func(n int, line string) error) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64<<10), 1<<20)
+	n := 0
+	for sc.Scan() {
+		n++
+		if err := f(n, sc.Text()); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
+
+func parseVertex(s string) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("bad vertex %q", s)
+	}
+	return v, nil
+}
+
+// parseWeight parses the optional weight field at fields[i], 1 if absent.
+func parseWeight(fields []string, i int) (int64, error) {
+	if len(fields) <= i {
+		return 1, nil
+	}
+	w, err := strconv.ParseInt(fields[i], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("bad weight %q", fields[i])
+	}
+	return w, nil
+}
+
+// ReadEdgeList reads a graph written one edge per line as "u v" or
+// "u v weight", with vertices numbered from 0 and fields separated by
+// spaces or tabs, the layout of the SNAP collection. A missing weight is 1.
+// Blank lines and lines starting with '#' or '%' are skipped. The graph has
+// one more vertex than the largest number used.
+func ReadEdgeList(r io.Reader, directed bool) (*Graph, error) {
+	g := New(0, directed)
+	err := lines(r, func(n int, line string) error {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || fields[0][0] == '#' || fields[0][0] == '%' {
+			return nil
+		}
+		if len(fields) < 2 || len(fields) > 3 {
+			return fmt.Errorf("graph: line %d: expected \"u v [weight]\", got %q", n, line)
+		}
+		from, err := parseVertex(fields[0])
+		if err != nil {
+			return fmt.Errorf("graph: line %d: %w", n, err)
+		}
+		to, err := parseVertex(fields[1])
+		if err != nil {
+			return fmt.Errorf("graph: line %d: %w", n, err)
+		}
+		w, err := parseWeight(fields, 2)
+		if err != nil {
+			return fmt.Errorf("graph: line %d: %w", n, err)
+		}
+		g.n = max(g.n, from+1, to+1)
+		g.edges = append(g.edges, Edge{from, to, w})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// WriteEdgeList writes g in the format ReadEdgeList reads, one "u v weight"
+// line per edge. Vertices without edges past the last one used are not
+// recorded.
+func WriteEdgeList(w io.Writer, g *Graph) error {
+	bw := bufio.NewWriter(w)
+	for _, e := range g.edges {
+		fmt.Fprintf(bw, "%d %d %d\n", e.From, e.To, e.Weight)
+	}
+	return bw.Flush()
+}
+
+// DIMACS describes the problem a DIMACS file poses about its graph.
+type DIMACS struct {
+	// Problem is the type on the problem line: "sp" for shortest paths and
+	// "max" for maximum flow, both directed, or "edge" for an undirected
+	// graph, as in the coloring and clique instances.
+	Problem string
+	// Source and Sink are the 0-based terminals of a flow problem, or -1
+	// where the file names none.
+	Source, Sink int
+}
+
+// ReadDIMACS reads a graph in the DIMACS format of the implementation
+// challenges: a problem line "p <type> <vertices> <edges>", then directed
+// arcs "a u v [weight]" or undirected edges "e u v [weight]", with vertices
+// numbered from 1, and for flow problems "n <vertex> s" and "n <vertex> t"
+// lines naming the source and sink. Lines starting with 'c' are comments.
+// Vertices are renumbered from 0. The edge count on the problem line must
+// match the edges given.
+func ReadDIMACS(r io.Reader) (*Graph, DIMACS, error) {
+	d := DIMACS{Source: -1, Sink: -1}
+	var g *Graph
+	declared := 0
+	err := lines(r, func(n int, line string) error {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || fields[0] == "c" {
+			return nil
+		}
+		bad := func(format string, args ...any) error {
+			return fmt.Errorf("graph: line %d: "+format, append([]any{n}, args...)...)
+		}
+		if fields[0] == "p" {
+			if g != nil {
+				return bad("second problem line")
+			}
+			if len(fields) != 4 {
+				return bad("expected \"p <type> <vertices> <edges>\", got %q", line)
+			}
+			vertices, err1 := strconv.Atoi(fields[2])
+			edges, err2 := strconv.Atoi(fields[3])
+			if err1 != nil || err2 != nil || vertices < 0 || edges < 0 {
+				return bad("bad problem size in %q", line)
+			}
+			d.Problem = fields[1]
+			g = New(vertices, d.Problem != "edge" && d.Problem != "col")
+			g.edges = make([]Edge, 0, edges)
+			declared = edges
+			return nil
+		}
+		if g == nil {
+			return bad("%q before the problem line", fields[0])
+		}
+		vertex := func(s string) (int, error) {
+			v, err := strconv.Atoi(s)
+			if err != nil || v < 1 || v > g.n {
+				return 0, bad("bad vertex %q", s)
+			}
+			return v - 1, nil
+		}
+		switch fields[0] {
+		case "a", "e":
+			if (fields[0] == "a") != g.directed {
+				return bad("%q line in a %q problem", fields[0], d.Problem)
+			}
+			if len(fields) < 3 || len(fields) > 4 {
+				return bad("expected \"%s u v [weight]\", got %q", fields[0], line)
+			}
+			from, err := vertex(fields[1])
+			if err != nil {
+				return err
+			}
+			to, err := vertex(fields[2])
+			if err != nil {
+				return err
+			}
+			w, err := parseWeight(fields, 3)
+			if err != nil {
+				return bad("%v", err)
+			}
+			g.edges = append(g.edges, Edge{from, to, w})
+		case "n":
+			if len(fields) != 3 {
+				return bad("expected \"n <vertex> s|t\", got %q", line)
+			}
+			v, err := vertex(fields[1])
+			if err != nil {
+				return err
+			}
+			switch fields[2] {
+			case "s":
+				d.Source = v
+			case "t":
+				d.Sink = v
+			default:
+				return bad("unknown terminal %q", fields[2])
+			}
+		default:
+			return bad("unknown line type %q", fields[0])
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, DIMACS{}, err
+	}
+	if g == nil {
+		return nil, DIMACS{}, fmt.Errorf("graph: no problem line")
+	}
+	if len(g.edges) != declared {
+		return nil, DIMACS{}, fmt.Errorf("graph: problem line declares %d edges, found %d", declared, len(g.edges))
+	}
+	return g, d, nil
+}
+
+// WriteDIMACS writes g in the format ReadDIMACS reads. An empty d.Problem
+// is written as "sp" for a directed graph and "edge" for an undirected one;
+// terminals are written when not -1.
+func WriteDIMACS(w io.Writer, g *Graph, d DIMACS) error {
+	problem := d.Problem
+	if problem == "" {
+		problem = "sp"
+		if !g.directed {
+			problem = "edge"
+		}
+	}
+	kind := "a"
+	if !g.directed {
+		kind = "e"
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "p %s %d %d\n", problem, g.n, len(g.edges))
+	if d.Source >= 0 {
+		fmt.Fprintf(bw, "n %d s\n", d.Source+1)
+	}
+	if d.Sink >= 0 {
+		fmt.Fprintf(bw, "n %d t\n", d.Sink+1)
+	}
+	for _, e := range g.edges {
+		fmt.Fprintf(bw, "%s %d %d %d\n", kind, e.From+1, e.To+1, e.Weight)
+	}
+	return bw.Flush()
+}
+
+// jsonGraph is the JSON adjacency schema: adjacency[u] lists the edges
+// leaving u. An undirected edge is listed under one endpoint only.
+type jsonGraph struct {
+	Directed  bool         `json:"directed"`
+	Vertices  int          `json:"vertices"`
+	Adjacency [][]jsonEdge `json:"adjacency"`
+}
+
+// jsonEdge is an entry of an adjacency list; a missing weight is 1.
+type jsonEdge struct {
+	To     int    `json:"to"`
+	Weight *int64 `json:"weight,omitempty"`
+}
+
+// ReadJSON reads a graph in the JSON adjacency schema:
+//
+//	{"directed": true, "vertices": 3,
+//	 "adjacency": [[{"to": 1, "weight": 4}, {"to": 2}], [{"to": 2}], []]}
+//
+// adjacency[u] lists the edges from u, with weight 1 where none is given,
+// and may be shorter than vertices. An undirected edge is listed once,
+// under either endpoint. Without "vertices" the graph has one vertex per
+// adjacency list.
+func ReadJSON(r io.Reader) (*Graph, error) {
+	var jg jsonGraph
+	if err := json.NewDecoder(r).Decode(&jg); err != nil {
+		return nil, fmt.Errorf("graph: %w", err)
+	}
+	if jg.Vertices == 0 {
+		jg.Vertices = len(jg.Adjacency)
+	}
+	if len(jg.Adjacency) > jg.Vertices {
+		return nil, fmt.Errorf("graph: %d adjacency lists for %d vertices", len(jg.Adjacency), jg.Vertices)
+	}
+	g := New(jg.Vertices, jg.Directed)
+	for from, list := range jg.Adjacency {
+		for _, e := range list {
+			if e.To < 0 || e.To >= g.n {
+				return nil, fmt.Errorf("graph: edge %d-%d out of range [0, %d)", from, e.To, g.n)
+			}
+			w := int64(1)
+			if e.Weight != nil {
+				w = *e.Weight
+			}
+			g.edges = append(g.edges, Edge{from, e.To, w})
+		}
+	}
+	return g, nil
+}
+
+// WriteJSON writes g in the JSON adjacency schema ReadJSON reads, listing
+// each edge under its From vertex and leaving out weights of 1.
+func WriteJSON(w io.Writer, g *Graph) error {
+	jg := jsonGraph{Directed: g.directed, Vertices: g.n, Adjacency: make([][]jsonEdge, g.n)}
+	for v := range jg.Adjacency {
+		jg.Adjacency[v] = []jsonEdge{}
+	}
+	for _, e := range g.edges {
+		je := jsonEdge{To: e.To}
+		if e.Weight != 1 {
+			weight := e.Weight
+			je.Weight = &weight
+		}
+		jg.Adjacency[e.From] = append(jg.Adjacency[e.From], je)
+	}
+	return json.NewEncoder(w).Encode(jg)
+}
@@ -0,0 +1,110 @@
+package graph
+
+import (
+	"math"
+	"math/rand"
+)
+
+// ErdosRenyi returns a random graph G(n, p) in which each of the possible
+// edges between distinct vertices is present independently with
+// probability p, with weight 1. Rather than tossing a coin per pair it
+// draws the gap to the next present edge from a geometric distribution
+// (Batagelj and Brandes, 2005), so it takes O(n + m) time for m edges,
+// and sparse graphs of millions of vertices are cheap.
+func ErdosRenyi(n int, p float64, directed bool, rng *rand.Rand) *Graph {
+	g := New(n, directed)
+	if p <= 0 || n < 2 {
+		return g
+	}
+	// Candidate pairs are numbered in row order: (u, v) for v < u when
+	// undirected, and all v != u when directed.
+	rowLen := func(u int) int {
+		if directed {
+			return n - 1
+		}
+		return u
+	}
+	logq := math.Log1p(-p)
+	u, i := 0, -1
+	for {
+		if p >= 1 {
+			i++
+		} else {
+			gap := math.Log(1-rng.Float64()) / logq
+			if gap >= float64(n)*float64(n) {
+				return g // past the last pair
+			}
+			i += 1 + int(gap)
+		}
+		for u < n && i >= rowLen(u) {
+			i -= rowLen(u)
+			u++
+		}
+		if u == n {
+			return g
+		}
+		v := i
+		if directed && v >= u {
+			v++ // skip the self-loop
+		}
+		g.edges = append(g.edges, Edge{u, v, 1})
+	}
+}
+
+// BarabasiAlbert returns an undirected scale-free graph grown by
+// preferential attachment: starting from m vertices without edges, each new
+// vertex attaches to m distinct existing vertices chosen with probability
+// proportional to their degree, until there are n. Degrees then follow a
+// power law with exponent about 3, with a few hubs, like many real
+// networks. Edges have weight 1.
+func BarabasiAlbert(n, m int, rng *rand.Rand) *Graph {
+	m = max(m, 1)
+	g := New(max(n, 0), false)
+	if n <= m {
+		return g
+	}
+	// ends holds every edge endpoint once per edge, so a uniform pick from
+	// it is a pick proportional to degree.
+	ends := make([]int, 0, 2*m*(n-m))
+	targets := make([]int, m)
+	for v := range targets {
+		targets[v] = v
+	}
+	chosen := make(map[int]bool, m)
+	for v := m; v < n; v++ {
+		for _, t := range targets {
+			g.edges = append(g.edges, Edge{v, t, 1})
+			ends = append(ends, v, t)
+		}
+		clear(chosen)
+		targets = targets[:0]
+		for len(targets) < m {
+			t := ends[rng.Intn(len(ends))]
+			if !chosen[t] {
+				chosen[t] = true
+				targets = append(targets, t)
+			}
+		}
+	}
+	return g
+}
+
+// Grid returns the undirected rows by cols grid graph, with vertex
+// r*cols+c joined to its horizontal and vertical neighbours by edges of
+// weight 1. Road networks look more like grids than like random graphs:
+// low degree and long shortest paths.
+func Grid(rows, cols int) *Graph {
+	g := New(max(rows, 0)*max(cols, 0), false)
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			v := r*cols + c
+			if c+1 < cols {
+				g.edges = append(g.edges, Edge{v, v + 1, 1})
+			}
+			if r+1 < rows {
+				g.edges = append(g.edges, Edge{v, v + cols, 1})
+			}
+		}
+	}
+	return g
+}
@@ -0,0 +1,125 @@
+// Package graph provides a weighted graph on integer vertices, readers and
+// writers for the common interchange formats, and random-graph generators,
+// so graph algorithms can be run on published benchmark instances as well as
+// synthetic ones of any size. Dinic and PushRelabel compute maximum flows
+// and minimum cuts, treating edge weights as capacities; their Context
+// variants can be cancelled between phases on large networks.
+package graph
+
+import "fmt"
+
+// Edge is an edge from From to To. In an undirected graph the two
+// endpoints are interchangeable.
+type Edge struct {
+	From, To int
+	Weight   int64
+}
+
+// Graph is a graph on the vertices 0 to NumVertices()-1 with int64 edge
+// weights, directed or not. Parallel edges and self-loops are allowed.
+// Edges are kept in the order added; Neighbors indexes them by source in a
+// compressed adjacency array, built on first use after a change. It is not
+// safe for concurrent use, even by readers, until Neighbors has been called
+// once after the last change.
+type Graph struct {
+	n        int
+	directed bool
+	edges    []Edge
+
+	// offsets[v] to offsets[v+1] is the range of adj holding v's edges.
+	// Both are nil when stale.
+	offsets []int
+	adj     []Edge
+}
+
+// New creates a graph of n vertices and no edges.
+func New(n int, directed bool) *Graph {
+	return &Graph{n: n, directed: directed}
+}
+
+// Directed reports whether edges are directed.
+func (g *Graph) Directed() bool {
+	return g.directed
+}
+
+// NumVertices returns the number of vertices.
+func (g *Graph) NumVertices() int {
+	return g.n
+}
+
+// NumEdges returns the number of edges added, each undirected edge once.
+func (g *Graph) NumEdges() int {
+	return len(g.edges)
+}
+
+// AddVertex adds a vertex and returns its number.
+func (g *Graph) AddVertex() int {
+	g.n++
+	g.offsets, g.adj = nil, nil
+	return g.n - 1
+}
+
+// AddEdge adds an edge from from to to. It panics if either is not a
+// vertex.
+func (g *Graph) AddEdge(from, to int, weight int64) {
+	if from < 0 || from >= g.n || to < 0 || to >= g.n {
+		panic(fmt.Sprintf("graph: edge %d-%d out of range [0, %d)", from, to, g.n))
+	}
+	g.edges = append(g.edges, Edge{from, to, weight})
+	g.offsets, g.adj = nil, nil
+}
+
+// Edges returns the edges in the order they were added. The slice is the
+// graph's own and must not be modified.
+func (g *Graph) Edges() []Edge {
+	return g.edges
+}
+
+// Neighbors returns the edges leaving v, each with From set to v. An
+// undirected edge is reported from both of its endpoints, a self-loop once.
+// The slice is the graph's own and must not be modified.
+func (g *Graph) Neighbors(v int) []Edge {
+	if g.offsets == nil {
+		g.index()
+	}
+	return g.adj[g.offsets[v]:g.offsets[v+1]]
+}
+
+// Degree returns the number of edges leaving v.
+func (g *Graph) Degree(v int) int {
+	return len(g.Neighbors(v))
+}
+
+// Reweight sets the weight of every edge to weight(e).
+func (g *Graph) Reweight(weight func(e Edge) int64) {
+	for i := range g.edges {
+		g.edges[i].Weight = weight(g.edges[i])
+	}
+	g.offsets, g.adj = nil, nil
+}
+
+// index builds the adjacency array with a counting sort of the edges by
+// source.
+func (g *Graph) index() {
+	offsets := make([]int, g.n+1)
+	for _, e := range g.edges {
+		offsets[e.From+1]++
+		if !g.directed && e.From != e.To {
+			offsets[e.To+1]++
+		}
+	}
+	for v := 0; v < g.n; v++ {
+		offsets[v+1] += offsets[v]
+	}
+	adj := make([]Edge, offsets[g.n])
+	next := append([]int(nil), offsets[:g.n]...)
+	for _, e := range g.edges {
+		adj[next[e.From]] = e
+		next[e.From]++
+		if !g.directed && e.From != e.To {
+			adj[next[e.To]] = Edge{e.To, e.From, e.Weight}
+			next[e.To]++
+		}
+	}
+	g.offsets, g.adj = offsets, adj
+}
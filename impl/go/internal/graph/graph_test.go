@@ -0,0 +1,480 @@
+package graph
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// sortedEdges returns g's edges in a canonical order, undirected edges with
+// their smaller endpoint first.
+func sortedEdges(g *Graph) []Edge {
+	edges := slices.Clone(g.Edges())
+	for i, e := range edges {
+		if !g.Directed() && e.From > e.To {
+			edges[i].From, edges[i].To = e.To, e.From
+		}
+	}
+	slices.SortFunc(edges, func(a, b Edge) int {
+		if a.From != b.From {
+			return cmp.Compare(a.From, b.From)
+		}
+		if a.To != b.To {
+			return cmp.Compare(a.To, b.To)
+		}
+		return cmp.Compare(a.Weight, b.Weight)
+	})
+	return edges
+}
+
+func TestNeighbors(t *testing.T) {
+	g := New(3, false)
+	g.AddEdge(0, 1, 5)
+	g.AddEdge(1, 2, 7)
+	g.AddEdge(2, 2, 1)
+	if got := g.Neighbors(1); !slices.Equal(got, []Edge{{1, 0, 5}, {1, 2, 7}}) {
+		t.Errorf("expected both edges of vertex 1, got %v", got)
+	}
+	if got := g.Degree(2); got != 2 {
+		t.Errorf("expected degree 2 with a self-loop counted once, got %d", got)
+	}
+	v := g.AddVertex()
+	g.AddEdge(v, 0, 2)
+	if got := g.Neighbors(0); !slices.Equal(got, []Edge{{0, 1, 5}, {0, 3, 2}}) {
+		t.Errorf("expected the new edge after a change, got %v", got)
+	}
+
+	d := New(2, true)
+	d.AddEdge(0, 1, 1)
+	if d.Degree(0) != 1 || d.Degree(1) != 0 {
+		t.Errorf("expected out-degrees 1 and 0, got %d and %d", d.Degree(0), d.Degree(1))
+	}
+	d.Reweight(func(e Edge) int64 { return 9 })
+	if got := d.Neighbors(0)[0].Weight; got != 9 {
+		t.Errorf("expected weight 9, got %d", got)
+	}
+}
+
+func TestRoundTrips(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	graphs := map[string]*Graph{
+		"er-directed":   ErdosRenyi(200, 0.05, true, rng),
+		"er-undirected": ErdosRenyi(200, 0.05, false, rng),
+		"ba":            BarabasiAlbert(300, 3, rng),
+		"grid":          Grid(7, 9),
+	}
+	for _, g := range graphs {
+		g.Reweight(func(Edge) int64 { return rng.Int63n(10) - 2 })
+	}
+	formats := map[string]func(g *Graph) (*Graph, error){
+		"edgelist": func(g *Graph) (*Graph, error) {
+			var buf bytes.Buffer
+			if err := WriteEdgeList(&buf, g); err != nil {
+				return nil, err
+			}
+			return ReadEdgeList(&buf, g.Directed())
+		},
+		"dimacs": func(g *Graph) (*Graph, error) {
+			var buf bytes.Buffer
+			if err := WriteDIMACS(&buf, g, DIMACS{Source: -1, Sink: -1}); err != nil {
+				return nil, err
+			}
+			got, _, err := ReadDIMACS(&buf)
+			return got, err
+		},
+		"json": func(g *Graph) (*Graph, error) {
+			var buf bytes.Buffer
+			if err := WriteJSON(&buf, g); err != nil {
+				return nil, err
+			}
+			return ReadJSON(&buf)
+		},
+	}
+	for gname, g := range graphs {
+		for fname, roundTrip := range formats {
+			got, err := roundTrip(g)
+			if err != nil {
+				t.Fatalf("%s/%s: %v", gname, fname, err)
+			}
+			if got.Directed() != g.Directed() || !slices.Equal(sortedEdges(got), sortedEdges(g)) {
+				t.Errorf("%s/%s: expected the same edges back", gname, fname)
+			}
+			// The edge list cannot record trailing isolated vertices.
+			if fname != "edgelist" && got.NumVertices() != g.NumVertices() {
+				t.Errorf("%s/%s: expected %d vertices, got %d", gname, fname, g.NumVertices(), got.NumVertices())
+			}
+		}
+	}
+}
+
+func TestReadEdgeList(t *testing.T) {
+	in := "# SNAP-style header\n0\t1\n\n% another comment\n1 3 -4\n"
+	g, err := ReadEdgeList(strings.NewReader(in), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.NumVertices() != 4 || !slices.Equal(g.Edges(), []Edge{{0, 1, 1}, {1, 3, -4}}) {
+		t.Errorf("expected 4 vertices and two edges, got %d and %v", g.NumVertices(), g.Edges())
+	}
+	for _, bad := range []string{"0\n", "0 1 2 3\n", "0 -1\n", "a b\n", "0 1 x\n"} {
+		if _, err := ReadEdgeList(strings.NewReader(bad), true); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
+
+func TestReadDIMACS(t *testing.T) {
+	in := `c a maximum flow instance
+p max 4 5
+n 1 s
+n 4 t
+a 1 2 3
+a 1 3 2
+a 2 3 1
+a 2 4 2
+a 3 4 3
+`
+	g, d, err := ReadDIMACS(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d != (DIMACS{Problem: "max", Source: 0, Sink: 3}) {
+		t.Errorf("expected a max problem from 0 to 3, got %+v", d)
+	}
+	if !g.Directed() || g.NumVertices() != 4 || g.NumEdges() != 5 || g.Edges()[2] != (Edge{1, 2, 1}) {
+		t.Errorf("expected 4 vertices and 5 arcs renumbered from 0, got %d, %v", g.NumVertices(), g.Edges())
+	}
+
+	for _, bad := range []string{
+		"a 1 2 3\n",            // no problem line
+		"p sp 2 1\n",           // missing arc
+		"p sp 2 1\na 1 3 1\n",  // vertex out of range
+		"p sp 2 1\ne 1 2\n",    // undirected edge in a directed problem
+		"p sp 2 0\nn 1 x\n",    // unknown terminal
+		"p sp 2\n",             // short problem line
+		"p sp 1 0\np sp 1 0\n", // two problem lines
+		"p sp 2 1\nq 1 2\n",    // unknown line
+		"",
+	} {
+		if _, _, err := ReadDIMACS(strings.NewReader(bad)); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
+
+func TestReadJSON(t *testing.T) {
+	in := `{"directed": true, "vertices": 4, "adjacency": [[{"to": 1, "weight": 4}, {"to": 2}], [{"to": 2, "weight": 0}]]}`
+	g, err := ReadJSON(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Edge{{0, 1, 4}, {0, 2, 1}, {1, 2, 0}}
+	if g.NumVertices() != 4 || !slices.Equal(g.Edges(), want) {
+		t.Errorf("expected 4 vertices and %v, got %d and %v", want, g.NumVertices(), g.Edges())
+	}
+	for _, bad := range []string{
+		`{"vertices": 1, "adjacency": [[], []]}`,
+		`{"adjacency": [[{"to": 1}]]}`,
+		`{"adjacency": [`,
+	} {
+		if _, err := ReadJSON(strings.NewReader(bad)); err == nil {
+			t.Errorf("expected an error for %s", bad)
+		}
+	}
+}
+
+// checkSimple fails if g has a self-loop or a repeated edge.
+func checkSimple(t *testing.T, name string, g *Graph) {
+	t.Helper()
+	seen := make(map[Edge]bool)
+	for _, e := range sortedEdges(g) {
+		if e.From == e.To {
+			t.Fatalf("%s: unexpected self-loop at %d", name, e.From)
+		}
+		if seen[e] {
+			t.Fatalf("%s: repeated edge %d-%d", name, e.From, e.To)
+		}
+		seen[e] = true
+	}
+}
+
+func TestErdosRenyi(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	for _, directed := range []bool{false, true} {
+		const n, p = 2000, 0.01
+		g := ErdosRenyi(n, p, directed, rng)
+		checkSimple(t, "ErdosRenyi", g)
+		pairs := float64(n) * (n - 1)
+		if !directed {
+			pairs /= 2
+		}
+		// Five standard deviations of the binomial edge count.
+		want, slack := pairs*p, 5*math.Sqrt(pairs*p*(1-p))
+		if m := float64(g.NumEdges()); m < want-slack || m > want+slack {
+			t.Errorf("directed=%v: expected about %.0f edges, got %.0f", directed, want, m)
+		}
+		complete := 30 * 29
+		if !directed {
+			complete /= 2
+		}
+		if full := ErdosRenyi(30, 1, directed, rng); full.NumEdges() != complete {
+			t.Errorf("directed=%v: expected %d edges for p=1, got %d", directed, complete, full.NumEdges())
+		}
+	}
+	if g := ErdosRenyi(1000, 1e-300, false, rng); g.NumEdges() != 0 {
+		t.Errorf("expected no edges for a vanishing p, got %d", g.NumEdges())
+	}
+}
+
+func TestBarabasiAlbert(t *testing.T) {
+	const n, m = 5000, 3
+	g := BarabasiAlbert(n, m, rand.New(rand.NewSource(3)))
+	checkSimple(t, "BarabasiAlbert", g)
+	if g.NumEdges() != m*(n-m) {
+		t.Fatalf("expected %d edges, got %d", m*(n-m), g.NumEdges())
+	}
+	hub := 0
+	for v := 0; v < n; v++ {
+		hub = max(hub, g.Degree(v))
+		if v >= m && g.Degree(v) < m {
+			t.Fatalf("expected vertex %d to attach to %d others, got degree %d", v, m, g.Degree(v))
+		}
+	}
+	// Preferential attachment grows hubs of degree about m*sqrt(n).
+	if hub < 10*m {
+		t.Errorf("expected a hub of degree at least %d, got %d", 10*m, hub)
+	}
+}
+
+func TestGrid(t *testing.T) {
+	g := Grid(3, 4)
+	checkSimple(t, "Grid", g)
+	if g.NumVertices() != 12 || g.NumEdges() != 3*3+2*4 {
+		t.Fatalf("expected 12 vertices and 17 edges, got %d and %d", g.NumVertices(), g.NumEdges())
+	}
+	for v, want := range map[int]int{0: 2, 1: 3, 5: 4, 11: 2} {
+		if got := g.Degree(v); got != want {
+			t.Errorf("expected vertex %d to have degree %d, got %d", v, want, got)
+		}
+	}
+}
+
+// checkFlow verifies that f is a feasible flow from s to t in g whose value
+// equals the capacity of its minimum cut, which proves it maximum.
+func checkFlow(t *testing.T, name string, g *Graph, s, sink int, f *Flow) {
+	t.Helper()
+	net := make([]int64, g.NumVertices())
+	for i, e := range g.Edges() {
+		x := f.EdgeFlow[i]
+		if x > e.Weight || (g.Directed() && x < 0) || x < -e.Weight {
+			t.Fatalf("%s: flow %d on edge %d-%d of capacity %d", name, x, e.From, e.To, e.Weight)
+		}
+		net[e.From] -= x
+		net[e.To] += x
+	}
+	for v, x := range net {
+		if v != s && v != sink && x != 0 {
+			t.Fatalf("%s: %d units lost at vertex %d", name, x, v)
+		}
+	}
+	if net[sink] != f.Value || net[s] != -f.Value {
+		t.Fatalf("%s: value %d, but %d leaves the source and %d reaches the sink", name, f.Value, -net[s], net[sink])
+	}
+	side := f.SourceSide()
+	if !side[s] || side[sink] {
+		t.Fatalf("%s: the cut does not separate source and sink", name)
+	}
+	var cut int64
+	for _, i := range f.MinCut() {
+		cut += g.Edges()[i].Weight
+	}
+	if cut != f.Value {
+		t.Fatalf("%s: cut capacity %d, flow value %d", name, cut, f.Value)
+	}
+}
+
+func TestMaxFlow(t *testing.T) {
+	g, d, err := ReadDIMACS(strings.NewReader("p max 4 5\nn 1 s\nn 4 t\na 1 2 3\na 1 3 2\na 2 3 1\na 2 4 2\na 3 4 3\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, maxFlow := range map[string]func(*Graph, int, int) *Flow{"dinic": Dinic, "pushrelabel": PushRelabel} {
+		f := maxFlow(g, d.Source, d.Sink)
+		if f.Value != 5 {
+			t.Errorf("%s: expected flow 5, got %d", name, f.Value)
+		}
+		checkFlow(t, name, g, d.Source, d.Sink, f)
+	}
+}
+
+func TestMaxFlowAgreement(t *testing.T) {
+	rng := rand.New(rand.NewSource(4))
+	for trial := 0; trial < 300; trial++ {
+		n := 2 + rng.Intn(40)
+		directed := trial%3 != 0
+		g := ErdosRenyi(n, rng.Float64()*0.5, directed, rng)
+		// A few parallel edges and self-loops, which both must tolerate.
+		for i := 0; i < 3; i++ {
+			u := rng.Intn(n)
+			g.AddEdge(u, rng.Intn(n), 1)
+			g.AddEdge(u, u, 1)
+		}
+		g.Reweight(func(Edge) int64 { return rng.Int63n(20) })
+		s, sink := rng.Intn(n), rng.Intn(n-1)
+		if sink >= s {
+			sink++
+		}
+		dinic, pr := Dinic(g, s, sink), PushRelabel(g, s, sink)
+		if dinic.Value != pr.Value {
+			t.Fatalf("trial %d: Dinic found %d, push-relabel %d", trial, dinic.Value, pr.Value)
+		}
+		checkFlow(t, "dinic", g, s, sink, dinic)
+		checkFlow(t, "pushrelabel", g, s, sink, pr)
+	}
+}
+
+// countdownContext is a context whose Err starts failing after a fixed
+// number of calls, so a test can stop an algorithm at a chosen check.
+type countdownContext struct {
+	context.Context
+	left int
+}
+
+func (c *countdownContext) Err() error {
+	if c.left == 0 {
+		return context.Canceled
+	}
+	c.left--
+	return nil
+}
+
+// pathLadder returns a network whose k unit paths from 0 to 1 have lengths
+// 1 to k, so Dinic needs a phase for each.
+func pathLadder(k int) *Graph {
+	g := New(2, true)
+	for length := 1; length <= k; length++ {
+		v := 0
+		for i := 1; i < length; i++ {
+			w := g.AddVertex()
+			g.AddEdge(v, w, 1)
+			v = w
+		}
+		g.AddEdge(v, 1, 1)
+	}
+	return g
+}
+
+func TestMaxFlowContext(t *testing.T) {
+	const k = 10
+	g := pathLadder(k)
+	// k phases push flow and one more finds the sink unreachable.
+	if f, err := DinicContext(&countdownContext{context.Background(), k + 1}, g, 0, 1); err != nil || f.Value != k {
+		t.Fatalf("dinic: expected flow %d within %d checks, got %v, %v", k, k+1, f, err)
+	}
+	if f, err := DinicContext(&countdownContext{context.Background(), k}, g, 0, 1); !errors.Is(err, context.Canceled) || f != nil {
+		t.Errorf("dinic: expected cancellation before the last phase, got %v, %v", f, err)
+	}
+
+	f, err := PushRelabelContext(context.Background(), g, 0, 1)
+	if err != nil || f.Value != k {
+		t.Fatalf("pushrelabel: expected flow %d, got %v, %v", k, f, err)
+	}
+	checkFlow(t, "pushrelabel", g, 0, 1, f)
+	// The longer paths need a pass per vertex they pass through.
+	if f, err := PushRelabelContext(&countdownContext{context.Background(), 1}, g, 0, 1); !errors.Is(err, context.Canceled) || f != nil {
+		t.Errorf("pushrelabel: expected cancellation after one pass, got %v, %v", f, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for name, maxFlow := range map[string]func(context.Context, *Graph, int, int) (*Flow, error){"dinic": DinicContext, "pushrelabel": PushRelabelContext} {
+		if _, err := maxFlow(ctx, g, 0, 1); !errors.Is(err, context.Canceled) {
+			t.Errorf("%s: expected context.Canceled, got %v", name, err)
+		}
+	}
+}
+
+func TestBipartiteMatching(t *testing.T) {
+	// Left 0 can only take right 0, so left 1 must take right 1 and left 2
+	// is left out.
+	pairs := [][2]int{{0, 0}, {1, 0}, {1, 1}, {2, 1}}
+	matched := BipartiteMatching(3, 2, pairs)
+	if len(matched) != 2 {
+		t.Fatalf("expected 2 matched pairs, got %v", matched)
+	}
+	lefts, rights := map[int]bool{}, map[int]bool{}
+	for _, p := range matched {
+		if !slices.Contains(pairs, p) || lefts[p[0]] || rights[p[1]] {
+			t.Fatalf("expected a matching drawn from the pairs, got %v", matched)
+		}
+		lefts[p[0]], rights[p[1]] = true, true
+	}
+
+	// Random bipartite graphs: the matching size must equal the maximum
+	// flow, computed by push-relabel, in the unit network.
+	rng := rand.New(rand.NewSource(5))
+	for trial := 0; trial < 100; trial++ {
+		left, right := 1+rng.Intn(30), 1+rng.Intn(30)
+		g := New(left+right, false)
+		isLeft := make([]bool, left+right)
+		for l := 0; l < left; l++ {
+			isLeft[l] = true
+			for r := 0; r < right; r++ {
+				if rng.Intn(10) == 0 {
+					g.AddEdge(left+r, l, 7)
+				}
+			}
+		}
+		edges := MaxMatching(g, isLeft)
+		used := make([]bool, left+right)
+		for _, i := range edges {
+			e := g.Edges()[i]
+			if used[e.From] || used[e.To] {
+				t.Fatalf("trial %d: vertex matched twice", trial)
+			}
+			used[e.From], used[e.To] = true, true
+		}
+		unit := New(left+right+2, true)
+		for l := 0; l < left; l++ {
+			unit.AddEdge(left+right, l, 1)
+		}
+		for r := 0; r < right; r++ {
+			unit.AddEdge(left+r, left+right+1, 1)
+		}
+		for _, e := range g.Edges() {
+			unit.AddEdge(e.To, e.From, 1)
+		}
+		if want := PushRelabel(unit, left+right, left+right+1).Value; int64(len(edges)) != want {
+			t.Fatalf("trial %d: expected a matching of %d, got %d", trial, want, len(edges))
+		}
+	}
+}
+
+func TestVisualize(t *testing.T) {
+	for _, directed := range []bool{true, false} {
+		g := New(4, directed)
+		g.AddEdge(0, 1, 5)
+		g.AddEdge(1, 2, -3)
+		g.AddEdge(1, 2, 7)
+		var buf strings.Builder
+		if err := g.Visualize(&buf); err != nil {
+			t.Fatal(err)
+		}
+		dot := buf.String()
+		head, arrow := "graph g {", "--"
+		if directed {
+			head, arrow = "digraph g {", "->"
+		}
+		for _, want := range []string{head, "  3;\n", "0 " + arrow + ` 1 [label="5"]`, "1 " + arrow + ` 2 [label="-3"]`, "1 " + arrow + ` 2 [label="7"]`} {
+			if !strings.Contains(dot, want) {
+				t.Errorf("directed=%v: expected DOT containing %q, got:\n%s", directed, want, dot)
+			}
+		}
+	}
+}
@@ -0,0 +1,76 @@
+//go:build go1.23
+
+package graph
+
+import "iter"
+
+// All returns an iterator over the edges in the order they were added, each
+// undirected edge once.
+func (g *Graph) All() iter.Seq[Edge] {
+	return func(yield func(Edge) bool) {
+		for _, e := range g.edges {
+			if !yield(e) {
+				return
+			}
+		}
+	}
+}
+
+// BFS returns an iterator over the vertices reachable from src in
+// breadth-first order, src first, following edges in the order Neighbors
+// reports them. Stopping early skips the rest of the search.
+func (g *Graph) BFS(src int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		seen := make([]bool, g.n)
+		seen[src] = true
+		queue := []int{src}
+		for len(queue) > 0 {
+			v := queue[0]
+			queue = queue[1:]
+			if !yield(v) {
+				return
+			}
+			for _, e := range g.Neighbors(v) {
+				if !seen[e.To] {
+					seen[e.To] = true
+					queue = append(queue, e.To)
+				}
+			}
+		}
+	}
+}
+
+// DFS returns an iterator over the vertices reachable from src in
+// depth-first preorder, src first, descending into neighbours in the order
+// Neighbors reports them. It keeps an explicit stack, so deep graphs do not
+// grow the goroutine's.
+func (g *Graph) DFS(src int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		seen := make([]bool, g.n)
+		seen[src] = true
+		if !yield(src) {
+			return
+		}
+		// Each frame is a vertex and the index of its next edge to try.
+		type frame struct{ v, next int }
+		stack := []frame{{src, 0}}
+		for len(stack) > 0 {
+			top := &stack[len(stack)-1]
+			edges := g.Neighbors(top.v)
+			if top.next == len(edges) {
+				stack = stack[:len(stack)-1]
+				continue
+			}
+			w := edges[top.next].To
+			top.next++
+			if seen[w] {
+				continue
+			}
+			seen[w] = true
+			if !yield(w) {
+				return
+			}
+			stack = append(stack, frame{w, 0})
+		}
+	}
+}
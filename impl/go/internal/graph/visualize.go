@@ -0,0 +1,30 @@
+package graph
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Visualize writes the graph as a Graphviz DOT graph: a digraph if g is
+// directed and an undirected graph otherwise, with every vertex, isolated
+// ones included, and every edge labelled with its weight. Parallel edges are
+// drawn separately.
+func (g *Graph) Visualize(w io.Writer) error {
+	var b strings.Builder
+	kind, arrow := "graph", "--"
+	if g.directed {
+		kind, arrow = "digraph", "->"
+	}
+	fmt.Fprintf(&b, "%s g {\n", kind)
+	b.WriteString("  node [shape=circle, fontname=\"monospace\"];\n")
+	for v := 0; v < g.n; v++ {
+		fmt.Fprintf(&b, "  %d;\n", v)
+	}
+	for _, e := range g.edges {
+		fmt.Fprintf(&b, "  %d %s %d [label=\"%d\"];\n", e.From, arrow, e.To, e.Weight)
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,174 @@
+package hashmap
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/dsa-lab/go/internal/errs"
+)
+
+// minParallelBulk is the fewest pairs BulkLoad spreads across goroutines;
+// below it, starting them costs more than they save.
+const minParallelBulk = 4096
+
+// BulkLoad creates a HashMap holding pairs, as if they were inserted in
+// order into NewWithCapacity with opts, so a key given more than once keeps
+// its last value. The table is allocated once, large enough for every pair
+// with no resize.
+//
+// Hashing and placement run on up to GOMAXPROCS goroutines. The table is
+// split into as many contiguous slot ranges, each filled by one goroutine
+// with the keys whose home slot falls in it; a key whose probe runs off the
+// end of its range is set aside and inserted afterwards, probing into the
+// next range as an ordinary insert would. A hash function or key normalizer
+// given in opts must therefore be safe for concurrent use. Counters,
+// observers, probe quantiles, interning and WithMaxProbe are all stateful,
+// so a map with any of them is loaded with sequential inserts instead.
+//
+// BulkLoad returns an error only if one of opts is invalid.
+func BulkLoad(pairs []Pair, opts ...Option) (*HashMap, error) {
+	m, err := NewWithCapacity(max(len(pairs)*4/3+1, defaultCapacity), opts...)
+	if err != nil {
+		return nil, err
+	}
+	workers := min(runtime.GOMAXPROCS(0), len(pairs)/minParallelBulk)
+	if workers < 2 || !m.parallelSafe() {
+		for _, p := range pairs {
+			m.Insert(p.Key, p.Value)
+		}
+		return m, nil
+	}
+	m.bulkLoad(pairs, workers)
+	return m, nil
+}
+
+// MustBulkLoad is like BulkLoad but panics if an option is invalid.
+func MustBulkLoad(pairs []Pair, opts ...Option) *HashMap {
+	return errs.Must(BulkLoad(pairs, opts...))
+}
+
+// parallelSafe reports whether placing keys touches only their slots, so
+// that goroutines filling disjoint slot ranges do not race.
+func (m *HashMap) parallelSafe() bool {
+	return m.counters == nil && m.observer == nil && len(m.probeQuantiles) == 0 &&
+		m.interner == nil && m.maxProbe == 0
+}
+
+// bulkKey is a pair's normalized key with its hash and home slot.
+type bulkKey struct {
+	key   string
+	value string
+	hash  uint64
+	home  int
+}
+
+func (m *HashMap) bulkLoad(pairs []Pair, workers int) {
+	capacity := len(m.entries)
+	// Range r owns slots [bound(r), bound(r+1)).
+	bound := func(r int) int { return r * capacity / workers }
+	rangeOf := func(home int) int {
+		r := home * workers / capacity
+		for home >= bound(r+1) {
+			r++
+		}
+		for home < bound(r) {
+			r--
+		}
+		return r
+	}
+	chunk := func(w int) []Pair { return pairs[w*len(pairs)/workers : (w+1)*len(pairs)/workers] }
+
+	// Hash each chunk of the input and count its keys per range.
+	keys := make([]bulkKey, len(pairs))
+	counts := make([][]int, workers)
+	parallel(workers, func(w int) {
+		counts[w] = make([]int, workers)
+		offset := w * len(pairs) / workers
+		for i, p := range chunk(w) {
+			key := m.normalize(p.Key)
+			hash := m.hashKey(key)
+			home := m.homeOf(hash, capacity)
+			keys[offset+i] = bulkKey{key, p.Value, hash, home}
+			counts[w][rangeOf(home)]++
+		}
+	})
+
+	// Scatter the keys' indices by range. Chunks write in input order, so
+	// each range sees its keys in input order and the last of equal keys
+	// wins. starts[w][r] is where chunk w writes its next key of range r.
+	starts := make([][]int, workers)
+	for w := range starts {
+		starts[w] = make([]int, workers)
+	}
+	next := 0
+	for r := 0; r < workers; r++ {
+		for w := 0; w < workers; w++ {
+			starts[w][r] = next
+			next += counts[w][r]
+		}
+	}
+	byRange := make([]int32, len(keys))
+	parallel(workers, func(w int) {
+		offset := w * len(pairs) / workers
+		for i := offset; i < offset+len(chunk(w)); i++ {
+			r := rangeOf(keys[i].home)
+			byRange[starts[w][r]] = int32(i)
+			starts[w][r]++
+		}
+	})
+
+	// Fill each range, setting aside the keys that run off its end.
+	added := make([]int, workers)
+	overflow := make([][]bulkKey, workers)
+	parallel(workers, func(r int) {
+		lo := 0
+		if r > 0 {
+			lo = starts[workers-1][r-1]
+		}
+		end := bound(r + 1)
+	place:
+		for _, i := range byRange[lo:starts[workers-1][r]] {
+			k := &keys[i]
+			for slot := k.home; slot < end; slot++ {
+				e := &m.entries[slot]
+				if e.state == empty {
+					m.store(slot, k.key, k.value, k.hash)
+					added[r]++
+					continue place
+				}
+				if (m.hashes == nil || m.hashes[slot] == k.hash) && e.key == k.key {
+					e.value = k.value
+					continue place
+				}
+			}
+			overflow[r] = append(overflow[r], *k)
+		}
+	})
+	for _, n := range added {
+		m.size += n
+	}
+	for _, spilled := range overflow {
+		for _, k := range spilled {
+			index, found := m.findSlotIn(m.entries, m.hashes, k.key, k.hash)
+			if found {
+				m.entries[index].value = k.value
+				continue
+			}
+			m.store(index, k.key, k.value, k.hash)
+			m.size++
+		}
+	}
+}
+
+// parallel runs f(0) through f(n-1) on n goroutines and waits for them.
+func parallel(n int, f func(i int)) {
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			defer wg.Done()
+			f(i)
+		}(i)
+	}
+	wg.Wait()
+}
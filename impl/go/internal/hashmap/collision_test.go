@@ -0,0 +1,293 @@
+package hashmap
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// withHasher injects a deterministic hash so tests can place keys precisely.
+func withHasher(h func(key string) uint64) Option {
+	return func(m *HashMap) {
+		m.hasher = h
+	}
+}
+
+// homes returns a hasher mapping each listed key to the given slot; other
+// keys hash to 0.
+func homes(slots map[string]uint64) func(string) uint64 {
+	return func(key string) uint64 {
+		return slots[key]
+	}
+}
+
+func slotOf(m *HashMap, key string) int {
+	for i, e := range m.entries {
+		if e.state == occupied && e.key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestAllKeysOneBucket(t *testing.T) {
+	m := MustNew(withHasher(func(string) uint64 { return 7 }))
+	for i := 0; i < 11; i++ {
+		m.Insert(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+
+	for i := 0; i < 11; i++ {
+		key := fmt.Sprintf("key%d", i)
+		if want := (7 + i) % 16; slotOf(m, key) != want {
+			t.Errorf("%s at slot %d, want %d", key, slotOf(m, key), want)
+		}
+	}
+	if s := m.Stats(); s.MaxProbe != 11 || s.Clusters != 1 || s.LongestCluster != 11 {
+		t.Errorf("unexpected stats for a single cluster: %+v", s)
+	}
+
+	m.Remove("key0")
+	m.Remove("key5")
+	for i := 0; i < 11; i++ {
+		key := fmt.Sprintf("key%d", i)
+		_, found := m.Get(key)
+		if want := i != 0 && i != 5; found != want {
+			t.Errorf("get(%s) found=%v, want %v", key, found, want)
+		}
+	}
+	if err := m.CheckInvariants(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWrapAroundTableEnd(t *testing.T) {
+	m := MustNew(withHasher(homes(map[string]uint64{"a": 14, "b": 14, "c": 15, "d": 14, "e": 14})))
+	for _, key := range []string{"a", "b", "c", "d"} {
+		m.Insert(key, key)
+	}
+
+	for key, want := range map[string]int{"a": 14, "b": 15, "c": 0, "d": 1} {
+		if got := slotOf(m, key); got != want {
+			t.Errorf("%s at slot %d, want %d", key, got, want)
+		}
+	}
+
+	// Removing the entries at the table end must keep the wrapped ones reachable.
+	m.Remove("b")
+	m.Remove("a")
+	for _, key := range []string{"c", "d"} {
+		if value, found := m.Get(key); !found || value != key {
+			t.Errorf("get(%s) = %q, %v after removals at table end", key, value, found)
+		}
+	}
+
+	// A new key homed at the end reuses the first tombstone, not a wrapped slot.
+	m.Insert("e", "e")
+	if got := slotOf(m, "e"); got != 14 {
+		t.Errorf("e at slot %d, want tombstone at 14", got)
+	}
+	if err := m.CheckInvariants(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClusterSpanningTombstones(t *testing.T) {
+	hash := homes(map[string]uint64{"a": 3, "b": 3, "c": 3, "d": 3, "e": 3})
+	m := MustNew(withHasher(hash))
+	for _, key := range []string{"a", "b", "c", "d"} {
+		m.Insert(key, key)
+	}
+	m.Remove("b")
+	m.Remove("c")
+
+	if value, found := m.Get("d"); !found || value != "d" {
+		t.Fatalf("get(d) = %q, %v; lookups must skip tombstones", value, found)
+	}
+	if m.Contains("e") {
+		t.Error("missing key should not be found across tombstones")
+	}
+
+	m.Insert("e", "e")
+	if got := slotOf(m, "e"); got != 4 {
+		t.Errorf("e at slot %d, want first tombstone at 4", got)
+	}
+	if m.tombstones != 1 {
+		t.Errorf("expected 1 tombstone after reuse, got %d", m.tombstones)
+	}
+
+	// Re-inserting an existing key past a tombstone updates it in place.
+	if old, existed := m.Insert("d", "d2"); !existed || old != "d" {
+		t.Errorf("overwrite of d returned %q, %v", old, existed)
+	}
+	if m.Len() != 3 {
+		t.Errorf("expected length 3, got %d", m.Len())
+	}
+	if err := m.CheckInvariants(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFullTableOfTombstones(t *testing.T) {
+	m := MustNew(withHasher(func(string) uint64 { return 5 }))
+	for i := range m.entries {
+		m.entries[i] = entry{state: tombstone}
+	}
+	m.tombstones = len(m.entries)
+
+	// With no empty slot, probing must stop after one pass.
+	if _, found := m.Get("missing"); found {
+		t.Error("get should not find keys in a table of tombstones")
+	}
+	if _, existed := m.Remove("missing"); existed {
+		t.Error("remove should not find keys in a table of tombstones")
+	}
+	if index, found := m.findSlot("missing"); found || index != 5 {
+		t.Errorf("findSlot = %d, %v; want first tombstone at home slot 5", index, found)
+	}
+
+	// Inserting triggers a rebuild that discards every tombstone.
+	m.Insert("key", "value")
+	if m.tombstones != 0 || m.Len() != 1 {
+		t.Errorf("expected tombstones cleared, got %d tombstones, length %d", m.tombstones, m.Len())
+	}
+	if value, _ := m.Get("key"); value != "value" {
+		t.Errorf("expected value, got %q", value)
+	}
+	if err := m.CheckInvariants(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFullTableOccupiedAndTombstones(t *testing.T) {
+	m := MustNew(withHasher(func(key string) uint64 { return uint64(len(key)) }))
+	for i := range m.entries {
+		if i%4 == 0 {
+			m.entries[i] = entry{state: tombstone}
+			m.tombstones++
+			continue
+		}
+		m.entries[i] = entry{state: occupied, key: fmt.Sprintf("%0*d", i, 0), value: "v"}
+		m.size++
+	}
+
+	if m.Contains("a-key-that-is-missing") {
+		t.Error("missing key should not be found in a table without empty slots")
+	}
+	// The key is homed at slot 21 % 16 = 5; the first tombstone after it is 8.
+	if index, found := m.findSlot("a-key-that-is-missing"); found || index != 8 {
+		t.Errorf("findSlot = %d, %v; want first tombstone encountered at 8", index, found)
+	}
+	if value, found := m.Get("000"); !found || value != "v" {
+		t.Errorf("get(000) = %q, %v", value, found)
+	}
+}
+
+func TestDump(t *testing.T) {
+	m := MustNew(withHasher(homes(map[string]uint64{"a": 2, "b": 2, "c": 9})))
+	m.Insert("a", "1")
+	m.Insert("b", "2")
+	m.Insert("c", "3")
+	m.Remove("a")
+
+	var buf strings.Builder
+	if err := m.Dump(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `table: 16 slots
+  [0-1] empty
+  [2] tombstone
+  [3] "b" = "2" (home 2, distance 1)
+  [4-8] empty
+  [9] "c" = "3" (home 9, distance 0)
+  [10-15] empty
+`
+	if buf.String() != want {
+		t.Errorf("unexpected dump:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestVisualize(t *testing.T) {
+	m := MustNew(withHasher(homes(map[string]uint64{"a": 2, "b": 2, "<c>": 9})))
+	m.Insert("a", "1")
+	m.Insert("b", "2")
+	m.Insert("<c>", "3")
+	m.Remove("a")
+
+	var buf strings.Builder
+	if err := m.Visualize(&buf); err != nil {
+		t.Fatal(err)
+	}
+	dot := buf.String()
+	for _, want := range []string{
+		"digraph hashmap {",
+		`<TD PORT="s2" BGCOLOR="gray80">`,
+		"table:s2:s -> table:s3:s [style=dashed",
+		"&lt;c&gt;",
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("expected DOT containing %q, got:\n%s", want, dot)
+		}
+	}
+	if strings.Contains(dot, "table:s9:s") {
+		t.Errorf("entry at its home slot should have no edge:\n%s", dot)
+	}
+}
+
+func TestObserverProbeEvents(t *testing.T) {
+	var events []string
+	record := ObserverFunc(func(e Event) {
+		events = append(events, fmt.Sprintf("%s %s@%d/%d", e.Kind, e.Key, e.Slot, e.Step))
+	})
+	m := MustNew(
+		withHasher(homes(map[string]uint64{"a": 2, "b": 2, "c": 2})),
+		WithObserver(record),
+	)
+	m.Insert("a", "1")
+	m.Insert("b", "2")
+	m.Remove("a")
+	events = nil
+
+	m.Insert("c", "3")
+	want := []string{
+		"probe c@2/0",
+		"probe c@3/1",
+		"collision c@3/1",
+		"probe c@4/2",
+		"tombstone-reuse c@2/0",
+	}
+	if fmt.Sprint(events) != fmt.Sprint(want) {
+		t.Errorf("expected events %v, got %v", want, events)
+	}
+}
+
+func TestObserverResizeEvents(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithIncrementalResize(4)}} {
+		var resizes []Event
+		observer := ObserverFunc(func(e Event) {
+			if e.Kind == EventResizeStart || e.Kind == EventResizeEnd {
+				resizes = append(resizes, e)
+			}
+		})
+		m := MustNew(append(opts, WithObserver(observer))...)
+		for i := 0; i < 100; i++ {
+			m.Insert(fmt.Sprintf("key_%d", i), "v")
+		}
+		for i := 0; i < 100 && m.Resizing(); i++ {
+			m.Get("key_0")
+		}
+
+		// 16 -> 32 -> 64 -> 128 -> 256, each start followed by its end.
+		if len(resizes) != 8 {
+			t.Fatalf("expected 8 resize events, got %d: %v", len(resizes), resizes)
+		}
+		for i, e := range resizes {
+			wantKind := EventResizeStart
+			if i%2 == 1 {
+				wantKind = EventResizeEnd
+			}
+			if e.Kind != wantKind || e.Capacity != 32<<(i/2) {
+				t.Errorf("event %d: expected %s to %d, got %s to %d", i, wantKind, 32<<(i/2), e.Kind, e.Capacity)
+			}
+		}
+	}
+}
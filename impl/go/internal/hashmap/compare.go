@@ -0,0 +1,49 @@
+package hashmap
+
+import "slices"
+
+// Equal reports whether a and b hold the same key-value pairs, regardless of
+// capacity, layout or in-progress resizing.
+func Equal(a, b *HashMap) bool {
+	return EqualFunc(a, b, func(x, y string) bool { return x == y })
+}
+
+// EqualFunc is Equal with values compared by eq, for values that have
+// several spellings, such as numbers or JSON documents. Keys are matched
+// through b's key normalizer, if it has one.
+func EqualFunc(a, b *HashMap, eq func(x, y string) bool) bool {
+	if a.Len() != b.Len() {
+		return false
+	}
+	equal := true
+	a.Range(func(key, value string) bool {
+		other, found := b.lookup(key)
+		equal = found && eq(value, other)
+		return equal
+	})
+	return equal
+}
+
+// Diff compares a and b by key. onlyA lists keys present only in a, onlyB
+// keys present only in b, and changed keys present in both with different
+// values. Each slice is sorted.
+func Diff(a, b *HashMap) (onlyA, onlyB, changed []string) {
+	a.Range(func(key, value string) bool {
+		if other, found := b.lookup(key); !found {
+			onlyA = append(onlyA, key)
+		} else if other != value {
+			changed = append(changed, key)
+		}
+		return true
+	})
+	b.Range(func(key, _ string) bool {
+		if !a.Contains(key) {
+			onlyB = append(onlyB, key)
+		}
+		return true
+	})
+	slices.Sort(onlyA)
+	slices.Sort(onlyB)
+	slices.Sort(changed)
+	return onlyA, onlyB, changed
+}
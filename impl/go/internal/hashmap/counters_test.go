@@ -0,0 +1,140 @@
+package hashmap
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"testing"
+)
+
+func TestCountersDisabledByDefault(t *testing.T) {
+	m := MustNew()
+	m.Insert("a", "1")
+	m.Get("a")
+	if c := m.Counters(); c != (Counters{}) {
+		t.Errorf("expected zero counters, got %+v", c)
+	}
+}
+
+func TestCountersTrackWork(t *testing.T) {
+	m := MustNew(WithCounters())
+	m.Insert("a", "1")
+	m.Get("a")
+	m.Get("missing")
+
+	c := m.Counters()
+	if c.Lookups != 3 || c.Hashes != 3 {
+		t.Errorf("expected 3 lookups and hashes, got %+v", c)
+	}
+	if c.Probes < 3 || c.Comparisons < 1 {
+		t.Errorf("unexpected probe counts %+v", c)
+	}
+
+	m.ResetCounters()
+	for i := 0; i < 13; i++ {
+		m.Insert(fmt.Sprintf("key%d", i), "v")
+	}
+	if moves := m.Counters().Moves; moves != 12 {
+		t.Errorf("expected 12 moves from one resize, got %d", moves)
+	}
+}
+
+// TestProbeCountsMatchLinearProbingModel measures average probe lengths at a
+// range of load factors and compares them with Knuth's analysis of linear
+// probing: a successful search examines about (1 + 1/(1-α)) / 2 slots and an
+// unsuccessful one about (1 + 1/(1-α)²) / 2, so cost grows as O(1/(1-α)).
+// A least-squares fit of successful probes against 1/(1-α) must recover the
+// model's slope and intercept of 1/2.
+func TestProbeCountsMatchLinearProbingModel(t *testing.T) {
+	const capacity = 1 << 15
+	var xs, ys []float64
+
+	for _, alpha := range []float64{0.1, 0.25, 0.4, 0.5, 0.6, 0.7} {
+		m := MustNewWithCapacity(capacity, WithCounters())
+		n := int(alpha * capacity)
+		for i := 0; i < n; i++ {
+			m.Insert(fmt.Sprintf("key_%d", i), "v")
+		}
+		if m.Capacity() != capacity {
+			t.Fatalf("alpha=%.2f: table resized to %d", alpha, m.Capacity())
+		}
+
+		m.ResetCounters()
+		for i := 0; i < n; i++ {
+			m.Get(fmt.Sprintf("key_%d", i))
+		}
+		hit := float64(m.Counters().Probes) / float64(n)
+
+		m.ResetCounters()
+		for i := 0; i < n; i++ {
+			m.Get(fmt.Sprintf("miss_%d", i))
+		}
+		miss := float64(m.Counters().Probes) / float64(n)
+
+		wantHit := (1 + 1/(1-alpha)) / 2
+		wantMiss := (1 + 1/((1-alpha)*(1-alpha))) / 2
+		t.Logf("alpha=%.2f hit=%.3f (model %.3f) miss=%.3f (model %.3f)", alpha, hit, wantHit, miss, wantMiss)
+
+		if math.Abs(hit-wantHit)/wantHit > 0.15 {
+			t.Errorf("alpha=%.2f: successful probes %.3f, model %.3f", alpha, hit, wantHit)
+		}
+		if math.Abs(miss-wantMiss)/wantMiss > 0.25 {
+			t.Errorf("alpha=%.2f: unsuccessful probes %.3f, model %.3f", alpha, miss, wantMiss)
+		}
+		xs = append(xs, 1/(1-alpha))
+		ys = append(ys, hit)
+	}
+
+	slope, intercept := fitLine(xs, ys)
+	t.Logf("fit: probes = %.3f * 1/(1-α) + %.3f", slope, intercept)
+	if math.Abs(slope-0.5) > 0.1 || math.Abs(intercept-0.5) > 0.1 {
+		t.Errorf("fitted probes = %.3f/(1-α) + %.3f, model 0.5/(1-α) + 0.5", slope, intercept)
+	}
+}
+
+// fitLine returns the least-squares slope and intercept of ys against xs.
+func fitLine(xs, ys []float64) (slope, intercept float64) {
+	n := float64(len(xs))
+	var sx, sy, sxx, sxy float64
+	for i := range xs {
+		sx += xs[i]
+		sy += ys[i]
+		sxx += xs[i] * xs[i]
+		sxy += xs[i] * ys[i]
+	}
+	slope = (n*sxy - sx*sy) / (n*sxx - sx*sx)
+	intercept = (sy - slope*sx) / n
+	return slope, intercept
+}
+
+func TestProbeQuantiles(t *testing.T) {
+	const capacity = 1 << 15
+	m := MustNewWithCapacity(capacity, WithCounters())
+	n := capacity * 7 / 10
+	for i := 0; i < n; i++ {
+		m.Insert(fmt.Sprintf("key_%d", i), "v")
+	}
+
+	// Track only the misses that follow, whose long tail at α=0.7 sets p99
+	// well apart from the median, and record their exact lengths alongside.
+	WithProbeQuantiles(0.5, 0.99)(m)
+	if _, ok := m.ProbeQuantile(0.9); ok {
+		t.Error("expected untracked quantile to be unavailable")
+	}
+	var lengths []float64
+	for i := 0; i < n; i++ {
+		before := m.Counters().Probes
+		m.Get(fmt.Sprintf("miss_%d", i))
+		lengths = append(lengths, float64(m.Counters().Probes-before))
+	}
+	sort.Float64s(lengths)
+
+	for _, q := range []float64{0.5, 0.99} {
+		exact := lengths[int(q*float64(len(lengths)-1))]
+		got, ok := m.ProbeQuantile(q)
+		t.Logf("q=%v: estimate %.1f, exact %.0f", q, got, exact)
+		if !ok || math.Abs(got-exact) > 0.15*exact+1 {
+			t.Errorf("q=%v: estimate %.1f far from exact %.0f", q, got, exact)
+		}
+	}
+}
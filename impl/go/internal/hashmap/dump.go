@@ -0,0 +1,52 @@
+package hashmap
+
+import (
+	"fmt"
+	"io"
+)
+
+// Dump writes the slot layout of the table, one line per non-empty slot with
+// its home slot and probe distance. Runs of empty slots are collapsed. During
+// an incremental resize the old table is dumped after the current one.
+func (m *HashMap) Dump(w io.Writer) error {
+	for _, table := range []struct {
+		name    string
+		entries []entry
+	}{{"table", m.entries}, {"old table", m.old}} {
+		if table.entries == nil {
+			continue
+		}
+		capacity := len(table.entries)
+		if _, err := fmt.Fprintf(w, "%s: %d slots\n", table.name, capacity); err != nil {
+			return err
+		}
+
+		for i := 0; i < capacity; i++ {
+			e := table.entries[i]
+			var err error
+			switch e.state {
+			case empty:
+				j := i
+				for j+1 < capacity && table.entries[j+1].state == empty {
+					j++
+				}
+				if j > i {
+					_, err = fmt.Fprintf(w, "  [%d-%d] empty\n", i, j)
+				} else {
+					_, err = fmt.Fprintf(w, "  [%d] empty\n", i)
+				}
+				i = j
+			case tombstone:
+				_, err = fmt.Fprintf(w, "  [%d] tombstone\n", i)
+			case occupied:
+				home := m.homeOf(m.hashKey(e.key), capacity)
+				probe := (i - home + capacity) % capacity
+				_, err = fmt.Fprintf(w, "  [%d] %q = %q (home %d, distance %d)\n", i, e.key, e.value, home, probe)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
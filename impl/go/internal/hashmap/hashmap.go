@@ -2,12 +2,23 @@
 package hashmap
 
 import (
+	"fmt"
+	"slices"
+
 	"github.com/cespare/xxhash/v2"
+
+	"github.com/dsa-lab/go/internal/errs"
+	"github.com/dsa-lab/go/internal/hashseed"
+	"github.com/dsa-lab/go/internal/intern"
+	"github.com/dsa-lab/go/internal/sketch"
 )
 
 const (
 	defaultCapacity = 16
-	maxLoadFactor   = 0.75
+	// minCapacity is the smallest table that, grown at maxLoadFactor, always
+	// keeps an empty slot to end probes.
+	minCapacity   = 4
+	maxLoadFactor = 0.75
 )
 
 // entryState represents the state of an entry in the hash map.
@@ -32,23 +43,230 @@ type HashMap struct {
 	entries    []entry
 	size       int
 	tombstones int
+
+	// hashes parallels entries with each slot's full key hash when the map
+	// was created with WithCachedHashes, and is nil otherwise.
+	cacheHashes bool
+	hashes      []uint64
+
+	// counters is non-nil when operation counting is enabled.
+	counters *Counters
+
+	// resizes counts table replacements, growth or rebuild, over the map's
+	// lifetime. Unlike counters it is always kept: it changes rarely and
+	// monitoring wants it without paying for per-probe counting.
+	resizes int
+
+	// probeQuantiles estimate quantiles of the probe length of every lookup
+	// when WithProbeQuantiles is set.
+	probeQuantiles []*sketch.P2
+
+	// observer, when set, receives internal events (see WithObserver).
+	observer Observer
+
+	// seed, when non-zero, is mixed into every hash before it picks a home
+	// slot. It is drawn afresh by each rebuild triggered by maxProbe.
+	seed uint64
+
+	// maxProbe, when positive, is the longest displacement an insert may
+	// leave before the table is rebuilt; sinceRebuild counts inserts since
+	// the last such rebuild.
+	maxProbe     int
+	sinceRebuild int
+
+	// interner, when set, supplies the stored copy of every new key and of
+	// every value (see WithInterner).
+	interner *intern.Interner
+
+	// normalizer, when set, rewrites every key before it is hashed or
+	// stored (see WithKeyNormalizer).
+	normalizer func(key string) string
+
+	// hasher overrides the key hash (see WithHashFunc). Tests also set it
+	// directly to force specific collision patterns.
+	hasher func(key string) uint64
+
+	// popCursor is the slot where the last PopAny found an entry, so that
+	// draining the map scans each slot once rather than once per call.
+	popCursor int
+
+	// Incremental rehashing state. While a resize is in progress, old holds
+	// the previous table and migrated is the index of the next slot to move.
+	// Every live key is stored in exactly one of the two tables.
+	rehashStep int
+	old        []entry
+	oldHashes  []uint64
+	oldLive    int
+	migrated   int
+
+	// optErr records the first invalid option applied, for NewWithCapacity
+	// to return.
+	optErr error
+}
+
+// Option configures a HashMap at construction time. An option given an
+// invalid argument makes the constructor return an error matching
+// errs.ErrInvalidOption.
+type Option func(*HashMap)
+
+// invalid records that an option was given a bad argument, described by
+// format and args. Only the first such option is reported.
+func (m *HashMap) invalid(format string, args ...any) {
+	if m.optErr == nil {
+		m.optErr = fmt.Errorf("hashmap: "+format+": %w", append(args, errs.ErrInvalidOption)...)
+	}
+}
+
+// WithIncrementalResize enables incremental rehashing. Instead of moving every
+// entry at once when the table grows, each subsequent operation migrates up to
+// step slots from the old table, bounding the worst-case cost of a single
+// operation. A step of at least 4 guarantees that migration finishes before
+// the new table itself needs to grow. The step must be at least 1.
+func WithIncrementalResize(step int) Option {
+	return func(m *HashMap) {
+		if step < 1 {
+			m.invalid("WithIncrementalResize(%d)", step)
+			return
+		}
+		m.rehashStep = step
+	}
+}
+
+// WithCachedHashes stores each entry's 64-bit key hash alongside the table.
+// Resizing then moves entries without rehashing their keys, and probing
+// compares hashes before keys, at the cost of 8 bytes per slot.
+func WithCachedHashes() Option {
+	return func(m *HashMap) {
+		m.cacheHashes = true
+	}
+}
+
+// WithMaxProbe bounds the probe length of inserted keys. When an insert
+// leaves its key more than n slots from its home, the table is rebuilt under
+// a fresh random hash seed, doubling the capacity if the map is at least half
+// of its maximum load. A run of keys crafted to collide under one seed is
+// scattered by the next, so lookups stay short even against adversarial
+// input. Rebuilds happen at most once per Len()/2 inserts, which keeps their
+// cost amortized O(1); n should sit well above the probe lengths expected
+// from ordinary clustering, and must be at least 1.
+func WithMaxProbe(n int) Option {
+	return func(m *HashMap) {
+		if n < 1 {
+			m.invalid("WithMaxProbe(%d)", n)
+			return
+		}
+		m.maxProbe = n
+	}
+}
+
+// WithInterner stores keys and values through in, so a string inserted many
+// times, or held by several maps sharing in, is kept as one copy. This pays
+// off when keys arrive as fresh allocations, such as fields parsed from a log;
+// each insert costs an extra lookup in the interner. A nil in gives the map a
+// private Interner.
+func WithInterner(in *intern.Interner) Option {
+	return func(m *HashMap) {
+		if in == nil {
+			in = intern.New()
+		}
+		m.interner = in
+	}
+}
+
+// WithHashFunc hashes keys with hash instead of xxhash. A keyed hash such as
+// hashseed.SipHash keeps an adversary who controls the keys but not the key
+// from choosing colliding ones; it costs a few nanoseconds more per key.
+func WithHashFunc(hash func(key string) uint64) Option {
+	return func(m *HashMap) {
+		m.hasher = hash
+	}
+}
+
+// Counters tallies the primitive work performed by the map, so complexity
+// claims can be checked empirically.
+type Counters struct {
+	// Lookups is the number of probe sequences started.
+	Lookups uint64
+	// Hashes is the number of key hash computations.
+	Hashes uint64
+	// Probes is the number of slots examined across all probe sequences.
+	Probes uint64
+	// Comparisons is the number of key equality comparisons.
+	Comparisons uint64
+	// Moves is the number of entries relocated by resizing.
+	Moves uint64
+	// Rebuilds is the number of rebuilds triggered by WithMaxProbe.
+	Rebuilds uint64
+}
+
+// WithCounters enables operation counting, readable via Counters. Counting
+// adds a small constant overhead per operation and is off by default.
+func WithCounters() Option {
+	return func(m *HashMap) {
+		m.counters = &Counters{}
+	}
+}
+
+// WithProbeQuantiles tracks streaming estimates of the given quantiles of
+// probe length, the number of slots a lookup examines, over the map's
+// lifetime; read them with ProbeQuantile. Each quantile costs a P² sketch of
+// constant size, so p99 probe length is available without storing every
+// sample. With no arguments it tracks the median, p90 and p99. Every
+// quantile must lie in (0, 1).
+func WithProbeQuantiles(qs ...float64) Option {
+	if len(qs) == 0 {
+		qs = []float64{0.5, 0.9, 0.99}
+	}
+	return func(m *HashMap) {
+		m.probeQuantiles = m.probeQuantiles[:0]
+		for _, q := range qs {
+			p, err := sketch.NewP2(q)
+			if err != nil {
+				m.invalid("WithProbeQuantiles(%v)", q)
+				return
+			}
+			m.probeQuantiles = append(m.probeQuantiles, p)
+		}
+	}
+}
+
+// New creates a new empty HashMap. It returns an error only if one of opts
+// is invalid.
+func New(opts ...Option) (*HashMap, error) {
+	return NewWithCapacity(defaultCapacity, opts...)
 }
 
-// New creates a new empty HashMap.
-func New() *HashMap {
-	return NewWithCapacity(defaultCapacity)
+// MustNew is like New but panics if an option is invalid.
+func MustNew(opts ...Option) *HashMap {
+	return errs.Must(New(opts...))
 }
 
-// NewWithCapacity creates a new HashMap with the specified capacity.
-func NewWithCapacity(capacity int) *HashMap {
-	if capacity < defaultCapacity {
-		capacity = defaultCapacity
+// NewWithCapacity creates a new HashMap whose table starts with exactly
+// capacity slots. A capacity below 4, the smallest table that always keeps an
+// empty slot, or an invalid option is an error matching errs.ErrInvalidOption.
+func NewWithCapacity(capacity int, opts ...Option) (*HashMap, error) {
+	if capacity < minCapacity {
+		return nil, fmt.Errorf("hashmap: capacity %d: %w", capacity, errs.ErrInvalidOption)
 	}
-	return &HashMap{
+	m := &HashMap{
 		entries:    make([]entry, capacity),
 		size:       0,
 		tombstones: 0,
 	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	if m.optErr != nil {
+		return nil, m.optErr
+	}
+	m.hashes = m.newHashes(len(m.entries))
+	return m, nil
+}
+
+// MustNewWithCapacity is like NewWithCapacity but panics if capacity or an
+// option is invalid.
+func MustNewWithCapacity(capacity int, opts ...Option) *HashMap {
+	return errs.Must(NewWithCapacity(capacity, opts...))
 }
 
 // Len returns the number of elements in the map.
@@ -66,25 +284,112 @@ func (m *HashMap) Capacity() int {
 	return len(m.entries)
 }
 
+// hash computes the hash of key for an operation, counting it.
+func (m *HashMap) hash(key string) uint64 {
+	if m.counters != nil {
+		m.counters.Hashes++
+	}
+	return m.hashKey(key)
+}
+
 func (m *HashMap) hashKey(key string) uint64 {
+	if m.hasher != nil {
+		return m.hasher(key)
+	}
 	return xxhash.Sum64String(key)
 }
 
+// homeOf returns the home slot of a key hash in a table of capacity slots.
+// With a seed the hash is passed through the splitmix64 finalizer, so keys
+// whose hashes agree in their low bits no longer share a home.
+func (m *HashMap) homeOf(hash uint64, capacity int) int {
+	if m.seed != 0 {
+		hash = hashseed.Mix(hash ^ m.seed)
+	}
+	return int(hash % uint64(capacity))
+}
+
+// Resizing reports whether an incremental resize is in progress.
+func (m *HashMap) Resizing() bool {
+	return m.old != nil
+}
+
+// Resizes returns the number of times the table has been replaced, by
+// growing or by a rebuild under WithMaxProbe, since the map was created.
+func (m *HashMap) Resizes() int {
+	return m.resizes
+}
+
+// Counters returns a snapshot of the operation counters. It returns zero
+// counters unless the map was created with WithCounters.
+func (m *HashMap) Counters() Counters {
+	if m.counters == nil {
+		return Counters{}
+	}
+	return *m.counters
+}
+
+// ResetCounters zeroes the operation counters.
+func (m *HashMap) ResetCounters() {
+	if m.counters != nil {
+		*m.counters = Counters{}
+	}
+}
+
+// ProbeQuantile returns the estimated q-quantile of probe length and true if
+// q was requested with WithProbeQuantiles. It is NaN before any lookup.
+func (m *HashMap) ProbeQuantile(q float64) (float64, bool) {
+	for _, p := range m.probeQuantiles {
+		if p.Q() == q {
+			return p.Quantile(), true
+		}
+	}
+	return 0, false
+}
+
+func (m *HashMap) recordProbe(probes, comparisons int) {
+	if c := m.counters; c != nil {
+		c.Lookups++
+		c.Probes += uint64(probes)
+		c.Comparisons += uint64(comparisons)
+	}
+	for _, p := range m.probeQuantiles {
+		p.Add(float64(probes))
+	}
+}
+
 func (m *HashMap) loadFactor() float64 {
-	return float64(m.size+m.tombstones) / float64(len(m.entries))
+	return float64(m.size-m.oldLive+m.tombstones) / float64(len(m.entries))
 }
 
 func (m *HashMap) findSlot(key string) (int, bool) {
-	hash := m.hashKey(key)
-	capacity := len(m.entries)
-	index := int(hash % uint64(capacity))
+	return m.findSlotIn(m.entries, m.hashes, key, m.hash(key))
+}
+
+// newHashes allocates the hash cache for a table of capacity slots, or
+// returns nil when hashes are not cached.
+func (m *HashMap) newHashes(capacity int) []uint64 {
+	if !m.cacheHashes {
+		return nil
+	}
+	return make([]uint64, capacity)
+}
+
+// findSlotIn probes entries for key, whose hash is hash. hashes is the
+// table's hash cache, or nil when hashes are not cached.
+func (m *HashMap) findSlotIn(entries []entry, hashes []uint64, key string, hash uint64) (int, bool) {
+	capacity := len(entries)
+	index := m.homeOf(hash, capacity)
 	firstTombstone := -1
+	comparisons := 0
 
 	for i := 0; i < capacity; i++ {
-		e := &m.entries[index]
+		e := &entries[index]
+		m.emit(EventProbe, key, index, i, capacity)
 
 		switch e.state {
 		case empty:
+			m.recordProbe(i+1, comparisons)
 			if firstTombstone >= 0 {
 				return firstTombstone, false
 			}
@@ -96,14 +401,21 @@ func (m *HashMap) findSlot(key string) (int, bool) {
 			}
 
 		case occupied:
+			if hashes != nil && hashes[index] != hash {
+				break
+			}
+			comparisons++
 			if e.key == key {
+				m.recordProbe(i+1, comparisons)
 				return index, true
 			}
+			m.emit(EventCollision, key, index, i, capacity)
 		}
 
 		index = (index + 1) % capacity
 	}
 
+	m.recordProbe(capacity, comparisons)
 	if firstTombstone >= 0 {
 		return firstTombstone, false
 	}
@@ -111,28 +423,144 @@ func (m *HashMap) findSlot(key string) (int, bool) {
 }
 
 func (m *HashMap) resize() {
-	newCapacity := len(m.entries) * 2
-	oldEntries := m.entries
+	if m.rehashStep > 0 {
+		m.startIncrementalResize()
+		return
+	}
 
-	m.entries = make([]entry, newCapacity)
-	m.size = 0
+	m.rehash(len(m.entries) * 2)
+}
+
+// rehash moves every entry into a new table of capacity slots at once.
+func (m *HashMap) rehash(capacity int) {
+	oldEntries, oldHashes := m.entries, m.hashes
+	m.resizes++
+	m.emit(EventResizeStart, "", -1, 0, capacity)
+
+	m.entries = make([]entry, capacity)
+	m.hashes = m.newHashes(capacity)
+	m.tombstones = 0
+
+	for i, e := range oldEntries {
+		if e.state == occupied {
+			m.move(e, oldHashes, i)
+		}
+	}
+	m.emit(EventResizeEnd, "", -1, 0, capacity)
+}
+
+// checkProbe rebuilds the table under a new seed when the key just stored at
+// index sits more than maxProbe slots from its home.
+func (m *HashMap) checkProbe(index int, hash uint64) {
+	m.sinceRebuild++
+	capacity := len(m.entries)
+	if (index-m.homeOf(hash, capacity)+capacity)%capacity+1 <= m.maxProbe || m.sinceRebuild <= m.size/2 {
+		return
+	}
+
+	if m.old != nil {
+		m.migrate(len(m.old))
+	}
+	m.seed = hashseed.New()
+	m.sinceRebuild = 0
+	if m.counters != nil {
+		m.counters.Rebuilds++
+	}
+	if m.loadFactor() >= maxLoadFactor/2 {
+		capacity *= 2
+	}
+	m.rehash(capacity)
+}
+
+func (m *HashMap) startIncrementalResize() {
+	if m.old != nil {
+		m.migrate(len(m.old))
+	}
+
+	m.old, m.oldHashes = m.entries, m.hashes
+	m.oldLive = m.size
+	m.migrated = 0
+	m.entries = make([]entry, len(m.old)*2)
+	m.hashes = m.newHashes(len(m.entries))
 	m.tombstones = 0
+	m.resizes++
+	m.emit(EventResizeStart, "", -1, 0, len(m.entries))
+}
 
-	for _, e := range oldEntries {
+// migrate moves up to n slots from the old table into the current one.
+// Migrated slots become tombstones so probe chains in the old table stay intact.
+func (m *HashMap) migrate(n int) {
+	for ; n > 0 && m.old != nil; n-- {
+		e := &m.old[m.migrated]
 		if e.state == occupied {
-			m.Insert(e.key, e.value)
+			m.move(*e, m.oldHashes, m.migrated)
+			*e = entry{state: tombstone}
+			m.oldLive--
 		}
+		m.migrated++
+
+		if m.oldLive == 0 || m.migrated == len(m.old) {
+			m.old, m.oldHashes = nil, nil
+			m.migrated = 0
+			m.emit(EventResizeEnd, "", -1, 0, len(m.entries))
+		}
+	}
+}
+
+// move places e, which sat at slot i of a previous table with hash cache
+// hashes, into the current table. The key is rehashed only when hashes
+// are not cached.
+func (m *HashMap) move(e entry, hashes []uint64, i int) {
+	var hash uint64
+	if hashes != nil {
+		hash = hashes[i]
+	} else {
+		hash = m.hash(e.key)
+	}
+	index, _ := m.findSlotIn(m.entries, m.hashes, e.key, hash)
+	m.store(index, e.key, e.value, hash)
+	if m.counters != nil {
+		m.counters.Moves++
+	}
+}
+
+// store writes a new key into the empty or tombstone slot index of the
+// current table. It does not change size.
+func (m *HashMap) store(index int, key, value string, hash uint64) {
+	if m.entries[index].state == tombstone {
+		m.tombstones--
+		m.emit(EventTombstoneReuse, key, index, 0, len(m.entries))
+	}
+	m.entries[index] = entry{state: occupied, key: key, value: value}
+	if m.hashes != nil {
+		m.hashes[index] = hash
 	}
 }
 
 // Insert inserts a key-value pair into the map.
 // Returns the previous value and true if the key existed, empty string and false otherwise.
 func (m *HashMap) Insert(key, value string) (string, bool) {
+	if m.old != nil {
+		m.migrate(m.rehashStep)
+	}
 	if m.loadFactor() >= maxLoadFactor {
 		m.resize()
 	}
 
-	index, found := m.findSlot(key)
+	key = m.normalize(key)
+	if m.interner != nil {
+		value = m.interner.Intern(value)
+	}
+	hash := m.hash(key)
+	if m.old != nil {
+		if index, found := m.findSlotIn(m.old, m.oldHashes, key, hash); found {
+			oldValue := m.old[index].value
+			m.old[index].value = value
+			return oldValue, true
+		}
+	}
+
+	index, found := m.findSlotIn(m.entries, m.hashes, key, hash)
 
 	if found {
 		oldValue := m.entries[index].value
@@ -140,80 +568,292 @@ func (m *HashMap) Insert(key, value string) (string, bool) {
 		return oldValue, true
 	}
 
-	if m.entries[index].state == tombstone {
-		m.tombstones--
-	}
-
-	m.entries[index] = entry{
-		state: occupied,
-		key:   key,
-		value: value,
+	if m.interner != nil {
+		key = m.interner.Intern(key)
 	}
+	m.store(index, key, value, hash)
 	m.size++
+	if m.maxProbe > 0 {
+		m.checkProbe(index, hash)
+	}
 	return "", false
 }
 
 // Get retrieves the value associated with the key.
 // Returns the value and true if found, empty string and false otherwise.
 func (m *HashMap) Get(key string) (string, bool) {
-	index, found := m.findSlot(key)
+	if m.old != nil {
+		m.migrate(m.rehashStep)
+	}
+	return m.lookup(key)
+}
+
+// lookup is Get without the migration step, so it is safe to call while
+// ranging over m.
+func (m *HashMap) lookup(key string) (string, bool) {
+	key = m.normalize(key)
+	hash := m.hash(key)
+	index, found := m.findSlotIn(m.entries, m.hashes, key, hash)
 	if found {
 		return m.entries[index].value, true
 	}
+	if m.old != nil {
+		if index, found := m.findSlotIn(m.old, m.oldHashes, key, hash); found {
+			return m.old[index].value, true
+		}
+	}
 	return "", false
 }
 
 // Remove removes a key-value pair from the map.
 // Returns the removed value and true if the key existed, empty string and false otherwise.
 func (m *HashMap) Remove(key string) (string, bool) {
-	index, found := m.findSlot(key)
+	if m.old != nil {
+		m.migrate(m.rehashStep)
+	}
+	key = m.normalize(key)
+	hash := m.hash(key)
+	index, found := m.findSlotIn(m.entries, m.hashes, key, hash)
 	if found {
-		oldValue := m.entries[index].value
-		m.entries[index].state = tombstone
-		m.entries[index].key = ""
-		m.entries[index].value = ""
-		m.size--
-		m.tombstones++
-		return oldValue, true
+		return m.removeAt(index).value, true
+	}
+	if m.old != nil {
+		if index, found := m.findSlotIn(m.old, m.oldHashes, key, hash); found {
+			return m.removeOldAt(index).value, true
+		}
 	}
 	return "", false
 }
 
+// removeAt replaces the occupied slot index of the current table with a
+// tombstone and returns the entry it held.
+func (m *HashMap) removeAt(index int) entry {
+	e := m.entries[index]
+	m.entries[index] = entry{state: tombstone}
+	m.size--
+	m.tombstones++
+	return e
+}
+
+// removeOldAt is removeAt for the old table of an incremental resize.
+func (m *HashMap) removeOldAt(index int) entry {
+	e := m.old[index]
+	m.old[index] = entry{state: tombstone}
+	m.size--
+	m.oldLive--
+	return e
+}
+
+// PopAny removes and returns an arbitrary entry, or false if the map is
+// empty. Draining a map with repeated calls costs O(capacity) in total.
+func (m *HashMap) PopAny() (key, value string, ok bool) {
+	if m.old != nil {
+		m.migrate(m.rehashStep)
+	}
+	if m.size == 0 {
+		return "", "", false
+	}
+	if m.old != nil {
+		// Slots below the migration cursor have already been moved.
+		for i := m.migrated; i < len(m.old); i++ {
+			if m.old[i].state == occupied {
+				e := m.removeOldAt(i)
+				return e.key, e.value, true
+			}
+		}
+	}
+	capacity := len(m.entries)
+	for n := 0; n < capacity; n++ {
+		i := (m.popCursor + n) % capacity
+		if m.entries[i].state == occupied {
+			m.popCursor = i
+			e := m.removeAt(i)
+			return e.key, e.value, true
+		}
+	}
+	return "", "", false
+}
+
+// Extract removes every entry for which match returns true, in a single pass
+// over the table, and returns the removed pairs in unspecified order. match
+// must not modify the map.
+func (m *HashMap) Extract(match func(key, value string) bool) []Pair {
+	var removed []Pair
+	for i, e := range m.entries {
+		if e.state == occupied && match(e.key, e.value) {
+			m.removeAt(i)
+			removed = append(removed, Pair{Key: e.key, Value: e.value})
+		}
+	}
+	for i, e := range m.old {
+		if e.state == occupied && match(e.key, e.value) {
+			m.removeOldAt(i)
+			removed = append(removed, Pair{Key: e.key, Value: e.value})
+		}
+	}
+	return removed
+}
+
 // Contains checks if the map contains the given key.
 func (m *HashMap) Contains(key string) bool {
-	_, found := m.findSlot(key)
-	return found
+	key = m.normalize(key)
+	hash := m.hash(key)
+	if _, found := m.findSlotIn(m.entries, m.hashes, key, hash); found {
+		return true
+	}
+	if m.old != nil {
+		_, found := m.findSlotIn(m.old, m.oldHashes, key, hash)
+		return found
+	}
+	return false
 }
 
-// Clear removes all entries from the map.
+// Clear removes all entries from the map. An incremental resize in progress is
+// abandoned, which observers see as its end.
 func (m *HashMap) Clear() {
+	if m.old != nil {
+		m.emit(EventResizeEnd, "", -1, 0, len(m.entries))
+	}
 	for i := range m.entries {
 		m.entries[i] = entry{}
 	}
 	m.size = 0
 	m.tombstones = 0
+	m.old, m.oldHashes = nil, nil
+	m.oldLive = 0
+	m.migrated = 0
+}
+
+// Clone returns an independent copy of m with the same options, copying the
+// table slot for slot, so no key is rehashed. Counters and probe quantile
+// estimates carry over and then diverge; an observer or interner is shared.
+func (m *HashMap) Clone() *HashMap {
+	c := *m
+	c.entries = slices.Clone(m.entries)
+	c.hashes = slices.Clone(m.hashes)
+	c.old = slices.Clone(m.old)
+	c.oldHashes = slices.Clone(m.oldHashes)
+	if m.counters != nil {
+		counters := *m.counters
+		c.counters = &counters
+	}
+	if m.probeQuantiles != nil {
+		c.probeQuantiles = make([]*sketch.P2, len(m.probeQuantiles))
+		for i, p := range m.probeQuantiles {
+			q := *p
+			c.probeQuantiles[i] = &q
+		}
+	}
+	return &c
 }
 
 // Keys returns a slice of all keys in the map.
 func (m *HashMap) Keys() []string {
 	keys := make([]string, 0, m.size)
-	for _, e := range m.entries {
-		if e.state == occupied {
-			keys = append(keys, e.key)
-		}
-	}
+	m.Range(func(key, _ string) bool {
+		keys = append(keys, key)
+		return true
+	})
 	return keys
 }
 
+// Pair is a key-value pair returned by Entries and Extract.
+type Pair struct {
+	Key   string
+	Value string
+}
+
+// Entries returns a slice of all key-value pairs in the map.
+func (m *HashMap) Entries() []Pair {
+	pairs := make([]Pair, 0, m.size)
+	m.Range(func(key, value string) bool {
+		pairs = append(pairs, Pair{Key: key, Value: value})
+		return true
+	})
+	return pairs
+}
+
 // Values returns a slice of all values in the map.
 func (m *HashMap) Values() []string {
 	values := make([]string, 0, m.size)
-	for _, e := range m.entries {
-		if e.state == occupied {
-			values = append(values, e.value)
+	m.Range(func(_, value string) bool {
+		values = append(values, value)
+		return true
+	})
+	return values
+}
+
+// Stats describes the physical layout of the table.
+type Stats struct {
+	Len        int
+	Capacity   int
+	Tombstones int
+	LoadFactor float64
+	// AvgProbe is the mean number of slots examined by a successful lookup.
+	AvgProbe float64
+	// MaxProbe is the longest successful lookup, in slots examined.
+	MaxProbe int
+	// Clusters counts maximal runs of non-empty (occupied or tombstone) slots,
+	// including runs that wrap past the end of the table.
+	Clusters int
+	// LongestCluster is the length of the longest such run.
+	LongestCluster int
+}
+
+// Stats scans the table and returns probe and clustering statistics.
+// During an incremental resize both tables are included.
+func (m *HashMap) Stats() Stats {
+	s := Stats{
+		Len:        m.size,
+		Capacity:   len(m.entries),
+		Tombstones: m.tombstones,
+		LoadFactor: float64(m.size) / float64(len(m.entries)),
+	}
+
+	totalProbe := 0
+	for _, entries := range [][]entry{m.entries, m.old} {
+		capacity := len(entries)
+		var runs []int
+		run := 0
+		for i, e := range entries {
+			if e.state == empty {
+				if run > 0 {
+					runs = append(runs, run)
+				}
+				run = 0
+				continue
+			}
+			run++
+			if e.state == occupied {
+				home := m.homeOf(m.hashKey(e.key), capacity)
+				probe := (i-home+capacity)%capacity + 1
+				totalProbe += probe
+				if probe > s.MaxProbe {
+					s.MaxProbe = probe
+				}
+			}
+		}
+		if run > 0 {
+			// A run touching the end continues at slot 0 when that slot is non-empty.
+			if len(runs) > 0 && entries[0].state != empty {
+				runs[0] += run
+			} else {
+				runs = append(runs, run)
+			}
+		}
+
+		s.Clusters += len(runs)
+		for _, r := range runs {
+			if r > s.LongestCluster {
+				s.LongestCluster = r
+			}
 		}
 	}
-	return values
+
+	if m.size > 0 {
+		s.AvgProbe = float64(totalProbe) / float64(m.size)
+	}
+	return s
 }
 
 // Range iterates over all key-value pairs in the map.
@@ -226,4 +866,11 @@ func (m *HashMap) Range(f func(key, value string) bool) {
 			}
 		}
 	}
+	for _, e := range m.old {
+		if e.state == occupied {
+			if !f(e.key, e.value) {
+				return
+			}
+		}
+	}
 }
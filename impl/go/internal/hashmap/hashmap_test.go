@@ -1,12 +1,23 @@
 package hashmap
 
 import (
+	"errors"
 	"fmt"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
+	"unsafe"
+
+	"github.com/cespare/xxhash/v2"
+
+	"github.com/dsa-lab/go/internal/errs"
+	"github.com/dsa-lab/go/internal/intern"
 )
 
 func TestNew(t *testing.T) {
-	m := New()
+	m := MustNew()
 	if !m.IsEmpty() {
 		t.Error("new map should be empty")
 	}
@@ -15,8 +26,31 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestNewValidatesOptions(t *testing.T) {
+	for _, capacity := range []int{minCapacity, 5, defaultCapacity, 100} {
+		if m := MustNewWithCapacity(capacity); m.Capacity() != capacity {
+			t.Errorf("expected capacity %d, got %d", capacity, m.Capacity())
+		}
+	}
+	for _, capacity := range []int{minCapacity - 1, 0, -1} {
+		if _, err := NewWithCapacity(capacity); !errors.Is(err, errs.ErrInvalidOption) {
+			t.Errorf("capacity %d: expected ErrInvalidOption, got %v", capacity, err)
+		}
+	}
+	for name, opt := range map[string]Option{
+		"WithIncrementalResize(0)": WithIncrementalResize(0),
+		"WithMaxProbe(-1)":         WithMaxProbe(-1),
+		"WithProbeQuantiles(1.5)":  WithProbeQuantiles(0.5, 1.5),
+	} {
+		_, err := New(WithCachedHashes(), opt)
+		if !errors.Is(err, errs.ErrInvalidOption) {
+			t.Errorf("%s: expected ErrInvalidOption, got %v", name, err)
+		}
+	}
+}
+
 func TestInsertAndGet(t *testing.T) {
-	m := New()
+	m := MustNew()
 	old, existed := m.Insert("key1", "value1")
 	if existed {
 		t.Error("insert to new map should not return existing value")
@@ -38,7 +72,7 @@ func TestInsertAndGet(t *testing.T) {
 }
 
 func TestInsertOverwrite(t *testing.T) {
-	m := New()
+	m := MustNew()
 	m.Insert("key", "value1")
 	old, existed := m.Insert("key", "value2")
 
@@ -59,7 +93,7 @@ func TestInsertOverwrite(t *testing.T) {
 }
 
 func TestRemove(t *testing.T) {
-	m := New()
+	m := MustNew()
 	m.Insert("key", "value")
 	removed, existed := m.Remove("key")
 
@@ -80,7 +114,7 @@ func TestRemove(t *testing.T) {
 }
 
 func TestRemoveNonExistent(t *testing.T) {
-	m := New()
+	m := MustNew()
 	_, existed := m.Remove("nonexistent")
 	if existed {
 		t.Error("remove should return existed=false for non-existent key")
@@ -88,7 +122,7 @@ func TestRemoveNonExistent(t *testing.T) {
 }
 
 func TestContains(t *testing.T) {
-	m := New()
+	m := MustNew()
 	m.Insert("key", "value")
 
 	if !m.Contains("key") {
@@ -100,7 +134,7 @@ func TestContains(t *testing.T) {
 }
 
 func TestClear(t *testing.T) {
-	m := New()
+	m := MustNew()
 	m.Insert("key1", "value1")
 	m.Insert("key2", "value2")
 	m.Clear()
@@ -114,7 +148,7 @@ func TestClear(t *testing.T) {
 }
 
 func TestResize(t *testing.T) {
-	m := NewWithCapacity(4)
+	m := MustNewWithCapacity(4)
 	for i := 0; i < 100; i++ {
 		m.Insert(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
 	}
@@ -122,6 +156,14 @@ func TestResize(t *testing.T) {
 	if m.Len() != 100 {
 		t.Errorf("expected length 100, got %d", m.Len())
 	}
+	// Each resize doubles the table, starting from 4 slots.
+	want := 0
+	for c := 4; c < m.Capacity(); c *= 2 {
+		want++
+	}
+	if m.Resizes() != want {
+		t.Errorf("expected %d resizes to reach capacity %d, got %d", want, m.Capacity(), m.Resizes())
+	}
 
 	for i := 0; i < 100; i++ {
 		key := fmt.Sprintf("key%d", i)
@@ -137,7 +179,7 @@ func TestResize(t *testing.T) {
 }
 
 func TestTombstoneReuse(t *testing.T) {
-	m := New()
+	m := MustNew()
 	m.Insert("key1", "value1")
 	m.Insert("key2", "value2")
 	m.Remove("key1")
@@ -158,7 +200,7 @@ func TestTombstoneReuse(t *testing.T) {
 }
 
 func TestKeysAndValues(t *testing.T) {
-	m := New()
+	m := MustNew()
 	m.Insert("a", "1")
 	m.Insert("b", "2")
 	m.Insert("c", "3")
@@ -175,7 +217,7 @@ func TestKeysAndValues(t *testing.T) {
 }
 
 func TestRange(t *testing.T) {
-	m := New()
+	m := MustNew()
 	m.Insert("a", "1")
 	m.Insert("b", "2")
 	m.Insert("c", "3")
@@ -201,3 +243,548 @@ func TestRange(t *testing.T) {
 		t.Errorf("range should stop after 2 iterations, got %d", count)
 	}
 }
+
+func TestIncrementalResize(t *testing.T) {
+	m := MustNew(WithIncrementalResize(1))
+	for i := 0; i < 13; i++ {
+		m.Insert(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+
+	// The 13th insert reaches the load factor; the next one starts a resize.
+	m.Insert("key13", "value13")
+	if !m.Resizing() {
+		t.Fatal("expected resize to be in progress")
+	}
+	if m.Capacity() != 32 {
+		t.Errorf("expected capacity 32, got %d", m.Capacity())
+	}
+	if m.Resizes() != 1 {
+		t.Errorf("expected a started resize to count, got %d", m.Resizes())
+	}
+
+	for i := 0; i < 14; i++ {
+		key := fmt.Sprintf("key%d", i)
+		if !m.Contains(key) {
+			t.Errorf("key %s missing during resize", key)
+		}
+	}
+
+	old, existed := m.Insert("key0", "updated")
+	if !existed || old != "value0" {
+		t.Errorf("expected overwrite of value0, got %q, %v", old, existed)
+	}
+	if removed, existed := m.Remove("key1"); !existed || removed != "value1" {
+		t.Errorf("expected removal of value1, got %q, %v", removed, existed)
+	}
+
+	for i := 0; m.Resizing() && i < 100; i++ {
+		m.Get("key0")
+	}
+	if m.Resizing() {
+		t.Fatal("resize should complete after enough operations")
+	}
+
+	if m.Len() != 13 {
+		t.Errorf("expected length 13, got %d", m.Len())
+	}
+	if value, _ := m.Get("key0"); value != "updated" {
+		t.Errorf("expected updated, got %s", value)
+	}
+	if m.Contains("key1") {
+		t.Error("key1 should not exist")
+	}
+	if len(m.Keys()) != 13 {
+		t.Errorf("expected 13 keys, got %d", len(m.Keys()))
+	}
+}
+
+func TestIncrementalResizeLarge(t *testing.T) {
+	m := MustNew(WithIncrementalResize(4))
+	for i := 0; i < 10000; i++ {
+		m.Insert(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+
+	if m.Len() != 10000 {
+		t.Errorf("expected length 10000, got %d", m.Len())
+	}
+	for i := 0; i < 10000; i++ {
+		key := fmt.Sprintf("key%d", i)
+		value, found := m.Get(key)
+		if !found || value != fmt.Sprintf("value%d", i) {
+			t.Errorf("key %s: got %q, %v", key, value, found)
+		}
+	}
+}
+
+func TestStats(t *testing.T) {
+	m := MustNew()
+	if s := m.Stats(); s.Len != 0 || s.Clusters != 0 || s.AvgProbe != 0 {
+		t.Errorf("unexpected stats for empty map: %+v", s)
+	}
+
+	for i := 0; i < 10; i++ {
+		m.Insert(fmt.Sprintf("key%d", i), "v")
+	}
+	m.Remove("key0")
+
+	s := m.Stats()
+	if s.Len != 9 || s.Capacity != 16 || s.Tombstones != 1 {
+		t.Errorf("unexpected counts: %+v", s)
+	}
+	if s.AvgProbe < 1 || s.MaxProbe < 1 || float64(s.MaxProbe) < s.AvgProbe {
+		t.Errorf("unexpected probe stats: %+v", s)
+	}
+	if s.Clusters < 1 || s.LongestCluster < 1 || s.LongestCluster > 10 {
+		t.Errorf("unexpected cluster stats: %+v", s)
+	}
+}
+
+func TestCheckInvariants(t *testing.T) {
+	m := MustNewWithCapacity(4, WithIncrementalResize(1))
+	for i := 0; i < 200; i++ {
+		m.Insert(fmt.Sprintf("key%d", i), "v")
+		if i%3 == 0 {
+			m.Remove(fmt.Sprintf("key%d", i/2))
+		}
+		if err := m.CheckInvariants(); err != nil {
+			t.Fatalf("after operation %d: %v", i, err)
+		}
+	}
+
+	m.size++
+	if err := m.CheckInvariants(); err == nil {
+		t.Error("expected size mismatch to be reported")
+	}
+	m.size--
+
+	// Hide a key behind an empty slot by clearing the slot before it.
+	for i, e := range m.entries {
+		if e.state != occupied {
+			continue
+		}
+		home := int(m.hashKey(e.key) % uint64(len(m.entries)))
+		if home != i {
+			m.entries[(i-1+len(m.entries))%len(m.entries)] = entry{}
+			break
+		}
+	}
+	if err := m.CheckInvariants(); err == nil {
+		t.Error("expected unreachable key to be reported")
+	}
+}
+
+func TestCachedHashes(t *testing.T) {
+	for _, opts := range [][]Option{
+		{WithCachedHashes()},
+		{WithCachedHashes(), WithIncrementalResize(1)},
+	} {
+		m := MustNewWithCapacity(4, opts...)
+		for i := 0; i < 500; i++ {
+			m.Insert(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+			if i%4 == 0 {
+				m.Remove(fmt.Sprintf("key%d", i/2))
+			}
+			if err := m.CheckInvariants(); err != nil {
+				t.Fatalf("after operation %d: %v", i, err)
+			}
+		}
+		for i := 250; i < 500; i++ {
+			key := fmt.Sprintf("key%d", i)
+			if value, found := m.Get(key); !found || value != fmt.Sprintf("value%d", i) {
+				t.Errorf("key %s: got %q, %v", key, value, found)
+			}
+		}
+	}
+}
+
+func TestCachedHashesSkipRehash(t *testing.T) {
+	for _, tc := range []struct {
+		opts   []Option
+		hashes uint64
+	}{
+		{[]Option{WithCounters()}, 13 + 12},
+		{[]Option{WithCounters(), WithCachedHashes()}, 13},
+	} {
+		m := MustNew(tc.opts...)
+		for i := 0; i < 13; i++ {
+			m.Insert(fmt.Sprintf("key%d", i), "v")
+		}
+		c := m.Counters()
+		if c.Moves != 12 {
+			t.Errorf("expected 12 moves from one resize, got %d", c.Moves)
+		}
+		if c.Hashes != tc.hashes {
+			t.Errorf("expected %d hashes, got %d", tc.hashes, c.Hashes)
+		}
+	}
+}
+
+// collidingKeys returns n distinct keys whose unseeded hashes agree in their
+// low bits, so they share a home slot in every table of up to 1<<bits slots.
+func collidingKeys(n, bits int) []string {
+	mask := uint64(1)<<bits - 1
+	keys := make([]string, 0, n)
+	for i := 0; len(keys) < n; i++ {
+		key := fmt.Sprintf("adv%d", i)
+		if xxhash.Sum64String(key)&mask == 0 {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// maxLookupProbes returns the longest probe sequence among Gets of keys.
+func maxLookupProbes(m *HashMap, keys []string) uint64 {
+	var worst uint64
+	for _, key := range keys {
+		m.ResetCounters()
+		if _, found := m.Get(key); !found {
+			return ^uint64(0)
+		}
+		worst = max(worst, m.Counters().Probes)
+	}
+	return worst
+}
+
+func TestMaxProbeBoundsAdversarialKeys(t *testing.T) {
+	keys := collidingKeys(1000, 12)
+
+	m := MustNew(WithCounters())
+	for _, key := range keys {
+		m.Insert(key, "v")
+	}
+	if worst := maxLookupProbes(m, keys); worst < 900 {
+		t.Fatalf("expected colliding keys to form one long cluster, worst lookup %d probes", worst)
+	}
+
+	for _, opts := range [][]Option{
+		{WithCounters(), WithMaxProbe(16)},
+		{WithCounters(), WithMaxProbe(16), WithCachedHashes(), WithIncrementalResize(4)},
+	} {
+		m := MustNew(opts...)
+		for _, key := range keys {
+			m.Insert(key, "v")
+			if err := m.CheckInvariants(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if rebuilds := m.Counters().Rebuilds; rebuilds == 0 {
+			t.Error("expected at least one rebuild")
+		}
+		if worst := maxLookupProbes(m, keys); worst > 128 {
+			t.Errorf("expected worst lookup within 128 probes, got %d", worst)
+		}
+	}
+}
+
+func TestEntries(t *testing.T) {
+	m := MustNew()
+	for i := 0; i < 20; i++ {
+		m.Insert(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+	pairs := m.Entries()
+	if len(pairs) != 20 {
+		t.Fatalf("expected 20 pairs, got %d", len(pairs))
+	}
+	for _, p := range pairs {
+		if value, _ := m.Get(p.Key); value != p.Value {
+			t.Errorf("pair %+v does not match stored value %q", p, value)
+		}
+	}
+}
+
+func TestPopAny(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithIncrementalResize(1)}} {
+		m := MustNew(opts...)
+		want := make(map[string]string)
+		for i := 0; i < 100; i++ {
+			key, value := fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i)
+			m.Insert(key, value)
+			want[key] = value
+		}
+
+		for len(want) > 0 {
+			key, value, ok := m.PopAny()
+			if !ok {
+				t.Fatalf("expected an entry with %d left", len(want))
+			}
+			if want[key] != value {
+				t.Fatalf("popped unexpected pair %q=%q", key, value)
+			}
+			delete(want, key)
+			if m.Len() != len(want) || m.Contains(key) {
+				t.Fatalf("expected %q removed and %d left, got len %d", key, len(want), m.Len())
+			}
+			if err := m.CheckInvariants(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, _, ok := m.PopAny(); ok {
+			t.Error("expected PopAny on an empty map to report false")
+		}
+	}
+}
+
+func TestExtract(t *testing.T) {
+	m := MustNew(WithIncrementalResize(1))
+	for i := 0; i < 50; i++ {
+		m.Insert(fmt.Sprintf("key%d", i), fmt.Sprint(i))
+	}
+	if !m.Resizing() {
+		t.Fatal("expected resize to be in progress")
+	}
+
+	removed := m.Extract(func(_, value string) bool {
+		var n int
+		fmt.Sscan(value, &n)
+		return n%3 == 0
+	})
+	if len(removed) != 17 {
+		t.Errorf("expected 17 removed pairs, got %d", len(removed))
+	}
+	for _, p := range removed {
+		if m.Contains(p.Key) {
+			t.Errorf("expected %q to be removed", p.Key)
+		}
+	}
+	if m.Len() != 33 {
+		t.Errorf("expected length 33, got %d", m.Len())
+	}
+	if err := m.CheckInvariants(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEqualAndDiff(t *testing.T) {
+	a := MustNew()
+	b := MustNew(WithIncrementalResize(1), WithCachedHashes())
+	for i := 0; i < 40; i++ {
+		a.Insert(fmt.Sprintf("key%d", i), "v")
+	}
+	for i := 39; i >= 0; i-- {
+		b.Insert(fmt.Sprintf("key%d", i), "v")
+	}
+	if !Equal(a, b) || !Equal(b, a) {
+		t.Fatal("expected maps with the same pairs to be equal")
+	}
+	if onlyA, onlyB, changed := Diff(a, b); onlyA != nil || onlyB != nil || changed != nil {
+		t.Errorf("expected empty diff, got %v %v %v", onlyA, onlyB, changed)
+	}
+
+	a.Remove("key3")
+	b.Insert("key7", "changed")
+	b.Insert("extra", "v")
+	b.Remove("key5")
+	if Equal(a, b) {
+		t.Fatal("expected maps to differ")
+	}
+	onlyA, onlyB, changed := Diff(a, b)
+	if !slices.Equal(onlyA, []string{"key5"}) {
+		t.Errorf("expected onlyA [key5], got %v", onlyA)
+	}
+	if !slices.Equal(onlyB, []string{"extra", "key3"}) {
+		t.Errorf("expected onlyB [extra key3], got %v", onlyB)
+	}
+	if !slices.Equal(changed, []string{"key7"}) {
+		t.Errorf("expected changed [key7], got %v", changed)
+	}
+
+	// Same length, same keys, one different value.
+	c, d := MustNew(), MustNew()
+	c.Insert("k", "1")
+	d.Insert("k", "2")
+	if Equal(c, d) {
+		t.Error("expected maps with different values to differ")
+	}
+}
+
+func TestWithInterner(t *testing.T) {
+	in := intern.New()
+	a, b := MustNew(WithInterner(in)), MustNew(WithInterner(in))
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("key_%d", i%10)
+		a.Insert(key, fmt.Sprint("value_", i%2))
+		b.Insert(strings.Clone(key), "value_0")
+	}
+
+	if in.Len() != 12 {
+		t.Errorf("expected 10 keys and 2 values interned, got %d", in.Len())
+	}
+	canonical, _ := in.Lookup("key_3")
+	for _, m := range []*HashMap{a, b} {
+		index, found := m.findSlot("key_3")
+		if !found {
+			t.Fatal("expected key_3 to be present")
+		}
+		if unsafe.StringData(m.entries[index].key) != unsafe.StringData(canonical) {
+			t.Error("expected stored key to share the interned copy")
+		}
+	}
+	if v, _ := a.Get("key_3"); v != "value_1" {
+		t.Errorf("expected value_1, got %q", v)
+	}
+}
+
+func TestFoldCase(t *testing.T) {
+	cases := map[string]string{
+		"":             "",
+		"content-type": "content-type",
+		"Content-Type": "content-type",
+		"ÉCOLE":        "école",
+		"K":            "k", // Kelvin sign
+		"ſ":            "s", // long s
+		"ΣΑΣ":          "σασ",
+		"ς":            "σ",
+		"A\xffB":       "a\xffb",
+		"ß":            "ß",
+	}
+	for in, want := range cases {
+		if got := FoldCase(in); got != want {
+			t.Errorf("FoldCase(%q): expected %q, got %q", in, want, got)
+		}
+		if got := FoldCase(FoldCase(in)); got != FoldCase(in) {
+			t.Errorf("FoldCase(%q) is not idempotent: %q", in, got)
+		}
+	}
+	key := "already-folded"
+	if allocs := testing.AllocsPerRun(100, func() { FoldCase(key) }); allocs != 0 {
+		t.Errorf("expected no allocations for a folded key, got %v", allocs)
+	}
+}
+
+func TestWithKeyNormalizer(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithIncrementalResize(1)}, {WithCachedHashes()}} {
+		m := MustNew(append(opts, WithKeyNormalizer(FoldCase))...)
+		for i := 0; i < 100; i++ {
+			m.Insert(fmt.Sprintf("Header-%d", i), "v")
+		}
+		if old, found := m.Insert("CONTENT-TYPE", "text/plain"); found {
+			t.Fatalf("expected new key, got %q", old)
+		}
+		if old, found := m.Insert("content-type", "text/html"); !found || old != "text/plain" {
+			t.Errorf("expected to replace text/plain, got %q %v", old, found)
+		}
+		if v, found := m.Get("Content-Type"); !found || v != "text/html" {
+			t.Errorf("expected text/html, got %q %v", v, found)
+		}
+		if !m.Contains("HEADER-42") || m.Len() != 101 {
+			t.Errorf("expected 101 keys including header-42, got %d", m.Len())
+		}
+		if !slices.Contains(m.Keys(), "content-type") {
+			t.Error("expected keys to be stored normalized")
+		}
+		if v, found := m.Remove("Content-TYPE"); !found || v != "text/html" {
+			t.Errorf("expected to remove text/html, got %q %v", v, found)
+		}
+		if err := m.CheckInvariants(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	a, b := MustNew(), MustNew(WithKeyNormalizer(FoldCase))
+	a.Insert("x", "1.0")
+	b.Insert("X", "1")
+	if Equal(a, b) {
+		t.Error("expected different value spellings to differ")
+	}
+	numeric := func(x, y string) bool {
+		fx, errx := strconv.ParseFloat(x, 64)
+		fy, erry := strconv.ParseFloat(y, 64)
+		return errx == nil && erry == nil && fx == fy
+	}
+	if !EqualFunc(a, b, numeric) {
+		t.Error("expected maps equal under numeric comparison")
+	}
+}
+
+func TestTxnNormalizesKeys(t *testing.T) {
+	m := MustNew(WithKeyNormalizer(FoldCase))
+	m.Insert("Accept", "text/html")
+	txn := m.Txn()
+	if old, found := txn.Insert("ACCEPT", "application/json"); !found || old != "text/html" {
+		t.Errorf("expected the batch to see the map's value, got %q %v", old, found)
+	}
+	if v, found := txn.Get("accept"); !found || v != "application/json" {
+		t.Errorf("expected the batch's own write under another case, got %q %v", v, found)
+	}
+	txn.Remove("Host")
+	if txn.Len() != 2 {
+		t.Errorf("expected 2 buffered keys, got %d", txn.Len())
+	}
+	txn.Commit()
+	if v, _ := m.Get("accept"); v != "application/json" || m.Len() != 1 {
+		t.Errorf("expected one key set by the batch, got %q and %d keys", v, m.Len())
+	}
+}
+
+func TestBulkLoad(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+	options := map[string][]Option{
+		"plain":      nil,
+		"cached":     {WithCachedHashes()},
+		"normalized": {WithKeyNormalizer(FoldCase)},
+		// Few distinct hashes pile keys into long runs that spill past the
+		// end of their slot range, and past the end of the table.
+		"clustered": {WithHashFunc(func(key string) uint64 { return xxhash.Sum64String(key) % 64 * 0x9e3779b97f4a7c15 })},
+		"counted":   {WithCounters()},
+	}
+	for name, opts := range options {
+		for _, n := range []int{0, 100, minParallelBulk * 2, 50000} {
+			pairs := make([]Pair, n)
+			for i := range pairs {
+				// Every fifth key repeats an earlier one in another case.
+				key := "key" + strconv.Itoa(i)
+				if i%5 == 4 {
+					key = "KEY" + strconv.Itoa(i/2)
+				}
+				pairs[i] = Pair{Key: key, Value: strconv.Itoa(i)}
+			}
+			want := MustNew(opts...)
+			for _, p := range pairs {
+				want.Insert(p.Key, p.Value)
+			}
+			got := MustBulkLoad(pairs, opts...)
+			if err := got.CheckInvariants(); err != nil {
+				t.Fatalf("%s/n=%d: %v", name, n, err)
+			}
+			if !Equal(got, want) {
+				onlyGot, onlyWant, changed := Diff(got, want)
+				t.Fatalf("%s/n=%d: expected the sequential map, got %d extra, %d missing and %d changed keys",
+					name, n, len(onlyGot), len(onlyWant), len(changed))
+			}
+			if float64(got.Len())/float64(got.Capacity()) >= maxLoadFactor {
+				t.Errorf("%s/n=%d: expected room for another insert, got %d keys in %d slots", name, n, got.Len(), got.Capacity())
+			}
+		}
+	}
+}
+
+func TestClone(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithCachedHashes(), WithCounters()}, {WithIncrementalResize(1)}} {
+		m := MustNew(opts...)
+		for i := 0; i < 100; i++ {
+			m.Insert(strconv.Itoa(i), "v")
+		}
+		c := m.Clone()
+		if err := c.CheckInvariants(); err != nil {
+			t.Fatal(err)
+		}
+		if !Equal(c, m) {
+			t.Fatal("expected the clone to equal the original")
+		}
+		c.Insert("new", "v")
+		c.Remove("0")
+		for i := 1; i < 100; i++ {
+			c.Insert(strconv.Itoa(i), "changed")
+		}
+		if m.Len() != 100 || m.Contains("new") || !m.Contains("0") {
+			t.Error("expected writes to the clone to leave the original alone")
+		}
+		if v, _ := m.Get("5"); v != "v" {
+			t.Errorf("expected v, got %q", v)
+		}
+		if err := m.CheckInvariants(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package hashmap
+
+import (
+	"fmt"
+)
+
+// CheckInvariants verifies the structural invariants of the table and returns
+// an error describing the first violation:
+//
+//   - size equals the number of occupied slots across both tables,
+//   - the tombstone and old-table live counts match the slots,
+//   - every key appears once and is reachable by probing from its home slot
+//     without crossing an empty slot,
+//   - the current table always keeps at least one empty slot,
+//   - cached hashes, if enabled, match the keys they belong to.
+//
+// It scans the whole table and is intended for tests and debugging.
+func (m *HashMap) CheckInvariants() error {
+	occupiedNew, tombstonesNew := countStates(m.entries)
+	occupiedOld, _ := countStates(m.old)
+
+	if tombstonesNew != m.tombstones {
+		return fmt.Errorf("tombstone count %d, table has %d", m.tombstones, tombstonesNew)
+	}
+	if occupiedOld != m.oldLive {
+		return fmt.Errorf("old table live count %d, table has %d", m.oldLive, occupiedOld)
+	}
+	if m.old == nil && m.migrated != 0 {
+		return fmt.Errorf("migration cursor %d with no resize in progress", m.migrated)
+	}
+	if occupiedNew+occupiedOld != m.size {
+		return fmt.Errorf("size %d, tables hold %d", m.size, occupiedNew+occupiedOld)
+	}
+	if occupiedNew+tombstonesNew >= len(m.entries) {
+		return fmt.Errorf("table of %d slots has no empty slot", len(m.entries))
+	}
+
+	seen := make(map[string]bool, m.size)
+	for t, entries := range [][]entry{m.entries, m.old} {
+		hashes := [][]uint64{m.hashes, m.oldHashes}[t]
+		if m.cacheHashes && len(hashes) != len(entries) {
+			return fmt.Errorf("hash cache of %d slots for a table of %d", len(hashes), len(entries))
+		}
+		capacity := len(entries)
+		for i, e := range entries {
+			if e.state != occupied {
+				continue
+			}
+			if hashes != nil && hashes[i] != m.hashKey(e.key) {
+				return fmt.Errorf("key %q at slot %d has stale cached hash %#x", e.key, i, hashes[i])
+			}
+			if seen[e.key] {
+				return fmt.Errorf("key %q stored more than once", e.key)
+			}
+			seen[e.key] = true
+
+			home := m.homeOf(m.hashKey(e.key), capacity)
+			for j := home; j != i; j = (j + 1) % capacity {
+				if entries[j].state == empty {
+					return fmt.Errorf("key %q at slot %d unreachable from home %d: slot %d is empty", e.key, i, home, j)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func countStates(entries []entry) (occupiedCount, tombstoneCount int) {
+	for _, e := range entries {
+		switch e.state {
+		case occupied:
+			occupiedCount++
+		case tombstone:
+			tombstoneCount++
+		}
+	}
+	return occupiedCount, tombstoneCount
+}
@@ -0,0 +1,10 @@
+//go:build go1.23
+
+package hashmap
+
+import "iter"
+
+// All returns an iterator over all key-value pairs in unspecified order.
+func (m *HashMap) All() iter.Seq2[string, string] {
+	return m.Range
+}
@@ -0,0 +1,85 @@
+package hashmap
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// WithKeyNormalizer passes every key through normalize before it is hashed,
+// compared or stored, so keys that normalize alike are the same key: with
+// FoldCase, "Content-Type" and "content-type" name one entry. Keys are
+// stored, and returned by Keys and Range, in normalized form. normalize must
+// be deterministic and idempotent. Ready-made normalizers include FoldCase,
+// path.Clean for slash-separated paths and textproto.CanonicalMIMEHeaderKey
+// for header names; Unicode normalization forms such as NFC need tables from
+// golang.org/x/text/unicode/norm, whose NFC.String fits here too.
+func WithKeyNormalizer(normalize func(key string) string) Option {
+	return func(m *HashMap) {
+		m.normalizer = normalize
+	}
+}
+
+// normalize applies the map's key normalizer, if any.
+func (m *HashMap) normalize(key string) string {
+	if m.normalizer == nil {
+		return key
+	}
+	return m.normalizer(key)
+}
+
+// FoldCase maps key to a case-folded form, so that two strings have the same
+// form exactly when they are equal under Unicode simple case folding: "STRASSE"
+// and "strasse" match, as do "K" and the Kelvin sign, but not "ß" and "ss",
+// which differ in length. Bytes that are not valid UTF-8 are kept as they
+// are. Keys that are already folded are returned without allocating.
+func FoldCase(key string) string {
+	for i := 0; i < len(key); {
+		c := key[i]
+		if c < utf8.RuneSelf {
+			if 'A' <= c && c <= 'Z' {
+				return foldFrom(key, i)
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(key[i:])
+		if foldRune(r) != r {
+			return foldFrom(key, i)
+		}
+		i += size
+	}
+	return key
+}
+
+// foldFrom folds key, whose first i bytes are known to be folded already.
+func foldFrom(key string, i int) string {
+	buf := make([]byte, i, len(key))
+	copy(buf, key[:i])
+	for i < len(key) {
+		r, size := utf8.DecodeRuneInString(key[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf = append(buf, key[i])
+		} else {
+			buf = utf8.AppendRune(buf, foldRune(r))
+		}
+		i += size
+	}
+	return string(buf)
+}
+
+// foldRune returns the representative of r's case-folding orbit: the
+// lowercase form of its smallest member. Every rune in an orbit maps to the
+// same representative.
+func foldRune(r rune) rune {
+	if r < utf8.RuneSelf {
+		if 'A' <= r && r <= 'Z' {
+			r += 'a' - 'A'
+		}
+		return r
+	}
+	least := r
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		least = min(least, f)
+	}
+	return unicode.ToLower(least)
+}
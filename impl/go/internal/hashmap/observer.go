@@ -0,0 +1,82 @@
+package hashmap
+
+// EventKind identifies an internal step reported to an Observer.
+type EventKind int
+
+const (
+	// EventProbe is reported for every slot examined by a probe sequence.
+	EventProbe EventKind = iota
+	// EventCollision is reported when an examined slot holds a different key.
+	// It follows the EventProbe for the same slot.
+	EventCollision
+	// EventTombstoneReuse is reported when an insert or migration stores an
+	// entry in a slot previously left by a removal.
+	EventTombstoneReuse
+	// EventResizeStart is reported when the table begins to grow, or to be
+	// rebuilt in place under a new seed (see WithMaxProbe).
+	EventResizeStart
+	// EventResizeEnd is reported once every entry lives in the new table.
+	// With incremental resizing this happens several operations after the
+	// matching EventResizeStart.
+	EventResizeEnd
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventProbe:
+		return "probe"
+	case EventCollision:
+		return "collision"
+	case EventTombstoneReuse:
+		return "tombstone-reuse"
+	case EventResizeStart:
+		return "resize-start"
+	case EventResizeEnd:
+		return "resize-end"
+	}
+	return "unknown"
+}
+
+// Event describes one internal step of a map operation.
+type Event struct {
+	Kind EventKind
+	// Key is the key being probed for or stored. It is empty for resize events.
+	Key string
+	// Slot is the slot examined or written, or -1 for resize events.
+	Slot int
+	// Step is the 0-based position of Slot in its probe sequence for probe
+	// and collision events, and 0 otherwise.
+	Step int
+	// Capacity is the size of the table Slot belongs to. For resize events it
+	// is the capacity of the new table.
+	Capacity int
+}
+
+// Observer receives internal events as they happen, in order. It is meant for
+// teaching front-ends that animate the algorithm; observers must not modify
+// the map.
+type Observer interface {
+	Observe(e Event)
+}
+
+// ObserverFunc adapts an ordinary function to the Observer interface.
+type ObserverFunc func(e Event)
+
+// Observe calls f(e).
+func (f ObserverFunc) Observe(e Event) {
+	f(e)
+}
+
+// WithObserver reports probe steps, collisions, tombstone reuse and resizes
+// to o. Each probe costs an extra call while an observer is installed.
+func WithObserver(o Observer) Option {
+	return func(m *HashMap) {
+		m.observer = o
+	}
+}
+
+func (m *HashMap) emit(kind EventKind, key string, slot, step, capacity int) {
+	if m.observer != nil {
+		m.observer.Observe(Event{Kind: kind, Key: key, Slot: slot, Step: step, Capacity: capacity})
+	}
+}
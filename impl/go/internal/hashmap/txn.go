@@ -0,0 +1,18 @@
+package hashmap
+
+import (
+	"strings"
+
+	"github.com/dsa-lab/go/internal/txn"
+)
+
+// Txn starts a batch of writes to m that Commit applies together. Keys in the
+// batch are matched after normalization, as in the map itself.
+func (m *HashMap) Txn() *txn.Txn[string, string] {
+	if m.normalizer == nil {
+		return txn.New[string, string](m)
+	}
+	return txn.NewFunc[string, string](m, func(a, b string) int {
+		return strings.Compare(m.normalize(a), m.normalize(b))
+	})
+}
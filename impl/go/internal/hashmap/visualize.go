@@ -0,0 +1,64 @@
+package hashmap
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// Visualize writes the slot layout as a Graphviz DOT graph. Each table is a
+// row of slots coloured by probe distance (green at home, shading to red as
+// entries are displaced further), tombstones are grey, and every displaced
+// entry has a dashed edge from its home slot so probe clusters are visible.
+// Render with, for example, `dot -Tsvg`.
+func (m *HashMap) Visualize(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("digraph hashmap {\n")
+	b.WriteString("  node [shape=plaintext, fontname=\"monospace\"];\n")
+
+	for t, entries := range [][]entry{m.entries, m.old} {
+		if entries == nil {
+			continue
+		}
+		capacity := len(entries)
+		name := "table"
+		if t == 1 {
+			name = "old"
+		}
+
+		fmt.Fprintf(&b, "  %s [label=<<TABLE BORDER=\"0\" CELLBORDER=\"1\" CELLSPACING=\"0\">\n", name)
+		fmt.Fprintf(&b, "    <TR><TD COLSPAN=\"%d\">%s: len %d, capacity %d</TD></TR>\n    <TR>", capacity, name, m.size, capacity)
+		var edges []string
+		for i, e := range entries {
+			switch e.state {
+			case empty:
+				fmt.Fprintf(&b, "<TD PORT=\"s%d\">%d</TD>", i, i)
+			case tombstone:
+				fmt.Fprintf(&b, "<TD PORT=\"s%d\" BGCOLOR=\"gray80\">%d<BR/>&#8224;</TD>", i, i)
+			case occupied:
+				home := m.homeOf(m.hashKey(e.key), capacity)
+				distance := (i - home + capacity) % capacity
+				fmt.Fprintf(&b, "<TD PORT=\"s%d\" BGCOLOR=\"%s\">%d<BR/>%s</TD>", i, distanceColor(distance), i, html.EscapeString(e.key))
+				if distance > 0 {
+					edges = append(edges, fmt.Sprintf("  %s:s%d:s -> %s:s%d:s [style=dashed, constraint=false];\n", name, home, name, i))
+				}
+			}
+		}
+		b.WriteString("</TR>\n  </TABLE>>];\n")
+		for _, edge := range edges {
+			b.WriteString(edge)
+		}
+	}
+
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// distanceColor maps a probe distance onto a green-to-red HSV ramp that
+// saturates at distance 8.
+func distanceColor(distance int) string {
+	hue := 0.33 * (1 - float64(min(distance, 8))/8)
+	return fmt.Sprintf("%.3f 0.45 1.0", hue)
+}
@@ -0,0 +1,32 @@
+// Package hashseed is the seeding strategy shared by the lab's hash
+// functions. A seed is a random non-zero 64-bit word, and everything keyed by
+// a seed, whether a hashmap's home-slot scrambling or a rolling hash's base
+// and byte table, is derived from it through the splitmix64 finalizer. One
+// seed therefore reproduces every derived value, and a fresh seed changes all
+// of them at once.
+package hashseed
+
+import "math/rand"
+
+// golden is the splitmix64 increment, 2^64 divided by the golden ratio.
+const golden = 0x9e3779b97f4a7c15
+
+// New returns a fresh random seed. It is never zero, so zero can mean
+// "unseeded".
+func New() uint64 {
+	return rand.Uint64() | 1
+}
+
+// Mix is the splitmix64 finalizer: a bijection on 64-bit words in which
+// every input bit affects every output bit.
+func Mix(x uint64) uint64 {
+	x = (x ^ x>>30) * 0xbf58476d1ce4e5b9
+	x = (x ^ x>>27) * 0x94d049bb133111eb
+	return x ^ x>>31
+}
+
+// Derive returns the i-th word derived from seed, the splitmix64 sequence
+// started at seed. Distinct i give independent-looking words.
+func Derive(seed uint64, i int) uint64 {
+	return Mix(seed + uint64(i+1)*golden)
+}
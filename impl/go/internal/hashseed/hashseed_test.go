@@ -0,0 +1,63 @@
+package hashseed
+
+import (
+	"math/bits"
+	"testing"
+)
+
+func TestNewIsNonZero(t *testing.T) {
+	for i := 0; i < 1000; i++ {
+		if New() == 0 {
+			t.Fatal("expected a non-zero seed")
+		}
+	}
+}
+
+func TestDeriveIsDeterministicAndDistinct(t *testing.T) {
+	seen := make(map[uint64]bool)
+	for i := 0; i < 10000; i++ {
+		w := Derive(12345, i)
+		if w != Derive(12345, i) {
+			t.Fatalf("expected Derive(12345, %d) to be deterministic", i)
+		}
+		if seen[w] {
+			t.Fatalf("expected distinct derived words, got a repeat at %d", i)
+		}
+		seen[w] = true
+	}
+	if Derive(1, 0) == Derive(2, 0) {
+		t.Error("expected different seeds to derive different words")
+	}
+}
+
+func TestMixAvalanche(t *testing.T) {
+	// Flipping one input bit should flip about half the output bits.
+	total := 0
+	for bit := 0; bit < 64; bit++ {
+		total += bits.OnesCount64(Mix(0x0123456789abcdef) ^ Mix(0x0123456789abcdef^1<<bit))
+	}
+	if avg := float64(total) / 64; avg < 28 || avg > 36 {
+		t.Errorf("expected about 32 flipped bits on average, got %.1f", avg)
+	}
+}
+
+func TestSipHashReferenceVectors(t *testing.T) {
+	// Key 00 01 .. 0f and messages 00 01 .. of each length, from the
+	// reference implementation's test vectors.
+	const k0, k1 = 0x0706050403020100, 0x0f0e0d0c0b0a0908
+	msg := make([]byte, 16)
+	for i := range msg {
+		msg[i] = byte(i)
+	}
+	for n, want := range map[int]uint64{
+		0:  0x726fdb47dd0e0e31,
+		1:  0x74f839c593dc67fd,
+		7:  0xab0200f58b01d137,
+		8:  0x93f5f5799a932462,
+		15: 0xa129ca6149be45e5,
+	} {
+		if got := SipHash(k0, k1, string(msg[:n])); got != want {
+			t.Errorf("length %d: expected %#x, got %#x", n, want, got)
+		}
+	}
+}
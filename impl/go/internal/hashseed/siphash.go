@@ -0,0 +1,54 @@
+package hashseed
+
+import "math/bits"
+
+// SipHash returns the SipHash-2-4 of s under the 128-bit key k0, k1. Unlike
+// a seeded fast hash, SipHash is a pseudorandom function: without the key,
+// seeing hashes of chosen strings does not help predict others, so an
+// attacker cannot manufacture colliding keys. Derive(seed, 0) and
+// Derive(seed, 1) make a key from a seed.
+func SipHash(k0, k1 uint64, s string) uint64 {
+	v0 := k0 ^ 0x736f6d6570736575
+	v1 := k1 ^ 0x646f72616e646f6d
+	v2 := k0 ^ 0x6c7967656e657261
+	v3 := k1 ^ 0x7465646279746573
+
+	round := func() {
+		v0 += v1
+		v1 = bits.RotateLeft64(v1, 13) ^ v0
+		v0 = bits.RotateLeft64(v0, 32)
+		v2 += v3
+		v3 = bits.RotateLeft64(v3, 16) ^ v2
+		v0 += v3
+		v3 = bits.RotateLeft64(v3, 21) ^ v0
+		v2 += v1
+		v1 = bits.RotateLeft64(v1, 17) ^ v2
+		v2 = bits.RotateLeft64(v2, 32)
+	}
+
+	n := len(s)
+	for ; len(s) >= 8; s = s[8:] {
+		m := uint64(s[0]) | uint64(s[1])<<8 | uint64(s[2])<<16 | uint64(s[3])<<24 |
+			uint64(s[4])<<32 | uint64(s[5])<<40 | uint64(s[6])<<48 | uint64(s[7])<<56
+		v3 ^= m
+		round()
+		round()
+		v0 ^= m
+	}
+	// The last word holds the remaining bytes and the length mod 256.
+	m := uint64(n) << 56
+	for i := len(s) - 1; i >= 0; i-- {
+		m |= uint64(s[i]) << (8 * i)
+	}
+	v3 ^= m
+	round()
+	round()
+	v0 ^= m
+
+	v2 ^= 0xff
+	round()
+	round()
+	round()
+	round()
+	return v0 ^ v1 ^ v2 ^ v3
+}
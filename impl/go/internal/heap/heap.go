@@ -0,0 +1,125 @@
+// Package heap provides a generic binary heap. Unlike container/heap it needs
+// no adapter type per element type and returns elements without boxing them
+// in interfaces. Keyed adds changing the priority of a queued key, and
+// Leftist and Skew are pointer-based heaps that Meld two queues in O(log n)
+// where a binary heap must move one element at a time.
+package heap
+
+import (
+	"cmp"
+
+	"github.com/dsa-lab/go/internal/order"
+)
+
+// Heap is a binary min-heap under compare: Pop returns an element no other
+// element sorts before. Push and Pop are O(log n), Peek is O(1). Equal
+// elements leave in no particular order. It is not safe for concurrent use.
+type Heap[T any] struct {
+	items   []T
+	compare order.Comparator[T]
+
+	// observer, when set, receives internal events (see WithObserver).
+	observer Observer
+}
+
+// Option configures a Heap at construction time.
+type Option[T any] func(*Heap[T])
+
+// New creates an empty heap ordered by compare.
+func New[T any](compare order.Comparator[T], opts ...Option[T]) *Heap[T] {
+	h := &Heap[T]{compare: compare}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// NewOrdered creates an empty min-heap of naturally ordered values.
+func NewOrdered[T cmp.Ordered](opts ...Option[T]) *Heap[T] {
+	return New(order.Natural[T](), opts...)
+}
+
+// From creates a heap holding items, reusing the slice, in O(n).
+func From[T any](items []T, compare order.Comparator[T], opts ...Option[T]) *Heap[T] {
+	h := New(compare, opts...)
+	h.items = items
+	for i := len(items)/2 - 1; i >= 0; i-- {
+		h.down(i)
+	}
+	return h
+}
+
+// Len returns the number of elements.
+func (h *Heap[T]) Len() int {
+	return len(h.items)
+}
+
+// Push adds x.
+func (h *Heap[T]) Push(x T) {
+	h.items = append(h.items, x)
+	h.up(len(h.items) - 1)
+}
+
+// Peek returns the least element without removing it, or false if the heap
+// is empty.
+func (h *Heap[T]) Peek() (T, bool) {
+	if len(h.items) == 0 {
+		var zero T
+		return zero, false
+	}
+	return h.items[0], true
+}
+
+// Pop removes and returns the least element, or false if the heap is empty.
+func (h *Heap[T]) Pop() (T, bool) {
+	var zero T
+	n := len(h.items) - 1
+	if n < 0 {
+		return zero, false
+	}
+	top := h.items[0]
+	h.items[0] = h.items[n]
+	h.items[n] = zero
+	h.items = h.items[:n]
+	if n > 0 {
+		h.down(0)
+	}
+	return top, true
+}
+
+// Clear removes all elements.
+func (h *Heap[T]) Clear() {
+	clear(h.items)
+	h.items = h.items[:0]
+}
+
+func (h *Heap[T]) up(i int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if h.compare(h.items[i], h.items[parent]) >= 0 {
+			return
+		}
+		h.items[i], h.items[parent] = h.items[parent], h.items[i]
+		h.emit(EventSiftUp, parent, i)
+		i = parent
+	}
+}
+
+func (h *Heap[T]) down(i int) {
+	n := len(h.items)
+	for {
+		least := i
+		if l := 2*i + 1; l < n && h.compare(h.items[l], h.items[least]) < 0 {
+			least = l
+		}
+		if r := 2*i + 2; r < n && h.compare(h.items[r], h.items[least]) < 0 {
+			least = r
+		}
+		if least == i {
+			return
+		}
+		h.items[i], h.items[least] = h.items[least], h.items[i]
+		h.emit(EventSiftDown, i, least)
+		i = least
+	}
+}
@@ -0,0 +1,313 @@
+package heap
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/order"
+)
+
+func TestHeapSorts(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	h := NewOrdered[int]()
+	var model []int
+	for i := 0; i < 5000; i++ {
+		if rng.Intn(3) > 0 {
+			x := rng.Intn(1000)
+			h.Push(x)
+			model = append(model, x)
+			continue
+		}
+		got, ok := h.Pop()
+		if ok != (len(model) > 0) {
+			t.Fatalf("Pop returned %v with %d elements", ok, len(model))
+		}
+		if !ok {
+			continue
+		}
+		least := slices.Min(model)
+		if got != least {
+			t.Fatalf("expected %d, got %d", least, got)
+		}
+		model = slices.Delete(model, slices.Index(model, least), slices.Index(model, least)+1)
+		if h.Len() != len(model) {
+			t.Fatalf("expected length %d, got %d", len(model), h.Len())
+		}
+	}
+}
+
+func TestFromHeapifies(t *testing.T) {
+	words := []string{"pear", "fig", "apple", "kiwi", "banana", "cherry"}
+	// A max-heap by length, from an unordered slice.
+	h := From(slices.Clone(words), order.By(func(w string) int { return len(w) }).Reverse())
+	if top, _ := h.Peek(); len(top) != 6 {
+		t.Errorf("expected a six-letter word on top, got %s", top)
+	}
+	var lengths []int
+	for h.Len() > 0 {
+		w, _ := h.Pop()
+		lengths = append(lengths, len(w))
+	}
+	if !slices.IsSortedFunc(lengths, func(a, b int) int { return b - a }) {
+		t.Errorf("expected descending lengths, got %v", lengths)
+	}
+	if _, ok := h.Pop(); ok {
+		t.Error("expected Pop on an empty heap to fail")
+	}
+}
+
+func TestObserverSwaps(t *testing.T) {
+	var events []string
+	record := ObserverFunc(func(e Event) {
+		events = append(events, fmt.Sprintf("%s %d-%d/%d", e.Kind, e.Parent, e.Child, e.Len))
+	})
+	h := NewOrdered(WithObserver[int](record))
+	for _, x := range []int{3, 2, 1, 0} {
+		h.Push(x)
+	}
+	h.Pop()
+	want := []string{
+		"sift-up 0-1/2",
+		"sift-up 0-2/3",
+		"sift-up 1-3/4",
+		"sift-up 0-1/4",
+		"sift-down 0-1/3",
+	}
+	if fmt.Sprint(events) != fmt.Sprint(want) {
+		t.Errorf("expected events %v, got %v", want, events)
+	}
+}
+
+func TestKeyedModel(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	h := NewKeyedOrdered[int, int]()
+	model := make(map[int]int)
+	for i := 0; i < 20000; i++ {
+		key, priority := rng.Intn(200), rng.Intn(1000)
+		switch rng.Intn(5) {
+		case 0, 1:
+			_, present := model[key]
+			if added := h.Push(key, priority); added == present {
+				t.Fatalf("push %d: expected added=%v, got %v", key, !present, added)
+			}
+			model[key] = priority
+		case 2:
+			_, present := model[key]
+			if h.Update(key, priority) != present {
+				t.Fatalf("update %d: expected %v", key, present)
+			}
+			if present {
+				model[key] = priority
+			}
+		case 3:
+			want, present := model[key]
+			if got, ok := h.Remove(key); ok != present || got != want {
+				t.Fatalf("remove %d: expected %d %v, got %d %v", key, want, present, got, ok)
+			}
+			delete(model, key)
+		default:
+			key, priority, ok := h.Pop()
+			if ok != (len(model) > 0) {
+				t.Fatalf("pop: expected ok=%v", len(model) > 0)
+			}
+			if !ok {
+				continue
+			}
+			for k, p := range model {
+				if p < priority {
+					t.Fatalf("pop: got %d at %d, but %d has %d", key, priority, k, p)
+				}
+			}
+			if model[key] != priority {
+				t.Fatalf("pop: expected %d to have priority %d, got %d", key, model[key], priority)
+			}
+			delete(model, key)
+		}
+		if h.Len() != len(model) {
+			t.Fatalf("expected %d keys, got %d", len(model), h.Len())
+		}
+	}
+	for key, want := range model {
+		if got, ok := h.Priority(key); !ok || got != want || !h.Contains(key) {
+			t.Fatalf("priority %d: expected %d, got %d %v", key, want, got, ok)
+		}
+	}
+	h.Clear()
+	if _, _, ok := h.Peek(); ok || h.Contains(0) {
+		t.Error("expected an empty heap after Clear")
+	}
+}
+
+// TestKeyedDijkstra runs Dijkstra's algorithm, lowering queued distances
+// with Update, and checks the result against Bellman-Ford.
+func TestKeyedDijkstra(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	const n = 300
+	type edge struct{ to, w int }
+	adj := make([][]edge, n)
+	for i := 0; i < 3000; i++ {
+		u, v := rng.Intn(n), rng.Intn(n)
+		adj[u] = append(adj[u], edge{v, 1 + rng.Intn(100)})
+	}
+
+	const inf = 1 << 60
+	dist := make([]int, n)
+	for i := range dist {
+		dist[i] = inf
+	}
+	dist[0] = 0
+	h := NewKeyedOrdered[int, int]()
+	h.Push(0, 0)
+	for h.Len() > 0 {
+		u, d, _ := h.Pop()
+		for _, e := range adj[u] {
+			if nd := d + e.w; nd < dist[e.to] {
+				dist[e.to] = nd
+				h.Push(e.to, nd)
+			}
+		}
+	}
+
+	want := make([]int, n)
+	for i := range want {
+		want[i] = inf
+	}
+	want[0] = 0
+	for round := 0; round < n; round++ {
+		for u := range adj {
+			for _, e := range adj[u] {
+				if want[u] < inf && want[u]+e.w < want[e.to] {
+					want[e.to] = want[u] + e.w
+				}
+			}
+		}
+	}
+	if !slices.Equal(dist, want) {
+		t.Error("expected Dijkstra distances to match Bellman-Ford")
+	}
+}
+
+// meldable is the operation set shared by Leftist and Skew.
+type meldable[H any] interface {
+	Push(x int)
+	Pop() (int, bool)
+	Peek() (int, bool)
+	Len() int
+	Meld(other H)
+}
+
+// testMeld builds many heaps from random pushes and pops, melds random pairs
+// until one remains, and checks that it pops exactly the surviving elements
+// in order.
+func testMeld[H meldable[H]](t *testing.T, newHeap func() H) {
+	rng := rand.New(rand.NewSource(4))
+	heaps := make([]H, 64)
+	var want []int
+	for i := range heaps {
+		heaps[i] = newHeap()
+		var model []int
+		for j := rng.Intn(200); j > 0; j-- {
+			if rng.Intn(4) == 0 && len(model) > 0 {
+				slices.Sort(model)
+				if got, _ := heaps[i].Pop(); got != model[0] {
+					t.Fatalf("expected pop %d, got %d", model[0], got)
+				}
+				model = model[1:]
+				continue
+			}
+			x := rng.Intn(1000)
+			heaps[i].Push(x)
+			model = append(model, x)
+		}
+		want = append(want, model...)
+	}
+	for len(heaps) > 1 {
+		i := rng.Intn(len(heaps) - 1)
+		heaps[i].Meld(heaps[len(heaps)-1])
+		if heaps[len(heaps)-1].Len() != 0 {
+			t.Fatal("expected Meld to empty its argument")
+		}
+		heaps = heaps[:len(heaps)-1]
+	}
+	h := heaps[0]
+	h.Meld(h)
+	if h.Len() != len(want) {
+		t.Fatalf("expected %d elements after melding, got %d", len(want), h.Len())
+	}
+	slices.Sort(want)
+	for _, x := range want {
+		if top, _ := h.Peek(); top != x {
+			t.Fatalf("expected peek %d, got %d", x, top)
+		}
+		if got, _ := h.Pop(); got != x {
+			t.Fatalf("expected pop %d, got %d", x, got)
+		}
+	}
+	if _, ok := h.Pop(); ok {
+		t.Error("expected an empty heap")
+	}
+}
+
+func TestLeftistMeld(t *testing.T) {
+	testMeld(t, func() *Leftist[int] { return NewLeftistOrdered[int]() })
+}
+
+func TestSkewMeld(t *testing.T) {
+	testMeld(t, func() *Skew[int] { return NewSkewOrdered[int]() })
+}
+
+// TestSkewLongPath melds in an order that makes a skew heap's merge path as
+// long as the heap, which must not exhaust the stack.
+func TestSkewLongPath(t *testing.T) {
+	h := NewSkewOrdered[int]()
+	for i := 0; i < 1_000_000; i++ {
+		h.Push(-i)
+	}
+	for i := 0; i < 1_000_000; i++ {
+		if got, _ := h.Pop(); got != -(1_000_000 - 1 - i) {
+			t.Fatalf("expected pop %d, got %d", -(1_000_000 - 1 - i), got)
+		}
+	}
+}
+
+func TestVisualize(t *testing.T) {
+	h := NewOrdered[int]()
+	l := NewLeftistOrdered[int]()
+	s := NewSkewOrdered[int]()
+	for _, x := range []int{5, 3, 8, 1, 9, 2} {
+		h.Push(x)
+		l.Push(x)
+		s.Push(x)
+	}
+	for name, v := range map[string]interface{ Visualize(io.Writer) error }{"heap": h, "leftist": l, "skew": s} {
+		var buf strings.Builder
+		if err := v.Visualize(&buf); err != nil {
+			t.Fatal(err)
+		}
+		dot := buf.String()
+		if !strings.HasPrefix(dot, "digraph "+name+" {") {
+			t.Errorf("%s: expected a digraph, got:\n%s", name, dot)
+		}
+		// Every element but the root has one parent edge; a point standing
+		// in for a missing child has one too.
+		points := strings.Count(dot, "[shape=point]")
+		if n := strings.Count(dot, " -> "); n != 5+points {
+			t.Errorf("%s: expected %d edges, got %d:\n%s", name, 5+points, n, dot)
+		}
+	}
+
+	var buf strings.Builder
+	h.Visualize(&buf)
+	if !strings.Contains(buf.String(), `n0 [label="0: 1"]`) {
+		t.Errorf("heap: expected the minimum at the root, got:\n%s", buf.String())
+	}
+	buf.Reset()
+	l.Visualize(&buf)
+	if !strings.Contains(buf.String(), `n0 [label="1\nrank`) {
+		t.Errorf("leftist: expected the minimum at the root, got:\n%s", buf.String())
+	}
+}
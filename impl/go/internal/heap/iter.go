@@ -0,0 +1,30 @@
+//go:build go1.23
+
+package heap
+
+import "iter"
+
+// All returns an iterator over the elements in storage order, which is not
+// sorted, without removing them.
+func (h *Heap[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, x := range h.items {
+			if !yield(x) {
+				return
+			}
+		}
+	}
+}
+
+// Drain returns an iterator that pops the elements in order. Stopping early
+// leaves the rest in the heap.
+func (h *Heap[T]) Drain() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for h.Len() > 0 {
+			x, _ := h.Pop()
+			if !yield(x) {
+				return
+			}
+		}
+	}
+}
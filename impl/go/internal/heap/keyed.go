@@ -0,0 +1,185 @@
+package heap
+
+import (
+	"cmp"
+
+	"github.com/dsa-lab/go/internal/order"
+)
+
+// Keyed is a binary min-heap of keys ordered by a priority per key, with an
+// index from each key to its position in the heap. The index lets a caller
+// change or remove the priority of any key in O(log n), which a plain Heap
+// cannot do without scanning: Dijkstra's algorithm lowers the distance of a
+// vertex already queued, and a cache evicting by expiry or score reorders an
+// entry on every access. Each key is in the heap at most once. It is not safe
+// for concurrent use.
+type Keyed[K comparable, P any] struct {
+	items   []keyedItem[K, P]
+	pos     map[K]int
+	compare order.Comparator[P]
+}
+
+type keyedItem[K comparable, P any] struct {
+	key      K
+	priority P
+}
+
+// NewKeyed creates an empty keyed heap ordered by compare on priorities.
+func NewKeyed[K comparable, P any](compare order.Comparator[P]) *Keyed[K, P] {
+	return &Keyed[K, P]{pos: make(map[K]int), compare: compare}
+}
+
+// NewKeyedOrdered creates an empty keyed min-heap of naturally ordered
+// priorities.
+func NewKeyedOrdered[K comparable, P cmp.Ordered]() *Keyed[K, P] {
+	return NewKeyed[K](order.Natural[P]())
+}
+
+// Len returns the number of keys.
+func (h *Keyed[K, P]) Len() int {
+	return len(h.items)
+}
+
+// Contains reports whether key is in the heap.
+func (h *Keyed[K, P]) Contains(key K) bool {
+	_, ok := h.pos[key]
+	return ok
+}
+
+// Priority returns the priority of key, or false if key is not in the heap.
+func (h *Keyed[K, P]) Priority(key K) (P, bool) {
+	i, ok := h.pos[key]
+	if !ok {
+		var zero P
+		return zero, false
+	}
+	return h.items[i].priority, true
+}
+
+// Push adds key with priority, or sets the priority of key if it is already
+// in the heap. It reports whether key was added.
+func (h *Keyed[K, P]) Push(key K, priority P) bool {
+	if h.Update(key, priority) {
+		return false
+	}
+	h.items = append(h.items, keyedItem[K, P]{key, priority})
+	h.pos[key] = len(h.items) - 1
+	h.up(len(h.items) - 1)
+	return true
+}
+
+// Update sets the priority of key and moves it to its new place. It returns
+// false, changing nothing, if key is not in the heap.
+func (h *Keyed[K, P]) Update(key K, priority P) bool {
+	i, ok := h.pos[key]
+	if !ok {
+		return false
+	}
+	h.items[i].priority = priority
+	h.fix(i)
+	return true
+}
+
+// Peek returns the key with the least priority without removing it, or false
+// if the heap is empty.
+func (h *Keyed[K, P]) Peek() (K, P, bool) {
+	if len(h.items) == 0 {
+		var key K
+		var priority P
+		return key, priority, false
+	}
+	return h.items[0].key, h.items[0].priority, true
+}
+
+// Pop removes and returns the key with the least priority, or false if the
+// heap is empty.
+func (h *Keyed[K, P]) Pop() (K, P, bool) {
+	if len(h.items) == 0 {
+		var key K
+		var priority P
+		return key, priority, false
+	}
+	top := h.removeAt(0)
+	return top.key, top.priority, true
+}
+
+// Remove removes key and returns its priority, or false if key is not in the
+// heap.
+func (h *Keyed[K, P]) Remove(key K) (P, bool) {
+	i, ok := h.pos[key]
+	if !ok {
+		var zero P
+		return zero, false
+	}
+	return h.removeAt(i).priority, true
+}
+
+// Clear removes all keys.
+func (h *Keyed[K, P]) Clear() {
+	clear(h.items)
+	h.items = h.items[:0]
+	clear(h.pos)
+}
+
+// removeAt removes the item at position i, filling the hole with the last
+// item and restoring the heap around it.
+func (h *Keyed[K, P]) removeAt(i int) keyedItem[K, P] {
+	item := h.items[i]
+	delete(h.pos, item.key)
+	n := len(h.items) - 1
+	if i != n {
+		h.items[i] = h.items[n]
+		h.pos[h.items[i].key] = i
+	}
+	h.items[n] = keyedItem[K, P]{}
+	h.items = h.items[:n]
+	if i < n {
+		h.fix(i)
+	}
+	return item
+}
+
+// fix restores the heap after the priority at position i changed.
+func (h *Keyed[K, P]) fix(i int) {
+	if !h.up(i) {
+		h.down(i)
+	}
+}
+
+func (h *Keyed[K, P]) swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+	h.pos[h.items[i].key] = i
+	h.pos[h.items[j].key] = j
+}
+
+// up moves the item at i toward the root and reports whether it moved.
+func (h *Keyed[K, P]) up(i int) bool {
+	start := i
+	for i > 0 {
+		parent := (i - 1) / 2
+		if h.compare(h.items[i].priority, h.items[parent].priority) >= 0 {
+			break
+		}
+		h.swap(i, parent)
+		i = parent
+	}
+	return i != start
+}
+
+func (h *Keyed[K, P]) down(i int) {
+	n := len(h.items)
+	for {
+		least := i
+		if l := 2*i + 1; l < n && h.compare(h.items[l].priority, h.items[least].priority) < 0 {
+			least = l
+		}
+		if r := 2*i + 2; r < n && h.compare(h.items[r].priority, h.items[least].priority) < 0 {
+			least = r
+		}
+		if least == i {
+			return
+		}
+		h.swap(i, least)
+		i = least
+	}
+}
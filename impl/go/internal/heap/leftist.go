@@ -0,0 +1,113 @@
+package heap
+
+import (
+	"cmp"
+
+	"github.com/dsa-lab/go/internal/order"
+)
+
+// Leftist is a meldable min-heap under compare, stored as a leftist tree: every
+// node's left subtree has a right spine at least as long as its right
+// subtree's, so the right spine of a tree of n nodes has at most
+// log2(n+1) nodes. Merging walks only right spines, so Meld, Push and Pop
+// all take O(log n) worst-case time. Equal elements leave in no particular
+// order. It is not safe for concurrent use.
+type Leftist[T any] struct {
+	root    *leftistNode[T]
+	n       int
+	compare order.Comparator[T]
+}
+
+type leftistNode[T any] struct {
+	value       T
+	left, right *leftistNode[T]
+	// rank is the length of the right spine from this node, counting it.
+	rank int
+}
+
+// NewLeftist creates an empty leftist heap ordered by compare.
+func NewLeftist[T any](compare order.Comparator[T]) *Leftist[T] {
+	return &Leftist[T]{compare: compare}
+}
+
+// NewLeftistOrdered creates an empty leftist min-heap of naturally ordered
+// values.
+func NewLeftistOrdered[T cmp.Ordered]() *Leftist[T] {
+	return NewLeftist(order.Natural[T]())
+}
+
+// Len returns the number of elements.
+func (h *Leftist[T]) Len() int {
+	return h.n
+}
+
+// Push adds x.
+func (h *Leftist[T]) Push(x T) {
+	h.root = h.merge(h.root, &leftistNode[T]{value: x, rank: 1})
+	h.n++
+}
+
+// Peek returns the least element without removing it, or false if the heap
+// is empty.
+func (h *Leftist[T]) Peek() (T, bool) {
+	if h.root == nil {
+		var zero T
+		return zero, false
+	}
+	return h.root.value, true
+}
+
+// Pop removes and returns the least element, or false if the heap is empty.
+func (h *Leftist[T]) Pop() (T, bool) {
+	if h.root == nil {
+		var zero T
+		return zero, false
+	}
+	top := h.root
+	h.root = h.merge(top.left, top.right)
+	h.n--
+	return top.value, true
+}
+
+// Meld moves every element of other into h, leaving other empty. other must
+// be ordered by the same comparator as h.
+func (h *Leftist[T]) Meld(other *Leftist[T]) {
+	if other == h {
+		return
+	}
+	h.root = h.merge(h.root, other.root)
+	h.n += other.n
+	other.root, other.n = nil, 0
+}
+
+// Clear removes all elements.
+func (h *Leftist[T]) Clear() {
+	h.root, h.n = nil, 0
+}
+
+func leftistRank[T any](n *leftistNode[T]) int {
+	if n == nil {
+		return 0
+	}
+	return n.rank
+}
+
+// merge merges two leftist trees down their right spines, swapping children
+// on the way back up wherever the right spine became the longer one.
+func (h *Leftist[T]) merge(a, b *leftistNode[T]) *leftistNode[T] {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	if h.compare(b.value, a.value) < 0 {
+		a, b = b, a
+	}
+	a.right = h.merge(a.right, b)
+	if leftistRank(a.left) < leftistRank(a.right) {
+		a.left, a.right = a.right, a.left
+	}
+	a.rank = leftistRank(a.right) + 1
+	return a
+}
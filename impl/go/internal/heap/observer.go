@@ -0,0 +1,60 @@
+package heap
+
+// EventKind identifies an internal step reported to an Observer.
+type EventKind int
+
+const (
+	// EventSiftUp is reported when Push moves an element above its parent.
+	EventSiftUp EventKind = iota
+	// EventSiftDown is reported when Pop or From moves an element below its
+	// lesser child.
+	EventSiftDown
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventSiftUp:
+		return "sift-up"
+	case EventSiftDown:
+		return "sift-down"
+	}
+	return "unknown"
+}
+
+// Event describes one swap of a heap operation. Indices are positions in
+// the array layout, where the children of i are 2i+1 and 2i+2.
+type Event struct {
+	Kind EventKind
+	// Parent and Child are the swapped positions; Child is Parent's child.
+	Parent, Child int
+	// Len is the number of elements in the heap.
+	Len int
+}
+
+// Observer receives internal events as they happen, in order. It is meant for
+// teaching front-ends that animate the algorithm; observers must not modify
+// the heap.
+type Observer interface {
+	Observe(e Event)
+}
+
+// ObserverFunc adapts an ordinary function to the Observer interface.
+type ObserverFunc func(e Event)
+
+// Observe calls f(e).
+func (f ObserverFunc) Observe(e Event) {
+	f(e)
+}
+
+// WithObserver reports every swap made while sifting to o.
+func WithObserver[T any](o Observer) Option[T] {
+	return func(h *Heap[T]) {
+		h.observer = o
+	}
+}
+
+func (h *Heap[T]) emit(kind EventKind, parent, child int) {
+	if h.observer != nil {
+		h.observer.Observe(Event{Kind: kind, Parent: parent, Child: child, Len: len(h.items)})
+	}
+}
@@ -0,0 +1,114 @@
+package heap
+
+import (
+	"cmp"
+
+	"github.com/dsa-lab/go/internal/order"
+)
+
+// Skew is a meldable min-heap under compare, stored as a skew heap: a binary
+// tree merged like a leftist tree but keeping no rank, swapping the children
+// of every node on the merge path instead. A single operation may take O(n)
+// time, but Meld, Push and Pop take O(log n) amortized, and nodes are a word
+// smaller than a Leftist's. Equal elements leave in no particular order. It
+// is not safe for concurrent use.
+type Skew[T any] struct {
+	root    *skewNode[T]
+	n       int
+	compare order.Comparator[T]
+}
+
+type skewNode[T any] struct {
+	value       T
+	left, right *skewNode[T]
+}
+
+// NewSkew creates an empty skew heap ordered by compare.
+func NewSkew[T any](compare order.Comparator[T]) *Skew[T] {
+	return &Skew[T]{compare: compare}
+}
+
+// NewSkewOrdered creates an empty skew min-heap of naturally ordered
+// values.
+func NewSkewOrdered[T cmp.Ordered]() *Skew[T] {
+	return NewSkew(order.Natural[T]())
+}
+
+// Len returns the number of elements.
+func (h *Skew[T]) Len() int {
+	return h.n
+}
+
+// Push adds x.
+func (h *Skew[T]) Push(x T) {
+	h.root = h.merge(h.root, &skewNode[T]{value: x})
+	h.n++
+}
+
+// Peek returns the least element without removing it, or false if the heap
+// is empty.
+func (h *Skew[T]) Peek() (T, bool) {
+	if h.root == nil {
+		var zero T
+		return zero, false
+	}
+	return h.root.value, true
+}
+
+// Pop removes and returns the least element, or false if the heap is empty.
+func (h *Skew[T]) Pop() (T, bool) {
+	if h.root == nil {
+		var zero T
+		return zero, false
+	}
+	top := h.root
+	h.root = h.merge(top.left, top.right)
+	h.n--
+	return top.value, true
+}
+
+// Meld moves every element of other into h, leaving other empty. other must
+// be ordered by the same comparator as h.
+func (h *Skew[T]) Meld(other *Skew[T]) {
+	if other == h {
+		return
+	}
+	h.root = h.merge(h.root, other.root)
+	h.n += other.n
+	other.root, other.n = nil, 0
+}
+
+// Clear removes all elements.
+func (h *Skew[T]) Clear() {
+	h.root, h.n = nil, 0
+}
+
+// merge merges two skew heaps top-down. Each node on the merge path moves
+// its left child to the right and takes the merge of its old right child and
+// the other tree as its left. It loops rather than recursing, since the path
+// may be as long as the heap.
+func (h *Skew[T]) merge(a, b *skewNode[T]) *skewNode[T] {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	if h.compare(b.value, a.value) < 0 {
+		a, b = b, a
+	}
+	root := a
+	for {
+		r := a.right
+		a.right = a.left
+		if r == nil {
+			a.left = b
+			return root
+		}
+		if h.compare(b.value, r.value) < 0 {
+			r, b = b, r
+		}
+		a.left = r
+		a = r
+	}
+}
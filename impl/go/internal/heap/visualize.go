@@ -0,0 +1,110 @@
+package heap
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Visualize writes the heap as a Graphviz DOT graph of the complete binary
+// tree its slice encodes, each node labelled with its index and element, so
+// the order between parents and children and the sift paths are visible.
+// Elements are formatted with fmt.Sprint.
+func (h *Heap[T]) Visualize(w io.Writer) error {
+	var b strings.Builder
+	beginHeap(&b, "heap", len(h.items) == 0)
+	for i, x := range h.items {
+		fmt.Fprintf(&b, "  n%d [label=\"%d: %s\"];\n", i, i, dotEscape(fmt.Sprint(x)))
+		if i > 0 {
+			fmt.Fprintf(&b, "  n%d -> n%d;\n", (i-1)/2, i)
+		}
+	}
+	return endHeap(w, &b)
+}
+
+// Visualize writes the heap as a Graphviz DOT graph, each node labelled with
+// its element and its rank, the length of its right spine, so the leftist
+// property (no left child of lower rank than its sibling) can be checked at
+// a glance. Elements are formatted with fmt.Sprint.
+func (h *Leftist[T]) Visualize(w io.Writer) error {
+	var b strings.Builder
+	beginHeap(&b, "leftist", h.n == 0)
+	next := 0
+	var walk func(n *leftistNode[T]) string
+	walk = func(n *leftistNode[T]) string {
+		id := fmt.Sprintf("n%d", next)
+		next++
+		fmt.Fprintf(&b, "  %s [label=\"%s\\nrank %d\"];\n", id, dotEscape(fmt.Sprint(n.value)), n.rank)
+		writeChildren(&b, id, n.left != nil, n.right != nil, func(right bool) string {
+			if right {
+				return walk(n.right)
+			}
+			return walk(n.left)
+		})
+		return id
+	}
+	if h.root != nil {
+		walk(h.root)
+	}
+	return endHeap(w, &b)
+}
+
+// Visualize writes the heap as a Graphviz DOT graph of its tree. Elements are
+// formatted with fmt.Sprint.
+func (h *Skew[T]) Visualize(w io.Writer) error {
+	var b strings.Builder
+	beginHeap(&b, "skew", h.n == 0)
+	next := 0
+	var walk func(n *skewNode[T]) string
+	walk = func(n *skewNode[T]) string {
+		id := fmt.Sprintf("n%d", next)
+		next++
+		fmt.Fprintf(&b, "  %s [label=\"%s\"];\n", id, dotEscape(fmt.Sprint(n.value)))
+		writeChildren(&b, id, n.left != nil, n.right != nil, func(right bool) string {
+			if right {
+				return walk(n.right)
+			}
+			return walk(n.left)
+		})
+		return id
+	}
+	if h.root != nil {
+		walk(h.root)
+	}
+	return endHeap(w, &b)
+}
+
+func beginHeap(b *strings.Builder, name string, empty bool) {
+	fmt.Fprintf(b, "digraph %s {\n", name)
+	b.WriteString("  node [shape=circle, fontname=\"monospace\"];\n")
+	if empty {
+		b.WriteString("  empty [shape=plaintext, label=\"empty\"];\n")
+	}
+}
+
+func endHeap(w io.Writer, b *strings.Builder) error {
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeChildren emits the edges from id to the children child draws. A
+// missing child of a node with one child is drawn as a point to keep left
+// and right apart.
+func writeChildren(b *strings.Builder, id string, left, right bool, child func(right bool) string) {
+	if !left && !right {
+		return
+	}
+	for i, present := range []bool{left, right} {
+		if !present {
+			fmt.Fprintf(b, "  %s_%d [shape=point];\n  %s -> %s_%d;\n", id, i, id, id, i)
+			continue
+		}
+		fmt.Fprintf(b, "  %s -> %s;\n", id, child(i == 1))
+	}
+}
+
+// dotEscape escapes s for use inside a quoted DOT string.
+func dotEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
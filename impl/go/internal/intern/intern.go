@@ -0,0 +1,72 @@
+// Package intern deduplicates repeated strings. Keys parsed from a workload or
+// a log arrive as fresh allocations even when they repeat, so a structure
+// that keeps them holds one copy per insert; passing them through an Interner
+// first leaves one shared copy per distinct string.
+package intern
+
+import "strings"
+
+// Interner maps each distinct string to a canonical copy. It is not safe for
+// concurrent use; share one between structures only from a single goroutine.
+type Interner struct {
+	table map[string]string
+	stats Stats
+}
+
+// Stats describes the work an Interner has saved.
+type Stats struct {
+	// Distinct is the number of canonical strings held, and Bytes their
+	// total length.
+	Distinct int
+	Bytes    int
+	// Hits counts calls that returned an existing copy; SavedBytes is the
+	// length of the duplicates those calls made collectable.
+	Hits       uint64
+	Misses     uint64
+	SavedBytes uint64
+}
+
+// New creates an empty Interner.
+func New() *Interner {
+	return &Interner{table: make(map[string]string)}
+}
+
+// Intern returns the canonical copy of s, storing a copy of s if it is new.
+// The stored copy is cloned so that interning a substring does not pin the
+// larger buffer it was cut from.
+func (in *Interner) Intern(s string) string {
+	if canonical, ok := in.table[s]; ok {
+		in.stats.Hits++
+		in.stats.SavedBytes += uint64(len(s))
+		return canonical
+	}
+	canonical := strings.Clone(s)
+	in.table[canonical] = canonical
+	in.stats.Misses++
+	in.stats.Distinct++
+	in.stats.Bytes += len(canonical)
+	return canonical
+}
+
+// Lookup returns the canonical copy of s without adding it.
+func (in *Interner) Lookup(s string) (string, bool) {
+	canonical, ok := in.table[s]
+	return canonical, ok
+}
+
+// Len returns the number of distinct strings held.
+func (in *Interner) Len() int {
+	return len(in.table)
+}
+
+// Stats returns the interner's counters.
+func (in *Interner) Stats() Stats {
+	return in.stats
+}
+
+// Reset drops every canonical string, letting them be collected once no
+// structure refers to them. Counters are kept.
+func (in *Interner) Reset() {
+	clear(in.table)
+	in.stats.Distinct, in.stats.Bytes = 0, 0
+}
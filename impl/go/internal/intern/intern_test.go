@@ -0,0 +1,38 @@
+package intern
+
+import (
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+func TestIntern(t *testing.T) {
+	in := New()
+	line := "GET /index.html 200"
+	a := in.Intern(line[4:15])
+	b := in.Intern(strings.Clone("/index.html"))
+
+	if a != "/index.html" || b != a {
+		t.Fatalf("expected /index.html twice, got %q and %q", a, b)
+	}
+	if unsafe.StringData(a) != unsafe.StringData(b) {
+		t.Error("expected both calls to return the same backing array")
+	}
+	if unsafe.StringData(a) == unsafe.StringData(line[4:15]) {
+		t.Error("expected the canonical copy not to alias the input buffer")
+	}
+
+	in.Intern("other")
+	s := in.Stats()
+	if s.Distinct != 2 || s.Bytes != 16 || s.Hits != 1 || s.Misses != 2 || s.SavedBytes != 11 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+	if _, ok := in.Lookup("missing"); ok || in.Len() != 2 {
+		t.Errorf("expected lookup not to add, got len %d", in.Len())
+	}
+
+	in.Reset()
+	if in.Len() != 0 || in.Stats().Hits != 1 {
+		t.Errorf("expected reset to drop strings and keep counters, got %+v", in.Stats())
+	}
+}
@@ -0,0 +1,323 @@
+package interval
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+const domain = 100
+
+func randomInterval(rng *rand.Rand) Interval[int] {
+	start := rng.Intn(domain)
+	return Interval[int]{start, start + 1 + rng.Intn(20)}
+}
+
+func TestTreeAgainstBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	tree := NewTree[int, int]()
+	model := make(map[Interval[int]]int)
+
+	for i := 0; i < 5000; i++ {
+		iv := randomInterval(rng)
+		switch rng.Intn(3) {
+		case 0, 1:
+			got, gotExisted := tree.Insert(iv, i)
+			want, wantExisted := model[iv]
+			model[iv] = i
+			if got != want || gotExisted != wantExisted {
+				t.Fatalf("Insert(%v): expected (%d, %v), got (%d, %v)", iv, want, wantExisted, got, gotExisted)
+			}
+		case 2:
+			got, gotExisted := tree.Remove(iv)
+			want, wantExisted := model[iv]
+			delete(model, iv)
+			if got != want || gotExisted != wantExisted {
+				t.Fatalf("Remove(%v): expected (%d, %v), got (%d, %v)", iv, want, wantExisted, got, gotExisted)
+			}
+		}
+		if err := tree.CheckInvariants(); err != nil {
+			t.Fatalf("after op %d: %v", i, err)
+		}
+		if tree.Len() != len(model) {
+			t.Fatalf("expected length %d, got %d", len(model), tree.Len())
+		}
+
+		p := rng.Intn(domain + 20)
+		want := 0
+		for iv := range model {
+			if iv.Contains(p) {
+				want++
+			}
+		}
+		got := 0
+		var prev *Interval[int]
+		tree.Stab(p, func(iv Interval[int], v int) bool {
+			if !iv.Contains(p) || model[iv] != v {
+				t.Fatalf("Stab(%d) reported %v=%d", p, iv, v)
+			}
+			if prev != nil && compareIntervals(*prev, iv) >= 0 {
+				t.Fatalf("Stab(%d) out of order: %v then %v", p, *prev, iv)
+			}
+			prev = &iv
+			got++
+			return true
+		})
+		if got != want {
+			t.Fatalf("Stab(%d): expected %d intervals, got %d", p, want, got)
+		}
+
+		q := randomInterval(rng)
+		want = 0
+		for iv := range model {
+			if iv.Overlaps(q) {
+				want++
+			}
+		}
+		got = 0
+		tree.Overlap(q, func(iv Interval[int], v int) bool {
+			if !iv.Overlaps(q) {
+				t.Fatalf("Overlap(%v) reported %v", q, iv)
+			}
+			got++
+			return true
+		})
+		if got != want {
+			t.Fatalf("Overlap(%v): expected %d intervals, got %d", q, want, got)
+		}
+		if found, _, ok := tree.FindOverlap(q); ok != (want > 0) || (ok && !found.Overlaps(q)) {
+			t.Fatalf("FindOverlap(%v): got %v, %v with %d overlapping", q, found, ok, want)
+		}
+	}
+}
+
+func TestTreeEarlyStopAndEmpty(t *testing.T) {
+	tree := NewTree[int, string]()
+	if _, ok := tree.Insert(Interval[int]{5, 5}, "empty"); ok || tree.Len() != 0 {
+		t.Errorf("expected empty interval to be ignored")
+	}
+	for i := 0; i < 10; i++ {
+		tree.Insert(Interval[int]{i, 10}, "x")
+	}
+	calls := 0
+	tree.Stab(9, func(Interval[int], string) bool {
+		calls++
+		return calls < 3
+	})
+	if calls != 3 {
+		t.Errorf("expected Stab to stop after 3 calls, got %d", calls)
+	}
+	if _, _, ok := tree.FindOverlap(Interval[int]{10, 20}); ok {
+		t.Errorf("expected no overlap with [10, 20)")
+	}
+	tree.Clear()
+	if !tree.IsEmpty() {
+		t.Errorf("expected empty tree after Clear")
+	}
+}
+
+// pointModel is a brute-force interval map over [0, domain+20).
+type pointModel struct {
+	value [domain + 20]int
+	set   [domain + 20]bool
+}
+
+func TestTreeObserverRotations(t *testing.T) {
+	var events []string
+	record := ObserverFunc[int](func(e Event[int]) {
+		events = append(events, fmt.Sprintf("%s %v %v", e.Kind, e.Pivot, e.Child))
+	})
+	tree := NewTree(WithObserver[int, string](record))
+	for _, start := range []int{0, 1, 2, 5, 4} {
+		tree.Insert(Interval[int]{start, start + 1}, "")
+	}
+	want := []string{
+		"rotate-left [0, 1) [1, 2)",
+		"rotate-right [5, 6) [4, 5)",
+		"rotate-left [2, 3) [4, 5)",
+	}
+	if fmt.Sprint(events) != fmt.Sprint(want) {
+		t.Errorf("expected events %v, got %v", want, events)
+	}
+	if err := tree.CheckInvariants(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMapAgainstBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	m := NewMap[int, int]()
+	var model pointModel
+
+	for i := 0; i < 5000; i++ {
+		iv := randomInterval(rng)
+		// A small value alphabet makes merges of equal neighbours common.
+		value := rng.Intn(3)
+		if rng.Intn(4) == 0 {
+			m.Delete(iv)
+			for p := iv.Start; p < iv.End; p++ {
+				model.set[p] = false
+			}
+		} else {
+			m.Set(iv, value)
+			for p := iv.Start; p < iv.End; p++ {
+				model.value[p], model.set[p] = value, true
+			}
+		}
+		if err := m.CheckInvariants(); err != nil {
+			t.Fatalf("after op %d: %v", i, err)
+		}
+		for p := range model.value {
+			got, ok := m.Get(p)
+			if ok != model.set[p] || (ok && got != model.value[p]) {
+				t.Fatalf("after op %d: Get(%d) = (%d, %v), expected (%d, %v)", i, p, got, ok, model.value[p], model.set[p])
+			}
+		}
+	}
+}
+
+func TestMapBooking(t *testing.T) {
+	// A room calendar: book hours, cancel part of a booking, and look up
+	// who holds a slot.
+	cal := NewMap[int, string]()
+	cal.Set(Interval[int]{9, 12}, "alice")
+	cal.Set(Interval[int]{12, 14}, "alice")
+	if cal.Len() != 1 {
+		t.Fatalf("expected adjacent bookings to merge, got %v", cal.Spans())
+	}
+	cal.Set(Interval[int]{10, 11}, "bob")
+	want := []Span[int, string]{
+		{Interval[int]{9, 10}, "alice"},
+		{Interval[int]{10, 11}, "bob"},
+		{Interval[int]{11, 14}, "alice"},
+	}
+	got := cal.Spans()
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("span %d: expected %v, got %v", i, want[i], got[i])
+		}
+	}
+
+	cal.Delete(Interval[int]{10, 11})
+	if who, ok := cal.Get(10); ok {
+		t.Errorf("expected 10 to be free, held by %s", who)
+	}
+	var holders []string
+	cal.Overlap(Interval[int]{8, 12}, func(s Span[int, string]) bool {
+		holders = append(holders, s.Value)
+		return true
+	})
+	if len(holders) != 2 {
+		t.Errorf("expected 2 spans overlapping [8, 12), got %v", holders)
+	}
+}
+
+func TestSetAgainstBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	s := NewSet[int]()
+	var model [domain + 20]bool
+	bounds := Interval[int]{0, len(model)}
+
+	for i := 0; i < 5000; i++ {
+		iv := randomInterval(rng)
+		switch rng.Intn(3) {
+		case 0:
+			s.Remove(iv)
+			for p := iv.Start; p < iv.End; p++ {
+				model[p] = false
+			}
+		case 1:
+			s.Insert(iv)
+			for p := iv.Start; p < iv.End; p++ {
+				model[p] = true
+			}
+		case 2:
+			size := 1 + rng.Intn(8)
+			want, wantOK := -1, false
+			for start := 0; start+size <= len(model) && !wantOK; start++ {
+				free := true
+				for p := start; p < start+size; p++ {
+					free = free && !model[p]
+				}
+				if free {
+					want, wantOK = start, true
+				}
+			}
+			got, ok := s.FirstFree(size, bounds)
+			if ok != wantOK || (ok && got != want) {
+				t.Fatalf("after op %d: FirstFree(%d) = (%d, %v), expected (%d, %v)", i, size, got, ok, want, wantOK)
+			}
+		}
+		if err := s.CheckInvariants(); err != nil {
+			t.Fatalf("after op %d: %v", i, err)
+		}
+		for p, want := range model {
+			if s.Contains(p) != want {
+				t.Fatalf("after op %d: Contains(%d) should be %v", i, p, want)
+			}
+		}
+	}
+}
+
+func TestSetAllocator(t *testing.T) {
+	// Hand out ephemeral ports, release some, and check that freed ports
+	// are reused first-fit.
+	ports := NewSet[uint16]()
+	bounds := Interval[uint16]{49152, 65535}
+	for i := 0; i < 4; i++ {
+		if iv, ok := ports.Allocate(10, bounds); !ok || iv.Start != 49152+uint16(10*i) {
+			t.Fatalf("expected block %d at %d, got %v", i, 49152+10*i, iv)
+		}
+	}
+	if ports.Len() != 1 || !ports.ContainsRange(Interval[uint16]{49152, 49192}) {
+		t.Errorf("expected adjacent blocks to merge into one range, got %v", ports.Ranges())
+	}
+
+	ports.Remove(Interval[uint16]{49160, 49175})
+	if ports.Len() != 2 || ports.Contains(49170) {
+		t.Errorf("expected the release to split the range, got %v", ports.Ranges())
+	}
+	if start, ok := ports.FirstFree(20, bounds); !ok || start != 49192 {
+		t.Errorf("expected a 20-port gap only after the blocks, got %d, %v", start, ok)
+	}
+	if iv, ok := ports.Allocate(15, bounds); !ok || iv.Start != 49160 {
+		t.Errorf("expected the released gap to be reused, got %v", iv)
+	}
+	if _, ok := ports.FirstFree(1, Interval[uint16]{49152, 49192}); ok {
+		t.Error("expected no free port in a fully allocated window")
+	}
+	if _, ok := ports.FirstFree(65535, bounds); ok {
+		t.Error("expected a request larger than the bounds to fail")
+	}
+}
+
+func TestVisualize(t *testing.T) {
+	tree := NewTree[int, string]()
+	// Ascending inserts force rotations; the result is perfectly balanced.
+	for i := 0; i < 7; i++ {
+		tree.Insert(Interval[int]{i, i + 10}, fmt.Sprint("v", i))
+	}
+	var buf strings.Builder
+	if err := tree.Visualize(&buf); err != nil {
+		t.Fatal(err)
+	}
+	dot := buf.String()
+	for _, want := range []string{
+		"digraph interval {",
+		"[3, 13)",
+		"max 16",
+		"h 3, bal +0",
+		"n0 -> n1;",
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("expected DOT containing %q, got:\n%s", want, dot)
+		}
+	}
+	if n := strings.Count(dot, " -> "); n != 6 {
+		t.Errorf("expected 6 edges in a tree of 7, got %d", n)
+	}
+}
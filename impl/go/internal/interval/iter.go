@@ -0,0 +1,31 @@
+//go:build go1.23
+
+package interval
+
+import "iter"
+
+// All returns an iterator over the stored intervals and their values,
+// ordered by start and then end.
+func (t *Tree[K, V]) All() iter.Seq2[Interval[K], V] {
+	return t.Range
+}
+
+// All returns an iterator over the spans in order.
+func (m *Map[K, V]) All() iter.Seq[Span[K, V]] {
+	return func(yield func(Span[K, V]) bool) {
+		for _, s := range m.spans {
+			if !yield(s) {
+				return
+			}
+		}
+	}
+}
+
+// All returns an iterator over the maximal ranges in ascending order.
+func (s *Set[K]) All() iter.Seq[Interval[K]] {
+	return func(yield func(Interval[K]) bool) {
+		s.ranges.Range(func(start, end K) bool {
+			return yield(Interval[K]{Start: start, End: end})
+		})
+	}
+}
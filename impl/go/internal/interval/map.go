@@ -0,0 +1,154 @@
+package interval
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// Span is a maximal range of a Map holding a single value.
+type Span[K cmp.Ordered, V comparable] struct {
+	Interval[K]
+	Value V
+}
+
+// Map assigns values to ranges of keys. Assigning a range overwrites whatever
+// it covers, splitting spans at its edges, and neighbouring spans with equal
+// values are merged, so the map always holds the fewest spans that describe
+// it. Spans are kept in a sorted slice: Get is O(log n) and Set and Delete
+// are O(log n + s) where s is the number of spans shifted in the slice.
+type Map[K cmp.Ordered, V comparable] struct {
+	spans []Span[K, V]
+}
+
+// NewMap creates an empty Map.
+func NewMap[K cmp.Ordered, V comparable]() *Map[K, V] {
+	return &Map[K, V]{}
+}
+
+// Len returns the number of spans.
+func (m *Map[K, V]) Len() int {
+	return len(m.spans)
+}
+
+// IsEmpty returns true if no key is assigned.
+func (m *Map[K, V]) IsEmpty() bool {
+	return len(m.spans) == 0
+}
+
+// Clear removes all spans.
+func (m *Map[K, V]) Clear() {
+	m.spans = m.spans[:0]
+}
+
+// Get returns the value assigned to p.
+func (m *Map[K, V]) Get(p K) (V, bool) {
+	i := sort.Search(len(m.spans), func(i int) bool { return m.spans[i].End > p })
+	if i < len(m.spans) && m.spans[i].Start <= p {
+		return m.spans[i].Value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// cut returns the index range [lo, hi) of spans overlapping iv along with the
+// parts of the first and last of them that stick out past iv's edges.
+func (m *Map[K, V]) cut(iv Interval[K]) (lo, hi int, keep []Span[K, V]) {
+	lo = sort.Search(len(m.spans), func(i int) bool { return m.spans[i].End > iv.Start })
+	hi = sort.Search(len(m.spans), func(i int) bool { return m.spans[i].Start >= iv.End })
+	if lo < hi && m.spans[lo].Start < iv.Start {
+		left := m.spans[lo]
+		left.End = iv.Start
+		keep = append(keep, left)
+	}
+	if lo < hi && m.spans[hi-1].End > iv.End {
+		right := m.spans[hi-1]
+		right.Start = iv.End
+		keep = append(keep, right)
+	}
+	return lo, hi, keep
+}
+
+// Set assigns value to every key in iv, replacing earlier assignments there.
+func (m *Map[K, V]) Set(iv Interval[K], value V) {
+	if iv.Empty() {
+		return
+	}
+	lo, hi, keep := m.cut(iv)
+	span := Span[K, V]{Interval: iv, Value: value}
+	replacement := make([]Span[K, V], 0, 3)
+	i := lo // index of span after the replacement
+	for _, k := range keep {
+		if k.End <= iv.Start {
+			replacement = append(replacement, k)
+			i++
+		}
+	}
+	replacement = append(replacement, span)
+	for _, k := range keep {
+		if k.Start >= iv.End {
+			replacement = append(replacement, k)
+		}
+	}
+	m.spans = slices.Replace(m.spans, lo, hi, replacement...)
+
+	// Merge with equal-valued neighbours that touch the new span.
+	if next := i + 1; next < len(m.spans) && m.spans[next].Start == m.spans[i].End && m.spans[next].Value == value {
+		m.spans[i].End = m.spans[next].End
+		m.spans = slices.Delete(m.spans, next, next+1)
+	}
+	if prev := i - 1; prev >= 0 && m.spans[prev].End == m.spans[i].Start && m.spans[prev].Value == value {
+		m.spans[prev].End = m.spans[i].End
+		m.spans = slices.Delete(m.spans, i, i+1)
+	}
+}
+
+// Delete unassigns every key in iv.
+func (m *Map[K, V]) Delete(iv Interval[K]) {
+	if iv.Empty() {
+		return
+	}
+	lo, hi, keep := m.cut(iv)
+	m.spans = slices.Replace(m.spans, lo, hi, keep...)
+}
+
+// Overlap calls f for every span sharing a point with q, in order, until f
+// returns false. Spans are reported whole, not clipped to q.
+func (m *Map[K, V]) Overlap(q Interval[K], f func(span Span[K, V]) bool) {
+	if q.Empty() {
+		return
+	}
+	i := sort.Search(len(m.spans), func(i int) bool { return m.spans[i].End > q.Start })
+	for ; i < len(m.spans) && m.spans[i].Start < q.End; i++ {
+		if !f(m.spans[i]) {
+			return
+		}
+	}
+}
+
+// Spans returns a copy of all spans in order.
+func (m *Map[K, V]) Spans() []Span[K, V] {
+	return slices.Clone(m.spans)
+}
+
+// CheckInvariants verifies that spans are non-empty, sorted, disjoint and
+// maximal.
+func (m *Map[K, V]) CheckInvariants() error {
+	for i, s := range m.spans {
+		if s.Empty() {
+			return fmt.Errorf("span %d %v is empty", i, s.Interval)
+		}
+		if i == 0 {
+			continue
+		}
+		prev := m.spans[i-1]
+		if prev.End > s.Start {
+			return fmt.Errorf("span %d %v overlaps or precedes %v", i, s.Interval, prev.Interval)
+		}
+		if prev.End == s.Start && prev.Value == s.Value {
+			return fmt.Errorf("spans %v and %v touch with equal values", prev.Interval, s.Interval)
+		}
+	}
+	return nil
+}
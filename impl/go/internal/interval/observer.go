@@ -0,0 +1,61 @@
+package interval
+
+import "cmp"
+
+// EventKind identifies an internal step reported to an Observer.
+type EventKind int
+
+const (
+	// EventRotateLeft is reported when a node's right child takes its place.
+	EventRotateLeft EventKind = iota
+	// EventRotateRight is reported when a node's left child takes its place.
+	EventRotateRight
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventRotateLeft:
+		return "rotate-left"
+	case EventRotateRight:
+		return "rotate-right"
+	}
+	return "unknown"
+}
+
+// Event describes one rotation made while rebalancing a Tree. A double
+// rotation is reported as its two single rotations, lower one first.
+type Event[K cmp.Ordered] struct {
+	Kind EventKind
+	// Pivot is the interval of the node that moves down.
+	Pivot Interval[K]
+	// Child is the interval of the child that moves up in its place.
+	Child Interval[K]
+}
+
+// Observer receives internal events as they happen, in order. It is meant for
+// teaching front-ends that animate the algorithm; observers must not modify
+// the tree.
+type Observer[K cmp.Ordered] interface {
+	Observe(e Event[K])
+}
+
+// ObserverFunc adapts an ordinary function to the Observer interface.
+type ObserverFunc[K cmp.Ordered] func(e Event[K])
+
+// Observe calls f(e).
+func (f ObserverFunc[K]) Observe(e Event[K]) {
+	f(e)
+}
+
+// WithObserver reports every rotation of the tree to o.
+func WithObserver[K cmp.Ordered, V any](o Observer[K]) TreeOption[K, V] {
+	return func(t *Tree[K, V]) {
+		t.observer = o
+	}
+}
+
+func (t *Tree[K, V]) emit(kind EventKind, pivot, child Interval[K]) {
+	if t.observer != nil {
+		t.observer.Observe(Event[K]{Kind: kind, Pivot: pivot, Child: child})
+	}
+}
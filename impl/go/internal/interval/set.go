@@ -0,0 +1,162 @@
+package interval
+
+import (
+	"fmt"
+
+	"github.com/dsa-lab/go/internal/aggregate"
+	"github.com/dsa-lab/go/internal/skiplist"
+)
+
+// Set is a set of integers stored as disjoint ranges, the bookkeeping behind
+// port, ID and disk-block allocators. Overlapping and adjacent ranges are
+// merged on insert, so the set always holds the fewest ranges covering it.
+// Ranges live in a skip list from start to end: Contains is O(log n), and
+// Insert and Remove are O(log n) per range they merge or split.
+type Set[K aggregate.Integer] struct {
+	ranges *skiplist.SkipList[K, K]
+}
+
+// NewSet creates an empty Set.
+func NewSet[K aggregate.Integer]() *Set[K] {
+	return &Set[K]{ranges: skiplist.MustNew[K, K]()}
+}
+
+// Len returns the number of disjoint ranges.
+func (s *Set[K]) Len() int {
+	return s.ranges.Len()
+}
+
+// IsEmpty returns true if the set holds no integers.
+func (s *Set[K]) IsEmpty() bool {
+	return s.ranges.IsEmpty()
+}
+
+// Clear removes all ranges.
+func (s *Set[K]) Clear() {
+	s.ranges.Clear()
+}
+
+// Contains reports whether p is in the set.
+func (s *Set[K]) Contains(p K) bool {
+	_, end, found := s.ranges.Floor(p)
+	return found && p < end
+}
+
+// ContainsRange reports whether every integer in iv is in the set. An empty
+// iv is always contained.
+func (s *Set[K]) ContainsRange(iv Interval[K]) bool {
+	if iv.Empty() {
+		return true
+	}
+	_, end, found := s.ranges.Floor(iv.Start)
+	return found && iv.End <= end
+}
+
+// Insert adds every integer in iv.
+func (s *Set[K]) Insert(iv Interval[K]) {
+	if iv.Empty() {
+		return
+	}
+	start, end := iv.Start, iv.End
+	if lo, loEnd, found := s.ranges.Floor(start); found && loEnd >= start {
+		start, end = lo, max(end, loEnd)
+		s.ranges.Remove(lo)
+	}
+	for {
+		next, nextEnd, found := s.ranges.Ceiling(start)
+		if !found || next > end {
+			break
+		}
+		end = max(end, nextEnd)
+		s.ranges.Remove(next)
+	}
+	s.ranges.Insert(start, end)
+}
+
+// Remove deletes every integer in iv, splitting a range that straddles
+// either edge.
+func (s *Set[K]) Remove(iv Interval[K]) {
+	if iv.Empty() {
+		return
+	}
+	if lo, loEnd, found := s.ranges.Lower(iv.Start); found && loEnd > iv.Start {
+		s.ranges.Insert(lo, iv.Start)
+		if loEnd > iv.End {
+			s.ranges.Insert(iv.End, loEnd)
+			return
+		}
+	}
+	for {
+		next, nextEnd, found := s.ranges.Ceiling(iv.Start)
+		if !found || next >= iv.End {
+			return
+		}
+		s.ranges.Remove(next)
+		if nextEnd > iv.End {
+			s.ranges.Insert(iv.End, nextEnd)
+			return
+		}
+	}
+}
+
+// FirstFree returns the lowest start of size consecutive integers within
+// bounds that are all absent from the set, or false if no gap in bounds is
+// large enough. It visits the ranges from bounds.Start up to the first
+// large-enough gap, so allocation is first-fit.
+func (s *Set[K]) FirstFree(size K, bounds Interval[K]) (K, bool) {
+	if size <= 0 || bounds.Empty() {
+		return 0, false
+	}
+	candidate := bounds.Start
+	if _, end, found := s.ranges.Floor(candidate); found && end > candidate {
+		candidate = end
+	}
+	for {
+		// Comparing against End-size avoids overflowing candidate+size.
+		if candidate > bounds.End || bounds.End-candidate < size {
+			return 0, false
+		}
+		next, nextEnd, found := s.ranges.Ceiling(candidate)
+		if !found || next-candidate >= size {
+			return candidate, true
+		}
+		candidate = nextEnd
+	}
+}
+
+// Allocate finds the first free run of size integers within bounds, as
+// FirstFree does, and inserts it.
+func (s *Set[K]) Allocate(size K, bounds Interval[K]) (Interval[K], bool) {
+	start, ok := s.FirstFree(size, bounds)
+	if !ok {
+		return Interval[K]{}, false
+	}
+	iv := Interval[K]{Start: start, End: start + size}
+	s.Insert(iv)
+	return iv, true
+}
+
+// Ranges returns the disjoint ranges in order.
+func (s *Set[K]) Ranges() []Interval[K] {
+	ranges := make([]Interval[K], 0, s.ranges.Len())
+	s.ranges.Range(func(start, end K) bool {
+		ranges = append(ranges, Interval[K]{Start: start, End: end})
+		return true
+	})
+	return ranges
+}
+
+// CheckInvariants verifies that ranges are non-empty, sorted, disjoint and
+// not adjacent.
+func (s *Set[K]) CheckInvariants() error {
+	ranges := s.Ranges()
+	for i, iv := range ranges {
+		if iv.Empty() {
+			return fmt.Errorf("range %d %v is empty", i, iv)
+		}
+		if i > 0 && ranges[i-1].End >= iv.Start {
+			return fmt.Errorf("range %d %v overlaps or touches %v", i, iv, ranges[i-1])
+		}
+	}
+	return nil
+}
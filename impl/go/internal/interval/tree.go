@@ -0,0 +1,369 @@
+// Package interval provides ordered structures keyed by half-open ranges
+// [Start, End): a Tree that answers stabbing and overlap queries over possibly
+// overlapping intervals, a Map that assigns values to disjoint ranges, the
+// shape of calendar booking problems, and a Set of integers kept as disjoint
+// ranges that finds free runs for ID and block allocation.
+package interval
+
+import (
+	"cmp"
+	"fmt"
+)
+
+// Interval is the half-open range [Start, End). An interval with
+// Start >= End is empty and contains no points.
+type Interval[K cmp.Ordered] struct {
+	Start, End K
+}
+
+// Empty reports whether iv contains no points.
+func (iv Interval[K]) Empty() bool {
+	return iv.Start >= iv.End
+}
+
+// Contains reports whether p lies in iv.
+func (iv Interval[K]) Contains(p K) bool {
+	return iv.Start <= p && p < iv.End
+}
+
+// Overlaps reports whether iv and other share at least one point.
+func (iv Interval[K]) Overlaps(other Interval[K]) bool {
+	return iv.Start < other.End && other.Start < iv.End && !iv.Empty() && !other.Empty()
+}
+
+func (iv Interval[K]) String() string {
+	return fmt.Sprintf("[%v, %v)", iv.Start, iv.End)
+}
+
+func compareIntervals[K cmp.Ordered](a, b Interval[K]) int {
+	if c := cmp.Compare(a.Start, b.Start); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.End, b.End)
+}
+
+type treeNode[K cmp.Ordered, V any] struct {
+	iv          Interval[K]
+	value       V
+	maxEnd      K // largest End in this subtree
+	height      int
+	left, right *treeNode[K, V]
+}
+
+// Tree stores intervals with associated values, ordered by start then end.
+// It is an AVL tree in which every node also records the largest End in its
+// subtree, so a query can skip any subtree that ends before the range it is
+// looking for. Insert, Remove and FindOverlap take O(log n); Stab and Overlap
+// take O(log n + m) for m reported intervals. Intervals with identical bounds
+// share one entry.
+type Tree[K cmp.Ordered, V any] struct {
+	root   *treeNode[K, V]
+	length int
+
+	// observer, when set, receives internal events (see WithObserver).
+	observer Observer[K]
+}
+
+// TreeOption configures a Tree at construction time.
+type TreeOption[K cmp.Ordered, V any] func(*Tree[K, V])
+
+// NewTree creates an empty Tree.
+func NewTree[K cmp.Ordered, V any](opts ...TreeOption[K, V]) *Tree[K, V] {
+	t := &Tree[K, V]{}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Len returns the number of intervals in the tree.
+func (t *Tree[K, V]) Len() int {
+	return t.length
+}
+
+// IsEmpty returns true if the tree contains no intervals.
+func (t *Tree[K, V]) IsEmpty() bool {
+	return t.length == 0
+}
+
+// Clear removes all intervals.
+func (t *Tree[K, V]) Clear() {
+	t.root = nil
+	t.length = 0
+}
+
+func height[K cmp.Ordered, V any](n *treeNode[K, V]) int {
+	if n == nil {
+		return 0
+	}
+	return n.height
+}
+
+// update recomputes n's height and maxEnd from its children.
+func (n *treeNode[K, V]) update() {
+	n.height = 1 + max(height(n.left), height(n.right))
+	n.maxEnd = n.iv.End
+	if n.left != nil {
+		n.maxEnd = max(n.maxEnd, n.left.maxEnd)
+	}
+	if n.right != nil {
+		n.maxEnd = max(n.maxEnd, n.right.maxEnd)
+	}
+}
+
+func (t *Tree[K, V]) rotateRight(n *treeNode[K, V]) *treeNode[K, V] {
+	l := n.left
+	t.emit(EventRotateRight, n.iv, l.iv)
+	n.left = l.right
+	l.right = n
+	n.update()
+	l.update()
+	return l
+}
+
+func (t *Tree[K, V]) rotateLeft(n *treeNode[K, V]) *treeNode[K, V] {
+	r := n.right
+	t.emit(EventRotateLeft, n.iv, r.iv)
+	n.right = r.left
+	r.left = n
+	n.update()
+	r.update()
+	return r
+}
+
+// rebalance restores the AVL height invariant at n after one of its subtrees
+// changed height by at most one.
+func (t *Tree[K, V]) rebalance(n *treeNode[K, V]) *treeNode[K, V] {
+	n.update()
+	switch balance := height(n.left) - height(n.right); {
+	case balance > 1:
+		if height(n.left.left) < height(n.left.right) {
+			n.left = t.rotateLeft(n.left)
+		}
+		return t.rotateRight(n)
+	case balance < -1:
+		if height(n.right.right) < height(n.right.left) {
+			n.right = t.rotateRight(n.right)
+		}
+		return t.rotateLeft(n)
+	}
+	return n
+}
+
+// Insert inserts or replaces the value for iv.
+// Returns the previous value and true if iv was already present. Empty
+// intervals contain no points and are not stored.
+func (t *Tree[K, V]) Insert(iv Interval[K], value V) (V, bool) {
+	var old V
+	var existed bool
+	if iv.Empty() {
+		return old, false
+	}
+	var insert func(n *treeNode[K, V]) *treeNode[K, V]
+	insert = func(n *treeNode[K, V]) *treeNode[K, V] {
+		if n == nil {
+			return &treeNode[K, V]{iv: iv, value: value, maxEnd: iv.End, height: 1}
+		}
+		switch c := compareIntervals(iv, n.iv); {
+		case c < 0:
+			n.left = insert(n.left)
+		case c > 0:
+			n.right = insert(n.right)
+		default:
+			old, existed = n.value, true
+			n.value = value
+			return n
+		}
+		return t.rebalance(n)
+	}
+	t.root = insert(t.root)
+	if !existed {
+		t.length++
+	}
+	return old, existed
+}
+
+// Get returns the value stored for exactly iv.
+func (t *Tree[K, V]) Get(iv Interval[K]) (V, bool) {
+	n := t.root
+	for n != nil {
+		switch c := compareIntervals(iv, n.iv); {
+		case c < 0:
+			n = n.left
+		case c > 0:
+			n = n.right
+		default:
+			return n.value, true
+		}
+	}
+	var zero V
+	return zero, false
+}
+
+// Remove removes iv from the tree.
+// Returns the removed value and true if iv was present.
+func (t *Tree[K, V]) Remove(iv Interval[K]) (V, bool) {
+	var old V
+	var existed bool
+	var remove func(n *treeNode[K, V]) *treeNode[K, V]
+	remove = func(n *treeNode[K, V]) *treeNode[K, V] {
+		if n == nil {
+			return nil
+		}
+		switch c := compareIntervals(iv, n.iv); {
+		case c < 0:
+			n.left = remove(n.left)
+		case c > 0:
+			n.right = remove(n.right)
+		default:
+			old, existed = n.value, true
+			if n.left == nil {
+				return n.right
+			}
+			if n.right == nil {
+				return n.left
+			}
+			// Replace n with its successor, the minimum of the right subtree.
+			var succ *treeNode[K, V]
+			n.right = t.removeMin(n.right, &succ)
+			succ.left, succ.right = n.left, n.right
+			n = succ
+		}
+		return t.rebalance(n)
+	}
+	t.root = remove(t.root)
+	if existed {
+		t.length--
+	}
+	return old, existed
+}
+
+// removeMin detaches the leftmost node of n, storing it in *min, and returns
+// the rebalanced remainder.
+func (t *Tree[K, V]) removeMin(n *treeNode[K, V], min **treeNode[K, V]) *treeNode[K, V] {
+	if n.left == nil {
+		*min = n
+		return n.right
+	}
+	n.left = t.removeMin(n.left, min)
+	return t.rebalance(n)
+}
+
+// FindOverlap returns some interval overlapping q, or false if there is none.
+// It follows a single root-to-leaf path: if the left subtree reaches past
+// q.Start yet holds no overlap, every interval there starts at or after
+// q.End, and so does everything to its right.
+func (t *Tree[K, V]) FindOverlap(q Interval[K]) (Interval[K], V, bool) {
+	n := t.root
+	for n != nil && !q.Empty() {
+		if n.iv.Overlaps(q) {
+			return n.iv, n.value, true
+		}
+		if n.left != nil && n.left.maxEnd > q.Start {
+			n = n.left
+		} else {
+			n = n.right
+		}
+	}
+	var zero V
+	return Interval[K]{}, zero, false
+}
+
+// Stab calls f for every interval containing p, in order, until f returns
+// false.
+func (t *Tree[K, V]) Stab(p K, f func(iv Interval[K], value V) bool) {
+	stab(t.root, p, f)
+}
+
+func stab[K cmp.Ordered, V any](n *treeNode[K, V], p K, f func(Interval[K], V) bool) bool {
+	if n == nil || n.maxEnd <= p {
+		return true
+	}
+	if !stab(n.left, p, f) {
+		return false
+	}
+	if n.iv.Start > p {
+		return true // n and its right subtree start after p
+	}
+	if n.iv.Contains(p) && !f(n.iv, n.value) {
+		return false
+	}
+	return stab(n.right, p, f)
+}
+
+// Overlap calls f for every interval that shares a point with q, in order,
+// until f returns false.
+func (t *Tree[K, V]) Overlap(q Interval[K], f func(iv Interval[K], value V) bool) {
+	if !q.Empty() {
+		overlap(t.root, q, f)
+	}
+}
+
+func overlap[K cmp.Ordered, V any](n *treeNode[K, V], q Interval[K], f func(Interval[K], V) bool) bool {
+	if n == nil || n.maxEnd <= q.Start {
+		return true
+	}
+	if !overlap(n.left, q, f) {
+		return false
+	}
+	if n.iv.Start >= q.End {
+		return true
+	}
+	if n.iv.Overlaps(q) && !f(n.iv, n.value) {
+		return false
+	}
+	return overlap(n.right, q, f)
+}
+
+// Range calls f for every interval in order until f returns false.
+func (t *Tree[K, V]) Range(f func(iv Interval[K], value V) bool) {
+	var walk func(n *treeNode[K, V]) bool
+	walk = func(n *treeNode[K, V]) bool {
+		return n == nil || (walk(n.left) && f(n.iv, n.value) && walk(n.right))
+	}
+	walk(t.root)
+}
+
+// CheckInvariants verifies ordering, AVL balance, the cached heights and
+// subtree maxima, and the stored length.
+func (t *Tree[K, V]) CheckInvariants() error {
+	count := 0
+	var check func(n *treeNode[K, V], lo, hi *Interval[K]) error
+	check = func(n *treeNode[K, V], lo, hi *Interval[K]) error {
+		if n == nil {
+			return nil
+		}
+		count++
+		if n.iv.Empty() {
+			return fmt.Errorf("empty interval %v stored", n.iv)
+		}
+		if (lo != nil && compareIntervals(n.iv, *lo) <= 0) || (hi != nil && compareIntervals(n.iv, *hi) >= 0) {
+			return fmt.Errorf("interval %v out of order", n.iv)
+		}
+		if err := check(n.left, lo, &n.iv); err != nil {
+			return err
+		}
+		if err := check(n.right, &n.iv, hi); err != nil {
+			return err
+		}
+		if b := height(n.left) - height(n.right); b < -1 || b > 1 {
+			return fmt.Errorf("node %v unbalanced by %d", n.iv, b)
+		}
+		want := *n
+		want.update()
+		if want.height != n.height {
+			return fmt.Errorf("node %v: height %d, expected %d", n.iv, n.height, want.height)
+		}
+		if want.maxEnd != n.maxEnd {
+			return fmt.Errorf("node %v: max end %v, expected %v", n.iv, n.maxEnd, want.maxEnd)
+		}
+		return nil
+	}
+	if err := check(t.root, nil, nil); err != nil {
+		return err
+	}
+	if count != t.length {
+		return fmt.Errorf("counted %d intervals, length is %d", count, t.length)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package interval
+
+import (
+	"cmp"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// Visualize writes the tree as a Graphviz DOT graph. Each node shows its
+// interval, value and the largest End below it, with its height and balance
+// factor; nodes leaning by one are shaded, so the rotations that keep every
+// balance within one are easy to follow. A missing child of a node with one
+// child is drawn as a point to keep left and right apart. Bounds and values
+// are formatted with fmt.Sprint.
+func (t *Tree[K, V]) Visualize(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("digraph interval {\n")
+	b.WriteString("  node [shape=plaintext, fontname=\"monospace\"];\n")
+	if t.root == nil {
+		b.WriteString("  empty [label=\"empty\"];\n")
+	} else {
+		next := 0
+		writeNode(&b, t.root, &next)
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeNode emits n and its subtree, numbering nodes in preorder from *next,
+// and returns n's id.
+func writeNode[K cmp.Ordered, V any](b *strings.Builder, n *treeNode[K, V], next *int) string {
+	id := fmt.Sprintf("n%d", *next)
+	*next++
+	balance := height(n.left) - height(n.right)
+	color := "white"
+	if balance != 0 {
+		color = "lightyellow"
+	}
+	fmt.Fprintf(b, "  %s [label=<<TABLE BORDER=\"0\" CELLBORDER=\"1\" CELLSPACING=\"0\" BGCOLOR=\"%s\">", id, color)
+	fmt.Fprintf(b, "<TR><TD COLSPAN=\"2\">%s</TD></TR>", html.EscapeString(n.iv.String()))
+	fmt.Fprintf(b, "<TR><TD COLSPAN=\"2\">%s</TD></TR>", html.EscapeString(fmt.Sprint(n.value)))
+	fmt.Fprintf(b, "<TR><TD>max %s</TD><TD>h %d, bal %+d</TD></TR></TABLE>>];\n", html.EscapeString(fmt.Sprint(n.maxEnd)), n.height, balance)
+	if n.left == nil && n.right == nil {
+		return id
+	}
+	for i, c := range []*treeNode[K, V]{n.left, n.right} {
+		if c == nil {
+			fmt.Fprintf(b, "  %s_%d [shape=point];\n  %s -> %s_%d;\n", id, i, id, id, i)
+			continue
+		}
+		fmt.Fprintf(b, "  %s -> %s;\n", id, writeNode(b, c, next))
+	}
+	return id
+}
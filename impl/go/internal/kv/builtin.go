@@ -0,0 +1,82 @@
+package kv
+
+import "slices"
+
+// Builtin adapts Go's builtin map to the Map interface.
+type Builtin struct {
+	m map[string]string
+}
+
+// NewBuiltin creates a new empty Builtin map.
+func NewBuiltin() *Builtin {
+	return &Builtin{m: make(map[string]string)}
+}
+
+// Insert inserts a key-value pair into the map.
+// Returns the previous value and true if the key existed, empty string and false otherwise.
+func (b *Builtin) Insert(key, value string) (string, bool) {
+	old, existed := b.m[key]
+	b.m[key] = value
+	return old, existed
+}
+
+// Get retrieves the value associated with the key.
+func (b *Builtin) Get(key string) (string, bool) {
+	value, found := b.m[key]
+	return value, found
+}
+
+// Remove removes a key-value pair from the map.
+func (b *Builtin) Remove(key string) (string, bool) {
+	old, existed := b.m[key]
+	if existed {
+		delete(b.m, key)
+	}
+	return old, existed
+}
+
+// Contains checks if the map contains the given key.
+func (b *Builtin) Contains(key string) bool {
+	_, found := b.m[key]
+	return found
+}
+
+// Len returns the number of elements in the map.
+func (b *Builtin) Len() int {
+	return len(b.m)
+}
+
+// Clear removes all entries from the map.
+func (b *Builtin) Clear() {
+	for key := range b.m {
+		delete(b.m, key)
+	}
+}
+
+// Range iterates over all key-value pairs in unspecified order.
+// If f returns false, iteration stops.
+func (b *Builtin) Range(f func(key, value string) bool) {
+	for key, value := range b.m {
+		if !f(key, value) {
+			return
+		}
+	}
+}
+
+// Scan visits the keys in [lo, hi) in ascending order. The builtin map is
+// unordered, so each call filters and sorts the whole map; it exists as an
+// oracle for range workloads rather than as a contender.
+func (b *Builtin) Scan(lo, hi string, f func(key, value string) bool) {
+	var keys []string
+	for key := range b.m {
+		if lo <= key && key < hi {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		if !f(key, b.m[key]) {
+			return
+		}
+	}
+}
@@ -0,0 +1,21 @@
+//go:build dsa_experimental
+
+package kv
+
+import (
+	"github.com/dsa-lab/go/internal/concurrent/lockfree"
+	"github.com/dsa-lab/go/internal/features"
+	"github.com/dsa-lab/go/internal/hashmap"
+)
+
+func init() {
+	features.Register("kv/hashmap_incremental")
+	features.Register("kv/lockfree")
+}
+
+// experimentalImplementations are variants still being evaluated; they are
+// only compiled with the dsa_experimental build tag.
+var experimentalImplementations = []Implementation{
+	{Name: "hashmap_incremental", New: func() Map { return hashmap.MustNew(hashmap.WithIncrementalResize(4)) }},
+	{Name: "lockfree", New: func() Map { return lockfree.New() }},
+}
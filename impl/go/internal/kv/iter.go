@@ -0,0 +1,23 @@
+//go:build go1.23
+
+package kv
+
+import "iter"
+
+// All returns an iterator over all key-value pairs in unspecified order.
+func (b *Builtin) All() iter.Seq2[string, string] {
+	return b.Range
+}
+
+// Between returns an iterator over the keys in [lo, hi) in ascending order.
+// Like Scan, it sorts the matching keys before yielding the first.
+func (b *Builtin) Between(lo, hi string) iter.Seq2[string, string] {
+	return func(yield func(string, string) bool) {
+		b.Scan(lo, hi, yield)
+	}
+}
+
+// All returns an iterator over all key-value pairs in unspecified order.
+func (s *SyncMap) All() iter.Seq2[string, string] {
+	return s.Range
+}
@@ -0,0 +1,65 @@
+// Package kv defines the common string-keyed Map interface shared by the lab's
+// map implementations and the standard-library baselines used for calibration.
+package kv
+
+import (
+	"github.com/dsa-lab/go/internal/concurrent/sharded"
+	"github.com/dsa-lab/go/internal/hashmap"
+	"github.com/dsa-lab/go/internal/skiplist"
+	"github.com/dsa-lab/go/internal/smallmap"
+)
+
+// Map is the operation set every map implementation in the lab provides.
+// Semantics follow docs/CONTRACT.md.
+type Map interface {
+	Insert(key, value string) (string, bool)
+	Get(key string) (string, bool)
+	Remove(key string) (string, bool)
+	Contains(key string) bool
+	Len() int
+	Clear()
+}
+
+// Ranger is implemented by maps that can iterate their entries.
+// If f returns false, iteration stops.
+type Ranger interface {
+	Range(f func(key, value string) bool)
+}
+
+// Scanner is implemented by ordered maps that can iterate a key range.
+// Scan visits the keys in [lo, hi) in ascending order; if f returns false,
+// iteration stops.
+type Scanner interface {
+	Scan(lo, hi string, f func(key, value string) bool)
+}
+
+// Lookup returns the registered implementation with the given name.
+func Lookup(name string) (Implementation, bool) {
+	for _, impl := range Implementations() {
+		if impl.Name == name {
+			return impl, true
+		}
+	}
+	return Implementation{}, false
+}
+
+// Implementation pairs a stable name with a constructor for a Map.
+type Implementation struct {
+	Name string
+	New  func() Map
+}
+
+// Implementations returns every registered Map implementation, lab maps first
+// followed by the standard-library baselines. Builds with the dsa_experimental
+// tag append experimental variants.
+func Implementations() []Implementation {
+	impls := []Implementation{
+		{Name: "hashmap", New: func() Map { return hashmap.MustNew() }},
+		{Name: "smallmap", New: func() Map { return smallmap.New() }},
+		{Name: "skiplist", New: func() Map { return skiplist.MustNew[string, string]() }},
+		{Name: "sharded", New: func() Map { return sharded.MustNew() }},
+		{Name: "builtin", New: func() Map { return NewBuiltin() }},
+		{Name: "syncmap", New: func() Map { return NewSyncMap() }},
+	}
+	return append(impls, experimentalImplementations...)
+}
@@ -0,0 +1,77 @@
+package kv
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestImplementations(t *testing.T) {
+	for _, impl := range Implementations() {
+		t.Run(impl.Name, func(t *testing.T) {
+			m := impl.New()
+			if m.Len() != 0 {
+				t.Errorf("new map should have length 0, got %d", m.Len())
+			}
+
+			if old, existed := m.Insert("key", "value1"); existed || old != "" {
+				t.Errorf("first insert returned %q, %v", old, existed)
+			}
+			if old, existed := m.Insert("key", "value2"); !existed || old != "value1" {
+				t.Errorf("overwrite returned %q, %v", old, existed)
+			}
+			if value, found := m.Get("key"); !found || value != "value2" {
+				t.Errorf("get returned %q, %v", value, found)
+			}
+			if !m.Contains("key") || m.Contains("other") {
+				t.Error("contains mismatch")
+			}
+			if removed, existed := m.Remove("key"); !existed || removed != "value2" {
+				t.Errorf("remove returned %q, %v", removed, existed)
+			}
+			if _, existed := m.Remove("key"); existed {
+				t.Error("second remove should report missing key")
+			}
+
+			for i := 0; i < 100; i++ {
+				m.Insert(fmt.Sprintf("key%d", i), "v")
+			}
+			if m.Len() != 100 {
+				t.Errorf("expected length 100, got %d", m.Len())
+			}
+			m.Clear()
+			if m.Len() != 0 || m.Contains("key0") {
+				t.Error("map should be empty after clear")
+			}
+		})
+	}
+}
+
+func TestLookupAndRange(t *testing.T) {
+	for _, impl := range Implementations() {
+		found, ok := Lookup(impl.Name)
+		if !ok || found.Name != impl.Name {
+			t.Errorf("lookup(%s) failed", impl.Name)
+		}
+
+		m := impl.New()
+		m.Insert("a", "1")
+		m.Insert("b", "2")
+		r, ok := m.(Ranger)
+		if !ok {
+			t.Errorf("%s does not implement Ranger", impl.Name)
+			continue
+		}
+		count := 0
+		r.Range(func(_, _ string) bool {
+			count++
+			return true
+		})
+		if count != 2 {
+			t.Errorf("%s: range visited %d entries, want 2", impl.Name, count)
+		}
+	}
+
+	if _, ok := Lookup("missing"); ok {
+		t.Error("lookup of unknown implementation should fail")
+	}
+}
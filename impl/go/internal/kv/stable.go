@@ -0,0 +1,5 @@
+//go:build !dsa_experimental
+
+package kv
+
+var experimentalImplementations []Implementation
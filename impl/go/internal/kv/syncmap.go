@@ -0,0 +1,78 @@
+package kv
+
+import (
+	"sync"
+
+	"github.com/dsa-lab/go/internal/concurrent/counter"
+)
+
+// SyncMap adapts sync.Map to the Map interface. sync.Map has no length, so
+// the adapter maintains one alongside it in a striped counter, keeping the
+// count from becoming the one contended word in an otherwise
+// contention-free map.
+type SyncMap struct {
+	m    sync.Map
+	size *counter.Counter
+}
+
+// NewSyncMap creates a new empty SyncMap.
+func NewSyncMap() *SyncMap {
+	return &SyncMap{size: counter.MustNew()}
+}
+
+// Insert inserts a key-value pair into the map.
+// Returns the previous value and true if the key existed, empty string and false otherwise.
+func (s *SyncMap) Insert(key, value string) (string, bool) {
+	old, loaded := s.m.Swap(key, value)
+	if !loaded {
+		s.size.Add(1)
+		return "", false
+	}
+	return old.(string), true
+}
+
+// Get retrieves the value associated with the key.
+func (s *SyncMap) Get(key string) (string, bool) {
+	value, found := s.m.Load(key)
+	if !found {
+		return "", false
+	}
+	return value.(string), true
+}
+
+// Remove removes a key-value pair from the map.
+func (s *SyncMap) Remove(key string) (string, bool) {
+	old, loaded := s.m.LoadAndDelete(key)
+	if !loaded {
+		return "", false
+	}
+	s.size.Add(-1)
+	return old.(string), true
+}
+
+// Contains checks if the map contains the given key.
+func (s *SyncMap) Contains(key string) bool {
+	_, found := s.m.Load(key)
+	return found
+}
+
+// Len returns the number of elements in the map.
+func (s *SyncMap) Len() int {
+	return int(s.size.Load())
+}
+
+// Clear removes all entries from the map.
+func (s *SyncMap) Clear() {
+	s.m.Range(func(key, _ any) bool {
+		s.Remove(key.(string))
+		return true
+	})
+}
+
+// Range iterates over all key-value pairs in unspecified order.
+// If f returns false, iteration stops.
+func (s *SyncMap) Range(f func(key, value string) bool) {
+	s.m.Range(func(key, value any) bool {
+		return f(key.(string), value.(string))
+	})
+}
@@ -0,0 +1,261 @@
+package lsm
+
+import (
+	"context"
+	"sort"
+)
+
+// checkEvery is how many merged entries pass between cancellation checks, so
+// a cancelled compaction stops within a fraction of one table's work.
+const checkEvery = 1024
+
+// iterator yields entries in strictly increasing key order.
+type iterator interface {
+	next() bool
+	entry() entry
+	error() error
+}
+
+type sliceIter struct {
+	entries []entry
+	pos     int
+}
+
+func (it *sliceIter) next() bool {
+	if it.pos >= len(it.entries) {
+		return false
+	}
+	it.pos++
+	return true
+}
+
+func (it *sliceIter) entry() entry {
+	return it.entries[it.pos-1]
+}
+
+func (it *sliceIter) error() error {
+	return nil
+}
+
+// merge combines sources, ordered newest first, into one sorted stream in
+// which each key appears once with its newest version. Tombstones are dropped
+// when dropTombstones is set, which is only safe when no older data for the
+// key can exist outside sources. It returns ctx's error if ctx is done before
+// the merge finishes.
+func merge(ctx context.Context, sources []iterator, dropTombstones bool, emit func(e entry) error) error {
+	var active []iterator
+	for _, it := range sources {
+		if it.next() {
+			active = append(active, it)
+		} else if err := it.error(); err != nil {
+			return err
+		}
+	}
+
+	for n := 0; len(active) > 0; n++ {
+		if n%checkEvery == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		// The first source holding the smallest key is the newest version.
+		minIdx := 0
+		for i := 1; i < len(active); i++ {
+			if active[i].entry().key < active[minIdx].entry().key {
+				minIdx = i
+			}
+		}
+		e := active[minIdx].entry()
+		if !(dropTombstones && e.deleted) {
+			if err := emit(e); err != nil {
+				return err
+			}
+		}
+
+		remaining := active[:0]
+		for _, it := range active {
+			if it.entry().key == e.key && !it.next() {
+				if err := it.error(); err != nil {
+					return err
+				}
+				continue
+			}
+			remaining = append(remaining, it)
+		}
+		active = remaining
+	}
+	return nil
+}
+
+func (db *DB) maxLevelBytes(level int) int64 {
+	n := 4 * db.tableSize
+	for l := 1; l < level; l++ {
+		n *= levelMultiplier
+	}
+	return n
+}
+
+// compact runs compactions until no level is over its limit. A compaction
+// interrupted by ctx leaves the tree as it was before that compaction began.
+func (db *DB) compact(ctx context.Context) error {
+	for {
+		level := -1
+		if len(db.levels[0]) >= level0Limit {
+			level = 0
+		} else {
+			for l := 1; l < numLevels-1; l++ {
+				if levelBytes(db.levels[l]) > db.maxLevelBytes(l) {
+					level = l
+					break
+				}
+			}
+		}
+		if level < 0 {
+			return nil
+		}
+		if err := db.compactLevel(ctx, level); err != nil {
+			return err
+		}
+	}
+}
+
+// compactLevel merges tables from level into level+1. From level 0 every
+// table is taken, since they may overlap each other; from deeper levels one
+// table is taken, rotating through the key space. Overlapping tables in
+// level+1 join the merge, and the output replaces them all.
+func (db *DB) compactLevel(ctx context.Context, level int) error {
+	var inputs []*table
+	if level == 0 {
+		inputs = append(inputs, db.levels[0]...)
+	} else {
+		tables := db.levels[level]
+		i := sort.Search(len(tables), func(i int) bool { return tables[i].smallest > db.compactPointer[level] })
+		if i == len(tables) {
+			i = 0
+		}
+		inputs = append(inputs, tables[i])
+	}
+
+	smallest, largest := inputs[0].smallest, inputs[0].largest
+	for _, t := range inputs[1:] {
+		smallest, largest = min(smallest, t.smallest), max(largest, t.largest)
+	}
+	var overlapping, kept []*table
+	for _, t := range db.levels[level+1] {
+		if t.overlaps(smallest, largest) {
+			overlapping = append(overlapping, t)
+		} else {
+			kept = append(kept, t)
+		}
+	}
+
+	// Tombstones can go once nothing deeper could hold an older version.
+	bottom := true
+	for l := level + 2; l < numLevels; l++ {
+		for _, t := range db.levels[l] {
+			if t.overlaps(smallest, largest) {
+				bottom = false
+			}
+		}
+	}
+
+	sources := make([]iterator, 0, len(inputs)+len(overlapping))
+	for _, t := range append(inputs, overlapping...) {
+		sources = append(sources, t.iter())
+		db.metrics.CompactionBytesRead += t.size
+	}
+
+	outputs, written, err := db.writeTables(ctx, sources, bottom)
+	if err != nil {
+		return err
+	}
+	if level > 0 {
+		db.compactPointer[level] = inputs[0].largest
+	}
+
+	next := append(kept, outputs...)
+	sort.Slice(next, func(i, j int) bool { return next[i].smallest < next[j].smallest })
+	db.levels[level+1] = next
+	if level == 0 {
+		db.levels[0] = nil
+	} else {
+		db.levels[level] = removeTable(db.levels[level], inputs[0])
+	}
+	if err := writeManifest(db.fs, db.dir, db.manifest()); err != nil {
+		return err
+	}
+
+	for _, t := range append(inputs, overlapping...) {
+		t.close()
+		db.fs.Remove(tablePath(db.dir, t.num))
+	}
+	db.metrics.CompactionBytesWritten += written
+	db.metrics.Compactions++
+	return nil
+}
+
+// writeTables merges sources into new tables of about tableSize bytes each.
+// On any error, including cancellation, the tables written so far are
+// deleted.
+func (db *DB) writeTables(ctx context.Context, sources []iterator, dropTombstones bool) ([]*table, int64, error) {
+	var outputs []*table
+	var written int64
+	var w *tableWriter
+	var num uint64
+
+	finish := func() error {
+		size, err := w.finish()
+		if err != nil {
+			return err
+		}
+		t, err := openTable(db.fs, tablePath(db.dir, num), num)
+		if err != nil {
+			return err
+		}
+		outputs = append(outputs, t)
+		written += size
+		w = nil
+		return nil
+	}
+
+	err := merge(ctx, sources, dropTombstones, func(e entry) error {
+		if w == nil {
+			num = db.next
+			db.next++
+			var err error
+			if w, err = createTable(db.fs, tablePath(db.dir, num)); err != nil {
+				return err
+			}
+		}
+		if err := w.add(e); err != nil {
+			return err
+		}
+		if w.size() >= db.tableSize {
+			return finish()
+		}
+		return nil
+	})
+	if err == nil && w != nil {
+		err = finish()
+	}
+	if err != nil {
+		if w != nil {
+			w.abort()
+		}
+		for _, t := range outputs {
+			t.close()
+			db.fs.Remove(tablePath(db.dir, t.num))
+		}
+		return nil, 0, err
+	}
+	return outputs, written, nil
+}
+
+func removeTable(tables []*table, t *table) []*table {
+	for i, u := range tables {
+		if u == t {
+			return append(tables[:i:i], tables[i+1:]...)
+		}
+	}
+	return tables
+}
@@ -0,0 +1,61 @@
+package lsm
+
+import (
+	"encoding/binary"
+)
+
+// flagBatch marks a log record holding every entry of a committed Txn:
+//
+//	flagBatch byte | entry record | entry record | ...
+//
+// The log checksums each record as a whole, so replay sees all of a batch or
+// none of it. It must not collide with the entry flags.
+const flagBatch = 2
+
+// encodeEntry encodes e as a write-ahead log record, in the same form as a
+// table record:
+//
+//	flags byte | key length uvarint | value length uvarint | key | value
+func encodeEntry(buf []byte, e entry) []byte {
+	return appendEntry(buf[:0], e)
+}
+
+func appendEntry(buf []byte, e entry) []byte {
+	var flags byte
+	if e.deleted {
+		flags = flagDeleted
+	}
+	buf = append(buf, flags)
+	buf = binary.AppendUvarint(buf, uint64(len(e.key)))
+	buf = binary.AppendUvarint(buf, uint64(len(e.value)))
+	buf = append(buf, e.key...)
+	return append(buf, e.value...)
+}
+
+// encodeBatch encodes entries as a single batch record.
+func encodeBatch(buf []byte, entries []entry) []byte {
+	buf = append(buf[:0], flagBatch)
+	for _, e := range entries {
+		buf = appendEntry(buf, e)
+	}
+	return buf
+}
+
+// decodeRecord decodes a log record into the entries it holds: one for a
+// plain record, every entry of the batch for a batch record.
+func decodeRecord(record []byte) ([]entry, error) {
+	r := &sliceReader{buf: record}
+	if len(record) == 0 || record[0] != flagBatch {
+		e := r.entry()
+		if r.err == nil && len(r.buf) != 0 {
+			r.err = errBadTable
+		}
+		return []entry{e}, r.err
+	}
+	r.buf = r.buf[1:]
+	var entries []entry
+	for len(r.buf) > 0 && r.err == nil {
+		entries = append(entries, r.entry())
+	}
+	return entries, r.err
+}
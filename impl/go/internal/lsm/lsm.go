@@ -0,0 +1,455 @@
+// Package lsm provides a log-structured merge tree: a persistent key-value
+// store that turns random writes into sequential ones.
+//
+// Writes go to a write-ahead log (package wal) and an in-memory memtable (the
+// lab's skip list).
+// A full memtable is flushed to an immutable sorted table (SSTable) in level
+// 0. Level 0 tables may overlap; once there are enough of them they are merged
+// into level 1, and each deeper level holds non-overlapping tables with ten
+// times the capacity of the one above, compacted downward one table at a time
+// (leveled compaction). Every table carries a bloom filter, so reads skip
+// tables that cannot hold the key.
+package lsm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dsa-lab/go/internal/errs"
+	"github.com/dsa-lab/go/internal/skiplist"
+	"github.com/dsa-lab/go/internal/vfs"
+	"github.com/dsa-lab/go/internal/wal"
+)
+
+const (
+	numLevels = 7
+
+	defaultMemtableSize = 1 << 20
+	defaultTableSize    = 2 << 20
+	level0Limit         = 4
+	levelMultiplier     = 10
+)
+
+var (
+	// ErrClosed is returned by operations on a closed DB.
+	ErrClosed = errors.New("lsm: db is closed")
+)
+
+// DB is an LSM-tree key-value store in a directory. It is not safe for
+// concurrent use.
+type DB struct {
+	fs       vfs.FS
+	dir      string
+	log      *wal.Log
+	logBuf   []byte
+	mem      *skiplist.SkipList[string, entry]
+	memBytes int64
+	levels   [numLevels][]*table
+	next     uint64
+	metrics  Metrics
+
+	memtableSize int64
+	tableSize    int64
+	syncWrites   bool
+
+	// compactPointer[level] is the largest key of the last table compacted
+	// out of that level, so successive compactions rotate through the key
+	// space.
+	compactPointer [numLevels]string
+}
+
+// Option configures a DB when it is opened.
+type Option func(*DB)
+
+// WithMemtableSize sets how many bytes of writes the memtable buffers before
+// it is flushed to level 0.
+func WithMemtableSize(bytes int64) Option {
+	return func(db *DB) {
+		db.memtableSize = bytes
+	}
+}
+
+// WithTableSize sets the target size of tables written by compaction. Level 1
+// holds up to 4 tables' worth of data, each deeper level ten times more.
+func WithTableSize(bytes int64) Option {
+	return func(db *DB) {
+		db.tableSize = bytes
+	}
+}
+
+// WithSyncWrites syncs the log after every write, so acknowledged writes
+// survive an operating system crash and not only a process crash.
+func WithSyncWrites() Option {
+	return func(db *DB) {
+		db.syncWrites = true
+	}
+}
+
+// WithFS keeps the store's tables, manifest and log in fsys instead of the
+// operating system's file system.
+func WithFS(fsys vfs.FS) Option {
+	return func(db *DB) {
+		db.fs = fsys
+	}
+}
+
+// LevelStats describes one level of the tree.
+type LevelStats struct {
+	Tables int
+	Bytes  int64
+}
+
+// Metrics reports the work done by the store. Byte counts are cumulative
+// since Open.
+type Metrics struct {
+	// UserBytes is the key and value bytes written by Put, Delete and Txn.
+	UserBytes int64
+	// LogBytes is the bytes appended to the write-ahead log.
+	LogBytes int64
+	// FlushBytes is the bytes written by memtable flushes.
+	FlushBytes int64
+	// CompactionBytesRead and CompactionBytesWritten count table bytes
+	// consumed and produced by compactions.
+	CompactionBytesRead    int64
+	CompactionBytesWritten int64
+	Flushes                int
+	Compactions            int
+	// BloomSkips counts table lookups answered by a bloom filter alone.
+	BloomSkips int64
+	// BlockReads counts table blocks read by Get.
+	BlockReads int64
+	Levels     [numLevels]LevelStats
+}
+
+// WriteAmplification is the ratio of bytes written to disk (log, flushes and
+// compactions) to bytes written by the user.
+func (m Metrics) WriteAmplification() float64 {
+	if m.UserBytes == 0 {
+		return 0
+	}
+	return float64(m.LogBytes+m.FlushBytes+m.CompactionBytesWritten) / float64(m.UserBytes)
+}
+
+// Open opens the store in dir, creating the directory if needed. Tables not
+// listed in the manifest, left by an interrupted flush or compaction, are
+// deleted, and writes in the log (kept in dir/wal) are replayed into the
+// memtable. Sizes set by options must be positive; Open reports any other as
+// an error matching errs.ErrInvalidOption.
+func Open(dir string, opts ...Option) (*DB, error) {
+	db := &DB{
+		fs:           vfs.OS,
+		dir:          dir,
+		mem:          skiplist.MustNew[string, entry](),
+		memtableSize: defaultMemtableSize,
+		tableSize:    defaultTableSize,
+	}
+	for _, opt := range opts {
+		opt(db)
+	}
+	if db.memtableSize < 1 || db.tableSize < 1 {
+		return nil, fmt.Errorf("lsm: memtable size %d, table size %d: %w", db.memtableSize, db.tableSize, errs.ErrInvalidOption)
+	}
+
+	if err := db.fs.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	m, err := readManifest(db.fs, dir)
+	if err != nil {
+		return nil, err
+	}
+	db.next = m.next
+
+	live := make(map[uint64]bool)
+	for level, nums := range m.levels {
+		for _, num := range nums {
+			t, err := openTable(db.fs, tablePath(dir, num), num)
+			if err != nil {
+				db.closeTables()
+				return nil, err
+			}
+			db.levels[level] = append(db.levels[level], t)
+			live[num] = true
+		}
+	}
+	removeOrphans(db.fs, dir, live)
+
+	logOpts := []wal.Option{wal.WithFS(db.fs)}
+	if db.syncWrites {
+		logOpts = append(logOpts, wal.WithSyncWrites())
+	}
+	if db.log, err = wal.Open(filepath.Join(dir, "wal"), logOpts...); err != nil {
+		db.closeTables()
+		return nil, err
+	}
+	if err := db.replay(); err != nil {
+		db.log.Close()
+		db.closeTables()
+		return nil, err
+	}
+	return db, nil
+}
+
+// replay rebuilds the memtable from the log.
+func (db *DB) replay() error {
+	it := db.log.Iterator()
+	defer it.Close()
+	for it.Next() {
+		entries, err := decodeRecord(it.Record())
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			db.apply(e)
+		}
+	}
+	return it.Err()
+}
+
+func removeOrphans(fsys vfs.FS, dir string, live map[uint64]bool) {
+	fsys.Remove(filepath.Join(dir, "MANIFEST.tmp"))
+	names, _ := vfs.Glob(fsys, dir, "*.sst")
+	for _, name := range names {
+		num, err := strconv.ParseUint(strings.TrimSuffix(filepath.Base(name), ".sst"), 10, 64)
+		if err == nil && !live[num] {
+			fsys.Remove(name)
+		}
+	}
+}
+
+// apply adds e to the memtable.
+func (db *DB) apply(e entry) {
+	if old, found := db.mem.Insert(e.key, e); found {
+		db.memBytes -= old.size()
+	}
+	db.memBytes += e.size()
+}
+
+// write logs entries as one record and applies them to the memtable.
+func (db *DB) write(entries ...entry) error {
+	if db.log == nil {
+		return ErrClosed
+	}
+	before := db.log.Size()
+	if len(entries) == 1 {
+		db.logBuf = encodeEntry(db.logBuf, entries[0])
+	} else {
+		db.logBuf = encodeBatch(db.logBuf, entries)
+	}
+	if err := db.log.Append(db.logBuf); err != nil {
+		return err
+	}
+	db.metrics.LogBytes += db.log.Size() - before
+	for _, e := range entries {
+		db.metrics.UserBytes += int64(len(e.key) + len(e.value))
+		db.apply(e)
+	}
+
+	if db.memBytes >= db.memtableSize {
+		if err := db.flush(); err != nil {
+			return err
+		}
+		return db.compact(context.Background())
+	}
+	return nil
+}
+
+// Put sets the value for key.
+func (db *DB) Put(key, value string) error {
+	return db.write(entry{key: key, value: value})
+}
+
+// Delete removes key by writing a tombstone that hides older values until
+// compaction reaches the bottom of the tree.
+func (db *DB) Delete(key string) error {
+	return db.write(entry{key: key, deleted: true})
+}
+
+// Get retrieves the value associated with the key, consulting the memtable,
+// then level 0 from newest to oldest, then each deeper level. The first
+// version found wins.
+// Returns the value and true if found, empty string and false otherwise.
+func (db *DB) Get(key string) (string, bool, error) {
+	if db.log == nil {
+		return "", false, ErrClosed
+	}
+	if e, found := db.mem.Get(key); found {
+		return e.value, !e.deleted, nil
+	}
+
+	for level, tables := range db.levels {
+		if level > 0 {
+			// Deeper levels do not overlap: only one table can hold the key.
+			i := sort.Search(len(tables), func(i int) bool { return tables[i].largest >= key })
+			if i == len(tables) {
+				continue
+			}
+			tables = tables[i : i+1]
+		}
+		for _, t := range tables {
+			e, found, skipped, err := t.get(key)
+			if err != nil {
+				return "", false, err
+			}
+			if skipped {
+				db.metrics.BloomSkips++
+			} else if key >= t.smallest && key <= t.largest {
+				db.metrics.BlockReads++
+			}
+			if found {
+				return e.value, !e.deleted, nil
+			}
+		}
+	}
+	return "", false, nil
+}
+
+// Range calls f for every live key in order. If f returns false, iteration
+// stops.
+func (db *DB) Range(f func(key, value string) bool) error {
+	if db.log == nil {
+		return ErrClosed
+	}
+	var mem []entry
+	db.mem.Range(func(_ string, e entry) bool {
+		mem = append(mem, e)
+		return true
+	})
+
+	sources := []iterator{&sliceIter{entries: mem}}
+	for _, tables := range db.levels {
+		for _, t := range tables {
+			sources = append(sources, t.iter())
+		}
+	}
+	stop := errors.New("stop")
+	err := merge(context.Background(), sources, true, func(e entry) error {
+		if !f(e.key, e.value) {
+			return stop
+		}
+		return nil
+	})
+	if err == stop {
+		return nil
+	}
+	return err
+}
+
+// Metrics returns a snapshot of the store's counters and level sizes.
+func (db *DB) Metrics() Metrics {
+	m := db.metrics
+	for level, tables := range db.levels {
+		m.Levels[level].Tables = len(tables)
+		m.Levels[level].Bytes = levelBytes(tables)
+	}
+	return m
+}
+
+func levelBytes(tables []*table) int64 {
+	var n int64
+	for _, t := range tables {
+		n += t.size
+	}
+	return n
+}
+
+func (db *DB) manifest() manifest {
+	m := manifest{next: db.next}
+	for level, tables := range db.levels {
+		for _, t := range tables {
+			m.levels[level] = append(m.levels[level], t.num)
+		}
+	}
+	return m
+}
+
+// flush writes the memtable to a new level 0 table, records it in the
+// manifest and empties the log.
+func (db *DB) flush() error {
+	if db.mem.Len() == 0 {
+		return nil
+	}
+	num := db.next
+	db.next++
+	w, err := createTable(db.fs, tablePath(db.dir, num))
+	if err != nil {
+		return err
+	}
+	db.mem.Range(func(_ string, e entry) bool {
+		err = w.add(e)
+		return err == nil
+	})
+	if err != nil {
+		w.abort()
+		return err
+	}
+	size, err := w.finish()
+	if err != nil {
+		return err
+	}
+	t, err := openTable(db.fs, tablePath(db.dir, num), num)
+	if err != nil {
+		return err
+	}
+
+	db.levels[0] = append([]*table{t}, db.levels[0]...)
+	if err := writeManifest(db.fs, db.dir, db.manifest()); err != nil {
+		return err
+	}
+	if err := db.log.Reset(); err != nil {
+		return err
+	}
+	db.mem = skiplist.MustNew[string, entry]()
+	db.memBytes = 0
+	db.metrics.FlushBytes += size
+	db.metrics.Flushes++
+	return nil
+}
+
+// Flush writes any buffered writes to a level 0 table and runs compactions
+// that become due.
+func (db *DB) Flush() error {
+	return db.FlushContext(context.Background())
+}
+
+// FlushContext is Flush with a context bounding the compactions, which can
+// rewrite whole levels. If ctx is done mid-compaction, the partly written
+// output tables are deleted, the tree keeps its previous tables, and ctx's
+// error is returned; the flushed memtable stays durable, and the next write or
+// flush resumes compacting.
+func (db *DB) FlushContext(ctx context.Context) error {
+	if db.log == nil {
+		return ErrClosed
+	}
+	if err := db.flush(); err != nil {
+		return err
+	}
+	return db.compact(ctx)
+}
+
+func (db *DB) closeTables() {
+	for level, tables := range db.levels {
+		for _, t := range tables {
+			t.close()
+		}
+		db.levels[level] = nil
+	}
+}
+
+// Close flushes the memtable and closes every file.
+func (db *DB) Close() error {
+	if db.log == nil {
+		return ErrClosed
+	}
+	err := db.flush()
+	if cerr := db.log.Close(); err == nil {
+		err = cerr
+	}
+	db.log = nil
+	db.closeTables()
+	return err
+}
@@ -0,0 +1,428 @@
+package lsm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/errs"
+	"github.com/dsa-lab/go/internal/vfs"
+	"github.com/dsa-lab/go/internal/vfs/faultfs"
+)
+
+// small keeps tables tiny so tests exercise flushes and several levels.
+var small = []Option{WithMemtableSize(4 << 10), WithTableSize(8 << 10)}
+
+// crash abandons db without flushing, as if the process had died.
+func crash(db *DB) {
+	db.log.Close()
+	db.log = nil
+	db.closeTables()
+}
+
+func checkContents(t *testing.T, db *DB, want map[string]string) {
+	t.Helper()
+	for k, v := range want {
+		got, found, err := db.Get(k)
+		if err != nil || !found || got != v {
+			t.Fatalf("get(%q): expected %q, got %q found=%v err=%v", k, v, got, found, err)
+		}
+	}
+	var prev string
+	seen := 0
+	if err := db.Range(func(k, v string) bool {
+		if seen > 0 && k <= prev {
+			t.Errorf("range out of order: %q after %q", k, prev)
+		}
+		if want[k] != v {
+			t.Errorf("range yielded %q=%q, want %q", k, v, want[k])
+		}
+		prev = k
+		seen++
+		return true
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if seen != len(want) {
+		t.Errorf("range visited %d keys, want %d", seen, len(want))
+	}
+}
+
+func TestOracle(t *testing.T) {
+	dir := t.TempDir()
+	db, err := Open(dir, small...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref := make(map[string]string)
+	rng := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 30000; i++ {
+		key := fmt.Sprintf("key_%05d", rng.Intn(5000))
+		switch rng.Intn(10) {
+		case 0, 1, 2, 3, 4:
+			value := fmt.Sprintf("value_%d", i)
+			if err := db.Put(key, value); err != nil {
+				t.Fatal(err)
+			}
+			ref[key] = value
+		case 5, 6:
+			if err := db.Delete(key); err != nil {
+				t.Fatal(err)
+			}
+			delete(ref, key)
+		default:
+			got, found, err := db.Get(key)
+			want, wantFound := ref[key]
+			if err != nil || found != wantFound || got != want {
+				t.Fatalf("op %d get(%q): got (%q, %v, %v), want (%q, %v)", i, key, got, found, err, want, wantFound)
+			}
+		}
+
+		if i%10000 == 9999 {
+			if err := db.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if db, err = Open(dir, small...); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	checkContents(t, db, ref)
+	m := db.Metrics()
+	if m.Levels[2].Tables == 0 {
+		t.Errorf("expected compactions to reach level 2, got %+v", m.Levels)
+	}
+	if len(db.levels[0]) >= level0Limit {
+		t.Errorf("expected level 0 below its limit, got %d tables", len(db.levels[0]))
+	}
+	for level := 1; level < numLevels; level++ {
+		tables := db.levels[level]
+		for i := 1; i < len(tables); i++ {
+			if tables[i-1].largest >= tables[i].smallest {
+				t.Errorf("level %d tables %d and %d overlap", level, i-1, i)
+			}
+		}
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := db.Get("key"); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected ErrClosed after close, got %v", err)
+	}
+}
+
+func TestOpenRejectsInvalidSizes(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "db")
+	for _, opt := range []Option{WithMemtableSize(0), WithTableSize(-1)} {
+		if _, err := Open(dir, opt); !errors.Is(err, errs.ErrInvalidOption) {
+			t.Errorf("expected ErrInvalidOption, got %v", err)
+		}
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("expected no directory after a rejected Open, got %v", err)
+	}
+}
+
+func TestRecoverMemtable(t *testing.T) {
+	dir := t.TempDir()
+	db, err := Open(dir, small...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := make(map[string]string)
+	for i := 0; i < 1000; i++ {
+		key := fmt.Sprintf("key_%d", i)
+		if i%7 == 0 {
+			db.Delete(key)
+			delete(want, key)
+			continue
+		}
+		db.Put(key, fmt.Sprintf("v%d", i))
+		want[key] = fmt.Sprintf("v%d", i)
+	}
+	if db.Metrics().Flushes == 0 || db.mem.Len() == 0 {
+		t.Fatal("expected both flushed tables and buffered writes before the crash")
+	}
+	crash(db)
+
+	if db, err = Open(dir, small...); err != nil {
+		t.Fatal(err)
+	}
+	checkContents(t, db, want)
+	db.Close()
+}
+
+func TestTornLogTail(t *testing.T) {
+	dir := t.TempDir()
+	db, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Put("a", "1")
+	db.Put("b", "2")
+	db.Put("c", "3")
+	crash(db)
+
+	segments, _ := filepath.Glob(filepath.Join(dir, "wal", "*.wal"))
+	logPath := segments[len(segments)-1]
+	info, err := os.Stat(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Truncate(logPath, info.Size()-2); err != nil {
+		t.Fatal(err)
+	}
+
+	// The torn record is discarded, and writes after recovery must not be
+	// hidden behind it.
+	if db, err = Open(dir); err != nil {
+		t.Fatal(err)
+	}
+	db.Put("d", "4")
+	crash(db)
+
+	if db, err = Open(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	checkContents(t, db, map[string]string{"a": "1", "b": "2", "d": "4"})
+}
+
+func TestTxn(t *testing.T) {
+	dir := t.TempDir()
+	db, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Put("a", "1")
+	db.Put("b", "2")
+
+	txn := db.Txn()
+	txn.Put("c", "3")
+	txn.Delete("a")
+	txn.Put("c", "4")
+	if v, found, _ := txn.Get("c"); !found || v != "4" {
+		t.Errorf("expected the batch to read its own write, got %q %v", v, found)
+	}
+	if _, found, _ := txn.Get("a"); found {
+		t.Error("expected the batch to hide its own delete")
+	}
+	if _, found, _ := db.Get("c"); found {
+		t.Error("expected the DB unchanged before Commit")
+	}
+	if err := txn.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if txn.Len() != 0 {
+		t.Errorf("expected an empty batch after Commit, got %d writes", txn.Len())
+	}
+	txn.Put("d", "5")
+	txn.Rollback()
+	crash(db)
+
+	if db, err = Open(dir); err != nil {
+		t.Fatal(err)
+	}
+	checkContents(t, db, map[string]string{"b": "2", "c": "4"})
+
+	// A batch torn by a crash is dropped whole.
+	txn = db.Txn()
+	for i := 0; i < 10; i++ {
+		txn.Put(fmt.Sprint("k", i), "v")
+	}
+	if err := txn.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	crash(db)
+	segments, _ := filepath.Glob(filepath.Join(dir, "wal", "*.wal"))
+	logPath := segments[len(segments)-1]
+	info, err := os.Stat(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Truncate(logPath, info.Size()-2); err != nil {
+		t.Fatal(err)
+	}
+	if db, err = Open(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	checkContents(t, db, map[string]string{"b": "2", "c": "4"})
+}
+
+// TestCrashDuringCompaction cuts the power at every operation of the put
+// that triggers the first compaction, including between writing its output
+// tables and installing them. Recovery must keep every acknowledged put and
+// remove any table the manifest does not list.
+func TestCrashDuringCompaction(t *testing.T) {
+	opts := append([]Option{WithSyncWrites()}, small...)
+	open := func(fsys vfs.FS) *DB {
+		t.Helper()
+		db, err := Open("db", append(opts, WithFS(fsys))...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return db
+	}
+	key := func(i int) string { return fmt.Sprintf("key_%05d", i) }
+
+	// A dry run finds the put that compacts and the operations it performs.
+	dry := faultfs.New(1)
+	db := open(dry)
+	n, start := 0, 0
+	for ; db.Metrics().Compactions == 0; n++ {
+		start = dry.Ops()
+		if err := db.Put(key(n), "value"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ops := dry.Ops() - start
+
+	for k := 0; k < ops; k++ {
+		fsys := faultfs.New(1)
+		db := open(fsys)
+		want := make(map[string]string)
+		for i := 0; i < n-1; i++ {
+			if err := db.Put(key(i), "value"); err != nil {
+				t.Fatal(err)
+			}
+			want[key(i)] = "value"
+		}
+		fsys.CrashAfter(k)
+		// The last operations remove compacted tables, and a failure there
+		// does not fail the put.
+		err := db.Put(key(n-1), "value")
+		if !fsys.Crashed() || err != nil && !errors.Is(err, faultfs.ErrCrashed) {
+			t.Fatalf("crash after %d ops: expected simulated crash, got %v", k, err)
+		}
+
+		after := fsys.Crash()
+		db = open(after)
+		// A put that failed may or may not have survived.
+		if _, found, _ := db.Get(key(n - 1)); found || err == nil {
+			want[key(n-1)] = "value"
+		}
+		checkContents(t, db, want)
+
+		files, _ := vfs.Glob(after, "db", "*.sst")
+		live := 0
+		for _, level := range db.Metrics().Levels {
+			live += level.Tables
+		}
+		if len(files) != live {
+			t.Errorf("crash after %d ops: expected orphaned tables to be removed: %d files, %d live tables", k, len(files), live)
+		}
+		db.Close()
+	}
+}
+
+// cancelAfter is a context whose Err starts reporting cancellation after n
+// calls, so a test can stop a compaction partway through its merge.
+type cancelAfter struct {
+	context.Context
+	n int
+}
+
+func (c *cancelAfter) Err() error {
+	if c.n--; c.n < 0 {
+		return context.Canceled
+	}
+	return nil
+}
+
+func TestCancelCompaction(t *testing.T) {
+	dir := t.TempDir()
+	db, err := Open(dir, small...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := make(map[string]string)
+	rng := rand.New(rand.NewSource(1))
+	// Stop one flush short of a level 0 compaction, with the memtable
+	// holding the writes that will trigger it.
+	for i := 0; i < 20000 || len(db.levels[0]) != level0Limit-1 || db.mem.Len() == 0; i++ {
+		key := fmt.Sprintf("key_%05d", rng.Intn(20000))
+		if err := db.Put(key, "value"); err != nil {
+			t.Fatal(err)
+		}
+		want[key] = "value"
+	}
+
+	next := db.next
+	err = db.FlushContext(&cancelAfter{Context: context.Background(), n: 1})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if db.next < next+2 {
+		t.Fatalf("expected the compaction to write tables before cancelling, allocated %d", db.next-next)
+	}
+	if n := len(db.levels[0]); n != level0Limit {
+		t.Errorf("expected level 0 to keep its %d tables, got %d", level0Limit, n)
+	}
+	checkTables := func() {
+		t.Helper()
+		files, _ := filepath.Glob(filepath.Join(dir, "*.sst"))
+		live := 0
+		for _, level := range db.Metrics().Levels {
+			live += level.Tables
+		}
+		if len(files) != live {
+			t.Errorf("expected partial compaction output to be removed: %d files, %d live tables", len(files), live)
+		}
+	}
+	checkTables()
+	checkContents(t, db, want)
+
+	if err := db.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(db.levels[0]); n != 0 {
+		t.Errorf("expected the next flush to finish the compaction, level 0 has %d tables", n)
+	}
+	checkTables()
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if db, err = Open(dir, small...); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	checkContents(t, db, want)
+}
+
+func TestBloomAndWriteAmplification(t *testing.T) {
+	db, err := Open(t.TempDir(), small...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for i := 0; i < 20000; i++ {
+		if err := db.Put(fmt.Sprintf("key_%05d", i), "value"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	before := db.Metrics()
+	for i := 0; i < 1000; i++ {
+		// Absent keys inside the tables' key ranges must pass the range
+		// check and be turned away by the filters.
+		if _, found, _ := db.Get(fmt.Sprintf("key_%05d_absent", i*20)); found {
+			t.Fatal("found absent key")
+		}
+	}
+	m := db.Metrics()
+	skips, reads := m.BloomSkips-before.BloomSkips, m.BlockReads-before.BlockReads
+	if skips < 900 || reads > 100 {
+		t.Errorf("expected filters to avoid most block reads, got %d skips and %d reads", skips, reads)
+	}
+
+	if wa := m.WriteAmplification(); wa < 2 {
+		t.Errorf("expected compaction to amplify writes, got %.2f", wa)
+	}
+}
@@ -0,0 +1,102 @@
+package lsm
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/dsa-lab/go/internal/vfs"
+)
+
+const manifestHeader = "# dsa-lab lsm manifest v1"
+
+// manifest is the durable list of live tables. It is rewritten in full and
+// renamed into place after every flush and compaction, so a crash leaves
+// either the old or the new version and never a mix.
+//
+//	# dsa-lab lsm manifest v1
+//	next <next table number>
+//	table <level> <table number>
+type manifest struct {
+	next   uint64
+	levels [numLevels][]uint64
+}
+
+func tablePath(dir string, num uint64) string {
+	return filepath.Join(dir, fmt.Sprintf("%06d.sst", num))
+}
+
+func readManifest(fsys vfs.FS, dir string) (manifest, error) {
+	m := manifest{next: 1}
+	f, err := vfs.Open(fsys, filepath.Join(dir, "MANIFEST"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return m, err
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	if !s.Scan() || s.Text() != manifestHeader {
+		return m, fmt.Errorf("lsm: manifest: missing header")
+	}
+	for line := 2; s.Scan(); line++ {
+		var level int
+		var num uint64
+		text := s.Text()
+		switch {
+		case strings.HasPrefix(text, "next "):
+			if _, err := fmt.Sscanf(text, "next %d", &m.next); err != nil {
+				return m, fmt.Errorf("lsm: manifest line %d: %w", line, err)
+			}
+		case strings.HasPrefix(text, "table "):
+			if _, err := fmt.Sscanf(text, "table %d %d", &level, &num); err != nil {
+				return m, fmt.Errorf("lsm: manifest line %d: %w", line, err)
+			}
+			if level < 0 || level >= numLevels {
+				return m, fmt.Errorf("lsm: manifest line %d: level %d out of range", line, level)
+			}
+			m.levels[level] = append(m.levels[level], num)
+		default:
+			return m, fmt.Errorf("lsm: manifest line %d: unrecognized %q", line, text)
+		}
+	}
+	return m, s.Err()
+}
+
+func writeManifest(fsys vfs.FS, dir string, m manifest) error {
+	var b strings.Builder
+	fmt.Fprintln(&b, manifestHeader)
+	fmt.Fprintf(&b, "next %d\n", m.next)
+	for level, nums := range m.levels {
+		for _, num := range nums {
+			fmt.Fprintf(&b, "table %d %d\n", level, num)
+		}
+	}
+
+	tmp := filepath.Join(dir, "MANIFEST.tmp")
+	f, err := vfs.Create(fsys, tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(f, b.String()); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := fsys.Rename(tmp, filepath.Join(dir, "MANIFEST")); err != nil {
+		return err
+	}
+	return fsys.SyncDir(dir)
+}
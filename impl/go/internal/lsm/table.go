@@ -0,0 +1,366 @@
+package lsm
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/dsa-lab/go/internal/bloom"
+	"github.com/dsa-lab/go/internal/errs"
+	"github.com/dsa-lab/go/internal/vfs"
+)
+
+// Table (SSTable) file layout:
+//
+//	data:   records in key order
+//	record: flags byte | key length uvarint | value length uvarint | key | value
+//	index:  count uvarint | (key length uvarint | key | offset uvarint)...
+//	bloom:  bloom.Filter binary encoding
+//	meta:   smallest key length uvarint | smallest | largest length uvarint | largest
+//	footer: index, bloom and meta offsets, record count and magic, 8 bytes each
+//
+// The index holds the first key and offset of every block of blockRecords
+// records, so a lookup reads one block.
+const (
+	blockRecords = 16
+	footerSize   = 40
+	tableMagic   = 0x3174737361736400 // "\x00dsasst1"
+
+	flagDeleted = 1
+
+	bloomFalsePositiveRate = 0.01
+)
+
+// entry is a key with either a value or a deletion marker (tombstone).
+type entry struct {
+	key     string
+	value   string
+	deleted bool
+}
+
+// size approximates the encoded length of e, for memtable accounting.
+func (e entry) size() int64 {
+	return int64(len(e.key) + len(e.value) + 3)
+}
+
+type indexEntry struct {
+	key    string
+	offset int64
+}
+
+type tableWriter struct {
+	fs    vfs.FS
+	f     vfs.File
+	w     *bufio.Writer
+	off   int64
+	index []indexEntry
+	keys  []string
+	first string
+	last  string
+	buf   []byte
+}
+
+func createTable(fsys vfs.FS, path string) (*tableWriter, error) {
+	f, err := vfs.Create(fsys, path)
+	if err != nil {
+		return nil, err
+	}
+	return &tableWriter{fs: fsys, f: f, w: bufio.NewWriter(f)}, nil
+}
+
+// add appends e, which must sort after every entry already added.
+func (w *tableWriter) add(e entry) error {
+	if len(w.keys)%blockRecords == 0 {
+		w.index = append(w.index, indexEntry{key: e.key, offset: w.off})
+	}
+	if len(w.keys) == 0 {
+		w.first = e.key
+	}
+	w.last = e.key
+	w.keys = append(w.keys, e.key)
+
+	var flags byte
+	if e.deleted {
+		flags = flagDeleted
+	}
+	w.buf = append(w.buf[:0], flags)
+	w.buf = binary.AppendUvarint(w.buf, uint64(len(e.key)))
+	w.buf = binary.AppendUvarint(w.buf, uint64(len(e.value)))
+	w.buf = append(w.buf, e.key...)
+	w.buf = append(w.buf, e.value...)
+	n, err := w.w.Write(w.buf)
+	w.off += int64(n)
+	return err
+}
+
+func (w *tableWriter) size() int64 {
+	return w.off
+}
+
+// finish writes the index, filter, metadata and footer, then syncs and closes
+// the file. It returns the final file size.
+func (w *tableWriter) finish() (int64, error) {
+	indexOff := w.off
+	buf := binary.AppendUvarint(nil, uint64(len(w.index)))
+	for _, ie := range w.index {
+		buf = binary.AppendUvarint(buf, uint64(len(ie.key)))
+		buf = append(buf, ie.key...)
+		buf = binary.AppendUvarint(buf, uint64(ie.offset))
+	}
+
+	filter := bloom.MustNew(max(len(w.keys), 1), bloomFalsePositiveRate)
+	for _, key := range w.keys {
+		filter.Add(key)
+	}
+	bloomOff := indexOff + int64(len(buf))
+	filterBytes, _ := filter.MarshalBinary()
+	buf = append(buf, filterBytes...)
+
+	metaOff := indexOff + int64(len(buf))
+	buf = binary.AppendUvarint(buf, uint64(len(w.first)))
+	buf = append(buf, w.first...)
+	buf = binary.AppendUvarint(buf, uint64(len(w.last)))
+	buf = append(buf, w.last...)
+
+	for _, v := range []uint64{uint64(indexOff), uint64(bloomOff), uint64(metaOff), uint64(len(w.keys)), tableMagic} {
+		buf = binary.LittleEndian.AppendUint64(buf, v)
+	}
+	if _, err := w.w.Write(buf); err != nil {
+		w.abort()
+		return 0, err
+	}
+	if err := w.w.Flush(); err != nil {
+		w.abort()
+		return 0, err
+	}
+	if err := w.f.Sync(); err != nil {
+		w.abort()
+		return 0, err
+	}
+	return indexOff + int64(len(buf)), w.f.Close()
+}
+
+// abort closes and deletes a partially written table.
+func (w *tableWriter) abort() {
+	w.f.Close()
+	w.fs.Remove(w.f.Name())
+}
+
+// table is an open, immutable SSTable.
+type table struct {
+	num      uint64
+	f        vfs.File
+	size     int64
+	count    int
+	dataEnd  int64
+	index    []indexEntry
+	filter   *bloom.Filter
+	smallest string
+	largest  string
+}
+
+var errBadTable = errs.New(errs.ErrCorruptSnapshot, "lsm: malformed table")
+
+func openTable(fsys vfs.FS, path string, num uint64) (*table, error) {
+	f, err := vfs.Open(fsys, path)
+	if err != nil {
+		return nil, err
+	}
+	t, err := readTable(f, num)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return t, nil
+}
+
+func readTable(f vfs.File, num uint64) (*table, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := info.Size()
+	if size < footerSize {
+		return nil, errBadTable
+	}
+	var footer [footerSize]byte
+	if _, err := f.ReadAt(footer[:], size-footerSize); err != nil {
+		return nil, err
+	}
+	var v [5]uint64
+	for i := range v {
+		v[i] = binary.LittleEndian.Uint64(footer[8*i:])
+	}
+	indexOff, bloomOff, metaOff, count := int64(v[0]), int64(v[1]), int64(v[2]), int(v[3])
+	if v[4] != tableMagic || !(0 <= indexOff && indexOff <= bloomOff && bloomOff <= metaOff && metaOff <= size-footerSize) {
+		return nil, errBadTable
+	}
+
+	tail := make([]byte, size-footerSize-indexOff)
+	if _, err := f.ReadAt(tail, indexOff); err != nil {
+		return nil, err
+	}
+	t := &table{num: num, f: f, size: size, count: count, dataEnd: indexOff, filter: &bloom.Filter{}}
+
+	r := &sliceReader{buf: tail[:bloomOff-indexOff]}
+	n := r.uvarint()
+	for i := uint64(0); i < n && r.err == nil; i++ {
+		key := r.bytes()
+		t.index = append(t.index, indexEntry{key: key, offset: int64(r.uvarint())})
+	}
+	if r.err != nil {
+		return nil, errBadTable
+	}
+	if err := t.filter.UnmarshalBinary(tail[bloomOff-indexOff : metaOff-indexOff]); err != nil {
+		return nil, err
+	}
+	r = &sliceReader{buf: tail[metaOff-indexOff:]}
+	t.smallest, t.largest = r.bytes(), r.bytes()
+	if r.err != nil {
+		return nil, errBadTable
+	}
+	return t, nil
+}
+
+func (t *table) overlaps(smallest, largest string) bool {
+	return t.smallest <= largest && smallest <= t.largest
+}
+
+// get looks key up. skipped reports whether the bloom filter ruled the table
+// out without a block read.
+func (t *table) get(key string) (e entry, found, skipped bool, err error) {
+	if key < t.smallest || key > t.largest {
+		return entry{}, false, false, nil
+	}
+	if !t.filter.MayContain(key) {
+		return entry{}, false, true, nil
+	}
+
+	i := sort.Search(len(t.index), func(i int) bool { return t.index[i].key > key }) - 1
+	if i < 0 {
+		return entry{}, false, false, nil
+	}
+	end := t.dataEnd
+	if i+1 < len(t.index) {
+		end = t.index[i+1].offset
+	}
+	block := make([]byte, end-t.index[i].offset)
+	if _, err := t.f.ReadAt(block, t.index[i].offset); err != nil {
+		return entry{}, false, false, err
+	}
+
+	r := &sliceReader{buf: block}
+	for len(r.buf) > 0 {
+		e := r.entry()
+		if r.err != nil {
+			return entry{}, false, false, errBadTable
+		}
+		if e.key == key {
+			return e, true, false, nil
+		}
+	}
+	return entry{}, false, false, nil
+}
+
+func (t *table) iter() *tableIter {
+	return &tableIter{r: bufio.NewReader(io.NewSectionReader(t.f, 0, t.dataEnd)), remaining: t.count}
+}
+
+func (t *table) close() error {
+	return t.f.Close()
+}
+
+// tableIter streams a table's records in key order.
+type tableIter struct {
+	r         *bufio.Reader
+	remaining int
+	cur       entry
+	err       error
+}
+
+func (it *tableIter) next() bool {
+	if it.remaining == 0 || it.err != nil {
+		return false
+	}
+	it.remaining--
+	flags, err := it.r.ReadByte()
+	if err != nil {
+		it.err = err
+		return false
+	}
+	klen, err := binary.ReadUvarint(it.r)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	vlen, err := binary.ReadUvarint(it.r)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	buf := make([]byte, klen+vlen)
+	if _, err := io.ReadFull(it.r, buf); err != nil {
+		it.err = err
+		return false
+	}
+	it.cur = entry{key: string(buf[:klen]), value: string(buf[klen:]), deleted: flags&flagDeleted != 0}
+	return true
+}
+
+func (it *tableIter) entry() entry {
+	return it.cur
+}
+
+func (it *tableIter) error() error {
+	return it.err
+}
+
+// sliceReader decodes the table's varint-prefixed fields from a byte slice,
+// recording the first error instead of returning it from every call.
+type sliceReader struct {
+	buf []byte
+	err error
+}
+
+func (r *sliceReader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(r.buf)
+	if n <= 0 {
+		r.err = errBadTable
+		return 0
+	}
+	r.buf = r.buf[n:]
+	return v
+}
+
+func (r *sliceReader) take(n uint64) string {
+	if r.err != nil {
+		return ""
+	}
+	if n > uint64(len(r.buf)) {
+		r.err = errBadTable
+		return ""
+	}
+	s := string(r.buf[:n])
+	r.buf = r.buf[n:]
+	return s
+}
+
+func (r *sliceReader) bytes() string {
+	return r.take(r.uvarint())
+}
+
+func (r *sliceReader) entry() entry {
+	if len(r.buf) == 0 {
+		r.err = errBadTable
+		return entry{}
+	}
+	flags := r.buf[0]
+	r.buf = r.buf[1:]
+	klen, vlen := r.uvarint(), r.uvarint()
+	return entry{key: r.take(klen), value: r.take(vlen), deleted: flags&flagDeleted != 0}
+}
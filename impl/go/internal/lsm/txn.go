@@ -0,0 +1,61 @@
+package lsm
+
+import "github.com/dsa-lab/go/internal/skiplist"
+
+// Txn is a batch of writes to a DB. Commit logs the whole batch as a single
+// write-ahead log record before applying any of it, so after a crash the DB
+// recovers with either every write of the batch or none of them.
+type Txn struct {
+	db     *DB
+	writes *skiplist.SkipList[string, entry]
+}
+
+// Txn starts an empty batch of writes to db.
+func (db *DB) Txn() *Txn {
+	return &Txn{db: db, writes: skiplist.MustNew[string, entry]()}
+}
+
+// Len returns the number of keys the batch writes.
+func (t *Txn) Len() int {
+	return t.writes.Len()
+}
+
+// Put buffers setting key to value.
+func (t *Txn) Put(key, value string) {
+	t.writes.Insert(key, entry{key: key, value: value})
+}
+
+// Delete buffers deleting key.
+func (t *Txn) Delete(key string) {
+	t.writes.Insert(key, entry{key: key, deleted: true})
+}
+
+// Get returns the value of key with the batch applied: the batch's own write
+// of key if there is one, or else the DB's value.
+func (t *Txn) Get(key string) (string, bool, error) {
+	if e, found := t.writes.Get(key); found {
+		return e.value, !e.deleted, nil
+	}
+	return t.db.Get(key)
+}
+
+// Commit writes the batch to the DB and empties it. An error from appending
+// to the log means none of the batch was applied; later errors come from the
+// flush or compaction the batch triggered, after it was applied.
+func (t *Txn) Commit() error {
+	if t.writes.Len() == 0 {
+		return nil
+	}
+	entries := make([]entry, 0, t.writes.Len())
+	t.writes.Range(func(_ string, e entry) bool {
+		entries = append(entries, e)
+		return true
+	})
+	t.writes.Clear()
+	return t.db.write(entries...)
+}
+
+// Rollback discards the batch.
+func (t *Txn) Rollback() {
+	t.writes.Clear()
+}
@@ -0,0 +1,148 @@
+package matrix
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+func random(rng *rand.Rand, rows, cols int, integral bool) *Dense {
+	m := New(rows, cols)
+	for i := range m.data {
+		if integral {
+			m.data[i] = float64(rng.Intn(21) - 10)
+		} else {
+			m.data[i] = rng.Float64()*2 - 1
+		}
+	}
+	return m
+}
+
+var kernels = []struct {
+	name string
+	mul  func(a, b *Dense) *Dense
+}{
+	{"naive", MulNaive},
+	{"blocked", func(a, b *Dense) *Dense { return MulBlocked(a, b, 0) }},
+	{"blocked-7", func(a, b *Dense) *Dense { return MulBlocked(a, b, 7) }},
+	{"strassen", MulStrassen},
+}
+
+func TestMulKnownProduct(t *testing.T) {
+	a := FromRows([][]float64{{1, 2, 3}, {4, 5, 6}})
+	b := FromRows([][]float64{{7, 8}, {9, 10}, {11, 12}})
+	want := FromRows([][]float64{{58, 64}, {139, 154}})
+	for _, k := range kernels {
+		if got := k.mul(a, b); !EqualApprox(got, want, 0) {
+			t.Errorf("%s: expected %v, got %v", k.name, want.data, got.data)
+		}
+	}
+}
+
+// TestMulAgainstNaive uses small integer entries, whose products and sums are
+// exact in float64, so every kernel must agree with the naive loop exactly.
+// The larger shapes exceed StrassenCutoff so the recursion and padding run.
+func TestMulAgainstNaive(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	shapes := [][3]int{{1, 1, 1}, {3, 5, 2}, {64, 64, 64}, {65, 33, 70}, {130, 200, 150}, {256, 256, 256}}
+	for _, s := range shapes {
+		a, b := random(rng, s[0], s[1], true), random(rng, s[1], s[2], true)
+		want := MulNaive(a, b)
+		for _, k := range kernels[1:] {
+			got := k.mul(a, b)
+			if r, c := got.Dims(); r != s[0] || c != s[2] {
+				t.Fatalf("%s %v: expected %dx%d result, got %dx%d", k.name, s, s[0], s[2], r, c)
+			}
+			if !EqualApprox(got, want, 0) {
+				t.Errorf("%s %v: result differs from naive", k.name, s)
+			}
+		}
+	}
+}
+
+func TestMulFloatTolerance(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	a, b := random(rng, 300, 300, false), random(rng, 300, 300, false)
+	want := MulNaive(a, b)
+	for _, k := range kernels[1:] {
+		if got := k.mul(a, b); !EqualApprox(got, want, 1e-9) {
+			t.Errorf("%s: result differs from naive beyond 1e-9", k.name)
+		}
+	}
+}
+
+func TestMulIdentityAndVector(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	a := random(rng, 40, 40, false)
+	for _, k := range kernels {
+		if got := k.mul(a, Identity(40)); !EqualApprox(got, a, 0) {
+			t.Errorf("%s: expected a·I = a", k.name)
+		}
+	}
+	x := make([]float64, 40)
+	col := New(40, 1)
+	for i := range x {
+		x[i] = rng.Float64()
+		col.Set(i, 0, x[i])
+	}
+	y, want := a.MulVec(x), MulNaive(a, col)
+	for i, v := range y {
+		if v != want.At(i, 0) {
+			t.Fatalf("row %d: expected %v, got %v", i, want.At(i, 0), v)
+		}
+	}
+}
+
+func TestMulShapeMismatchPanics(t *testing.T) {
+	for _, k := range kernels {
+		func() {
+			defer func() {
+				if err, _ := recover().(error); !errors.Is(err, ErrShape) {
+					t.Errorf("%s: expected panic with ErrShape, got %v", k.name, err)
+				}
+			}()
+			k.mul(New(2, 3), New(2, 3))
+		}()
+	}
+}
+
+func TestCSRMulVecAgainstDense(t *testing.T) {
+	rng := rand.New(rand.NewSource(4))
+	for _, density := range []float64{0, 0.01, 0.2, 1} {
+		d := New(90, 70)
+		for i := range d.data {
+			if rng.Float64() < density {
+				d.data[i] = rng.Float64()*2 - 1
+			}
+		}
+		x := make([]float64, 70)
+		for i := range x {
+			x[i] = rng.Float64()
+		}
+		s := CSRFromDense(d)
+		if !EqualApprox(s.Dense(), d, 0) {
+			t.Fatalf("density %v: CSR round trip differs", density)
+		}
+		want, got := d.MulVec(x), s.MulVec(x)
+		for i := range want {
+			if want[i] != got[i] {
+				t.Fatalf("density %v, row %d: expected %v, got %v", density, i, want[i], got[i])
+			}
+		}
+	}
+}
+
+func TestNewCSRFromTriplets(t *testing.T) {
+	s := NewCSR(3, 4, []Triplet{{2, 3, 1}, {0, 1, 2}, {2, 0, 4}, {0, 1, 3}, {1, 2, 0}})
+	want := FromRows([][]float64{{0, 5, 0, 0}, {0, 0, 0, 0}, {4, 0, 0, 1}})
+	if !EqualApprox(s.Dense(), want, 0) {
+		t.Errorf("expected %v, got %v", want.data, s.Dense().data)
+	}
+	if s.NNZ() != 4 {
+		t.Errorf("expected 4 stored entries, got %d", s.NNZ())
+	}
+	got := s.MulVec([]float64{1, 2, 3, 4})
+	if got[0] != 10 || got[1] != 0 || got[2] != 8 {
+		t.Errorf("expected [10 0 8], got %v", got)
+	}
+}
@@ -0,0 +1,101 @@
+package matrix
+
+import "slices"
+
+// Triplet is one nonzero entry of a sparse matrix.
+type Triplet struct {
+	Row, Col int
+	Value    float64
+}
+
+// CSR is a sparse matrix in compressed sparse row form: the nonzeros of row
+// i are values[indptr[i]:indptr[i+1]], in columns indices[indptr[i]:...].
+// A matrix-vector product reads values and indices sequentially and touches
+// only the nonzeros, O(nnz) rather than O(rows*cols).
+type CSR struct {
+	rows, cols int
+	indptr     []int
+	indices    []int
+	values     []float64
+}
+
+// NewCSR builds a rows x cols matrix from its nonzeros in any order.
+// Duplicate positions are summed, and entries that sum to zero are kept.
+func NewCSR(rows, cols int, entries []Triplet) *CSR {
+	sorted := slices.Clone(entries)
+	slices.SortFunc(sorted, func(a, b Triplet) int {
+		if a.Row != b.Row {
+			return a.Row - b.Row
+		}
+		return a.Col - b.Col
+	})
+	m := &CSR{rows: rows, cols: cols, indptr: make([]int, rows+1)}
+	for i, e := range sorted {
+		if e.Row < 0 || e.Row >= rows || e.Col < 0 || e.Col >= cols {
+			panic(ErrShape)
+		}
+		if i > 0 && sorted[i-1].Row == e.Row && sorted[i-1].Col == e.Col {
+			m.values[len(m.values)-1] += e.Value
+			continue
+		}
+		m.indices = append(m.indices, e.Col)
+		m.values = append(m.values, e.Value)
+		m.indptr[e.Row+1]++
+	}
+	for i := 0; i < rows; i++ {
+		m.indptr[i+1] += m.indptr[i]
+	}
+	return m
+}
+
+// CSRFromDense builds the sparse form of d, keeping its nonzero elements.
+func CSRFromDense(d *Dense) *CSR {
+	m := &CSR{rows: d.rows, cols: d.cols, indptr: make([]int, d.rows+1)}
+	for i := 0; i < d.rows; i++ {
+		for j, v := range d.Row(i) {
+			if v != 0 {
+				m.indices = append(m.indices, j)
+				m.values = append(m.values, v)
+			}
+		}
+		m.indptr[i+1] = len(m.values)
+	}
+	return m
+}
+
+// Dims returns the number of rows and columns.
+func (m *CSR) Dims() (rows, cols int) {
+	return m.rows, m.cols
+}
+
+// NNZ returns the number of stored entries.
+func (m *CSR) NNZ() int {
+	return len(m.values)
+}
+
+// MulVec returns m·x.
+func (m *CSR) MulVec(x []float64) []float64 {
+	if len(x) != m.cols {
+		panic(ErrShape)
+	}
+	y := make([]float64, m.rows)
+	for i := range y {
+		sum := 0.0
+		for p := m.indptr[i]; p < m.indptr[i+1]; p++ {
+			sum += m.values[p] * x[m.indices[p]]
+		}
+		y[i] = sum
+	}
+	return y
+}
+
+// Dense returns m as a dense matrix.
+func (m *CSR) Dense() *Dense {
+	d := New(m.rows, m.cols)
+	for i := 0; i < m.rows; i++ {
+		for p := m.indptr[i]; p < m.indptr[i+1]; p++ {
+			d.Set(i, m.indices[p], m.values[p])
+		}
+	}
+	return d
+}
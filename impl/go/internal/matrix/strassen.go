@@ -0,0 +1,102 @@
+package matrix
+
+// StrassenCutoff is the size below which MulStrassen switches to blocked
+// multiplication; below it, the extra additions and allocations of the
+// recursion cost more than the multiplication they save.
+const StrassenCutoff = 128
+
+// MulStrassen returns a·b by Strassen's algorithm, which multiplies 2x2
+// block matrices with seven block products instead of eight, for
+// O(n^log2(7)) ≈ O(n^2.81) work. Operands are padded with zeros to a square
+// power-of-two size. Rounding error grows somewhat faster than with the
+// cubic algorithms, since each product is assembled from sums and
+// differences of blocks.
+func MulStrassen(a, b *Dense) *Dense {
+	checkMul(a, b)
+	n := 1
+	for n < max(a.rows, a.cols, b.cols) {
+		n *= 2
+	}
+	c := strassen(pad(a, n), pad(b, n))
+	if c.rows == a.rows && c.cols == b.cols {
+		return c
+	}
+	out := New(a.rows, b.cols)
+	for i := 0; i < a.rows; i++ {
+		copy(out.Row(i), c.Row(i)[:b.cols])
+	}
+	return out
+}
+
+// pad returns m extended with zeros to n x n, or m itself if it is n x n.
+func pad(m *Dense, n int) *Dense {
+	if m.rows == n && m.cols == n {
+		return m
+	}
+	p := New(n, n)
+	for i := 0; i < m.rows; i++ {
+		copy(p.Row(i), m.Row(i))
+	}
+	return p
+}
+
+func strassen(a, b *Dense) *Dense {
+	n := a.rows
+	if n <= StrassenCutoff {
+		c := New(n, n)
+		mulAddBlocked(c, a, b, DefaultBlock)
+		return c
+	}
+	h := n / 2
+	a11, a12, a21, a22 := quarter(a, 0, 0), quarter(a, 0, h), quarter(a, h, 0), quarter(a, h, h)
+	b11, b12, b21, b22 := quarter(b, 0, 0), quarter(b, 0, h), quarter(b, h, 0), quarter(b, h, h)
+
+	m1 := strassen(add(a11, a22), add(b11, b22))
+	m2 := strassen(add(a21, a22), b11)
+	m3 := strassen(a11, sub(b12, b22))
+	m4 := strassen(a22, sub(b21, b11))
+	m5 := strassen(add(a11, a12), b22)
+	m6 := strassen(sub(a21, a11), add(b11, b12))
+	m7 := strassen(sub(a12, a22), add(b21, b22))
+
+	c := New(n, n)
+	for i := 0; i < h; i++ {
+		top, bottom := c.Row(i), c.Row(i+h)
+		r1, r2, r3, r4 := m1.Row(i), m2.Row(i), m3.Row(i), m4.Row(i)
+		r5, r6, r7 := m5.Row(i), m6.Row(i), m7.Row(i)
+		for j := 0; j < h; j++ {
+			top[j] = r1[j] + r4[j] - r5[j] + r7[j]
+			top[j+h] = r3[j] + r5[j]
+			bottom[j] = r2[j] + r4[j]
+			bottom[j+h] = r1[j] - r2[j] + r3[j] + r6[j]
+		}
+	}
+	return c
+}
+
+// quarter copies the h x h block of m starting at (i, j), where h is half
+// of m's size.
+func quarter(m *Dense, i, j int) *Dense {
+	h := m.rows / 2
+	q := New(h, h)
+	for r := 0; r < h; r++ {
+		copy(q.Row(r), m.Row(i + r)[j:j+h])
+	}
+	return q
+}
+
+func add(a, b *Dense) *Dense {
+	c := New(a.rows, a.cols)
+	for i := range c.data {
+		c.data[i] = a.data[i] + b.data[i]
+	}
+	return c
+}
+
+func sub(a, b *Dense) *Dense {
+	c := New(a.rows, a.cols)
+	for i := range c.data {
+		c.data[i] = a.data[i] - b.data[i]
+	}
+	return c
+}
@@ -0,0 +1,17 @@
+//go:build go1.23
+
+package merkle
+
+import "iter"
+
+// All returns an iterator over the pairs the tree commits to, in ascending
+// key order.
+func (t *Tree) All() iter.Seq2[string, string] {
+	return func(yield func(string, string) bool) {
+		for _, p := range t.pairs {
+			if !yield(p.Key, p.Value) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,168 @@
+// Package merkle builds Merkle trees over key/value snapshots. The root hash
+// commits to every pair, so two snapshots with equal roots hold the same
+// data, and an inclusion proof of O(log n) hashes shows that one pair belongs
+// to a snapshot given only its root.
+package merkle
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"slices"
+	"strings"
+)
+
+// Hash is a SHA-256 digest.
+type Hash [sha256.Size]byte
+
+// Leaf and interior hashes take different prefixes, as in RFC 6962, so a
+// leaf can never be passed off as an interior node or the reverse.
+const (
+	leafPrefix = 0x00
+	nodePrefix = 0x01
+)
+
+// leafHash hashes a pair with length-prefixed fields, so that moving bytes
+// between key and value changes the hash.
+func leafHash(key, value string) Hash {
+	buf := make([]byte, 0, 1+2*binary.MaxVarintLen64+len(key)+len(value))
+	buf = append(buf, leafPrefix)
+	buf = binary.AppendUvarint(buf, uint64(len(key)))
+	buf = append(buf, key...)
+	buf = binary.AppendUvarint(buf, uint64(len(value)))
+	buf = append(buf, value...)
+	return sha256.Sum256(buf)
+}
+
+func nodeHash(left, right Hash) Hash {
+	var buf [1 + 2*sha256.Size]byte
+	buf[0] = nodePrefix
+	copy(buf[1:], left[:])
+	copy(buf[1+sha256.Size:], right[:])
+	return sha256.Sum256(buf[:])
+}
+
+// Pair is a key and its value.
+type Pair struct {
+	Key   string
+	Value string
+}
+
+// Tree is a Merkle tree over pairs sorted by key, so the root depends only
+// on the snapshot's contents and not on the order it was read in. Each level
+// hashes adjacent pairs of the level below; an unpaired last node is carried
+// up unchanged rather than duplicated, which would let two different
+// snapshots share a root.
+type Tree struct {
+	pairs  []Pair
+	levels [][]Hash // levels[0] are the leaves; the last level is the root
+}
+
+// New builds a tree over pairs. When a key repeats, its last value wins.
+func New(pairs []Pair) *Tree {
+	sorted := slices.Clone(pairs)
+	slices.SortStableFunc(sorted, func(a, b Pair) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	unique := sorted[:0]
+	for _, p := range sorted {
+		if n := len(unique); n > 0 && unique[n-1].Key == p.Key {
+			unique[n-1] = p
+			continue
+		}
+		unique = append(unique, p)
+	}
+
+	t := &Tree{pairs: unique}
+	level := make([]Hash, len(unique))
+	for i, p := range unique {
+		level[i] = leafHash(p.Key, p.Value)
+	}
+	t.levels = append(t.levels, level)
+	for len(level) > 1 {
+		next := make([]Hash, 0, (len(level)+1)/2)
+		for i := 0; i+1 < len(level); i += 2 {
+			next = append(next, nodeHash(level[i], level[i+1]))
+		}
+		if len(level)%2 == 1 {
+			next = append(next, level[len(level)-1])
+		}
+		t.levels = append(t.levels, next)
+		level = next
+	}
+	return t
+}
+
+// FromRange builds a tree over the pairs visited by a Range method, such as
+// that of diskhash.DiskHash or lsm.DB, and returns the error it returns.
+func FromRange(rng func(f func(key, value string) bool) error) (*Tree, error) {
+	var pairs []Pair
+	err := rng(func(key, value string) bool {
+		pairs = append(pairs, Pair{Key: key, Value: value})
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return New(pairs), nil
+}
+
+// Len returns the number of pairs.
+func (t *Tree) Len() int {
+	return len(t.pairs)
+}
+
+// Root returns the root hash. The root of an empty tree is the hash of no
+// input.
+func (t *Tree) Root() Hash {
+	top := t.levels[len(t.levels)-1]
+	if len(top) == 0 {
+		return sha256.Sum256(nil)
+	}
+	return top[0]
+}
+
+// Step is one sibling on the path from a leaf to the root.
+type Step struct {
+	Hash Hash
+	// Left reports whether the sibling is the left child, so the running
+	// hash goes on the right.
+	Left bool
+}
+
+// Proof shows that Key maps to Value in the snapshot with a given root.
+type Proof struct {
+	Key   string
+	Value string
+	Path  []Step
+}
+
+// Prove returns the inclusion proof of key, or false if key is absent.
+func (t *Tree) Prove(key string) (Proof, bool) {
+	i, found := slices.BinarySearchFunc(t.pairs, key, func(p Pair, key string) int {
+		return strings.Compare(p.Key, key)
+	})
+	if !found {
+		return Proof{}, false
+	}
+	proof := Proof{Key: key, Value: t.pairs[i].Value}
+	for _, level := range t.levels[:len(t.levels)-1] {
+		if sibling := i ^ 1; sibling < len(level) {
+			proof.Path = append(proof.Path, Step{Hash: level[sibling], Left: sibling < i})
+		}
+		i /= 2
+	}
+	return proof, true
+}
+
+// Verify reports whether p proves its pair against root.
+func Verify(root Hash, p Proof) bool {
+	h := leafHash(p.Key, p.Value)
+	for _, step := range p.Path {
+		if step.Left {
+			h = nodeHash(step.Hash, h)
+		} else {
+			h = nodeHash(h, step.Hash)
+		}
+	}
+	return h == root
+}
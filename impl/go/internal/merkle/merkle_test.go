@@ -0,0 +1,86 @@
+package merkle
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func pairs(n int) []Pair {
+	ps := make([]Pair, n)
+	for i := range ps {
+		ps[i] = Pair{Key: fmt.Sprintf("key%04d", i), Value: fmt.Sprint("value", i)}
+	}
+	return ps
+}
+
+func TestRootIgnoresOrder(t *testing.T) {
+	ps := pairs(100)
+	root := New(ps).Root()
+	rand.New(rand.NewSource(1)).Shuffle(len(ps), func(i, j int) { ps[i], ps[j] = ps[j], ps[i] })
+	if New(ps).Root() != root {
+		t.Error("expected the root not to depend on input order")
+	}
+
+	ps[0].Value += "!"
+	if New(ps).Root() == root {
+		t.Error("expected a changed value to change the root")
+	}
+	// Shifting bytes from key to value must not collide.
+	a := New([]Pair{{Key: "ab", Value: "c"}}).Root()
+	b := New([]Pair{{Key: "a", Value: "bc"}}).Root()
+	if a == b {
+		t.Error("expected length-prefixed leaves to tell ab=c from a=bc")
+	}
+	// Duplicating the last pair must not collide with the odd-sized tree.
+	odd := pairs(3)
+	even := append(pairs(3), Pair{Key: "key0003", Value: odd[2].Value})
+	if New(odd).Root() == New(even).Root() {
+		t.Error("expected carrying odd nodes up to avoid duplicate-leaf collisions")
+	}
+}
+
+func TestProofs(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 3, 7, 64, 100} {
+		tree := New(pairs(n))
+		root := tree.Root()
+		if tree.Len() != n {
+			t.Errorf("expected %d pairs, got %d", n, tree.Len())
+		}
+		for _, p := range pairs(n) {
+			proof, found := tree.Prove(p.Key)
+			if !found || proof.Value != p.Value {
+				t.Fatalf("n=%d: expected a proof of %s=%s", n, p.Key, p.Value)
+			}
+			if !Verify(root, proof) {
+				t.Fatalf("n=%d: proof of %s failed to verify", n, p.Key)
+			}
+			forged := proof
+			forged.Value += "x"
+			if Verify(root, forged) {
+				t.Fatalf("n=%d: forged value of %s verified", n, p.Key)
+			}
+		}
+		if _, found := tree.Prove("missing"); found {
+			t.Errorf("n=%d: expected no proof of a missing key", n)
+		}
+	}
+}
+
+func TestFromRangeDedupes(t *testing.T) {
+	data := []Pair{{"b", "1"}, {"a", "1"}, {"b", "2"}}
+	tree, err := FromRange(func(f func(key, value string) bool) error {
+		for _, p := range data {
+			if !f(p.Key, p.Value) {
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tree.Len() != 2 || tree.Root() != New([]Pair{{"a", "1"}, {"b", "2"}}).Root() {
+		t.Error("expected the last value of a repeated key to win")
+	}
+}
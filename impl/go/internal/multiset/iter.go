@@ -0,0 +1,22 @@
+//go:build go1.23
+
+package multiset
+
+import "iter"
+
+// All returns an iterator over the distinct elements and their counts, in
+// unspecified order.
+func (s *Multiset) All() iter.Seq2[string, int] {
+	return s.Range
+}
+
+// Keys returns an iterator over the distinct elements.
+func (s *Multiset) Keys() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for _, key := range s.keys {
+			if !yield(key) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,229 @@
+// Package multiset provides a bag of strings: a set that counts how many
+// times each element was added.
+package multiset
+
+import (
+	"strconv"
+
+	"github.com/dsa-lab/go/internal/hashmap"
+	"github.com/dsa-lab/go/internal/intern"
+)
+
+// Multiset counts occurrences of string elements. The hashmap maps each
+// distinct element to its position in two dense slices holding the elements
+// and their counts, so changing a count never rewrites the map and iteration
+// walks contiguous memory. Removing an element's last occurrence moves the
+// final element into its position.
+type Multiset struct {
+	index  *hashmap.HashMap
+	keys   []string
+	counts []int
+	total  int
+
+	// interner, when set, supplies the stored copy of each new element.
+	interner *intern.Interner
+}
+
+// Option configures a Multiset at construction time.
+type Option func(*Multiset)
+
+// WithInterner stores each distinct element through in, so elements parsed
+// from input keep one shared copy instead of the first caller's allocation.
+// A nil in gives the multiset a private Interner.
+func WithInterner(in *intern.Interner) Option {
+	return func(s *Multiset) {
+		if in == nil {
+			in = intern.New()
+		}
+		s.interner = in
+	}
+}
+
+// New creates an empty Multiset.
+func New(opts ...Option) *Multiset {
+	s := &Multiset{index: hashmap.MustNew()}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// position returns the dense index of key and true if key is present.
+func (s *Multiset) position(key string) (int, bool) {
+	v, found := s.index.Get(key)
+	if !found {
+		return 0, false
+	}
+	i, _ := strconv.Atoi(v)
+	return i, true
+}
+
+// Add adds one occurrence of key and returns its new count.
+func (s *Multiset) Add(key string) int {
+	return s.AddN(key, 1)
+}
+
+// AddN adds n occurrences of key and returns its new count. A non-positive n
+// leaves the multiset unchanged.
+func (s *Multiset) AddN(key string, n int) int {
+	i, found := s.position(key)
+	if n <= 0 {
+		if found {
+			return s.counts[i]
+		}
+		return 0
+	}
+	if !found {
+		i = len(s.keys)
+		if s.interner != nil {
+			key = s.interner.Intern(key)
+		}
+		s.index.Insert(key, strconv.Itoa(i))
+		s.keys = append(s.keys, key)
+		s.counts = append(s.counts, 0)
+	}
+	s.counts[i] += n
+	s.total += n
+	return s.counts[i]
+}
+
+// Remove removes one occurrence of key.
+// Returns true if key was present.
+func (s *Multiset) Remove(key string) bool {
+	return s.RemoveN(key, 1) > 0
+}
+
+// RemoveN removes up to n occurrences of key and returns how many it removed.
+func (s *Multiset) RemoveN(key string, n int) int {
+	i, found := s.position(key)
+	if !found || n <= 0 {
+		return 0
+	}
+	if n < s.counts[i] {
+		s.counts[i] -= n
+		s.total -= n
+		return n
+	}
+
+	removed := s.counts[i]
+	s.total -= removed
+	last := len(s.keys) - 1
+	if i != last {
+		s.keys[i], s.counts[i] = s.keys[last], s.counts[last]
+		s.index.Insert(s.keys[i], strconv.Itoa(i))
+	}
+	s.keys[last] = ""
+	s.keys, s.counts = s.keys[:last], s.counts[:last]
+	s.index.Remove(key)
+	return removed
+}
+
+// Count returns the number of occurrences of key.
+func (s *Multiset) Count(key string) int {
+	if i, found := s.position(key); found {
+		return s.counts[i]
+	}
+	return 0
+}
+
+// Contains reports whether key occurs at least once.
+func (s *Multiset) Contains(key string) bool {
+	return s.index.Contains(key)
+}
+
+// Distinct returns the number of distinct elements.
+func (s *Multiset) Distinct() int {
+	return len(s.keys)
+}
+
+// TotalLen returns the number of occurrences of all elements.
+func (s *Multiset) TotalLen() int {
+	return s.total
+}
+
+// Clear removes all elements.
+func (s *Multiset) Clear() {
+	s.index.Clear()
+	clear(s.keys)
+	s.keys, s.counts = s.keys[:0], s.counts[:0]
+	s.total = 0
+}
+
+// Range calls f for each distinct element and its count, in unspecified
+// order. If f returns false, iteration stops. f must not modify s.
+func (s *Multiset) Range(f func(key string, count int) bool) {
+	for i, key := range s.keys {
+		if !f(key, s.counts[i]) {
+			return
+		}
+	}
+}
+
+// Union returns the multiset holding each element with the larger of its
+// counts in a and b.
+func Union(a, b *Multiset) *Multiset {
+	u := a.clone()
+	b.Range(func(key string, count int) bool {
+		u.AddN(key, count-u.Count(key))
+		return true
+	})
+	return u
+}
+
+// Intersection returns the multiset holding each element with the smaller
+// of its counts in a and b.
+func Intersection(a, b *Multiset) *Multiset {
+	if a.Distinct() > b.Distinct() {
+		a, b = b, a
+	}
+	n := New()
+	a.Range(func(key string, count int) bool {
+		n.AddN(key, min(count, b.Count(key)))
+		return true
+	})
+	return n
+}
+
+// Difference returns the multiset holding each element of a with its count
+// reduced by its count in b, dropping elements that reach zero.
+func Difference(a, b *Multiset) *Multiset {
+	d := New()
+	a.Range(func(key string, count int) bool {
+		d.AddN(key, count-b.Count(key))
+		return true
+	})
+	return d
+}
+
+// Sum returns the multiset holding each element with its counts in a and b
+// added together.
+func Sum(a, b *Multiset) *Multiset {
+	s := a.clone()
+	b.Range(func(key string, count int) bool {
+		s.AddN(key, count)
+		return true
+	})
+	return s
+}
+
+// Subset reports whether every element of a occurs in b at least as often.
+func Subset(a, b *Multiset) bool {
+	if a.TotalLen() > b.TotalLen() {
+		return false
+	}
+	subset := true
+	a.Range(func(key string, count int) bool {
+		subset = count <= b.Count(key)
+		return subset
+	})
+	return subset
+}
+
+func (s *Multiset) clone() *Multiset {
+	c := New()
+	s.Range(func(key string, count int) bool {
+		c.AddN(key, count)
+		return true
+	})
+	return c
+}
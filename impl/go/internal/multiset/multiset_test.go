@@ -0,0 +1,89 @@
+package multiset
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/intern"
+)
+
+func of(pairs map[string]int) *Multiset {
+	s := New()
+	for key, n := range pairs {
+		s.AddN(key, n)
+	}
+	return s
+}
+
+func expectCounts(t *testing.T, name string, s *Multiset, want map[string]int) {
+	t.Helper()
+	total := 0
+	for key, n := range want {
+		if got := s.Count(key); got != n {
+			t.Errorf("%s: expected count(%s) = %d, got %d", name, key, n, got)
+		}
+		total += n
+	}
+	if s.Distinct() != len(want) || s.TotalLen() != total {
+		t.Errorf("%s: expected %d distinct and %d total, got %d and %d", name, len(want), total, s.Distinct(), s.TotalLen())
+	}
+}
+
+func TestAddRemove(t *testing.T) {
+	s := New()
+	s.Add("a")
+	s.Add("a")
+	if n := s.AddN("b", 3); n != 3 {
+		t.Errorf("expected count 3, got %d", n)
+	}
+	s.AddN("c", 0)
+	expectCounts(t, "after adds", s, map[string]int{"a": 2, "b": 3})
+
+	if !s.Remove("a") || s.Count("a") != 1 {
+		t.Errorf("expected one a left, got %d", s.Count("a"))
+	}
+	if removed := s.RemoveN("b", 10); removed != 3 || s.Contains("b") {
+		t.Errorf("expected all 3 b removed, got %d", removed)
+	}
+	if s.Remove("missing") {
+		t.Error("expected Remove of a missing key to report false")
+	}
+	expectCounts(t, "after removes", s, map[string]int{"a": 1})
+
+	s.Clear()
+	expectCounts(t, "after clear", s, map[string]int{})
+}
+
+func TestSetAlgebra(t *testing.T) {
+	a := of(map[string]int{"x": 3, "y": 1, "z": 2})
+	b := of(map[string]int{"x": 1, "y": 4, "w": 2})
+
+	expectCounts(t, "union", Union(a, b), map[string]int{"x": 3, "y": 4, "z": 2, "w": 2})
+	expectCounts(t, "intersection", Intersection(a, b), map[string]int{"x": 1, "y": 1})
+	expectCounts(t, "difference", Difference(a, b), map[string]int{"x": 2, "z": 2})
+	expectCounts(t, "sum", Sum(a, b), map[string]int{"x": 4, "y": 5, "z": 2, "w": 2})
+
+	if Subset(a, b) || !Subset(Intersection(a, b), a) || !Subset(a, Sum(a, b)) {
+		t.Error("unexpected subset relation")
+	}
+	// Operands are left unchanged.
+	expectCounts(t, "a", a, map[string]int{"x": 3, "y": 1, "z": 2})
+}
+
+func TestWithInterner(t *testing.T) {
+	in := intern.New()
+	a, b := New(WithInterner(in)), New(WithInterner(in))
+	for _, word := range strings.Fields("the cat saw the hat on the mat") {
+		a.Add(strings.Clone(word))
+		b.Add(strings.Clone(word))
+	}
+	if in.Len() != 6 {
+		t.Errorf("expected 6 distinct words interned, got %d", in.Len())
+	}
+	if s := in.Stats(); s.Hits != 6 {
+		t.Errorf("expected the second multiset to reuse all 6 words, got %d hits", s.Hits)
+	}
+	if a.Count("the") != 3 || b.Count("the") != 3 {
+		t.Errorf("expected count 3, got %d and %d", a.Count("the"), b.Count("the"))
+	}
+}
@@ -0,0 +1,21 @@
+//go:build go1.23
+
+package mvcc
+
+import "iter"
+
+// All returns an iterator over the keys present at the latest version and
+// their values, in unspecified order. The version is read when iteration
+// starts, and the map is read-locked until it ends, so the loop body must
+// not write to it. Use Range to read an older version, which can fail.
+func (m *Map) All() iter.Seq2[string, string] {
+	return func(yield func(string, string) bool) {
+		m.mu.RLock()
+		defer m.mu.RUnlock()
+		for _, key := range m.keys {
+			if value, found := m.get(key, m.current); found && !yield(key, value) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,319 @@
+// Package mvcc provides a multi-version string map: every write creates a
+// new version of the map, and readers can read at any version still
+// retained, seeing exactly the writes up to it, while writers continue.
+//
+// Each key holds a chain of versions, oldest first. A reader pins a version
+// with Snapshot or Pin and unpins it with Release. A key's old versions are
+// garbage once a newer one is at or below the oldest pinned version, the
+// watermark, since no reader can see them any more. A heap of pending
+// collections, ordered by the version at which each becomes garbage, lets
+// collection visit only the keys it can shrink.
+//
+// It sits between the mutable hashmap, which keeps one version, and fully
+// persistent maps such as a HAMT, which share structure so that every
+// version stays alive for as long as it is referenced.
+package mvcc
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/dsa-lab/go/internal/hashmap"
+	"github.com/dsa-lab/go/internal/heap"
+	"github.com/dsa-lab/go/internal/order"
+	"github.com/dsa-lab/go/internal/skiplist"
+)
+
+var (
+	// ErrVersionGone is returned for a version older than the oldest one
+	// the map still retains.
+	ErrVersionGone = errors.New("mvcc: version no longer retained")
+
+	// ErrFutureVersion is returned for a version not yet written.
+	ErrFutureVersion = errors.New("mvcc: version not yet written")
+
+	// ErrNotPinned is returned by Release for a version with no pins.
+	ErrNotPinned = errors.New("mvcc: version is not pinned")
+)
+
+// version is one value of a key, written at version ver. A deleted version
+// is a tombstone hiding the older ones.
+type version struct {
+	ver     uint64
+	value   string
+	deleted bool
+}
+
+// pending records that key's versions older than ready become garbage once
+// the watermark reaches ready.
+type pending struct {
+	ready uint64
+	key   string
+}
+
+// Stats describes the versions a Map holds.
+type Stats struct {
+	// Keys is the number of keys with a chain, live or not.
+	Keys int
+	// Versions is the number of versions stored across all chains.
+	Versions int
+	// Pins is the number of outstanding pins.
+	Pins int
+	// Oldest is the oldest version that can still be read.
+	Oldest uint64
+}
+
+// Map is a multi-version map from string keys to string values. Version 0
+// is the empty map and each Put or Delete creates the next one. A Map is
+// safe for concurrent use: readers share a lock that writers take alone, and
+// a pinned version reads the same however many writes follow.
+type Map struct {
+	mu sync.RWMutex
+
+	// index maps each key to its position in keys and chains, the dense
+	// layout the multiset uses.
+	index  *hashmap.HashMap
+	keys   []string
+	chains [][]version
+
+	current  uint64
+	pins     *skiplist.SkipList[uint64, int]
+	npins    int
+	versions int
+	gc       *heap.Heap[pending]
+}
+
+// New creates an empty Map at version 0.
+func New() *Map {
+	return &Map{
+		index: hashmap.MustNew(),
+		pins:  skiplist.MustNew[uint64, int](),
+		gc:    heap.New(order.By(func(p pending) uint64 { return p.ready })),
+	}
+}
+
+// Version returns the latest version.
+func (m *Map) Version() uint64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.current
+}
+
+// watermark returns the oldest version a reader may still ask for.
+func (m *Map) watermark() uint64 {
+	if oldest, _, ok := m.pins.At(0); ok {
+		return oldest
+	}
+	return m.current
+}
+
+func (m *Map) position(key string) (int, bool) {
+	v, found := m.index.Get(key)
+	if !found {
+		return 0, false
+	}
+	i, _ := strconv.Atoi(v)
+	return i, true
+}
+
+// Put sets key to value in a new version and returns that version.
+func (m *Map) Put(key, value string) uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.write(key, version{value: value})
+}
+
+// Delete removes key in a new version and returns that version and true.
+// If key is absent at the latest version, no version is created and Delete
+// returns the latest version and false.
+func (m *Map) Delete(key string) (uint64, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, found := m.get(key, m.current); !found {
+		return m.current, false
+	}
+	return m.write(key, version{deleted: true}), true
+}
+
+func (m *Map) write(key string, v version) uint64 {
+	m.current++
+	v.ver = m.current
+	i, found := m.position(key)
+	if !found {
+		i = len(m.keys)
+		m.index.Insert(key, strconv.Itoa(i))
+		m.keys = append(m.keys, key)
+		m.chains = append(m.chains, nil)
+	}
+	m.chains[i] = append(m.chains[i], v)
+	m.versions++
+	if len(m.chains[i]) > 1 || v.deleted {
+		m.gc.Push(pending{ready: v.ver, key: key})
+	}
+	m.collect()
+	return v.ver
+}
+
+// Get returns the value of key as of version. It fails with ErrVersionGone
+// if version is older than the oldest retained version and with
+// ErrFutureVersion if it is newer than the latest.
+func (m *Map) Get(key string, version uint64) (string, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if err := m.check(version); err != nil {
+		return "", false, err
+	}
+	value, found := m.get(key, version)
+	return value, found, nil
+}
+
+// Latest returns the value of key at the latest version.
+func (m *Map) Latest(key string) (string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.get(key, m.current)
+}
+
+func (m *Map) check(version uint64) error {
+	if version > m.current {
+		return ErrFutureVersion
+	}
+	if version < m.watermark() {
+		return ErrVersionGone
+	}
+	return nil
+}
+
+// get returns key's value as of version: the newest version at or below it.
+func (m *Map) get(key string, ver uint64) (string, bool) {
+	i, found := m.position(key)
+	if !found {
+		return "", false
+	}
+	chain := m.chains[i]
+	j := sort.Search(len(chain), func(j int) bool { return chain[j].ver > ver }) - 1
+	if j < 0 || chain[j].deleted {
+		return "", false
+	}
+	return chain[j].value, true
+}
+
+// Range calls f for each key present at version, with its value then, in
+// unspecified order, until f returns false. The map is read-locked while f
+// runs, so f must not write to it.
+func (m *Map) Range(version uint64, f func(key, value string) bool) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if err := m.check(version); err != nil {
+		return err
+	}
+	for _, key := range m.keys {
+		if value, found := m.get(key, version); found && !f(key, value) {
+			break
+		}
+	}
+	return nil
+}
+
+// Snapshot pins the latest version and returns it. Reads at that version
+// stay valid until it is released.
+func (m *Map) Snapshot() uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pin(m.current)
+	return m.current
+}
+
+// Pin pins version, which must still be retained, so a reader can reopen a
+// version another reader holds.
+func (m *Map) Pin(version uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.check(version); err != nil {
+		return err
+	}
+	m.pin(version)
+	return nil
+}
+
+func (m *Map) pin(version uint64) {
+	n, _ := m.pins.Get(version)
+	m.pins.Insert(version, n+1)
+	m.npins++
+}
+
+// Release drops one pin of version. Once no pin holds a version back,
+// versions it alone kept alive are collected.
+func (m *Map) Release(version uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n, found := m.pins.Get(version)
+	if !found {
+		return ErrNotPinned
+	}
+	if n == 1 {
+		m.pins.Remove(version)
+	} else {
+		m.pins.Insert(version, n-1)
+	}
+	m.npins--
+	m.collect()
+	return nil
+}
+
+// collect prunes every key whose pending collection the watermark has
+// reached.
+func (m *Map) collect() {
+	watermark := m.watermark()
+	for {
+		p, ok := m.gc.Peek()
+		if !ok || p.ready > watermark {
+			return
+		}
+		m.gc.Pop()
+		m.prune(p.key, watermark)
+	}
+}
+
+// prune drops the versions of key no read at or after watermark can see:
+// those older than the newest version at or below it, and that version too
+// if it is a tombstone. A key left without versions is removed.
+func (m *Map) prune(key string, watermark uint64) {
+	i, found := m.position(key)
+	if !found {
+		return
+	}
+	chain := m.chains[i]
+	j := sort.Search(len(chain), func(j int) bool { return chain[j].ver > watermark }) - 1
+	if j >= 0 && chain[j].deleted {
+		j++
+	}
+	if j <= 0 {
+		return
+	}
+	m.versions -= j
+	chain = append(chain[:0], chain[j:]...)
+	clear(m.chains[i][len(chain):])
+	m.chains[i] = chain
+	if len(chain) > 0 {
+		return
+	}
+
+	last := len(m.keys) - 1
+	if i != last {
+		m.keys[i], m.chains[i] = m.keys[last], m.chains[last]
+		m.index.Insert(m.keys[i], strconv.Itoa(i))
+	}
+	m.keys[last], m.chains[last] = "", nil
+	m.keys, m.chains = m.keys[:last], m.chains[:last]
+	m.index.Remove(key)
+}
+
+// Stats returns the map's version counts.
+func (m *Map) Stats() Stats {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return Stats{Keys: len(m.keys), Versions: m.versions, Pins: m.npins, Oldest: m.watermark()}
+}
@@ -0,0 +1,179 @@
+package mvcc
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+func TestVersions(t *testing.T) {
+	m := New()
+	v1 := m.Put("a", "1")
+	snap := m.Snapshot()
+	v2 := m.Put("a", "2")
+	v3, deleted := m.Delete("a")
+	if !deleted || v1 != 1 || snap != 1 || v2 != 2 || v3 != 3 {
+		t.Fatalf("expected versions 1, 1, 2, 3, got %d, %d, %d, %d", v1, snap, v2, v3)
+	}
+	if v, ok := m.Delete("a"); ok || v != 3 {
+		t.Errorf("expected deleting an absent key to create no version, got %d %v", v, ok)
+	}
+
+	for ver, want := range map[uint64]string{1: "1", 2: "2", 3: ""} {
+		got, found, err := m.Get("a", ver)
+		if err != nil || got != want || found != (want != "") {
+			t.Errorf("get at %d: expected %q, got %q %v %v", ver, want, got, found, err)
+		}
+	}
+	if _, _, err := m.Get("a", 0); !errors.Is(err, ErrVersionGone) {
+		t.Errorf("expected ErrVersionGone below the pin, got %v", err)
+	}
+	if _, _, err := m.Get("a", 4); !errors.Is(err, ErrFutureVersion) {
+		t.Errorf("expected ErrFutureVersion, got %v", err)
+	}
+	if s := m.Stats(); s.Versions != 3 || s.Pins != 1 || s.Oldest != 1 {
+		t.Errorf("expected 3 versions held by one pin at 1, got %+v", s)
+	}
+
+	if err := m.Pin(2); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Release(snap); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := m.Get("a", 1); !errors.Is(err, ErrVersionGone) {
+		t.Errorf("expected version 1 released, got %v", err)
+	}
+	if s := m.Stats(); s.Versions != 2 || s.Oldest != 2 {
+		t.Errorf("expected versions 2 and 3 kept for the pin at 2, got %+v", s)
+	}
+	if err := m.Release(2); err != nil {
+		t.Fatal(err)
+	}
+	if s := m.Stats(); s.Keys != 0 || s.Versions != 0 || s.Oldest != 3 {
+		t.Errorf("expected the deleted key collected, got %+v", s)
+	}
+	if err := m.Release(2); !errors.Is(err, ErrNotPinned) {
+		t.Errorf("expected ErrNotPinned, got %v", err)
+	}
+	if err := m.Pin(1); !errors.Is(err, ErrVersionGone) {
+		t.Errorf("expected pinning a collected version to fail, got %v", err)
+	}
+}
+
+func TestModel(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	m := New()
+	model := make(map[string]string)
+	// states records the model at every pinned version, with a pin count.
+	states := make(map[uint64]map[string]string)
+	pins := make(map[uint64]int)
+
+	check := func(ver uint64) {
+		want := states[ver]
+		got := make(map[string]string)
+		if err := m.Range(ver, func(key, value string) bool {
+			got[key] = value
+			return true
+		}); err != nil {
+			t.Fatalf("range at %d: %v", ver, err)
+		}
+		if !maps.Equal(got, want) {
+			t.Fatalf("range at %d: expected %d keys, got %d", ver, len(want), len(got))
+		}
+		key := fmt.Sprint(rng.Intn(50))
+		value, found, err := m.Get(key, ver)
+		if wantValue, wantFound := want[key]; err != nil || value != wantValue || found != wantFound {
+			t.Fatalf("get %s at %d: expected %q %v, got %q %v %v", key, ver, wantValue, wantFound, value, found, err)
+		}
+	}
+
+	for i := 0; i < 20000; i++ {
+		key := fmt.Sprint(rng.Intn(50))
+		switch r := rng.Intn(100); {
+		case r < 50:
+			value := fmt.Sprint(i)
+			m.Put(key, value)
+			model[key] = value
+		case r < 70:
+			_, deleted := m.Delete(key)
+			if _, ok := model[key]; ok != deleted {
+				t.Fatalf("delete %s: expected %v, got %v", key, ok, deleted)
+			}
+			delete(model, key)
+		case r < 75:
+			ver := m.Snapshot()
+			if pins[ver] == 0 {
+				states[ver] = maps.Clone(model)
+			}
+			pins[ver]++
+		case r < 82:
+			for ver := range pins {
+				if err := m.Release(ver); err != nil {
+					t.Fatal(err)
+				}
+				if pins[ver]--; pins[ver] == 0 {
+					delete(pins, ver)
+					delete(states, ver)
+				}
+				break
+			}
+		default:
+			for ver := range pins {
+				check(ver)
+				break
+			}
+		}
+	}
+
+	for ver, n := range pins {
+		check(ver)
+		for ; n > 0; n-- {
+			if err := m.Release(ver); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if s := m.Stats(); s.Keys != len(model) || s.Versions != len(model) || s.Pins != 0 {
+		t.Errorf("expected one version per live key after releasing every pin, got %+v for %d keys", s, len(model))
+	}
+}
+
+func TestConcurrentSnapshots(t *testing.T) {
+	m := New()
+	const writes = 5000
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < writes; i++ {
+			m.Put(fmt.Sprint(i), "x")
+		}
+	}()
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				ver := m.Snapshot()
+				// Version v is the map after the first v writes.
+				n := 0
+				m.Range(ver, func(_, _ string) bool {
+					n++
+					return true
+				})
+				if uint64(n) != ver {
+					t.Errorf("expected %d keys at version %d, got %d", ver, ver, n)
+				}
+				m.Release(ver)
+			}
+		}()
+	}
+	wg.Wait()
+	if s := m.Stats(); s.Versions != writes || s.Pins != 0 {
+		t.Errorf("expected %d versions and no pins, got %+v", writes, s)
+	}
+}
@@ -0,0 +1,61 @@
+// Package order defines the comparator shared by the lab's ordered
+// structures, so that every one of them interprets an ordering the same way.
+package order
+
+import "cmp"
+
+// Comparator returns a negative number, zero or a positive number when a
+// sorts before, equal to or after b. It must define a strict weak ordering:
+// consistent, transitive, and with Compare(a, b) == -Compare(b, a) in sign.
+type Comparator[K any] func(a, b K) int
+
+// Natural returns the comparator for the natural ordering of K.
+// NaN sorts before every other float, as in cmp.Compare.
+func Natural[K cmp.Ordered]() Comparator[K] {
+	return cmp.Compare[K]
+}
+
+// By orders values of T by the natural ordering of the key extracted by key.
+func By[T any, K cmp.Ordered](key func(T) K) Comparator[T] {
+	return func(a, b T) int {
+		return cmp.Compare(key(a), key(b))
+	}
+}
+
+// Reverse returns the comparator for the opposite ordering.
+func (c Comparator[K]) Reverse() Comparator[K] {
+	return func(a, b K) int {
+		return c(b, a)
+	}
+}
+
+// Then returns a comparator that orders by c and breaks ties with next.
+func (c Comparator[K]) Then(next Comparator[K]) Comparator[K] {
+	return func(a, b K) int {
+		if r := c(a, b); r != 0 {
+			return r
+		}
+		return next(a, b)
+	}
+}
+
+// Equal reports whether a and b are equivalent under c.
+func (c Comparator[K]) Equal(a, b K) bool {
+	return c(a, b) == 0
+}
+
+// Min returns the smaller of a and b under c, preferring a on ties.
+func (c Comparator[K]) Min(a, b K) K {
+	if c(b, a) < 0 {
+		return b
+	}
+	return a
+}
+
+// Max returns the larger of a and b under c, preferring a on ties.
+func (c Comparator[K]) Max(a, b K) K {
+	if c(b, a) > 0 {
+		return b
+	}
+	return a
+}
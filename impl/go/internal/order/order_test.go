@@ -0,0 +1,55 @@
+package order
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+type player struct {
+	name  string
+	score int
+}
+
+func TestNaturalAndReverse(t *testing.T) {
+	xs := []int{5, 1, 4, 2, 3}
+	slices.SortFunc(xs, Natural[int]())
+	if !slices.Equal(xs, []int{1, 2, 3, 4, 5}) {
+		t.Errorf("expected ascending order, got %v", xs)
+	}
+	slices.SortFunc(xs, Natural[int]().Reverse())
+	if !slices.Equal(xs, []int{5, 4, 3, 2, 1}) {
+		t.Errorf("expected descending order, got %v", xs)
+	}
+
+	if c := Natural[float64](); c(math.NaN(), math.Inf(-1)) >= 0 {
+		t.Error("expected NaN to sort before -Inf")
+	}
+}
+
+func TestByThen(t *testing.T) {
+	players := []player{{"carol", 10}, {"alice", 20}, {"bob", 10}, {"dave", 20}}
+	byScoreDesc := By(func(p player) int { return p.score }).Reverse()
+	slices.SortFunc(players, byScoreDesc.Then(By(func(p player) string { return p.name })))
+
+	want := []string{"alice", "dave", "bob", "carol"}
+	for i, p := range players {
+		if p.name != want[i] {
+			t.Fatalf("expected order %v, got %v", want, players)
+		}
+	}
+	if !byScoreDesc.Equal(players[2], players[3]) {
+		t.Error("expected equal scores to compare equal")
+	}
+}
+
+func TestMinMax(t *testing.T) {
+	c := By(func(p player) int { return p.score })
+	a, b := player{"a", 1}, player{"b", 1}
+	if c.Min(a, b) != a || c.Max(a, b) != a {
+		t.Error("expected ties to prefer the first argument")
+	}
+	if c.Min(player{"x", 3}, b) != b || c.Reverse().Min(player{"x", 3}, b).name != "x" {
+		t.Error("expected Min to follow the comparator")
+	}
+}
@@ -0,0 +1,30 @@
+//go:build go1.23
+
+package orderlist
+
+import "iter"
+
+// All returns an iterator over the elements from front to back. The loop
+// body may remove the element it is given, but no other.
+func (l *List[T]) All() iter.Seq[*Element[T]] {
+	return func(yield func(*Element[T]) bool) {
+		for e := l.head; e != nil; {
+			next := e.next
+			if !yield(e) {
+				return
+			}
+			e = next
+		}
+	}
+}
+
+// Values returns an iterator over the element values from front to back.
+func (l *List[T]) Values() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for e := l.head; e != nil; e = e.next {
+			if !yield(e.Value) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,331 @@
+// Package orderlist provides an order-maintenance list: a sequence that
+// supports inserting next to any element, deleting any element, and asking
+// which of two elements comes first, each in O(1) amortized time.
+//
+// Comparing positions in a linked list means walking it. Numbering the
+// elements instead makes comparison a subtraction, but an insert between
+// neighbours numbered 4 and 5 must first renumber some of the list. The
+// structure keeps that renumbering cheap with two levels of labels:
+//
+//   - Elements live in groups of at most 64, each element labelled within
+//     its group. When an insert finds no gap between two labels, only its
+//     group is renumbered, and a full group is split in two.
+//   - Groups carry their own 64-bit tags. A group inserted where tags have
+//     run out renumbers the smallest aligned range of tags around it that is
+//     sparse enough, following Bender, Cole, Demaine, Farach-Colton and Zito,
+//     "Two Simplified Algorithms for Maintaining Order in a List" (2002).
+//     This costs O(log n) amortized, but only one insert in 32 or more
+//     creates a group, which brings the amortized cost per element to O(1).
+//
+// Elements are compared by their groups' tags first and then by their own
+// labels.
+package orderlist
+
+import "math"
+
+const (
+	// groupLimit is the most elements a group holds; a group reaching it
+	// splits in two.
+	groupLimit = 64
+
+	// density is the threshold base: a tag range of 2^i tags may be
+	// renumbered once it holds fewer than (2/density)^i groups. It must lie
+	// between 1 and 2; smaller values renumber larger ranges less often.
+	density = 1.4
+)
+
+// Element is one position in a List, carrying a value.
+type Element[T any] struct {
+	Value T
+
+	label      uint64
+	group      *group[T]
+	prev, next *Element[T]
+}
+
+// Next returns the element after e, or nil if e is last.
+func (e *Element[T]) Next() *Element[T] {
+	return e.next
+}
+
+// Prev returns the element before e, or nil if e is first.
+func (e *Element[T]) Prev() *Element[T] {
+	return e.prev
+}
+
+// group is a run of consecutive elements sharing a tag.
+type group[T any] struct {
+	tag        uint64
+	first      *Element[T]
+	size       int
+	prev, next *group[T]
+}
+
+// Stats counts the renumbering work a List has done, so the amortized bounds
+// can be checked empirically.
+type Stats struct {
+	// Groups is the current number of groups.
+	Groups int
+	// Labels counts element labels assigned by group renumbering and splits.
+	Labels uint64
+	// Tags counts group tags assigned by tag-range renumbering.
+	Tags uint64
+}
+
+// List is an order-maintenance list. The zero List is empty and ready to use.
+// It is not safe for concurrent use.
+type List[T any] struct {
+	head, tail *Element[T]
+	groups     *group[T]
+	len        int
+	stats      Stats
+}
+
+// New creates an empty List.
+func New[T any]() *List[T] {
+	return &List[T]{}
+}
+
+// Len returns the number of elements.
+func (l *List[T]) Len() int {
+	return l.len
+}
+
+// Front returns the first element, or nil if the list is empty.
+func (l *List[T]) Front() *Element[T] {
+	return l.head
+}
+
+// Back returns the last element, or nil if the list is empty.
+func (l *List[T]) Back() *Element[T] {
+	return l.tail
+}
+
+// Stats returns the list's renumbering counts.
+func (l *List[T]) Stats() Stats {
+	return l.stats
+}
+
+// Order compares the positions of x and y, which must both be in l: it
+// returns a negative number if x comes before y, zero if they are the same
+// element and a positive number if x comes after y. It is an
+// order.Comparator, so ordered structures can sort elements by position.
+func (l *List[T]) Order(x, y *Element[T]) int {
+	gx, gy := x.group, y.group
+	if gx != gy {
+		if gx.tag < gy.tag {
+			return -1
+		}
+		return 1
+	}
+	switch {
+	case x.label < y.label:
+		return -1
+	case x.label > y.label:
+		return 1
+	}
+	return 0
+}
+
+// PushFront inserts a new first element holding v and returns it.
+func (l *List[T]) PushFront(v T) *Element[T] {
+	e := &Element[T]{Value: v}
+	if l.head == nil {
+		g := &group[T]{tag: math.MaxUint64 / 2, first: e, size: 1}
+		l.groups = g
+		l.stats.Groups++
+		e.group, e.label = g, math.MaxUint64/2
+		l.head, l.tail = e, e
+		l.len++
+		return e
+	}
+	first := l.head
+	g := first.group
+	if first.label == 0 {
+		l.renumber(g)
+	}
+	e.group, e.label = g, first.label/2
+	e.next, first.prev = first, e
+	l.head, g.first = e, e
+	l.added(g)
+	return e
+}
+
+// PushBack inserts a new last element holding v and returns it.
+func (l *List[T]) PushBack(v T) *Element[T] {
+	if l.tail == nil {
+		return l.PushFront(v)
+	}
+	return l.InsertAfter(l.tail, v)
+}
+
+// InsertBefore inserts a new element holding v just before mark, which must
+// be in l, and returns it.
+func (l *List[T]) InsertBefore(mark *Element[T], v T) *Element[T] {
+	if mark.prev == nil {
+		return l.PushFront(v)
+	}
+	return l.InsertAfter(mark.prev, v)
+}
+
+// InsertAfter inserts a new element holding v just after mark, which must be
+// in l, and returns it.
+func (l *List[T]) InsertAfter(mark *Element[T], v T) *Element[T] {
+	g := mark.group
+	if l.upper(mark)-mark.label < 2 {
+		l.renumber(g)
+	}
+	e := &Element[T]{Value: v, group: g}
+	e.label = mark.label + (l.upper(mark)-mark.label)/2
+	e.prev, e.next = mark, mark.next
+	if mark.next != nil {
+		mark.next.prev = e
+	} else {
+		l.tail = e
+	}
+	mark.next = e
+	l.added(g)
+	return e
+}
+
+// upper returns the label bounding inserts after e in its group: the next
+// element's label, or the top of the label space if e ends its group.
+func (l *List[T]) upper(e *Element[T]) uint64 {
+	if e.next != nil && e.next.group == e.group {
+		return e.next.label
+	}
+	return math.MaxUint64
+}
+
+// added accounts for an element just linked into g, splitting g if it is
+// full.
+func (l *List[T]) added(g *group[T]) {
+	l.len++
+	g.size++
+	if g.size >= groupLimit {
+		l.split(g)
+	}
+}
+
+// renumber spreads the labels of g's elements evenly over the label space,
+// leaving the widest possible gaps around every element.
+func (l *List[T]) renumber(g *group[T]) {
+	gap := math.MaxUint64/uint64(g.size+1) + 1
+	e := g.first
+	for i := 1; i <= g.size; i++ {
+		e.label = uint64(i) * gap
+		e = e.next
+	}
+	l.stats.Labels += uint64(g.size)
+}
+
+// split moves the second half of g into a new group after it and renumbers
+// both halves.
+func (l *List[T]) split(g *group[T]) {
+	half := g.size / 2
+	e := g.first
+	for i := 0; i < half; i++ {
+		e = e.next
+	}
+	h := &group[T]{first: e, size: g.size - half}
+	g.size = half
+	for x, i := e, 0; i < h.size; x, i = x.next, i+1 {
+		x.group = h
+	}
+	l.insertGroup(g, h)
+	l.renumber(g)
+	l.renumber(h)
+}
+
+// insertGroup links h into the group list after g and gives it a tag.
+func (l *List[T]) insertGroup(g, h *group[T]) {
+	h.prev, h.next = g, g.next
+	if g.next != nil {
+		g.next.prev = h
+	}
+	g.next = h
+	l.stats.Groups++
+
+	upper := uint64(math.MaxUint64)
+	if h.next != nil {
+		upper = h.next.tag
+	}
+	if upper-g.tag >= 2 {
+		h.tag = g.tag + (upper-g.tag)/2
+		return
+	}
+	l.retag(g)
+}
+
+// retag finds the smallest aligned range of 2^i tags around g's tag that
+// holds fewer than (2/density)^i groups, counting the group just linked
+// after g, and spreads those groups' tags evenly over the range.
+func (l *List[T]) retag(g *group[T]) {
+	limit := 1.0
+	for i := 1; i <= 64; i++ {
+		limit *= 2 / density
+		lo, hi := uint64(0), uint64(math.MaxUint64)
+		if i < 64 {
+			lo = g.tag &^ (1<<i - 1)
+			hi = lo + 1<<i - 1
+		}
+		// The new group after g has no tag yet, so it is counted with g.
+		first, count := g, 2
+		for first.prev != nil && first.prev.tag >= lo {
+			first = first.prev
+			count++
+		}
+		for x := g.next.next; x != nil && x.tag <= hi; x = x.next {
+			count++
+		}
+		if float64(count) >= limit && i < 64 {
+			continue
+		}
+
+		// count is below 2^i, so every group gets a distinct tag, and
+		// the last stays inside the range.
+		step := uint64(math.MaxUint64) / uint64(count)
+		if i < 64 {
+			step = (1 << i) / uint64(count)
+		}
+		x := first
+		for k := 0; k < count; k++ {
+			x.tag = lo + uint64(k)*step
+			x = x.next
+		}
+		l.stats.Tags += uint64(count)
+		return
+	}
+}
+
+// Remove deletes e from l. e must be in l and must not be used afterwards.
+func (l *List[T]) Remove(e *Element[T]) {
+	g := e.group
+	if g.first == e {
+		g.first = e.next
+	}
+	if e.prev != nil {
+		e.prev.next = e.next
+	} else {
+		l.head = e.next
+	}
+	if e.next != nil {
+		e.next.prev = e.prev
+	} else {
+		l.tail = e.prev
+	}
+	g.size--
+	l.len--
+	if g.size == 0 {
+		l.stats.Groups--
+		if g.prev != nil {
+			g.prev.next = g.next
+		} else {
+			l.groups = g.next
+		}
+		if g.next != nil {
+			g.next.prev = g.prev
+		}
+	}
+	e.group, e.prev, e.next = nil, nil, nil
+}
@@ -0,0 +1,100 @@
+package orderlist
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// check verifies that l holds exactly the elements of model, in order, and
+// that Order agrees with their positions.
+func check(t *testing.T, l *List[int], model []*Element[int], rng *rand.Rand) {
+	t.Helper()
+	if l.Len() != len(model) {
+		t.Fatalf("expected %d elements, got %d", len(model), l.Len())
+	}
+	i := 0
+	for e := l.Front(); e != nil; e = e.Next() {
+		if e != model[i] {
+			t.Fatalf("expected element %d to be %d, got %d", i, model[i].Value, e.Value)
+		}
+		if i > 0 && l.Order(model[i-1], e) >= 0 {
+			t.Fatalf("expected element %d before element %d", i-1, i)
+		}
+		i++
+	}
+	for k := 0; k < 100 && len(model) > 0; k++ {
+		a, b := rng.Intn(len(model)), rng.Intn(len(model))
+		got := l.Order(model[a], model[b])
+		if (a < b) != (got < 0) || (a == b) != (got == 0) {
+			t.Fatalf("expected Order(%d, %d) to agree with positions, got %d", a, b, got)
+		}
+	}
+}
+
+func TestModel(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	l := New[int]()
+	var model []*Element[int]
+	for op := 0; op < 30000; op++ {
+		switch r := rng.Intn(10); {
+		case len(model) == 0 || r < 2:
+			model = append([]*Element[int]{l.PushFront(op)}, model...)
+		case r < 3:
+			model = append(model, l.PushBack(op))
+		case r < 6:
+			// Insert repeatedly near one hot spot to exhaust label gaps.
+			i := min(len(model)-1, len(model)/3+rng.Intn(3))
+			e := l.InsertAfter(model[i], op)
+			model = append(model[:i+1], append([]*Element[int]{e}, model[i+1:]...)...)
+		case r < 8:
+			i := rng.Intn(len(model))
+			e := l.InsertBefore(model[i], op)
+			model = append(model[:i], append([]*Element[int]{e}, model[i:]...)...)
+		default:
+			i := rng.Intn(len(model))
+			l.Remove(model[i])
+			model = append(model[:i], model[i+1:]...)
+		}
+		if op%500 == 0 {
+			check(t, l, model, rng)
+		}
+	}
+	check(t, l, model, rng)
+	for len(model) > 0 {
+		l.Remove(model[0])
+		model = model[1:]
+	}
+	if l.Front() != nil || l.Back() != nil || l.Stats().Groups != 0 {
+		t.Errorf("expected an empty list, got %+v", l.Stats())
+	}
+}
+
+// TestAmortizedRelabeling inserts always after the same element, which
+// halves the same gap every time, and bounds the relabeling work per insert.
+func TestAmortizedRelabeling(t *testing.T) {
+	for _, pattern := range []string{"after-first", "front", "back"} {
+		l := New[int]()
+		first := l.PushBack(0)
+		const n = 200_000
+		for i := 1; i < n; i++ {
+			switch pattern {
+			case "after-first":
+				l.InsertAfter(first, i)
+			case "front":
+				l.PushFront(i)
+			default:
+				l.PushBack(i)
+			}
+		}
+		s := l.Stats()
+		if perOp := float64(s.Labels+s.Tags) / n; perOp > 4 {
+			t.Errorf("%s: expected O(1) relabels per insert, got %.1f (%+v)", pattern, perOp, s)
+		}
+		prev := l.Front()
+		for e := prev.Next(); e != nil; prev, e = e, e.Next() {
+			if l.Order(prev, e) >= 0 {
+				t.Fatalf("%s: expected list order to match Order", pattern)
+			}
+		}
+	}
+}
@@ -0,0 +1,119 @@
+// Package pool provides a freelist for recycling the nodes of linked
+// structures. Unlike sync.Pool it is not safe for concurrent use and is never
+// emptied by the garbage collector, so a structure that deletes and inserts
+// at a steady rate stops allocating once the freelist is warm.
+package pool
+
+import (
+	"fmt"
+
+	"github.com/dsa-lab/go/internal/errs"
+)
+
+// Stats counts freelist traffic.
+type Stats struct {
+	// Gets is the number of objects handed out.
+	Gets uint64
+	// Allocs is the number of Gets that found the freelist empty.
+	Allocs uint64
+	// Puts is the number of objects returned.
+	Puts uint64
+	// Dropped is the number of returned objects discarded because the
+	// freelist was full.
+	Dropped uint64
+}
+
+// Freelist hands out *T values, reusing returned ones before allocating.
+type Freelist[T any] struct {
+	free  []*T
+	limit int
+	reset func(*T)
+	stats Stats
+
+	// optErr is set by an option given an invalid argument.
+	optErr error
+}
+
+// Option configures a Freelist at construction time.
+type Option[T any] func(*Freelist[T])
+
+// WithLimit caps the number of idle objects retained; further Puts are
+// dropped for the garbage collector. The default is unlimited; a limit of
+// zero retains nothing, and a negative limit is invalid.
+func WithLimit[T any](n int) Option[T] {
+	return func(f *Freelist[T]) {
+		if n < 0 {
+			f.optErr = fmt.Errorf("pool: WithLimit(%d): %w", n, errs.ErrInvalidOption)
+			return
+		}
+		f.limit = n
+	}
+}
+
+// WithReset replaces the default reset, which zeroes the object, with one
+// that may keep internal buffers for reuse. reset must clear every reference
+// the object holds to caller data so the freelist does not retain it.
+func WithReset[T any](reset func(*T)) Option[T] {
+	return func(f *Freelist[T]) {
+		f.reset = reset
+	}
+}
+
+// New creates an empty Freelist. An invalid option is an error matching
+// errs.ErrInvalidOption.
+func New[T any](opts ...Option[T]) (*Freelist[T], error) {
+	f := &Freelist[T]{limit: -1}
+	for _, opt := range opts {
+		opt(f)
+	}
+	if f.optErr != nil {
+		return nil, f.optErr
+	}
+	return f, nil
+}
+
+// MustNew is like New but panics if an option is invalid.
+func MustNew[T any](opts ...Option[T]) *Freelist[T] {
+	return errs.Must(New(opts...))
+}
+
+// Get returns a recycled object, or a newly allocated zero one if none is
+// idle.
+func (f *Freelist[T]) Get() *T {
+	f.stats.Gets++
+	if n := len(f.free); n > 0 {
+		x := f.free[n-1]
+		f.free[n-1] = nil
+		f.free = f.free[:n-1]
+		return x
+	}
+	f.stats.Allocs++
+	return new(T)
+}
+
+// Put resets x and keeps it for a later Get. The caller must not use x
+// afterwards.
+func (f *Freelist[T]) Put(x *T) {
+	f.stats.Puts++
+	if f.limit >= 0 && len(f.free) >= f.limit {
+		f.stats.Dropped++
+		return
+	}
+	if f.reset != nil {
+		f.reset(x)
+	} else {
+		var zero T
+		*x = zero
+	}
+	f.free = append(f.free, x)
+}
+
+// Len returns the number of idle objects.
+func (f *Freelist[T]) Len() int {
+	return len(f.free)
+}
+
+// Stats returns the traffic counters.
+func (f *Freelist[T]) Stats() Stats {
+	return f.stats
+}
@@ -0,0 +1,57 @@
+package pool
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/errs"
+)
+
+type item struct {
+	name string
+	buf  []byte
+}
+
+func TestReuse(t *testing.T) {
+	f := MustNew[item]()
+	a := f.Get()
+	a.name = "a"
+	f.Put(a)
+	if f.Len() != 1 {
+		t.Fatalf("expected 1 idle object, got %d", f.Len())
+	}
+	b := f.Get()
+	if b != a {
+		t.Error("expected the returned object to be reused")
+	}
+	if b.name != "" {
+		t.Errorf("expected a zeroed object, got name %q", b.name)
+	}
+	if s := f.Stats(); s.Gets != 2 || s.Allocs != 1 || s.Puts != 1 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+}
+
+func TestLimitAndReset(t *testing.T) {
+	f := MustNew(WithLimit[item](1), WithReset(func(x *item) {
+		x.name = ""
+		x.buf = x.buf[:0]
+	}))
+	a, b := f.Get(), f.Get()
+	a.name, a.buf = "a", append(a.buf, "data"...)
+	f.Put(a)
+	f.Put(b)
+	if f.Len() != 1 || f.Stats().Dropped != 1 {
+		t.Fatalf("expected one retained and one dropped, got %d idle and %+v", f.Len(), f.Stats())
+	}
+	got := f.Get()
+	if got.name != "" || len(got.buf) != 0 || cap(got.buf) < 4 {
+		t.Errorf("expected custom reset to clear fields and keep the buffer, got %+v", got)
+	}
+}
+
+func TestInvalidLimit(t *testing.T) {
+	if _, err := New(WithLimit[item](-1)); !errors.Is(err, errs.ErrInvalidOption) {
+		t.Errorf("expected ErrInvalidOption, got %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+package prefixsum
+
+import "github.com/dsa-lab/go/internal/aggregate"
+
+// Sums2D holds the prefix sums of a rectangular grid, answering the sum of
+// any rectangular region in O(1) by inclusion-exclusion.
+type Sums2D[T aggregate.Number] struct {
+	rows, cols int
+	prefix     []T // (rows+1) x (cols+1); the sum of the region above and left of (r, c)
+}
+
+// New2D computes the prefix sums of grid in O(rows*cols). Every row must have
+// the same length.
+func New2D[T aggregate.Number](grid [][]T) *Sums2D[T] {
+	rows := len(grid)
+	cols := 0
+	if rows > 0 {
+		cols = len(grid[0])
+	}
+	s := &Sums2D[T]{rows: rows, cols: cols, prefix: make([]T, (rows+1)*(cols+1))}
+	for r, row := range grid {
+		if len(row) != cols {
+			panic("prefixsum: ragged grid")
+		}
+		var rowSum T
+		for c, x := range row {
+			rowSum += x
+			s.prefix[s.at(r+1, c+1)] = s.prefix[s.at(r, c+1)] + rowSum
+		}
+	}
+	return s
+}
+
+func (s *Sums2D[T]) at(r, c int) int {
+	return r*(s.cols+1) + c
+}
+
+// Dims returns the number of rows and columns.
+func (s *Sums2D[T]) Dims() (rows, cols int) {
+	return s.rows, s.cols
+}
+
+// Sum returns the sum of the region of rows [r0, r1) and columns [c0, c1).
+func (s *Sums2D[T]) Sum(r0, c0, r1, c1 int) T {
+	if r0 > r1 || c0 > c1 {
+		panic("prefixsum: region start after end")
+	}
+	return s.prefix[s.at(r1, c1)] - s.prefix[s.at(r0, c1)] - s.prefix[s.at(r1, c0)] + s.prefix[s.at(r0, c0)]
+}
+
+// Diff2D is the 2D difference array: a grid of zeros that takes region
+// additions in O(1), touching only the region's four corners, and
+// materializes the grid in O(rows*cols) when read.
+type Diff2D[T aggregate.Number] struct {
+	rows, cols int
+	delta      []T // (rows+1) x (cols+1)
+	values     []T // rows x cols
+	dirty      bool
+}
+
+// NewDiff2D creates a rows x cols grid of zeros.
+func NewDiff2D[T aggregate.Number](rows, cols int) *Diff2D[T] {
+	return &Diff2D[T]{
+		rows:   rows,
+		cols:   cols,
+		delta:  make([]T, (rows+1)*(cols+1)),
+		values: make([]T, rows*cols),
+	}
+}
+
+// Dims returns the number of rows and columns.
+func (d *Diff2D[T]) Dims() (rows, cols int) {
+	return d.rows, d.cols
+}
+
+// AddRegion adds v to every cell in rows [r0, r1) and columns [c0, c1).
+func (d *Diff2D[T]) AddRegion(r0, c0, r1, c1 int, v T) {
+	if r0 > r1 || c0 > c1 {
+		panic("prefixsum: region start after end")
+	}
+	w := d.cols + 1
+	d.delta[r0*w+c0] += v
+	d.delta[r0*w+c1] -= v
+	d.delta[r1*w+c0] -= v
+	d.delta[r1*w+c1] += v
+	d.dirty = true
+}
+
+func (d *Diff2D[T]) materialize() {
+	if !d.dirty {
+		return
+	}
+	w := d.cols + 1
+	// Each cell is the 2D prefix sum of delta up to and including it.
+	above := make([]T, d.cols)
+	for r := 0; r < d.rows; r++ {
+		var rowSum T
+		for c := 0; c < d.cols; c++ {
+			rowSum += d.delta[r*w+c]
+			above[c] += rowSum
+			d.values[r*d.cols+c] = above[c]
+		}
+	}
+	d.dirty = false
+}
+
+// At returns the value of cell (r, c).
+func (d *Diff2D[T]) At(r, c int) T {
+	if c < 0 || c >= d.cols {
+		panic("prefixsum: column out of range")
+	}
+	d.materialize()
+	return d.values[r*d.cols+c]
+}
+
+// Grid returns a copy of the grid as rows.
+func (d *Diff2D[T]) Grid() [][]T {
+	d.materialize()
+	grid := make([][]T, d.rows)
+	for r := range grid {
+		grid[r] = append([]T(nil), d.values[r*d.cols:(r+1)*d.cols]...)
+	}
+	return grid
+}
@@ -0,0 +1,91 @@
+// Package prefixsum answers range queries over static arrays and grids.
+// Sums precompute running totals so any range or region sum is O(1); Diff
+// records range updates in O(1) each and materializes the values once they
+// are read. Where a Fenwick or segment tree pays O(log n) per operation to
+// mix updates and queries freely, these pay nothing per operation as long as
+// all updates come before all queries.
+package prefixsum
+
+import "github.com/dsa-lab/go/internal/aggregate"
+
+// Sums holds the prefix sums of a 1D array.
+type Sums[T aggregate.Number] struct {
+	prefix []T // prefix[i] is the sum of the first i values
+}
+
+// New computes the prefix sums of xs in O(n).
+func New[T aggregate.Number](xs []T) *Sums[T] {
+	prefix := make([]T, len(xs)+1)
+	for i, x := range xs {
+		prefix[i+1] = prefix[i] + x
+	}
+	return &Sums[T]{prefix: prefix}
+}
+
+// Len returns the length of the array.
+func (s *Sums[T]) Len() int {
+	return len(s.prefix) - 1
+}
+
+// Sum returns the sum of the values in [i, j). It panics if the range is out
+// of bounds.
+func (s *Sums[T]) Sum(i, j int) T {
+	if i > j {
+		panic("prefixsum: range start after end")
+	}
+	return s.prefix[j] - s.prefix[i]
+}
+
+// Diff is a difference array: an array of n values, initially zero, that
+// takes range additions in O(1). Reading a value materializes every value in
+// O(n), which later reads reuse until the next addition.
+type Diff[T aggregate.Number] struct {
+	delta  []T // delta[i] is values[i] - values[i-1]
+	values []T
+	dirty  bool
+}
+
+// NewDiff creates a difference array of n zeros.
+func NewDiff[T aggregate.Number](n int) *Diff[T] {
+	return &Diff[T]{delta: make([]T, n+1), values: make([]T, n)}
+}
+
+// Len returns the number of values.
+func (d *Diff[T]) Len() int {
+	return len(d.values)
+}
+
+// AddRange adds v to every value in [i, j).
+func (d *Diff[T]) AddRange(i, j int, v T) {
+	if i > j {
+		panic("prefixsum: range start after end")
+	}
+	d.delta[i] += v
+	d.delta[j] -= v
+	d.dirty = true
+}
+
+func (d *Diff[T]) materialize() {
+	if !d.dirty {
+		return
+	}
+	var running T
+	for i := range d.values {
+		running += d.delta[i]
+		d.values[i] = running
+	}
+	d.dirty = false
+}
+
+// At returns the value at index i.
+func (d *Diff[T]) At(i int) T {
+	d.materialize()
+	return d.values[i]
+}
+
+// Values returns all values. The slice is shared with d and is valid until
+// the next AddRange.
+func (d *Diff[T]) Values() []T {
+	d.materialize()
+	return d.values
+}
@@ -0,0 +1,123 @@
+package prefixsum
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestSumsAgainstBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	xs := make([]int, 200)
+	for i := range xs {
+		xs[i] = rng.Intn(201) - 100
+	}
+	s := New(xs)
+	if s.Len() != len(xs) {
+		t.Errorf("expected length %d, got %d", len(xs), s.Len())
+	}
+	for q := 0; q < 2000; q++ {
+		i := rng.Intn(len(xs) + 1)
+		j := i + rng.Intn(len(xs)-i+1)
+		want := 0
+		for _, x := range xs[i:j] {
+			want += x
+		}
+		if got := s.Sum(i, j); got != want {
+			t.Fatalf("Sum(%d, %d): expected %d, got %d", i, j, want, got)
+		}
+	}
+}
+
+func TestDiffAgainstBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	const n = 100
+	d := NewDiff[int64](n)
+	want := make([]int64, n)
+	for q := 0; q < 2000; q++ {
+		i := rng.Intn(n + 1)
+		j := i + rng.Intn(n-i+1)
+		v := int64(rng.Intn(21) - 10)
+		d.AddRange(i, j, v)
+		for k := i; k < j; k++ {
+			want[k] += v
+		}
+		// Interleave reads so materialization is exercised repeatedly.
+		if q%50 == 0 {
+			k := rng.Intn(n)
+			if got := d.At(k); got != want[k] {
+				t.Fatalf("At(%d): expected %d, got %d", k, want[k], got)
+			}
+		}
+	}
+	for k, v := range d.Values() {
+		if v != want[k] {
+			t.Fatalf("value %d: expected %d, got %d", k, want[k], v)
+		}
+	}
+}
+
+func TestSums2DAgainstBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	const rows, cols = 30, 40
+	grid := make([][]float64, rows)
+	for r := range grid {
+		grid[r] = make([]float64, cols)
+		for c := range grid[r] {
+			grid[r][c] = float64(rng.Intn(10))
+		}
+	}
+	s := New2D(grid)
+	if r, c := s.Dims(); r != rows || c != cols {
+		t.Errorf("expected %dx%d, got %dx%d", rows, cols, r, c)
+	}
+	for q := 0; q < 1000; q++ {
+		r0, c0 := rng.Intn(rows+1), rng.Intn(cols+1)
+		r1, c1 := r0+rng.Intn(rows-r0+1), c0+rng.Intn(cols-c0+1)
+		want := 0.0
+		for r := r0; r < r1; r++ {
+			for c := c0; c < c1; c++ {
+				want += grid[r][c]
+			}
+		}
+		if got := s.Sum(r0, c0, r1, c1); got != want {
+			t.Fatalf("Sum(%d, %d, %d, %d): expected %v, got %v", r0, c0, r1, c1, want, got)
+		}
+	}
+	if New2D[int](nil).Sum(0, 0, 0, 0) != 0 {
+		t.Error("expected an empty grid to sum to zero")
+	}
+}
+
+func TestDiff2DAgainstBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(4))
+	const rows, cols = 20, 25
+	d := NewDiff2D[int](rows, cols)
+	want := make([][]int, rows)
+	for r := range want {
+		want[r] = make([]int, cols)
+	}
+	for q := 0; q < 500; q++ {
+		r0, c0 := rng.Intn(rows+1), rng.Intn(cols+1)
+		r1, c1 := r0+rng.Intn(rows-r0+1), c0+rng.Intn(cols-c0+1)
+		v := rng.Intn(11) - 5
+		d.AddRegion(r0, c0, r1, c1, v)
+		for r := r0; r < r1; r++ {
+			for c := c0; c < c1; c++ {
+				want[r][c] += v
+			}
+		}
+		if q%25 == 0 {
+			r, c := rng.Intn(rows), rng.Intn(cols)
+			if got := d.At(r, c); got != want[r][c] {
+				t.Fatalf("At(%d, %d): expected %d, got %d", r, c, want[r][c], got)
+			}
+		}
+	}
+	for r, row := range d.Grid() {
+		for c, v := range row {
+			if v != want[r][c] {
+				t.Fatalf("cell (%d, %d): expected %d, got %d", r, c, want[r][c], v)
+			}
+		}
+	}
+}
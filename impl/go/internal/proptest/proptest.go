@@ -0,0 +1,226 @@
+// Package proptest provides property-based testing for Map implementations.
+// Random operation sequences are generated with testing/quick, replayed against
+// a model (Go's builtin map), and any failing sequence is shrunk to a minimal
+// reproduction before being reported. It complements the fixed-seed oracle
+// tests by exploring many more interleavings.
+package proptest
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"github.com/dsa-lab/go/internal/kv"
+	"github.com/dsa-lab/go/internal/sampling"
+)
+
+// Kind identifies a Map operation.
+type Kind uint8
+
+const (
+	Insert Kind = iota
+	Get
+	Remove
+	Contains
+	Clear
+)
+
+var kindNames = [...]string{"Insert", "Get", "Remove", "Contains", "Clear"}
+
+func (k Kind) String() string {
+	if int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return fmt.Sprintf("Kind(%d)", k)
+}
+
+// Op is a single generated operation.
+type Op struct {
+	Kind  Kind
+	Key   string
+	Value string
+}
+
+func (op Op) String() string {
+	switch op.Kind {
+	case Insert:
+		return fmt.Sprintf("Insert(%q, %q)", op.Key, op.Value)
+	case Clear:
+		return "Clear()"
+	default:
+		return fmt.Sprintf("%s(%q)", op.Kind, op.Key)
+	}
+}
+
+// kindMix is the relative frequency of each Kind in generated sequences:
+// mostly inserts and reads, enough removals to create tombstones, and an
+// occasional Clear.
+var kindMix = mustAlias([]float64{
+	Insert:   45,
+	Get:      25,
+	Remove:   20,
+	Contains: 9,
+	Clear:    1,
+})
+
+func mustAlias(weights []float64) *sampling.Alias {
+	a, err := sampling.NewAlias(weights)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+// Ops is an operation sequence. It implements quick.Generator so it can be
+// used directly as a property argument.
+type Ops []Op
+
+// Generate produces a random sequence of up to 4*size operations over a key
+// space of roughly size keys, so overwrites, removals of live keys and
+// tombstone reuse all occur frequently.
+func (Ops) Generate(rng *rand.Rand, size int) reflect.Value {
+	if size < 1 {
+		size = 1
+	}
+	keySpace := size/2 + 1
+	n := rng.Intn(4*size + 1)
+
+	ops := make(Ops, n)
+	for i := range ops {
+		op := Op{Kind: Kind(kindMix.Sample(rng)), Key: fmt.Sprintf("k%d", rng.Intn(keySpace))}
+		if op.Kind == Insert {
+			op.Value = fmt.Sprintf("v%d", rng.Intn(1000))
+		}
+		ops[i] = op
+	}
+	return reflect.ValueOf(ops)
+}
+
+func (ops Ops) String() string {
+	var b strings.Builder
+	for i, op := range ops {
+		fmt.Fprintf(&b, "  %d: %s\n", i, op)
+	}
+	return b.String()
+}
+
+// invariantChecker is implemented by structures that can validate their
+// internal layout.
+type invariantChecker interface {
+	CheckInvariants() error
+}
+
+// Run applies ops to m and to a model map, returning an error describing the
+// first observable divergence. If m provides CheckInvariants, it is verified
+// after every operation.
+func Run(m kv.Map, ops Ops) error {
+	model := make(map[string]string)
+	checker, _ := m.(invariantChecker)
+
+	for i, op := range ops {
+		switch op.Kind {
+		case Insert:
+			got, gotExisted := m.Insert(op.Key, op.Value)
+			want, wantExisted := model[op.Key]
+			model[op.Key] = op.Value
+			if got != want || gotExisted != wantExisted {
+				return fmt.Errorf("op %d %s: got (%q, %v), want (%q, %v)", i, op, got, gotExisted, want, wantExisted)
+			}
+		case Get:
+			got, gotFound := m.Get(op.Key)
+			want, wantFound := model[op.Key]
+			if got != want || gotFound != wantFound {
+				return fmt.Errorf("op %d %s: got (%q, %v), want (%q, %v)", i, op, got, gotFound, want, wantFound)
+			}
+		case Remove:
+			got, gotExisted := m.Remove(op.Key)
+			want, wantExisted := model[op.Key]
+			delete(model, op.Key)
+			if got != want || gotExisted != wantExisted {
+				return fmt.Errorf("op %d %s: got (%q, %v), want (%q, %v)", i, op, got, gotExisted, want, wantExisted)
+			}
+		case Contains:
+			_, want := model[op.Key]
+			if got := m.Contains(op.Key); got != want {
+				return fmt.Errorf("op %d %s: got %v, want %v", i, op, got, want)
+			}
+		case Clear:
+			m.Clear()
+			model = make(map[string]string)
+		}
+
+		if m.Len() != len(model) {
+			return fmt.Errorf("op %d %s: Len() = %d, want %d", i, op, m.Len(), len(model))
+		}
+		if checker != nil {
+			if err := checker.CheckInvariants(); err != nil {
+				return fmt.Errorf("op %d %s: invariant violated: %w", i, op, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Shrink reduces a failing sequence by repeatedly deleting chunks of
+// operations (halving the chunk size down to single operations) while fails
+// keeps reporting true. Single operations are retried until a pass deletes
+// none, since a deletion can let an operation kept earlier go too. The result
+// is 1-minimal: removing any single remaining operation makes the failure
+// disappear.
+func Shrink(ops Ops, fails func(Ops) bool) Ops {
+	for chunk := len(ops) / 2; chunk > 1; chunk /= 2 {
+		ops, _ = deleteChunks(ops, chunk, fails)
+	}
+	for deleted := true; deleted; {
+		ops, deleted = deleteChunks(ops, 1, fails)
+	}
+	return ops
+}
+
+// deleteChunks makes one pass over ops, deleting each run of chunk
+// operations whose removal keeps the sequence failing. It reports whether
+// anything was deleted.
+func deleteChunks(ops Ops, chunk int, fails func(Ops) bool) (Ops, bool) {
+	deleted := false
+	for start := 0; start+chunk <= len(ops); {
+		candidate := make(Ops, 0, len(ops)-chunk)
+		candidate = append(candidate, ops[:start]...)
+		candidate = append(candidate, ops[start+chunk:]...)
+		if fails(candidate) {
+			ops, deleted = candidate, true
+			continue
+		}
+		start += chunk
+	}
+	return ops, deleted
+}
+
+// CheckMap runs the model-equivalence property against maps built by newMap.
+// On failure it reports the shrunk operation sequence. A nil config uses
+// testing/quick defaults.
+func CheckMap(t testing.TB, newMap func() kv.Map, config *quick.Config) {
+	t.Helper()
+
+	property := func(ops Ops) bool {
+		return Run(newMap(), ops) == nil
+	}
+
+	err := quick.Check(property, config)
+	if err == nil {
+		return
+	}
+
+	checkErr, ok := err.(*quick.CheckError)
+	if !ok {
+		t.Fatal(err)
+	}
+	ops := checkErr.In[0].(Ops)
+	minimal := Shrink(ops, func(candidate Ops) bool {
+		return Run(newMap(), candidate) != nil
+	})
+	t.Fatalf("property failed after %d checks; shrunk from %d to %d ops: %v\n%s",
+		checkErr.Count, len(ops), len(minimal), Run(newMap(), minimal), minimal)
+}
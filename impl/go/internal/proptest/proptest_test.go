@@ -0,0 +1,83 @@
+package proptest
+
+import (
+	"testing"
+
+	"github.com/dsa-lab/go/internal/kv"
+)
+
+// forgetfulMap drops removals of one specific key, a bug only visible after
+// that key is inserted, removed and read back.
+type forgetfulMap struct {
+	*kv.Builtin
+}
+
+func (m forgetfulMap) Remove(key string) (string, bool) {
+	if key == "k1" {
+		value, found := m.Get(key)
+		return value, found
+	}
+	return m.Builtin.Remove(key)
+}
+
+func TestRunDetectsDivergence(t *testing.T) {
+	ops := Ops{
+		{Kind: Insert, Key: "k1", Value: "a"},
+		{Kind: Remove, Key: "k1"},
+		{Kind: Get, Key: "k1"},
+	}
+	if err := Run(kv.NewBuiltin(), ops); err != nil {
+		t.Fatalf("builtin map should match the model: %v", err)
+	}
+	if err := Run(forgetfulMap{kv.NewBuiltin()}, ops); err == nil {
+		t.Fatal("expected divergence from forgetful map")
+	}
+}
+
+func TestShrinkFindsMinimalSequence(t *testing.T) {
+	var ops Ops
+	for i := 0; i < 50; i++ {
+		ops = append(ops, Op{Kind: Insert, Key: "k0", Value: "x"}, Op{Kind: Get, Key: "k2"})
+	}
+	ops = append(ops, Op{Kind: Insert, Key: "k1", Value: "a"})
+	for i := 0; i < 50; i++ {
+		ops = append(ops, Op{Kind: Contains, Key: "k3"})
+	}
+	ops = append(ops, Op{Kind: Remove, Key: "k1"}, Op{Kind: Get, Key: "k0"})
+
+	fails := func(candidate Ops) bool {
+		return Run(forgetfulMap{kv.NewBuiltin()}, candidate) != nil
+	}
+	if !fails(ops) {
+		t.Fatal("expected original sequence to fail")
+	}
+
+	minimal := Shrink(ops, fails)
+	if len(minimal) != 2 {
+		t.Fatalf("expected 2 ops after shrinking, got %d:\n%s", len(minimal), minimal)
+	}
+	if minimal[0].Kind != Insert || minimal[1].Kind != Remove {
+		t.Errorf("unexpected minimal sequence:\n%s", minimal)
+	}
+}
+
+func TestShrinkRetriesSingleOps(t *testing.T) {
+	// b can only go once c has, which a single left-to-right pass misses.
+	ops := Ops{{Kind: Get, Key: "a"}, {Kind: Get, Key: "b"}, {Kind: Get, Key: "c"}}
+	has := func(candidate Ops, key string) bool {
+		for _, op := range candidate {
+			if op.Key == key {
+				return true
+			}
+		}
+		return false
+	}
+	fails := func(candidate Ops) bool {
+		return has(candidate, "a") && !(has(candidate, "c") && !has(candidate, "b"))
+	}
+
+	minimal := Shrink(ops, fails)
+	if len(minimal) != 1 || minimal[0].Key != "a" {
+		t.Errorf("expected only the Get of a, got:\n%s", minimal)
+	}
+}
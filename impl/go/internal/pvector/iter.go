@@ -0,0 +1,19 @@
+//go:build go1.23
+
+package pvector
+
+import "iter"
+
+// All returns an iterator over the indexes and elements in order.
+func (v Vector[T]) All() iter.Seq2[int, T] {
+	return v.Range
+}
+
+// Values returns an iterator over the elements in order.
+func (v Vector[T]) Values() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		v.Range(func(_ int, x T) bool {
+			return yield(x)
+		})
+	}
+}
@@ -0,0 +1,220 @@
+// Package pvector provides an immutable vector with structural sharing, the
+// bit-partitioned trie popularised by Clojure. Every update returns a new
+// Vector and leaves the original untouched, yet copies only the O(log32 n)
+// nodes on the path to the changed element; everything else is shared
+// between versions.
+package pvector
+
+import "fmt"
+
+const (
+	bits  = 5
+	width = 1 << bits
+	mask  = width - 1
+)
+
+type node[T any] struct {
+	children []*node[T] // internal nodes
+	values   []T        // leaves, always full
+}
+
+// Vector is an immutable sequence. The zero value is an empty vector ready
+// to use.
+//
+// Elements live in a trie of 32-way nodes whose leaves hold 32 elements
+// each; the last, partially filled leaf is kept separately as the tail, so
+// PushBack only touches the trie once every 32 appends. Get, Set and
+// PushBack run in O(log32 n), which is at most 7 levels for any realistic
+// size. Slice is O(1): the result is a window onto the same trie.
+type Vector[T any] struct {
+	size       int // elements stored in root and tail
+	shift      uint
+	root       *node[T]
+	tail       []T
+	start, end int // visible window [start, end) of the stored elements
+}
+
+// New returns an empty vector.
+func New[T any]() Vector[T] {
+	return Vector[T]{}
+}
+
+// Of returns a vector holding xs.
+func Of[T any](xs ...T) Vector[T] {
+	var v Vector[T]
+	for _, x := range xs {
+		v = v.PushBack(x)
+	}
+	return v
+}
+
+// Len returns the number of elements.
+func (v Vector[T]) Len() int {
+	return v.end - v.start
+}
+
+// tailOffset is the index of the first element held in the tail.
+func (v Vector[T]) tailOffset() int {
+	if v.size < width {
+		return 0
+	}
+	return ((v.size - 1) >> bits) << bits
+}
+
+// leaf returns the array holding stored element i.
+func (v Vector[T]) leaf(i int) []T {
+	if i >= v.tailOffset() {
+		return v.tail
+	}
+	n := v.root
+	for level := v.shift; level > 0; level -= bits {
+		n = n.children[(i>>level)&mask]
+	}
+	return n.values
+}
+
+func (v Vector[T]) check(i int) {
+	if i < 0 || i >= v.Len() {
+		panic(fmt.Sprintf("pvector: index %d out of range [0, %d)", i, v.Len()))
+	}
+}
+
+// Get returns the element at index i. It panics if i is out of range.
+func (v Vector[T]) Get(i int) T {
+	v.check(i)
+	i += v.start
+	return v.leaf(i)[i&mask]
+}
+
+// Set returns a copy of v with the element at index i replaced by x. It
+// panics if i is out of range.
+func (v Vector[T]) Set(i int, x T) Vector[T] {
+	v.check(i)
+	return v.set(v.start+i, x)
+}
+
+func (v Vector[T]) set(i int, x T) Vector[T] {
+	if i >= v.tailOffset() {
+		tail := append([]T(nil), v.tail...)
+		tail[i&mask] = x
+		v.tail = tail
+		return v
+	}
+	v.root = setPath(v.root, v.shift, i, x)
+	return v
+}
+
+func setPath[T any](n *node[T], level uint, i int, x T) *node[T] {
+	if level == 0 {
+		values := append([]T(nil), n.values...)
+		values[i&mask] = x
+		return &node[T]{values: values}
+	}
+	children := append([]*node[T](nil), n.children...)
+	sub := (i >> level) & mask
+	children[sub] = setPath(children[sub], level-bits, i, x)
+	return &node[T]{children: children}
+}
+
+// PushBack returns a copy of v with x appended.
+func (v Vector[T]) PushBack(x T) Vector[T] {
+	if v.end < v.size {
+		// A slice hides stored elements past its end; overwrite the next
+		// one rather than growing the trie.
+		v = v.set(v.end, x)
+		v.end++
+		return v
+	}
+
+	if v.size-v.tailOffset() < width {
+		tail := make([]T, len(v.tail), len(v.tail)+1)
+		copy(tail, v.tail)
+		v.tail = append(tail, x)
+		v.size++
+		v.end++
+		return v
+	}
+
+	// The tail is full: move it into the trie and start a new one.
+	full := &node[T]{values: v.tail}
+	switch {
+	case v.root == nil:
+		v.root = &node[T]{children: []*node[T]{full}}
+		v.shift = bits
+	case v.size>>bits > 1<<v.shift:
+		// The trie is full at this height; grow a new root.
+		v.root = &node[T]{children: []*node[T]{v.root, newPath(v.shift, full)}}
+		v.shift += bits
+	default:
+		v.root = pushTail(v.root, v.shift, v.size, full)
+	}
+	v.tail = []T{x}
+	v.size++
+	v.end++
+	return v
+}
+
+// pushTail returns a copy of n with the full leaf appended at the position of
+// stored index size-1.
+func pushTail[T any](n *node[T], level uint, size int, leaf *node[T]) *node[T] {
+	sub := ((size - 1) >> level) & mask
+	children := make([]*node[T], len(n.children), len(n.children)+1)
+	copy(children, n.children)
+	var child *node[T]
+	switch {
+	case level == bits:
+		child = leaf
+	case sub < len(n.children):
+		child = pushTail(n.children[sub], level-bits, size, leaf)
+	default:
+		child = newPath(level-bits, leaf)
+	}
+	if sub < len(children) {
+		children[sub] = child
+	} else {
+		children = append(children, child)
+	}
+	return &node[T]{children: children}
+}
+
+// newPath wraps leaf in single-child nodes up to the given level.
+func newPath[T any](level uint, leaf *node[T]) *node[T] {
+	if level == 0 {
+		return leaf
+	}
+	return &node[T]{children: []*node[T]{newPath(level-bits, leaf)}}
+}
+
+// Slice returns the elements [lo, hi) of v, sharing storage with v. It panics
+// if the bounds are out of range. Like a Go sub-slice, the result keeps all of
+// v's storage reachable.
+func (v Vector[T]) Slice(lo, hi int) Vector[T] {
+	if lo < 0 || hi < lo || hi > v.Len() {
+		panic(fmt.Sprintf("pvector: slice bounds [%d:%d] out of range with length %d", lo, hi, v.Len()))
+	}
+	v.start, v.end = v.start+lo, v.start+hi
+	return v
+}
+
+// Range calls f for each element in order until f returns false.
+func (v Vector[T]) Range(f func(i int, x T) bool) {
+	for i := v.start; i < v.end; {
+		leaf := v.leaf(i)
+		for j := i & mask; j < len(leaf) && i < v.end; j++ {
+			if !f(i-v.start, leaf[j]) {
+				return
+			}
+			i++
+		}
+	}
+}
+
+// ToSlice returns the elements as a new slice.
+func (v Vector[T]) ToSlice() []T {
+	out := make([]T, 0, v.Len())
+	v.Range(func(_ int, x T) bool {
+		out = append(out, x)
+		return true
+	})
+	return out
+}
@@ -0,0 +1,118 @@
+package pvector
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+type version struct {
+	v     Vector[int]
+	model []int
+}
+
+func checkVersion(t *testing.T, step int, ver version) {
+	t.Helper()
+	if ver.v.Len() != len(ver.model) {
+		t.Fatalf("step %d: expected length %d, got %d", step, len(ver.model), ver.v.Len())
+	}
+	for i, want := range ver.model {
+		if got := ver.v.Get(i); got != want {
+			t.Fatalf("step %d: Get(%d) = %d, expected %d", step, i, got, want)
+		}
+	}
+	if got := ver.v.ToSlice(); !slices.Equal(got, ver.model) {
+		t.Fatalf("step %d: ToSlice mismatch", step)
+	}
+}
+
+func TestAgainstSliceModel(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	cur := version{}
+	var history []version
+
+	for step := 0; step < 20000; step++ {
+		switch r := rng.Intn(100); {
+		case r < 70 || cur.v.Len() == 0:
+			cur = version{cur.v.PushBack(step), append(slices.Clip(cur.model), step)}
+		case r < 95:
+			i := rng.Intn(cur.v.Len())
+			model := slices.Clone(cur.model)
+			model[i] = -step
+			cur = version{cur.v.Set(i, -step), model}
+		default:
+			lo := rng.Intn(cur.v.Len() + 1)
+			hi := lo + rng.Intn(cur.v.Len()-lo+1)
+			cur = version{cur.v.Slice(lo, hi), slices.Clip(cur.model[lo:hi])}
+		}
+		if step%100 == 0 {
+			history = append(history, cur)
+		}
+	}
+	checkVersion(t, -1, cur)
+	// Every earlier version must be unaffected by later updates.
+	for i, ver := range history {
+		checkVersion(t, i*100, ver)
+	}
+}
+
+func TestDeepTrie(t *testing.T) {
+	// 32^3 + some elements forces two root splits.
+	const n = 40000
+	v := New[int]()
+	for i := 0; i < n; i++ {
+		v = v.PushBack(i)
+	}
+	if v.shift != 3*bits {
+		t.Errorf("expected shift %d, got %d", 3*bits, v.shift)
+	}
+	for i := 0; i < n; i += 997 {
+		if got := v.Get(i); got != i {
+			t.Fatalf("Get(%d) = %d", i, got)
+		}
+	}
+	w := v.Set(12345, -1)
+	if v.Get(12345) != 12345 || w.Get(12345) != -1 {
+		t.Errorf("expected Set to leave the original untouched")
+	}
+}
+
+func TestSliceThenPushBack(t *testing.T) {
+	v := Of(0, 1, 2, 3, 4)
+	s := v.Slice(1, 3).PushBack(9)
+	if got := s.ToSlice(); !slices.Equal(got, []int{1, 2, 9}) {
+		t.Errorf("expected [1 2 9], got %v", got)
+	}
+	if got := v.ToSlice(); !slices.Equal(got, []int{0, 1, 2, 3, 4}) {
+		t.Errorf("expected original unchanged, got %v", got)
+	}
+}
+
+func TestRangeEarlyStop(t *testing.T) {
+	v := Of(1, 2, 3, 4)
+	var seen []int
+	v.Range(func(i, x int) bool {
+		seen = append(seen, x)
+		return i < 1
+	})
+	if !slices.Equal(seen, []int{1, 2}) {
+		t.Errorf("expected [1 2], got %v", seen)
+	}
+}
+
+func TestOutOfRangePanics(t *testing.T) {
+	for name, f := range map[string]func(){
+		"get":   func() { Of(1).Get(1) },
+		"set":   func() { Of(1).Set(-1, 0) },
+		"slice": func() { Of(1).Slice(1, 0) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: expected panic", name)
+				}
+			}()
+			f()
+		}()
+	}
+}
@@ -0,0 +1,113 @@
+package queue
+
+// Deque is a double-ended queue in a growable ring buffer. Both ends push
+// and pop in amortized O(1), and At indexes from the front in O(1), which
+// the two-stack queue cannot offer. The buffer doubles when full and never
+// shrinks. It is not safe for concurrent use.
+type Deque[T any] struct {
+	buf  []T // len(buf) is zero or a power of two
+	head int // index of the front element
+	n    int
+}
+
+// NewDeque creates an empty deque.
+func NewDeque[T any]() *Deque[T] {
+	return &Deque[T]{}
+}
+
+// Len returns the number of elements.
+func (d *Deque[T]) Len() int {
+	return d.n
+}
+
+func (d *Deque[T]) grow() {
+	if d.n < len(d.buf) {
+		return
+	}
+	buf := make([]T, max(8, 2*len(d.buf)))
+	for i := 0; i < d.n; i++ {
+		buf[i] = d.buf[(d.head+i)&(len(d.buf)-1)]
+	}
+	d.buf, d.head = buf, 0
+}
+
+// PushBack appends x at the back.
+func (d *Deque[T]) PushBack(x T) {
+	d.grow()
+	d.buf[(d.head+d.n)&(len(d.buf)-1)] = x
+	d.n++
+}
+
+// PushFront prepends x at the front.
+func (d *Deque[T]) PushFront(x T) {
+	d.grow()
+	d.head = (d.head - 1) & (len(d.buf) - 1)
+	d.buf[d.head] = x
+	d.n++
+}
+
+// PopFront removes and returns the front element, or false if the deque is
+// empty.
+func (d *Deque[T]) PopFront() (T, bool) {
+	var zero T
+	if d.n == 0 {
+		return zero, false
+	}
+	x := d.buf[d.head]
+	d.buf[d.head] = zero
+	d.head = (d.head + 1) & (len(d.buf) - 1)
+	d.n--
+	return x, true
+}
+
+// PopBack removes and returns the back element, or false if the deque is
+// empty.
+func (d *Deque[T]) PopBack() (T, bool) {
+	var zero T
+	if d.n == 0 {
+		return zero, false
+	}
+	d.n--
+	i := (d.head + d.n) & (len(d.buf) - 1)
+	x := d.buf[i]
+	d.buf[i] = zero
+	return x, true
+}
+
+// Front returns the front element without removing it.
+func (d *Deque[T]) Front() (T, bool) {
+	return d.At(0)
+}
+
+// Back returns the back element without removing it.
+func (d *Deque[T]) Back() (T, bool) {
+	return d.At(d.n - 1)
+}
+
+// At returns the element i positions from the front, or false if i is out of
+// range.
+func (d *Deque[T]) At(i int) (T, bool) {
+	if i < 0 || i >= d.n {
+		var zero T
+		return zero, false
+	}
+	return d.buf[(d.head+i)&(len(d.buf)-1)], true
+}
+
+// Push appends x at the back, so a Deque satisfies Queue. It always
+// succeeds.
+func (d *Deque[T]) Push(x T) bool {
+	d.PushBack(x)
+	return true
+}
+
+// Pop removes the front element, so a Deque satisfies Queue.
+func (d *Deque[T]) Pop() (T, bool) {
+	return d.PopFront()
+}
+
+// Clear removes all elements, keeping the buffer.
+func (d *Deque[T]) Clear() {
+	clear(d.buf)
+	d.head, d.n = 0, 0
+}
@@ -0,0 +1,33 @@
+//go:build go1.23
+
+package queue
+
+import "iter"
+
+// All returns an iterator over the elements from front to back.
+func (d *Deque[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i := 0; i < d.n; i++ {
+			if !yield(d.buf[(d.head+i)&(len(d.buf)-1)]) {
+				return
+			}
+		}
+	}
+}
+
+// All returns an iterator over the elements from oldest to newest, without
+// removing them.
+func (q *TwoStack[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i := len(q.front) - 1; i >= 0; i-- {
+			if !yield(q.front[i]) {
+				return
+			}
+		}
+		for _, x := range q.back {
+			if !yield(x) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package queue
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/dsa-lab/go/internal/errs"
+)
+
+type cell[T any] struct {
+	// seq tells producers and the consumer whose turn the cell is: it equals
+	// the enqueue position when the cell is free for that position, and
+	// position+1 once a value has been published there.
+	seq   atomic.Uint64
+	value T
+}
+
+// MPSC is a bounded lock-free queue for any number of producers and a single
+// consumer, after Dmitry Vyukov's bounded queue. Producers claim a slot by
+// advancing the enqueue position with a compare-and-swap, write the value and
+// then publish it through the slot's sequence number; the consumer waits for
+// that sequence number before reading. No locks are taken and a stalled
+// producer delays only the consumer, never other producers.
+//
+// Push may be called from any goroutine. Pop must only be called from one
+// goroutine at a time.
+type MPSC[T any] struct {
+	cells   []cell[T]
+	mask    uint64
+	_       [56]byte // keep the producer and consumer positions on separate cache lines
+	enqueue atomic.Uint64
+	_       [56]byte
+	dequeue atomic.Uint64
+}
+
+// NewMPSC creates a queue holding up to capacity elements, rounded up to a
+// power of two. It returns an error matching errs.ErrInvalidOption if
+// capacity is below 2: with a single cell, the sequence number of a
+// published value would equal the next enqueue position, and producers would
+// overwrite it.
+func NewMPSC[T any](capacity int) (*MPSC[T], error) {
+	if capacity < 2 {
+		return nil, fmt.Errorf("queue: MPSC capacity %d: %w", capacity, errs.ErrInvalidOption)
+	}
+	size := 2
+	for size < capacity {
+		size <<= 1
+	}
+	q := &MPSC[T]{cells: make([]cell[T], size), mask: uint64(size - 1)}
+	for i := range q.cells {
+		q.cells[i].seq.Store(uint64(i))
+	}
+	return q, nil
+}
+
+// MustNewMPSC is like NewMPSC but panics if capacity is below 2.
+func MustNewMPSC[T any](capacity int) *MPSC[T] {
+	return errs.Must(NewMPSC[T](capacity))
+}
+
+// Cap returns the capacity of the queue.
+func (q *MPSC[T]) Cap() int {
+	return len(q.cells)
+}
+
+// Push appends x, returning false if the queue is full.
+func (q *MPSC[T]) Push(x T) bool {
+	pos := q.enqueue.Load()
+	for {
+		c := &q.cells[pos&q.mask]
+		switch diff := int64(c.seq.Load() - pos); {
+		case diff == 0:
+			if q.enqueue.CompareAndSwap(pos, pos+1) {
+				c.value = x
+				c.seq.Store(pos + 1)
+				return true
+			}
+			pos = q.enqueue.Load()
+		case diff < 0:
+			// The cell still holds the value from one lap ago.
+			return false
+		default:
+			// Another producer claimed pos; catch up.
+			pos = q.enqueue.Load()
+		}
+	}
+}
+
+// Pop removes and returns the oldest element, or false if the queue is empty
+// or the oldest slot has been claimed but not yet published.
+func (q *MPSC[T]) Pop() (T, bool) {
+	var zero T
+	pos := q.dequeue.Load()
+	c := &q.cells[pos&q.mask]
+	if c.seq.Load() != pos+1 {
+		return zero, false
+	}
+	x := c.value
+	c.value = zero
+	c.seq.Store(pos + q.mask + 1)
+	q.dequeue.Store(pos + 1)
+	return x, true
+}
+
+// Len returns the number of claimed slots not yet consumed. Under concurrent
+// use it is only a snapshot.
+func (q *MPSC[T]) Len() int {
+	deq := q.dequeue.Load()
+	enq := q.enqueue.Load()
+	if enq < deq {
+		return 0
+	}
+	return int(enq - deq)
+}
@@ -0,0 +1,64 @@
+// Package queue provides FIFO queues behind a common interface: a two-stack
+// queue that shows how amortized O(1) FIFO order falls out of two LIFO
+// stacks, a ring-buffer deque that also pushes and pops at the front, and a
+// bounded lock-free ring for many producers feeding a single consumer.
+package queue
+
+// Queue is a first-in, first-out queue.
+type Queue[T any] interface {
+	// Push appends x, returning false if the queue is full.
+	Push(x T) bool
+	// Pop removes and returns the oldest element, or false if the queue is
+	// empty.
+	Pop() (T, bool)
+	// Len returns the number of queued elements.
+	Len() int
+}
+
+// TwoStack is an unbounded queue built from two stacks, the imperative form
+// of the classic functional queue. Pushes go onto the back stack; pops come
+// off the front stack, and when the front runs dry the back stack is reversed
+// into it. Each element is moved at most once, so every operation is O(1)
+// amortized even though a single Pop may take O(n). It is not safe for
+// concurrent use.
+type TwoStack[T any] struct {
+	front []T // next element to pop is last
+	back  []T // most recently pushed element is last
+}
+
+// NewTwoStack creates an empty two-stack queue.
+func NewTwoStack[T any]() *TwoStack[T] {
+	return &TwoStack[T]{}
+}
+
+// Push appends x. It always succeeds.
+func (q *TwoStack[T]) Push(x T) bool {
+	q.back = append(q.back, x)
+	return true
+}
+
+// Pop removes and returns the oldest element.
+func (q *TwoStack[T]) Pop() (T, bool) {
+	var zero T
+	if len(q.front) == 0 {
+		if len(q.back) == 0 {
+			return zero, false
+		}
+		// Reverse back onto front, reusing front's storage.
+		for i := len(q.back) - 1; i >= 0; i-- {
+			q.front = append(q.front, q.back[i])
+			q.back[i] = zero
+		}
+		q.back = q.back[:0]
+	}
+	last := len(q.front) - 1
+	x := q.front[last]
+	q.front[last] = zero
+	q.front = q.front[:last]
+	return x, true
+}
+
+// Len returns the number of queued elements.
+func (q *TwoStack[T]) Len() int {
+	return len(q.front) + len(q.back)
+}
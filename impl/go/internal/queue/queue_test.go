@@ -0,0 +1,171 @@
+package queue
+
+import (
+	"errors"
+	"math/rand"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/errs"
+)
+
+func TestQueuesAgainstModel(t *testing.T) {
+	impls := map[string]func() Queue[int]{
+		"twostack": func() Queue[int] { return NewTwoStack[int]() },
+		"deque":    func() Queue[int] { return NewDeque[int]() },
+		"mpsc":     func() Queue[int] { return MustNewMPSC[int](64) },
+	}
+	for name, newQueue := range impls {
+		rng := rand.New(rand.NewSource(1))
+		q := newQueue()
+		var model []int
+		for i := 0; i < 10000; i++ {
+			if rng.Intn(2) == 0 {
+				ok := q.Push(i)
+				if ok {
+					model = append(model, i)
+				} else if mq, bounded := q.(*MPSC[int]); !bounded || len(model) != mq.Cap() {
+					t.Fatalf("%s: Push failed with %d queued", name, len(model))
+				}
+			} else {
+				got, ok := q.Pop()
+				if ok != (len(model) > 0) {
+					t.Fatalf("%s: Pop returned %v with %d queued", name, ok, len(model))
+				}
+				if ok {
+					if got != model[0] {
+						t.Fatalf("%s: expected %d, got %d", name, model[0], got)
+					}
+					model = model[1:]
+				}
+			}
+			if q.Len() != len(model) {
+				t.Fatalf("%s: expected length %d, got %d", name, len(model), q.Len())
+			}
+		}
+	}
+}
+
+func TestDequeBothEnds(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	d := NewDeque[int]()
+	var model []int
+	for i := 0; i < 10000; i++ {
+		switch rng.Intn(4) {
+		case 0:
+			d.PushBack(i)
+			model = append(model, i)
+		case 1:
+			d.PushFront(i)
+			model = append([]int{i}, model...)
+		case 2:
+			got, ok := d.PopFront()
+			if ok != (len(model) > 0) || (ok && got != model[0]) {
+				t.Fatalf("PopFront: expected %v, got %d, %v", model[:min(1, len(model))], got, ok)
+			}
+			if ok {
+				model = model[1:]
+			}
+		case 3:
+			got, ok := d.PopBack()
+			if ok != (len(model) > 0) || (ok && got != model[len(model)-1]) {
+				t.Fatalf("PopBack: expected %v, got %d, %v", model[max(0, len(model)-1):], got, ok)
+			}
+			if ok {
+				model = model[:len(model)-1]
+			}
+		}
+		if d.Len() != len(model) {
+			t.Fatalf("expected length %d, got %d", len(model), d.Len())
+		}
+		if len(model) > 0 {
+			j := rng.Intn(len(model))
+			if x, _ := d.At(j); x != model[j] {
+				t.Fatalf("At(%d): expected %d, got %d", j, model[j], x)
+			}
+		}
+	}
+	if _, ok := d.At(d.Len()); ok {
+		t.Error("expected At past the back to fail")
+	}
+	d.Clear()
+	if _, ok := d.Front(); ok || d.Len() != 0 {
+		t.Error("expected an empty deque after Clear")
+	}
+}
+
+func TestMPSCCapacity(t *testing.T) {
+	for _, capacity := range []int{-1, 0, 1} {
+		if _, err := NewMPSC[int](capacity); !errors.Is(err, errs.ErrInvalidOption) {
+			t.Errorf("capacity %d: expected ErrInvalidOption, got %v", capacity, err)
+		}
+	}
+	q := MustNewMPSC[int](5)
+	if q.Cap() != 8 {
+		t.Fatalf("expected capacity 8, got %d", q.Cap())
+	}
+	for i := 0; i < 8; i++ {
+		if !q.Push(i) {
+			t.Fatalf("Push %d failed", i)
+		}
+	}
+	if q.Push(8) {
+		t.Errorf("expected Push into a full queue to fail")
+	}
+	if x, _ := q.Pop(); x != 0 {
+		t.Errorf("expected 0, got %d", x)
+	}
+	if !q.Push(8) {
+		t.Errorf("expected Push after Pop to succeed")
+	}
+}
+
+// TestMPSCConcurrent hammers the queue from many producers while one consumer
+// drains it. Each producer's items must arrive exactly once and in the order
+// that producer pushed them. Run with -race to check the publication protocol.
+func TestMPSCConcurrent(t *testing.T) {
+	const producers = 8
+	perProducer := 20000
+	if testing.Short() {
+		perProducer = 2000
+	}
+
+	type item struct{ producer, seq int }
+	q := MustNewMPSC[item](128)
+
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < perProducer; i++ {
+				for !q.Push(item{p, i}) {
+					runtime.Gosched()
+				}
+			}
+		}(p)
+	}
+
+	next := make([]int, producers)
+	for received := 0; received < producers*perProducer; {
+		it, ok := q.Pop()
+		if !ok {
+			runtime.Gosched()
+			continue
+		}
+		if it.seq != next[it.producer] {
+			t.Fatalf("producer %d: expected item %d, got %d", it.producer, next[it.producer], it.seq)
+		}
+		next[it.producer]++
+		received++
+	}
+	wg.Wait()
+
+	if _, ok := q.Pop(); ok {
+		t.Errorf("expected queue to be empty")
+	}
+	if q.Len() != 0 {
+		t.Errorf("expected length 0, got %d", q.Len())
+	}
+}
@@ -0,0 +1,26 @@
+//go:build go1.23
+
+package rangequery
+
+import (
+	"iter"
+	"slices"
+)
+
+// All returns an iterator over the indices and current elements of the
+// array, like slices.All.
+func (t *SegmentTree[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i, x := range t.tree[t.n:] {
+			if !yield(i, x) {
+				return
+			}
+		}
+	}
+}
+
+// All returns an iterator over the indices and elements of the array, like
+// slices.All.
+func (t *SparseTable[T]) All() iter.Seq2[int, T] {
+	return slices.All(t.levels[0])
+}
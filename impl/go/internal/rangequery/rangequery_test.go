@@ -0,0 +1,68 @@
+package rangequery
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestSparseTableAgainstScan(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, n := range []int{1, 2, 3, 17, 64, 100} {
+		xs := make([]int, n)
+		for i := range xs {
+			xs[i] = (rng.Intn(30) + 1) * 6
+		}
+		mins, gcds := NewMinTable(xs), NewGCDTable(xs)
+		for q := 0; q < 500; q++ {
+			i := rng.Intn(n)
+			j := i + 1 + rng.Intn(n-i)
+			lo, g := xs[i], 0
+			for _, x := range xs[i:j] {
+				lo, g = min(lo, x), GCD(g, x)
+			}
+			if got := mins.Query(i, j); got != lo {
+				t.Fatalf("n=%d: min of [%d, %d): expected %d, got %d", n, i, j, lo, got)
+			}
+			if got := gcds.Query(i, j); got != g {
+				t.Fatalf("n=%d: gcd of [%d, %d): expected %d, got %d", n, i, j, g, got)
+			}
+		}
+	}
+}
+
+func TestGCD(t *testing.T) {
+	cases := []struct{ a, b, want int }{{12, 18, 6}, {-4, 6, 2}, {0, 5, 5}, {0, 0, 0}, {7, 13, 1}}
+	for _, c := range cases {
+		if got := GCD(c.a, c.b); got != c.want {
+			t.Errorf("expected gcd(%d, %d) = %d, got %d", c.a, c.b, c.want, got)
+		}
+	}
+}
+
+func TestSegmentTreeAgainstScan(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	// Concatenation is associative but not commutative, so any reordering
+	// of operands shows up in the result.
+	concat := func(a, b string) string { return a + b }
+	for _, n := range []int{1, 5, 13, 32} {
+		xs := make([]string, n)
+		for i := range xs {
+			xs[i] = string(rune('a' + rng.Intn(26)))
+		}
+		tree := NewSegmentTree(xs, concat)
+		for q := 0; q < 500; q++ {
+			if rng.Intn(4) == 0 {
+				i := rng.Intn(n)
+				xs[i] = string(rune('A' + rng.Intn(26)))
+				tree.Set(i, xs[i])
+				continue
+			}
+			i := rng.Intn(n)
+			j := i + 1 + rng.Intn(n-i)
+			if got, want := tree.Query(i, j), strings.Join(xs[i:j], ""); got != want {
+				t.Fatalf("n=%d: query [%d, %d): expected %q, got %q", n, i, j, want, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package rangequery
+
+// SegmentTree keeps op over the nodes of a complete binary tree stored
+// bottom-up in an array, leaves in the second half. A query combines the
+// O(log n) nodes that tile the range, and an update recomputes the leaf's
+// ancestors. op must be associative but need not be idempotent or
+// commutative.
+type SegmentTree[T any] struct {
+	op   func(a, b T) T
+	n    int
+	tree []T // tree[n+i] is element i; tree[p] = op(tree[2p], tree[2p+1])
+}
+
+// NewSegmentTree builds a tree over xs in O(n).
+func NewSegmentTree[T any](xs []T, op func(a, b T) T) *SegmentTree[T] {
+	n := len(xs)
+	t := &SegmentTree[T]{op: op, n: n, tree: make([]T, 2*n)}
+	copy(t.tree[n:], xs)
+	for p := n - 1; p > 0; p-- {
+		t.tree[p] = op(t.tree[2*p], t.tree[2*p+1])
+	}
+	return t
+}
+
+// Len returns the length of the array.
+func (t *SegmentTree[T]) Len() int {
+	return t.n
+}
+
+// Set replaces element i with x in O(log n).
+func (t *SegmentTree[T]) Set(i int, x T) {
+	p := t.n + i
+	t.tree[p] = x
+	for p /= 2; p > 0; p /= 2 {
+		t.tree[p] = t.op(t.tree[2*p], t.tree[2*p+1])
+	}
+}
+
+// Query returns op over [i, j) in O(log n). It panics if the range is empty
+// or out of bounds.
+func (t *SegmentTree[T]) Query(i, j int) T {
+	if i >= j || i < 0 || j > t.n {
+		panic("rangequery: empty range")
+	}
+	// Nodes are folded in from both ends; keeping the two sides apart
+	// preserves operand order for non-commutative ops.
+	var left, right T
+	var hasLeft, hasRight bool
+	for l, r := i+t.n, j+t.n; l < r; l, r = l/2, r/2 {
+		if l&1 == 1 {
+			if hasLeft {
+				left = t.op(left, t.tree[l])
+			} else {
+				left, hasLeft = t.tree[l], true
+			}
+			l++
+		}
+		if r&1 == 1 {
+			r--
+			if hasRight {
+				right = t.op(t.tree[r], right)
+			} else {
+				right, hasRight = t.tree[r], true
+			}
+		}
+	}
+	switch {
+	case !hasLeft:
+		return right
+	case !hasRight:
+		return left
+	default:
+		return t.op(left, right)
+	}
+}
@@ -0,0 +1,75 @@
+// Package rangequery answers queries that combine a range of an array with an
+// associative operation, such as its minimum or greatest common divisor. A
+// SparseTable answers in O(1) after O(n log n) preprocessing but cannot
+// change; a SegmentTree answers in O(log n) and takes point updates.
+package rangequery
+
+import (
+	"cmp"
+	"math/bits"
+
+	"github.com/dsa-lab/go/internal/aggregate"
+)
+
+// SparseTable precomputes op over every range whose length is a power of
+// two. Any range is the union of two such ranges, possibly overlapping, so a
+// query is two lookups and one op. Overlap counts some elements twice, which
+// is why op must be idempotent (op(x, x) == x) as well as associative: min,
+// max, gcd, bitwise and/or qualify; sum does not.
+type SparseTable[T any] struct {
+	op     func(a, b T) T
+	levels [][]T // levels[k][i] is op over [i, i+2^k)
+}
+
+// NewSparseTable builds a table over xs in O(n log n) time and space.
+func NewSparseTable[T any](xs []T, op func(a, b T) T) *SparseTable[T] {
+	t := &SparseTable[T]{op: op, levels: [][]T{append([]T(nil), xs...)}}
+	for k := 1; 1<<k <= len(xs); k++ {
+		prev := t.levels[k-1]
+		half := 1 << (k - 1)
+		level := make([]T, len(xs)-(1<<k)+1)
+		for i := range level {
+			level[i] = op(prev[i], prev[i+half])
+		}
+		t.levels = append(t.levels, level)
+	}
+	return t
+}
+
+// NewMinTable builds a sparse table answering range minimums.
+func NewMinTable[T cmp.Ordered](xs []T) *SparseTable[T] {
+	return NewSparseTable(xs, func(a, b T) T { return min(a, b) })
+}
+
+// NewGCDTable builds a sparse table answering range greatest common
+// divisors.
+func NewGCDTable[T aggregate.Integer](xs []T) *SparseTable[T] {
+	return NewSparseTable(xs, GCD[T])
+}
+
+// Len returns the length of the array.
+func (t *SparseTable[T]) Len() int {
+	return len(t.levels[0])
+}
+
+// Query returns op over [i, j). It panics if the range is empty or out of
+// bounds.
+func (t *SparseTable[T]) Query(i, j int) T {
+	if i >= j {
+		panic("rangequery: empty range")
+	}
+	k := bits.Len(uint(j-i)) - 1
+	return t.op(t.levels[k][i], t.levels[k][j-(1<<k)])
+}
+
+// GCD returns the greatest common divisor of a and b, non-negative for
+// signed types; GCD(0, 0) is 0.
+func GCD[T aggregate.Integer](a, b T) T {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	if a < 0 {
+		return -a
+	}
+	return a
+}
@@ -0,0 +1,110 @@
+package ratelimit
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dsa-lab/go/internal/errs"
+)
+
+// bucket is the state of one key: its tokens as of last.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// TokenBucket gives each key a bucket of up to burst tokens that refills at
+// rate tokens per second. An event spends one token and is refused when the
+// bucket is empty, so a key may burst after being idle but cannot exceed the
+// rate over time. Refills are computed on access, so idle keys cost nothing
+// but their memory; Prune reclaims that.
+type TokenBucket struct {
+	mu      sync.Mutex
+	rate    float64
+	burst   float64
+	now     func() time.Time
+	buckets keyed[bucket]
+}
+
+// NewTokenBucket creates a limiter admitting rate events per second per key,
+// with bursts of up to burst events. It returns an error matching
+// errs.ErrInvalidOption unless rate is positive and burst at least 1.
+func NewTokenBucket(rate float64, burst int, opts ...Option) (*TokenBucket, error) {
+	if !(rate > 0) || burst < 1 {
+		return nil, fmt.Errorf("ratelimit: NewTokenBucket(%v, %d): %w", rate, burst, errs.ErrInvalidOption)
+	}
+	cfg := newConfig(opts)
+	return &TokenBucket{
+		rate:    rate,
+		burst:   float64(burst),
+		now:     cfg.now,
+		buckets: newKeyed[bucket](),
+	}, nil
+}
+
+// MustNewTokenBucket is like NewTokenBucket but panics if rate or burst is
+// invalid.
+func MustNewTokenBucket(rate float64, burst int, opts ...Option) *TokenBucket {
+	return errs.Must(NewTokenBucket(rate, burst, opts...))
+}
+
+// refill returns key's bucket with the tokens accrued up to now added.
+func (l *TokenBucket) refill(key string, now time.Time) *bucket {
+	b := l.buckets.lookup(key, bucket{tokens: l.burst, last: now})
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = min(l.burst, b.tokens+elapsed*l.rate)
+		b.last = now
+	}
+	return b
+}
+
+// Allow spends one token of key's bucket, reporting whether one was left.
+func (l *TokenBucket) Allow(key string) bool {
+	return l.AllowN(key, 1)
+}
+
+// AllowN spends n tokens at once, or none if fewer than n are left. It
+// reports false for n < 1, which would otherwise admit for free or add
+// tokens.
+func (l *TokenBucket) AllowN(key string, n int) bool {
+	if n < 1 {
+		return false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := l.refill(key, l.now())
+	if b.tokens < float64(n) {
+		return false
+	}
+	b.tokens -= float64(n)
+	return true
+}
+
+// Tokens returns the tokens currently in key's bucket.
+func (l *TokenBucket) Tokens(key string) float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, found := l.buckets.find(key); !found {
+		return l.burst
+	}
+	return l.refill(key, l.now()).tokens
+}
+
+// Prune forgets keys whose buckets have refilled completely, which behave
+// exactly like keys never seen, and returns how many it forgot.
+func (l *TokenBucket) Prune() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	return l.buckets.prune(func(b *bucket) bool {
+		return b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst
+	})
+}
+
+// Len returns the number of keys with state.
+func (l *TokenBucket) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.buckets.len()
+}
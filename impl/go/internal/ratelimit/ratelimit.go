@@ -0,0 +1,102 @@
+// Package ratelimit provides per-key rate limiters: a token bucket, which
+// allows bursts up to a fixed size and refills at a steady rate, and a
+// sliding-window log, which admits at most a fixed number of events in any
+// window of the given length. Both keep per-key state in the lab hashmap and
+// are safe for concurrent use.
+package ratelimit
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/dsa-lab/go/internal/hashmap"
+)
+
+// Limiter decides whether an event for key may proceed now.
+type Limiter interface {
+	Allow(key string) bool
+}
+
+// config holds the options shared by the limiters.
+type config struct {
+	now func() time.Time
+}
+
+// Option configures a limiter at construction time.
+type Option func(*config)
+
+// WithClock replaces time.Now, for tests and simulations.
+func WithClock(now func() time.Time) Option {
+	return func(c *config) {
+		c.now = now
+	}
+}
+
+func newConfig(opts []Option) config {
+	c := config{now: time.Now}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// keyed holds per-key state in a dense slice. The hashmap maps each key to
+// its position, as in multiset, so updating a key's state never rewrites the
+// map and pruning moves the last state into the freed position.
+type keyed[T any] struct {
+	index  *hashmap.HashMap
+	keys   []string
+	states []T
+}
+
+func newKeyed[T any]() keyed[T] {
+	return keyed[T]{index: hashmap.MustNew()}
+}
+
+// find returns the state of key, if it has one.
+func (k *keyed[T]) find(key string) (*T, bool) {
+	v, found := k.index.Get(key)
+	if !found {
+		return nil, false
+	}
+	i, _ := strconv.Atoi(v)
+	return &k.states[i], true
+}
+
+// lookup returns the state of key, appending init as its state if key is new.
+func (k *keyed[T]) lookup(key string, init T) *T {
+	if state, found := k.find(key); found {
+		return state
+	}
+	k.index.Insert(key, strconv.Itoa(len(k.keys)))
+	k.keys = append(k.keys, key)
+	k.states = append(k.states, init)
+	return &k.states[len(k.states)-1]
+}
+
+// prune drops every key whose state idle reports true and returns how many
+// it dropped.
+func (k *keyed[T]) prune(idle func(*T) bool) int {
+	dropped := 0
+	for i := len(k.keys) - 1; i >= 0; i-- {
+		if !idle(&k.states[i]) {
+			continue
+		}
+		key := k.keys[i]
+		last := len(k.keys) - 1
+		if i != last {
+			k.keys[i], k.states[i] = k.keys[last], k.states[last]
+			k.index.Insert(k.keys[i], strconv.Itoa(i))
+		}
+		var zero T
+		k.keys[last], k.states[last] = "", zero
+		k.keys, k.states = k.keys[:last], k.states[:last]
+		k.index.Remove(key)
+		dropped++
+	}
+	return dropped
+}
+
+func (k *keyed[T]) len() int {
+	return len(k.keys)
+}
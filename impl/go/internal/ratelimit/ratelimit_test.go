@@ -0,0 +1,154 @@
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dsa-lab/go/internal/errs"
+)
+
+// fakeClock is a manually advanced time source, safe for concurrent use.
+type fakeClock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{t: time.Unix(0, 0)}
+}
+
+func (c *fakeClock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(d)
+}
+
+// admitted returns how many of n attempts l allows for key.
+func admitted(l Limiter, key string, n int) int {
+	allowed := 0
+	for i := 0; i < n; i++ {
+		if l.Allow(key) {
+			allowed++
+		}
+	}
+	return allowed
+}
+
+func TestTokenBucket(t *testing.T) {
+	clock := newFakeClock()
+	l := MustNewTokenBucket(2, 5, WithClock(clock.now))
+
+	if n := admitted(l, "a", 10); n != 5 {
+		t.Errorf("expected a burst of 5, got %d", n)
+	}
+	if n := admitted(l, "b", 3); n != 3 {
+		t.Errorf("expected keys to have separate buckets, got %d for b", n)
+	}
+
+	clock.advance(1500 * time.Millisecond)
+	if tokens := l.Tokens("a"); tokens != 3 {
+		t.Errorf("expected 3 tokens after 1.5s at 2/s, got %v", tokens)
+	}
+	if l.AllowN("a", 4) || !l.AllowN("a", 3) {
+		t.Error("expected AllowN to spend all or nothing")
+	}
+	if l.AllowN("a", 0) || l.AllowN("a", -5) || l.Tokens("a") != 0 {
+		t.Errorf("expected AllowN to refuse n < 1 without adding tokens, got %v tokens", l.Tokens("a"))
+	}
+
+	clock.advance(time.Minute)
+	if n := admitted(l, "a", 10); n != 5 {
+		t.Errorf("expected the bucket to refill only up to the burst, got %d", n)
+	}
+	// b has refilled completely; a has just been drained.
+	if pruned := l.Prune(); pruned != 1 || l.Len() != 1 {
+		t.Errorf("expected b pruned and a kept, got %d pruned and %d left", pruned, l.Len())
+	}
+}
+
+func TestSlidingLog(t *testing.T) {
+	clock := newFakeClock()
+	l := MustNewSlidingLog(3, time.Second, WithClock(clock.now))
+
+	for i := 0; i < 3; i++ {
+		if !l.Allow("a") {
+			t.Fatalf("expected event %d to be admitted", i)
+		}
+		clock.advance(300 * time.Millisecond)
+	}
+	// Events at 0, 300 and 600ms; it is now 900ms.
+	if l.Allow("a") {
+		t.Error("expected a fourth event within the window to be refused")
+	}
+	if d := l.RetryAfter("a"); d != 100*time.Millisecond {
+		t.Errorf("expected to retry in 100ms, got %v", d)
+	}
+	clock.advance(100 * time.Millisecond)
+	if !l.Allow("a") || l.Count("a") != 3 {
+		t.Errorf("expected the first event to leave the window, got count %d", l.Count("a"))
+	}
+	if l.Count("unseen") != 0 || l.Len() != 1 {
+		t.Errorf("expected queries not to create state, got %d keys", l.Len())
+	}
+
+	clock.advance(time.Second)
+	if pruned := l.Prune(); pruned != 1 || l.Len() != 0 {
+		t.Errorf("expected the idle key pruned, got %d pruned and %d left", pruned, l.Len())
+	}
+}
+
+func TestConcurrentLimits(t *testing.T) {
+	clock := newFakeClock()
+	limiters := map[string]Limiter{
+		"token-bucket": MustNewTokenBucket(1, 100, WithClock(clock.now)),
+		"sliding-log":  MustNewSlidingLog(100, time.Hour, WithClock(clock.now)),
+	}
+	for name, l := range limiters {
+		var allowed [4]atomic.Int64
+		var wg sync.WaitGroup
+		for g := 0; g < 16; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				for i := 0; i < 200; i++ {
+					key := fmt.Sprint("k", (g+i)%len(allowed))
+					if l.Allow(key) {
+						allowed[(g+i)%len(allowed)].Add(1)
+					}
+				}
+			}(g)
+		}
+		wg.Wait()
+		// The clock is frozen, so each key admits exactly its limit of 100
+		// out of 800 attempts however the goroutines interleave.
+		for key := range allowed {
+			if n := allowed[key].Load(); n != 100 {
+				t.Errorf("%s: expected 100 events admitted for k%d, got %d", name, key, n)
+			}
+		}
+	}
+}
+
+func TestInvalidArguments(t *testing.T) {
+	bad := map[string]error{}
+	_, bad["NewTokenBucket(0, 5)"] = NewTokenBucket(0, 5)
+	_, bad["NewTokenBucket(-1, 5)"] = NewTokenBucket(-1, 5)
+	_, bad["NewTokenBucket(1, 0)"] = NewTokenBucket(1, 0)
+	_, bad["NewSlidingLog(0, time.Second)"] = NewSlidingLog(0, time.Second)
+	_, bad["NewSlidingLog(1, 0)"] = NewSlidingLog(1, 0)
+	for call, err := range bad {
+		if !errors.Is(err, errs.ErrInvalidOption) {
+			t.Errorf("%s: expected ErrInvalidOption, got %v", call, err)
+		}
+	}
+}
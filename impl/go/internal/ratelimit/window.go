@@ -0,0 +1,120 @@
+package ratelimit
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dsa-lab/go/internal/errs"
+)
+
+// SlidingLog admits at most limit events per key in any window of the given
+// length. It keeps the timestamp of every admitted event still inside the
+// window, so it is exact where a fixed-window counter lets through up to
+// twice the limit across a window boundary, at the cost of O(limit) memory
+// per key.
+type SlidingLog struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	now    func() time.Time
+	logs   keyed[[]time.Time]
+}
+
+// NewSlidingLog creates a limiter admitting limit events per window per key.
+// It returns an error matching errs.ErrInvalidOption unless limit is at
+// least 1 and window positive.
+func NewSlidingLog(limit int, window time.Duration, opts ...Option) (*SlidingLog, error) {
+	if limit < 1 || window <= 0 {
+		return nil, fmt.Errorf("ratelimit: NewSlidingLog(%d, %v): %w", limit, window, errs.ErrInvalidOption)
+	}
+	cfg := newConfig(opts)
+	return &SlidingLog{
+		limit:  limit,
+		window: window,
+		now:    cfg.now,
+		logs:   newKeyed[[]time.Time](),
+	}, nil
+}
+
+// MustNewSlidingLog is like NewSlidingLog but panics if limit or window is
+// invalid.
+func MustNewSlidingLog(limit int, window time.Duration, opts ...Option) *SlidingLog {
+	return errs.Must(NewSlidingLog(limit, window, opts...))
+}
+
+// trim drops the timestamps that have left the window ending at now. The
+// log is in ascending order, so they form a prefix.
+func (l *SlidingLog) trim(log *[]time.Time, now time.Time) {
+	cutoff := now.Add(-l.window)
+	i := 0
+	for i < len(*log) && !(*log)[i].After(cutoff) {
+		i++
+	}
+	if i > 0 {
+		*log = append((*log)[:0], (*log)[i:]...)
+	}
+}
+
+// Allow records an event for key if fewer than limit events were admitted
+// in the last window, reporting whether it did.
+func (l *SlidingLog) Allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	log := l.logs.lookup(key, nil)
+	l.trim(log, now)
+	if len(*log) >= l.limit {
+		return false
+	}
+	*log = append(*log, now)
+	return true
+}
+
+// Count returns the number of events admitted for key in the last window.
+func (l *SlidingLog) Count(key string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	log, found := l.logs.find(key)
+	if !found {
+		return 0
+	}
+	l.trim(log, l.now())
+	return len(*log)
+}
+
+// RetryAfter returns how long key must wait until Allow can succeed, zero
+// if it can now.
+func (l *SlidingLog) RetryAfter(key string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	log, found := l.logs.find(key)
+	if !found {
+		return 0
+	}
+	now := l.now()
+	l.trim(log, now)
+	if len(*log) < l.limit {
+		return 0
+	}
+	return (*log)[len(*log)-l.limit].Add(l.window).Sub(now)
+}
+
+// Prune forgets keys with no events in the last window and returns how many
+// it forgot.
+func (l *SlidingLog) Prune() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	return l.logs.prune(func(log *[]time.Time) bool {
+		l.trim(log, now)
+		return len(*log) == 0
+	})
+}
+
+// Len returns the number of keys with state.
+func (l *SlidingLog) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.logs.len()
+}
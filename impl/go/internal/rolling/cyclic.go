@@ -0,0 +1,84 @@
+package rolling
+
+import (
+	"fmt"
+	"math/bits"
+
+	"github.com/dsa-lab/go/internal/errs"
+	"github.com/dsa-lab/go/internal/hashseed"
+)
+
+// Cyclic is the cyclic polynomial hash, or buzhash: each byte maps to a
+// random word from a seeded table, and a window hashes to the XOR of those
+// words, each rotated by its distance from the end. Sliding costs two
+// rotations and three XORs, with no multiplication, which makes it the
+// usual choice for chunking. Unlike Polynomial it offers no collision bound
+// beyond the randomness of the table, and in windows wider than 64 bytes,
+// bytes 64 positions apart share a rotation, so equal pairs cancel out.
+type Cyclic struct {
+	table [256]uint64
+	hash  uint64
+	width int
+	win   window
+}
+
+// NewCyclic creates a cyclic hash of the given window width. It returns an
+// error matching errs.ErrInvalidOption if width < 1.
+func NewCyclic(width int, seed uint64) (*Cyclic, error) {
+	if width < 1 {
+		return nil, fmt.Errorf("rolling: cyclic hash of width %d: %w", width, errs.ErrInvalidOption)
+	}
+	c := &Cyclic{win: newWindow(width), width: width}
+	for i := range c.table {
+		c.table[i] = hashseed.Derive(seed, i)
+	}
+	return c, nil
+}
+
+// MustNewCyclic is like NewCyclic but panics if width < 1.
+func MustNewCyclic(width int, seed uint64) *Cyclic {
+	return errs.Must(NewCyclic(width, seed))
+}
+
+// Append extends an unfilled window by b.
+func (c *Cyclic) Append(b byte) uint64 {
+	c.hash = bits.RotateLeft64(c.hash, 1) ^ c.table[b]
+	return c.hash
+}
+
+// Slide drops out from the front of a full window and appends in.
+func (c *Cyclic) Slide(out, in byte) uint64 {
+	// By now out has been rotated width-1 times; remove it before Append
+	// rotates again.
+	c.hash ^= bits.RotateLeft64(c.table[out], c.width-1)
+	return c.Append(in)
+}
+
+// Roll implements Hash.
+func (c *Cyclic) Roll(b byte) uint64 {
+	if out, full := c.win.push(b); full {
+		return c.Slide(out, b)
+	}
+	return c.Append(b)
+}
+
+// Sum64 implements Hash.
+func (c *Cyclic) Sum64() uint64 {
+	return c.hash
+}
+
+// Full implements Hash.
+func (c *Cyclic) Full() bool {
+	return c.win.n == c.width
+}
+
+// Width implements Hash.
+func (c *Cyclic) Width() int {
+	return c.width
+}
+
+// Reset implements Hash.
+func (c *Cyclic) Reset() {
+	c.hash = 0
+	c.win.reset()
+}
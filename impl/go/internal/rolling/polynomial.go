@@ -0,0 +1,123 @@
+package rolling
+
+import (
+	"fmt"
+	"math/bits"
+
+	"github.com/dsa-lab/go/internal/errs"
+	"github.com/dsa-lab/go/internal/hashseed"
+)
+
+// mersenne61 is the prime 2^61 - 1. Reducing modulo a Mersenne prime needs
+// only shifts and adds.
+const mersenne61 = 1<<61 - 1
+
+func mulMod(a, b uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	// a·b = hi·2^64 + lo = (hi·8 + lo>>61)·2^61 + lo&m, and 2^61 ≡ 1.
+	r := (lo & mersenne61) + (lo>>61 | hi<<3)
+	if r >= mersenne61 {
+		r -= mersenne61
+	}
+	return r
+}
+
+func addMod(a, b uint64) uint64 {
+	r := a + b
+	if r >= mersenne61 {
+		r -= mersenne61
+	}
+	return r
+}
+
+// Polynomial is the Rabin–Karp hash: a window b₀…b_{w-1} hashes to
+// Σ bᵢ·x^(w-1-i) mod 2^61-1 for a base x drawn from the seed. Two distinct
+// windows collide only if x is a root of their difference, a polynomial of
+// degree below w, so for a random seed the probability is at most
+// (w-1)/(2^61-1).
+type Polynomial struct {
+	base  uint64
+	top   uint64 // base^(width-1): the weight of the oldest byte
+	hash  uint64
+	width int
+	win   window
+}
+
+// NewPolynomial creates a polynomial hash of the given window width. It
+// returns an error matching errs.ErrInvalidOption if width < 1.
+func NewPolynomial(width int, seed uint64) (*Polynomial, error) {
+	if width < 1 {
+		return nil, fmt.Errorf("rolling: polynomial hash of width %d: %w", width, errs.ErrInvalidOption)
+	}
+	p := &Polynomial{base: polynomialBase(seed), win: newWindow(width), width: width}
+	p.top = 1
+	for i := 1; i < p.width; i++ {
+		p.top = mulMod(p.top, p.base)
+	}
+	return p, nil
+}
+
+// MustNewPolynomial is like NewPolynomial but panics if width < 1.
+func MustNewPolynomial(width int, seed uint64) *Polynomial {
+	return errs.Must(NewPolynomial(width, seed))
+}
+
+// polynomialBase picks a base in [256, 2^61-1) from seed. Bases below 256
+// would let small byte differences cancel.
+func polynomialBase(seed uint64) uint64 {
+	return 256 + hashseed.Derive(seed, 0)%(mersenne61-256)
+}
+
+// Append extends an unfilled window by b.
+func (p *Polynomial) Append(b byte) uint64 {
+	p.hash = addMod(mulMod(p.hash, p.base), uint64(b))
+	return p.hash
+}
+
+// Slide drops out from the front of a full window and appends in. out must
+// be the oldest byte of the window; callers that keep the input, like
+// Rabin–Karp, pass it directly instead of going through Roll.
+func (p *Polynomial) Slide(out, in byte) uint64 {
+	p.hash = addMod(p.hash, mersenne61-mulMod(uint64(out), p.top))
+	return p.Append(in)
+}
+
+// Roll implements Hash.
+func (p *Polynomial) Roll(b byte) uint64 {
+	if out, full := p.win.push(b); full {
+		return p.Slide(out, b)
+	}
+	return p.Append(b)
+}
+
+// Sum64 implements Hash.
+func (p *Polynomial) Sum64() uint64 {
+	return p.hash
+}
+
+// Full implements Hash.
+func (p *Polynomial) Full() bool {
+	return p.win.n == p.width
+}
+
+// Width implements Hash.
+func (p *Polynomial) Width() int {
+	return p.width
+}
+
+// Reset implements Hash.
+func (p *Polynomial) Reset() {
+	p.hash = 0
+	p.win.reset()
+}
+
+// HashBytes returns the polynomial hash of data under seed, equal to the
+// hash of a window holding exactly data.
+func HashBytes(data []byte, seed uint64) uint64 {
+	base := polynomialBase(seed)
+	var h uint64
+	for _, b := range data {
+		h = addMod(mulMod(h, base), uint64(b))
+	}
+	return h
+}
@@ -0,0 +1,59 @@
+package rolling
+
+import (
+	"bytes"
+
+	"github.com/dsa-lab/go/internal/hashseed"
+)
+
+// Index returns the index of the first occurrence of pattern in text, or -1.
+// It is Rabin–Karp search: slide a polynomial hash of pattern's width along
+// text and compare bytes only where the hashes agree. Each search uses a
+// fresh seed, so no input can be crafted to force many false matches, and
+// the expected time is O(len(text) + len(pattern)).
+func Index(text, pattern []byte) int {
+	matches := search(text, pattern, 1)
+	if len(matches) == 0 {
+		return -1
+	}
+	return matches[0]
+}
+
+// IndexAll returns the start of every occurrence of pattern in text,
+// overlapping ones included.
+func IndexAll(text, pattern []byte) []int {
+	return search(text, pattern, -1)
+}
+
+// search returns up to limit matches, or all of them if limit is negative.
+func search(text, pattern []byte, limit int) []int {
+	var matches []int
+	m := len(pattern)
+	if m == 0 {
+		for i := 0; i <= len(text) && len(matches) != limit; i++ {
+			matches = append(matches, i)
+		}
+		return matches
+	}
+	if m > len(text) {
+		return nil
+	}
+	seed := hashseed.New()
+	want := HashBytes(pattern, seed)
+	h := MustNewPolynomial(m, seed)
+	for _, b := range text[:m] {
+		h.Append(b)
+	}
+	for i := 0; ; i++ {
+		if h.Sum64() == want && bytes.Equal(text[i:i+m], pattern) {
+			matches = append(matches, i)
+			if len(matches) == limit {
+				return matches
+			}
+		}
+		if i+m == len(text) {
+			return matches
+		}
+		h.Slide(text[i], text[i+m])
+	}
+}
@@ -0,0 +1,53 @@
+// Package rolling provides rolling hashes: hashes of a fixed-width window of
+// bytes that slide one byte at a time in O(1), independent of the width.
+// Rabin–Karp search and content-defined chunking both hash every window of
+// their input, which would cost O(width) per byte with an ordinary hash.
+//
+// Both hashes are keyed by a seed from internal/hashseed, the same seeds
+// that scramble hashmap slots: Polynomial derives its base from the seed and
+// Cyclic its byte table. A fixed seed gives reproducible hashes, needed when
+// they are stored, as chunk boundaries are; hashseed.New gives hashes an
+// adversary cannot predict.
+package rolling
+
+// Hash is a rolling hash over a window of fixed width.
+type Hash interface {
+	// Roll appends b to the window, dropping the oldest byte once the
+	// window is full, and returns the new hash.
+	Roll(b byte) uint64
+	// Sum64 returns the hash of the current window.
+	Sum64() uint64
+	// Full reports whether the window holds Width bytes.
+	Full() bool
+	// Width returns the window width.
+	Width() int
+	// Reset empties the window.
+	Reset()
+}
+
+// window is the ring buffer of recent bytes behind Roll.
+type window struct {
+	buf []byte
+	pos int
+	n   int
+}
+
+func newWindow(width int) window {
+	return window{buf: make([]byte, width)}
+}
+
+// push stores b, returning the byte it displaces and whether the window was
+// already full.
+func (w *window) push(b byte) (out byte, full bool) {
+	out, full = w.buf[w.pos], w.n == len(w.buf)
+	w.buf[w.pos] = b
+	w.pos = (w.pos + 1) % len(w.buf)
+	if !full {
+		w.n++
+	}
+	return out, full
+}
+
+func (w *window) reset() {
+	w.pos, w.n = 0, 0
+}
@@ -0,0 +1,157 @@
+package rolling
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/errs"
+)
+
+func hashes(seed uint64) map[string]func(width int) Hash {
+	return map[string]func(width int) Hash{
+		"polynomial": func(width int) Hash { return MustNewPolynomial(width, seed) },
+		"cyclic":     func(width int) Hash { return MustNewCyclic(width, seed) },
+	}
+}
+
+func TestZeroWidthRejected(t *testing.T) {
+	if _, err := NewPolynomial(0, 1); !errors.Is(err, errs.ErrInvalidOption) {
+		t.Errorf("NewPolynomial(0): expected ErrInvalidOption, got %v", err)
+	}
+	if _, err := NewCyclic(-1, 1); !errors.Is(err, errs.ErrInvalidOption) {
+		t.Errorf("NewCyclic(-1): expected ErrInvalidOption, got %v", err)
+	}
+}
+
+// TestRollMatchesFreshHash checks that sliding gives the same hash as
+// hashing the current window from scratch.
+func TestRollMatchesFreshHash(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	data := make([]byte, 2000)
+	rng.Read(data)
+	for name, newHash := range hashes(42) {
+		for _, width := range []int{1, 2, 7, 48, 64, 100} {
+			h := newHash(width)
+			for i, b := range data {
+				got := h.Roll(b)
+				start := max(0, i+1-width)
+				fresh := newHash(width)
+				for _, c := range data[start : i+1] {
+					fresh.Roll(c)
+				}
+				if got != fresh.Sum64() {
+					t.Fatalf("%s width %d at %d: expected %x, got %x", name, width, i, fresh.Sum64(), got)
+				}
+				if h.Full() != (i+1 >= width) {
+					t.Fatalf("%s width %d at %d: expected Full %v", name, width, i, i+1 >= width)
+				}
+			}
+			h.Reset()
+			if h.Sum64() != 0 || h.Full() {
+				t.Errorf("%s: expected an empty window after Reset", name)
+			}
+		}
+	}
+}
+
+func TestPolynomialMatchesHashBytes(t *testing.T) {
+	data := []byte("the quick brown fox jumps over the lazy dog")
+	h := MustNewPolynomial(9, 7)
+	for i, b := range data {
+		h.Roll(b)
+		if i >= 8 && h.Sum64() != HashBytes(data[i-8:i+1], 7) {
+			t.Fatalf("at %d: expected the window hash to equal HashBytes", i)
+		}
+	}
+}
+
+func TestSeedsDetermineHashes(t *testing.T) {
+	data := []byte("content-defined")
+	sum := func(h Hash) uint64 {
+		for _, b := range data {
+			h.Roll(b)
+		}
+		return h.Sum64()
+	}
+	for name := range hashes(0) {
+		a, b, c := sum(hashes(1)[name](8)), sum(hashes(1)[name](8)), sum(hashes(2)[name](8))
+		if a != b {
+			t.Errorf("%s: expected equal hashes under one seed", name)
+		}
+		if a == c {
+			t.Errorf("%s: expected different hashes under different seeds", name)
+		}
+	}
+}
+
+// TestCollisionRates hashes many distinct random windows. No two should
+// collide in all 64 bits, and collisions in the low 20 bits should occur as
+// often as the birthday bound predicts for a uniform hash.
+func TestCollisionRates(t *testing.T) {
+	const n, width, lowBits = 1 << 17, 16, 20
+	rng := rand.New(rand.NewSource(3))
+	data := make([]byte, n+width-1)
+	rng.Read(data)
+	expected := float64(n) * float64(n-1) / 2 / (1 << lowBits)
+	for name, newHash := range hashes(99) {
+		h := newHash(width)
+		full := make(map[uint64]int, n)
+		low := make(map[uint64]int, n)
+		windows := make(map[string]bool, n)
+		for i, b := range data {
+			sum := h.Roll(b)
+			if i+1 < width {
+				continue
+			}
+			if w := string(data[i+1-width : i+1]); !windows[w] {
+				windows[w] = true
+				full[sum]++
+				low[sum&(1<<lowBits-1)]++
+			}
+		}
+		if len(full) != len(windows) {
+			t.Errorf("%s: expected no 64-bit collisions, got %d", name, len(windows)-len(full))
+		}
+		pairs := 0
+		for _, c := range low {
+			pairs += c * (c - 1) / 2
+		}
+		if r := float64(pairs) / expected; r < 0.9 || r > 1.1 {
+			t.Errorf("%s: expected about %.0f low-bit collisions, got %d", name, expected, pairs)
+		}
+	}
+}
+
+func bruteIndexAll(text, pattern []byte) []int {
+	var out []int
+	for i := 0; i+len(pattern) <= len(text); i++ {
+		if bytes.Equal(text[i:i+len(pattern)], pattern) {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+func TestRabinKarpAgainstBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(4))
+	random := func(n int) []byte {
+		b := make([]byte, n)
+		for i := range b {
+			b[i] = "ab"[rng.Intn(2)]
+		}
+		return b
+	}
+	for trial := 0; trial < 500; trial++ {
+		text, pattern := random(rng.Intn(200)), random(rng.Intn(6))
+		want := bruteIndexAll(text, pattern)
+		if got := IndexAll(text, pattern); !slices.Equal(got, want) {
+			t.Fatalf("IndexAll(%q, %q): expected %v, got %v", text, pattern, want, got)
+		}
+		if got, first := Index(text, pattern), bytes.Index(text, pattern); got != first {
+			t.Fatalf("Index(%q, %q): expected %d, got %d", text, pattern, first, got)
+		}
+	}
+}
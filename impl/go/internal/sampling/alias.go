@@ -0,0 +1,88 @@
+package sampling
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+var (
+	// ErrNoWeights is returned when an alias table is built from no weights
+	// or from weights that sum to zero.
+	ErrNoWeights = errors.New("sampling: no positive weights")
+	// ErrInvalidWeight is returned for a negative, infinite or NaN weight.
+	ErrInvalidWeight = errors.New("sampling: invalid weight")
+)
+
+// Alias samples indices from a discrete distribution in O(1) per draw using
+// Vose's alias method. Building the table takes O(n). Index i is drawn with
+// probability weights[i] / sum(weights). An Alias is immutable once built and
+// may be shared; the caller supplies the random source on each draw.
+type Alias struct {
+	prob  []float64
+	alias []int
+}
+
+// NewAlias builds an alias table for weights, which need not be normalized.
+func NewAlias(weights []float64) (*Alias, error) {
+	var total float64
+	for i, w := range weights {
+		if w < 0 || math.IsInf(w, 0) || math.IsNaN(w) {
+			return nil, fmt.Errorf("%w: weights[%d] = %v", ErrInvalidWeight, i, w)
+		}
+		total += w
+	}
+	if total == 0 {
+		return nil, ErrNoWeights
+	}
+
+	n := len(weights)
+	a := &Alias{prob: make([]float64, n), alias: make([]int, n)}
+	scaled := make([]float64, n)
+	var small, large []int
+	for i, w := range weights {
+		scaled[i] = w * float64(n) / total
+		if scaled[i] < 1 {
+			small = append(small, i)
+		} else {
+			large = append(large, i)
+		}
+	}
+
+	// Pair each under-full column with an over-full one that tops it up.
+	for len(small) > 0 && len(large) > 0 {
+		s, l := small[len(small)-1], large[len(large)-1]
+		small, large = small[:len(small)-1], large[:len(large)-1]
+		a.prob[s] = scaled[s]
+		a.alias[s] = l
+		scaled[l] += scaled[s] - 1
+		if scaled[l] < 1 {
+			small = append(small, l)
+		} else {
+			large = append(large, l)
+		}
+	}
+	// Whatever remains is full up to rounding error.
+	for _, i := range large {
+		a.prob[i] = 1
+	}
+	for _, i := range small {
+		a.prob[i] = 1
+	}
+	return a, nil
+}
+
+// Len returns the number of outcomes.
+func (a *Alias) Len() int {
+	return len(a.prob)
+}
+
+// Sample draws an index using rng.
+func (a *Alias) Sample(rng *rand.Rand) int {
+	i := rng.Intn(len(a.prob))
+	if rng.Float64() < a.prob[i] {
+		return i
+	}
+	return a.alias[i]
+}
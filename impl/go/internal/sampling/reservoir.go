@@ -0,0 +1,131 @@
+// Package sampling draws random samples from streams and from weighted
+// discrete distributions: uniform reservoir sampling over a stream of unknown
+// length (Algorithms R and L) and constant-time weighted sampling with Vose's
+// alias method.
+package sampling
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/dsa-lab/go/internal/errs"
+)
+
+// Reservoir keeps a uniform random sample of up to k items from a stream
+// using Algorithm R (Vitter, 1985). Every item seen so far is in the sample
+// with equal probability k/n. Each Add draws one random number.
+type Reservoir[T any] struct {
+	k      int
+	seen   int
+	sample []T
+	rng    *rand.Rand
+}
+
+// NewReservoir creates a reservoir of size k that draws from rng. It returns
+// an error matching errs.ErrInvalidOption if k < 1.
+func NewReservoir[T any](k int, rng *rand.Rand) (*Reservoir[T], error) {
+	if k < 1 {
+		return nil, fmt.Errorf("sampling: reservoir of size %d: %w", k, errs.ErrInvalidOption)
+	}
+	return &Reservoir[T]{k: k, sample: make([]T, 0, k), rng: rng}, nil
+}
+
+// MustNewReservoir is like NewReservoir but panics if k < 1.
+func MustNewReservoir[T any](k int, rng *rand.Rand) *Reservoir[T] {
+	return errs.Must(NewReservoir[T](k, rng))
+}
+
+// Add offers item to the sample.
+func (r *Reservoir[T]) Add(item T) {
+	r.seen++
+	if len(r.sample) < r.k {
+		r.sample = append(r.sample, item)
+		return
+	}
+	if j := r.rng.Intn(r.seen); j < r.k {
+		r.sample[j] = item
+	}
+}
+
+// Seen returns the number of items offered so far.
+func (r *Reservoir[T]) Seen() int {
+	return r.seen
+}
+
+// Sample returns the current sample. The slice is owned by the reservoir and
+// is only valid until the next Add.
+func (r *Reservoir[T]) Sample() []T {
+	return r.sample
+}
+
+// SkipReservoir keeps a uniform random sample of up to k items like
+// Reservoir, but uses Algorithm L (Li, 1994): instead of rolling for every
+// item it computes how many items to skip before the next replacement, so a
+// stream of n items costs O(k(1 + log(n/k))) random numbers rather than O(n).
+type SkipReservoir[T any] struct {
+	k      int
+	seen   int
+	next   int // 1-based position of the next item to enter the sample
+	w      float64
+	sample []T
+	rng    *rand.Rand
+}
+
+// NewSkipReservoir creates an Algorithm L reservoir of size k that draws from
+// rng, with the same validation as NewReservoir.
+func NewSkipReservoir[T any](k int, rng *rand.Rand) (*SkipReservoir[T], error) {
+	if k < 1 {
+		return nil, fmt.Errorf("sampling: reservoir of size %d: %w", k, errs.ErrInvalidOption)
+	}
+	return &SkipReservoir[T]{k: k, sample: make([]T, 0, k), rng: rng}, nil
+}
+
+// MustNewSkipReservoir is like NewSkipReservoir but panics if k < 1.
+func MustNewSkipReservoir[T any](k int, rng *rand.Rand) *SkipReservoir[T] {
+	return errs.Must(NewSkipReservoir[T](k, rng))
+}
+
+// Add offers item to the sample.
+func (r *SkipReservoir[T]) Add(item T) {
+	r.seen++
+	if len(r.sample) < r.k {
+		r.sample = append(r.sample, item)
+		if len(r.sample) == r.k {
+			r.w = math.Exp(math.Log(r.uniform()) / float64(r.k))
+			r.skip()
+		}
+		return
+	}
+	if r.seen == r.next {
+		r.sample[r.rng.Intn(r.k)] = item
+		r.w *= math.Exp(math.Log(r.uniform()) / float64(r.k))
+		r.skip()
+	}
+}
+
+// skip advances next by a geometrically distributed gap with success
+// probability w.
+func (r *SkipReservoir[T]) skip() {
+	gap := math.Floor(math.Log(r.uniform()) / math.Log1p(-r.w))
+	if gap > math.MaxInt32 {
+		gap = math.MaxInt32
+	}
+	r.next = r.seen + int(gap) + 1
+}
+
+// uniform returns a float in (0, 1], safe to pass to math.Log.
+func (r *SkipReservoir[T]) uniform() float64 {
+	return 1 - r.rng.Float64()
+}
+
+// Seen returns the number of items offered so far.
+func (r *SkipReservoir[T]) Seen() int {
+	return r.seen
+}
+
+// Sample returns the current sample. The slice is owned by the reservoir and
+// is only valid until the next Add.
+func (r *SkipReservoir[T]) Sample() []T {
+	return r.sample
+}
@@ -0,0 +1,196 @@
+package sampling
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/errs"
+)
+
+// chiSquared returns Pearson's statistic for observed counts against
+// expected counts.
+func chiSquared(observed []int, expected []float64) float64 {
+	var stat float64
+	for i, o := range observed {
+		d := float64(o) - expected[i]
+		stat += d * d / expected[i]
+	}
+	return stat
+}
+
+// Critical values of the chi-squared distribution at p = 0.001, indexed by
+// degrees of freedom. The tests use fixed seeds, so they are deterministic;
+// the strict level only guards against choosing an unlucky seed.
+var chiSquaredCritical = map[int]float64{
+	4:  18.467,
+	9:  27.877,
+	19: 43.820,
+}
+
+type sampler interface {
+	Add(int)
+	Sample() []int
+	Seen() int
+}
+
+// testReservoirUniform checks that every stream position is equally likely to
+// end up in the sample, for streams both shorter and much longer than k.
+func testReservoirUniform(t *testing.T, newSampler func(k int, rng *rand.Rand) sampler) {
+	const trials = 20000
+	for _, tc := range []struct{ n, k int }{{20, 5}, {20, 1}, {10, 3}} {
+		rng := rand.New(rand.NewSource(42))
+		counts := make([]int, tc.n)
+		for trial := 0; trial < trials; trial++ {
+			r := newSampler(tc.k, rng)
+			for i := 0; i < tc.n; i++ {
+				r.Add(i)
+			}
+			if r.Seen() != tc.n {
+				t.Fatalf("expected %d seen, got %d", tc.n, r.Seen())
+			}
+			sample := r.Sample()
+			if len(sample) != tc.k {
+				t.Fatalf("expected sample of %d, got %d", tc.k, len(sample))
+			}
+			seen := make(map[int]bool)
+			for _, v := range sample {
+				if seen[v] {
+					t.Fatalf("item %d sampled twice in %v", v, sample)
+				}
+				seen[v] = true
+				counts[v]++
+			}
+		}
+
+		expected := make([]float64, tc.n)
+		for i := range expected {
+			expected[i] = float64(trials*tc.k) / float64(tc.n)
+		}
+		stat := chiSquared(counts, expected)
+		if limit := chiSquaredCritical[tc.n-1]; stat > limit {
+			t.Errorf("n=%d k=%d: chi-squared %.2f exceeds %.2f; counts %v", tc.n, tc.k, stat, limit, counts)
+		}
+	}
+}
+
+func TestReservoirUniform(t *testing.T) {
+	testReservoirUniform(t, func(k int, rng *rand.Rand) sampler { return MustNewReservoir[int](k, rng) })
+}
+
+func TestSkipReservoirUniform(t *testing.T) {
+	testReservoirUniform(t, func(k int, rng *rand.Rand) sampler { return MustNewSkipReservoir[int](k, rng) })
+}
+
+func TestReservoirRejectsNonPositiveK(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	if _, err := NewReservoir[int](0, rng); !errors.Is(err, errs.ErrInvalidOption) {
+		t.Errorf("NewReservoir(0): expected ErrInvalidOption, got %v", err)
+	}
+	if _, err := NewSkipReservoir[int](-2, rng); !errors.Is(err, errs.ErrInvalidOption) {
+		t.Errorf("NewSkipReservoir(-2): expected ErrInvalidOption, got %v", err)
+	}
+}
+
+func TestReservoirShortStream(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, r := range []sampler{MustNewReservoir[int](10, rng), MustNewSkipReservoir[int](10, rng)} {
+		for i := 0; i < 4; i++ {
+			r.Add(i)
+		}
+		if got := r.Sample(); len(got) != 4 {
+			t.Errorf("expected all 4 items, got %v", got)
+		}
+	}
+}
+
+func TestSkipReservoirLongStream(t *testing.T) {
+	// Items late in a long stream must still be reachable: split a stream of
+	// 100000 into ten bands and check the sample is spread evenly across them.
+	const (
+		n      = 100000
+		k      = 10
+		trials = 2000
+		bands  = 10
+	)
+	rng := rand.New(rand.NewSource(7))
+	counts := make([]int, bands)
+	for trial := 0; trial < trials; trial++ {
+		r := MustNewSkipReservoir[int](k, rng)
+		for i := 0; i < n; i++ {
+			r.Add(i)
+		}
+		for _, v := range r.Sample() {
+			counts[v*bands/n]++
+		}
+	}
+	expected := make([]float64, bands)
+	for i := range expected {
+		expected[i] = float64(trials*k) / bands
+	}
+	if stat := chiSquared(counts, expected); stat > chiSquaredCritical[bands-1] {
+		t.Errorf("chi-squared %.2f exceeds %.2f; counts %v", stat, chiSquaredCritical[bands-1], counts)
+	}
+}
+
+func TestAliasDistribution(t *testing.T) {
+	weights := []float64{1, 2, 3, 4, 0, 10}
+	a, err := NewAlias(weights)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Len() != len(weights) {
+		t.Errorf("expected %d outcomes, got %d", len(weights), a.Len())
+	}
+
+	const draws = 200000
+	rng := rand.New(rand.NewSource(3))
+	counts := make([]int, len(weights))
+	for i := 0; i < draws; i++ {
+		counts[a.Sample(rng)]++
+	}
+	if counts[4] != 0 {
+		t.Errorf("expected zero-weight outcome never drawn, got %d", counts[4])
+	}
+
+	// Drop the zero-weight outcome: it has no expected count.
+	var observed []int
+	var expected []float64
+	for i, w := range weights {
+		if w > 0 {
+			observed = append(observed, counts[i])
+			expected = append(expected, draws*w/20)
+		}
+	}
+	if stat := chiSquared(observed, expected); stat > chiSquaredCritical[len(observed)-1] {
+		t.Errorf("chi-squared %.2f exceeds %.2f; counts %v", stat, chiSquaredCritical[len(observed)-1], counts)
+	}
+}
+
+func TestAliasSingleOutcome(t *testing.T) {
+	a, err := NewAlias([]float64{0, 5, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		if got := a.Sample(rng); got != 1 {
+			t.Fatalf("expected 1, got %d", got)
+		}
+	}
+}
+
+func TestAliasInvalidWeights(t *testing.T) {
+	if _, err := NewAlias(nil); !errors.Is(err, ErrNoWeights) {
+		t.Errorf("expected ErrNoWeights, got %v", err)
+	}
+	if _, err := NewAlias([]float64{0, 0}); !errors.Is(err, ErrNoWeights) {
+		t.Errorf("expected ErrNoWeights, got %v", err)
+	}
+	for _, w := range []float64{-1, math.Inf(1), math.NaN()} {
+		if _, err := NewAlias([]float64{1, w}); !errors.Is(err, ErrInvalidWeight) {
+			t.Errorf("weight %v: expected ErrInvalidWeight, got %v", w, err)
+		}
+	}
+}
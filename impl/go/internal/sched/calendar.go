@@ -0,0 +1,194 @@
+package sched
+
+import (
+	"cmp"
+	"slices"
+)
+
+const (
+	minBuckets = 2
+	// widthSample is how many of the earliest timers a resize examines to
+	// choose the bucket width.
+	widthSample = 25
+)
+
+// Calendar is Brown's calendar queue. Deadlines are hashed into buckets like
+// dates into the days of a desk calendar: bucket i holds deadlines d with
+// d/width ≡ i (mod buckets), each bucket sorted. Advance reads the current
+// bucket's entries for the current "year", then moves to the next day. The
+// bucket count doubles or halves with the timer count, and each resize picks
+// a width from the spacing of the earliest deadlines, aiming for a few
+// timers per bucket, so Add and removal are O(1) expected for deadlines drawn
+// from a stable distribution. Cancel is O(1).
+//
+// A Calendar is not safe for concurrent use.
+type Calendar[T any] struct {
+	now     uint64
+	buckets []timerList[T]
+	width   uint64
+	// cur is the bucket being read; its day ends before top.
+	cur int
+	top uint64
+	n   int
+}
+
+// NewCalendar creates an empty calendar queue whose clock starts at start.
+func NewCalendar[T any](start uint64) *Calendar[T] {
+	c := &Calendar[T]{now: start}
+	c.reset(minBuckets, 1)
+	return c
+}
+
+// reset empties the buckets, resizing them, and points the calendar at the
+// day holding the current tick.
+func (c *Calendar[T]) reset(buckets int, width uint64) {
+	c.buckets = make([]timerList[T], buckets)
+	c.width = width
+	c.seek(c.now)
+}
+
+// seek makes the day holding tick the current one.
+func (c *Calendar[T]) seek(tick uint64) {
+	day := tick / c.width
+	c.cur = int(day % uint64(len(c.buckets)))
+	c.top = (day + 1) * c.width
+}
+
+// Now implements Scheduler.
+func (c *Calendar[T]) Now() uint64 {
+	return c.now
+}
+
+// Len implements Scheduler.
+func (c *Calendar[T]) Len() int {
+	return c.n
+}
+
+// Add implements Scheduler.
+func (c *Calendar[T]) Add(deadline uint64, value T) *Timer[T] {
+	t := &Timer[T]{deadline: deadline, value: value}
+	c.insert(t)
+	c.n++
+	if c.n > 2*len(c.buckets) {
+		c.resize(2 * len(c.buckets))
+	}
+	return t
+}
+
+// insert files t in its bucket, keeping the bucket sorted by deadline and
+// timers with equal deadlines in insertion order.
+func (c *Calendar[T]) insert(t *Timer[T]) {
+	// A deadline before the current day moves the calendar back to it;
+	// otherwise Advance would not reach it until the next year.
+	if t.deadline < c.top-c.width {
+		c.seek(t.deadline)
+	}
+	t.list = int(t.deadline / c.width % uint64(len(c.buckets)))
+	l := &c.buckets[t.list]
+	after := l.tail
+	for after != nil && after.deadline > t.deadline {
+		after = after.prev
+	}
+	if after == nil {
+		t.prev, t.next = nil, l.head
+		if l.head != nil {
+			l.head.prev = t
+		} else {
+			l.tail = t
+		}
+		l.head = t
+		return
+	}
+	t.prev, t.next = after, after.next
+	if after.next != nil {
+		after.next.prev = t
+	} else {
+		l.tail = t
+	}
+	after.next = t
+}
+
+// Cancel implements Scheduler.
+func (c *Calendar[T]) Cancel(t *Timer[T]) bool {
+	if t.list < 0 {
+		return false
+	}
+	c.buckets[t.list].remove(t)
+	t.list = -1
+	c.removed()
+	return true
+}
+
+// removed accounts for one timer leaving, shrinking the calendar when it is
+// mostly empty.
+func (c *Calendar[T]) removed() {
+	c.n--
+	if len(c.buckets) > minBuckets && c.n < len(c.buckets)/2 {
+		c.resize(len(c.buckets) / 2)
+	}
+}
+
+// peek returns the earliest timer and positions the calendar on its day.
+func (c *Calendar[T]) peek() *Timer[T] {
+	if c.n == 0 {
+		return nil
+	}
+	for range c.buckets {
+		if t := c.buckets[c.cur].head; t != nil && t.deadline < c.top {
+			return t
+		}
+		c.cur = (c.cur + 1) % len(c.buckets)
+		c.top += c.width
+	}
+	// A whole year passed with nothing due: the timers are sparse relative
+	// to the year's length. Find the earliest directly and jump to it.
+	var first *Timer[T]
+	for i := range c.buckets {
+		if t := c.buckets[i].head; t != nil && (first == nil || t.deadline < first.deadline) {
+			first = t
+		}
+	}
+	c.seek(first.deadline)
+	return first
+}
+
+// Advance implements Scheduler.
+func (c *Calendar[T]) Advance(now uint64) []*Timer[T] {
+	var fired []*Timer[T]
+	for {
+		t := c.peek()
+		if t == nil || t.deadline > max(now, c.now) {
+			break
+		}
+		c.buckets[t.list].remove(t)
+		t.list = -1
+		fired = append(fired, t)
+		c.removed()
+	}
+	c.now = max(c.now, now)
+	return fired
+}
+
+// resize rebuilds the calendar with the given number of buckets and a width
+// of about three times the mean gap between the earliest deadlines, as
+// Brown suggests.
+func (c *Calendar[T]) resize(buckets int) {
+	var all []*Timer[T]
+	for i := range c.buckets {
+		for t := c.buckets[i].take(); t != nil; t = t.next {
+			all = append(all, t)
+		}
+	}
+	slices.SortStableFunc(all, func(a, b *Timer[T]) int {
+		return cmp.Compare(a.deadline, b.deadline)
+	})
+	width := uint64(1)
+	if k := min(len(all), widthSample); k > 1 {
+		width = max(1, 3*(all[k-1].deadline-all[0].deadline)/uint64(k-1))
+	}
+	c.reset(buckets, width)
+	for _, t := range all {
+		t.prev, t.next = nil, nil
+		c.insert(t)
+	}
+}
@@ -0,0 +1,49 @@
+//go:build go1.23
+
+package sched
+
+import "iter"
+
+// The iterators below visit the pending timers in storage order, which is
+// not deadline order. The scheduler must not be modified during iteration;
+// collect the timers first to cancel some of them.
+
+// each yields the timers of l in order, reporting whether yield asked for
+// more.
+func (l *timerList[T]) each(yield func(*Timer[T]) bool) bool {
+	for t := l.head; t != nil; t = t.next {
+		if !yield(t) {
+			return false
+		}
+	}
+	return true
+}
+
+// All returns an iterator over the pending timers: those already due, then
+// each level's slots from the finest level up.
+func (w *Wheel[T]) All() iter.Seq[*Timer[T]] {
+	return func(yield func(*Timer[T]) bool) {
+		if !w.due.each(yield) {
+			return
+		}
+		for l := range w.slots {
+			for s := range w.slots[l] {
+				if !w.slots[l][s].each(yield) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// All returns an iterator over the pending timers bucket by bucket, each
+// bucket in deadline order.
+func (c *Calendar[T]) All() iter.Seq[*Timer[T]] {
+	return func(yield func(*Timer[T]) bool) {
+		for i := range c.buckets {
+			if !c.buckets[i].each(yield) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,90 @@
+// Package sched provides timer queues for a discrete clock of uint64 ticks:
+// a hierarchical timing wheel and a calendar queue. Both schedule a value
+// for a deadline, cancel it in O(1) through the returned handle, and fire
+// everything due when the clock advances. They trade the binary heap's
+// O(log n) per operation for O(1) expected time, the wheel by bucketing
+// deadlines by their bits and the calendar queue by bucketing them by time
+// like days of a year.
+package sched
+
+// Scheduler is a timer queue over a clock that only moves forward.
+type Scheduler[T any] interface {
+	// Add schedules value to fire at deadline. A deadline not after Now
+	// fires on the next Advance.
+	Add(deadline uint64, value T) *Timer[T]
+	// Cancel unschedules t, reporting whether it was still pending. t must
+	// have been returned by this scheduler's Add.
+	Cancel(t *Timer[T]) bool
+	// Advance moves the clock to now, if that is later, and returns every
+	// timer due by then, ordered by deadline.
+	Advance(now uint64) []*Timer[T]
+	// Now returns the current tick.
+	Now() uint64
+	// Len returns the number of pending timers.
+	Len() int
+}
+
+// Timer is a handle to a scheduled value.
+type Timer[T any] struct {
+	deadline   uint64
+	value      T
+	prev, next *Timer[T]
+	// list identifies the list holding the timer, a wheel slot or calendar
+	// bucket, or is -1 once it has fired or been cancelled.
+	list int
+}
+
+// Deadline returns the tick the timer fires at.
+func (t *Timer[T]) Deadline() uint64 {
+	return t.deadline
+}
+
+// Value returns the scheduled value.
+func (t *Timer[T]) Value() T {
+	return t.value
+}
+
+// Pending reports whether the timer has neither fired nor been cancelled.
+func (t *Timer[T]) Pending() bool {
+	return t.list >= 0
+}
+
+// timerList is an intrusive doubly linked list of timers.
+type timerList[T any] struct {
+	head, tail *Timer[T]
+}
+
+func (l *timerList[T]) empty() bool {
+	return l.head == nil
+}
+
+func (l *timerList[T]) pushBack(t *Timer[T]) {
+	t.prev, t.next = l.tail, nil
+	if l.tail != nil {
+		l.tail.next = t
+	} else {
+		l.head = t
+	}
+	l.tail = t
+}
+
+func (l *timerList[T]) remove(t *Timer[T]) {
+	if t.prev != nil {
+		t.prev.next = t.next
+	} else {
+		l.head = t.next
+	}
+	if t.next != nil {
+		t.next.prev = t.prev
+	} else {
+		l.tail = t.prev
+	}
+	t.prev, t.next = nil, nil
+}
+
+// take empties the list and returns its timers, which stay linked together.
+func (l *timerList[T]) take() *Timer[T] {
+	head := l.head
+	l.head, l.tail = nil, nil
+	return head
+}
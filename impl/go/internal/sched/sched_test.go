@@ -0,0 +1,166 @@
+package sched
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func schedulers(start uint64) map[string]Scheduler[int] {
+	return map[string]Scheduler[int]{
+		"wheel":    NewWheel[int](start),
+		"calendar": NewCalendar[int](start),
+	}
+}
+
+func values(fired []*Timer[int]) []int {
+	out := make([]int, len(fired))
+	for i, t := range fired {
+		out[i] = t.Value()
+	}
+	return out
+}
+
+func TestFiresAtDeadline(t *testing.T) {
+	for name, s := range schedulers(100) {
+		s.Add(105, 1)
+		s.Add(103, 2)
+		s.Add(200, 3)
+		if got := s.Advance(102); len(got) != 0 {
+			t.Errorf("%s: expected nothing due at 102, got %v", name, values(got))
+		}
+		if got := values(s.Advance(105)); !slices.Equal(got, []int{2, 1}) {
+			t.Errorf("%s: expected [2 1] by 105, got %v", name, got)
+		}
+		if s.Now() != 105 || s.Len() != 1 {
+			t.Errorf("%s: expected now 105 with 1 pending, got %d with %d", name, s.Now(), s.Len())
+		}
+		if got := values(s.Advance(1000)); !slices.Equal(got, []int{3}) {
+			t.Errorf("%s: expected [3] by 1000, got %v", name, got)
+		}
+	}
+}
+
+func TestPastDeadlinesFireOnNextAdvance(t *testing.T) {
+	for name, s := range schedulers(50) {
+		s.Add(10, 1)
+		s.Add(50, 2)
+		s.Add(51, 3)
+		if got := values(s.Advance(50)); !slices.Equal(got, []int{1, 2}) {
+			t.Errorf("%s: expected [1 2], got %v", name, got)
+		}
+		// Advancing to an earlier tick does not move the clock back.
+		s.Advance(20)
+		if s.Now() != 50 {
+			t.Errorf("%s: expected now to stay 50, got %d", name, s.Now())
+		}
+	}
+}
+
+func TestCancel(t *testing.T) {
+	for name, s := range schedulers(0) {
+		a := s.Add(10, 1)
+		b := s.Add(10, 2)
+		far := s.Add(1<<40, 3)
+		if !s.Cancel(a) || s.Cancel(a) {
+			t.Errorf("%s: expected the first Cancel to succeed and the second to fail", name)
+		}
+		if a.Pending() || !b.Pending() {
+			t.Errorf("%s: expected a cancelled and b pending", name)
+		}
+		if got := values(s.Advance(10)); !slices.Equal(got, []int{2}) {
+			t.Errorf("%s: expected [2], got %v", name, got)
+		}
+		if b.Pending() || s.Cancel(b) {
+			t.Errorf("%s: expected a fired timer not to be cancellable", name)
+		}
+		if !s.Cancel(far) || s.Len() != 0 {
+			t.Errorf("%s: expected the far timer cancelled leaving none, got %d", name, s.Len())
+		}
+	}
+}
+
+// TestWheelCascadesAcrossLevels schedules deadlines that differ from the
+// clock in every 6-bit group, so they enter high levels and must cascade
+// all the way down, and fire one tick apart.
+func TestWheelCascadesAcrossLevels(t *testing.T) {
+	w := NewWheel[int](0)
+	var want []int
+	for level := 0; level < levels; level++ {
+		d := uint64(1) << (level * slotBits)
+		w.Add(d+1, 2*level)
+		w.Add(d, 2*level+1)
+		want = append(want, 2*level+1, 2*level)
+	}
+	var got []int
+	var deadlines []uint64
+	for _, tick := range []uint64{1, 1 << 10, 1 << 33, 1 << 50, 1<<64 - 1} {
+		for _, timer := range w.Advance(tick) {
+			if timer.Deadline() > tick {
+				t.Fatalf("fired %d early at %d", timer.Deadline(), tick)
+			}
+			got = append(got, timer.Value())
+			deadlines = append(deadlines, timer.Deadline())
+		}
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if !slices.IsSorted(deadlines) {
+		t.Errorf("expected deadlines in order, got %v", deadlines)
+	}
+}
+
+// TestAgainstModel runs random adds, cancels and advances against a plain
+// list of pending timers, with deadlines both clustered and spread out.
+func TestAgainstModel(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, spread := range []uint64{8, 1000, 1 << 30} {
+		for name, s := range schedulers(uint64(rng.Intn(1000))) {
+			var pending []*Timer[int]
+			for op := 0; op < 20000; op++ {
+				switch r := rng.Intn(10); {
+				case r < 5:
+					d := s.Now() + uint64(rng.Int63n(int64(spread)))
+					if rng.Intn(20) == 0 && s.Now() > 0 {
+						d = s.Now() - 1
+					}
+					pending = append(pending, s.Add(d, op))
+				case r < 7 && len(pending) > 0:
+					i := rng.Intn(len(pending))
+					if !s.Cancel(pending[i]) {
+						t.Fatalf("%s: expected pending timer %d to cancel", name, pending[i].Value())
+					}
+					pending = slices.Delete(pending, i, i+1)
+				default:
+					to := s.Now() + uint64(rng.Int63n(int64(spread/4+2)))
+					var want []*Timer[int]
+					rest := pending[:0]
+					for _, p := range pending {
+						if p.Deadline() <= to {
+							want = append(want, p)
+						} else {
+							rest = append(rest, p)
+						}
+					}
+					pending = rest
+					got := s.Advance(to)
+					if len(got) != len(want) {
+						t.Fatalf("%s spread %d: expected %d timers by %d, got %d", name, spread, len(want), to, len(got))
+					}
+					for i, g := range got {
+						if i > 0 && g.Deadline() < got[i-1].Deadline() {
+							t.Fatalf("%s: expected deadlines in order", name)
+						}
+						if g.Deadline() > to || g.Pending() {
+							t.Fatalf("%s: fired timer %d (deadline %d) at %d", name, g.Value(), g.Deadline(), to)
+						}
+					}
+				}
+				if s.Len() != len(pending) {
+					t.Fatalf("%s: expected %d pending, got %d", name, len(pending), s.Len())
+				}
+			}
+		}
+	}
+}
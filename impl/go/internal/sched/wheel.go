@@ -0,0 +1,178 @@
+package sched
+
+import (
+	"cmp"
+	"math/bits"
+	"slices"
+)
+
+const (
+	slotBits = 6
+	slots    = 1 << slotBits
+	// levels covers every 64-bit deadline with 6 bits per level.
+	levels = (64 + slotBits - 1) / slotBits
+	// dueList is the list index of timers added with a past deadline.
+	dueList = levels * slots
+)
+
+// Wheel is a hierarchical timing wheel. Level l has 64 slots, each spanning
+// 64^l ticks. A timer goes into the level of the highest 6-bit group in
+// which its deadline differs from the current tick, and the slot given by
+// that group. When the clock reaches the start of a slot on a higher level,
+// the slot's timers cascade down to finer levels, so each timer is moved at
+// most once per level and reaches level 0 exactly on its deadline's rotation.
+// Add and Cancel are O(1); Advance skips empty slots using an occupancy
+// bitmap per level, so idle stretches cost nothing.
+//
+// A Wheel is not safe for concurrent use.
+type Wheel[T any] struct {
+	now      uint64
+	slots    [levels][slots]timerList[T]
+	occupied [levels]uint64
+	due      timerList[T]
+	n        int
+}
+
+// NewWheel creates an empty wheel whose clock starts at start.
+func NewWheel[T any](start uint64) *Wheel[T] {
+	return &Wheel[T]{now: start}
+}
+
+// Now implements Scheduler.
+func (w *Wheel[T]) Now() uint64 {
+	return w.now
+}
+
+// Len implements Scheduler.
+func (w *Wheel[T]) Len() int {
+	return w.n
+}
+
+// Add implements Scheduler.
+func (w *Wheel[T]) Add(deadline uint64, value T) *Timer[T] {
+	t := &Timer[T]{deadline: deadline, value: value}
+	w.n++
+	w.place(t)
+	return t
+}
+
+// place files t by its deadline relative to the current tick.
+func (w *Wheel[T]) place(t *Timer[T]) {
+	if t.deadline <= w.now {
+		t.list = dueList
+		w.due.pushBack(t)
+		return
+	}
+	level := (bits.Len64(t.deadline^w.now) - 1) / slotBits
+	slot := int(t.deadline>>(level*slotBits)) % slots
+	t.list = level*slots + slot
+	w.slots[level][slot].pushBack(t)
+	w.occupied[level] |= 1 << slot
+}
+
+// Cancel implements Scheduler.
+func (w *Wheel[T]) Cancel(t *Timer[T]) bool {
+	if t.list < 0 {
+		return false
+	}
+	w.unlink(t)
+	w.n--
+	return true
+}
+
+func (w *Wheel[T]) unlink(t *Timer[T]) {
+	if t.list == dueList {
+		w.due.remove(t)
+	} else {
+		level, slot := t.list/slots, t.list%slots
+		l := &w.slots[level][slot]
+		l.remove(t)
+		if l.empty() {
+			w.occupied[level] &^= 1 << slot
+		}
+	}
+	t.list = -1
+}
+
+// Advance implements Scheduler.
+func (w *Wheel[T]) Advance(now uint64) []*Timer[T] {
+	var fired []*Timer[T]
+	for t := w.due.take(); t != nil; {
+		next := t.next
+		t.prev, t.next, t.list = nil, nil, -1
+		fired = append(fired, t)
+		t = next
+	}
+	slices.SortStableFunc(fired, func(a, b *Timer[T]) int {
+		return cmp.Compare(a.deadline, b.deadline)
+	})
+	w.n -= len(fired)
+
+	for w.n > 0 {
+		next, ok := w.nextEvent()
+		if !ok || next > now {
+			break
+		}
+		w.now = next
+		// Cascade every level whose rotation starts at this tick, coarsest
+		// first, so timers falling through several levels land in level 0.
+		for level := levels - 1; level >= 1; level-- {
+			if w.now&(1<<(level*slotBits)-1) == 0 {
+				w.cascade(level, int(w.now>>(level*slotBits))%slots)
+			}
+		}
+		slot := int(w.now % slots)
+		for t := w.slots[0][slot].take(); t != nil; {
+			next := t.next
+			t.prev, t.next, t.list = nil, nil, -1
+			fired = append(fired, t)
+			w.n--
+			t = next
+		}
+		w.occupied[0] &^= 1 << slot
+	}
+	w.now = max(w.now, now)
+	return fired
+}
+
+// nextEvent returns the next tick at which a slot fires or cascades. Every
+// timer on level l sits in a slot after the current tick's slot on that
+// level, so the first occupied slot after it on the lowest level that has
+// one is the next event; lower levels' events all come before higher ones'.
+func (w *Wheel[T]) nextEvent() (uint64, bool) {
+	for level := 0; level < levels; level++ {
+		shift := level * slotBits
+		pos := int(w.now>>shift) % slots
+		// Slots at or before pos; a shift by 64 yields 0, so all of them.
+		ahead := w.occupied[level] &^ (1<<(pos+1) - 1)
+		if ahead == 0 {
+			continue
+		}
+		slot := uint64(bits.TrailingZeros64(ahead))
+		span := shift + slotBits
+		var base uint64
+		if span < 64 {
+			base = w.now &^ (1<<span - 1)
+		}
+		return base | slot<<shift, true
+	}
+	return 0, false
+}
+
+// cascade refiles the timers of one slot relative to the current tick.
+// Those due now go to the current level-0 slot, which fires next.
+func (w *Wheel[T]) cascade(level, slot int) {
+	t := w.slots[level][slot].take()
+	w.occupied[level] &^= 1 << slot
+	for t != nil {
+		next := t.next
+		t.prev, t.next = nil, nil
+		if t.deadline == w.now {
+			t.list = int(w.now % slots)
+			w.slots[0][t.list].pushBack(t)
+		} else {
+			w.place(t)
+		}
+		t = next
+	}
+}
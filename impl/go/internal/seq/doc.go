@@ -0,0 +1,13 @@
+// Package seq provides composable helpers over Go 1.23 iterators (iter.Seq
+// and iter.Seq2): Filter, MapSeq, Take, Collect and the Keys and Values
+// projections.
+//
+// The lab's collections expose their contents as iterators through All,
+// alongside their callback-based Range methods, and ordered collections add
+// Between(lo, hi) for the half-open key range [lo, hi). Where a collection
+// already had slice-returning Keys or Values methods, those keep their
+// signatures; seq.Keys(c.All()) gives the iterator form.
+//
+// The module still builds with Go 1.21, so every iterator method and helper
+// lives in a file constrained to go1.23 and is absent on older toolchains.
+package seq
@@ -0,0 +1,96 @@
+//go:build go1.23
+
+package seq
+
+import "iter"
+
+// Filter yields the elements of s for which keep returns true.
+func Filter[T any](s iter.Seq[T], keep func(T) bool) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for x := range s {
+			if keep(x) && !yield(x) {
+				return
+			}
+		}
+	}
+}
+
+// Filter2 yields the pairs of s for which keep returns true.
+func Filter2[K, V any](s iter.Seq2[K, V], keep func(K, V) bool) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for k, v := range s {
+			if keep(k, v) && !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+// MapSeq yields f applied to each element of s.
+func MapSeq[T, U any](s iter.Seq[T], f func(T) U) iter.Seq[U] {
+	return func(yield func(U) bool) {
+		for x := range s {
+			if !yield(f(x)) {
+				return
+			}
+		}
+	}
+}
+
+// Take yields at most the first n elements of s.
+func Take[T any](s iter.Seq[T], n int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if n <= 0 {
+			return
+		}
+		i := 0
+		for x := range s {
+			if !yield(x) {
+				return
+			}
+			if i++; i == n {
+				return
+			}
+		}
+	}
+}
+
+// Keys yields the first element of each pair of s.
+func Keys[K, V any](s iter.Seq2[K, V]) iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for k := range s {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// Values yields the second element of each pair of s.
+func Values[K, V any](s iter.Seq2[K, V]) iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, v := range s {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Collect returns the elements of s in a new slice.
+func Collect[T any](s iter.Seq[T]) []T {
+	var out []T
+	for x := range s {
+		out = append(out, x)
+	}
+	return out
+}
+
+// Count returns the number of elements of s.
+func Count[T any](s iter.Seq[T]) int {
+	n := 0
+	for range s {
+		n++
+	}
+	return n
+}
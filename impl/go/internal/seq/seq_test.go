@@ -0,0 +1,66 @@
+//go:build go1.23
+
+package seq
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestHelpersCompose(t *testing.T) {
+	nums := slices.Values([]int{1, 2, 3, 4, 5, 6, 7, 8})
+	even := Filter(nums, func(x int) bool { return x%2 == 0 })
+	squares := MapSeq(even, func(x int) int { return x * x })
+	if got := Collect(Take(squares, 3)); !slices.Equal(got, []int{4, 16, 36}) {
+		t.Errorf("expected [4 16 36], got %v", got)
+	}
+	if got := Count(even); got != 4 {
+		t.Errorf("expected 4 even numbers, got %d", got)
+	}
+	if got := Collect(Take(nums, 0)); got != nil {
+		t.Errorf("expected nothing from Take 0, got %v", got)
+	}
+}
+
+func TestPairs(t *testing.T) {
+	m := map[string]int{"a": 1, "b": 2, "c": 3}
+	big := Filter2(maps.All(m), func(_ string, v int) bool { return v > 1 })
+	keys := Collect(Keys(big))
+	slices.Sort(keys)
+	if !slices.Equal(keys, []string{"b", "c"}) {
+		t.Errorf("expected [b c], got %v", keys)
+	}
+	values := Collect(Values(big))
+	slices.Sort(values)
+	if !slices.Equal(values, []int{2, 3}) {
+		t.Errorf("expected [2 3], got %v", values)
+	}
+}
+
+// TestEarlyStop checks that every helper stops pulling from its source once
+// the consumer stops.
+func TestEarlyStop(t *testing.T) {
+	pulled := 0
+	source := func(yield func(int) bool) {
+		for i := 0; ; i++ {
+			pulled++
+			if !yield(i) {
+				return
+			}
+		}
+	}
+	for x := range MapSeq(Filter(source, func(int) bool { return true }), func(x int) int { return x }) {
+		if x == 4 {
+			break
+		}
+	}
+	if pulled != 5 {
+		t.Errorf("expected 5 elements pulled, got %d", pulled)
+	}
+	pulled = 0
+	Collect(Take(source, 3))
+	if pulled != 3 {
+		t.Errorf("expected Take to pull 3 elements, got %d", pulled)
+	}
+}
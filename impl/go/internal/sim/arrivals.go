@@ -0,0 +1,103 @@
+package sim
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Arrivals is an arrival process: Next returns the gap from an arrival at
+// now to the one after it.
+type Arrivals interface {
+	Next(now time.Duration, rng *rand.Rand) time.Duration
+}
+
+// exponential draws a gap with the given mean rate per second.
+func exponential(rate float64, rng *rand.Rand) time.Duration {
+	return time.Duration(rng.ExpFloat64() / rate * float64(time.Second))
+}
+
+// Poisson arrives at a constant average rate per second with independent,
+// exponentially distributed gaps: the memoryless traffic of many unrelated
+// clients.
+type Poisson struct {
+	Rate float64
+}
+
+// Next implements Arrivals.
+func (p Poisson) Next(_ time.Duration, rng *rand.Rand) time.Duration {
+	return exponential(p.Rate, rng)
+}
+
+// Periodic arrives exactly every Interval, like a polling job.
+type Periodic struct {
+	Interval time.Duration
+}
+
+// Next implements Arrivals.
+func (p Periodic) Next(time.Duration, *rand.Rand) time.Duration {
+	return p.Interval
+}
+
+// OnOff alternates between bursts of Poisson arrivals at OnRate, lasting On
+// on average, and silences lasting Off on average, with exponentially
+// distributed period lengths. Its arrivals cluster far more than a Poisson
+// stream of the same mean rate, which is what token buckets exist for. An
+// OnOff tracks its current burst, so each client needs its own.
+type OnOff struct {
+	OnRate  float64
+	On, Off time.Duration
+
+	// burstEnd is the end of the current burst; zero before the first.
+	burstEnd time.Duration
+}
+
+// Next implements Arrivals.
+func (o *OnOff) Next(now time.Duration, rng *rand.Rand) time.Duration {
+	if o.burstEnd == 0 {
+		o.burstEnd = now + expDuration(o.On, rng)
+	}
+	t := now
+	for {
+		t += exponential(o.OnRate, rng)
+		if t < o.burstEnd {
+			return t - now
+		}
+		// The burst ended first. By memorylessness the next burst's
+		// arrivals start afresh after the silence.
+		t = o.burstEnd + expDuration(o.Off, rng)
+		o.burstEnd = t + expDuration(o.On, rng)
+	}
+}
+
+func expDuration(mean time.Duration, rng *rand.Rand) time.Duration {
+	return time.Duration(rng.ExpFloat64() * float64(mean))
+}
+
+// Diurnal is a Poisson stream whose rate follows a sinusoid with the given
+// Period, between Base-Amplitude and Base+Amplitude arrivals per second,
+// peaking a quarter period after the start. It is sampled by thinning:
+// candidates are drawn at the peak rate and each kept with probability
+// rate(t)/peak.
+type Diurnal struct {
+	Base, Amplitude float64
+	Period          time.Duration
+}
+
+// Rate returns the arrival rate at t.
+func (d Diurnal) Rate(t time.Duration) float64 {
+	phase := 2 * math.Pi * float64(t) / float64(d.Period)
+	return max(0, d.Base+d.Amplitude*math.Sin(phase))
+}
+
+// Next implements Arrivals.
+func (d Diurnal) Next(now time.Duration, rng *rand.Rand) time.Duration {
+	peak := d.Base + math.Abs(d.Amplitude)
+	t := now
+	for {
+		t += exponential(peak, rng)
+		if rng.Float64()*peak < d.Rate(t) {
+			return t - now
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package sim
+
+import (
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/dsa-lab/go/internal/workload"
+)
+
+// Keys chooses the key of each request.
+type Keys func(rng *rand.Rand) string
+
+// UniformKeys chooses among n keys with equal probability.
+func UniformKeys(prefix string, n int) Keys {
+	return func(rng *rand.Rand) string {
+		return prefix + strconv.Itoa(rng.Intn(n))
+	}
+}
+
+// ZipfKeys chooses among n keys with a Zipfian skew s > 1: key i is chosen
+// with probability proportional to 1/(i+1)^s, as popularity usually falls
+// off.
+func ZipfKeys(prefix string, n int, s float64) Keys {
+	// rand.Zipf binds its random source at creation, so one is made for
+	// each source the chooser is called with.
+	var zipf *rand.Zipf
+	var source *rand.Rand
+	return func(rng *rand.Rand) string {
+		if rng != source {
+			zipf, source = rand.NewZipf(rng, s, 1, uint64(n-1)), rng
+		}
+		return prefix + strconv.FormatUint(zipf.Uint64(), 10)
+	}
+}
+
+// Client is a source of requests: when they arrive and what they ask for.
+type Client struct {
+	Name     string
+	Arrivals Arrivals
+	Keys     Keys
+}
+
+// Request is one generated request.
+type Request struct {
+	At     time.Time
+	Client string
+	Key    string
+}
+
+// Generate simulates clients from start for the given duration and returns
+// their requests in time order. The same seed always produces the same
+// requests.
+func Generate(start time.Time, duration time.Duration, seed int64, clients ...Client) []Request {
+	s := New(start, seed)
+	var requests []Request
+	for _, c := range clients {
+		c := c
+		first := c.Arrivals.Next(0, s.Rand())
+		s.Spawn(first, func(s *Sim) (time.Duration, bool) {
+			requests = append(requests, Request{At: s.Now(), Client: c.Name, Key: c.Keys(s.Rand())})
+			return c.Arrivals.Next(s.Elapsed(), s.Rand()), true
+		})
+	}
+	s.RunFor(duration)
+	return requests
+}
+
+// Workload converts requests to a workload of gets, the access trace a cache
+// sees, keeping their order. Its size is the number of distinct keys.
+func Workload(name string, requests []Request) *workload.Workload {
+	w := &workload.Workload{Name: name}
+	keys := make(map[string]bool)
+	for _, r := range requests {
+		w.Operations = append(w.Operations, workload.Operation{Op: workload.OpGet, Key: r.Key})
+		keys[r.Key] = true
+	}
+	w.Size = len(keys)
+	w.OperationWeights = workload.Weights(w.Operations)
+	return w
+}
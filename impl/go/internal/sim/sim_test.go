@@ -0,0 +1,164 @@
+package sim
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+)
+
+var epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestEventsRunInTimeOrder(t *testing.T) {
+	s := New(epoch, 1)
+	var log []string
+	record := func(name string) func() {
+		return func() { log = append(log, name) }
+	}
+	s.Schedule(3*time.Second, record("c"))
+	s.Schedule(time.Second, record("a1"))
+	s.Schedule(time.Second, record("a2"))
+	s.Schedule(2*time.Second, func() {
+		log = append(log, "b")
+		s.Schedule(0, record("b-now"))
+		s.Schedule(-time.Second, record("b-past"))
+	})
+	s.Run()
+	want := []string{"a1", "a2", "b", "b-now", "b-past", "c"}
+	if !slices.Equal(log, want) {
+		t.Errorf("expected %v, got %v", want, log)
+	}
+	if got := s.Now(); !got.Equal(epoch.Add(3 * time.Second)) {
+		t.Errorf("expected the clock at the last event, got %v", got)
+	}
+}
+
+func TestRunForAndStop(t *testing.T) {
+	s := New(epoch, 1)
+	ticks := 0
+	s.Spawn(0, func(s *Sim) (time.Duration, bool) {
+		ticks++
+		return time.Second, true
+	})
+	if n := s.RunFor(10 * time.Second); n != 11 || ticks != 11 {
+		t.Errorf("expected 11 activations in [0s, 10s], got %d", ticks)
+	}
+	if s.Elapsed() != 10*time.Second || s.Pending() != 1 {
+		t.Errorf("expected the clock at 10s with one pending event, got %v and %d", s.Elapsed(), s.Pending())
+	}
+	s.Schedule(1500*time.Millisecond, s.Stop)
+	s.Run()
+	if s.Elapsed() != 11500*time.Millisecond {
+		t.Errorf("expected Stop to end the run at 11.5s, got %v", s.Elapsed())
+	}
+}
+
+func TestProcessEnds(t *testing.T) {
+	s := New(epoch, 1)
+	left := 3
+	s.Spawn(time.Minute, func(*Sim) (time.Duration, bool) {
+		left--
+		return time.Minute, left > 0
+	})
+	s.Run()
+	if left != 0 || s.Elapsed() != 3*time.Minute {
+		t.Errorf("expected three activations ending at 3m, got %d left at %v", left, s.Elapsed())
+	}
+}
+
+// counts returns arrivals per second over n seconds.
+func counts(a Arrivals, n int, seed int64) []int {
+	rng := rand.New(rand.NewSource(seed))
+	out := make([]int, n)
+	for t := a.Next(0, rng); t < time.Duration(n)*time.Second; t += a.Next(t, rng) {
+		out[t/time.Second]++
+	}
+	return out
+}
+
+func meanVar(xs []int) (float64, float64) {
+	var sum, sq float64
+	for _, x := range xs {
+		sum += float64(x)
+	}
+	mean := sum / float64(len(xs))
+	for _, x := range xs {
+		sq += (float64(x) - mean) * (float64(x) - mean)
+	}
+	return mean, sq / float64(len(xs))
+}
+
+func TestArrivalRates(t *testing.T) {
+	// A Poisson count has variance equal to its mean; bursts inflate it.
+	mean, variance := meanVar(counts(Poisson{Rate: 50}, 5000, 1))
+	if math.Abs(mean-50) > 1 {
+		t.Errorf("poisson: expected 50 arrivals per second, got %.2f", mean)
+	}
+	if r := variance / mean; r < 0.9 || r > 1.1 {
+		t.Errorf("poisson: expected dispersion near 1, got %.2f", r)
+	}
+
+	onOff := &OnOff{OnRate: 200, On: 2 * time.Second, Off: 6 * time.Second}
+	mean, variance = meanVar(counts(onOff, 20000, 2))
+	if math.Abs(mean-50) > 5 {
+		t.Errorf("on-off: expected 50 arrivals per second on average, got %.2f", mean)
+	}
+	if r := variance / mean; r < 10 {
+		t.Errorf("on-off: expected dispersion far above 1, got %.2f", r)
+	}
+
+	if got := counts(Periodic{Interval: 250 * time.Millisecond}, 10, 3); !slices.Equal(got, []int{3, 4, 4, 4, 4, 4, 4, 4, 4, 4}) {
+		t.Errorf("periodic: expected 4 per second after the first, got %v", got)
+	}
+
+	d := Diurnal{Base: 100, Amplitude: 80, Period: 100 * time.Second}
+	c := counts(d, 1000, 4)
+	var peak, trough int
+	for i, n := range c {
+		switch i % 100 {
+		case 25:
+			peak += n
+		case 75:
+			trough += n
+		}
+	}
+	if peak < 1500 || peak > 2100 || trough < 100 || trough > 300 {
+		t.Errorf("diurnal: expected about 1800 arrivals at peaks and 200 at troughs, got %d and %d", peak, trough)
+	}
+}
+
+func TestGenerateIsReproducible(t *testing.T) {
+	clients := func() []Client {
+		return []Client{
+			{Name: "steady", Arrivals: Poisson{Rate: 20}, Keys: UniformKeys("u", 100)},
+			{Name: "bursty", Arrivals: &OnOff{OnRate: 100, On: time.Second, Off: 3 * time.Second}, Keys: ZipfKeys("z", 1000, 1.2)},
+		}
+	}
+	a := Generate(epoch, time.Minute, 7, clients()...)
+	b := Generate(epoch, time.Minute, 7, clients()...)
+	if !slices.Equal(a, b) {
+		t.Fatal("expected the same seed to generate the same requests")
+	}
+	if c := Generate(epoch, time.Minute, 8, clients()...); slices.Equal(a, c) {
+		t.Error("expected a different seed to generate different requests")
+	}
+	byClient := map[string]int{}
+	for i, r := range a {
+		if i > 0 && r.At.Before(a[i-1].At) {
+			t.Fatalf("expected requests in time order at %d", i)
+		}
+		if r.At.Before(epoch) || r.At.After(epoch.Add(time.Minute)) {
+			t.Fatalf("expected requests within the minute, got %v", r.At)
+		}
+		byClient[r.Client]++
+	}
+	if byClient["steady"] < 1000 || byClient["steady"] > 1400 || byClient["bursty"] == 0 {
+		t.Errorf("expected about 1200 steady requests and some bursty ones, got %v", byClient)
+	}
+
+	w := Workload("sim", a)
+	if len(w.Operations) != len(a) || w.Operations[0].Key != a[0].Key || w.OperationWeights["get"] != 1 {
+		t.Errorf("expected one get per request in order, got %d operations", len(w.Operations))
+	}
+}
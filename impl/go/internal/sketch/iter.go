@@ -0,0 +1,18 @@
+//go:build go1.23
+
+package sketch
+
+import "iter"
+
+// All returns an iterator over the monitored keys and their estimated
+// frequencies in unspecified order. Use Top for the keys ranked, with their
+// error bounds.
+func (t *TopK) All() iter.Seq2[string, uint64] {
+	return func(yield func(string, uint64) bool) {
+		for _, c := range t.counters {
+			if !yield(c.key, c.count) {
+				return
+			}
+		}
+	}
+}
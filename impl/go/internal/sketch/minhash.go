@@ -0,0 +1,151 @@
+// Package sketch provides compact probabilistic summaries of large data sets:
+// fixed-size signatures and counters that answer similarity and frequency
+// questions approximately, with known error bounds, in a fraction of the
+// memory an exact answer needs.
+package sketch
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"math/rand"
+	"strings"
+
+	"github.com/cespare/xxhash/v2"
+
+	"github.com/dsa-lab/go/internal/errs"
+)
+
+// mersenne61 is the prime 2^61 - 1 used as the modulus of the hash family.
+const mersenne61 = 1<<61 - 1
+
+// ErrIncompatible is returned when comparing sketches built with different
+// parameters.
+var ErrIncompatible = errors.New("sketch: incompatible sketches")
+
+// MinHash is a signature of a set whose agreement with another signature
+// estimates the Jaccard similarity |A ∩ B| / |A ∪ B| of the two sets.
+//
+// Each of the k slots keeps the minimum of one hash function over the set.
+// Two sets share a slot's minimum exactly when the element minimizing that
+// hash over A ∪ B lies in A ∩ B, which happens with probability equal to the
+// Jaccard similarity. The estimate's standard error is sqrt(J(1-J)/k).
+type MinHash struct {
+	seed int64
+	a, b []uint64
+	sig  []uint64
+}
+
+// NewMinHash creates an empty signature with k hash functions drawn from
+// seed. Signatures are comparable only when built with the same k and seed.
+// It returns an error matching errs.ErrInvalidOption if k < 1.
+func NewMinHash(k int, seed int64) (*MinHash, error) {
+	if k < 1 {
+		return nil, fmt.Errorf("sketch: NewMinHash(%d): %w", k, errs.ErrInvalidOption)
+	}
+	rng := rand.New(rand.NewSource(seed))
+	m := &MinHash{seed: seed, a: make([]uint64, k), b: make([]uint64, k), sig: make([]uint64, k)}
+	for i := range m.sig {
+		// h_i(x) = (a_i x + b_i) mod p with a_i != 0 is a universal family
+		// over the 61-bit hash values.
+		m.a[i] = 1 + uint64(rng.Int63n(mersenne61-1))
+		m.b[i] = uint64(rng.Int63n(mersenne61))
+		m.sig[i] = math.MaxUint64
+	}
+	return m, nil
+}
+
+// MustNewMinHash is like NewMinHash but panics if k < 1.
+func MustNewMinHash(k int, seed int64) *MinHash {
+	return errs.Must(NewMinHash(k, seed))
+}
+
+// mulAddMod returns (a*x + b) mod 2^61-1.
+func mulAddMod(a, x, b uint64) uint64 {
+	hi, lo := bits.Mul64(a, x)
+	// a*x = hi*2^64 + lo; with 2^61 ≡ 1, 2^64 ≡ 8 (mod p).
+	r := (lo & mersenne61) + (lo >> 61) + (hi << 3)
+	r = (r & mersenne61) + (r >> 61)
+	r += b
+	r = (r & mersenne61) + (r >> 61)
+	if r >= mersenne61 {
+		r -= mersenne61
+	}
+	return r
+}
+
+// Add adds an element to the set.
+func (m *MinHash) Add(element string) {
+	x := xxhash.Sum64String(element) & mersenne61
+	for i := range m.sig {
+		if h := mulAddMod(m.a[i], x, m.b[i]); h < m.sig[i] {
+			m.sig[i] = h
+		}
+	}
+}
+
+// Signature returns the k slot minimums.
+func (m *MinHash) Signature() []uint64 {
+	return append([]uint64(nil), m.sig...)
+}
+
+// Merge folds other into m, so m becomes the signature of the union of the
+// two sets.
+func (m *MinHash) Merge(other *MinHash) error {
+	if !m.compatible(other) {
+		return ErrIncompatible
+	}
+	for i, v := range other.sig {
+		if v < m.sig[i] {
+			m.sig[i] = v
+		}
+	}
+	return nil
+}
+
+// Jaccard estimates the Jaccard similarity between the sets summarized by m
+// and other.
+func (m *MinHash) Jaccard(other *MinHash) (float64, error) {
+	if !m.compatible(other) {
+		return 0, ErrIncompatible
+	}
+	equal := 0
+	for i, v := range m.sig {
+		if v == other.sig[i] {
+			equal++
+		}
+	}
+	return float64(equal) / float64(len(m.sig)), nil
+}
+
+func (m *MinHash) compatible(other *MinHash) bool {
+	return m.seed == other.seed && len(m.sig) == len(other.sig)
+}
+
+// Shingles returns the distinct word n-grams of text, the usual way to turn
+// a document into a set for MinHash. Words are split on whitespace and
+// lower-cased. A text shorter than n words yields a single shingle. An n
+// below 1 is treated as 1, so the shingles are the distinct words.
+func Shingles(text string, n int) []string {
+	words := strings.Fields(strings.ToLower(text))
+	if n < 1 {
+		n = 1
+	}
+	if len(words) < n {
+		if len(words) == 0 {
+			return nil
+		}
+		return []string{strings.Join(words, " ")}
+	}
+	seen := make(map[string]bool)
+	var shingles []string
+	for i := 0; i+n <= len(words); i++ {
+		s := strings.Join(words[i:i+n], " ")
+		if !seen[s] {
+			seen[s] = true
+			shingles = append(shingles, s)
+		}
+	}
+	return shingles
+}
@@ -0,0 +1,130 @@
+package sketch
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/dsa-lab/go/internal/errs"
+)
+
+// P2 estimates a single quantile of a stream in constant memory using the P²
+// algorithm (Jain and Chlamtac, 1985). It keeps five markers: the minimum,
+// the maximum, the target quantile and two midpoints. Each observation shifts
+// the markers' positions, and a marker that drifts from its ideal position is
+// moved one step, adjusting its height by piecewise-parabolic interpolation.
+// No samples are stored, so the estimate is approximate; it converges for
+// smooth distributions but can lag on heavily discrete ones.
+type P2 struct {
+	q       float64
+	count   int
+	heights [5]float64 // marker heights, ascending
+	pos     [5]float64 // actual marker positions, 1-based
+	want    [5]float64 // desired marker positions
+	step    [5]float64 // increments of want per observation
+}
+
+// NewP2 creates an estimator for quantile q. It returns an error matching
+// errs.ErrInvalidOption unless q lies in (0, 1).
+func NewP2(q float64) (*P2, error) {
+	if !(q > 0 && q < 1) {
+		return nil, fmt.Errorf("sketch: P2 quantile %v not in (0, 1): %w", q, errs.ErrInvalidOption)
+	}
+	return &P2{
+		q:    q,
+		step: [5]float64{0, q / 2, q, (1 + q) / 2, 1},
+	}, nil
+}
+
+// MustNewP2 is like NewP2 but panics if q is outside (0, 1).
+func MustNewP2(q float64) *P2 {
+	return errs.Must(NewP2(q))
+}
+
+// Add records one observation.
+func (p *P2) Add(x float64) {
+	if p.count < 5 {
+		p.heights[p.count] = x
+		p.count++
+		if p.count == 5 {
+			sort.Float64s(p.heights[:])
+			for i := range p.pos {
+				p.pos[i] = float64(i + 1)
+				p.want[i] = 1 + 4*p.step[i]
+			}
+		}
+		return
+	}
+	p.count++
+
+	// Find the cell k with heights[k] <= x < heights[k+1], widening the
+	// extremes when x falls outside them.
+	var k int
+	switch {
+	case x < p.heights[0]:
+		p.heights[0] = x
+		k = 0
+	case x >= p.heights[4]:
+		p.heights[4] = x
+		k = 3
+	default:
+		for k = 0; k < 3 && x >= p.heights[k+1]; k++ {
+		}
+	}
+	for i := k + 1; i < 5; i++ {
+		p.pos[i]++
+	}
+	for i := range p.want {
+		p.want[i] += p.step[i]
+	}
+
+	for i := 1; i <= 3; i++ {
+		d := p.want[i] - p.pos[i]
+		if (d >= 1 && p.pos[i+1]-p.pos[i] > 1) || (d <= -1 && p.pos[i-1]-p.pos[i] < -1) {
+			sign := math.Copysign(1, d)
+			h := p.parabolic(i, sign)
+			if p.heights[i-1] < h && h < p.heights[i+1] {
+				p.heights[i] = h
+			} else {
+				p.heights[i] = p.linear(i, sign)
+			}
+			p.pos[i] += sign
+		}
+	}
+}
+
+func (p *P2) parabolic(i int, d float64) float64 {
+	n, h := p.pos, p.heights
+	return h[i] + d/(n[i+1]-n[i-1])*
+		((n[i]-n[i-1]+d)*(h[i+1]-h[i])/(n[i+1]-n[i])+
+			(n[i+1]-n[i]-d)*(h[i]-h[i-1])/(n[i]-n[i-1]))
+}
+
+func (p *P2) linear(i int, d float64) float64 {
+	j := i + int(d)
+	return p.heights[i] + d*(p.heights[j]-p.heights[i])/(p.pos[j]-p.pos[i])
+}
+
+// Quantile returns the current estimate, exact while fewer than five
+// observations have been seen. It returns NaN for an empty stream.
+func (p *P2) Quantile() float64 {
+	if p.count == 0 {
+		return math.NaN()
+	}
+	if p.count < 5 {
+		sorted := append([]float64(nil), p.heights[:p.count]...)
+		sort.Float64s(sorted)
+		return sorted[int(p.q*float64(p.count-1)+0.5)]
+	}
+	return p.heights[2]
+}
+
+// Count returns the number of observations.
+func (p *P2) Count() int {
+	return p.count
+}
+
+// Q returns the quantile being estimated.
+func (p *P2) Q() float64 {
+	return p.q
+}
@@ -0,0 +1,62 @@
+package sketch
+
+import (
+	"math"
+	"math/bits"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+// SimHash computes a 64-bit fingerprint of a weighted feature set such that
+// similar sets get fingerprints that differ in few bits (Charikar, 2002).
+//
+// Every feature hashes to 64 pseudo-random ±1 coordinates; bit i of the
+// fingerprint is the sign of the weighted sum of coordinate i over all
+// features. Each bit is a random-hyperplane test, so two fingerprints differ
+// in a bit with probability θ/π, where θ is the angle between the feature
+// vectors. Features with non-positive weight are ignored.
+func SimHash(weights map[string]float64) uint64 {
+	var sums [64]float64
+	for feature, w := range weights {
+		if w <= 0 {
+			continue
+		}
+		h := xxhash.Sum64String(feature)
+		for i := range sums {
+			if h&(1<<i) != 0 {
+				sums[i] += w
+			} else {
+				sums[i] -= w
+			}
+		}
+	}
+
+	var fp uint64
+	for i, s := range sums {
+		if s > 0 {
+			fp |= 1 << i
+		}
+	}
+	return fp
+}
+
+// SimHashTokens fingerprints a token stream, weighting each distinct token by
+// its number of occurrences.
+func SimHashTokens(tokens []string) uint64 {
+	counts := make(map[string]float64, len(tokens))
+	for _, t := range tokens {
+		counts[t]++
+	}
+	return SimHash(counts)
+}
+
+// HammingDistance returns the number of bits in which a and b differ.
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// SimHashCosine estimates the cosine similarity of the feature vectors behind
+// two fingerprints, as cos(π · distance / 64).
+func SimHashCosine(a, b uint64) float64 {
+	return math.Cos(math.Pi * float64(HammingDistance(a, b)) / 64)
+}
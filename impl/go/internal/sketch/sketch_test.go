@@ -0,0 +1,496 @@
+package sketch
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/errs"
+)
+
+// documents generates a base document and variants of it in which a growing
+// fraction of words has been replaced, giving pairs that range from
+// near-duplicates to unrelated texts.
+func documents(rng *rand.Rand, variants int) (base string, docs []string) {
+	vocab := make([]string, 2000)
+	for i := range vocab {
+		vocab[i] = fmt.Sprintf("w%d", i)
+	}
+	words := make([]string, 300)
+	for i := range words {
+		words[i] = vocab[rng.Intn(len(vocab))]
+	}
+	for v := 0; v < variants; v++ {
+		fraction := float64(v) / float64(variants-1)
+		variant := append([]string(nil), words...)
+		for i := range variant {
+			if rng.Float64() < fraction {
+				variant[i] = vocab[rng.Intn(len(vocab))]
+			}
+		}
+		docs = append(docs, strings.Join(variant, " "))
+	}
+	return strings.Join(words, " "), docs
+}
+
+func exactJaccard(a, b []string) float64 {
+	set := make(map[string]int)
+	for _, s := range a {
+		set[s] |= 1
+	}
+	for _, s := range b {
+		set[s] |= 2
+	}
+	both := 0
+	for _, v := range set {
+		if v == 3 {
+			both++
+		}
+	}
+	return float64(both) / float64(len(set))
+}
+
+func termVector(doc string) map[string]float64 {
+	v := make(map[string]float64)
+	for _, w := range strings.Fields(doc) {
+		v[w]++
+	}
+	return v
+}
+
+func exactCosine(a, b map[string]float64) float64 {
+	var dot, na, nb float64
+	for k, x := range a {
+		dot += x * b[k]
+		na += x * x
+	}
+	for _, y := range b {
+		nb += y * y
+	}
+	return dot / math.Sqrt(na*nb)
+}
+
+func TestMulAddMod(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	p := new(big.Int).SetUint64(mersenne61)
+	for i := 0; i < 10000; i++ {
+		a := 1 + uint64(rng.Int63n(mersenne61-1))
+		x := uint64(rng.Int63n(mersenne61))
+		b := uint64(rng.Int63n(mersenne61))
+		if i == 0 {
+			a, x, b = mersenne61-1, mersenne61-1, mersenne61-1
+		}
+		want := new(big.Int).SetUint64(a)
+		want.Mul(want, new(big.Int).SetUint64(x))
+		want.Add(want, new(big.Int).SetUint64(b))
+		want.Mod(want, p)
+		if got := mulAddMod(a, x, b); got != want.Uint64() {
+			t.Fatalf("(%d*%d+%d) mod p: expected %d, got %d", a, x, b, want.Uint64(), got)
+		}
+	}
+}
+
+func TestMinHashEstimatesJaccard(t *testing.T) {
+	const k = 256
+	rng := rand.New(rand.NewSource(7))
+	totalErr := 0.0
+	pairs := 0
+	for trial := 0; trial < 5; trial++ {
+		base, docs := documents(rng, 11)
+		baseShingles := Shingles(base, 3)
+		baseSig := MustNewMinHash(k, 42)
+		for _, s := range baseShingles {
+			baseSig.Add(s)
+		}
+
+		for _, doc := range docs {
+			shingles := Shingles(doc, 3)
+			sig := MustNewMinHash(k, 42)
+			for _, s := range shingles {
+				sig.Add(s)
+			}
+			want := exactJaccard(baseShingles, shingles)
+			got, err := baseSig.Jaccard(sig)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Allow four standard errors, plus one slot for rounding.
+			bound := 4*math.Sqrt(want*(1-want)/k) + 1.0/k
+			if math.Abs(got-want) > bound {
+				t.Errorf("expected estimate within %.3f of %.3f, got %.3f", bound, want, got)
+			}
+			totalErr += math.Abs(got - want)
+			pairs++
+		}
+	}
+	if mean := totalErr / float64(pairs); mean > 0.03 {
+		t.Errorf("expected mean absolute error below 0.03, got %.4f", mean)
+	}
+}
+
+func TestMinHashMergeAndCompatibility(t *testing.T) {
+	a, b, union := MustNewMinHash(64, 1), MustNewMinHash(64, 1), MustNewMinHash(64, 1)
+	for i := 0; i < 100; i++ {
+		a.Add(fmt.Sprint(i))
+		union.Add(fmt.Sprint(i))
+	}
+	for i := 50; i < 150; i++ {
+		b.Add(fmt.Sprint(i))
+		union.Add(fmt.Sprint(i))
+	}
+	if err := a.Merge(b); err != nil {
+		t.Fatal(err)
+	}
+	if j, _ := a.Jaccard(union); j != 1 {
+		t.Errorf("expected merged signature to equal the union's, got similarity %v", j)
+	}
+
+	if _, err := a.Jaccard(MustNewMinHash(64, 2)); err != ErrIncompatible {
+		t.Errorf("expected ErrIncompatible for different seeds, got %v", err)
+	}
+	if err := a.Merge(MustNewMinHash(32, 1)); err != ErrIncompatible {
+		t.Errorf("expected ErrIncompatible for different sizes, got %v", err)
+	}
+}
+
+func TestShingles(t *testing.T) {
+	got := Shingles("The cat sat on the cat sat", 2)
+	want := []string{"the cat", "cat sat", "sat on", "on the"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	if got := Shingles("one two", 3); len(got) != 1 || got[0] != "one two" {
+		t.Errorf("expected a short text to yield one shingle, got %q", got)
+	}
+	if got := Shingles("   ", 3); got != nil {
+		t.Errorf("expected no shingles for empty text, got %q", got)
+	}
+	if got := Shingles("a b a", 0); fmt.Sprint(got) != "[a b]" {
+		t.Errorf("expected n < 1 to yield the distinct words, got %q", got)
+	}
+}
+
+func TestSimHashEstimatesCosine(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	var totalErr float64
+	var nearDistance, farDistance, pairs int
+	for trial := 0; trial < 20; trial++ {
+		base, docs := documents(rng, 11)
+		baseVec := termVector(base)
+		baseFP := SimHash(baseVec)
+		for i, doc := range docs {
+			vec := termVector(doc)
+			fp := SimHash(vec)
+
+			angle := math.Acos(math.Min(1, exactCosine(baseVec, vec)))
+			estimated := math.Pi * float64(HammingDistance(baseFP, fp)) / 64
+			totalErr += math.Abs(estimated - angle)
+			pairs++
+
+			switch i {
+			case 1:
+				nearDistance += HammingDistance(baseFP, fp)
+			case len(docs) - 1:
+				farDistance += HammingDistance(baseFP, fp)
+			}
+		}
+	}
+
+	// One 64-bit fingerprint has a standard error of about 0.2 radians per
+	// pair; averaged over many pairs the estimate should be close.
+	if mean := totalErr / float64(pairs); mean > 0.15 {
+		t.Errorf("expected mean angular error below 0.15 rad, got %.3f", mean)
+	}
+	if nearDistance*3 > farDistance {
+		t.Errorf("expected near-duplicates to be much closer than unrelated documents: %d vs %d bits in total", nearDistance, farDistance)
+	}
+}
+
+func TestSimHashBasics(t *testing.T) {
+	doc := strings.Fields("to be or not to be")
+	if SimHashTokens(doc) != SimHash(map[string]float64{"to": 2, "be": 2, "or": 1, "not": 1}) {
+		t.Error("expected token counts to act as weights")
+	}
+	if got := SimHashCosine(42, 42); got != 1 {
+		t.Errorf("expected identical fingerprints to have cosine 1, got %v", got)
+	}
+	if got := SimHashCosine(0, math.MaxUint64); got != -1 {
+		t.Errorf("expected complementary fingerprints to have cosine -1, got %v", got)
+	}
+	if SimHash(map[string]float64{"ignored": -1}) != 0 {
+		t.Error("expected non-positive weights to be ignored")
+	}
+}
+
+// zipfCounts draws n keys from a Zipfian distribution and returns the stream
+// together with exact frequencies.
+func zipfCounts(rng *rand.Rand, s float64, keys uint64, n int) ([]string, map[string]uint64) {
+	zipf := rand.NewZipf(rng, s, 1, keys-1)
+	exact := make(map[string]uint64)
+	stream := make([]string, n)
+	for i := range stream {
+		key := fmt.Sprintf("k%d", zipf.Uint64())
+		stream[i] = key
+		exact[key]++
+	}
+	return stream, exact
+}
+
+func TestTopKZipfian(t *testing.T) {
+	const (
+		k      = 100
+		n      = 200000
+		report = 10
+	)
+	for _, s := range []float64{1.1, 1.5} {
+		t.Run(fmt.Sprintf("s=%.1f", s), func(t *testing.T) {
+			stream, exact := zipfCounts(rand.New(rand.NewSource(7)), s, 100000, n)
+			tk := MustNewTopK(k)
+			for _, key := range stream {
+				tk.Add(key)
+			}
+			if tk.N() != n {
+				t.Fatalf("expected N %d, got %d", n, tk.N())
+			}
+
+			type kc struct {
+				key   string
+				count uint64
+			}
+			var truth []kc
+			for key, count := range exact {
+				truth = append(truth, kc{key, count})
+			}
+			sort.Slice(truth, func(i, j int) bool { return truth[i].count > truth[j].count })
+
+			bound := uint64(n / k)
+			for _, h := range tk.Top(k) {
+				actual := exact[h.Key]
+				if h.Count < actual || h.Count-h.Error > actual {
+					t.Errorf("%s: true count %d outside [%d, %d]", h.Key, actual, h.Count-h.Error, h.Count)
+				}
+				if h.Error > bound {
+					t.Errorf("%s: expected error at most N/k = %d, got %d", h.Key, bound, h.Error)
+				}
+			}
+
+			// Every key more frequent than N/k must be monitored.
+			for _, e := range truth {
+				if e.count <= bound {
+					break
+				}
+				if _, _, ok := tk.Count(e.key); !ok {
+					t.Errorf("expected heavy hitter %s (%d) to be monitored", e.key, e.count)
+				}
+			}
+
+			// Guaranteed keys must belong to the true top n, allowing for
+			// ties at the boundary.
+			top := tk.Top(report)
+			cutoff := truth[report-1].count
+			guaranteed := 0
+			for i, h := range top {
+				actual := exact[h.Key]
+				if h.Guaranteed {
+					guaranteed++
+					if actual < cutoff {
+						t.Errorf("guaranteed key %s has count %d below top-%d cutoff %d", h.Key, actual, report, cutoff)
+					}
+				}
+				t.Logf("#%d %s estimated=%d guaranteed>=%d exact=%d true-rank-count=%d guaranteed=%v",
+					i+1, h.Key, h.Count, h.Count-h.Error, actual, truth[i].count, h.Guaranteed)
+			}
+			if guaranteed == 0 {
+				t.Errorf("expected some guaranteed heavy hitters on a skewed stream")
+			}
+		})
+	}
+}
+
+func TestTopKBasics(t *testing.T) {
+	tk := MustNewTopK(2)
+	tk.AddN("a", 5)
+	tk.Add("b")
+	tk.Add("c") // evicts b, inherits its count of 1
+
+	if c, e, ok := tk.Count("a"); !ok || c != 5 || e != 0 {
+		t.Errorf("expected a=5 err 0, got %d err %d (%v)", c, e, ok)
+	}
+	if c, e, ok := tk.Count("c"); !ok || c != 2 || e != 1 {
+		t.Errorf("expected c=2 err 1, got %d err %d (%v)", c, e, ok)
+	}
+	if _, e, ok := tk.Count("b"); ok || e != 2 {
+		t.Errorf("expected b unmonitored with bound 2, got bound %d (%v)", e, ok)
+	}
+
+	top := tk.Top(5)
+	if len(top) != 2 || top[0].Key != "a" || top[1].Key != "c" {
+		t.Fatalf("expected [a c], got %+v", top)
+	}
+	if !top[0].Guaranteed {
+		t.Errorf("expected a to be guaranteed")
+	}
+	if top[1].Guaranteed {
+		t.Errorf("expected c not to be guaranteed")
+	}
+
+	tk.AddN("z", 0)
+	if tk.N() != 7 {
+		t.Errorf("expected N 7, got %d", tk.N())
+	}
+}
+
+func TestP2MatchesExactQuantiles(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	distributions := []struct {
+		name string
+		draw func() float64
+	}{
+		{"uniform", func() float64 { return rng.Float64() * 1000 }},
+		{"normal", func() float64 { return rng.NormFloat64()*50 + 500 }},
+		{"exponential", func() float64 { return rng.ExpFloat64() * 100 }},
+	}
+	for _, d := range distributions {
+		for _, q := range []float64{0.5, 0.9, 0.99} {
+			p := MustNewP2(q)
+			samples := make([]float64, 100_000)
+			for i := range samples {
+				samples[i] = d.draw()
+				p.Add(samples[i])
+			}
+			sort.Float64s(samples)
+			// Compare ranks rather than values so the bound does not depend
+			// on the distribution's scale.
+			rank := float64(sort.SearchFloat64s(samples, p.Quantile())) / float64(len(samples))
+			if math.Abs(rank-q) > 0.005 {
+				t.Errorf("%s q=%v: estimate %.2f has rank %.4f", d.name, q, p.Quantile(), rank)
+			}
+		}
+	}
+}
+
+func TestP2SmallStreams(t *testing.T) {
+	p := MustNewP2(0.5)
+	if !math.IsNaN(p.Quantile()) {
+		t.Errorf("expected NaN for an empty stream, got %v", p.Quantile())
+	}
+	for _, x := range []float64{5, 1, 3} {
+		p.Add(x)
+	}
+	if p.Quantile() != 3 || p.Count() != 3 {
+		t.Errorf("expected exact median 3 of 3 samples, got %v", p.Quantile())
+	}
+}
+
+// rankOf returns the fraction of sorted samples below x.
+func rankOf(sorted []float64, x float64) float64 {
+	return float64(sort.SearchFloat64s(sorted, x)) / float64(len(sorted))
+}
+
+func TestTDigestMatchesExactQuantiles(t *testing.T) {
+	rng := rand.New(rand.NewSource(5))
+	distributions := []struct {
+		name string
+		draw func() float64
+	}{
+		{"uniform", func() float64 { return rng.Float64() * 1000 }},
+		{"normal", func() float64 { return rng.NormFloat64()*50 + 500 }},
+		{"exponential", func() float64 { return rng.ExpFloat64() * 100 }},
+		{"lognormal", func() float64 { return math.Exp(rng.NormFloat64() * 2) }},
+	}
+	for _, d := range distributions {
+		td := MustNewTDigest(0)
+		samples := make([]float64, 200_000)
+		for i := range samples {
+			samples[i] = d.draw()
+			td.Add(samples[i])
+		}
+		sort.Float64s(samples)
+
+		for _, q := range []float64{0.001, 0.01, 0.1, 0.5, 0.9, 0.99, 0.999} {
+			// The arcsine scale bounds rank error roughly in proportion to
+			// sqrt(q(1-q)), so tails are held to a tighter bound.
+			bound := 0.02*math.Sqrt(q*(1-q)) + 0.0002
+			if rank := rankOf(samples, td.Quantile(q)); math.Abs(rank-q) > bound {
+				t.Errorf("%s q=%v: estimate has rank %.5f, bound %.5f", d.name, q, rank, bound)
+			}
+		}
+		if td.Quantile(0) != samples[0] || td.Quantile(1) != samples[len(samples)-1] {
+			t.Errorf("%s: expected exact extremes", d.name)
+		}
+		if c := td.Centroids(); c > 2*DefaultCompression {
+			t.Errorf("%s: expected at most %d centroids, got %d", d.name, 2*DefaultCompression, c)
+		}
+	}
+}
+
+func TestTDigestMerge(t *testing.T) {
+	rng := rand.New(rand.NewSource(6))
+	whole := MustNewTDigest(0)
+	parts := []*TDigest{MustNewTDigest(0), MustNewTDigest(0), MustNewTDigest(0), MustNewTDigest(0)}
+	var samples []float64
+	for i := 0; i < 100_000; i++ {
+		// Give each part a different range so the merge must interleave.
+		x := rng.ExpFloat64()*10 + float64(i%4)*5
+		samples = append(samples, x)
+		whole.Add(x)
+		parts[i%4].Add(x)
+	}
+	merged := MustNewTDigest(0)
+	for _, p := range parts {
+		merged.Merge(p)
+	}
+	sort.Float64s(samples)
+
+	if merged.Count() != whole.Count() || merged.Min() != whole.Min() || merged.Max() != whole.Max() {
+		t.Fatalf("expected merged count and extremes to match, got %v/%v", merged.Count(), whole.Count())
+	}
+	for _, q := range []float64{0.01, 0.5, 0.99, 0.999} {
+		bound := 0.02*math.Sqrt(q*(1-q)) + 0.0002
+		if rank := rankOf(samples, merged.Quantile(q)); math.Abs(rank-q) > bound {
+			t.Errorf("merged q=%v: estimate has rank %.5f", q, rank)
+		}
+	}
+	if parts[0].Count() != 25_000 {
+		t.Errorf("expected merge to leave its argument unchanged, got count %v", parts[0].Count())
+	}
+}
+
+func TestTDigestSmall(t *testing.T) {
+	td := MustNewTDigest(0)
+	if !math.IsNaN(td.Quantile(0.5)) {
+		t.Error("expected NaN for an empty digest")
+	}
+	td.Add(7)
+	if td.Quantile(0.5) != 7 {
+		t.Errorf("expected 7, got %v", td.Quantile(0.5))
+	}
+	td.Add(1)
+	td.AddWeighted(4, 0)
+	td.Add(math.NaN())
+	if td.Count() != 2 || td.Quantile(0) != 1 || td.Quantile(1) != 7 {
+		t.Errorf("expected two values 1..7, got count %v", td.Count())
+	}
+}
+
+func TestConstructorsRejectInvalid(t *testing.T) {
+	checks := map[string]error{}
+	_, checks["NewMinHash(0)"] = NewMinHash(0, 1)
+	_, checks["NewTopK(0)"] = NewTopK(0)
+	_, checks["NewTopK(-1)"] = NewTopK(-1)
+	_, checks["NewTDigest(-1)"] = NewTDigest(-1)
+	_, checks["NewTDigest(NaN)"] = NewTDigest(math.NaN())
+	_, checks["NewP2(0)"] = NewP2(0)
+	_, checks["NewP2(1)"] = NewP2(1)
+	for name, err := range checks {
+		if !errors.Is(err, errs.ErrInvalidOption) {
+			t.Errorf("%s: expected ErrInvalidOption, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package sketch
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/dsa-lab/go/internal/errs"
+)
+
+// DefaultCompression is the t-digest compression used by NewTDigest when
+// given zero. It keeps on the order of 100 centroids and
+// estimates the 99th and 99.9th percentiles to within a fraction of a
+// percentile.
+const DefaultCompression = 100
+
+type centroid struct {
+	mean  float64
+	count float64
+}
+
+// TDigest summarizes a stream of values for quantile queries using the
+// merging t-digest (Dunning and Ertl, 2019). Values are clustered into
+// centroids whose allowed weight shrinks toward both ends of the distribution,
+// under the arcsine scale function, so tail quantiles such as p99.9 stay
+// accurate while the middle is summarized coarsely. Digests of disjoint
+// streams merge into a digest of their union, so per-worker summaries can be
+// combined.
+type TDigest struct {
+	compression float64
+	centroids   []centroid // merged, sorted by mean
+	buffer      []centroid // unmerged additions
+	total       float64    // weight of centroids and buffer together
+	min, max    float64
+}
+
+// NewTDigest creates an empty digest with the given compression; larger
+// values keep more centroids and give more accurate quantiles. A compression
+// of zero selects DefaultCompression; a negative or NaN one is an error
+// matching errs.ErrInvalidOption.
+func NewTDigest(compression float64) (*TDigest, error) {
+	if compression == 0 {
+		compression = DefaultCompression
+	}
+	if !(compression > 0) {
+		return nil, fmt.Errorf("sketch: NewTDigest(%v): %w", compression, errs.ErrInvalidOption)
+	}
+	return &TDigest{
+		compression: compression,
+		min:         math.Inf(1),
+		max:         math.Inf(-1),
+	}, nil
+}
+
+// MustNewTDigest is like NewTDigest but panics on an invalid compression.
+func MustNewTDigest(compression float64) *TDigest {
+	return errs.Must(NewTDigest(compression))
+}
+
+// Add records one value.
+func (t *TDigest) Add(x float64) {
+	t.AddWeighted(x, 1)
+}
+
+// AddWeighted records a value seen w times. Non-positive weights and NaN are
+// ignored.
+func (t *TDigest) AddWeighted(x, w float64) {
+	if w <= 0 || math.IsNaN(x) {
+		return
+	}
+	t.buffer = append(t.buffer, centroid{x, w})
+	t.total += w
+	t.min = math.Min(t.min, x)
+	t.max = math.Max(t.max, x)
+	if len(t.buffer) >= 5*int(t.compression) {
+		t.compress()
+	}
+}
+
+// Merge adds every value summarized by other into t. other is unchanged.
+func (t *TDigest) Merge(other *TDigest) {
+	if other.total == 0 {
+		return
+	}
+	t.buffer = append(t.buffer, other.centroids...)
+	t.buffer = append(t.buffer, other.buffer...)
+	t.total += other.total
+	t.min = math.Min(t.min, other.min)
+	t.max = math.Max(t.max, other.max)
+	t.compress()
+}
+
+// scale maps a quantile to the arcsine k-scale; centroids may span at most
+// one unit of k, which makes them narrow near q=0 and q=1.
+func (t *TDigest) scale(q float64) float64 {
+	return t.compression / (2 * math.Pi) * math.Asin(2*q-1)
+}
+
+func (t *TDigest) inverseScale(k float64) float64 {
+	return (math.Sin(k*2*math.Pi/t.compression) + 1) / 2
+}
+
+// compress merges the buffer into the centroids.
+func (t *TDigest) compress() {
+	if len(t.buffer) == 0 {
+		return
+	}
+	all := append(t.centroids, t.buffer...)
+	sort.Slice(all, func(i, j int) bool { return all[i].mean < all[j].mean })
+
+	merged := make([]centroid, 0, int(t.compression))
+	cur := all[0]
+	seen := 0.0
+	limit := t.total * t.inverseScale(t.scale(0)+1)
+	for _, c := range all[1:] {
+		if seen+cur.count+c.count <= limit {
+			cur.count += c.count
+			cur.mean += (c.mean - cur.mean) * c.count / cur.count
+			continue
+		}
+		seen += cur.count
+		merged = append(merged, cur)
+		limit = t.total * t.inverseScale(t.scale(seen/t.total)+1)
+		cur = c
+	}
+	t.centroids = append(merged, cur)
+	t.buffer = t.buffer[:0]
+}
+
+// Quantile returns the estimated q-quantile for q in [0, 1], interpolating
+// between centroid means and the exact minimum and maximum. It returns NaN
+// for an empty digest.
+func (t *TDigest) Quantile(q float64) float64 {
+	t.compress()
+	n := len(t.centroids)
+	switch {
+	case n == 0:
+		return math.NaN()
+	case q <= 0:
+		return t.min
+	case q >= 1:
+		return t.max
+	case n == 1:
+		return t.centroids[0].mean
+	}
+
+	// Each centroid's mean sits at the middle of its weight; interpolate
+	// between neighbouring midpoints, and between the extremes and the first
+	// and last midpoints.
+	index := q * t.total
+	first := t.centroids[0]
+	if index < first.count/2 {
+		return t.min + (first.mean-t.min)*index/(first.count/2)
+	}
+	cum := 0.0
+	for i := 0; i < n-1; i++ {
+		a, b := t.centroids[i], t.centroids[i+1]
+		left := cum + a.count/2
+		right := cum + a.count + b.count/2
+		if index < right {
+			return a.mean + (b.mean-a.mean)*(index-left)/(right-left)
+		}
+		cum += a.count
+	}
+	last := t.centroids[n-1]
+	start := t.total - last.count/2
+	return last.mean + (t.max-last.mean)*(index-start)/(last.count/2)
+}
+
+// Count returns the total weight added.
+func (t *TDigest) Count() float64 {
+	return t.total
+}
+
+// Min returns the smallest value added, or +Inf when empty.
+func (t *TDigest) Min() float64 {
+	return t.min
+}
+
+// Max returns the largest value added, or -Inf when empty.
+func (t *TDigest) Max() float64 {
+	return t.max
+}
+
+// Centroids returns the number of centroids after merging any buffered
+// values, which bounds the digest's memory.
+func (t *TDigest) Centroids() int {
+	t.compress()
+	return len(t.centroids)
+}
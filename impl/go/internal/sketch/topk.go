@@ -0,0 +1,163 @@
+package sketch
+
+import (
+	"container/heap"
+	"fmt"
+	"sort"
+
+	"github.com/dsa-lab/go/internal/errs"
+)
+
+// TopK tracks the most frequent keys of a stream in fixed memory using the
+// Space-Saving algorithm (Metwally, Agrawal and El Abbadi, 2005), a refinement
+// of Misra-Gries that keeps an explicit error bound per key.
+//
+// It monitors at most k keys. A new key, when all k counters are taken,
+// replaces the key with the smallest count c and starts at c+1 with error c.
+// Every estimate therefore overestimates the true frequency by at most its
+// error, and every error is at most N/k for a stream of N items, so any key
+// more frequent than N/k is guaranteed to be monitored.
+type TopK struct {
+	k        int
+	n        uint64
+	counters counterHeap
+	index    map[string]*counter
+}
+
+type counter struct {
+	key   string
+	count uint64
+	err   uint64
+	pos   int
+}
+
+// counterHeap is a min-heap of counters ordered by count.
+type counterHeap []*counter
+
+func (h counterHeap) Len() int           { return len(h) }
+func (h counterHeap) Less(i, j int) bool { return h[i].count < h[j].count }
+func (h counterHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].pos = i
+	h[j].pos = j
+}
+func (h *counterHeap) Push(x any) {
+	c := x.(*counter)
+	c.pos = len(*h)
+	*h = append(*h, c)
+}
+func (h *counterHeap) Pop() any {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
+
+// NewTopK creates a summary that monitors up to k keys. It returns an error
+// matching errs.ErrInvalidOption if k < 1.
+func NewTopK(k int) (*TopK, error) {
+	if k < 1 {
+		return nil, fmt.Errorf("sketch: NewTopK(%d): %w", k, errs.ErrInvalidOption)
+	}
+	return &TopK{k: k, index: make(map[string]*counter, k)}, nil
+}
+
+// MustNewTopK is like NewTopK but panics if k < 1.
+func MustNewTopK(k int) *TopK {
+	return errs.Must(NewTopK(k))
+}
+
+// Add records one occurrence of key.
+func (t *TopK) Add(key string) {
+	t.AddN(key, 1)
+}
+
+// AddN records n occurrences of key.
+func (t *TopK) AddN(key string, n uint64) {
+	if n == 0 {
+		return
+	}
+	t.n += n
+	if c, ok := t.index[key]; ok {
+		c.count += n
+		heap.Fix(&t.counters, c.pos)
+		return
+	}
+	if len(t.counters) < t.k {
+		c := &counter{key: key, count: n}
+		t.index[key] = c
+		heap.Push(&t.counters, c)
+		return
+	}
+
+	// Evict the minimum; the newcomer inherits its count as error.
+	c := t.counters[0]
+	delete(t.index, c.key)
+	c.key, c.err = key, c.count
+	c.count += n
+	t.index[key] = c
+	heap.Fix(&t.counters, 0)
+}
+
+// N returns the total number of occurrences recorded.
+func (t *TopK) N() uint64 {
+	return t.n
+}
+
+// Count returns the estimated frequency of key and its maximum
+// overestimation. The true frequency lies in [estimate-err, estimate]. For an
+// unmonitored key it returns the smallest monitored count as the error bound
+// with an estimate of zero, and false.
+func (t *TopK) Count(key string) (estimate, err uint64, monitored bool) {
+	if c, ok := t.index[key]; ok {
+		return c.count, c.err, true
+	}
+	if len(t.counters) < t.k {
+		return 0, 0, false
+	}
+	return 0, t.counters[0].count, false
+}
+
+// HeavyHitter is a monitored key with its frequency bounds.
+type HeavyHitter struct {
+	Key string
+	// Count is the estimated frequency, never less than the true one.
+	Count uint64
+	// Error bounds the overestimate: the true frequency is at least
+	// Count - Error.
+	Error uint64
+	// Guaranteed is set when the key is certainly among the true top n,
+	// because its lower bound is at least the estimate of the first key
+	// outside the top n.
+	Guaranteed bool
+}
+
+// Top returns up to n monitored keys by decreasing estimated frequency.
+func (t *TopK) Top(n int) []HeavyHitter {
+	sorted := make([]*counter, len(t.counters))
+	copy(sorted, t.counters)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].count != sorted[j].count {
+			return sorted[i].count > sorted[j].count
+		}
+		return sorted[i].key < sorted[j].key
+	})
+
+	if n > len(sorted) {
+		n = len(sorted)
+	}
+	// An unmonitored key may have occurred up to the smallest count's times,
+	// so it bounds the frequency of everything below the top n.
+	var threshold uint64
+	if n < len(sorted) {
+		threshold = sorted[n].count
+	} else if len(sorted) == t.k {
+		threshold = t.counters[0].count
+	}
+
+	top := make([]HeavyHitter, n)
+	for i, c := range sorted[:n] {
+		top[i] = HeavyHitter{Key: c.key, Count: c.count, Error: c.err, Guaranteed: c.count-c.err >= threshold}
+	}
+	return top
+}
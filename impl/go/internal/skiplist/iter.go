@@ -0,0 +1,18 @@
+//go:build go1.23
+
+package skiplist
+
+import "iter"
+
+// All returns an iterator over all key-value pairs in ascending key order.
+func (s *SkipList[K, V]) All() iter.Seq2[K, V] {
+	return s.Range
+}
+
+// Between returns an iterator over the keys in [lo, hi) in ascending order,
+// the iterator form of Scan.
+func (s *SkipList[K, V]) Between(lo, hi K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		s.Scan(lo, hi, yield)
+	}
+}
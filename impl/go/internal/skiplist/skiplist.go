@@ -0,0 +1,419 @@
+// Package skiplist provides an ordered map backed by a probabilistic skip list.
+// Every forward link records its span (the number of level-0 nodes it skips),
+// which makes the list indexable: rank and positional lookups run in
+// O(log n) expected time alongside the usual ordered-map operations.
+package skiplist
+
+import (
+	"cmp"
+	"fmt"
+	"math/rand"
+
+	"github.com/dsa-lab/go/internal/errs"
+	"github.com/dsa-lab/go/internal/order"
+	"github.com/dsa-lab/go/internal/pool"
+	"github.com/dsa-lab/go/internal/txn"
+)
+
+const (
+	maxLevel    = 32
+	probability = 0.25
+	defaultSeed = 1
+)
+
+// link is a forward pointer together with the number of positions it covers.
+type link[K, V any] struct {
+	node *node[K, V]
+	span int
+}
+
+type node[K, V any] struct {
+	key   K
+	value V
+	next  []link[K, V]
+}
+
+// SkipList is an ordered map from K to V with order-statistic queries.
+// It provides O(log n) expected time for insert, get, remove, rank and
+// positional access.
+type SkipList[K, V any] struct {
+	compare order.Comparator[K]
+	head    *node[K, V]
+	level   int
+	length  int
+	rng     *rand.Rand
+
+	// pools recycles removed nodes by level when WithNodePool is set, so a
+	// node's link slice is reused along with it. Entries are created lazily.
+	pools     []*pool.Freelist[node[K, V]]
+	poolLimit int
+
+	// optErr is set by an option given an invalid argument.
+	optErr error
+}
+
+// Option configures a SkipList at construction time.
+type Option[K, V any] func(*SkipList[K, V])
+
+// WithNodePool recycles the nodes of removed keys for later inserts, keeping
+// up to limit idle nodes per level. Churn-heavy workloads then allocate only
+// when the list grows. The limit must not be negative.
+func WithNodePool[K, V any](limit int) Option[K, V] {
+	return func(s *SkipList[K, V]) {
+		if limit < 0 {
+			s.optErr = fmt.Errorf("skiplist: WithNodePool(%d): %w", limit, errs.ErrInvalidOption)
+			return
+		}
+		s.pools = make([]*pool.Freelist[node[K, V]], maxLevel+1)
+		s.poolLimit = limit
+	}
+}
+
+// New creates an empty SkipList ordered by the natural ordering of K. It
+// returns an error matching errs.ErrInvalidOption if an option is given an
+// argument out of range.
+func New[K cmp.Ordered, V any](opts ...Option[K, V]) (*SkipList[K, V], error) {
+	return NewFunc(order.Natural[K](), opts...)
+}
+
+// MustNew is like New but panics if an option is invalid.
+func MustNew[K cmp.Ordered, V any](opts ...Option[K, V]) *SkipList[K, V] {
+	return errs.Must(New(opts...))
+}
+
+// NewFunc creates an empty SkipList ordered by compare. Invalid options are
+// reported as by New.
+func NewFunc[K, V any](compare order.Comparator[K], opts ...Option[K, V]) (*SkipList[K, V], error) {
+	s := &SkipList[K, V]{
+		compare: compare,
+		head:    &node[K, V]{next: make([]link[K, V], maxLevel)},
+		level:   1,
+		rng:     rand.New(rand.NewSource(defaultSeed)),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.optErr != nil {
+		return nil, s.optErr
+	}
+	return s, nil
+}
+
+// MustNewFunc is like NewFunc but panics if an option is invalid.
+func MustNewFunc[K, V any](compare order.Comparator[K], opts ...Option[K, V]) *SkipList[K, V] {
+	return errs.Must(NewFunc(compare, opts...))
+}
+
+// newNode returns a node of the given level, recycled when pooling is on.
+func (s *SkipList[K, V]) newNode(key K, value V, level int) *node[K, V] {
+	if s.pools == nil {
+		return &node[K, V]{key: key, value: value, next: make([]link[K, V], level)}
+	}
+	n := s.levelPool(level).Get()
+	if n.next == nil {
+		n.next = make([]link[K, V], level)
+	}
+	n.key, n.value = key, value
+	return n
+}
+
+// freeNode hands an unlinked node back to its level's freelist.
+func (s *SkipList[K, V]) freeNode(n *node[K, V]) {
+	if s.pools != nil {
+		s.levelPool(len(n.next)).Put(n)
+	}
+}
+
+func (s *SkipList[K, V]) levelPool(level int) *pool.Freelist[node[K, V]] {
+	if s.pools[level] == nil {
+		s.pools[level] = pool.MustNew(
+			pool.WithLimit[node[K, V]](s.poolLimit),
+			pool.WithReset(func(n *node[K, V]) {
+				var zero node[K, V]
+				n.key, n.value = zero.key, zero.value
+				clear(n.next)
+			}),
+		)
+	}
+	return s.pools[level]
+}
+
+// Len returns the number of elements in the list.
+func (s *SkipList[K, V]) Len() int {
+	return s.length
+}
+
+// IsEmpty returns true if the list contains no elements.
+func (s *SkipList[K, V]) IsEmpty() bool {
+	return s.length == 0
+}
+
+func (s *SkipList[K, V]) randomLevel() int {
+	level := 1
+	for level < maxLevel && s.rng.Float64() < probability {
+		level++
+	}
+	return level
+}
+
+// Insert inserts or replaces the value for key.
+// Returns the previous value and true if the key existed, zero value and false otherwise.
+func (s *SkipList[K, V]) Insert(key K, value V) (V, bool) {
+	var update [maxLevel]*node[K, V]
+	var rank [maxLevel]int
+
+	x := s.head
+	for i := s.level - 1; i >= 0; i-- {
+		if i < s.level-1 {
+			rank[i] = rank[i+1]
+		}
+		for x.next[i].node != nil && s.compare(x.next[i].node.key, key) < 0 {
+			rank[i] += x.next[i].span
+			x = x.next[i].node
+		}
+		update[i] = x
+	}
+
+	if n := x.next[0].node; n != nil && s.compare(n.key, key) == 0 {
+		old := n.value
+		n.value = value
+		return old, true
+	}
+
+	level := s.randomLevel()
+	if level > s.level {
+		for i := s.level; i < level; i++ {
+			rank[i] = 0
+			update[i] = s.head
+			s.head.next[i].span = s.length
+		}
+		s.level = level
+	}
+
+	n := s.newNode(key, value, level)
+	for i := 0; i < level; i++ {
+		n.next[i].node = update[i].next[i].node
+		update[i].next[i].node = n
+		n.next[i].span = update[i].next[i].span - (rank[0] - rank[i])
+		update[i].next[i].span = rank[0] - rank[i] + 1
+	}
+	for i := level; i < s.level; i++ {
+		update[i].next[i].span++
+	}
+
+	s.length++
+	var zero V
+	return zero, false
+}
+
+// Get retrieves the value associated with the key.
+func (s *SkipList[K, V]) Get(key K) (V, bool) {
+	x := s.head
+	for i := s.level - 1; i >= 0; i-- {
+		for x.next[i].node != nil && s.compare(x.next[i].node.key, key) < 0 {
+			x = x.next[i].node
+		}
+	}
+	if n := x.next[0].node; n != nil && s.compare(n.key, key) == 0 {
+		return n.value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Contains checks if the list contains the given key.
+func (s *SkipList[K, V]) Contains(key K) bool {
+	_, found := s.Get(key)
+	return found
+}
+
+// Remove removes key from the list.
+// Returns the removed value and true if the key existed, zero value and false otherwise.
+func (s *SkipList[K, V]) Remove(key K) (V, bool) {
+	var update [maxLevel]*node[K, V]
+
+	x := s.head
+	for i := s.level - 1; i >= 0; i-- {
+		for x.next[i].node != nil && s.compare(x.next[i].node.key, key) < 0 {
+			x = x.next[i].node
+		}
+		update[i] = x
+	}
+
+	n := x.next[0].node
+	if n == nil || s.compare(n.key, key) != 0 {
+		var zero V
+		return zero, false
+	}
+
+	for i := 0; i < s.level; i++ {
+		if update[i].next[i].node == n {
+			update[i].next[i].span += n.next[i].span - 1
+			update[i].next[i].node = n.next[i].node
+		} else {
+			update[i].next[i].span--
+		}
+	}
+	for s.level > 1 && s.head.next[s.level-1].node == nil {
+		s.level--
+	}
+
+	s.length--
+	value := n.value
+	s.freeNode(n)
+	return value, true
+}
+
+// Rank returns the zero-based position of key in sort order.
+func (s *SkipList[K, V]) Rank(key K) (int, bool) {
+	rank := 0
+	x := s.head
+	for i := s.level - 1; i >= 0; i-- {
+		for x.next[i].node != nil && s.compare(x.next[i].node.key, key) <= 0 {
+			rank += x.next[i].span
+			x = x.next[i].node
+		}
+		if x != s.head && s.compare(x.key, key) == 0 {
+			return rank - 1, true
+		}
+	}
+	return 0, false
+}
+
+// At returns the key and value at zero-based position index in sort order.
+func (s *SkipList[K, V]) At(index int) (K, V, bool) {
+	if index < 0 || index >= s.length {
+		var zeroK K
+		var zeroV V
+		return zeroK, zeroV, false
+	}
+
+	target := index + 1
+	traversed := 0
+	x := s.head
+	for i := s.level - 1; i >= 0; i-- {
+		for x.next[i].node != nil && traversed+x.next[i].span <= target {
+			traversed += x.next[i].span
+			x = x.next[i].node
+		}
+		if traversed == target {
+			break
+		}
+	}
+	return x.key, x.value, true
+}
+
+// Clear removes all elements from the list.
+func (s *SkipList[K, V]) Clear() {
+	s.head = &node[K, V]{next: make([]link[K, V], maxLevel)}
+	s.level = 1
+	s.length = 0
+}
+
+// Txn starts a batch of writes to s that Commit applies together, in
+// ascending key order.
+func (s *SkipList[K, V]) Txn() *txn.Txn[K, V] {
+	return txn.NewFunc[K, V](s, s.compare)
+}
+
+// Keys returns all keys in ascending order.
+func (s *SkipList[K, V]) Keys() []K {
+	keys := make([]K, 0, s.length)
+	s.Range(func(key K, _ V) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return keys
+}
+
+// Range iterates over all key-value pairs in ascending key order.
+// If f returns false, iteration stops.
+func (s *SkipList[K, V]) Range(f func(key K, value V) bool) {
+	for x := s.head.next[0].node; x != nil; x = x.next[0].node {
+		if !f(x.key, x.value) {
+			return
+		}
+	}
+}
+
+// RangeFrom iterates in ascending order starting at zero-based position start.
+// If f returns false, iteration stops.
+func (s *SkipList[K, V]) RangeFrom(start int, f func(key K, value V) bool) {
+	if start < 0 {
+		start = 0
+	}
+	if start >= s.length {
+		return
+	}
+
+	target := start
+	traversed := 0
+	x := s.head
+	for i := s.level - 1; i >= 0; i-- {
+		for x.next[i].node != nil && traversed+x.next[i].span <= target {
+			traversed += x.next[i].span
+			x = x.next[i].node
+		}
+	}
+	for x = x.next[0].node; x != nil; x = x.next[0].node {
+		if !f(x.key, x.value) {
+			return
+		}
+	}
+}
+
+// before returns the last node whose key sorts before key, or also equal to
+// it when inclusive is set. It returns the head when there is none.
+func (s *SkipList[K, V]) before(key K, inclusive bool) *node[K, V] {
+	limit := 0
+	if inclusive {
+		limit = 1
+	}
+	x := s.head
+	for i := s.level - 1; i >= 0; i-- {
+		for x.next[i].node != nil && s.compare(x.next[i].node.key, key) < limit {
+			x = x.next[i].node
+		}
+	}
+	return x
+}
+
+// entry returns n's key and value, or zero values and false for the head or nil.
+func (s *SkipList[K, V]) entry(n *node[K, V]) (K, V, bool) {
+	if n == nil || n == s.head {
+		var zeroK K
+		var zeroV V
+		return zeroK, zeroV, false
+	}
+	return n.key, n.value, true
+}
+
+// Floor returns the greatest key less than or equal to key.
+func (s *SkipList[K, V]) Floor(key K) (K, V, bool) {
+	return s.entry(s.before(key, true))
+}
+
+// Ceiling returns the least key greater than or equal to key.
+func (s *SkipList[K, V]) Ceiling(key K) (K, V, bool) {
+	return s.entry(s.before(key, false).next[0].node)
+}
+
+// Lower returns the greatest key strictly less than key, its predecessor.
+func (s *SkipList[K, V]) Lower(key K) (K, V, bool) {
+	return s.entry(s.before(key, false))
+}
+
+// Higher returns the least key strictly greater than key, its successor.
+func (s *SkipList[K, V]) Higher(key K) (K, V, bool) {
+	return s.entry(s.before(key, true).next[0].node)
+}
+
+// Scan iterates in ascending order over the keys in the half-open range
+// [lo, hi). If f returns false, iteration stops.
+func (s *SkipList[K, V]) Scan(lo, hi K, f func(key K, value V) bool) {
+	for x := s.before(lo, false).next[0].node; x != nil && s.compare(x.key, hi) < 0; x = x.next[0].node {
+		if !f(x.key, x.value) {
+			return
+		}
+	}
+}
@@ -0,0 +1,299 @@
+package skiplist
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/errs"
+)
+
+func TestInsertGetRemove(t *testing.T) {
+	s := MustNew[string, int]()
+	if !s.IsEmpty() {
+		t.Error("new list should be empty")
+	}
+
+	if _, existed := s.Insert("b", 2); existed {
+		t.Error("first insert should not report existing key")
+	}
+	s.Insert("a", 1)
+	s.Insert("c", 3)
+	if old, existed := s.Insert("b", 20); !existed || old != 2 {
+		t.Errorf("overwrite returned %d, %v", old, existed)
+	}
+
+	if value, found := s.Get("b"); !found || value != 20 {
+		t.Errorf("expected b=20, got %d, %v", value, found)
+	}
+	if s.Len() != 3 {
+		t.Errorf("expected length 3, got %d", s.Len())
+	}
+
+	if removed, existed := s.Remove("a"); !existed || removed != 1 {
+		t.Errorf("remove returned %d, %v", removed, existed)
+	}
+	if s.Contains("a") {
+		t.Error("a should not exist after remove")
+	}
+	if _, existed := s.Remove("missing"); existed {
+		t.Error("remove of missing key should report false")
+	}
+}
+
+func TestOrderAndRank(t *testing.T) {
+	s := MustNew[int, string]()
+	for _, k := range []int{50, 10, 40, 20, 30} {
+		s.Insert(k, "")
+	}
+
+	keys := s.Keys()
+	if !sort.IntsAreSorted(keys) || len(keys) != 5 {
+		t.Errorf("keys not sorted: %v", keys)
+	}
+
+	for i, k := range []int{10, 20, 30, 40, 50} {
+		if rank, found := s.Rank(k); !found || rank != i {
+			t.Errorf("rank(%d) = %d, %v; want %d", k, rank, found, i)
+		}
+		if key, _, found := s.At(i); !found || key != k {
+			t.Errorf("at(%d) = %d, %v; want %d", i, key, found, k)
+		}
+	}
+	if _, found := s.Rank(25); found {
+		t.Error("rank of missing key should report false")
+	}
+	if _, _, found := s.At(5); found {
+		t.Error("at past end should report false")
+	}
+
+	var fromTwo []int
+	s.RangeFrom(2, func(key int, _ string) bool {
+		fromTwo = append(fromTwo, key)
+		return true
+	})
+	if len(fromTwo) != 3 || fromTwo[0] != 30 {
+		t.Errorf("range from 2 returned %v", fromTwo)
+	}
+}
+
+func TestCustomOrdering(t *testing.T) {
+	s := MustNewFunc[int, struct{}](func(a, b int) int { return b - a })
+	for i := 0; i < 10; i++ {
+		s.Insert(i, struct{}{})
+	}
+	if key, _, _ := s.At(0); key != 9 {
+		t.Errorf("descending list should start with 9, got %d", key)
+	}
+}
+
+func TestOracleRandomOperations(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+	s := MustNew[int, int]()
+	ref := make(map[int]int)
+
+	for i := 0; i < 20000; i++ {
+		key := rng.Intn(500)
+		switch rng.Intn(3) {
+		case 0:
+			s.Insert(key, i)
+			ref[key] = i
+		case 1:
+			_, ourExisted := s.Remove(key)
+			_, refExisted := ref[key]
+			if ourExisted != refExisted {
+				t.Fatalf("remove(%d) existed mismatch at iteration %d", key, i)
+			}
+			delete(ref, key)
+		case 2:
+			value, found := s.Get(key)
+			want, wantFound := ref[key]
+			if found != wantFound || value != want {
+				t.Fatalf("get(%d) mismatch at iteration %d", key, i)
+			}
+		}
+	}
+
+	sorted := make([]int, 0, len(ref))
+	for k := range ref {
+		sorted = append(sorted, k)
+	}
+	sort.Ints(sorted)
+
+	if s.Len() != len(sorted) {
+		t.Fatalf("length mismatch: our=%d, ref=%d", s.Len(), len(sorted))
+	}
+	for i, k := range sorted {
+		if rank, found := s.Rank(k); !found || rank != i {
+			t.Errorf("rank(%d) = %d; want %d", k, rank, i)
+		}
+		if key, value, _ := s.At(i); key != k || value != ref[k] {
+			t.Errorf("at(%d) = %d=%d; want %d=%d", i, key, value, k, ref[k])
+		}
+	}
+}
+
+func TestVisualize(t *testing.T) {
+	s := MustNew[int, string]()
+	for i := 1; i <= 20; i++ {
+		s.Insert(i, "")
+	}
+
+	var buf strings.Builder
+	if err := s.Visualize(&buf); err != nil {
+		t.Fatal(err)
+	}
+	dot := buf.String()
+	for _, want := range []string{
+		"digraph skiplist {",
+		`head:l0 -> n0:l0 [label="1"]`,
+		`n0:l0 -> n1:l0 [label="1"]`,
+		"n19:l0 -> nil:l0",
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("expected DOT containing %q, got:\n%s", want, dot)
+		}
+	}
+
+	// Every level of the head has an outgoing link, and spans along the top
+	// level add up to the list length.
+	top := s.level - 1
+	total := 0
+	for _, line := range strings.Split(dot, "\n") {
+		var from, to string
+		var level, toLevel, span int
+		line = strings.NewReplacer(":l", " ", " -> ", " ", " [label=\"", " ", "\"];", "").Replace(strings.TrimSpace(line))
+		if n, _ := fmt.Sscan(line, &from, &level, &to, &toLevel, &span); n == 5 && level == top {
+			total += span
+		}
+	}
+	if total != s.Len() {
+		t.Errorf("expected top-level spans to sum to %d, got %d", s.Len(), total)
+	}
+}
+
+func TestNavigation(t *testing.T) {
+	s := MustNew[int, string]()
+	for _, k := range []int{10, 20, 30, 40} {
+		s.Insert(k, fmt.Sprint(k))
+	}
+
+	tests := []struct {
+		name  string
+		nav   func(int) (int, string, bool)
+		query int
+		want  int
+		found bool
+	}{
+		{"floor exact", s.Floor, 20, 20, true},
+		{"floor between", s.Floor, 25, 20, true},
+		{"floor below", s.Floor, 5, 0, false},
+		{"ceiling exact", s.Ceiling, 20, 20, true},
+		{"ceiling between", s.Ceiling, 25, 30, true},
+		{"ceiling above", s.Ceiling, 45, 0, false},
+		{"lower exact", s.Lower, 20, 10, true},
+		{"lower first", s.Lower, 10, 0, false},
+		{"higher exact", s.Higher, 20, 30, true},
+		{"higher last", s.Higher, 40, 0, false},
+	}
+	for _, tt := range tests {
+		key, value, found := tt.nav(tt.query)
+		if found != tt.found || key != tt.want || (found && value != fmt.Sprint(tt.want)) {
+			t.Errorf("%s(%d) = %d, %q, %v; want %d, %v", tt.name, tt.query, key, value, found, tt.want, tt.found)
+		}
+	}
+
+	var got []int
+	s.Scan(15, 40, func(key int, _ string) bool {
+		got = append(got, key)
+		return true
+	})
+	if len(got) != 2 || got[0] != 20 || got[1] != 30 {
+		t.Errorf("scan [15, 40) returned %v, want [20 30]", got)
+	}
+	got = got[:0]
+	s.Scan(0, 100, func(key int, _ string) bool {
+		got = append(got, key)
+		return len(got) < 3
+	})
+	if len(got) != 3 {
+		t.Errorf("scan should stop after 3 keys, got %v", got)
+	}
+}
+
+func TestNavigationOracle(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	s := MustNew[int, struct{}]()
+	var keys []int
+	for i := 0; i < 300; i++ {
+		k := rng.Intn(1000)
+		if _, existed := s.Insert(k, struct{}{}); !existed {
+			keys = append(keys, k)
+		}
+	}
+	sort.Ints(keys)
+
+	for q := -1; q <= 1001; q++ {
+		i := sort.SearchInts(keys, q) // first index with keys[i] >= q
+		j := sort.SearchInts(keys, q+1)
+		check := func(name string, got int, found bool, idx int) {
+			if wantFound := idx >= 0 && idx < len(keys); found != wantFound || (found && got != keys[idx]) {
+				t.Fatalf("%s(%d) = %d, %v; mismatch with sorted oracle", name, q, got, found)
+			}
+		}
+		k, _, found := s.Ceiling(q)
+		check("ceiling", k, found, i)
+		k, _, found = s.Higher(q)
+		check("higher", k, found, j)
+		k, _, found = s.Lower(q)
+		check("lower", k, found, i-1)
+		k, _, found = s.Floor(q)
+		check("floor", k, found, j-1)
+	}
+}
+
+func TestNodePool(t *testing.T) {
+	s := MustNew(WithNodePool[int, int](64))
+	ref := make(map[int]int)
+	rng := rand.New(rand.NewSource(9))
+	for i := 0; i < 20000; i++ {
+		key := rng.Intn(200)
+		if rng.Intn(2) == 0 {
+			s.Insert(key, i)
+			ref[key] = i
+		} else {
+			value, found := s.Remove(key)
+			want, wantFound := ref[key]
+			if found != wantFound || value != want {
+				t.Fatalf("remove(%d) = %d, %v; want %d, %v", key, value, found, want, wantFound)
+			}
+			delete(ref, key)
+		}
+	}
+	if s.Len() != len(ref) {
+		t.Fatalf("expected len %d, got %d", len(ref), s.Len())
+	}
+	for key, want := range ref {
+		if value, _ := s.Get(key); value != want {
+			t.Errorf("get(%d) = %d, want %d", key, value, want)
+		}
+	}
+
+	// Once warm, removing and reinserting the same key reuses its node.
+	allocs := testing.AllocsPerRun(100, func() {
+		s.Remove(5)
+		s.Insert(5, 5)
+	})
+	if allocs != 0 {
+		t.Errorf("expected no allocations under churn, got %.1f per run", allocs)
+	}
+}
+
+func TestInvalidNodePool(t *testing.T) {
+	if _, err := New(WithNodePool[int, int](-1)); !errors.Is(err, errs.ErrInvalidOption) {
+		t.Errorf("expected ErrInvalidOption, got %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package skiplist
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// Visualize writes the list as a Graphviz DOT graph. Each node is drawn as a
+// tower with one cell per level, and every forward link is an edge labelled
+// with its span, so the express lanes and the rank bookkeeping are both
+// visible. Keys are formatted with fmt.Sprint.
+func (s *SkipList[K, V]) Visualize(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("digraph skiplist {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=plaintext, fontname=\"monospace\"];\n")
+
+	ids := map[*node[K, V]]string{s.head: "head"}
+	writeTower(&b, "head", "head", s.level)
+	i := 0
+	for x := s.head.next[0].node; x != nil; x = x.next[0].node {
+		id := fmt.Sprintf("n%d", i)
+		ids[x] = id
+		writeTower(&b, id, html.EscapeString(fmt.Sprint(x.key)), len(x.next))
+		i++
+	}
+	writeTower(&b, "nil", "nil", s.level)
+
+	for x := s.head; x != nil; x = x.next[0].node {
+		levels := len(x.next)
+		if x == s.head {
+			levels = s.level
+		}
+		for l := 0; l < levels; l++ {
+			target := "nil"
+			if next := x.next[l].node; next != nil {
+				target = ids[next]
+			}
+			fmt.Fprintf(&b, "  %s:l%d -> %s:l%d [label=\"%d\"];\n", ids[x], l, target, l, x.next[l].span)
+		}
+	}
+
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeTower emits a node whose cells, top to bottom, are the levels from
+// highest to 0 followed by the label.
+func writeTower(b *strings.Builder, id, label string, levels int) {
+	fmt.Fprintf(b, "  %s [label=<<TABLE BORDER=\"0\" CELLBORDER=\"1\" CELLSPACING=\"0\">", id)
+	for l := levels - 1; l >= 0; l-- {
+		fmt.Fprintf(b, "<TR><TD PORT=\"l%d\">%d</TD></TR>", l, l)
+	}
+	fmt.Fprintf(b, "<TR><TD>%s</TD></TR></TABLE>>];\n", label)
+}
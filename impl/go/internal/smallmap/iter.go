@@ -0,0 +1,11 @@
+//go:build go1.23
+
+package smallmap
+
+import "iter"
+
+// All returns an iterator over all key-value pairs in unspecified order,
+// like Range.
+func (m *Map) All() iter.Seq2[string, string] {
+	return m.Range
+}
@@ -0,0 +1,149 @@
+// Package smallmap provides a string map for workloads that build many tiny
+// maps. Up to eight entries live in an array inside the map itself, found by
+// comparing keys one after another with no hashing and no table allocation;
+// the ninth insert moves them into an open-addressing hashmap, which serves
+// the map from then on.
+//
+// For a handful of keys a linear scan over a few cache lines beats hashing
+// the key, and creating the map costs one allocation of a fixed size instead
+// of the hashmap's struct and its sixteen-slot table.
+package smallmap
+
+import "github.com/dsa-lab/go/internal/hashmap"
+
+// inlineLimit is the most entries the inline array holds.
+const inlineLimit = 8
+
+type pair struct {
+	key   string
+	value string
+}
+
+// Map is a map from string keys to string values. The zero Map is empty and
+// ready to use, so a Map embedded in another struct costs no allocation of
+// its own until it is promoted.
+type Map struct {
+	n      int
+	inline [inlineLimit]pair
+	// table holds every entry once the map has outgrown the inline array,
+	// and is nil before.
+	table *hashmap.HashMap
+}
+
+// New creates an empty Map.
+func New() *Map {
+	return &Map{}
+}
+
+// Promoted reports whether the map has moved its entries into a hash table.
+// A promoted map stays promoted until Clear, even if it shrinks, so a map
+// whose size hovers around the limit does not copy its entries back and
+// forth.
+func (m *Map) Promoted() bool {
+	return m.table != nil
+}
+
+// Len returns the number of entries in the map.
+func (m *Map) Len() int {
+	if m.table != nil {
+		return m.table.Len()
+	}
+	return m.n
+}
+
+// find returns the index of key in the inline array, or -1.
+func (m *Map) find(key string) int {
+	for i := 0; i < m.n; i++ {
+		if m.inline[i].key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// Insert inserts or replaces the value for key.
+// Returns the previous value and true if the key existed, empty string and false otherwise.
+func (m *Map) Insert(key, value string) (string, bool) {
+	if m.table != nil {
+		return m.table.Insert(key, value)
+	}
+	if i := m.find(key); i >= 0 {
+		old := m.inline[i].value
+		m.inline[i].value = value
+		return old, true
+	}
+	if m.n < inlineLimit {
+		m.inline[m.n] = pair{key, value}
+		m.n++
+		return "", false
+	}
+	m.promote()
+	return m.table.Insert(key, value)
+}
+
+// promote moves the inline entries into a new hash table, sized to take a
+// few more inserts past the limit before its first resize.
+func (m *Map) promote() {
+	m.table = hashmap.MustNewWithCapacity(4 * inlineLimit)
+	for i := 0; i < m.n; i++ {
+		m.table.Insert(m.inline[i].key, m.inline[i].value)
+	}
+	m.inline = [inlineLimit]pair{}
+	m.n = 0
+}
+
+// Get retrieves the value associated with the key.
+// Returns the value and true if found, empty string and false otherwise.
+func (m *Map) Get(key string) (string, bool) {
+	if m.table != nil {
+		return m.table.Get(key)
+	}
+	if i := m.find(key); i >= 0 {
+		return m.inline[i].value, true
+	}
+	return "", false
+}
+
+// Remove removes the key from the map.
+// Returns the removed value and true if the key existed, empty string and false otherwise.
+func (m *Map) Remove(key string) (string, bool) {
+	if m.table != nil {
+		return m.table.Remove(key)
+	}
+	i := m.find(key)
+	if i < 0 {
+		return "", false
+	}
+	value := m.inline[i].value
+	m.n--
+	m.inline[i] = m.inline[m.n]
+	m.inline[m.n] = pair{}
+	return value, true
+}
+
+// Contains checks if the map contains the given key.
+func (m *Map) Contains(key string) bool {
+	if m.table != nil {
+		return m.table.Contains(key)
+	}
+	return m.find(key) >= 0
+}
+
+// Clear removes all entries and returns the map to its inline form.
+func (m *Map) Clear() {
+	*m = Map{}
+}
+
+// Range calls f for each entry in unspecified order. If f returns false,
+// iteration stops.
+func (m *Map) Range(f func(key, value string) bool) {
+	if m.table != nil {
+		m.table.Range(f)
+		return
+	}
+	for i := 0; i < m.n; i++ {
+		if !f(m.inline[i].key, m.inline[i].value) {
+			return
+		}
+	}
+}
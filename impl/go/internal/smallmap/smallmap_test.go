@@ -0,0 +1,75 @@
+package smallmap
+
+import (
+	"fmt"
+	"maps"
+	"math/rand"
+	"testing"
+)
+
+func TestPromotion(t *testing.T) {
+	var m Map
+	for i := 0; i < inlineLimit; i++ {
+		m.Insert(fmt.Sprint(i), "v")
+	}
+	m.Insert("0", "w")
+	if m.Promoted() {
+		t.Fatalf("expected %d entries and an overwrite to stay inline", inlineLimit)
+	}
+	m.Insert("extra", "v")
+	if !m.Promoted() || m.Len() != inlineLimit+1 {
+		t.Fatalf("expected promotion at %d entries, got promoted=%v len=%d", inlineLimit+1, m.Promoted(), m.Len())
+	}
+	if v, ok := m.Get("0"); !ok || v != "w" {
+		t.Errorf("expected promoted entries kept, got %q %v", v, ok)
+	}
+	m.Remove("extra")
+	m.Remove("1")
+	if !m.Promoted() {
+		t.Error("expected the map to stay promoted as it shrinks")
+	}
+	m.Clear()
+	if m.Promoted() || m.Len() != 0 {
+		t.Error("expected Clear to return to the inline form")
+	}
+}
+
+// TestModel compares random operations on keys around the promotion
+// threshold with a builtin map.
+func TestModel(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for round := 0; round < 200; round++ {
+		m := New()
+		model := make(map[string]string)
+		for i := 0; i < 50; i++ {
+			key := fmt.Sprint(rng.Intn(inlineLimit + 4))
+			want, wantFound := model[key]
+			var got string
+			var found bool
+			switch rng.Intn(3) {
+			case 0:
+				got, found = m.Insert(key, fmt.Sprint(i))
+				model[key] = fmt.Sprint(i)
+			case 1:
+				got, found = m.Remove(key)
+				delete(model, key)
+			default:
+				got, found = m.Get(key)
+			}
+			if got != want || found != wantFound {
+				t.Fatalf("round %d op %d on %s: expected %q %v, got %q %v", round, i, key, want, wantFound, got, found)
+			}
+			if m.Len() != len(model) || m.Contains(key) != (model[key] != "") {
+				t.Fatalf("round %d op %d: expected %d entries, got %d", round, i, len(model), m.Len())
+			}
+		}
+		got := make(map[string]string)
+		m.Range(func(key, value string) bool {
+			got[key] = value
+			return true
+		})
+		if !maps.Equal(got, model) {
+			t.Fatalf("round %d: range expected %v, got %v", round, model, got)
+		}
+	}
+}
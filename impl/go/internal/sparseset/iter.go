@@ -0,0 +1,33 @@
+//go:build go1.23
+
+package sparseset
+
+import "iter"
+
+// All returns an iterator over the members in insertion order, as disturbed
+// by removals.
+func (s *Set) All() iter.Seq[int] {
+	return s.Range
+}
+
+// All returns an iterator over the key-value pairs in the order of the
+// underlying Set.
+func (m *Map[V]) All() iter.Seq2[int, V] {
+	return m.Range
+}
+
+// Keys returns an iterator over the keys.
+func (m *Map[V]) Keys() iter.Seq[int] {
+	return m.keys.Range
+}
+
+// Values returns an iterator over the values, a scan of the dense array.
+func (m *Map[V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, v := range m.values {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,169 @@
+// Package sparseset provides sets and maps over small non-negative integer
+// keys, such as entity ids, using Briggs and Torczon's paired arrays: a dense
+// array lists the members in insertion order and a sparse array, indexed by
+// key, records each member's position in the dense one. Insert, Remove,
+// Contains and Clear are O(1), and iteration walks only the dense array.
+package sparseset
+
+// Set is a set of non-negative integers. Memory grows with the largest key
+// ever inserted, so keys should be drawn from a compact range.
+type Set struct {
+	dense  []int
+	sparse []uint32
+}
+
+// New creates an empty Set with room for keys below universe before the
+// sparse array has to grow.
+func New(universe int) *Set {
+	return &Set{sparse: make([]uint32, max(universe, 0))}
+}
+
+// Len returns the number of members.
+func (s *Set) Len() int {
+	return len(s.dense)
+}
+
+// Contains reports whether key is a member.
+func (s *Set) Contains(key int) bool {
+	_, found := s.find(key)
+	return found
+}
+
+// find returns the dense position of key and true if key is a member.
+func (s *Set) find(key int) (int, bool) {
+	if key < 0 || key >= len(s.sparse) {
+		return 0, false
+	}
+	i := int(s.sparse[key])
+	return i, i < len(s.dense) && s.dense[i] == key
+}
+
+// Insert adds key to the set and returns true if it was not already present.
+// It panics if key is negative.
+func (s *Set) Insert(key int) bool {
+	if key < 0 {
+		panic("sparseset: negative key")
+	}
+	if _, found := s.find(key); found {
+		return false
+	}
+	if key >= len(s.sparse) {
+		s.sparse = append(s.sparse, make([]uint32, max(key+1, 2*len(s.sparse))-len(s.sparse))...)
+	}
+	s.sparse[key] = uint32(len(s.dense))
+	s.dense = append(s.dense, key)
+	return true
+}
+
+// Remove deletes key from the set and returns true if it was present. The
+// last member takes its place in iteration order.
+func (s *Set) Remove(key int) bool {
+	i, found := s.find(key)
+	if !found {
+		return false
+	}
+	last := s.dense[len(s.dense)-1]
+	s.dense[i] = last
+	s.sparse[last] = uint32(i)
+	s.dense = s.dense[:len(s.dense)-1]
+	return true
+}
+
+// Clear removes every member in O(1). Stale sparse entries are harmless
+// because membership is confirmed against the dense array.
+func (s *Set) Clear() {
+	s.dense = s.dense[:0]
+}
+
+// Keys returns the members in iteration order. The slice aliases the set's
+// storage and is valid until the next modification.
+func (s *Set) Keys() []int {
+	return s.dense
+}
+
+// Range calls f for each member. If f returns false, iteration stops.
+func (s *Set) Range(f func(key int) bool) {
+	for _, key := range s.dense {
+		if !f(key) {
+			return
+		}
+	}
+}
+
+// Map associates values with non-negative integer keys. Values live in a
+// dense array beside the keys, so iterating them is a linear scan.
+type Map[V any] struct {
+	keys   Set
+	values []V
+}
+
+// NewMap creates an empty Map with room for keys below universe.
+func NewMap[V any](universe int) *Map[V] {
+	return &Map[V]{keys: *New(universe)}
+}
+
+// Len returns the number of entries.
+func (m *Map[V]) Len() int {
+	return m.keys.Len()
+}
+
+// Insert inserts or updates the value for key.
+// Returns the previous value and true if the key existed.
+func (m *Map[V]) Insert(key int, value V) (V, bool) {
+	if i, found := m.keys.find(key); found {
+		old := m.values[i]
+		m.values[i] = value
+		return old, true
+	}
+	m.keys.Insert(key)
+	m.values = append(m.values, value)
+	var zero V
+	return zero, false
+}
+
+// Get returns the value for key and true if it is present.
+func (m *Map[V]) Get(key int) (V, bool) {
+	if i, found := m.keys.find(key); found {
+		return m.values[i], true
+	}
+	var zero V
+	return zero, false
+}
+
+// Contains reports whether key is present.
+func (m *Map[V]) Contains(key int) bool {
+	return m.keys.Contains(key)
+}
+
+// Remove deletes key.
+// Returns the removed value and true if the key existed.
+func (m *Map[V]) Remove(key int) (V, bool) {
+	var zero V
+	i, found := m.keys.find(key)
+	if !found {
+		return zero, false
+	}
+	old := m.values[i]
+	last := len(m.values) - 1
+	m.values[i] = m.values[last]
+	m.values[last] = zero
+	m.values = m.values[:last]
+	m.keys.Remove(key)
+	return old, true
+}
+
+// Clear removes every entry.
+func (m *Map[V]) Clear() {
+	m.keys.Clear()
+	clear(m.values)
+	m.values = m.values[:0]
+}
+
+// Range calls f for each entry. If f returns false, iteration stops.
+func (m *Map[V]) Range(f func(key int, value V) bool) {
+	for i, key := range m.keys.dense {
+		if !f(key, m.values[i]) {
+			return
+		}
+	}
+}
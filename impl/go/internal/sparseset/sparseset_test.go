@@ -0,0 +1,92 @@
+package sparseset
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestSet(t *testing.T) {
+	s := New(4)
+	for _, k := range []int{3, 1, 100, 3} {
+		s.Insert(k)
+	}
+	if s.Len() != 3 || !s.Contains(100) || s.Contains(2) || s.Contains(-1) || s.Contains(1000) {
+		t.Fatalf("unexpected membership, keys %v", s.Keys())
+	}
+	if !s.Remove(3) || s.Remove(3) || s.Contains(3) {
+		t.Error("expected 3 to be removed exactly once")
+	}
+	got := slices.Clone(s.Keys())
+	slices.Sort(got)
+	if !slices.Equal(got, []int{1, 100}) {
+		t.Errorf("expected keys [1 100], got %v", got)
+	}
+
+	s.Clear()
+	if s.Len() != 0 || s.Contains(1) {
+		t.Error("expected empty set after Clear")
+	}
+	if !s.Insert(1) || s.Len() != 1 {
+		t.Error("expected reinsert after Clear to succeed")
+	}
+}
+
+func TestInsertNegativePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for negative key")
+		}
+	}()
+	New(0).Insert(-1)
+}
+
+func TestMapOracle(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	m := NewMap[int](16)
+	ref := make(map[int]int)
+
+	for i := 0; i < 20000; i++ {
+		key := rng.Intn(300)
+		switch rng.Intn(4) {
+		case 0, 1:
+			old, existed := m.Insert(key, i)
+			want, wantExisted := ref[key]
+			if existed != wantExisted || old != want {
+				t.Fatalf("Insert(%d) = %d, %v; expected %d, %v", key, old, existed, want, wantExisted)
+			}
+			ref[key] = i
+		case 2:
+			old, existed := m.Remove(key)
+			want, wantExisted := ref[key]
+			if existed != wantExisted || old != want {
+				t.Fatalf("Remove(%d) = %d, %v; expected %d, %v", key, old, existed, want, wantExisted)
+			}
+			delete(ref, key)
+		case 3:
+			if rng.Intn(500) == 0 {
+				m.Clear()
+				clear(ref)
+			}
+			value, found := m.Get(key)
+			want, wantFound := ref[key]
+			if found != wantFound || value != want {
+				t.Fatalf("Get(%d) = %d, %v; expected %d, %v", key, value, found, want, wantFound)
+			}
+		}
+		if m.Len() != len(ref) {
+			t.Fatalf("expected len %d, got %d", len(ref), m.Len())
+		}
+	}
+
+	m.Range(func(key, value int) bool {
+		if ref[key] != value {
+			t.Errorf("Range yielded %d=%d, expected %d", key, value, ref[key])
+		}
+		delete(ref, key)
+		return true
+	})
+	if len(ref) != 0 {
+		t.Errorf("Range missed %d entries", len(ref))
+	}
+}
@@ -0,0 +1,63 @@
+//go:build go1.23
+
+package spatial
+
+import "iter"
+
+// All returns an iterator over the stored points and their values in
+// unspecified order. The points are the tree's own and must not be modified.
+func (t *KDTree[V]) All() iter.Seq2[Point, V] {
+	return func(yield func(Point, V) bool) {
+		var walk func(n *kdNode[V]) bool
+		walk = func(n *kdNode[V]) bool {
+			return n == nil || yield(n.point, n.value) && walk(n.left) && walk(n.right)
+		}
+		walk(t.root)
+	}
+}
+
+// All returns an iterator over the stored points and their values in
+// unspecified order. The points are the tree's own and must not be modified.
+func (q *Quadtree[V]) All() iter.Seq2[Point, V] {
+	return func(yield func(Point, V) bool) {
+		var walk func(n *quadNode[V]) bool
+		walk = func(n *quadNode[V]) bool {
+			if n.children == nil {
+				for _, it := range n.items {
+					if !yield(it.point, it.value) {
+						return false
+					}
+				}
+				return true
+			}
+			for _, c := range n.children {
+				if !walk(c) {
+					return false
+				}
+			}
+			return true
+		}
+		walk(q.root)
+	}
+}
+
+// All returns an iterator over the stored boxes and their values in
+// unspecified order. The boxes are the tree's own and must not be modified.
+func (t *RTree[V]) All() iter.Seq2[Rect, V] {
+	return func(yield func(Rect, V) bool) {
+		var walk func(n *rtreeNode[V], level int) bool
+		walk = func(n *rtreeNode[V], level int) bool {
+			for _, e := range n.entries {
+				if level == 0 {
+					if !yield(e.rect, e.value) {
+						return false
+					}
+				} else if !walk(e.child, level-1) {
+					return false
+				}
+			}
+			return true
+		}
+		walk(t.root, t.height-1)
+	}
+}
@@ -0,0 +1,181 @@
+package spatial
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/dsa-lab/go/internal/errs"
+)
+
+// ErrDimension is returned when a point or box does not match the dimension
+// of the index.
+var ErrDimension = errors.New("spatial: dimension mismatch")
+
+type kdNode[V any] struct {
+	point       Point
+	value       V
+	left, right *kdNode[V]
+}
+
+// KDTree indexes points of a fixed dimension. Each level splits space on one
+// axis, cycling through the axes with depth; points with a coordinate less
+// than the splitting point's go left, the rest right. The tree is not
+// rebalanced, so insertion order matters: random orders give O(log n)
+// expected depth, sorted input degrades to a list.
+type KDTree[V any] struct {
+	dims   int
+	root   *kdNode[V]
+	length int
+}
+
+// NewKDTree creates an empty tree for points with dims coordinates. It
+// returns an error matching errs.ErrInvalidOption if dims < 1.
+func NewKDTree[V any](dims int) (*KDTree[V], error) {
+	if dims < 1 {
+		return nil, fmt.Errorf("spatial: k-d tree of %d dimensions: %w", dims, errs.ErrInvalidOption)
+	}
+	return &KDTree[V]{dims: dims}, nil
+}
+
+// MustNewKDTree is like NewKDTree but panics if dims < 1.
+func MustNewKDTree[V any](dims int) *KDTree[V] {
+	return errs.Must(NewKDTree[V](dims))
+}
+
+// Dims returns the dimension of the indexed points.
+func (t *KDTree[V]) Dims() int {
+	return t.dims
+}
+
+// Len returns the number of points in the tree.
+func (t *KDTree[V]) Len() int {
+	return t.length
+}
+
+func (t *KDTree[V]) checkPoint(p Point) error {
+	if len(p) != t.dims {
+		return fmt.Errorf("%w: point has %d coordinates, tree has %d", ErrDimension, len(p), t.dims)
+	}
+	return nil
+}
+
+// Insert adds p with value v. Duplicate points are kept as separate entries.
+// The tree keeps its own copy of p.
+func (t *KDTree[V]) Insert(p Point, v V) error {
+	if err := t.checkPoint(p); err != nil {
+		return err
+	}
+	n := &kdNode[V]{point: append(Point(nil), p...), value: v}
+	link := &t.root
+	for depth := 0; *link != nil; depth++ {
+		axis := depth % t.dims
+		if p[axis] < (*link).point[axis] {
+			link = &(*link).left
+		} else {
+			link = &(*link).right
+		}
+	}
+	*link = n
+	t.length++
+	return nil
+}
+
+// RangeSearch calls f for every point inside r until f returns false.
+func (t *KDTree[V]) RangeSearch(r Rect, f func(p Point, v V) bool) error {
+	if err := t.checkPoint(r.Min); err != nil {
+		return err
+	}
+	if err := t.checkPoint(r.Max); err != nil {
+		return err
+	}
+	var search func(n *kdNode[V], depth int) bool
+	search = func(n *kdNode[V], depth int) bool {
+		if n == nil {
+			return true
+		}
+		axis := depth % t.dims
+		if r.Contains(n.point) && !f(n.point, n.value) {
+			return false
+		}
+		// The left subtree holds coordinates below the split; the right
+		// subtree those at or above it.
+		if r.Min[axis] < n.point[axis] && !search(n.left, depth+1) {
+			return false
+		}
+		if r.Max[axis] >= n.point[axis] && !search(n.right, depth+1) {
+			return false
+		}
+		return true
+	}
+	search(t.root, 0)
+	return nil
+}
+
+// Neighbor is a result of a nearest-neighbour query.
+type Neighbor[V any] struct {
+	Point Point
+	Value V
+	Dist  float64
+}
+
+// neighborHeap is a max-heap on distance holding the best candidates so far.
+type neighborHeap[V any] []Neighbor[V]
+
+func (h neighborHeap[V]) Len() int           { return len(h) }
+func (h neighborHeap[V]) Less(i, j int) bool { return h[i].Dist > h[j].Dist }
+func (h neighborHeap[V]) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *neighborHeap[V]) Push(x any)        { *h = append(*h, x.(Neighbor[V])) }
+func (h *neighborHeap[V]) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// Nearest returns the k points closest to q by Euclidean distance, nearest
+// first. Ties are broken arbitrarily.
+func (t *KDTree[V]) Nearest(q Point, k int) ([]Neighbor[V], error) {
+	if err := t.checkPoint(q); err != nil {
+		return nil, err
+	}
+	if k < 1 {
+		return nil, nil
+	}
+	// Distances are squared during the search and rooted at the end.
+	best := make(neighborHeap[V], 0, k)
+	var search func(n *kdNode[V], depth int)
+	search = func(n *kdNode[V], depth int) {
+		if n == nil {
+			return
+		}
+		if d := Dist2(q, n.point); len(best) < k {
+			heap.Push(&best, Neighbor[V]{Point: n.point, Value: n.value, Dist: d})
+		} else if d < best[0].Dist {
+			best[0] = Neighbor[V]{Point: n.point, Value: n.value, Dist: d}
+			heap.Fix(&best, 0)
+		}
+
+		axis := depth % t.dims
+		diff := q[axis] - n.point[axis]
+		near, far := n.left, n.right
+		if diff >= 0 {
+			near, far = far, near
+		}
+		search(near, depth+1)
+		// The far side can only help if the splitting plane is closer than
+		// the worst candidate kept so far.
+		if len(best) < k || diff*diff < best[0].Dist {
+			search(far, depth+1)
+		}
+	}
+	search(t.root, 0)
+
+	sort.Slice(best, func(i, j int) bool { return best[i].Dist < best[j].Dist })
+	for i := range best {
+		best[i].Dist = math.Sqrt(best[i].Dist)
+	}
+	return best, nil
+}
@@ -0,0 +1,275 @@
+package spatial
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dsa-lab/go/internal/errs"
+)
+
+// ErrOutOfBounds is returned when a point lies outside a quadtree's region.
+var ErrOutOfBounds = errors.New("spatial: point out of bounds")
+
+const (
+	defaultNodeCapacity = 8
+	// maxQuadDepth stops splitting once cells are tiny, so many copies of
+	// one point cannot recurse forever.
+	maxQuadDepth = 24
+)
+
+type quadItem[V comparable] struct {
+	point Point
+	value V
+}
+
+type quadNode[V comparable] struct {
+	bounds   Rect
+	items    []quadItem[V]    // set on leaves
+	children *[4]*quadNode[V] // nil on leaves
+	count    int              // items in this subtree
+}
+
+// Quadtree indexes 2-D points inside a fixed region. Every leaf holds up to a
+// fixed number of points; a leaf that overflows is split into four equal
+// quadrants, and an internal node whose subtree shrinks back to that size is
+// collapsed into a leaf again. Dense areas are therefore subdivided finely
+// while sparse ones stay shallow.
+type Quadtree[V comparable] struct {
+	root     *quadNode[V]
+	capacity int
+}
+
+// NewQuadtree creates an empty quadtree covering bounds with leaves of up to
+// capacity points. A capacity of 0 selects the default; a negative one is an
+// error matching errs.ErrInvalidOption.
+func NewQuadtree[V comparable](bounds Rect, capacity int) (*Quadtree[V], error) {
+	if !bounds.Valid(2) {
+		return nil, fmt.Errorf("%w: quadtree bounds %v are not a 2-D box", ErrDimension, bounds)
+	}
+	if capacity < 0 {
+		return nil, fmt.Errorf("spatial: quadtree leaf capacity %d: %w", capacity, errs.ErrInvalidOption)
+	}
+	if capacity == 0 {
+		capacity = defaultNodeCapacity
+	}
+	return &Quadtree[V]{root: &quadNode[V]{bounds: bounds.clone()}, capacity: capacity}, nil
+}
+
+// MustNewQuadtree is like NewQuadtree but panics on invalid arguments.
+func MustNewQuadtree[V comparable](bounds Rect, capacity int) *Quadtree[V] {
+	return errs.Must(NewQuadtree[V](bounds, capacity))
+}
+
+// Len returns the number of points in the tree.
+func (q *Quadtree[V]) Len() int {
+	return q.root.count
+}
+
+// quadrant returns the index of the child of n whose cell holds p: bit 0 is
+// set east of the midpoint, bit 1 north of it.
+func (n *quadNode[V]) quadrant(p Point) int {
+	i := 0
+	if p[0] >= (n.bounds.Min[0]+n.bounds.Max[0])/2 {
+		i |= 1
+	}
+	if p[1] >= (n.bounds.Min[1]+n.bounds.Max[1])/2 {
+		i |= 2
+	}
+	return i
+}
+
+// split turns the leaf n at depth into an internal node, splitting again any
+// quadrant that still overflows because the points were bunched together.
+func (n *quadNode[V]) split(depth, capacity int) {
+	midX := (n.bounds.Min[0] + n.bounds.Max[0]) / 2
+	midY := (n.bounds.Min[1] + n.bounds.Max[1]) / 2
+	n.children = new([4]*quadNode[V])
+	for i := range n.children {
+		b := n.bounds.clone()
+		if i&1 != 0 {
+			b.Min[0] = midX
+		} else {
+			b.Max[0] = midX
+		}
+		if i&2 != 0 {
+			b.Min[1] = midY
+		} else {
+			b.Max[1] = midY
+		}
+		n.children[i] = &quadNode[V]{bounds: b}
+	}
+	for _, it := range n.items {
+		c := n.children[n.quadrant(it.point)]
+		c.items = append(c.items, it)
+		c.count++
+	}
+	n.items = nil
+	for _, c := range n.children {
+		if len(c.items) > capacity && depth+1 < maxQuadDepth {
+			c.split(depth+1, capacity)
+		}
+	}
+}
+
+// Insert adds p with value v. Duplicate entries are kept. The tree keeps its
+// own copy of p.
+func (q *Quadtree[V]) Insert(p Point, v V) error {
+	if len(p) != 2 {
+		return fmt.Errorf("%w: quadtree points have 2 coordinates, got %d", ErrDimension, len(p))
+	}
+	if !q.root.bounds.Contains(p) {
+		return fmt.Errorf("%w: %v outside %v", ErrOutOfBounds, p, q.root.bounds)
+	}
+	it := quadItem[V]{point: append(Point(nil), p...), value: v}
+	n := q.root
+	for depth := 0; ; depth++ {
+		n.count++
+		if n.children == nil {
+			n.items = append(n.items, it)
+			if len(n.items) > q.capacity && depth < maxQuadDepth {
+				n.split(depth, q.capacity)
+			}
+			return nil
+		}
+		n = n.children[n.quadrant(p)]
+	}
+}
+
+// Remove deletes one entry equal to (p, v).
+// Returns true if such an entry was present.
+func (q *Quadtree[V]) Remove(p Point, v V) bool {
+	if len(p) != 2 || !q.root.bounds.Contains(p) {
+		return false
+	}
+	return q.remove(q.root, p, v)
+}
+
+func (q *Quadtree[V]) remove(n *quadNode[V], p Point, v V) bool {
+	if n.children == nil {
+		for i, it := range n.items {
+			if it.value == v && it.point[0] == p[0] && it.point[1] == p[1] {
+				last := len(n.items) - 1
+				n.items[i] = n.items[last]
+				n.items[last] = quadItem[V]{}
+				n.items = n.items[:last]
+				n.count--
+				return true
+			}
+		}
+		return false
+	}
+	if !q.remove(n.children[n.quadrant(p)], p, v) {
+		return false
+	}
+	n.count--
+	if n.count <= q.capacity {
+		n.collapse()
+	}
+	return true
+}
+
+// collapse gathers every item below n back into n as a leaf.
+func (n *quadNode[V]) collapse() {
+	items := make([]quadItem[V], 0, n.count)
+	var gather func(c *quadNode[V])
+	gather = func(c *quadNode[V]) {
+		if c.children == nil {
+			items = append(items, c.items...)
+			return
+		}
+		for _, cc := range c.children {
+			gather(cc)
+		}
+	}
+	gather(n)
+	n.items, n.children = items, nil
+}
+
+// Search calls f for every point inside window until f returns false.
+func (q *Quadtree[V]) Search(window Rect, f func(p Point, v V) bool) error {
+	if !window.Valid(2) {
+		return fmt.Errorf("%w: quadtree windows are 2-D boxes, got %v", ErrDimension, window)
+	}
+	var search func(n *quadNode[V]) bool
+	search = func(n *quadNode[V]) bool {
+		if n.count == 0 || !n.bounds.Intersects(window) {
+			return true
+		}
+		if n.children == nil {
+			for _, it := range n.items {
+				if window.Contains(it.point) && !f(it.point, it.value) {
+					return false
+				}
+			}
+			return true
+		}
+		for _, c := range n.children {
+			if !search(c) {
+				return false
+			}
+		}
+		return true
+	}
+	search(q.root)
+	return nil
+}
+
+// Depth returns the depth of the deepest leaf; a tree that has never split
+// has depth 0.
+func (q *Quadtree[V]) Depth() int {
+	var depth func(n *quadNode[V]) int
+	depth = func(n *quadNode[V]) int {
+		if n.children == nil {
+			return 0
+		}
+		d := 0
+		for _, c := range n.children {
+			d = max(d, depth(c))
+		}
+		return d + 1
+	}
+	return depth(q.root)
+}
+
+// CheckInvariants verifies that every point lies in its leaf's cell, that
+// subtree counts are exact, and that no internal node holds few enough points
+// to have been collapsed.
+func (q *Quadtree[V]) CheckInvariants() error {
+	var check func(n *quadNode[V], depth int) (int, error)
+	check = func(n *quadNode[V], depth int) (int, error) {
+		if n.children == nil {
+			for _, it := range n.items {
+				if !n.bounds.Contains(it.point) {
+					return 0, fmt.Errorf("point %v outside leaf %v", it.point, n.bounds)
+				}
+			}
+			if len(n.items) > q.capacity && depth < maxQuadDepth {
+				return 0, fmt.Errorf("leaf %v holds %d points, capacity %d", n.bounds, len(n.items), q.capacity)
+			}
+			if len(n.items) != n.count {
+				return 0, fmt.Errorf("leaf %v count %d, holds %d", n.bounds, n.count, len(n.items))
+			}
+			return n.count, nil
+		}
+		if len(n.items) != 0 {
+			return 0, fmt.Errorf("internal node %v holds items", n.bounds)
+		}
+		total := 0
+		for _, c := range n.children {
+			got, err := check(c, depth+1)
+			if err != nil {
+				return 0, err
+			}
+			total += got
+		}
+		if total != n.count {
+			return 0, fmt.Errorf("node %v count %d, subtree holds %d", n.bounds, n.count, total)
+		}
+		if n.count <= q.capacity {
+			return 0, fmt.Errorf("node %v with %d points was not collapsed", n.bounds, n.count)
+		}
+		return total, nil
+	}
+	_, err := check(q.root, 0)
+	return err
+}
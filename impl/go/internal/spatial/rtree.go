@@ -0,0 +1,351 @@
+package spatial
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/dsa-lab/go/internal/errs"
+)
+
+const defaultMaxEntries = 8
+
+type rtreeEntry[V comparable] struct {
+	rect  Rect
+	child *rtreeNode[V] // nil in leaves
+	value V
+}
+
+type rtreeNode[V comparable] struct {
+	entries []rtreeEntry[V]
+}
+
+func (n *rtreeNode[V]) bounds() Rect {
+	b := n.entries[0].rect.clone()
+	for _, e := range n.entries[1:] {
+		for i := range b.Min {
+			b.Min[i] = min(b.Min[i], e.rect.Min[i])
+			b.Max[i] = max(b.Max[i], e.rect.Max[i])
+		}
+	}
+	return b
+}
+
+// RTree indexes axis-aligned boxes (Guttman, 1984). Each node holds between
+// m and M entries, each the bounding box of a child node or, in leaves, of a
+// stored object; all leaves are at the same depth. A new box descends into
+// the child needing the least enlargement to hold it, and overflowing nodes
+// are divided with the quadratic split. Removing a box that leaves a node
+// under-full dissolves the node and reinserts its boxes.
+type RTree[V comparable] struct {
+	dims       int
+	root       *rtreeNode[V]
+	height     int // number of levels; leaves are level 0
+	length     int
+	maxEntries int
+	minEntries int
+}
+
+// NewRTree creates an empty tree for boxes with dims coordinates per corner,
+// holding up to maxEntries entries per node. A maxEntries of 0 selects the
+// default; otherwise it must be at least 4. The minimum fill is 40% of
+// maxEntries, the usual choice for the quadratic split. Invalid arguments
+// give an error matching errs.ErrInvalidOption.
+func NewRTree[V comparable](dims, maxEntries int) (*RTree[V], error) {
+	if maxEntries == 0 {
+		maxEntries = defaultMaxEntries
+	}
+	if dims < 1 || maxEntries < 4 {
+		return nil, fmt.Errorf("spatial: R-tree of %d dimensions, %d entries per node: %w", dims, maxEntries, errs.ErrInvalidOption)
+	}
+	return &RTree[V]{
+		dims:       dims,
+		root:       &rtreeNode[V]{},
+		height:     1,
+		maxEntries: maxEntries,
+		minEntries: max(2, maxEntries*2/5),
+	}, nil
+}
+
+// MustNewRTree is like NewRTree but panics on invalid arguments.
+func MustNewRTree[V comparable](dims, maxEntries int) *RTree[V] {
+	return errs.Must(NewRTree[V](dims, maxEntries))
+}
+
+// Len returns the number of boxes in the tree.
+func (t *RTree[V]) Len() int {
+	return t.length
+}
+
+// Height returns the number of levels in the tree.
+func (t *RTree[V]) Height() int {
+	return t.height
+}
+
+func (t *RTree[V]) checkRect(r Rect) error {
+	if !r.Valid(t.dims) {
+		return fmt.Errorf("%w: %v is not a %d-D box", ErrDimension, r, t.dims)
+	}
+	return nil
+}
+
+// Insert adds r with value v. Duplicate entries are kept. The tree keeps its
+// own copy of r.
+func (t *RTree[V]) Insert(r Rect, v V) error {
+	if err := t.checkRect(r); err != nil {
+		return err
+	}
+	t.insert(rtreeEntry[V]{rect: r.clone(), value: v}, 0)
+	t.length++
+	return nil
+}
+
+// insert places e in a node at level, growing a new root if the old one
+// splits.
+func (t *RTree[V]) insert(e rtreeEntry[V], level int) {
+	if sibling := t.insertAt(t.root, t.height-1, e, level); sibling != nil {
+		old := t.root
+		t.root = &rtreeNode[V]{entries: []rtreeEntry[V]{
+			{rect: old.bounds(), child: old},
+			{rect: sibling.bounds(), child: sibling},
+		}}
+		t.height++
+	}
+}
+
+// insertAt adds e below n, which sits at nodeLevel, and returns the new
+// sibling of n if n had to split.
+func (t *RTree[V]) insertAt(n *rtreeNode[V], nodeLevel int, e rtreeEntry[V], level int) *rtreeNode[V] {
+	if nodeLevel == level {
+		n.entries = append(n.entries, e)
+	} else {
+		i := chooseSubtree(n, e.rect)
+		child := n.entries[i].child
+		sibling := t.insertAt(child, nodeLevel-1, e, level)
+		n.entries[i].rect = child.bounds()
+		if sibling != nil {
+			n.entries = append(n.entries, rtreeEntry[V]{rect: sibling.bounds(), child: sibling})
+		}
+	}
+	if len(n.entries) > t.maxEntries {
+		return t.split(n)
+	}
+	return nil
+}
+
+// chooseSubtree picks the entry of n whose box grows least to cover r,
+// preferring the smaller box on ties.
+func chooseSubtree[V comparable](n *rtreeNode[V], r Rect) int {
+	best, bestGrowth, bestArea := 0, math.Inf(1), math.Inf(1)
+	for i, e := range n.entries {
+		area := e.rect.Area()
+		growth := e.rect.Union(r).Area() - area
+		if growth < bestGrowth || (growth == bestGrowth && area < bestArea) {
+			best, bestGrowth, bestArea = i, growth, area
+		}
+	}
+	return best
+}
+
+// split divides the entries of an overflowing node between n and a new
+// sibling using Guttman's quadratic method: seed the two groups with the pair
+// that would waste the most area together, then repeatedly assign the entry
+// with the strongest preference for one group.
+func (t *RTree[V]) split(n *rtreeNode[V]) *rtreeNode[V] {
+	entries := n.entries
+	seedA, seedB, worst := 0, 1, math.Inf(-1)
+	for i := range entries {
+		for j := i + 1; j < len(entries); j++ {
+			waste := entries[i].rect.Union(entries[j].rect).Area() - entries[i].rect.Area() - entries[j].rect.Area()
+			if waste > worst {
+				seedA, seedB, worst = i, j, waste
+			}
+		}
+	}
+
+	a := []rtreeEntry[V]{entries[seedA]}
+	b := []rtreeEntry[V]{entries[seedB]}
+	boundsA, boundsB := entries[seedA].rect, entries[seedB].rect
+	rest := make([]rtreeEntry[V], 0, len(entries)-2)
+	for i, e := range entries {
+		if i != seedA && i != seedB {
+			rest = append(rest, e)
+		}
+	}
+
+	for len(rest) > 0 {
+		// Give a group everything left if it needs it to reach the minimum.
+		if len(a)+len(rest) == t.minEntries {
+			a = append(a, rest...)
+			break
+		}
+		if len(b)+len(rest) == t.minEntries {
+			b = append(b, rest...)
+			break
+		}
+
+		pick, pickDiff := 0, math.Inf(-1)
+		var growA, growB float64
+		for i, e := range rest {
+			ga := boundsA.Union(e.rect).Area() - boundsA.Area()
+			gb := boundsB.Union(e.rect).Area() - boundsB.Area()
+			if diff := math.Abs(ga - gb); diff > pickDiff {
+				pick, pickDiff, growA, growB = i, diff, ga, gb
+			}
+		}
+		e := rest[pick]
+		rest[pick] = rest[len(rest)-1]
+		rest = rest[:len(rest)-1]
+
+		toA := growA < growB
+		if growA == growB {
+			if areaA, areaB := boundsA.Area(), boundsB.Area(); areaA != areaB {
+				toA = areaA < areaB
+			} else {
+				toA = len(a) <= len(b)
+			}
+		}
+		if toA {
+			a = append(a, e)
+			boundsA = boundsA.Union(e.rect)
+		} else {
+			b = append(b, e)
+			boundsB = boundsB.Union(e.rect)
+		}
+	}
+
+	n.entries = a
+	return &rtreeNode[V]{entries: b}
+}
+
+// Remove deletes one entry whose box equals r and whose value equals v.
+// Returns true if such an entry was present.
+func (t *RTree[V]) Remove(r Rect, v V) bool {
+	if t.checkRect(r) != nil {
+		return false
+	}
+	var orphans []rtreeEntry[V]
+	if !t.remove(t.root, t.height-1, r, v, &orphans) {
+		return false
+	}
+	t.length--
+
+	// Shorten the tree while the root is a mere pass-through.
+	for t.height > 1 && len(t.root.entries) == 1 {
+		t.root = t.root.entries[0].child
+		t.height--
+	}
+	if t.height > 1 && len(t.root.entries) == 0 {
+		t.root, t.height = &rtreeNode[V]{}, 1
+	}
+	for _, e := range orphans {
+		t.insert(e, 0)
+	}
+	return true
+}
+
+// remove deletes the entry (r, v) below n, which sits at level. Nodes left
+// under-full are unlinked and their leaf entries appended to orphans for
+// reinsertion.
+func (t *RTree[V]) remove(n *rtreeNode[V], level int, r Rect, v V, orphans *[]rtreeEntry[V]) bool {
+	if level == 0 {
+		for i, e := range n.entries {
+			if e.value == v && e.rect.Equal(r) {
+				n.entries = append(n.entries[:i], n.entries[i+1:]...)
+				return true
+			}
+		}
+		return false
+	}
+	for i, e := range n.entries {
+		if !e.rect.Covers(r) || !t.remove(e.child, level-1, r, v, orphans) {
+			continue
+		}
+		if len(e.child.entries) < t.minEntries {
+			collectLeaves(e.child, level-1, orphans)
+			n.entries = append(n.entries[:i], n.entries[i+1:]...)
+		} else {
+			n.entries[i].rect = e.child.bounds()
+		}
+		return true
+	}
+	return false
+}
+
+func collectLeaves[V comparable](n *rtreeNode[V], level int, out *[]rtreeEntry[V]) {
+	if level == 0 {
+		*out = append(*out, n.entries...)
+		return
+	}
+	for _, e := range n.entries {
+		collectLeaves(e.child, level-1, out)
+	}
+}
+
+// Search calls f for every box intersecting window until f returns false.
+func (t *RTree[V]) Search(window Rect, f func(r Rect, v V) bool) error {
+	if err := t.checkRect(window); err != nil {
+		return err
+	}
+	var search func(n *rtreeNode[V], level int) bool
+	search = func(n *rtreeNode[V], level int) bool {
+		for _, e := range n.entries {
+			if !e.rect.Intersects(window) {
+				continue
+			}
+			if level == 0 {
+				if !f(e.rect, e.value) {
+					return false
+				}
+			} else if !search(e.child, level-1) {
+				return false
+			}
+		}
+		return true
+	}
+	search(t.root, t.height-1)
+	return nil
+}
+
+// CheckInvariants verifies node fill, that every leaf is at the same depth,
+// that each entry's box is exactly its child's bounds, and the stored length.
+func (t *RTree[V]) CheckInvariants() error {
+	count := 0
+	var check func(n *rtreeNode[V], level int) error
+	check = func(n *rtreeNode[V], level int) error {
+		if len(n.entries) > t.maxEntries {
+			return fmt.Errorf("node at level %d has %d entries, max %d", level, len(n.entries), t.maxEntries)
+		}
+		if n != t.root && len(n.entries) < t.minEntries {
+			return fmt.Errorf("node at level %d has %d entries, min %d", level, len(n.entries), t.minEntries)
+		}
+		for _, e := range n.entries {
+			if level == 0 {
+				if e.child != nil {
+					return fmt.Errorf("leaf entry %v has a child", e.rect)
+				}
+				count++
+				continue
+			}
+			if e.child == nil {
+				return fmt.Errorf("internal entry %v at level %d has no child", e.rect, level)
+			}
+			if len(e.child.entries) == 0 {
+				return fmt.Errorf("empty node below %v", e.rect)
+			}
+			if b := e.child.bounds(); !b.Equal(e.rect) {
+				return fmt.Errorf("entry box %v differs from child bounds %v", e.rect, b)
+			}
+			if err := check(e.child, level-1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := check(t.root, t.height-1); err != nil {
+		return err
+	}
+	if count != t.length {
+		return fmt.Errorf("counted %d boxes, length is %d", count, t.length)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+// Package spatial provides indexes over points and boxes in low-dimensional
+// space:
+//
+//   - KDTree answers range and nearest-neighbour queries over points of any
+//     fixed dimension by splitting on one axis per level.
+//   - Quadtree indexes 2-D points by recursively quartering a fixed region,
+//     adapting its depth to local density.
+//   - RTree indexes boxes of any dimension under a hierarchy of bounding
+//     boxes, the usual choice when the objects themselves have extent.
+//
+// Pruning makes all three much faster than a linear scan in two or three
+// dimensions, but the advantage shrinks as the dimension grows and bounding
+// regions stop excluding anything.
+package spatial
+
+import (
+	"fmt"
+	"math"
+)
+
+// Point is a position in space, one coordinate per dimension.
+type Point []float64
+
+// Rect is the closed axis-aligned box between Min and Max.
+type Rect struct {
+	Min, Max Point
+}
+
+// Contains reports whether p lies inside r, boundary included.
+func (r Rect) Contains(p Point) bool {
+	for i := range p {
+		if p[i] < r.Min[i] || p[i] > r.Max[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Intersects reports whether r and o share at least one point.
+func (r Rect) Intersects(o Rect) bool {
+	for i := range r.Min {
+		if r.Min[i] > o.Max[i] || o.Min[i] > r.Max[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Covers reports whether o lies entirely inside r.
+func (r Rect) Covers(o Rect) bool {
+	for i := range r.Min {
+		if o.Min[i] < r.Min[i] || o.Max[i] > r.Max[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Equal reports whether r and o have the same corners.
+func (r Rect) Equal(o Rect) bool {
+	for i := range r.Min {
+		if r.Min[i] != o.Min[i] || r.Max[i] != o.Max[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Area returns the volume of r.
+func (r Rect) Area() float64 {
+	a := 1.0
+	for i := range r.Min {
+		a *= r.Max[i] - r.Min[i]
+	}
+	return a
+}
+
+// Union returns the smallest box containing both r and o.
+func (r Rect) Union(o Rect) Rect {
+	u := Rect{Min: make(Point, len(r.Min)), Max: make(Point, len(r.Max))}
+	for i := range r.Min {
+		u.Min[i] = min(r.Min[i], o.Min[i])
+		u.Max[i] = max(r.Max[i], o.Max[i])
+	}
+	return u
+}
+
+// Valid reports whether r has dims coordinates per corner and Min <= Max on
+// every axis.
+func (r Rect) Valid(dims int) bool {
+	if len(r.Min) != dims || len(r.Max) != dims {
+		return false
+	}
+	for i := range r.Min {
+		if !(r.Min[i] <= r.Max[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r Rect) clone() Rect {
+	return Rect{Min: append(Point(nil), r.Min...), Max: append(Point(nil), r.Max...)}
+}
+
+func (r Rect) String() string {
+	return fmt.Sprintf("%v-%v", r.Min, r.Max)
+}
+
+// Dist2 returns the squared Euclidean distance between a and b.
+func Dist2(a, b Point) float64 {
+	var d float64
+	for i := range a {
+		x := a[i] - b[i]
+		d += x * x
+	}
+	return d
+}
+
+// Dist returns the Euclidean distance between a and b.
+func Dist(a, b Point) float64 {
+	return math.Sqrt(Dist2(a, b))
+}
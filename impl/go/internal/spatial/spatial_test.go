@@ -0,0 +1,362 @@
+package spatial
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/errs"
+)
+
+func randomPoint(rng *rand.Rand, dims int) Point {
+	p := make(Point, dims)
+	for i := range p {
+		// A coarse grid produces duplicate coordinates and exact ties on
+		// splitting planes.
+		p[i] = float64(rng.Intn(50))
+	}
+	return p
+}
+
+func TestKDTreeNearestAgainstScan(t *testing.T) {
+	for _, dims := range []int{1, 2, 3, 5} {
+		rng := rand.New(rand.NewSource(int64(dims)))
+		tree := MustNewKDTree[int](dims)
+		var points []Point
+		for i := 0; i < 2000; i++ {
+			p := randomPoint(rng, dims)
+			points = append(points, p)
+			if err := tree.Insert(p, i); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if tree.Len() != len(points) {
+			t.Fatalf("expected %d points, got %d", len(points), tree.Len())
+		}
+
+		for trial := 0; trial < 200; trial++ {
+			q := randomPoint(rng, dims)
+			k := 1 + rng.Intn(10)
+			got, err := tree.Nearest(q, k)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			dists := make([]float64, len(points))
+			for i, p := range points {
+				dists[i] = Dist(q, p)
+			}
+			sort.Float64s(dists)
+			if len(got) != k {
+				t.Fatalf("dims=%d: expected %d neighbours, got %d", dims, k, len(got))
+			}
+			// Ties make the identity of neighbours ambiguous; the distances
+			// are not.
+			for i, n := range got {
+				if n.Dist != dists[i] {
+					t.Fatalf("dims=%d q=%v k=%d: neighbour %d at %v, expected %v", dims, q, k, i, n.Dist, dists[i])
+				}
+				if n.Dist != Dist(q, points[n.Value]) {
+					t.Fatalf("neighbour %d reports the wrong point", i)
+				}
+			}
+		}
+	}
+}
+
+func TestKDTreeRangeSearchAgainstScan(t *testing.T) {
+	const dims = 3
+	rng := rand.New(rand.NewSource(9))
+	tree := MustNewKDTree[int](dims)
+	var points []Point
+	for i := 0; i < 2000; i++ {
+		p := randomPoint(rng, dims)
+		points = append(points, p)
+		tree.Insert(p, i)
+	}
+
+	for trial := 0; trial < 200; trial++ {
+		a, b := randomPoint(rng, dims), randomPoint(rng, dims)
+		r := Rect{Min: make(Point, dims), Max: make(Point, dims)}
+		for i := range a {
+			r.Min[i], r.Max[i] = min(a[i], b[i]), max(a[i], b[i])
+		}
+
+		want := make(map[int]bool)
+		for i, p := range points {
+			if r.Contains(p) {
+				want[i] = true
+			}
+		}
+		got := make(map[int]bool)
+		if err := tree.RangeSearch(r, func(p Point, v int) bool {
+			if got[v] {
+				t.Fatalf("point %d reported twice", v)
+			}
+			got[v] = true
+			return true
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("range %v: expected %d points, got %d", r, len(want), len(got))
+		}
+		for v := range want {
+			if !got[v] {
+				t.Fatalf("range %v: missing point %v", r, points[v])
+			}
+		}
+	}
+}
+
+func TestKDTreeDimensionErrors(t *testing.T) {
+	tree := MustNewKDTree[string](2)
+	if err := tree.Insert(Point{1, 2, 3}, "x"); !errors.Is(err, ErrDimension) {
+		t.Errorf("expected ErrDimension, got %v", err)
+	}
+	if _, err := tree.Nearest(Point{1}, 1); !errors.Is(err, ErrDimension) {
+		t.Errorf("expected ErrDimension, got %v", err)
+	}
+	if err := tree.RangeSearch(Rect{Point{0, 0}, Point{1}}, nil); !errors.Is(err, ErrDimension) {
+		t.Errorf("expected ErrDimension, got %v", err)
+	}
+	if got, _ := tree.Nearest(Point{0, 0}, 3); len(got) != 0 {
+		t.Errorf("expected no neighbours in an empty tree, got %v", got)
+	}
+}
+
+func TestKDTreeInsertCopiesPoint(t *testing.T) {
+	tree := MustNewKDTree[int](2)
+	p := Point{1, 1}
+	tree.Insert(p, 0)
+	p[0] = 100
+	got, _ := tree.Nearest(Point{1, 1}, 1)
+	if got[0].Dist != 0 {
+		t.Errorf("expected stored point to be unaffected by caller mutation, got %v", got[0].Point)
+	}
+}
+
+func randomWindow(rng *rand.Rand) Rect {
+	x, y := rng.Float64()*100, rng.Float64()*100
+	w, h := rng.Float64()*30, rng.Float64()*30
+	return Rect{Min: Point{x, y}, Max: Point{x + w, y + h}}
+}
+
+func TestQuadtreeAgainstScan(t *testing.T) {
+	rng := rand.New(rand.NewSource(11))
+	q, err := NewQuadtree[int](Rect{Min: Point{0, 0}, Max: Point{100, 100}}, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	model := make(map[int]Point)
+
+	for i := 0; i < 5000; i++ {
+		if len(model) > 0 && rng.Intn(3) == 0 {
+			// Remove an arbitrary live point.
+			for id, p := range model {
+				if !q.Remove(p, id) {
+					t.Fatalf("Remove(%v, %d): expected true", p, id)
+				}
+				delete(model, id)
+				break
+			}
+		} else {
+			// Clustered, coarse coordinates exercise deep splits and
+			// duplicate points.
+			p := Point{float64(rng.Intn(20)), float64(rng.Intn(100))}
+			if err := q.Insert(p, i); err != nil {
+				t.Fatal(err)
+			}
+			model[i] = p
+		}
+		if err := q.CheckInvariants(); err != nil {
+			t.Fatalf("after op %d: %v", i, err)
+		}
+		if q.Len() != len(model) {
+			t.Fatalf("expected %d points, got %d", len(model), q.Len())
+		}
+
+		window := randomWindow(rng)
+		got := make(map[int]bool)
+		q.Search(window, func(p Point, id int) bool {
+			got[id] = true
+			return true
+		})
+		want := 0
+		for id, p := range model {
+			if window.Contains(p) {
+				want++
+				if !got[id] {
+					t.Fatalf("window %v: missing %d at %v", window, id, p)
+				}
+			}
+		}
+		if len(got) != want {
+			t.Fatalf("window %v: expected %d points, got %d", window, want, len(got))
+		}
+	}
+	if q.Remove(Point{1, 1}, -1) {
+		t.Errorf("expected Remove of absent entry to return false")
+	}
+}
+
+func TestConstructorsRejectInvalid(t *testing.T) {
+	unit := Rect{Min: Point{0, 0}, Max: Point{1, 1}}
+	checks := map[string]error{}
+	_, checks["NewKDTree(0)"] = NewKDTree[int](0)
+	_, checks["NewRTree(0, 0)"] = NewRTree[int](0, 0)
+	_, checks["NewRTree(2, 3)"] = NewRTree[int](2, 3)
+	_, checks["NewQuadtree(unit, -1)"] = NewQuadtree[int](unit, -1)
+	for name, err := range checks {
+		if !errors.Is(err, errs.ErrInvalidOption) {
+			t.Errorf("%s: expected ErrInvalidOption, got %v", name, err)
+		}
+	}
+}
+
+func TestQuadtreeErrors(t *testing.T) {
+	if _, err := NewQuadtree[int](Rect{Min: Point{0}, Max: Point{1}}, 0); !errors.Is(err, ErrDimension) {
+		t.Errorf("expected ErrDimension, got %v", err)
+	}
+	q := MustNewQuadtree[int](Rect{Min: Point{0, 0}, Max: Point{1, 1}}, 0)
+	if err := q.Insert(Point{2, 0}, 1); !errors.Is(err, ErrOutOfBounds) {
+		t.Errorf("expected ErrOutOfBounds, got %v", err)
+	}
+	// Many copies of one point stop splitting at the depth limit.
+	for i := 0; i < 100; i++ {
+		if err := q.Insert(Point{0.5, 0.5}, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := q.CheckInvariants(); err != nil {
+		t.Fatal(err)
+	}
+	if d := q.Depth(); d != maxQuadDepth {
+		t.Errorf("expected depth %d, got %d", maxQuadDepth, d)
+	}
+}
+
+func TestRTreeAgainstScan(t *testing.T) {
+	rng := rand.New(rand.NewSource(12))
+	tree := MustNewRTree[int](2, 6)
+	model := make(map[int]Rect)
+
+	for i := 0; i < 5000; i++ {
+		if len(model) > 0 && rng.Intn(3) == 0 {
+			for id, r := range model {
+				if !tree.Remove(r, id) {
+					t.Fatalf("Remove(%v, %d): expected true", r, id)
+				}
+				delete(model, id)
+				break
+			}
+		} else {
+			r := randomWindow(rng)
+			if err := tree.Insert(r, i); err != nil {
+				t.Fatal(err)
+			}
+			model[i] = r
+		}
+		if err := tree.CheckInvariants(); err != nil {
+			t.Fatalf("after op %d: %v", i, err)
+		}
+		if tree.Len() != len(model) {
+			t.Fatalf("expected %d boxes, got %d", len(model), tree.Len())
+		}
+
+		window := randomWindow(rng)
+		got := make(map[int]bool)
+		tree.Search(window, func(r Rect, id int) bool {
+			got[id] = true
+			return true
+		})
+		want := 0
+		for id, r := range model {
+			if window.Intersects(r) {
+				want++
+				if !got[id] {
+					t.Fatalf("window %v: missing %d at %v", window, id, r)
+				}
+			}
+		}
+		if len(got) != want {
+			t.Fatalf("window %v: expected %d boxes, got %d", window, want, len(got))
+		}
+	}
+
+	// Drain the tree completely; it must shrink back to a single leaf.
+	for id, r := range model {
+		if !tree.Remove(r, id) {
+			t.Fatalf("Remove(%v, %d): expected true", r, id)
+		}
+	}
+	if tree.Len() != 0 || tree.Height() != 1 {
+		t.Errorf("expected empty tree of height 1, got %d boxes at height %d", tree.Len(), tree.Height())
+	}
+}
+
+func TestRTreeErrors(t *testing.T) {
+	tree := MustNewRTree[string](2, 0)
+	if err := tree.Insert(Rect{Min: Point{1, 1}, Max: Point{0, 0}}, "x"); !errors.Is(err, ErrDimension) {
+		t.Errorf("expected ErrDimension for inverted box, got %v", err)
+	}
+	if err := tree.Search(Rect{Min: Point{0}, Max: Point{1}}, nil); !errors.Is(err, ErrDimension) {
+		t.Errorf("expected ErrDimension, got %v", err)
+	}
+	if tree.Remove(Rect{Min: Point{0, 0}, Max: Point{1, 1}}, "x") {
+		t.Errorf("expected Remove on empty tree to return false")
+	}
+}
+
+func TestParse(t *testing.T) {
+	p, err := ParsePoint("1.5, 2")
+	if err != nil || len(p) != 2 || p[0] != 1.5 || p[1] != 2 {
+		t.Errorf("expected [1.5 2], got %v (%v)", p, err)
+	}
+	r, err := ParseRect("0,1,10,11")
+	if err != nil || !r.Equal(Rect{Min: Point{0, 1}, Max: Point{10, 11}}) {
+		t.Errorf("expected [0 1]-[10 11], got %v (%v)", r, err)
+	}
+	for _, bad := range []string{"1,2,3", "5,5,1,1", "a,b"} {
+		if _, err := ParseRect(bad); err == nil {
+			t.Errorf("ParseRect(%q): expected error", bad)
+		}
+	}
+}
+
+func TestVisualize(t *testing.T) {
+	kd := MustNewKDTree[string](2)
+	quad := MustNewQuadtree[string](Rect{Min: Point{0, 0}, Max: Point{10, 10}}, 2)
+	rt := MustNewRTree[string](2, 4)
+	for i, p := range []Point{{5, 5}, {2, 8}, {8, 2}, {1, 1}, {9, 9}, {3, 6}, {7, 4}} {
+		v := fmt.Sprint("p", i)
+		kd.Insert(p, v)
+		quad.Insert(p, v)
+		rt.Insert(Rect{Min: p, Max: p}, v)
+	}
+	cases := []struct {
+		name string
+		v    interface{ Visualize(io.Writer) error }
+		want []string
+	}{
+		{"kdtree", kd, []string{"[5 5]<BR/>p0<BR/><I>axis 0</I>", "x0 &lt; 5", "x1 &ge; 2"}},
+		{"quadtree", quad, []string{"<I>7 points</I>", `[label="NE"]`, "[9 9] p4"}},
+		{"rtree", rt, []string{"n0:e0 -> n1;", "[1 1]-[1 1]<BR/>p3"}},
+	}
+	for _, c := range cases {
+		var buf strings.Builder
+		if err := c.v.Visualize(&buf); err != nil {
+			t.Fatal(err)
+		}
+		dot := buf.String()
+		for _, want := range append([]string{"digraph " + c.name + " {"}, c.want...) {
+			if !strings.Contains(dot, want) {
+				t.Errorf("%s: expected DOT containing %q, got:\n%s", c.name, want, dot)
+			}
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package spatial
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// quadrantNames labels a quadtree node's children by quadrant index.
+var quadrantNames = [4]string{"SW", "SE", "NW", "NE"}
+
+// Visualize writes the tree as a Graphviz DOT graph. Each node shows its
+// point and value and the axis it splits on, and its edges are labelled with
+// the side of the split, so the alternating cuts of the space are visible.
+// Values are formatted with fmt.Sprint.
+func (t *KDTree[V]) Visualize(w io.Writer) error {
+	var b strings.Builder
+	beginGraph(&b, "kdtree", t.length == 0)
+	next := 0
+	var walk func(n *kdNode[V], depth int) string
+	walk = func(n *kdNode[V], depth int) string {
+		id := fmt.Sprintf("n%d", next)
+		next++
+		axis := depth % t.dims
+		fmt.Fprintf(&b, "  %s [label=<%s<BR/>%s<BR/><I>axis %d</I>>];\n", id, html.EscapeString(fmt.Sprint(n.point)), html.EscapeString(fmt.Sprint(n.value)), axis)
+		if n.left == nil && n.right == nil {
+			return id
+		}
+		for i, c := range []*kdNode[V]{n.left, n.right} {
+			label := fmt.Sprintf("x%d &lt; %g", axis, n.point[axis])
+			if i == 1 {
+				label = fmt.Sprintf("x%d &ge; %g", axis, n.point[axis])
+			}
+			if c == nil {
+				fmt.Fprintf(&b, "  %s_%d [shape=point];\n  %s -> %s_%d;\n", id, i, id, id, i)
+				continue
+			}
+			fmt.Fprintf(&b, "  %s -> %s [label=<%s>];\n", id, walk(c, depth+1), label)
+		}
+		return id
+	}
+	if t.root != nil {
+		walk(t.root, 0)
+	}
+	return endGraph(w, &b)
+}
+
+// Visualize writes the tree as a Graphviz DOT graph. Internal nodes show
+// their cell and point count, with edges labelled by quadrant; leaves list
+// their points and values, so the subdivision of dense areas is visible.
+// Values are formatted with fmt.Sprint.
+func (q *Quadtree[V]) Visualize(w io.Writer) error {
+	var b strings.Builder
+	beginGraph(&b, "quadtree", false)
+	next := 0
+	var walk func(n *quadNode[V]) string
+	walk = func(n *quadNode[V]) string {
+		id := fmt.Sprintf("n%d", next)
+		next++
+		fmt.Fprintf(&b, "  %s [label=<%s<BR/><I>%d points</I>", id, html.EscapeString(n.bounds.String()), n.count)
+		if n.children == nil {
+			for _, it := range n.items {
+				fmt.Fprintf(&b, "<BR/>%s %s", html.EscapeString(fmt.Sprint(it.point)), html.EscapeString(fmt.Sprint(it.value)))
+			}
+			b.WriteString(">];\n")
+			return id
+		}
+		b.WriteString(">];\n")
+		for i, c := range n.children {
+			fmt.Fprintf(&b, "  %s -> %s [label=\"%s\"];\n", id, walk(c), quadrantNames[i])
+		}
+		return id
+	}
+	walk(q.root)
+	return endGraph(w, &b)
+}
+
+// Visualize writes the tree as a Graphviz DOT graph. Each node is a row of
+// its entries' bounding boxes, and every entry of an internal node has an
+// edge to the node it bounds, so the overlap between siblings that slows
+// searches is visible. Leaf entries also show their values, formatted with
+// fmt.Sprint.
+func (t *RTree[V]) Visualize(w io.Writer) error {
+	var b strings.Builder
+	beginGraph(&b, "rtree", t.length == 0)
+	next := 0
+	var walk func(n *rtreeNode[V]) string
+	walk = func(n *rtreeNode[V]) string {
+		id := fmt.Sprintf("n%d", next)
+		next++
+		fmt.Fprintf(&b, "  %s [shape=plaintext, label=<<TABLE BORDER=\"0\" CELLBORDER=\"1\" CELLSPACING=\"0\"><TR>", id)
+		for i, e := range n.entries {
+			label := html.EscapeString(e.rect.String())
+			if e.child == nil {
+				label += "<BR/>" + html.EscapeString(fmt.Sprint(e.value))
+			}
+			fmt.Fprintf(&b, "<TD PORT=\"e%d\">%s</TD>", i, label)
+		}
+		b.WriteString("</TR></TABLE>>];\n")
+		for i, e := range n.entries {
+			if e.child != nil {
+				fmt.Fprintf(&b, "  %s:e%d -> %s;\n", id, i, walk(e.child))
+			}
+		}
+		return id
+	}
+	if t.length > 0 {
+		walk(t.root)
+	}
+	return endGraph(w, &b)
+}
+
+func beginGraph(b *strings.Builder, name string, empty bool) {
+	fmt.Fprintf(b, "digraph %s {\n", name)
+	b.WriteString("  node [shape=box, fontname=\"monospace\"];\n")
+	if empty {
+		b.WriteString("  empty [shape=plaintext, label=\"empty\"];\n")
+	}
+}
+
+func endGraph(w io.Writer, b *strings.Builder) error {
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,44 @@
+package spatial
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// OpQuery is the window-query operation of spatial workloads generated by
+// tools/gen_workloads.py. Inserts and deletes use workload.OpInsert and
+// workload.OpDelete, keyed by object id.
+const OpQuery = "query"
+
+// ParsePoint parses comma-separated coordinates such as "12.5,40".
+func ParsePoint(s string) (Point, error) {
+	fields := strings.Split(s, ",")
+	p := make(Point, len(fields))
+	for i, f := range fields {
+		x, err := strconv.ParseFloat(strings.TrimSpace(f), 64)
+		if err != nil {
+			return nil, fmt.Errorf("spatial: parse point %q: %w", s, err)
+		}
+		p[i] = x
+	}
+	return p, nil
+}
+
+// ParseRect parses a box written as its minimum corner followed by its
+// maximum corner, such as "0,0,10,5" for a 2-D box.
+func ParseRect(s string) (Rect, error) {
+	p, err := ParsePoint(s)
+	if err != nil {
+		return Rect{}, err
+	}
+	if len(p)%2 != 0 {
+		return Rect{}, fmt.Errorf("spatial: parse rect %q: odd number of coordinates", s)
+	}
+	d := len(p) / 2
+	r := Rect{Min: p[:d:d], Max: p[d:]}
+	if !r.Valid(d) {
+		return Rect{}, fmt.Errorf("spatial: parse rect %q: min exceeds max", s)
+	}
+	return r, nil
+}
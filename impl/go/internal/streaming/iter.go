@@ -0,0 +1,29 @@
+//go:build go1.23
+
+package streaming
+
+import "iter"
+
+// All returns an iterator over the elements kept in heap storage order,
+// which is not sorted. Use Sorted for the greatest first.
+func (t *TopK[T]) All() iter.Seq[T] {
+	return t.h.All()
+}
+
+// All returns an iterator over the elements recorded: the lower half, then
+// the upper half, each in heap storage order. Every element of the lower
+// half is at most every element of the upper, but neither half is sorted.
+func (m *Median[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for x := range m.lower.All() {
+			if !yield(x) {
+				return
+			}
+		}
+		for x := range m.upper.All() {
+			if !yield(x) {
+				return
+			}
+		}
+	}
+}
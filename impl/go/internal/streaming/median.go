@@ -0,0 +1,78 @@
+package streaming
+
+import (
+	"cmp"
+
+	"github.com/dsa-lab/go/internal/heap"
+	"github.com/dsa-lab/go/internal/order"
+)
+
+// Median maintains the median of a stream with two heaps: a max-heap of the
+// lower half and a min-heap of the upper half, the lower holding the extra
+// element when the count is odd. The middle elements are the two roots, so
+// Median is O(1) and Add is O(log n). It keeps every element. It is not safe
+// for concurrent use.
+type Median[T any] struct {
+	lower   *heap.Heap[T] // max-heap
+	upper   *heap.Heap[T] // min-heap
+	compare order.Comparator[T]
+}
+
+// NewMedian creates an empty running median under compare.
+func NewMedian[T any](compare order.Comparator[T]) *Median[T] {
+	return &Median[T]{
+		lower:   heap.New(compare.Reverse()),
+		upper:   heap.New(compare),
+		compare: compare,
+	}
+}
+
+// NewMedianOrdered creates an empty running median of naturally ordered
+// values.
+func NewMedianOrdered[T cmp.Ordered]() *Median[T] {
+	return NewMedian(order.Natural[T]())
+}
+
+// Add records x.
+func (m *Median[T]) Add(x T) {
+	if top, ok := m.lower.Peek(); !ok || m.compare(top, x) >= 0 {
+		m.lower.Push(x)
+	} else {
+		m.upper.Push(x)
+	}
+	// Rebalance so that the lower half has as many elements as the upper
+	// half, or one more.
+	if m.lower.Len() > m.upper.Len()+1 {
+		x, _ := m.lower.Pop()
+		m.upper.Push(x)
+	} else if m.upper.Len() > m.lower.Len() {
+		x, _ := m.upper.Pop()
+		m.lower.Push(x)
+	}
+}
+
+// Len returns the number of elements recorded.
+func (m *Median[T]) Len() int {
+	return m.lower.Len() + m.upper.Len()
+}
+
+// Median returns the middle elements of the stream in sorted order: lo is
+// the lower median and hi the upper, equal when the count is odd. It returns
+// false if the stream is empty.
+func (m *Median[T]) Median() (lo, hi T, ok bool) {
+	lo, ok = m.lower.Peek()
+	if !ok {
+		return lo, lo, false
+	}
+	if m.upper.Len() < m.lower.Len() {
+		return lo, lo, true
+	}
+	hi, _ = m.upper.Peek()
+	return lo, hi, true
+}
+
+// Reset discards every element.
+func (m *Median[T]) Reset() {
+	m.lower.Clear()
+	m.upper.Clear()
+}
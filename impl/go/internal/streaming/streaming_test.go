@@ -0,0 +1,117 @@
+package streaming
+
+import (
+	"errors"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/errs"
+)
+
+// streams returns random streams of various lengths and value ranges, small
+// ranges giving many duplicates.
+func streams(rng *rand.Rand) [][]int {
+	var out [][]int
+	for _, n := range []int{0, 1, 2, 3, 10, 100, 1000, 5000} {
+		for _, span := range []int{3, 1000, 1 << 30} {
+			s := make([]int, n)
+			for i := range s {
+				s[i] = rng.Intn(span)
+			}
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func TestTopKMatchesSort(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, s := range streams(rng) {
+		for _, k := range []int{1, 5, 64, 10000} {
+			top := MustNewTopKOrdered[int](k)
+			for i, x := range s {
+				top.Add(x)
+				// Check the threshold against a sorted prefix now and then.
+				if i%97 != 0 {
+					continue
+				}
+				prefix := slices.Clone(s[:i+1])
+				slices.Sort(prefix)
+				want := prefix[max(len(prefix)-k, 0)]
+				if got, ok := top.Threshold(); !ok || got != want {
+					t.Fatalf("n=%d k=%d after %d: expected threshold %d, got %d", len(s), k, i+1, want, got)
+				}
+			}
+			sorted := slices.Clone(s)
+			slices.Sort(sorted)
+			slices.Reverse(sorted)
+			want := sorted[:min(k, len(sorted))]
+			if got := top.Sorted(); !slices.Equal(got, want) {
+				t.Fatalf("n=%d k=%d: expected %v, got %v", len(s), k, want, got)
+			}
+			// Sorted must leave the selector intact.
+			if got := top.Sorted(); !slices.Equal(got, want) {
+				t.Fatalf("n=%d k=%d: expected %v after Sorted, got %v", len(s), k, want, got)
+			}
+			if top.Seen() != len(s) {
+				t.Errorf("expected %d seen, got %d", len(s), top.Seen())
+			}
+		}
+	}
+}
+
+func TestTopKRejectsNonPositiveK(t *testing.T) {
+	for _, k := range []int{0, -1} {
+		if _, err := NewTopKOrdered[int](k); !errors.Is(err, errs.ErrInvalidOption) {
+			t.Errorf("NewTopKOrdered(%d): expected ErrInvalidOption, got %v", k, err)
+		}
+	}
+}
+
+func TestTopKAdd(t *testing.T) {
+	top := MustNewTopKOrdered[int](2)
+	for _, step := range []struct {
+		x    int
+		kept bool
+	}{{5, true}, {3, true}, {1, false}, {3, false}, {4, true}, {9, true}, {4, false}} {
+		if got := top.Add(step.x); got != step.kept {
+			t.Errorf("Add(%d): expected %v, got %v", step.x, step.kept, got)
+		}
+	}
+	if got := top.Sorted(); !slices.Equal(got, []int{9, 5}) {
+		t.Errorf("expected [9 5], got %v", got)
+	}
+	top.Reset()
+	if _, ok := top.Threshold(); ok || top.Len() != 0 || top.Seen() != 0 {
+		t.Error("expected an empty selector after Reset")
+	}
+}
+
+func TestMedianMatchesSort(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	for _, s := range streams(rng) {
+		m := NewMedianOrdered[int]()
+		if _, _, ok := m.Median(); ok {
+			t.Fatal("expected no median of an empty stream")
+		}
+		var prefix []int
+		for _, x := range s {
+			m.Add(x)
+			i, _ := slices.BinarySearch(prefix, x)
+			prefix = slices.Insert(prefix, i, x)
+			lo, hi, ok := m.Median()
+			wantLo, wantHi := prefix[(len(prefix)-1)/2], prefix[len(prefix)/2]
+			if !ok || lo != wantLo || hi != wantHi {
+				t.Fatalf("after %d: expected median (%d, %d), got (%d, %d, %v)", len(prefix), wantLo, wantHi, lo, hi, ok)
+			}
+		}
+		if m.Len() != len(s) {
+			t.Errorf("expected %d elements, got %d", len(s), m.Len())
+		}
+		m.Reset()
+		if m.Len() != 0 {
+			t.Error("expected an empty median after Reset")
+		}
+	}
+}
@@ -0,0 +1,100 @@
+// Package streaming provides exact order statistics over a stream seen one
+// element at a time: the K greatest elements so far, and the running median.
+// Unlike the estimators in internal/sketch their answers are exact, at the
+// cost of memory that grows with K or with the stream.
+package streaming
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/dsa-lab/go/internal/errs"
+	"github.com/dsa-lab/go/internal/heap"
+	"github.com/dsa-lab/go/internal/order"
+)
+
+// TopK keeps the k greatest elements of a stream under compare. They sit in a
+// min-heap of at most k elements whose root is the least of them, so an
+// element that does not beat the root is rejected in O(1) and one that does
+// replaces it in O(log k). It is not safe for concurrent use.
+type TopK[T any] struct {
+	k       int
+	h       *heap.Heap[T]
+	compare order.Comparator[T]
+	seen    int
+}
+
+// NewTopK creates a selector of the k greatest elements under compare. It
+// returns an error matching errs.ErrInvalidOption if k < 1.
+func NewTopK[T any](k int, compare order.Comparator[T]) (*TopK[T], error) {
+	if k < 1 {
+		return nil, fmt.Errorf("streaming: NewTopK(%d): %w", k, errs.ErrInvalidOption)
+	}
+	return &TopK[T]{k: k, h: heap.New(compare), compare: compare}, nil
+}
+
+// MustNewTopK is like NewTopK but panics if k < 1.
+func MustNewTopK[T any](k int, compare order.Comparator[T]) *TopK[T] {
+	return errs.Must(NewTopK(k, compare))
+}
+
+// NewTopKOrdered creates a selector of the k greatest naturally ordered
+// values, with the same validation as NewTopK.
+func NewTopKOrdered[T cmp.Ordered](k int) (*TopK[T], error) {
+	return NewTopK(k, order.Natural[T]())
+}
+
+// MustNewTopKOrdered is like NewTopKOrdered but panics if k < 1.
+func MustNewTopKOrdered[T cmp.Ordered](k int) *TopK[T] {
+	return errs.Must(NewTopKOrdered[T](k))
+}
+
+// Add offers x and reports whether it is now among the k greatest.
+func (t *TopK[T]) Add(x T) bool {
+	t.seen++
+	if t.h.Len() < t.k {
+		t.h.Push(x)
+		return true
+	}
+	if least, _ := t.h.Peek(); t.compare(least, x) >= 0 {
+		return false
+	}
+	t.h.Pop()
+	t.h.Push(x)
+	return true
+}
+
+// Len returns the number of elements kept, at most k.
+func (t *TopK[T]) Len() int {
+	return t.h.Len()
+}
+
+// Seen returns the number of elements offered since creation or Reset.
+func (t *TopK[T]) Seen() int {
+	return t.seen
+}
+
+// Threshold returns the least element kept, which a new element must exceed
+// to be kept once k are held, or false if none is kept.
+func (t *TopK[T]) Threshold() (T, bool) {
+	return t.h.Peek()
+}
+
+// Sorted returns the elements kept, greatest first.
+func (t *TopK[T]) Sorted() []T {
+	out := make([]T, 0, t.h.Len())
+	for t.h.Len() > 0 {
+		x, _ := t.h.Pop()
+		out = append(out, x)
+	}
+	t.h = heap.From(slices.Clone(out), t.compare)
+	slices.Reverse(out)
+	return out
+}
+
+// Reset discards every element.
+func (t *TopK[T]) Reset() {
+	t.h.Clear()
+	t.seen = 0
+}
@@ -0,0 +1,139 @@
+package succinct
+
+import (
+	"slices"
+
+	"github.com/dsa-lab/go/internal/bitset"
+)
+
+// defaultSampleRate is the spacing of the suffix array samples kept for
+// Locate when NewFMIndex is given none.
+const defaultSampleRate = 32
+
+// FMIndex is a full-text index that counts and locates the occurrences of a
+// pattern without scanning the text (Ferragina and Manzini, 2000).
+//
+// It keeps the Burrows-Wheeler transform of the text in a WaveletMatrix. Row
+// r of the index is the r-th suffix in sorted order, row 0 being the empty
+// suffix at the end, and the transform holds the byte before each row's
+// suffix. Count narrows the range of rows whose suffixes start with the
+// pattern one byte at a time from its end, with two Rank queries per byte,
+// so it takes O(m) time for a pattern of m bytes whatever the text's length.
+// Locate then walks each row back to the nearest sampled suffix position.
+//
+// The index does not keep the text, yet at about eleven bits per text byte
+// with the default sample rate, rank directories included, it is larger than
+// the text: compressed FM-indexes replace the flat wavelet matrix with
+// entropy-coded ones, which this one leaves out for clarity.
+type FMIndex struct {
+	bwt *WaveletMatrix
+	// endRow is the row whose suffix is the whole text: its transform byte
+	// is the end marker, which is stored as a 0 and excluded from ranks.
+	endRow int
+	// before[c] is the number of rows whose suffixes start with a byte
+	// below c, counting the empty suffix.
+	before [257]int
+
+	// sampled marks the rows whose text positions are kept; the rank of a
+	// row among them indexes samples.
+	sampled *bitset.Bitset
+	samples []int32
+}
+
+// NewFMIndex builds an FMIndex of text. Locate stores the position of every
+// suffix starting at a multiple of sampleRate, and takes up to sampleRate
+// steps per occurrence to reach one; sampleRate <= 0 means 32.
+func NewFMIndex(text string, sampleRate int) *FMIndex {
+	if sampleRate <= 0 {
+		sampleRate = defaultSampleRate
+	}
+	sa := SuffixArray(text)
+	n := len(text)
+	x := &FMIndex{sampled: bitset.MustNew(n + 1)}
+
+	bwt := make([]byte, n+1)
+	var counts [256]int
+	for r := 0; r <= n; r++ {
+		pos := n // row 0 is the empty suffix
+		if r > 0 {
+			pos = int(sa[r-1])
+		}
+		if pos == 0 {
+			x.endRow = r
+		} else {
+			bwt[r] = text[pos-1]
+		}
+		if pos%sampleRate == 0 {
+			x.sampled.Set(r)
+			x.samples = append(x.samples, int32(pos))
+		}
+	}
+	for i := 0; i < n; i++ {
+		counts[text[i]]++
+	}
+	x.before[0] = 1
+	for c := 0; c < 256; c++ {
+		x.before[c+1] = x.before[c] + counts[c]
+	}
+	x.bwt = NewWaveletMatrix(bwt)
+	x.sampled.Rank(x.sampled.Len())
+	return x
+}
+
+// Len returns the length of the indexed text.
+func (x *FMIndex) Len() int {
+	return x.bwt.Len() - 1
+}
+
+// occ returns the number of text bytes equal to c in the transform's first
+// r rows, leaving out the end marker.
+func (x *FMIndex) occ(c byte, r int) int {
+	k := x.bwt.Rank(c, r)
+	if c == 0 && x.endRow < r {
+		k--
+	}
+	return k
+}
+
+// rows returns the range [lo, hi) of rows whose suffixes start with pattern.
+func (x *FMIndex) rows(pattern string) (lo, hi int) {
+	lo, hi = 0, x.bwt.Len()
+	for i := len(pattern) - 1; i >= 0 && lo < hi; i-- {
+		c := pattern[i]
+		lo = x.before[c] + x.occ(c, lo)
+		hi = x.before[c] + x.occ(c, hi)
+	}
+	return lo, max(hi, lo)
+}
+
+// Count returns the number of possibly overlapping occurrences of pattern in
+// the text. Like strings.Count, it counts len(text)+1 empty matches for an
+// empty pattern.
+func (x *FMIndex) Count(pattern string) int {
+	lo, hi := x.rows(pattern)
+	return hi - lo
+}
+
+// Locate returns the starting positions of every occurrence of pattern in
+// the text, in ascending order.
+func (x *FMIndex) Locate(pattern string) []int {
+	lo, hi := x.rows(pattern)
+	positions := make([]int, 0, hi-lo)
+	for r := lo; r < hi; r++ {
+		positions = append(positions, x.position(r))
+	}
+	slices.Sort(positions)
+	return positions
+}
+
+// position returns the text position of row r's suffix by stepping to the
+// row of the suffix one byte earlier until reaching a sampled row.
+func (x *FMIndex) position(r int) int {
+	steps := 0
+	for !x.sampled.Test(r) {
+		c := x.bwt.Access(r)
+		r = x.before[c] + x.occ(c, r)
+		steps++
+	}
+	return int(x.samples[x.sampled.Rank(r)]) + steps
+}
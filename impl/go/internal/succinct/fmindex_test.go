@@ -0,0 +1,120 @@
+package succinct
+
+import (
+	"math/rand"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// naiveLocate returns every start of pattern in text, overlaps included.
+func naiveLocate(text, pattern string) []int {
+	var positions []int
+	for i := 0; i+len(pattern) <= len(text); i++ {
+		if text[i:i+len(pattern)] == pattern {
+			positions = append(positions, i)
+		}
+	}
+	return positions
+}
+
+// texts returns test texts: tiny alphabets with long repeats, every byte
+// value including NUL, and word-like prose.
+func texts(rng *rand.Rand) map[string]string {
+	random := func(n int, alphabet string) string {
+		b := make([]byte, n)
+		for i := range b {
+			b[i] = alphabet[rng.Intn(len(alphabet))]
+		}
+		return string(b)
+	}
+	words := []string{"the", "quick", "brown", "fox", "jumps", "over", "lazy", "dog", "then", "there"}
+	var prose strings.Builder
+	for prose.Len() < 20000 {
+		prose.WriteString(words[rng.Intn(len(words))])
+		prose.WriteByte(" \n"[rng.Intn(2)])
+	}
+	all := make([]byte, 5000)
+	for i := range all {
+		all[i] = byte(rng.Intn(256))
+	}
+	return map[string]string{
+		"empty":  "",
+		"single": "a",
+		"binary": random(5000, "ab"),
+		"dna":    random(20000, "acgt"),
+		"runs":   strings.Repeat("abracadabra", 500) + strings.Repeat("a", 1000),
+		"bytes":  string(all) + "\x00\x00\x00",
+		"prose":  prose.String(),
+	}
+}
+
+func TestSuffixArray(t *testing.T) {
+	for name, text := range texts(rand.New(rand.NewSource(1))) {
+		sa := SuffixArray(text)
+		want := make([]int32, len(text))
+		for i := range want {
+			want[i] = int32(i)
+		}
+		sort.Slice(want, func(i, j int) bool { return text[want[i]:] < text[want[j]:] })
+		if !slices.Equal(sa, want) {
+			t.Errorf("%s: expected the suffixes in sorted order", name)
+		}
+	}
+}
+
+func TestWaveletMatrix(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	data := make([]byte, 3000)
+	for i := range data {
+		data[i] = byte(rng.Intn(256) & rng.Intn(256))
+	}
+	w := NewWaveletMatrix(data)
+	var counts [256]int
+	for i, c := range data {
+		if got := w.Access(i); got != c {
+			t.Fatalf("Access(%d): expected %d, got %d", i, c, got)
+		}
+		probe := byte(rng.Intn(256))
+		if got := w.Rank(probe, i); got != counts[probe] {
+			t.Fatalf("Rank(%d, %d): expected %d, got %d", probe, i, counts[probe], got)
+		}
+		counts[c]++
+	}
+	for c := 0; c < 256; c++ {
+		if got := w.Rank(byte(c), len(data)); got != counts[c] {
+			t.Fatalf("Rank(%d) over everything: expected %d, got %d", c, counts[c], got)
+		}
+	}
+}
+
+// TestFMIndexOracle checks Count and Locate against a naive search for
+// patterns cut from the text, so they occur, and random ones, which mostly
+// do not.
+func TestFMIndexOracle(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	for name, text := range texts(rng) {
+		for _, rate := range []int{1, 7, 0} {
+			x := NewFMIndex(text, rate)
+			if x.Len() != len(text) {
+				t.Fatalf("%s: expected length %d, got %d", name, len(text), x.Len())
+			}
+			patterns := []string{"", "a", "ab", "the ", "zzzz", "\x00", text}
+			for i := 0; i < 200 && len(text) > 0; i++ {
+				start := rng.Intn(len(text))
+				end := min(len(text), start+1+rng.Intn(12))
+				patterns = append(patterns, text[start:end], text[start:end]+"q")
+			}
+			for _, p := range patterns {
+				want := naiveLocate(text, p)
+				if got := x.Count(p); got != len(want) {
+					t.Fatalf("%s rate %d: Count(%q): expected %d, got %d", name, rate, p, len(want), got)
+				}
+				if got := x.Locate(p); !slices.Equal(got, want) && len(want)+len(got) > 0 {
+					t.Fatalf("%s rate %d: Locate(%q): expected %v, got %v", name, rate, p, want, got)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,40 @@
+//go:build go1.23
+
+package succinct
+
+import "iter"
+
+// All returns an iterator over the keys and their values in ascending key
+// order. A Trie built without values yields empty values.
+func (t *Trie) All() iter.Seq2[string, string] {
+	return func(yield func(string, string) bool) {
+		var buf []byte
+		t.walk(0, &buf, func(v int, key string) bool {
+			var value string
+			if t.values != nil {
+				value = t.values[t.terminal.Rank(v)]
+			}
+			return yield(key, value)
+		})
+	}
+}
+
+// Keys returns an iterator over the keys in ascending order.
+func (t *Trie) Keys() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		var buf []byte
+		t.walk(0, &buf, func(_ int, key string) bool { return yield(key) })
+	}
+}
+
+// All returns an iterator over the positions and bytes of the sequence in
+// order. Each byte costs an Access, one rank query per level.
+func (w *WaveletMatrix) All() iter.Seq2[int, byte] {
+	return func(yield func(int, byte) bool) {
+		for i := 0; i < w.n; i++ {
+			if !yield(i, w.Access(i)) {
+				return
+			}
+		}
+	}
+}
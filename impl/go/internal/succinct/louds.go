@@ -0,0 +1,199 @@
+// Package succinct provides static structures stored in close to the
+// information-theoretic minimum of bits, navigated with rank and select
+// queries instead of pointers.
+//
+// Trie is a LOUDS-encoded trie (level-order unary degree sequence, Jacobson
+// 1989). Its nodes are numbered in breadth-first order, and the shape of the
+// whole tree is one bit sequence in which each node, in that order, writes a
+// 0 for every child and then a 1. A node costs about two bits of shape, one
+// byte for the label of the edge into it and one bit marking whether a key
+// ends there, where a pointer-based trie spends a pointer or more on every
+// edge.
+//
+// FMIndex counts and locates substrings of a text using a WaveletMatrix over
+// its Burrows-Wheeler transform, which SuffixArray computes.
+package succinct
+
+import (
+	"sort"
+
+	"github.com/dsa-lab/go/internal/bitset"
+)
+
+// Trie is an immutable set of strings, optionally mapped to values, with
+// lookup and prefix enumeration. It is safe for concurrent use.
+type Trie struct {
+	// shape holds the degree sequence, preceded by "01" for a virtual super
+	// root whose one child is the root. The children of node v are numbered
+	// from select(v)+1-(v+1) on, one per 0 between the v-th and (v+1)-th 1.
+	shape *bitset.Bitset
+	// labels[v] is the byte on the edge into node v; sibling labels ascend.
+	labels []byte
+	// terminal marks the nodes where a key ends. The rank of a terminal
+	// node among them indexes values.
+	terminal *bitset.Bitset
+	values   []string
+	n        int
+}
+
+// span is a node waiting to be laid out: the keys in [lo, hi) share their
+// first depth bytes, which lead to the node.
+type span struct {
+	lo, hi, depth int
+}
+
+// Build creates a Trie holding keys. If values is not nil it must be as long
+// as keys, and Get returns values[i] for keys[i]; a key given more than once
+// keeps its last value. The inputs are not modified.
+func Build(keys, values []string) *Trie {
+	order := make([]int, len(keys))
+	for i := range order {
+		order[i] = i
+	}
+	// A stable sort keeps duplicates in input order, so the last one is
+	// the one kept below.
+	sort.SliceStable(order, func(a, b int) bool { return keys[order[a]] < keys[order[b]] })
+	sorted := make([]string, 0, len(keys))
+	var sortedValues []string
+	for i, j := range order {
+		if i+1 < len(order) && keys[order[i+1]] == keys[j] {
+			continue
+		}
+		sorted = append(sorted, keys[j])
+		if values != nil {
+			sortedValues = append(sortedValues, values[j])
+		}
+	}
+
+	t := &Trie{
+		shape:    bitset.MustNew(0),
+		labels:   []byte{0},
+		terminal: bitset.MustNew(0),
+		n:        len(sorted),
+	}
+	// The super root: one child, then its terminator.
+	pos := 1
+	t.shape.Set(pos)
+	pos++
+
+	// Nodes are numbered in the order they leave the queue. Consumed spans
+	// are dropped, so the queue holds about two levels at a time.
+	queue := []span{{0, len(sorted), 0}}
+	for v := 0; len(queue) > 0; v++ {
+		s := queue[0]
+		queue = queue[1:]
+		lo := s.lo
+		if lo < s.hi && len(sorted[lo]) == s.depth {
+			t.terminal.Set(v)
+			if values != nil {
+				t.values = append(t.values, sortedValues[lo])
+			}
+			lo++
+		}
+		for lo < s.hi {
+			c := sorted[lo][s.depth]
+			hi := lo + 1
+			for hi < s.hi && sorted[hi][s.depth] == c {
+				hi++
+			}
+			queue = append(queue, span{lo, hi, s.depth + 1})
+			t.labels = append(t.labels, c)
+			pos++
+			lo = hi
+		}
+		t.shape.Set(pos)
+		pos++
+	}
+	// Build the rank directories now, so queries never modify the Trie.
+	t.shape.Rank(t.shape.Len())
+	t.terminal.Rank(t.terminal.Len())
+	return t
+}
+
+// Len returns the number of keys.
+func (t *Trie) Len() int {
+	return t.n
+}
+
+// Nodes returns the number of nodes, the root included.
+func (t *Trie) Nodes() int {
+	return len(t.labels)
+}
+
+// children returns the number of node v's first child and how many children
+// it has.
+func (t *Trie) children(v int) (first, count int) {
+	start, _ := t.shape.Select(v)
+	end, _ := t.shape.NextSet(start + 1)
+	return start + 1 - (v + 1), end - start - 1
+}
+
+// child returns the child of v along the edge labelled c.
+func (t *Trie) child(v int, c byte) (int, bool) {
+	first, count := t.children(v)
+	sibs := t.labels[first : first+count]
+	i := sort.Search(len(sibs), func(i int) bool { return sibs[i] >= c })
+	if i == len(sibs) || sibs[i] != c {
+		return 0, false
+	}
+	return first + i, true
+}
+
+// find returns the node reached by following key from the root.
+func (t *Trie) find(key string) (int, bool) {
+	v := 0
+	for i := 0; i < len(key); i++ {
+		var ok bool
+		if v, ok = t.child(v, key[i]); !ok {
+			return 0, false
+		}
+	}
+	return v, true
+}
+
+// Contains reports whether key is in the trie.
+func (t *Trie) Contains(key string) bool {
+	v, ok := t.find(key)
+	return ok && t.terminal.Test(v)
+}
+
+// Get returns the value of key and true, or false if key is absent. A Trie
+// built without values returns the empty string for present keys.
+func (t *Trie) Get(key string) (string, bool) {
+	v, ok := t.find(key)
+	if !ok || !t.terminal.Test(v) {
+		return "", false
+	}
+	if t.values == nil {
+		return "", true
+	}
+	return t.values[t.terminal.Rank(v)], true
+}
+
+// WithPrefix calls f for every key that starts with prefix, in ascending
+// order, until f returns false.
+func (t *Trie) WithPrefix(prefix string, f func(key string) bool) {
+	v, ok := t.find(prefix)
+	if !ok {
+		return
+	}
+	buf := []byte(prefix)
+	t.walk(v, &buf, func(_ int, key string) bool { return f(key) })
+}
+
+// walk visits the keys below v in order, passing f each key's node; buf
+// holds the path to v.
+func (t *Trie) walk(v int, buf *[]byte, f func(v int, key string) bool) bool {
+	if t.terminal.Test(v) && !f(v, string(*buf)) {
+		return false
+	}
+	first, count := t.children(v)
+	for c := first; c < first+count; c++ {
+		*buf = append(*buf, t.labels[c])
+		if !t.walk(c, buf, f) {
+			return false
+		}
+		*buf = (*buf)[:len(*buf)-1]
+	}
+	return true
+}
@@ -0,0 +1,102 @@
+package succinct
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTrie(t *testing.T) {
+	keys := []string{"tea", "ten", "to", "inn", "in", "i", "", "tea", "A"}
+	values := []string{"1", "2", "3", "4", "5", "6", "7", "8", "9"}
+	tr := Build(keys, values)
+	if tr.Len() != 8 {
+		t.Fatalf("expected 8 distinct keys, got %d", tr.Len())
+	}
+	for key, want := range map[string]string{"tea": "8", "ten": "2", "to": "3", "i": "6", "": "7", "A": "9"} {
+		if got, ok := tr.Get(key); !ok || got != want {
+			t.Errorf("get %q: expected %q, got %q %v", key, want, got, ok)
+		}
+	}
+	for _, key := range []string{"t", "te", "teas", "a", "x", "innn"} {
+		if tr.Contains(key) {
+			t.Errorf("expected %q absent", key)
+		}
+	}
+
+	var got []string
+	tr.WithPrefix("t", func(key string) bool {
+		got = append(got, key)
+		return true
+	})
+	if fmt.Sprint(got) != "[tea ten to]" {
+		t.Errorf("expected keys under t in order, got %v", got)
+	}
+	got = got[:0]
+	tr.WithPrefix("", func(key string) bool {
+		got = append(got, key)
+		return len(got) < 4
+	})
+	if fmt.Sprint(got) != "[ A i in]" {
+		t.Errorf("expected the first four keys in order, got %q", got)
+	}
+}
+
+func TestEmptyTrie(t *testing.T) {
+	tr := Build(nil, nil)
+	if tr.Len() != 0 || tr.Contains("") || tr.Nodes() != 1 {
+		t.Errorf("expected only a root, got %d keys and %d nodes", tr.Len(), tr.Nodes())
+	}
+	tr.WithPrefix("", func(string) bool {
+		t.Error("expected no keys")
+		return true
+	})
+}
+
+func TestRandomDictionary(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	set := make(map[string]string)
+	var keys, values []string
+	for i := 0; i < 20000; i++ {
+		// Short keys over a small alphabet share many prefixes.
+		n := 1 + rng.Intn(12)
+		b := make([]byte, n)
+		for j := range b {
+			b[j] = "abcdz\x00\xff"[rng.Intn(7)]
+		}
+		key := string(b)
+		keys = append(keys, key)
+		values = append(values, fmt.Sprint(i))
+		set[key] = fmt.Sprint(i)
+	}
+	tr := Build(keys, values)
+	if tr.Len() != len(set) {
+		t.Fatalf("expected %d keys, got %d", len(set), tr.Len())
+	}
+	for key, want := range set {
+		if got, ok := tr.Get(key); !ok || got != want {
+			t.Fatalf("get %q: expected %q, got %q %v", key, want, got, ok)
+		}
+		if tr.Contains(key + "q") {
+			t.Fatalf("expected %q absent", key+"q")
+		}
+	}
+
+	want := make([]string, 0, len(set))
+	for key := range set {
+		if strings.HasPrefix(key, "ab") {
+			want = append(want, key)
+		}
+	}
+	slices.Sort(want)
+	var got []string
+	tr.WithPrefix("ab", func(key string) bool {
+		got = append(got, key)
+		return true
+	})
+	if !slices.Equal(got, want) {
+		t.Errorf("expected %d keys under ab in order, got %d", len(want), len(got))
+	}
+}
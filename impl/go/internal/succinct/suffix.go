@@ -0,0 +1,49 @@
+package succinct
+
+import "sort"
+
+// SuffixArray returns the starting positions of the suffixes of text in
+// ascending order of the suffixes, a suffix that is a prefix of another
+// sorting first. It sorts by prefix doubling: after the round for k, suffixes
+// are ranked by their first 2k bytes, found by sorting on the pair of ranks
+// of their first k bytes and of the k bytes after. That is O(n log^2 n)
+// time, fine for texts of a few megabytes.
+func SuffixArray(text string) []int32 {
+	n := len(text)
+	sa := make([]int32, n)
+	rank := make([]int32, n)
+	next := make([]int32, n)
+	for i := range sa {
+		sa[i] = int32(i)
+		rank[i] = int32(text[i])
+	}
+	for k := 1; n > 1; k *= 2 {
+		// second is the rank of the k bytes after i, -1 past the end.
+		second := func(i int32) int32 {
+			if int(i)+k < n {
+				return rank[int(i)+k]
+			}
+			return -1
+		}
+		less := func(a, b int32) bool {
+			if rank[a] != rank[b] {
+				return rank[a] < rank[b]
+			}
+			return second(a) < second(b)
+		}
+		sort.Slice(sa, func(i, j int) bool { return less(sa[i], sa[j]) })
+
+		next[sa[0]] = 0
+		for i := 1; i < n; i++ {
+			next[sa[i]] = next[sa[i-1]]
+			if less(sa[i-1], sa[i]) {
+				next[sa[i]]++
+			}
+		}
+		rank, next = next, rank
+		if int(rank[sa[n-1]]) == n-1 || k >= n {
+			break
+		}
+	}
+	return sa
+}
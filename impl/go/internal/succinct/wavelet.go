@@ -0,0 +1,99 @@
+package succinct
+
+import "github.com/dsa-lab/go/internal/bitset"
+
+// byteBits is the number of levels of a WaveletMatrix over bytes.
+const byteBits = 8
+
+// WaveletMatrix is a static byte sequence that answers Rank, the number of
+// occurrences of a byte in a prefix, in O(8) bitset rank queries. It is the
+// pointer-free layout of a wavelet tree: level l holds bit l of every byte,
+// counting from the most significant, with the bytes reordered stably by
+// their higher bits, zeros first, as a wavelet tree's nodes would be laid out
+// side by side. It takes eight bits per byte plus the rank directories.
+type WaveletMatrix struct {
+	levels [byteBits]*bitset.Bitset
+	// zeros[l] is how many bytes have a 0 at level l; the bytes with a 1
+	// follow them at the next level.
+	zeros [byteBits]int
+	n     int
+}
+
+// NewWaveletMatrix creates a WaveletMatrix holding data.
+func NewWaveletMatrix(data []byte) *WaveletMatrix {
+	w := &WaveletMatrix{n: len(data)}
+	cur := append([]byte(nil), data...)
+	next := make([]byte, len(data))
+	for l := 0; l < byteBits; l++ {
+		shift := byteBits - 1 - l
+		bits := bitset.MustNew(len(data))
+		for i, c := range cur {
+			if c>>shift&1 == 1 {
+				bits.Set(i)
+			}
+		}
+		// Partition stably: zeros first, then ones.
+		z := 0
+		for _, c := range cur {
+			if c>>shift&1 == 0 {
+				next[z] = c
+				z++
+			}
+		}
+		o := z
+		for _, c := range cur {
+			if c>>shift&1 == 1 {
+				next[o] = c
+				o++
+			}
+		}
+		bits.Rank(bits.Len())
+		w.levels[l], w.zeros[l] = bits, z
+		cur, next = next, cur
+	}
+	return w
+}
+
+// Len returns the length of the sequence.
+func (w *WaveletMatrix) Len() int {
+	return w.n
+}
+
+// rank0 returns the number of zeros before position i of level l.
+func (w *WaveletMatrix) rank0(l, i int) int {
+	return i - w.levels[l].Rank(i)
+}
+
+// Access returns the byte at position i.
+func (w *WaveletMatrix) Access(i int) byte {
+	var c byte
+	for l := 0; l < byteBits; l++ {
+		c <<= 1
+		if w.levels[l].Test(i) {
+			c |= 1
+			i = w.zeros[l] + w.levels[l].Rank(i)
+		} else {
+			i = w.rank0(l, i)
+		}
+	}
+	return c
+}
+
+// Rank returns the number of occurrences of c among the first i bytes.
+func (w *WaveletMatrix) Rank(c byte, i int) int {
+	i = min(max(i, 0), w.n)
+	// [start, i) tracks the positions, within each level, of the bytes
+	// whose higher bits match c's so far; start begins at the first of
+	// them and i just past those from the prefix.
+	start := 0
+	for l := 0; l < byteBits; l++ {
+		if c>>(byteBits-1-l)&1 == 1 {
+			start = w.zeros[l] + w.levels[l].Rank(start)
+			i = w.zeros[l] + w.levels[l].Rank(i)
+		} else {
+			start = w.rank0(l, start)
+			i = w.rank0(l, i)
+		}
+	}
+	return i - start
+}
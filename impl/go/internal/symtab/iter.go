@@ -0,0 +1,17 @@
+//go:build go1.23
+
+package symtab
+
+import "iter"
+
+// All returns an iterator over the IDs and their names in ID order, which is
+// the order the names were first interned.
+func (t *Table) All() iter.Seq2[ID, string] {
+	return func(yield func(ID, string) bool) {
+		for i, name := range t.names {
+			if !yield(ID(i), name) {
+				return
+			}
+		}
+	}
+}
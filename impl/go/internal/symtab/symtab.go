@@ -0,0 +1,165 @@
+// Package symtab provides a symbol table: it assigns each distinct string a
+// small, stable integer ID and maps IDs back to their strings. A structure
+// that stores IDs instead of strings, such as an edge list or a replayed key
+// stream, holds four bytes per reference instead of a sixteen-byte string
+// header plus a copy of the bytes.
+//
+// Names are copied into an append-only arena of large chunks, each followed
+// by its ID in four bytes, so the table allocates once per chunk rather than
+// once per name. The forward index is the lab's hashmap, whose keys and values
+// are views into the arena: the name, and the encoded ID after it. It hashes
+// with SipHash under a random key, so names taken from untrusted input cannot
+// be chosen to collide.
+package symtab
+
+import (
+	"math"
+	"strings"
+
+	"github.com/dsa-lab/go/internal/errs"
+	"github.com/dsa-lab/go/internal/hashmap"
+	"github.com/dsa-lab/go/internal/hashseed"
+)
+
+// ErrFull is the value Intern panics with when every ID is taken.
+var ErrFull = errs.New(errs.ErrCapacityExceeded, "symtab: too many symbols")
+
+const (
+	// chunkSize is the size of an arena chunk. Names longer than a quarter
+	// of a chunk get a chunk of their own, so one long name does not leave
+	// most of a chunk unused.
+	chunkSize = 64 << 10
+
+	// idBytes is the size of an ID as stored after its name.
+	idBytes = 4
+)
+
+// ID identifies a symbol. IDs are assigned densely from 0 in the order names
+// are first interned, and a name keeps its ID for the life of the table.
+type ID uint32
+
+// Stats describes the memory a Table holds.
+type Stats struct {
+	// Symbols is the number of distinct names.
+	Symbols int
+	// NameBytes is the total length of the names; ArenaBytes is the space
+	// reserved across all chunks for them and their encoded IDs.
+	NameBytes  int
+	ArenaBytes int
+	Chunks     int
+}
+
+// Table is a bidirectional mapping between strings and IDs. It is not safe
+// for concurrent use.
+type Table struct {
+	index *hashmap.HashMap
+	names []string
+
+	chunk *strings.Builder
+	stats Stats
+}
+
+// Option configures a Table at construction time.
+type Option func(*config)
+
+type config struct {
+	seed uint64
+}
+
+// WithSeed keys the table's hash from seed instead of a random seed, which
+// makes its hashmap's layout reproducible between runs. IDs do not depend on
+// the seed.
+func WithSeed(seed uint64) Option {
+	return func(c *config) {
+		c.seed = seed
+	}
+}
+
+// New creates an empty Table.
+func New(opts ...Option) *Table {
+	c := config{seed: hashseed.New()}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	k0, k1 := hashseed.Derive(c.seed, 0), hashseed.Derive(c.seed, 1)
+	return &Table{
+		index: hashmap.MustNew(hashmap.WithHashFunc(func(key string) uint64 {
+			return hashseed.SipHash(k0, k1, key)
+		})),
+	}
+}
+
+// Len returns the number of symbols.
+func (t *Table) Len() int {
+	return len(t.names)
+}
+
+// Intern returns the ID of name, assigning the next ID if name is new. It
+// panics with ErrFull if name is new and all 2^32 IDs are in use.
+func (t *Table) Intern(name string) ID {
+	if id, ok := t.Lookup(name); ok {
+		return id
+	}
+	if uint64(len(t.names)) > math.MaxUint32 {
+		panic(ErrFull)
+	}
+	id := ID(len(t.names))
+	stored, encoded := t.store(name, id)
+	t.names = append(t.names, stored)
+	t.index.Insert(stored, encoded)
+	t.stats.Symbols++
+	t.stats.NameBytes += len(name)
+	return id
+}
+
+// store copies name and the encoding of id into the arena and returns both
+// copies.
+func (t *Table) store(name string, id ID) (string, string) {
+	size := len(name) + idBytes
+	if len(name) > chunkSize/4 {
+		var b strings.Builder
+		b.Grow(size)
+		t.stats.ArenaBytes += size
+		t.stats.Chunks++
+		return appendSymbol(&b, name, id)
+	}
+	if t.chunk == nil || t.chunk.Len()+size > t.chunk.Cap() {
+		// Strings already handed out keep the old chunk alive; a Builder
+		// never changes bytes it has written, so they stay valid.
+		t.chunk = new(strings.Builder)
+		t.chunk.Grow(chunkSize)
+		t.stats.ArenaBytes += t.chunk.Cap()
+		t.stats.Chunks++
+	}
+	return appendSymbol(t.chunk, name, id)
+}
+
+func appendSymbol(b *strings.Builder, name string, id ID) (string, string) {
+	start := b.Len()
+	b.WriteString(name)
+	for i := 0; i < idBytes; i++ {
+		b.WriteByte(byte(id >> (8 * i)))
+	}
+	s := b.String()[start:]
+	return s[:len(name)], s[len(name):]
+}
+
+// Lookup returns the ID of name and true, or false if name has no ID.
+func (t *Table) Lookup(name string) (ID, bool) {
+	v, found := t.index.Get(name)
+	if !found {
+		return 0, false
+	}
+	return ID(v[0]) | ID(v[1])<<8 | ID(v[2])<<16 | ID(v[3])<<24, true
+}
+
+// Name returns the string with the given ID. It panics if id was not
+// returned by Intern.
+func (t *Table) Name(id ID) string {
+	return t.names[id]
+}
+
+// Stats returns the table's memory counts.
+func (t *Table) Stats() Stats {
+	return t.stats
+}
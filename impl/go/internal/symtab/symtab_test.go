@@ -0,0 +1,62 @@
+package symtab
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestInternAndName(t *testing.T) {
+	tab := New(WithSeed(1))
+	long := strings.Repeat("x", chunkSize)
+	var names []string
+	for i := 0; i < 20000; i++ {
+		names = append(names, fmt.Sprintf("node-%d", i))
+	}
+	names = append(names, "", long)
+
+	for i, name := range names {
+		if id := tab.Intern(name); id != ID(i) {
+			t.Fatalf("expected %q to get ID %d, got %d", name, i, id)
+		}
+	}
+	// Interning again, from a fresh copy, returns the same IDs.
+	for i, name := range names {
+		if id := tab.Intern(strings.Clone(name)); id != ID(i) {
+			t.Fatalf("expected %q to keep ID %d, got %d", name, i, id)
+		}
+		if got := tab.Name(ID(i)); got != name {
+			t.Fatalf("expected name %q for ID %d, got %q", name, i, got)
+		}
+	}
+	if _, ok := tab.Lookup("absent"); ok || tab.Len() != len(names) {
+		t.Errorf("expected Lookup not to add, got %d symbols", tab.Len())
+	}
+
+	s := tab.Stats()
+	if s.Symbols != len(names) || s.Chunks < 3 || s.NameBytes > s.ArenaBytes {
+		t.Errorf("expected %d symbols over several chunks, got %+v", len(names), s)
+	}
+}
+
+func TestSeedDoesNotChangeIDs(t *testing.T) {
+	a, b := New(WithSeed(1)), New(WithSeed(2))
+	for i := 0; i < 1000; i++ {
+		name := fmt.Sprint(i * 7919)
+		if x, y := a.Intern(name), b.Intern(name); x != y {
+			t.Fatalf("expected the same ID under both seeds, got %d and %d", x, y)
+		}
+	}
+}
+
+func TestInternAllocations(t *testing.T) {
+	tab := New()
+	for i := 0; i < 1000; i++ {
+		tab.Intern(fmt.Sprint(i))
+	}
+	name := "already-present"
+	tab.Intern(name)
+	if allocs := testing.AllocsPerRun(100, func() { tab.Intern(name) }); allocs != 0 {
+		t.Errorf("expected interning a known name not to allocate, got %v allocations", allocs)
+	}
+}
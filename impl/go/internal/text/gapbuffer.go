@@ -0,0 +1,101 @@
+package text
+
+import "strings"
+
+const minGap = 64
+
+// GapBuffer stores the text in a single byte slice with an unused gap at the
+// last edit position. Edits at the gap cost O(1) amortized; an edit elsewhere
+// first moves the gap there, copying every byte in between. Sequential typing,
+// the common case in an editor, therefore stays cheap while random edits over
+// a large text do not.
+type GapBuffer struct {
+	buf      []byte
+	gapStart int
+	gapEnd   int
+}
+
+// NewGapBuffer creates a gap buffer holding s with the gap at the end.
+func NewGapBuffer(s string) *GapBuffer {
+	buf := make([]byte, len(s)+minGap)
+	copy(buf, s)
+	return &GapBuffer{buf: buf, gapStart: len(s), gapEnd: len(buf)}
+}
+
+// Len returns the length of the text in bytes.
+func (g *GapBuffer) Len() int {
+	return len(g.buf) - (g.gapEnd - g.gapStart)
+}
+
+// moveGap moves the gap to start at pos.
+func (g *GapBuffer) moveGap(pos int) {
+	switch {
+	case pos < g.gapStart:
+		n := g.gapStart - pos
+		copy(g.buf[g.gapEnd-n:g.gapEnd], g.buf[pos:g.gapStart])
+		g.gapStart -= n
+		g.gapEnd -= n
+	case pos > g.gapStart:
+		n := pos - g.gapStart
+		copy(g.buf[g.gapStart:g.gapStart+n], g.buf[g.gapEnd:g.gapEnd+n])
+		g.gapStart += n
+		g.gapEnd += n
+	}
+}
+
+// grow enlarges the gap to at least need bytes, doubling the buffer.
+func (g *GapBuffer) grow(need int) {
+	size := max(2*len(g.buf), g.Len()+need+minGap)
+	buf := make([]byte, size)
+	copy(buf, g.buf[:g.gapStart])
+	tail := len(g.buf) - g.gapEnd
+	copy(buf[size-tail:], g.buf[g.gapEnd:])
+	g.buf, g.gapEnd = buf, size-tail
+}
+
+// Insert inserts s before the byte at pos.
+func (g *GapBuffer) Insert(pos int, s string) error {
+	if err := checkRange(pos, 0, g.Len()); err != nil {
+		return err
+	}
+	if len(s) > g.gapEnd-g.gapStart {
+		g.grow(len(s))
+	}
+	g.moveGap(pos)
+	copy(g.buf[g.gapStart:], s)
+	g.gapStart += len(s)
+	return nil
+}
+
+// Delete removes n bytes starting at pos by widening the gap over them.
+func (g *GapBuffer) Delete(pos, n int) error {
+	if err := checkRange(pos, n, g.Len()); err != nil {
+		return err
+	}
+	g.moveGap(pos)
+	g.gapEnd += n
+	return nil
+}
+
+// Slice returns the n bytes starting at pos.
+func (g *GapBuffer) Slice(pos, n int) (string, error) {
+	if err := checkRange(pos, n, g.Len()); err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	b.Grow(n)
+	end := pos + n
+	if pos < g.gapStart {
+		b.Write(g.buf[pos:min(end, g.gapStart)])
+	}
+	if end > g.gapStart {
+		gap := g.gapEnd - g.gapStart
+		b.Write(g.buf[max(pos, g.gapStart)+gap : end+gap])
+	}
+	return b.String(), nil
+}
+
+// String returns the whole text.
+func (g *GapBuffer) String() string {
+	return string(g.buf[:g.gapStart]) + string(g.buf[g.gapEnd:])
+}
@@ -0,0 +1,56 @@
+//go:build go1.23
+
+package text
+
+import "iter"
+
+// Chunks returns an iterator over the text in order as the contiguous runs
+// the buffer stores: the text before the gap and the text after it. Joined,
+// they equal String. The buffer must not be edited during iteration.
+func (g *GapBuffer) Chunks() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for _, b := range [][]byte{g.buf[:g.gapStart], g.buf[g.gapEnd:]} {
+			if len(b) > 0 && !yield(string(b)) {
+				return
+			}
+		}
+	}
+}
+
+// Chunks returns an iterator over the text in order, one piece at a time.
+// Joined, the pieces equal String. Pieces of the original text are yielded
+// without copying. The table must not be edited during iteration.
+func (t *PieceTable) Chunks() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for _, p := range t.pieces {
+			var s string
+			if p.add {
+				s = string(t.add[p.start : p.start+p.length])
+			} else {
+				s = t.original[p.start : p.start+p.length]
+			}
+			if !yield(s) {
+				return
+			}
+		}
+	}
+}
+
+// Chunks returns an iterator over the text in order, one leaf at a time.
+// Joined, the leaves equal String, and none is copied. Since the rope's
+// nodes are immutable, edits during iteration do not affect it.
+func (r *Rope) Chunks() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		var walk func(n *ropeNode) bool
+		walk = func(n *ropeNode) bool {
+			switch {
+			case n == nil:
+				return true
+			case n.left == nil:
+				return n.length == 0 || yield(n.leaf)
+			}
+			return walk(n.left) && walk(n.right)
+		}
+		walk(r.root)
+	}
+}
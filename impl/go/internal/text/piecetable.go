@@ -0,0 +1,148 @@
+package text
+
+import (
+	"slices"
+	"strings"
+)
+
+// piece is a span of either the original text or the add buffer.
+type piece struct {
+	add    bool
+	start  int
+	length int
+}
+
+// PieceTable represents the text as a sequence of pieces, each a span of one
+// of two buffers: the original text, which is never modified, and an
+// append-only add buffer that receives every inserted byte. Editing only
+// splits and splices the piece list, so no text is ever moved; the cost is
+// that locating a position walks the list, O(p) for p pieces. Consecutive
+// typing extends the last piece instead of creating a new one.
+type PieceTable struct {
+	original string
+	add      []byte
+	pieces   []piece
+	length   int
+}
+
+// NewPieceTable creates a piece table whose original buffer is s.
+func NewPieceTable(s string) *PieceTable {
+	t := &PieceTable{original: s, length: len(s)}
+	if len(s) > 0 {
+		t.pieces = []piece{{start: 0, length: len(s)}}
+	}
+	return t
+}
+
+// Len returns the length of the text in bytes.
+func (t *PieceTable) Len() int {
+	return t.length
+}
+
+// Pieces returns the number of pieces describing the text.
+func (t *PieceTable) Pieces() int {
+	return len(t.pieces)
+}
+
+// write appends bytes [from, to) of piece p to b.
+func (t *PieceTable) write(b *strings.Builder, p piece, from, to int) {
+	if p.add {
+		b.Write(t.add[p.start+from : p.start+to])
+	} else {
+		b.WriteString(t.original[p.start+from : p.start+to])
+	}
+}
+
+// find returns the index of the piece holding pos and the offset of pos
+// within it. For pos == Len() it returns len(pieces) and 0.
+func (t *PieceTable) find(pos int) (int, int) {
+	for i, p := range t.pieces {
+		if pos < p.length {
+			return i, pos
+		}
+		pos -= p.length
+	}
+	return len(t.pieces), 0
+}
+
+// splitAt ensures a piece boundary at pos and returns the index of the piece
+// starting there.
+func (t *PieceTable) splitAt(pos int) int {
+	i, off := t.find(pos)
+	if off == 0 {
+		return i
+	}
+	p := t.pieces[i]
+	left := piece{add: p.add, start: p.start, length: off}
+	right := piece{add: p.add, start: p.start + off, length: p.length - off}
+	t.pieces = slices.Replace(t.pieces, i, i+1, left, right)
+	return i + 1
+}
+
+// Insert inserts s before the byte at pos.
+func (t *PieceTable) Insert(pos int, s string) error {
+	if err := checkRange(pos, 0, t.length); err != nil {
+		return err
+	}
+	if s == "" {
+		return nil
+	}
+	i, off := t.find(pos)
+	start := len(t.add)
+	t.add = append(t.add, s...)
+	t.length += len(s)
+
+	// Typing straight after the previous insert just lengthens its piece.
+	if off == 0 && i > 0 {
+		if prev := &t.pieces[i-1]; prev.add && prev.start+prev.length == start {
+			prev.length += len(s)
+			return nil
+		}
+	}
+	i = t.splitAt(pos)
+	t.pieces = slices.Insert(t.pieces, i, piece{add: true, start: start, length: len(s)})
+	return nil
+}
+
+// Delete removes n bytes starting at pos.
+func (t *PieceTable) Delete(pos, n int) error {
+	if err := checkRange(pos, n, t.length); err != nil {
+		return err
+	}
+	if n == 0 {
+		return nil
+	}
+	from := t.splitAt(pos)
+	to := t.splitAt(pos + n)
+	t.pieces = slices.Delete(t.pieces, from, to)
+	t.length -= n
+	return nil
+}
+
+// Slice returns the n bytes starting at pos.
+func (t *PieceTable) Slice(pos, n int) (string, error) {
+	if err := checkRange(pos, n, t.length); err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	b.Grow(n)
+	i, off := t.find(pos)
+	for ; n > 0; i++ {
+		p := t.pieces[i]
+		end := min(p.length, off+n)
+		t.write(&b, p, off, end)
+		n -= end - off
+		off = 0
+	}
+	return b.String(), nil
+}
+
+// String returns the whole text.
+func (t *PieceTable) String() string {
+	var b strings.Builder
+	b.Grow(t.length)
+	for _, p := range t.pieces {
+		t.write(&b, p, 0, p.length)
+	}
+	return b.String()
+}
@@ -0,0 +1,189 @@
+package text
+
+import "strings"
+
+const (
+	// maxLeaf is the longest string stored in a single leaf.
+	maxLeaf = 512
+	// maxRopeDepth triggers a rebuild once edits have skewed the tree.
+	maxRopeDepth = 48
+)
+
+// ropeNode is immutable: edits build new nodes along the affected path and
+// share everything else.
+type ropeNode struct {
+	left, right *ropeNode
+	leaf        string
+	length      int
+	depth       int
+}
+
+func newLeaf(s string) *ropeNode {
+	return &ropeNode{leaf: s, length: len(s)}
+}
+
+func concat(a, b *ropeNode) *ropeNode {
+	switch {
+	case a == nil || a.length == 0:
+		return b
+	case b == nil || b.length == 0:
+		return a
+	case a.left == nil && b.left == nil && a.length+b.length <= maxLeaf:
+		return newLeaf(a.leaf + b.leaf)
+	case b.left == nil && a.left != nil && a.right.left == nil && a.right.length+b.length <= maxLeaf:
+		// Absorb a short leaf into the neighbouring leaf of a subtree, so
+		// typing does not fragment the rope into one-byte leaves.
+		return concat(a.left, newLeaf(a.right.leaf+b.leaf))
+	case a.left == nil && b.left != nil && b.left.left == nil && a.length+b.left.length <= maxLeaf:
+		return concat(newLeaf(a.leaf+b.left.leaf), b.right)
+	}
+	return &ropeNode{left: a, right: b, length: a.length + b.length, depth: 1 + max(a.depth, b.depth)}
+}
+
+// split returns the first pos bytes of n and the rest.
+func split(n *ropeNode, pos int) (*ropeNode, *ropeNode) {
+	switch {
+	case n == nil:
+		return nil, nil
+	case pos <= 0:
+		return nil, n
+	case pos >= n.length:
+		return n, nil
+	case n.left == nil:
+		return newLeaf(n.leaf[:pos]), newLeaf(n.leaf[pos:])
+	case pos < n.left.length:
+		l, r := split(n.left, pos)
+		return l, concat(r, n.right)
+	default:
+		l, r := split(n.right, pos-n.left.length)
+		return concat(n.left, l), r
+	}
+}
+
+// Rope stores text as a binary tree whose leaves hold short strings and whose
+// internal nodes record the length below them. Insert and Delete split the
+// tree at the edit positions and concatenate the parts, O(log n) when the
+// tree is balanced; the rope is rebuilt into a balanced tree whenever its
+// depth exceeds a fixed bound.
+type Rope struct {
+	root *ropeNode
+}
+
+// NewRope creates a rope holding s.
+func NewRope(s string) *Rope {
+	return &Rope{root: build(chunk(s))}
+}
+
+// chunk cuts s into leaves of at most maxLeaf bytes.
+func chunk(s string) []*ropeNode {
+	var leaves []*ropeNode
+	for len(s) > 0 {
+		n := min(len(s), maxLeaf)
+		leaves = append(leaves, newLeaf(s[:n]))
+		s = s[n:]
+	}
+	return leaves
+}
+
+// build assembles leaves into a balanced tree.
+func build(leaves []*ropeNode) *ropeNode {
+	switch len(leaves) {
+	case 0:
+		return nil
+	case 1:
+		return leaves[0]
+	}
+	mid := len(leaves) / 2
+	return concat(build(leaves[:mid]), build(leaves[mid:]))
+}
+
+// Len returns the length of the text in bytes.
+func (r *Rope) Len() int {
+	if r.root == nil {
+		return 0
+	}
+	return r.root.length
+}
+
+// Depth returns the height of the tree; a single leaf has depth 0.
+func (r *Rope) Depth() int {
+	if r.root == nil {
+		return 0
+	}
+	return r.root.depth
+}
+
+func (r *Rope) setRoot(n *ropeNode) {
+	if n != nil && n.depth > maxRopeDepth {
+		var leaves []*ropeNode
+		var collect func(n *ropeNode)
+		collect = func(n *ropeNode) {
+			if n.left == nil {
+				leaves = append(leaves, n)
+				return
+			}
+			collect(n.left)
+			collect(n.right)
+		}
+		collect(n)
+		n = build(leaves)
+	}
+	r.root = n
+}
+
+// Insert inserts s before the byte at pos.
+func (r *Rope) Insert(pos int, s string) error {
+	if err := checkRange(pos, 0, r.Len()); err != nil {
+		return err
+	}
+	l, rest := split(r.root, pos)
+	r.setRoot(concat(concat(l, build(chunk(s))), rest))
+	return nil
+}
+
+// Delete removes n bytes starting at pos.
+func (r *Rope) Delete(pos, n int) error {
+	if err := checkRange(pos, n, r.Len()); err != nil {
+		return err
+	}
+	l, rest := split(r.root, pos)
+	_, rest = split(rest, n)
+	r.setRoot(concat(l, rest))
+	return nil
+}
+
+// Slice returns the n bytes starting at pos.
+func (r *Rope) Slice(pos, n int) (string, error) {
+	if err := checkRange(pos, n, r.Len()); err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	b.Grow(n)
+	var walk func(node *ropeNode, pos, n int)
+	walk = func(node *ropeNode, pos, n int) {
+		if n <= 0 {
+			return
+		}
+		if node.left == nil {
+			b.WriteString(node.leaf[pos : pos+n])
+			return
+		}
+		if pos < node.left.length {
+			k := min(n, node.left.length-pos)
+			walk(node.left, pos, k)
+			walk(node.right, 0, n-k)
+		} else {
+			walk(node.right, pos-node.left.length, n)
+		}
+	}
+	if n > 0 {
+		walk(r.root, pos, n)
+	}
+	return b.String(), nil
+}
+
+// String returns the whole text.
+func (r *Rope) String() string {
+	s, _ := r.Slice(0, r.Len())
+	return s
+}
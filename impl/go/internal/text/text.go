@@ -0,0 +1,129 @@
+// Package text provides mutable text buffers for editor-style workloads:
+// many small inserts and deletes clustered around a moving cursor. Three
+// classic representations implement the same Text interface so they can be
+// compared on identical edit sessions:
+//
+//   - GapBuffer keeps the text in one array with a hole at the cursor.
+//   - PieceTable never moves text; it records spans of an original and an
+//     append-only buffer.
+//   - Rope is a balanced tree of short strings.
+//
+// Positions are byte offsets.
+package text
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+)
+
+// ErrRange is returned when a position or length falls outside the text.
+var ErrRange = errors.New("text: position out of range")
+
+// Text is the edit interface shared by every buffer.
+type Text interface {
+	// Len returns the length of the text in bytes.
+	Len() int
+	// Insert inserts s before the byte at pos; pos == Len() appends.
+	Insert(pos int, s string) error
+	// Delete removes n bytes starting at pos.
+	Delete(pos, n int) error
+	// Slice returns the n bytes starting at pos.
+	Slice(pos, n int) (string, error)
+	// String returns the whole text.
+	String() string
+}
+
+// Implementation pairs a stable name with a constructor for a Text holding
+// initial.
+type Implementation struct {
+	Name string
+	New  func(initial string) Text
+}
+
+// Implementations returns every registered Text implementation.
+func Implementations() []Implementation {
+	return []Implementation{
+		{Name: "gapbuffer", New: func(s string) Text { return NewGapBuffer(s) }},
+		{Name: "piecetable", New: func(s string) Text { return NewPieceTable(s) }},
+		{Name: "rope", New: func(s string) Text { return NewRope(s) }},
+	}
+}
+
+func checkRange(pos, n, length int) error {
+	if pos < 0 || n < 0 || pos+n > length {
+		return fmt.Errorf("%w: [%d, %d) in text of length %d", ErrRange, pos, pos+n, length)
+	}
+	return nil
+}
+
+// Edit is one step of an edit session: delete Delete bytes at Pos, then
+// insert Insert there.
+type Edit struct {
+	Pos    int
+	Delete int
+	Insert string
+}
+
+// Apply performs edits on t in order.
+func Apply(t Text, edits []Edit) error {
+	for i, e := range edits {
+		if e.Delete > 0 {
+			if err := t.Delete(e.Pos, e.Delete); err != nil {
+				return fmt.Errorf("edit %d: %w", i, err)
+			}
+		}
+		if e.Insert != "" {
+			if err := t.Insert(e.Pos, e.Insert); err != nil {
+				return fmt.Errorf("edit %d: %w", i, err)
+			}
+		}
+	}
+	return nil
+}
+
+// EditorSession generates n edits against a document of initial length,
+// imitating someone typing: mostly single characters at a cursor that moves
+// forward as they type, some backspacing, short cursor moves, occasional
+// jumps elsewhere and the odd paste or cut of a larger block.
+func EditorSession(rng *rand.Rand, initial, n int) []Edit {
+	const letters = "abcdefghijklmnopqrstuvwxyz     \n"
+	edits := make([]Edit, 0, n)
+	length := initial
+	cursor := rng.Intn(length + 1)
+	for len(edits) < n {
+		switch r := rng.Intn(100); {
+		case r < 70:
+			edits = append(edits, Edit{Pos: cursor, Insert: string(letters[rng.Intn(len(letters))])})
+			cursor++
+			length++
+		case r < 85:
+			if cursor == 0 {
+				continue
+			}
+			cursor--
+			length--
+			edits = append(edits, Edit{Pos: cursor, Delete: 1})
+		case r < 93:
+			cursor = min(max(cursor+rng.Intn(41)-20, 0), length)
+		case r < 97:
+			cursor = rng.Intn(length + 1)
+		case r < 99:
+			paste := make([]byte, 20+rng.Intn(200))
+			for i := range paste {
+				paste[i] = letters[rng.Intn(len(letters))]
+			}
+			edits = append(edits, Edit{Pos: cursor, Insert: string(paste)})
+			cursor += len(paste)
+			length += len(paste)
+		default:
+			cut := min(length-cursor, 20+rng.Intn(200))
+			if cut == 0 {
+				continue
+			}
+			edits = append(edits, Edit{Pos: cursor, Delete: cut})
+			length -= cut
+		}
+	}
+	return edits
+}
@@ -0,0 +1,116 @@
+package text
+
+import (
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// reference applies an edit to a plain string.
+func reference(s string, e Edit) string {
+	return s[:e.Pos] + e.Insert + s[e.Pos+e.Delete:]
+}
+
+func TestImplementationsAgainstString(t *testing.T) {
+	initial := strings.Repeat("the quick brown fox jumps over the lazy dog\n", 100)
+	edits := EditorSession(rand.New(rand.NewSource(1)), len(initial), 5000)
+
+	for _, impl := range Implementations() {
+		t.Run(impl.Name, func(t *testing.T) {
+			rng := rand.New(rand.NewSource(2))
+			txt := impl.New(initial)
+			want := initial
+			for i, e := range edits {
+				if err := Apply(txt, []Edit{e}); err != nil {
+					t.Fatalf("edit %d: %v", i, err)
+				}
+				want = reference(want, e)
+				if txt.Len() != len(want) {
+					t.Fatalf("edit %d: expected length %d, got %d", i, len(want), txt.Len())
+				}
+				// Spot-check a random slice after each edit and the whole
+				// text periodically.
+				pos := rng.Intn(len(want) + 1)
+				n := rng.Intn(len(want) - pos + 1)
+				if got, err := txt.Slice(pos, n); err != nil || got != want[pos:pos+n] {
+					t.Fatalf("edit %d: Slice(%d, %d) = %q, %v; expected %q", i, pos, n, got, err, want[pos:pos+n])
+				}
+				if i%500 == 0 && txt.String() != want {
+					t.Fatalf("edit %d: text diverged", i)
+				}
+			}
+			if txt.String() != want {
+				t.Fatalf("final text diverged")
+			}
+		})
+	}
+}
+
+func TestRandomEditsFromEmpty(t *testing.T) {
+	for _, impl := range Implementations() {
+		rng := rand.New(rand.NewSource(3))
+		txt := impl.New("")
+		want := ""
+		for i := 0; i < 3000; i++ {
+			pos := rng.Intn(len(want) + 1)
+			e := Edit{Pos: pos}
+			if rng.Intn(3) == 0 {
+				e.Delete = rng.Intn(len(want) - pos + 1)
+			} else {
+				e.Insert = strings.Repeat(string(rune('a'+rng.Intn(26))), 1+rng.Intn(700))
+			}
+			if err := Apply(txt, []Edit{e}); err != nil {
+				t.Fatalf("%s edit %d: %v", impl.Name, i, err)
+			}
+			want = reference(want, e)
+		}
+		if txt.String() != want {
+			t.Errorf("%s: text diverged after random edits", impl.Name)
+		}
+	}
+}
+
+func TestRangeErrors(t *testing.T) {
+	for _, impl := range Implementations() {
+		txt := impl.New("hello")
+		if err := txt.Insert(6, "x"); !errors.Is(err, ErrRange) {
+			t.Errorf("%s: Insert past end: expected ErrRange, got %v", impl.Name, err)
+		}
+		if err := txt.Delete(3, 3); !errors.Is(err, ErrRange) {
+			t.Errorf("%s: Delete past end: expected ErrRange, got %v", impl.Name, err)
+		}
+		if _, err := txt.Slice(-1, 2); !errors.Is(err, ErrRange) {
+			t.Errorf("%s: Slice before start: expected ErrRange, got %v", impl.Name, err)
+		}
+		if txt.String() != "hello" {
+			t.Errorf("%s: expected text unchanged after errors, got %q", impl.Name, txt.String())
+		}
+	}
+}
+
+func TestPieceTableTypingExtendsPiece(t *testing.T) {
+	pt := NewPieceTable("hello world")
+	for i, c := range "there " {
+		pt.Insert(6+i, string(c))
+	}
+	if pt.String() != "hello there world" {
+		t.Fatalf("expected %q, got %q", "hello there world", pt.String())
+	}
+	if pt.Pieces() != 3 {
+		t.Errorf("expected 3 pieces, got %d", pt.Pieces())
+	}
+}
+
+func TestRopeStaysShallow(t *testing.T) {
+	r := NewRope("")
+	for i := 0; i < 20000; i++ {
+		r.Insert(r.Len()/2, "x")
+	}
+	if r.Depth() > maxRopeDepth {
+		t.Errorf("expected depth at most %d, got %d", maxRopeDepth, r.Depth())
+	}
+	if r.Len() != 20000 {
+		t.Errorf("expected length 20000, got %d", r.Len())
+	}
+}
@@ -0,0 +1,275 @@
+// Package trace records Map operations together with their results and
+// replays them deterministically. A trace captured from a failing randomized
+// test can be minimized against a model map and checked in as a regression
+// test; replaying it reports the first operation whose result differs.
+//
+// Traces use a compact line format, one event per line:
+//
+//	# dsa-lab trace v1
+//	I "key" "value" "previous" 1
+//	G "key" "result" 0
+//	R "key" "removed" 1
+//
+// The trailing flag is the found/existed result.
+package trace
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/dsa-lab/go/internal/kv"
+	"github.com/dsa-lab/go/internal/proptest"
+)
+
+const header = "# dsa-lab trace v1"
+
+// Kind identifies a traced operation.
+type Kind byte
+
+const (
+	Insert Kind = 'I'
+	Get    Kind = 'G'
+	Remove Kind = 'R'
+)
+
+// Event is one operation and the result it produced.
+type Event struct {
+	Kind   Kind
+	Key    string
+	Value  string // insert only
+	Result string
+	Found  bool
+}
+
+func (e Event) String() string {
+	switch e.Kind {
+	case Insert:
+		return fmt.Sprintf("Insert(%q, %q) = (%q, %v)", e.Key, e.Value, e.Result, e.Found)
+	case Get:
+		return fmt.Sprintf("Get(%q) = (%q, %v)", e.Key, e.Result, e.Found)
+	default:
+		return fmt.Sprintf("Remove(%q) = (%q, %v)", e.Key, e.Result, e.Found)
+	}
+}
+
+// Tracer wraps a Map and records every Insert, Get and Remove with its result.
+type Tracer struct {
+	kv.Map
+	events []Event
+}
+
+// NewTracer creates a Tracer around m.
+func NewTracer(m kv.Map) *Tracer {
+	return &Tracer{Map: m}
+}
+
+// Insert forwards to the wrapped map and records the result.
+func (t *Tracer) Insert(key, value string) (string, bool) {
+	result, found := t.Map.Insert(key, value)
+	t.events = append(t.events, Event{Kind: Insert, Key: key, Value: value, Result: result, Found: found})
+	return result, found
+}
+
+// Get forwards to the wrapped map and records the result.
+func (t *Tracer) Get(key string) (string, bool) {
+	result, found := t.Map.Get(key)
+	t.events = append(t.events, Event{Kind: Get, Key: key, Result: result, Found: found})
+	return result, found
+}
+
+// Remove forwards to the wrapped map and records the result.
+func (t *Tracer) Remove(key string) (string, bool) {
+	result, found := t.Map.Remove(key)
+	t.events = append(t.events, Event{Kind: Remove, Key: key, Result: result, Found: found})
+	return result, found
+}
+
+// Events returns the events recorded so far.
+func (t *Tracer) Events() []Event {
+	return t.events
+}
+
+// Write encodes events in the trace line format.
+func Write(w io.Writer, events []Event) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, header)
+	for _, e := range events {
+		found := 0
+		if e.Found {
+			found = 1
+		}
+		if e.Kind == Insert {
+			fmt.Fprintf(bw, "%c %q %q %q %d\n", e.Kind, e.Key, e.Value, e.Result, found)
+		} else {
+			fmt.Fprintf(bw, "%c %q %q %d\n", e.Kind, e.Key, e.Result, found)
+		}
+	}
+	return bw.Flush()
+}
+
+// Read decodes a trace. Blank lines and lines starting with '#' are ignored.
+func Read(r io.Reader) ([]Event, error) {
+	var events []Event
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		e, err := parseEvent(text)
+		if err != nil {
+			return nil, fmt.Errorf("trace line %d: %w", line, err)
+		}
+		events = append(events, e)
+	}
+	return events, scanner.Err()
+}
+
+func parseEvent(text string) (Event, error) {
+	var e Event
+	if len(text) < 2 || text[1] != ' ' {
+		return e, fmt.Errorf("malformed event %q", text)
+	}
+	e.Kind = Kind(text[0])
+
+	want := 2
+	if e.Kind == Insert {
+		want = 3
+	} else if e.Kind != Get && e.Kind != Remove {
+		return e, fmt.Errorf("unknown operation %q", text[0])
+	}
+
+	rest := text[2:]
+	fields := make([]string, 0, want)
+	for i := 0; i < want; i++ {
+		quoted, err := strconv.QuotedPrefix(rest)
+		if err != nil {
+			return e, fmt.Errorf("field %d: %w", i+1, err)
+		}
+		field, _ := strconv.Unquote(quoted)
+		fields = append(fields, field)
+		rest = strings.TrimPrefix(rest[len(quoted):], " ")
+	}
+
+	switch rest {
+	case "0":
+	case "1":
+		e.Found = true
+	default:
+		return e, fmt.Errorf("invalid found flag %q", rest)
+	}
+
+	e.Key = fields[0]
+	if e.Kind == Insert {
+		e.Value, e.Result = fields[1], fields[2]
+	} else {
+		e.Result = fields[1]
+	}
+	return e, nil
+}
+
+// Divergence reports the first replayed event whose result differs from the
+// recorded one.
+type Divergence struct {
+	Index  int
+	Event  Event
+	Result string
+	Found  bool
+}
+
+func (d *Divergence) Error() string {
+	return fmt.Sprintf("event %d: recorded %s, replay returned (%q, %v)", d.Index, d.Event, d.Result, d.Found)
+}
+
+// Replay applies events to m in order and returns a *Divergence for the first
+// event whose result does not match the recording.
+func Replay(m kv.Map, events []Event) error {
+	for i, e := range events {
+		var result string
+		var found bool
+		switch e.Kind {
+		case Insert:
+			result, found = m.Insert(e.Key, e.Value)
+		case Get:
+			result, found = m.Get(e.Key)
+		case Remove:
+			result, found = m.Remove(e.Key)
+		default:
+			return fmt.Errorf("event %d: unknown operation %q", i, e.Kind)
+		}
+		if result != e.Result || found != e.Found {
+			return &Divergence{Index: i, Event: e, Result: result, Found: found}
+		}
+	}
+	return nil
+}
+
+// Expected returns a copy of events with results recomputed against Go's
+// builtin map, the reference semantics every implementation must match.
+func Expected(events []Event) []Event {
+	tracer := NewTracer(kv.NewBuiltin())
+	for _, e := range events {
+		switch e.Kind {
+		case Insert:
+			tracer.Insert(e.Key, e.Value)
+		case Get:
+			tracer.Get(e.Key)
+		case Remove:
+			tracer.Remove(e.Key)
+		}
+	}
+	return tracer.Events()
+}
+
+// Minimize shrinks a trace to a minimal sequence whose replay against maps
+// built by newMap still diverges from the reference semantics. The returned
+// events carry the expected results, so replaying them is a regression test
+// that fails until the bug is fixed. If the trace does not diverge, it is
+// returned as-is.
+func Minimize(events []Event, newMap func() kv.Map) []Event {
+	ops := toOps(events)
+	fails := func(candidate proptest.Ops) bool {
+		return Replay(newMap(), Expected(fromOps(candidate))) != nil
+	}
+	if !fails(ops) {
+		return events
+	}
+	return Expected(fromOps(proptest.Shrink(ops, fails)))
+}
+
+func toOps(events []Event) proptest.Ops {
+	ops := make(proptest.Ops, len(events))
+	for i, e := range events {
+		op := proptest.Op{Key: e.Key, Value: e.Value}
+		switch e.Kind {
+		case Insert:
+			op.Kind = proptest.Insert
+		case Get:
+			op.Kind = proptest.Get
+		case Remove:
+			op.Kind = proptest.Remove
+		}
+		ops[i] = op
+	}
+	return ops
+}
+
+func fromOps(ops proptest.Ops) []Event {
+	events := make([]Event, len(ops))
+	for i, op := range ops {
+		e := Event{Key: op.Key, Value: op.Value}
+		switch op.Kind {
+		case proptest.Insert:
+			e.Kind = Insert
+		case proptest.Get:
+			e.Kind = Get
+		case proptest.Remove:
+			e.Kind = Remove
+		}
+		events[i] = e
+	}
+	return events
+}
@@ -0,0 +1,98 @@
+package trace
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/kv"
+)
+
+// stickyMap never forgets the key "stuck", mimicking a tombstone bug.
+type stickyMap struct {
+	*kv.Builtin
+}
+
+func (m stickyMap) Remove(key string) (string, bool) {
+	if key == "stuck" {
+		value, found := m.Get(key)
+		return value, found
+	}
+	return m.Builtin.Remove(key)
+}
+
+func TestWriteReadRoundTrip(t *testing.T) {
+	tracer := NewTracer(kv.NewBuiltin())
+	tracer.Insert("a", "1")
+	tracer.Insert("with space", "quote\"d\n")
+	tracer.Get("a")
+	tracer.Remove("a")
+	tracer.Get("a")
+
+	var buf bytes.Buffer
+	if err := Write(&buf, tracer.Events()); err != nil {
+		t.Fatal(err)
+	}
+	events, err := Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 5 {
+		t.Fatalf("expected 5 events, got %d", len(events))
+	}
+	for i, e := range events {
+		if e != tracer.Events()[i] {
+			t.Errorf("event %d: got %v, want %v", i, e, tracer.Events()[i])
+		}
+	}
+
+	if _, err := Read(bytes.NewBufferString("X \"k\" \"\" 0\n")); err == nil {
+		t.Error("expected error for unknown operation")
+	}
+}
+
+func TestReplayReportsDivergence(t *testing.T) {
+	tracer := NewTracer(kv.NewBuiltin())
+	tracer.Insert("stuck", "v")
+	tracer.Remove("stuck")
+	tracer.Get("stuck")
+
+	if err := Replay(kv.NewBuiltin(), tracer.Events()); err != nil {
+		t.Fatalf("replay against a correct map failed: %v", err)
+	}
+
+	err := Replay(stickyMap{kv.NewBuiltin()}, tracer.Events())
+	var d *Divergence
+	if !errors.As(err, &d) {
+		t.Fatalf("expected divergence, got %v", err)
+	}
+	if d.Index != 2 || !d.Found {
+		t.Errorf("expected divergence at the final get, got %v", d)
+	}
+}
+
+func TestMinimize(t *testing.T) {
+	tracer := NewTracer(stickyMap{kv.NewBuiltin()})
+	for i := 0; i < 20; i++ {
+		tracer.Insert("other", "x")
+		tracer.Get("missing")
+	}
+	tracer.Insert("stuck", "v")
+	tracer.Remove("other")
+	tracer.Remove("stuck")
+	tracer.Get("stuck")
+
+	newMap := func() kv.Map { return stickyMap{kv.NewBuiltin()} }
+	minimal := Minimize(tracer.Events(), newMap)
+	if len(minimal) != 3 {
+		t.Fatalf("expected 3 events, got %d: %v", len(minimal), minimal)
+	}
+	// Minimized events carry reference results, so they fail on the buggy map
+	// and pass on a correct one.
+	if Replay(newMap(), minimal) == nil {
+		t.Error("minimized trace should reproduce the bug")
+	}
+	if err := Replay(kv.NewBuiltin(), minimal); err != nil {
+		t.Errorf("minimized trace should pass on a correct map: %v", err)
+	}
+}
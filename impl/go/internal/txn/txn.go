@@ -0,0 +1,124 @@
+// Package txn provides write batches over the lab's in-memory maps. A Txn
+// buffers inserts and removes, answers reads with its own writes layered over
+// the map, and then applies every buffered write with Commit or drops them
+// all with Rollback, so a caller never leaves the map half updated.
+package txn
+
+import (
+	"cmp"
+	"sort"
+
+	"github.com/dsa-lab/go/internal/order"
+)
+
+// Map is the set of operations a Txn reads through and writes to. The
+// hashmap and the skip list implement it, and both return a Txn from their
+// Txn method.
+type Map[K, V any] interface {
+	Insert(key K, value V) (V, bool)
+	Get(key K) (V, bool)
+	Remove(key K) (V, bool)
+}
+
+// write is the pending state of one key: its new value, or its removal.
+type write[K, V any] struct {
+	key     K
+	value   V
+	removed bool
+}
+
+// Txn is a batch of writes to a Map. It keeps one write per key, the latest,
+// sorted by key, so lookups in the batch take O(log n) and Commit applies the
+// writes in ascending key order. Reads of keys the batch has not written see
+// the map as it is at the time of the read: a Txn isolates nobody from other
+// writers, it only defers its own writes.
+//
+// Commit cannot fail part way, so the map goes from its state before Commit
+// to that state with every buffered write applied. Like any write, Commit
+// must not run concurrently with other use of the map. A Txn is empty again
+// after Commit or Rollback and can be reused for the next batch.
+type Txn[K, V any] struct {
+	m       Map[K, V]
+	compare order.Comparator[K]
+	writes  []write[K, V]
+}
+
+// New creates an empty Txn on m, matching keys by their natural ordering.
+func New[K cmp.Ordered, V any](m Map[K, V]) *Txn[K, V] {
+	return NewFunc(m, order.Natural[K]())
+}
+
+// NewFunc creates an empty Txn on m, treating two keys as the same key when
+// compare reports them equal. It must agree with the map's notion of key
+// equality.
+func NewFunc[K, V any](m Map[K, V], compare order.Comparator[K]) *Txn[K, V] {
+	return &Txn[K, V]{m: m, compare: compare}
+}
+
+// Len returns the number of keys the batch writes.
+func (t *Txn[K, V]) Len() int {
+	return len(t.writes)
+}
+
+// search returns the position of key's write, or where it belongs, and
+// whether the batch writes key.
+func (t *Txn[K, V]) search(key K) (int, bool) {
+	i := sort.Search(len(t.writes), func(i int) bool { return t.compare(t.writes[i].key, key) >= 0 })
+	return i, i < len(t.writes) && t.compare(t.writes[i].key, key) == 0
+}
+
+// Get returns the value of key with the batch applied: the batch's own write
+// of key if there is one, or else the map's value.
+func (t *Txn[K, V]) Get(key K) (V, bool) {
+	if i, found := t.search(key); found {
+		w := t.writes[i]
+		if w.removed {
+			var zero V
+			return zero, false
+		}
+		return w.value, true
+	}
+	return t.m.Get(key)
+}
+
+// Insert buffers setting key to value. It returns the value key had with the
+// batch applied so far and true, or the zero value and false if it had none.
+func (t *Txn[K, V]) Insert(key K, value V) (V, bool) {
+	return t.set(write[K, V]{key: key, value: value})
+}
+
+// Remove buffers removing key. It returns the value key had with the batch
+// applied so far and true, or the zero value and false if it had none.
+func (t *Txn[K, V]) Remove(key K) (V, bool) {
+	return t.set(write[K, V]{key: key, removed: true})
+}
+
+func (t *Txn[K, V]) set(w write[K, V]) (V, bool) {
+	old, found := t.Get(w.key)
+	if i, ok := t.search(w.key); ok {
+		t.writes[i] = w
+	} else {
+		t.writes = append(t.writes, write[K, V]{})
+		copy(t.writes[i+1:], t.writes[i:])
+		t.writes[i] = w
+	}
+	return old, found
+}
+
+// Commit applies every buffered write to the map and empties the batch.
+func (t *Txn[K, V]) Commit() {
+	for _, w := range t.writes {
+		if w.removed {
+			t.m.Remove(w.key)
+		} else {
+			t.m.Insert(w.key, w.value)
+		}
+	}
+	t.Rollback()
+}
+
+// Rollback discards every buffered write, leaving the map untouched.
+func (t *Txn[K, V]) Rollback() {
+	clear(t.writes)
+	t.writes = t.writes[:0]
+}
@@ -0,0 +1,105 @@
+package txn_test
+
+import (
+	"fmt"
+	"maps"
+	"math/rand"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/hashmap"
+	"github.com/dsa-lab/go/internal/skiplist"
+	"github.com/dsa-lab/go/internal/txn"
+)
+
+type stringMap interface {
+	txn.Map[string, string]
+	Range(f func(key, value string) bool)
+	Txn() *txn.Txn[string, string]
+}
+
+func contents(m stringMap) map[string]string {
+	got := make(map[string]string)
+	m.Range(func(key, value string) bool {
+		got[key] = value
+		return true
+	})
+	return got
+}
+
+// TestModel runs random batches against each map, committing or rolling
+// back each one, and compares reads in the batch and the map afterwards
+// with a builtin map.
+func TestModel(t *testing.T) {
+	for name, m := range map[string]stringMap{
+		"hashmap":  hashmap.MustNew(),
+		"skiplist": skiplist.MustNew[string, string](),
+	} {
+		t.Run(name, func(t *testing.T) {
+			rng := rand.New(rand.NewSource(1))
+			model := make(map[string]string)
+			for batch := 0; batch < 200; batch++ {
+				tx := m.Txn()
+				pending := maps.Clone(model)
+				for i := rng.Intn(30); i > 0; i-- {
+					key := fmt.Sprint(rng.Intn(40))
+					want, wantFound := pending[key]
+					var got string
+					var found bool
+					switch rng.Intn(3) {
+					case 0:
+						got, found = tx.Get(key)
+					case 1:
+						value := fmt.Sprint(batch, ".", i)
+						got, found = tx.Insert(key, value)
+						pending[key] = value
+					default:
+						got, found = tx.Remove(key)
+						delete(pending, key)
+					}
+					if got != want || found != wantFound {
+						t.Fatalf("batch %d, key %s: expected %q %v, got %q %v", batch, key, want, wantFound, got, found)
+					}
+				}
+				if !maps.Equal(contents(m), model) {
+					t.Fatalf("batch %d: expected the map untouched before Commit", batch)
+				}
+				if rng.Intn(4) == 0 {
+					tx.Rollback()
+				} else {
+					tx.Commit()
+					model = pending
+				}
+				if tx.Len() != 0 {
+					t.Fatalf("batch %d: expected an empty batch, got %d writes", batch, tx.Len())
+				}
+				if got := contents(m); !maps.Equal(got, model) {
+					t.Fatalf("batch %d: expected %d keys, got %d", batch, len(model), len(got))
+				}
+			}
+		})
+	}
+}
+
+func TestCommitOrder(t *testing.T) {
+	var applied []string
+	m := recorder{skiplist.MustNew[string, string](), &applied}
+	tx := txn.New[string, string](m)
+	for _, key := range []string{"c", "a", "b", "a"} {
+		tx.Insert(key, "x")
+	}
+	tx.Commit()
+	if fmt.Sprint(applied) != "[a b c]" {
+		t.Errorf("expected one write per key in key order, got %v", applied)
+	}
+}
+
+// recorder logs the keys written to a map.
+type recorder struct {
+	*skiplist.SkipList[string, string]
+	applied *[]string
+}
+
+func (r recorder) Insert(key, value string) (string, bool) {
+	*r.applied = append(*r.applied, key)
+	return r.SkipList.Insert(key, value)
+}
@@ -0,0 +1,17 @@
+//go:build go1.23
+
+package veb
+
+import "iter"
+
+// All returns an iterator over the members in ascending order, following
+// successors from the minimum.
+func (t *Tree) All() iter.Seq[uint64] {
+	return func(yield func(uint64) bool) {
+		for x, ok := t.Min(); ok; x, ok = t.Successor(x) {
+			if !yield(x) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,290 @@
+// Package veb provides a van Emde Boas tree: an ordered set of integers from
+// a fixed universe [0, 2^bits) with Insert, Delete, Successor and Predecessor
+// in O(log log U) time.
+//
+// Each node splits its keys into a high half, naming a cluster, and a low
+// half, stored recursively inside it; a summary node records which clusters
+// are non-empty. A node's minimum is kept only in the node itself, which is
+// what lets every operation recurse into a single child. Clusters are created
+// on demand, so memory grows with the keys stored rather than the universe,
+// and universes of at most 64 keys are plain bitmaps.
+package veb
+
+import (
+	"math/bits"
+
+	"github.com/dsa-lab/go/internal/errs"
+)
+
+// leafBits is the largest universe, as a power of two, stored as a bitmap.
+const leafBits = 6
+
+// ErrUniverse is returned for universe sizes outside 1 to 64 bits.
+var ErrUniverse = errs.New(errs.ErrInvalidOption, "veb: universe must be between 1 and 64 bits")
+
+type node struct {
+	bits  uint
+	empty bool
+
+	// Leaf nodes keep their keys in mask.
+	mask uint64
+
+	min, max uint64
+	summary  *node
+	clusters map[uint64]*node
+}
+
+func newNode(bits uint) *node {
+	n := &node{bits: bits, empty: true}
+	if bits > leafBits {
+		n.clusters = make(map[uint64]*node)
+	}
+	return n
+}
+
+func (n *node) lowBits() uint {
+	return n.bits / 2
+}
+
+func (n *node) split(x uint64) (high, low uint64) {
+	lb := n.lowBits()
+	return x >> lb, x & (1<<lb - 1)
+}
+
+func (n *node) join(high, low uint64) uint64 {
+	return high<<n.lowBits() | low
+}
+
+func (n *node) minimum() uint64 {
+	if n.bits <= leafBits {
+		return uint64(bits.TrailingZeros64(n.mask))
+	}
+	return n.min
+}
+
+func (n *node) maximum() uint64 {
+	if n.bits <= leafBits {
+		return uint64(63 - bits.LeadingZeros64(n.mask))
+	}
+	return n.max
+}
+
+func (n *node) contains(x uint64) bool {
+	if n.bits <= leafBits {
+		return n.mask&(1<<x) != 0
+	}
+	if n.empty {
+		return false
+	}
+	if x == n.min || x == n.max {
+		return true
+	}
+	high, low := n.split(x)
+	c := n.clusters[high]
+	return c != nil && c.contains(low)
+}
+
+// insert adds x, which must not be present.
+func (n *node) insert(x uint64) {
+	if n.bits <= leafBits {
+		n.mask |= 1 << x
+		n.empty = false
+		return
+	}
+	if n.empty {
+		n.min, n.max, n.empty = x, x, false
+		return
+	}
+	if x < n.min {
+		x, n.min = n.min, x
+	}
+	if x > n.max {
+		n.max = x
+	}
+
+	high, low := n.split(x)
+	c := n.clusters[high]
+	if c == nil {
+		c = newNode(n.lowBits())
+		n.clusters[high] = c
+		if n.summary == nil {
+			n.summary = newNode(n.bits - n.lowBits())
+		}
+		n.summary.insert(high)
+	}
+	c.insert(low)
+}
+
+// remove deletes x, which must be present.
+func (n *node) remove(x uint64) {
+	if n.bits <= leafBits {
+		n.mask &^= 1 << x
+		n.empty = n.mask == 0
+		return
+	}
+	if n.min == n.max {
+		n.empty = true
+		return
+	}
+	if x == n.min {
+		// Promote the smallest clustered key to be the new minimum.
+		first := n.summary.minimum()
+		x = n.join(first, n.clusters[first].minimum())
+		n.min = x
+	}
+
+	high, low := n.split(x)
+	c := n.clusters[high]
+	c.remove(low)
+	if c.empty {
+		delete(n.clusters, high)
+		n.summary.remove(high)
+	}
+	if x == n.max {
+		if n.summary.empty {
+			n.max = n.min
+		} else {
+			last := n.summary.maximum()
+			n.max = n.join(last, n.clusters[last].maximum())
+		}
+	}
+}
+
+// successor returns the smallest key greater than x.
+func (n *node) successor(x uint64) (uint64, bool) {
+	if n.bits <= leafBits {
+		if x >= 63 {
+			return 0, false
+		}
+		rest := n.mask >> (x + 1)
+		if rest == 0 {
+			return 0, false
+		}
+		return x + 1 + uint64(bits.TrailingZeros64(rest)), true
+	}
+	if n.empty || x >= n.max {
+		return 0, false
+	}
+	if x < n.min {
+		return n.min, true
+	}
+
+	high, low := n.split(x)
+	if c := n.clusters[high]; c != nil && low < c.maximum() {
+		next, _ := c.successor(low)
+		return n.join(high, next), true
+	}
+	nextHigh, _ := n.summary.successor(high)
+	return n.join(nextHigh, n.clusters[nextHigh].minimum()), true
+}
+
+// predecessor returns the largest key less than x.
+func (n *node) predecessor(x uint64) (uint64, bool) {
+	if n.bits <= leafBits {
+		rest := n.mask & (1<<x - 1)
+		if rest == 0 {
+			return 0, false
+		}
+		return uint64(63 - bits.LeadingZeros64(rest)), true
+	}
+	if n.empty || x <= n.min {
+		return 0, false
+	}
+	if x > n.max {
+		return n.max, true
+	}
+
+	high, low := n.split(x)
+	if c := n.clusters[high]; c != nil && low > c.minimum() {
+		prev, _ := c.predecessor(low)
+		return n.join(high, prev), true
+	}
+	if n.summary != nil {
+		if prevHigh, ok := n.summary.predecessor(high); ok {
+			return n.join(prevHigh, n.clusters[prevHigh].maximum()), true
+		}
+	}
+	return n.min, true
+}
+
+// Tree is a van Emde Boas tree over the universe [0, 2^bits).
+type Tree struct {
+	root     *node
+	universe uint64
+	size     int
+}
+
+// New creates an empty tree over keys in [0, 2^bits).
+func New(bits int) (*Tree, error) {
+	if bits < 1 || bits > 64 {
+		return nil, ErrUniverse
+	}
+	t := &Tree{root: newNode(uint(bits)), universe: ^uint64(0)}
+	if bits < 64 {
+		t.universe = 1<<bits - 1
+	}
+	return t, nil
+}
+
+// Len returns the number of keys.
+func (t *Tree) Len() int {
+	return t.size
+}
+
+// Contains reports whether x is in the tree.
+func (t *Tree) Contains(x uint64) bool {
+	return x <= t.universe && t.root.contains(x)
+}
+
+// Insert adds x and returns true if it was not already present.
+// It panics if x lies outside the universe.
+func (t *Tree) Insert(x uint64) bool {
+	if x > t.universe {
+		panic("veb: key outside the universe")
+	}
+	if t.root.contains(x) {
+		return false
+	}
+	t.root.insert(x)
+	t.size++
+	return true
+}
+
+// Delete removes x and returns true if it was present.
+func (t *Tree) Delete(x uint64) bool {
+	if !t.Contains(x) {
+		return false
+	}
+	t.root.remove(x)
+	t.size--
+	return true
+}
+
+// Min returns the smallest key, and false if the tree is empty.
+func (t *Tree) Min() (uint64, bool) {
+	if t.root.empty {
+		return 0, false
+	}
+	return t.root.minimum(), true
+}
+
+// Max returns the largest key, and false if the tree is empty.
+func (t *Tree) Max() (uint64, bool) {
+	if t.root.empty {
+		return 0, false
+	}
+	return t.root.maximum(), true
+}
+
+// Successor returns the smallest key strictly greater than x.
+func (t *Tree) Successor(x uint64) (uint64, bool) {
+	return t.root.successor(x)
+}
+
+// Predecessor returns the largest key strictly less than x.
+func (t *Tree) Predecessor(x uint64) (uint64, bool) {
+	if x > t.universe {
+		return t.Max()
+	}
+	return t.root.predecessor(x)
+}
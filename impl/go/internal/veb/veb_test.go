@@ -0,0 +1,114 @@
+package veb
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestNewRejectsBadUniverse(t *testing.T) {
+	for _, bits := range []int{0, -1, 65} {
+		if _, err := New(bits); err != ErrUniverse {
+			t.Errorf("New(%d): expected ErrUniverse, got %v", bits, err)
+		}
+	}
+}
+
+// TestOracle runs random operations against a sorted slice for universes
+// that exercise the bitmap leaves, odd splits and the full 64-bit range.
+func TestOracle(t *testing.T) {
+	for _, bits := range []int{1, 3, 6, 7, 12, 20, 64} {
+		tree, err := New(bits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rng := rand.New(rand.NewSource(int64(bits)))
+		draw := func() uint64 {
+			if bits == 64 {
+				// Mix in near neighbours of a few hot values so the
+				// dense paths are hit in a sparse universe.
+				if rng.Intn(2) == 0 {
+					return uint64(1)<<63 + uint64(rng.Intn(300))
+				}
+				return rng.Uint64()
+			}
+			return uint64(rng.Int63n(1 << bits))
+		}
+		var ref []uint64
+
+		for i := 0; i < 5000; i++ {
+			x := draw()
+			pos, present := slices.BinarySearch(ref, x)
+			switch rng.Intn(4) {
+			case 0, 1:
+				if tree.Insert(x) == present {
+					t.Fatalf("bits=%d: Insert(%d) disagreed with presence %v", bits, x, present)
+				}
+				if !present {
+					ref = slices.Insert(ref, pos, x)
+				}
+			case 2:
+				if tree.Delete(x) != present {
+					t.Fatalf("bits=%d: Delete(%d) disagreed with presence %v", bits, x, present)
+				}
+				if present {
+					ref = slices.Delete(ref, pos, pos+1)
+				}
+			case 3:
+				if tree.Contains(x) != present {
+					t.Fatalf("bits=%d: Contains(%d) expected %v", bits, x, present)
+				}
+			}
+
+			q := draw()
+			i := lowerBound(ref, q)
+			next, ok := tree.Successor(q)
+			j := i
+			if j < len(ref) && ref[j] == q {
+				j++
+			}
+			if ok != (j < len(ref)) || (ok && next != ref[j]) {
+				t.Fatalf("bits=%d: Successor(%d) = %d, %v", bits, q, next, ok)
+			}
+			prev, ok := tree.Predecessor(q)
+			if ok != (i > 0) || (ok && prev != ref[i-1]) {
+				t.Fatalf("bits=%d: Predecessor(%d) = %d, %v", bits, q, prev, ok)
+			}
+			if tree.Len() != len(ref) {
+				t.Fatalf("bits=%d: expected len %d, got %d", bits, len(ref), tree.Len())
+			}
+		}
+
+		if len(ref) > 0 {
+			lo, _ := tree.Min()
+			hi, _ := tree.Max()
+			if lo != ref[0] || hi != ref[len(ref)-1] {
+				t.Errorf("bits=%d: expected min/max %d/%d, got %d/%d", bits, ref[0], ref[len(ref)-1], lo, hi)
+			}
+		}
+	}
+}
+
+// lowerBound returns the index of the first element of ref not less than q.
+func lowerBound(ref []uint64, q uint64) int {
+	i, _ := slices.BinarySearch(ref, q)
+	return i
+}
+
+func TestDrain(t *testing.T) {
+	tree, _ := New(16)
+	for x := uint64(0); x < 1<<16; x += 7 {
+		tree.Insert(x)
+	}
+	for x, ok := tree.Min(); ok; x, ok = tree.Min() {
+		if !tree.Delete(x) {
+			t.Fatalf("Delete(%d) failed", x)
+		}
+	}
+	if tree.Len() != 0 {
+		t.Errorf("expected empty tree, got len %d", tree.Len())
+	}
+	if _, ok := tree.Successor(0); ok {
+		t.Error("expected no successor in an empty tree")
+	}
+}
@@ -0,0 +1,572 @@
+// Package faultfs is an in-memory vfs.FS that simulates power loss, for
+// testing that the persistent structures recover from a crash at any point.
+//
+// The file system keeps two views of the disk. Reads see every operation so
+// far. The durable view holds what would survive a power cut: a file's
+// contents as of its last Sync, and a directory's entries as of its last
+// SyncDir. Directories themselves are durable as soon as MkdirAll creates
+// them, a simplification the structures do not rely on being false.
+//
+// Crash cuts the power and returns the disk as it comes back: names the
+// directories never synced are gone or restored, and every file whose
+// contents changed since its last Sync is torn. Each 512-byte sector of an
+// overwritten region independently keeps its old or its new contents. Data
+// appended past the synced length reaches the disk in order, so a prefix of
+// it survives, and the file's length lands anywhere between the old and the
+// new one, zero-filled past what survived. That covers lost writes, torn
+// writes, overwrites persisted out of order and the zeros a file system can
+// expose after extending a file; it does not model appends persisted out of
+// order, which would make a torn log tail indistinguishable from corruption.
+//
+// Faults are injected at operation boundaries. Every call that changes the
+// file system counts as one operation. CrashAfter arms a power cut after a
+// number of further operations, from which point every call fails with
+// ErrCrashed, as if the process had died; ShortWriteAt makes one write store
+// only part of its data and fail with io.ErrShortWrite.
+package faultfs
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dsa-lab/go/internal/vfs"
+)
+
+// sectorSize is the unit in which a crash tears unsynced data.
+const sectorSize = 512
+
+// ErrCrashed is returned by every operation after the armed power cut.
+var ErrCrashed = errors.New("faultfs: simulated power cut")
+
+// inode is a file's contents, shared by every name and handle for it.
+type inode struct {
+	data   []byte // what reads see
+	synced []byte // what survives a crash, barring tearing
+}
+
+// FS is an in-memory file system with simulated power loss. It is safe for
+// concurrent use.
+type FS struct {
+	mu  sync.Mutex
+	rng *rand.Rand
+
+	dirs    map[string]bool
+	names   map[string]*inode
+	durable map[string]*inode
+
+	ops        int
+	crashAt    int // power is cut once ops reaches it; 0 means never
+	shortWrite int // the write at this operation is cut short; 0 means never
+	crashed    bool
+}
+
+// New creates an empty file system holding only the root directory and the
+// current directory. seed drives the choice of torn sectors and short write
+// lengths, so a failing run can be replayed.
+func New(seed int64) *FS {
+	m := &FS{
+		rng:     rand.New(rand.NewSource(seed)),
+		dirs:    map[string]bool{"/": true, ".": true},
+		names:   make(map[string]*inode),
+		durable: make(map[string]*inode),
+	}
+	return m
+}
+
+var _ vfs.FS = (*FS)(nil)
+
+// Ops returns the number of operations performed so far. A dry run of a
+// workload tells a test how many crash points it has.
+func (m *FS) Ops() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ops
+}
+
+// CrashAfter arms a power cut after n more operations: the n+1st and every
+// later call fail with ErrCrashed. n must be at least 0.
+func (m *FS) CrashAfter(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.crashAt = m.ops + n + 1
+}
+
+// ShortWriteAt makes the write that is the n+1st operation from now persist a
+// strict prefix of its data and fail with io.ErrShortWrite. If that operation
+// is not a write, no write is shortened.
+func (m *FS) ShortWriteAt(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.shortWrite = m.ops + n + 1
+}
+
+// Crashed reports whether the armed power cut has happened.
+func (m *FS) Crashed() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.crashed
+}
+
+// Crash cuts the power, if CrashAfter has not already, and returns a new
+// file system holding what survived. m fails every later call.
+func (m *FS) Crash() *FS {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.crashed = true
+
+	after := New(m.rng.Int63())
+	for dir := range m.dirs {
+		after.dirs[dir] = true
+	}
+	torn := make(map[*inode]*inode)
+	for name, ino := range m.durable {
+		t, ok := torn[ino]
+		if !ok {
+			b := m.tear(ino)
+			t = &inode{data: b, synced: b}
+			torn[ino] = t
+		}
+		after.names[name] = t
+		after.durable[name] = t
+	}
+	return after
+}
+
+// tear returns the contents of ino after a crash.
+func (m *FS) tear(ino *inode) []byte {
+	old, cur := ino.synced, ino.data
+	lo, hi := min(len(old), len(cur)), max(len(old), len(cur))
+	n := lo + m.rng.Intn(hi-lo+1)
+	b := make([]byte, n)
+	for off := 0; off < lo; off += sectorSize {
+		end := min(off+sectorSize, lo)
+		src := old
+		if m.rng.Intn(2) == 0 {
+			src = cur
+		}
+		copy(b[off:end], src[off:end])
+	}
+	if len(old) > len(cur) {
+		// A truncation that never reached the disk.
+		copy(b[lo:], old[lo:n])
+	} else {
+		kept := lo + m.rng.Intn(n-lo+1)
+		copy(b[lo:kept], cur[lo:kept])
+	}
+	return b
+}
+
+// step counts one operation, failing if the power is or has just been cut.
+// It must be called with m.mu held.
+func (m *FS) step() error {
+	if m.crashed {
+		return ErrCrashed
+	}
+	if m.crashAt != 0 && m.ops+1 >= m.crashAt {
+		m.crashed = true
+		return ErrCrashed
+	}
+	m.ops++
+	return nil
+}
+
+// alive fails if the power has been cut. Reads use it: they do not count as
+// operations but a dead process cannot perform them either.
+func (m *FS) alive() error {
+	if m.crashed {
+		return ErrCrashed
+	}
+	return nil
+}
+
+func clean(name string) string {
+	return filepath.Clean(name)
+}
+
+func pathErr(op, name string, err error) error {
+	return &fs.PathError{Op: op, Path: name, Err: err}
+}
+
+// parentExists reports whether the directory holding name exists. It must be
+// called with m.mu held.
+func (m *FS) parentExists(name string) bool {
+	return m.dirs[filepath.Dir(name)]
+}
+
+// OpenFile opens name. Opening counts as an operation only when it creates or
+// truncates the file.
+func (m *FS) OpenFile(name string, flag int, perm fs.FileMode) (vfs.File, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	name = clean(name)
+	if err := m.alive(); err != nil {
+		return nil, pathErr("open", name, err)
+	}
+	if m.dirs[name] {
+		return nil, pathErr("open", name, errors.New("is a directory"))
+	}
+	ino, exists := m.names[name]
+	switch {
+	case exists && flag&os.O_CREATE != 0 && flag&os.O_EXCL != 0:
+		return nil, pathErr("open", name, fs.ErrExist)
+	case !exists && flag&os.O_CREATE == 0:
+		return nil, pathErr("open", name, fs.ErrNotExist)
+	case !exists && !m.parentExists(name):
+		return nil, pathErr("open", name, fs.ErrNotExist)
+	}
+	if !exists || flag&os.O_TRUNC != 0 {
+		if err := m.step(); err != nil {
+			return nil, pathErr("open", name, err)
+		}
+		if !exists {
+			ino = &inode{}
+			m.names[name] = ino
+		}
+		if flag&os.O_TRUNC != 0 {
+			ino.data = nil
+		}
+	}
+	writable := flag&(os.O_WRONLY|os.O_RDWR) != 0
+	return &file{fs: m, ino: ino, name: name, readable: flag&os.O_WRONLY == 0, writable: writable}, nil
+}
+
+// Remove deletes the file name. Removing directories is not supported.
+func (m *FS) Remove(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	name = clean(name)
+	if err := m.alive(); err != nil {
+		return pathErr("remove", name, err)
+	}
+	if _, ok := m.names[name]; !ok {
+		return pathErr("remove", name, fs.ErrNotExist)
+	}
+	if err := m.step(); err != nil {
+		return pathErr("remove", name, err)
+	}
+	delete(m.names, name)
+	return nil
+}
+
+// Rename moves the file oldpath to newpath, replacing any file there.
+func (m *FS) Rename(oldpath, newpath string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	oldpath, newpath = clean(oldpath), clean(newpath)
+	if err := m.alive(); err != nil {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: err}
+	}
+	ino, ok := m.names[oldpath]
+	if !ok || !m.parentExists(newpath) {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: fs.ErrNotExist}
+	}
+	if err := m.step(); err != nil {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: err}
+	}
+	delete(m.names, oldpath)
+	m.names[newpath] = ino
+	return nil
+}
+
+// MkdirAll creates path and any missing parents. New directories are durable
+// at once.
+func (m *FS) MkdirAll(path string, perm fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	path = clean(path)
+	if err := m.alive(); err != nil {
+		return pathErr("mkdir", path, err)
+	}
+	if m.dirs[path] {
+		return nil
+	}
+	if _, ok := m.names[path]; ok {
+		return pathErr("mkdir", path, fs.ErrExist)
+	}
+	if err := m.step(); err != nil {
+		return pathErr("mkdir", path, err)
+	}
+	for p := path; !m.dirs[p]; p = filepath.Dir(p) {
+		m.dirs[p] = true
+	}
+	return nil
+}
+
+// Stat describes the file or directory name.
+func (m *FS) Stat(name string) (fs.FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	name = clean(name)
+	if err := m.alive(); err != nil {
+		return nil, pathErr("stat", name, err)
+	}
+	if m.dirs[name] {
+		return fileInfo{name: filepath.Base(name), dir: true}, nil
+	}
+	ino, ok := m.names[name]
+	if !ok {
+		return nil, pathErr("stat", name, fs.ErrNotExist)
+	}
+	return fileInfo{name: filepath.Base(name), size: int64(len(ino.data))}, nil
+}
+
+// ReadDir returns the sorted names of the files and directories in dir.
+func (m *FS) ReadDir(dir string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	dir = clean(dir)
+	if err := m.alive(); err != nil {
+		return nil, pathErr("readdir", dir, err)
+	}
+	if !m.dirs[dir] {
+		return nil, pathErr("readdir", dir, fs.ErrNotExist)
+	}
+	var names []string
+	for name := range m.names {
+		if filepath.Dir(name) == dir {
+			names = append(names, filepath.Base(name))
+		}
+	}
+	for d := range m.dirs {
+		if d != dir && filepath.Dir(d) == dir {
+			names = append(names, filepath.Base(d))
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// SyncDir makes the current entries of dir durable.
+func (m *FS) SyncDir(dir string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	dir = clean(dir)
+	if err := m.alive(); err != nil {
+		return pathErr("sync", dir, err)
+	}
+	if !m.dirs[dir] {
+		return pathErr("sync", dir, fs.ErrNotExist)
+	}
+	if err := m.step(); err != nil {
+		return pathErr("sync", dir, err)
+	}
+	for name := range m.durable {
+		if filepath.Dir(name) == dir {
+			delete(m.durable, name)
+		}
+	}
+	for name, ino := range m.names {
+		if filepath.Dir(name) == dir {
+			m.durable[name] = ino
+		}
+	}
+	return nil
+}
+
+// String lists the files and their sizes, for test failure messages.
+func (m *FS) String() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	names := make([]string, 0, len(m.names))
+	for name := range m.names {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	for _, name := range names {
+		ino := m.names[name]
+		_, durable := m.durable[name]
+		b.WriteString(name)
+		b.WriteString(" ")
+		b.WriteString(strings.Repeat("#", min(len(ino.data)/sectorSize, 40)))
+		if !durable {
+			b.WriteString(" (name not synced)")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// file is an open handle on an inode.
+type file struct {
+	fs       *FS
+	ino      *inode
+	name     string
+	off      int64
+	readable bool
+	writable bool
+	closed   bool
+}
+
+func (f *file) Name() string { return f.name }
+
+// check fails if the handle is closed or the power has been cut, or if the
+// handle lacks the access the call needs. It must be called with f.fs.mu held.
+func (f *file) check(op string, write bool) error {
+	if err := f.fs.alive(); err != nil {
+		return pathErr(op, f.name, err)
+	}
+	if f.closed {
+		return pathErr(op, f.name, fs.ErrClosed)
+	}
+	if write && !f.writable || !write && !f.readable {
+		return pathErr(op, f.name, fs.ErrPermission)
+	}
+	return nil
+}
+
+func (f *file) ReadAt(p []byte, off int64) (int, error) {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+	if err := f.check("read", false); err != nil {
+		return 0, err
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if off >= int64(len(f.ino.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, f.ino.data[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (f *file) Read(p []byte) (int, error) {
+	n, err := f.ReadAt(p, f.off)
+	f.off += int64(n)
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+func (f *file) WriteAt(p []byte, off int64) (int, error) {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+	if err := f.check("write", true); err != nil {
+		return 0, err
+	}
+	if err := f.fs.step(); err != nil {
+		return 0, pathErr("write", f.name, err)
+	}
+	var err error
+	if f.fs.ops == f.fs.shortWrite && len(p) > 0 {
+		p = p[:f.fs.rng.Intn(len(p))]
+		err = io.ErrShortWrite
+	}
+	if end := off + int64(len(p)); end > int64(len(f.ino.data)) {
+		f.ino.data = append(f.ino.data, make([]byte, end-int64(len(f.ino.data)))...)
+	}
+	copy(f.ino.data[off:], p)
+	return len(p), err
+}
+
+func (f *file) Write(p []byte) (int, error) {
+	n, err := f.WriteAt(p, f.off)
+	f.off += int64(n)
+	return n, err
+}
+
+func (f *file) Seek(offset int64, whence int) (int64, error) {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+	if err := f.check("seek", false); err != nil && !errors.Is(err, fs.ErrPermission) {
+		return 0, err
+	}
+	switch whence {
+	case io.SeekCurrent:
+		offset += f.off
+	case io.SeekEnd:
+		offset += int64(len(f.ino.data))
+	}
+	if offset < 0 {
+		return 0, pathErr("seek", f.name, fs.ErrInvalid)
+	}
+	f.off = offset
+	return offset, nil
+}
+
+func (f *file) Stat() (fs.FileInfo, error) {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+	if err := f.check("stat", false); err != nil && !errors.Is(err, fs.ErrPermission) {
+		return nil, err
+	}
+	return fileInfo{name: filepath.Base(f.name), size: int64(len(f.ino.data))}, nil
+}
+
+// Sync makes the file's contents durable. Like the operating system's, it
+// does not make the file's name durable.
+func (f *file) Sync() error {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+	if err := f.check("sync", false); err != nil && !errors.Is(err, fs.ErrPermission) {
+		return err
+	}
+	if err := f.fs.step(); err != nil {
+		return pathErr("sync", f.name, err)
+	}
+	f.ino.synced = append([]byte(nil), f.ino.data...)
+	return nil
+}
+
+func (f *file) Truncate(size int64) error {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+	if err := f.check("truncate", true); err != nil {
+		return err
+	}
+	if size < 0 {
+		return pathErr("truncate", f.name, fs.ErrInvalid)
+	}
+	if err := f.fs.step(); err != nil {
+		return pathErr("truncate", f.name, err)
+	}
+	if size <= int64(len(f.ino.data)) {
+		f.ino.data = f.ino.data[:size:size]
+	} else {
+		f.ino.data = append(f.ino.data, make([]byte, size-int64(len(f.ino.data)))...)
+	}
+	return nil
+}
+
+// Close releases the handle. It succeeds even after a power cut, so deferred
+// closes do not mask the error that stopped the workload.
+func (f *file) Close() error {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+	if f.closed {
+		return pathErr("close", f.name, fs.ErrClosed)
+	}
+	f.closed = true
+	return nil
+}
+
+type fileInfo struct {
+	name string
+	size int64
+	dir  bool
+}
+
+func (i fileInfo) Name() string { return i.name }
+func (i fileInfo) Size() int64  { return i.size }
+func (i fileInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0o755
+	}
+	return 0o644
+}
+func (i fileInfo) ModTime() time.Time { return time.Time{} }
+func (i fileInfo) IsDir() bool        { return i.dir }
+func (i fileInfo) Sys() any           { return nil }
@@ -0,0 +1,189 @@
+package faultfs
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/vfs"
+)
+
+func write(t *testing.T, m *FS, name string, data []byte, sync bool) vfs.File {
+	t.Helper()
+	f, err := vfs.Create(m, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if sync {
+		if err := f.Sync(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return f
+}
+
+func read(t *testing.T, m *FS, name string) ([]byte, error) {
+	t.Helper()
+	f, err := vfs.Open(m, name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+func TestReadWrite(t *testing.T) {
+	m := New(1)
+	if err := m.MkdirAll("/db/wal", 0o755); err != nil {
+		t.Fatal(err)
+	}
+	f := write(t, m, "/db/a", []byte("hello"), false)
+	if _, err := f.WriteAt([]byte("J"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteAt([]byte("!"), 7); err != nil {
+		t.Fatal(err)
+	}
+	got, err := read(t, m, "/db/a")
+	if err != nil || string(got) != "Jello\x00\x00!" {
+		t.Errorf("expected %q, got %q (%v)", "Jello\x00\x00!", got, err)
+	}
+	if err := f.Truncate(2); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := m.Stat("/db/a"); info.Size() != 2 {
+		t.Errorf("expected size 2 after truncating, got %d", info.Size())
+	}
+
+	if err := m.Rename("/db/a", "/db/b"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Stat("/db/a"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected the old name to be gone, got %v", err)
+	}
+	names, err := m.ReadDir("/db")
+	if err != nil || len(names) != 2 || names[0] != "b" || names[1] != "wal" {
+		t.Errorf("expected [b wal], got %v (%v)", names, err)
+	}
+	if _, err := m.OpenFile("/missing/x", os.O_RDWR|os.O_CREATE, 0o644); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected creating in a missing directory to fail, got %v", err)
+	}
+	if err := m.Remove("/db/b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Remove("/db/b"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected removing twice to fail, got %v", err)
+	}
+}
+
+// TestCrashKeepsOnlyDurableState checks each half of the durability model:
+// a synced file in a synced directory survives, a file whose name was never
+// synced vanishes, and an unsynced rename is undone.
+func TestCrashKeepsOnlyDurableState(t *testing.T) {
+	m := New(1)
+	write(t, m, "/synced", []byte("kept"), true)
+	write(t, m, "/renamed", []byte("old"), true)
+	if err := m.SyncDir("/"); err != nil {
+		t.Fatal(err)
+	}
+	write(t, m, "/unnamed", []byte("lost"), true)
+	write(t, m, "/tmp", []byte("new"), true)
+	if err := m.Rename("/tmp", "/renamed"); err != nil {
+		t.Fatal(err)
+	}
+
+	after := m.Crash()
+	if got, err := read(t, after, "/synced"); string(got) != "kept" {
+		t.Errorf("expected the synced file to survive, got %q (%v)", got, err)
+	}
+	if _, err := after.Stat("/unnamed"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected a file in an unsynced directory to vanish, got %v", err)
+	}
+	if got, _ := read(t, after, "/renamed"); string(got) != "old" {
+		t.Errorf("expected the unsynced rename to be undone, got %q", got)
+	}
+	if _, err := m.Stat("/synced"); !errors.Is(err, ErrCrashed) {
+		t.Errorf("expected the crashed file system to fail, got %v", err)
+	}
+}
+
+// TestCrashTearsUnsyncedData overwrites a synced file without syncing again
+// and checks every sector of the survivor comes from one version or the
+// other, and that across seeds both versions appear.
+func TestCrashTearsUnsyncedData(t *testing.T) {
+	old := bytes.Repeat([]byte{'o'}, 4*sectorSize)
+	cur := bytes.Repeat([]byte{'n'}, 4*sectorSize)
+	seen := map[byte]bool{}
+	for seed := int64(0); seed < 20; seed++ {
+		m := New(seed)
+		f := write(t, m, "/f", old, true)
+		m.SyncDir("/")
+		f.WriteAt(cur, 0)
+		got, err := read(t, m.Crash(), "/f")
+		if err != nil || len(got) != len(old) {
+			t.Fatalf("seed %d: expected %d bytes, got %d (%v)", seed, len(old), len(got), err)
+		}
+		for off := 0; off < len(got); off += sectorSize {
+			sector := got[off : off+sectorSize]
+			if !bytes.Equal(sector, old[:sectorSize]) && !bytes.Equal(sector, cur[:sectorSize]) {
+				t.Fatalf("seed %d: sector at %d mixes versions", seed, off)
+			}
+			seen[sector[0]] = true
+		}
+	}
+	if !seen['o'] || !seen['n'] {
+		t.Errorf("expected both old and new sectors across seeds, got %v", seen)
+	}
+}
+
+func TestCrashAfter(t *testing.T) {
+	m := New(1)
+	m.CrashAfter(2)
+	f, err := vfs.Create(m, "/f") // operation 1
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte("a")); err != nil { // operation 2
+		t.Fatal(err)
+	}
+	if _, err := f.ReadAt(make([]byte, 1), 0); err != nil {
+		t.Errorf("expected reads not to count as operations, got %v", err)
+	}
+	if _, err := f.Write([]byte("b")); !errors.Is(err, ErrCrashed) {
+		t.Errorf("expected the third operation to crash, got %v", err)
+	}
+	if !m.Crashed() {
+		t.Error("expected Crashed after the power cut")
+	}
+	if _, err := f.ReadAt(make([]byte, 1), 0); !errors.Is(err, ErrCrashed) {
+		t.Errorf("expected reads after the power cut to fail, got %v", err)
+	}
+	if m.Ops() != 2 {
+		t.Errorf("expected 2 completed operations, got %d", m.Ops())
+	}
+}
+
+func TestShortWrite(t *testing.T) {
+	m := New(1)
+	f, err := vfs.Create(m, "/f")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.ShortWriteAt(0)
+	n, err := f.Write([]byte("abcdef"))
+	if !errors.Is(err, io.ErrShortWrite) || n >= 6 {
+		t.Fatalf("expected a short write, got %d bytes (%v)", n, err)
+	}
+	if info, _ := f.Stat(); info.Size() != int64(n) {
+		t.Errorf("expected %d bytes stored, got %d", n, info.Size())
+	}
+	if _, err := f.Write([]byte("g")); err != nil {
+		t.Errorf("expected only one write to be shortened, got %v", err)
+	}
+}
@@ -0,0 +1,215 @@
+// Package viz registers the lab's drawable structures that are not string
+// maps, the interval and spatial trees, the heaps and the graph, behind one
+// interface fed with strings. The REPL and the browser playground use it to
+// build those structures from typed commands and draw them with Graphviz
+// next to the map implementations registered in package kv.
+package viz
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/dsa-lab/go/internal/graph"
+	"github.com/dsa-lab/go/internal/heap"
+	"github.com/dsa-lab/go/internal/interval"
+	"github.com/dsa-lab/go/internal/spatial"
+)
+
+// maxVertex bounds the vertex numbers a graph key may name, since the graph
+// grows to include every vertex up to the largest.
+const maxVertex = 1 << 10
+
+// quadBounds is the region of the quadtree structure.
+var quadBounds = spatial.Rect{Min: spatial.Point{0, 0}, Max: spatial.Point{100, 100}}
+
+// Structure is a structure that items spelled as strings can be added to and
+// that can draw itself as DOT.
+type Structure interface {
+	// Insert adds the item key spells, with value where the structure
+	// stores one. It fails if key is not in the form Implementation.Key
+	// describes or the structure rejects the item.
+	Insert(key, value string) error
+	// Len returns the number of items added.
+	Len() int
+	// Visualize writes the structure as a Graphviz DOT graph.
+	Visualize(w io.Writer) error
+}
+
+// Implementation pairs a stable name and the syntax of its keys with a
+// constructor for a Structure.
+type Implementation struct {
+	Name string
+	// Key describes the keys Insert accepts, and what becomes of the value.
+	Key string
+	New func() Structure
+}
+
+// Implementations returns every drawable structure: trees, then heaps, then
+// the graph.
+func Implementations() []Implementation {
+	return []Implementation{
+		{Name: "interval", Key: "start,end as integers", New: newInterval},
+		{Name: "kdtree", Key: "x,y", New: newKDTree},
+		{Name: "quadtree", Key: "x,y within [0, 100]", New: newQuadtree},
+		{Name: "rtree", Key: "minx,miny,maxx,maxy", New: newRTree},
+		{Name: "heap", Key: "an integer; the value is ignored", New: func() Structure { return newHeap(heap.NewOrdered[int]()) }},
+		{Name: "leftist", Key: "an integer; the value is ignored", New: func() Structure { return newHeap(heap.NewLeftistOrdered[int]()) }},
+		{Name: "skew", Key: "an integer; the value is ignored", New: func() Structure { return newHeap(heap.NewSkewOrdered[int]()) }},
+		{Name: "graph", Key: "from,to vertices; the value is the integer weight", New: newGraph},
+	}
+}
+
+// Lookup returns the registered structure with the given name.
+func Lookup(name string) (Implementation, bool) {
+	for _, impl := range Implementations() {
+		if impl.Name == name {
+			return impl, true
+		}
+	}
+	return Implementation{}, false
+}
+
+// adapter implements Structure with the functions of a concrete structure.
+type adapter struct {
+	insert    func(key, value string) error
+	length    func() int
+	visualize func(w io.Writer) error
+}
+
+func (a adapter) Insert(key, value string) error { return a.insert(key, value) }
+func (a adapter) Len() int                       { return a.length() }
+func (a adapter) Visualize(w io.Writer) error    { return a.visualize(w) }
+
+func newInterval() Structure {
+	t := interval.NewTree[int, string]()
+	return adapter{
+		insert: func(key, value string) error {
+			b, err := parseFields(key, 2, strconv.Atoi)
+			if err != nil {
+				return err
+			}
+			t.Insert(interval.Interval[int]{Start: b[0], End: b[1]}, value)
+			return nil
+		},
+		length:    t.Len,
+		visualize: t.Visualize,
+	}
+}
+
+func newKDTree() Structure {
+	t := spatial.MustNewKDTree[string](2)
+	return adapter{
+		insert: func(key, value string) error {
+			p, err := parseFields(key, 2, parseFloat)
+			if err != nil {
+				return err
+			}
+			return t.Insert(p, value)
+		},
+		length:    t.Len,
+		visualize: t.Visualize,
+	}
+}
+
+func newQuadtree() Structure {
+	t := spatial.MustNewQuadtree[string](quadBounds, 4)
+	return adapter{
+		insert: func(key, value string) error {
+			p, err := parseFields(key, 2, parseFloat)
+			if err != nil {
+				return err
+			}
+			return t.Insert(p, value)
+		},
+		length:    t.Len,
+		visualize: t.Visualize,
+	}
+}
+
+func newRTree() Structure {
+	t := spatial.MustNewRTree[string](2, 4)
+	return adapter{
+		insert: func(key, value string) error {
+			c, err := parseFields(key, 4, parseFloat)
+			if err != nil {
+				return err
+			}
+			return t.Insert(spatial.Rect{Min: spatial.Point{c[0], c[1]}, Max: spatial.Point{c[2], c[3]}}, value)
+		},
+		length:    t.Len,
+		visualize: t.Visualize,
+	}
+}
+
+// pushHeap is the part of the heaps' method sets a heap structure uses.
+type pushHeap interface {
+	Push(x int)
+	Len() int
+	Visualize(w io.Writer) error
+}
+
+func newHeap(h pushHeap) Structure {
+	return adapter{
+		insert: func(key, _ string) error {
+			x, err := strconv.Atoi(key)
+			if err != nil {
+				return fmt.Errorf("viz: key %q is not an integer", key)
+			}
+			h.Push(x)
+			return nil
+		},
+		length:    h.Len,
+		visualize: h.Visualize,
+	}
+}
+
+func newGraph() Structure {
+	g := graph.New(0, true)
+	return adapter{
+		insert: func(key, value string) error {
+			ends, err := parseFields(key, 2, strconv.Atoi)
+			if err != nil {
+				return err
+			}
+			weight, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("viz: weight %q is not an integer", value)
+			}
+			for _, v := range ends {
+				if v < 0 || v >= maxVertex {
+					return fmt.Errorf("viz: vertex %d out of range [0, %d)", v, maxVertex)
+				}
+			}
+			for g.NumVertices() <= max(ends[0], ends[1]) {
+				g.AddVertex()
+			}
+			g.AddEdge(ends[0], ends[1], weight)
+			return nil
+		},
+		length:    g.NumEdges,
+		visualize: g.Visualize,
+	}
+}
+
+func parseFloat(s string) (float64, error) {
+	return strconv.ParseFloat(s, 64)
+}
+
+// parseFields parses key as n comma-separated fields.
+func parseFields[T any](key string, n int, parse func(string) (T, error)) ([]T, error) {
+	fields := strings.Split(key, ",")
+	if len(fields) != n {
+		return nil, fmt.Errorf("viz: key %q has %d comma-separated fields, want %d", key, len(fields), n)
+	}
+	out := make([]T, n)
+	for i, f := range fields {
+		x, err := parse(f)
+		if err != nil {
+			return nil, fmt.Errorf("viz: key %q: field %q is not a number", key, f)
+		}
+		out[i] = x
+	}
+	return out, nil
+}
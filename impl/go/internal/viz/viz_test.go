@@ -0,0 +1,66 @@
+package viz
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestImplementations(t *testing.T) {
+	keys := map[string][2]string{
+		"interval": {"3,9", "a"},
+		"kdtree":   {"1.5,2", "a"},
+		"quadtree": {"50,50", "a"},
+		"rtree":    {"0,0,2,3", "a"},
+		"heap":     {"7", ""},
+		"leftist":  {"7", ""},
+		"skew":     {"7", ""},
+		"graph":    {"0,4", "12"},
+	}
+	for _, impl := range Implementations() {
+		if got, ok := Lookup(impl.Name); !ok || got.Name != impl.Name {
+			t.Errorf("%s: expected Lookup to find it", impl.Name)
+		}
+		key, ok := keys[impl.Name]
+		if !ok {
+			t.Errorf("%s: no test key", impl.Name)
+			continue
+		}
+		s := impl.New()
+		if err := s.Insert(key[0], key[1]); err != nil {
+			t.Errorf("%s: Insert(%q, %q): %v", impl.Name, key[0], key[1], err)
+		}
+		if err := s.Insert("x", "1"); err == nil {
+			t.Errorf("%s: expected an error for a malformed key", impl.Name)
+		}
+		if s.Len() != 1 {
+			t.Errorf("%s: expected 1 item, got %d", impl.Name, s.Len())
+		}
+		var buf strings.Builder
+		if err := s.Visualize(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(buf.String(), "graph ") {
+			t.Errorf("%s: expected a DOT graph, got:\n%s", impl.Name, buf.String())
+		}
+	}
+	if _, ok := Lookup("hashmap"); ok {
+		t.Error("expected maps to be registered in kv only")
+	}
+}
+
+func TestRejectedItems(t *testing.T) {
+	quad, _ := Lookup("quadtree")
+	if err := quad.New().Insert("150,50", ""); err == nil {
+		t.Error("quadtree: expected an error for a point out of bounds")
+	}
+	g, _ := Lookup("graph")
+	s := g.New()
+	for _, c := range [][2]string{{"0,2000", "1"}, {"-1,0", "1"}, {"0,1", "heavy"}} {
+		if err := s.Insert(c[0], c[1]); err == nil {
+			t.Errorf("graph: expected an error for %q weight %q", c[0], c[1])
+		}
+	}
+	if s.Len() != 0 {
+		t.Errorf("graph: expected rejected edges to be dropped, got %d", s.Len())
+	}
+}
@@ -0,0 +1,460 @@
+// Package wal provides an append-only write-ahead log split into segment
+// files, with checksummed records and recovery from torn writes.
+//
+// A record is
+//
+//	crc32c(data length | data) uint32 | data length uint32 | data
+//
+// The checksum covers the length so that a run of zeros, which a crash can
+// leave where a record was being written, never reads as a valid empty record.
+//
+// Records are appended to the newest segment; once a segment would exceed the
+// configured size a new one is started. A crash can leave the last record of
+// the newest segment partially written. Open detects this by checksum and
+// truncates the segment back to its last complete record, so the log always
+// reads as a prefix of what was appended. A damaged record anywhere else,
+// including one in the newest segment with intact records after it, is
+// corruption rather than a torn write and makes Open or iteration fail with
+// ErrCorrupt.
+package wal
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dsa-lab/go/internal/errs"
+	"github.com/dsa-lab/go/internal/vfs"
+)
+
+const (
+	headerSize         = 8
+	defaultSegmentSize = 16 << 20
+
+	// MaxRecordSize bounds a single record so a corrupt length field cannot
+	// trigger a huge allocation during replay.
+	MaxRecordSize = 1 << 30
+)
+
+var (
+	// ErrCorrupt is returned when a record other than a torn tail fails its
+	// checksum.
+	ErrCorrupt = errs.New(errs.ErrCorruptSnapshot, "wal: corrupt record")
+	// ErrClosed is returned by operations on a closed log.
+	ErrClosed = errors.New("wal: log is closed")
+	// ErrTooLarge is returned when appending a record over MaxRecordSize.
+	ErrTooLarge = errs.New(errs.ErrKeyTooLarge, "wal: record too large")
+)
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// checksum returns the checksum of a record from its encoded length and data.
+func checksum(length, data []byte) uint32 {
+	return crc32.Update(crc32.Checksum(length, castagnoli), castagnoli, data)
+}
+
+// Log is a segmented append-only log in a directory. It is not safe for
+// concurrent use.
+type Log struct {
+	fs          vfs.FS
+	dir         string
+	segments    []uint64
+	active      vfs.File
+	activeSize  int64
+	size        int64
+	segmentSize int64
+	syncWrites  bool
+	buf         []byte
+
+	// optErr is set by an option given an invalid argument.
+	optErr error
+}
+
+// Option configures a Log when it is opened.
+type Option func(*Log)
+
+// WithSegmentSize sets the size at which a new segment is started, which
+// must be positive. A record larger than the segment size gets a segment to
+// itself.
+func WithSegmentSize(bytes int64) Option {
+	return func(l *Log) {
+		if bytes < 1 {
+			l.optErr = fmt.Errorf("wal: WithSegmentSize(%d): %w", bytes, errs.ErrInvalidOption)
+			return
+		}
+		l.segmentSize = bytes
+	}
+}
+
+// WithFS makes the log reach the disk through fsys instead of the operating
+// system's file system.
+func WithFS(fsys vfs.FS) Option {
+	return func(l *Log) {
+		l.fs = fsys
+	}
+}
+
+// WithSyncWrites syncs the active segment after every append.
+func WithSyncWrites() Option {
+	return func(l *Log) {
+		l.syncWrites = true
+	}
+}
+
+func segmentName(n uint64) string {
+	return fmt.Sprintf("%08d.wal", n)
+}
+
+// Open opens the log in dir, creating the directory if needed, and repairs a
+// torn tail in the newest segment. An invalid option makes Open fail with an
+// error matching errs.ErrInvalidOption before touching the directory.
+func Open(dir string, opts ...Option) (*Log, error) {
+	l := &Log{fs: vfs.OS, dir: dir, segmentSize: defaultSegmentSize}
+	for _, opt := range opts {
+		opt(l)
+	}
+	if l.optErr != nil {
+		return nil, l.optErr
+	}
+	if err := l.fs.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	names, err := vfs.Glob(l.fs, dir, "*.wal")
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		n, err := strconv.ParseUint(strings.TrimSuffix(filepath.Base(name), ".wal"), 10, 64)
+		if err == nil {
+			l.segments = append(l.segments, n)
+		}
+	}
+	sort.Slice(l.segments, func(i, j int) bool { return l.segments[i] < l.segments[j] })
+
+	if len(l.segments) == 0 {
+		if err := l.startSegment(1); err != nil {
+			return nil, err
+		}
+		return l, nil
+	}
+
+	for _, n := range l.segments[:len(l.segments)-1] {
+		info, err := l.fs.Stat(filepath.Join(dir, segmentName(n)))
+		if err != nil {
+			return nil, err
+		}
+		l.size += info.Size()
+	}
+
+	last := l.segments[len(l.segments)-1]
+	f, err := l.fs.OpenFile(filepath.Join(dir, segmentName(last)), os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	valid, err := validPrefix(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Truncate(valid); err != nil {
+		f.Close()
+		return nil, err
+	}
+	l.active, l.activeSize = f, valid
+	l.size += valid
+	return l, nil
+}
+
+// validPrefix returns the length of the intact records at the start of f, the
+// newest segment, which ends where a crash tore the tail. A damaged record,
+// whether it fails its checksum or its length runs past the end of f, is that
+// torn tail only if no intact record starts anywhere after it. Otherwise the
+// damage is corruption, perhaps of the length field itself, and it returns an
+// error matching ErrCorrupt rather than let Open discard the records after it.
+func validPrefix(f vfs.File) (int64, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	size := info.Size()
+	var valid int64
+	for valid < size {
+		n, err := recordAt(f, valid, size)
+		if err == nil {
+			valid += n
+			continue
+		}
+		if !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, ErrCorrupt) {
+			return 0, err
+		}
+		switch found, err := intactAfter(f, valid, size); {
+		case err != nil:
+			return 0, err
+		case found:
+			return 0, fmt.Errorf("%w at offset %d of %s, before intact records", ErrCorrupt, valid, f.Name())
+		}
+		return valid, nil
+	}
+	return valid, nil
+}
+
+// intactAfter reports whether an intact record starts at any offset past off
+// in f, which is size bytes long. The length at off cannot be trusted, so
+// every later offset is tried. A crash only leaves a prefix of the unsynced
+// tail followed by zeros, and the checksum covers the length precisely so
+// that zeros never read as a record.
+func intactAfter(f io.ReaderAt, off, size int64) (bool, error) {
+	rest := make([]byte, size-off)
+	if _, err := f.ReadAt(rest, off); err != nil && err != io.EOF {
+		return false, err
+	}
+	for i := 1; i+headerSize <= len(rest); i++ {
+		n := int(binary.LittleEndian.Uint32(rest[i+4:]))
+		if n > len(rest)-i-headerSize {
+			continue
+		}
+		if checksum(rest[i+4:i+headerSize], rest[i+headerSize:i+headerSize+n]) == binary.LittleEndian.Uint32(rest[i:]) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// recordAt checks the record at offset off of f, which is size bytes long,
+// and returns its length including the header. It returns io.ErrUnexpectedEOF
+// if the record runs past the end of f, and an error matching ErrCorrupt, with
+// the length the header claims, if it fails its checksum.
+func recordAt(f io.ReaderAt, off, size int64) (int64, error) {
+	var head [headerSize]byte
+	if off+headerSize > size {
+		return 0, io.ErrUnexpectedEOF
+	}
+	if _, err := f.ReadAt(head[:], off); err != nil {
+		return 0, err
+	}
+	n := int64(binary.LittleEndian.Uint32(head[4:]))
+	if off+headerSize+n > size {
+		return 0, io.ErrUnexpectedEOF
+	}
+	if n > MaxRecordSize {
+		return headerSize + n, ErrCorrupt
+	}
+	data := make([]byte, n)
+	if _, err := f.ReadAt(data, off+headerSize); err != nil {
+		return 0, err
+	}
+	if checksum(head[4:], data) != binary.LittleEndian.Uint32(head[0:]) {
+		return headerSize + n, ErrCorrupt
+	}
+	return headerSize + n, nil
+}
+
+func readRecord(r io.Reader) ([]byte, error) {
+	var head [headerSize]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		return nil, err
+	}
+	sum := binary.LittleEndian.Uint32(head[0:])
+	n := binary.LittleEndian.Uint32(head[4:])
+	if n > MaxRecordSize {
+		return nil, ErrCorrupt
+	}
+	data := make([]byte, n)
+	if _, err := io.ReadFull(r, data); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	if checksum(head[4:], data) != sum {
+		return nil, ErrCorrupt
+	}
+	return data, nil
+}
+
+// startSegment makes segment n the active one. Only the newest segment may
+// have a torn tail, so the previous one is synced before the new one exists,
+// and records synced into the new one are only durable once its name is.
+func (l *Log) startSegment(n uint64) error {
+	if l.active != nil {
+		if err := l.active.Sync(); err != nil {
+			return err
+		}
+	}
+	f, err := vfs.Create(l.fs, filepath.Join(l.dir, segmentName(n)))
+	if err != nil {
+		return err
+	}
+	if err := l.fs.SyncDir(l.dir); err != nil {
+		f.Close()
+		return err
+	}
+	if l.active != nil {
+		l.active.Close()
+	}
+	l.active, l.activeSize = f, 0
+	l.segments = append(l.segments, n)
+	return nil
+}
+
+// Append writes data as one record. The record is durable once Sync returns,
+// or immediately when the log was opened WithSyncWrites.
+func (l *Log) Append(data []byte) error {
+	if l.active == nil {
+		return ErrClosed
+	}
+	if len(data) > MaxRecordSize {
+		return ErrTooLarge
+	}
+	n := int64(headerSize + len(data))
+	if l.activeSize > 0 && l.activeSize+n > l.segmentSize {
+		if err := l.startSegment(l.segments[len(l.segments)-1] + 1); err != nil {
+			return err
+		}
+	}
+
+	l.buf = append(l.buf[:0], make([]byte, headerSize)...)
+	binary.LittleEndian.PutUint32(l.buf[4:], uint32(len(data)))
+	binary.LittleEndian.PutUint32(l.buf[0:], checksum(l.buf[4:headerSize], data))
+	l.buf = append(l.buf, data...)
+	if _, err := l.active.WriteAt(l.buf, l.activeSize); err != nil {
+		return err
+	}
+	l.activeSize += n
+	l.size += n
+	if l.syncWrites {
+		return l.active.Sync()
+	}
+	return nil
+}
+
+// Sync makes every appended record durable.
+func (l *Log) Sync() error {
+	if l.active == nil {
+		return ErrClosed
+	}
+	return l.active.Sync()
+}
+
+// Size returns the total size of all segments in bytes.
+func (l *Log) Size() int64 {
+	return l.size
+}
+
+// Segments returns the number of segment files.
+func (l *Log) Segments() int {
+	return len(l.segments)
+}
+
+// Reset discards every record, typically once their effects have been made
+// durable elsewhere (a checkpoint). A new segment is started before the old
+// ones are removed, oldest first, so a crash part-way through can leave some
+// discarded records behind; replaying them must be harmless.
+func (l *Log) Reset() error {
+	if l.active == nil {
+		return ErrClosed
+	}
+	next := l.segments[len(l.segments)-1] + 1
+	old := l.segments
+	l.segments = nil
+	if err := l.startSegment(next); err != nil {
+		return err
+	}
+	for _, n := range old {
+		if err := l.fs.Remove(filepath.Join(l.dir, segmentName(n))); err != nil {
+			return err
+		}
+	}
+	l.size = 0
+	return l.fs.SyncDir(l.dir)
+}
+
+// Close syncs and closes the active segment.
+func (l *Log) Close() error {
+	if l.active == nil {
+		return ErrClosed
+	}
+	err := l.active.Sync()
+	if cerr := l.active.Close(); err == nil {
+		err = cerr
+	}
+	l.active = nil
+	return err
+}
+
+// Iterator reads records in the order they were appended.
+type Iterator struct {
+	fs       vfs.FS
+	dir      string
+	segments []uint64
+	f        vfs.File
+	r        *bufio.Reader
+	record   []byte
+	err      error
+}
+
+// Iterator returns an iterator over every record currently in the log.
+// Records appended after the call may or may not be seen.
+func (l *Log) Iterator() *Iterator {
+	return &Iterator{fs: l.fs, dir: l.dir, segments: append([]uint64(nil), l.segments...)}
+}
+
+// Next advances to the next record, returning false at the end of the log or
+// on error.
+func (it *Iterator) Next() bool {
+	for it.err == nil {
+		if it.f == nil {
+			if len(it.segments) == 0 {
+				return false
+			}
+			name := filepath.Join(it.dir, segmentName(it.segments[0]))
+			it.segments = it.segments[1:]
+			if it.f, it.err = vfs.Open(it.fs, name); it.err != nil {
+				return false
+			}
+			it.r = bufio.NewReader(it.f)
+		}
+
+		data, err := readRecord(it.r)
+		if err == nil {
+			it.record = data
+			return true
+		}
+		if err != io.EOF {
+			it.err = fmt.Errorf("%w in %s", ErrCorrupt, it.f.Name())
+			return false
+		}
+		it.f.Close()
+		it.f = nil
+	}
+	return false
+}
+
+// Record returns the current record. It is valid until the next call to Next.
+func (it *Iterator) Record() []byte {
+	return it.record
+}
+
+// Err returns the error that stopped iteration, if any.
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// Close releases the iterator's open segment.
+func (it *Iterator) Close() error {
+	if it.f != nil {
+		err := it.f.Close()
+		it.f = nil
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,259 @@
+package wal
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/errs"
+)
+
+func readAll(t *testing.T, l *Log) ([]string, error) {
+	t.Helper()
+	it := l.Iterator()
+	defer it.Close()
+	var records []string
+	for it.Next() {
+		records = append(records, string(it.Record()))
+	}
+	return records, it.Err()
+}
+
+func expectRecords(t *testing.T, l *Log, want []string) {
+	t.Helper()
+	got, err := readAll(t, l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("expected records %q, got %q", want, got)
+	}
+}
+
+func lastSegment(t *testing.T, dir string) string {
+	t.Helper()
+	names, err := filepath.Glob(filepath.Join(dir, "*.wal"))
+	if err != nil || len(names) == 0 {
+		t.Fatalf("no segments in %s: %v", dir, err)
+	}
+	return names[len(names)-1]
+}
+
+func TestAppendRotateReplay(t *testing.T) {
+	dir := t.TempDir()
+	l, err := Open(dir, WithSegmentSize(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want []string
+	for i := 0; i < 50; i++ {
+		record := fmt.Sprintf("record-%d", i)
+		if err := l.Append([]byte(record)); err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, record)
+	}
+	if l.Segments() < 5 {
+		t.Errorf("expected rotation into several segments, got %d", l.Segments())
+	}
+	expectRecords(t, l, want)
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reopening continues appending after the existing records.
+	if l, err = Open(dir, WithSegmentSize(100)); err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	l.Append([]byte("after-reopen"))
+	expectRecords(t, l, append(want, "after-reopen"))
+
+	if err := l.Append(nil); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := readAll(t, l)
+	if len(got) != len(want)+2 || got[len(got)-1] != "" {
+		t.Errorf("expected an empty record at the end, got %q", got[len(got)-1])
+	}
+}
+
+// TestTornWrites cuts the final record at every byte offset, as a crash
+// during the write would, and checks recovery keeps exactly the complete
+// records and accepts new appends after them.
+func TestTornWrites(t *testing.T) {
+	complete := []string{"alpha", "beta", "gamma"}
+	const torn = "delta-delta-delta"
+
+	for cut := 1; cut < headerSize+len(torn); cut++ {
+		dir := t.TempDir()
+		l, err := Open(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range complete {
+			l.Append([]byte(r))
+		}
+		l.Append([]byte(torn))
+		l.Close()
+
+		seg := lastSegment(t, dir)
+		info, _ := os.Stat(seg)
+		if err := os.Truncate(seg, info.Size()-int64(cut)); err != nil {
+			t.Fatal(err)
+		}
+
+		if l, err = Open(dir); err != nil {
+			t.Fatalf("cut %d: %v", cut, err)
+		}
+		l.Append([]byte("epsilon"))
+		expectRecords(t, l, append(append([]string(nil), complete...), "epsilon"))
+		l.Close()
+	}
+}
+
+// TestCorruptLength damages the length field of a record in the middle of
+// the newest segment. Whether the length now runs past the end of the segment
+// or falls short of the data, the intact records after it show this is not a
+// torn tail, so Open reports it rather than truncate them away.
+func TestCorruptLength(t *testing.T) {
+	for _, length := range []uint32{1 << 20, 2} {
+		dir := t.TempDir()
+		l, err := Open(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var off int64
+		for i := 0; i < 10; i++ {
+			record := fmt.Sprintf("record-%d", i)
+			if i < 3 {
+				off += int64(headerSize + len(record))
+			}
+			l.Append([]byte(record))
+		}
+		l.Close()
+
+		seg := lastSegment(t, dir)
+		data, err := os.ReadFile(seg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		binary.LittleEndian.PutUint32(data[off+4:], length)
+		if err := os.WriteFile(seg, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := Open(dir); !errors.Is(err, ErrCorrupt) {
+			t.Errorf("length %d: expected ErrCorrupt, got %v", length, err)
+		}
+		if info, _ := os.Stat(seg); info.Size() != int64(len(data)) {
+			t.Errorf("length %d: expected the segment to be left whole, got %d bytes", length, info.Size())
+		}
+	}
+}
+
+func TestCorruption(t *testing.T) {
+	flip := func(path string, offset int64) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data[offset] ^= 0x40
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A damaged final record is indistinguishable from a torn write and is
+	// dropped.
+	dir := t.TempDir()
+	l, _ := Open(dir)
+	l.Append([]byte("one"))
+	l.Append([]byte("two"))
+	l.Close()
+	flip(lastSegment(t, dir), headerSize+3+headerSize+1)
+	l, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectRecords(t, l, []string{"one"})
+	l.Close()
+
+	// Damage in the newest segment with intact records after it is not a
+	// torn write either: Open reports it instead of truncating them away.
+	dir = t.TempDir()
+	l, _ = Open(dir)
+	for _, r := range []string{"one", "two", "three"} {
+		l.Append([]byte(r))
+	}
+	l.Close()
+	flip(lastSegment(t, dir), headerSize+3+headerSize+1)
+	if _, err := Open(dir); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("expected ErrCorrupt for damage before intact records, got %v", err)
+	}
+	if info, _ := os.Stat(lastSegment(t, dir)); info.Size() != 3*headerSize+3+3+5 {
+		t.Errorf("expected the damaged segment to be left whole, got %d bytes", info.Size())
+	}
+
+	// Damage in an older segment cannot be a torn write and is reported.
+	dir = t.TempDir()
+	l, _ = Open(dir, WithSegmentSize(16))
+	for i := 0; i < 4; i++ {
+		l.Append([]byte(fmt.Sprintf("rec%d", i)))
+	}
+	l.Close()
+	flip(filepath.Join(dir, segmentName(1)), headerSize)
+	l, err = Open(dir, WithSegmentSize(16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	if _, err := readAll(t, l); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("expected ErrCorrupt, got %v", err)
+	}
+}
+
+func TestReset(t *testing.T) {
+	dir := t.TempDir()
+	l, err := Open(dir, WithSegmentSize(32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		l.Append([]byte(fmt.Sprintf("old-%d", i)))
+	}
+	if err := l.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	if l.Size() != 0 || l.Segments() != 1 {
+		t.Errorf("expected one empty segment after reset, got size %d in %d segments", l.Size(), l.Segments())
+	}
+	l.Append([]byte("new"))
+	l.Close()
+
+	if l, err = Open(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	expectRecords(t, l, []string{"new"})
+
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Append([]byte("x")); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected ErrClosed, got %v", err)
+	}
+}
+
+func TestInvalidSegmentSize(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "log")
+	if _, err := Open(dir, WithSegmentSize(0)); !errors.Is(err, errs.ErrInvalidOption) {
+		t.Errorf("expected ErrInvalidOption, got %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("expected no directory after a rejected Open, got %v", err)
+	}
+}
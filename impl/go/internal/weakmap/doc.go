@@ -0,0 +1,11 @@
+// Package weakmap provides a map whose values are held weakly: an entry
+// lasts only as long as its value is referenced from somewhere else, and the
+// garbage collector's cleanup removes it afterwards. It suits caches of
+// objects that are already owned elsewhere, such as parsed documents keyed
+// by path or canonical instances keyed by content, where a strong map would
+// keep every object alive for the life of the cache.
+//
+// The map is built on Go 1.24's weak pointers and runtime.AddCleanup. The
+// module still builds with Go 1.21, so everything but this comment lives in
+// files constrained to go1.24 and is absent on older toolchains.
+package weakmap
@@ -0,0 +1,12 @@
+//go:build go1.23
+
+package weakmap
+
+import "iter"
+
+// All returns an iterator over the keys and live values in unspecified
+// order. Like Range, it skips collected values and yields without holding
+// the map's lock, so the loop body may call any method of m.
+func (m *Map[K, V]) All() iter.Seq2[K, *V] {
+	return m.Range
+}
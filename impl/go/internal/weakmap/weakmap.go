@@ -0,0 +1,173 @@
+//go:build go1.24
+
+package weakmap
+
+import (
+	"runtime"
+	"sync"
+	"weak"
+)
+
+// Map maps keys to weakly held *V values. Once a value becomes unreachable
+// outside the map, Get stops returning it at once, and the entry itself is
+// deleted when the collector runs its cleanup, some time after the next
+// collection. A Map is safe for concurrent use, since cleanups run on a
+// goroutine of the runtime's choosing.
+//
+// Cleanups are not guaranteed for values the allocator batches together:
+// pointer-free objects smaller than 16 bytes, such as a lone *int. Such an
+// entry still disappears from Get and Range once its value is unreachable,
+// but it is only deleted by a later Get, Range, Set or Delete of its key.
+type Map[K comparable, V any] struct {
+	mu      sync.Mutex
+	entries map[K]entry[V]
+	expired uint64
+}
+
+type entry[V any] struct {
+	ptr     weak.Pointer[V]
+	cleanup runtime.Cleanup
+}
+
+// drop identifies the entry a cleanup should delete. It must not refer to
+// the value, or the value would never become unreachable.
+type drop[K comparable, V any] struct {
+	m   *Map[K, V]
+	key K
+	ptr weak.Pointer[V]
+}
+
+// New creates an empty Map.
+func New[K comparable, V any]() *Map[K, V] {
+	return &Map[K, V]{entries: make(map[K]entry[V])}
+}
+
+// Len returns the number of entries, which may include entries whose values
+// were collected but whose cleanups have not run yet.
+func (m *Map[K, V]) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.entries)
+}
+
+// Expired returns how many entries have been removed because their values
+// were collected.
+func (m *Map[K, V]) Expired() uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.expired
+}
+
+// Set maps key to v, replacing any previous value. The map does not keep v
+// alive. A nil v deletes key.
+func (m *Map[K, V]) Set(key K, v *V) {
+	if v == nil {
+		m.Delete(key)
+		return
+	}
+	ptr := weak.Make(v)
+	cleanup := runtime.AddCleanup(v, expire[K, V], drop[K, V]{m: m, key: key, ptr: ptr})
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if old, ok := m.entries[key]; ok {
+		old.cleanup.Stop()
+	}
+	m.entries[key] = entry[V]{ptr: ptr, cleanup: cleanup}
+}
+
+// expire is the cleanup attached to every value. It deletes the entry only
+// if it still holds that value: the key may have been set again since.
+func expire[K comparable, V any](d drop[K, V]) {
+	d.m.mu.Lock()
+	defer d.m.mu.Unlock()
+	if e, ok := d.m.entries[d.key]; ok && e.ptr == d.ptr {
+		delete(d.m.entries, d.key)
+		d.m.expired++
+	}
+}
+
+// Get returns the value for key, or false if key is absent or its value has
+// been collected. The returned pointer keeps the value alive while the
+// caller holds it.
+func (m *Map[K, V]) Get(key K) (*V, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.get(key)
+}
+
+func (m *Map[K, V]) get(key K) (*V, bool) {
+	e, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+	v := e.ptr.Value()
+	if v == nil {
+		// Collected, but the cleanup has not run yet; it will find the
+		// entry gone and do nothing.
+		e.cleanup.Stop()
+		delete(m.entries, key)
+		m.expired++
+		return nil, false
+	}
+	return v, true
+}
+
+// LoadOrStore returns the live value for key if there is one. Otherwise it
+// stores v and returns it. The boolean reports whether the value was loaded.
+// The lookup and the store happen under one lock, so concurrent callers
+// with the same key all get the one value that was stored: it is the
+// building block of a canonicalizing cache, in which every caller asking for
+// the same key shares one instance for as long as any of them holds it. A
+// nil v is never stored.
+func (m *Map[K, V]) LoadOrStore(key K, v *V) (*V, bool) {
+	if v == nil {
+		return m.Get(key)
+	}
+	// The cleanup is attached before locking, as in Set, and stopped if
+	// another value is already there.
+	ptr := weak.Make(v)
+	cleanup := runtime.AddCleanup(v, expire[K, V], drop[K, V]{m: m, key: key, ptr: ptr})
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if old, ok := m.get(key); ok {
+		cleanup.Stop()
+		return old, true
+	}
+	m.entries[key] = entry[V]{ptr: ptr, cleanup: cleanup}
+	return v, false
+}
+
+// Delete removes key.
+func (m *Map[K, V]) Delete(key K) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e, ok := m.entries[key]; ok {
+		e.cleanup.Stop()
+		delete(m.entries, key)
+	}
+}
+
+// Range calls f with each key and live value, in unspecified order, until f
+// returns false. Entries whose values have been collected are skipped and
+// deleted. f runs without the map's lock, so it may call any method of m.
+func (m *Map[K, V]) Range(f func(key K, v *V) bool) {
+	type pair struct {
+		key K
+		v   *V
+	}
+	m.mu.Lock()
+	live := make([]pair, 0, len(m.entries))
+	for key := range m.entries {
+		if v, ok := m.get(key); ok {
+			live = append(live, pair{key, v})
+		}
+	}
+	m.mu.Unlock()
+	for _, p := range live {
+		if !f(p.key, p.v) {
+			return
+		}
+	}
+}
@@ -0,0 +1,196 @@
+//go:build go1.24
+
+package weakmap
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+type doc struct {
+	name string
+	body []byte
+}
+
+func newDoc(name string) *doc {
+	return &doc{name: name, body: make([]byte, 64)}
+}
+
+// collect runs collections until cond holds, giving cleanups, which run on
+// their own goroutine, a chance to finish.
+func collect(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not reached after repeated collections")
+		}
+		runtime.GC()
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestCollectedValuesAreDropped(t *testing.T) {
+	m := New[string, doc]()
+	kept := make([]*doc, 0, 50)
+	for i := 0; i < 100; i++ {
+		d := newDoc(fmt.Sprint(i))
+		m.Set(d.name, d)
+		if i%2 == 0 {
+			kept = append(kept, d)
+		}
+	}
+	if m.Len() != 100 {
+		t.Fatalf("expected 100 entries, got %d", m.Len())
+	}
+
+	collect(t, func() bool { return m.Len() == 50 })
+	if m.Expired() != 50 {
+		t.Errorf("expected 50 expired entries, got %d", m.Expired())
+	}
+	for _, d := range kept {
+		if got, ok := m.Get(d.name); !ok || got != d {
+			t.Errorf("expected referenced %s to survive, got %v %v", d.name, got, ok)
+		}
+	}
+	if _, ok := m.Get("1"); ok {
+		t.Error("expected unreferenced value to be gone")
+	}
+	n := 0
+	m.Range(func(_ string, _ *doc) bool {
+		n++
+		return true
+	})
+	if n != 50 {
+		t.Errorf("expected Range to visit 50 live entries, got %d", n)
+	}
+
+	runtime.KeepAlive(kept)
+	kept = nil
+	collect(t, func() bool { return m.Len() == 0 })
+}
+
+func TestReplacedValueCleanup(t *testing.T) {
+	m := New[string, doc]()
+	m.Set("k", newDoc("old"))
+	current := newDoc("new")
+	m.Set("k", current)
+
+	// The old value's cleanup was stopped; even had it run, it must not
+	// delete the entry that now holds the new value.
+	for i := 0; i < 5; i++ {
+		runtime.GC()
+	}
+	if got, ok := m.Get("k"); !ok || got != current {
+		t.Fatalf("expected the replacement to survive, got %v %v", got, ok)
+	}
+	if m.Expired() != 0 {
+		t.Errorf("expected no expirations, got %d", m.Expired())
+	}
+
+	m.Set("k", nil)
+	if _, ok := m.Get("k"); ok || m.Len() != 0 {
+		t.Error("expected Set with nil to delete")
+	}
+	m.Set("k", current)
+	m.Delete("k")
+	if m.Len() != 0 {
+		t.Error("expected Delete to remove the entry")
+	}
+	runtime.KeepAlive(current)
+}
+
+func TestLoadOrStore(t *testing.T) {
+	m := New[string, doc]()
+	first := newDoc("a")
+	if got, loaded := m.LoadOrStore("a", first); loaded || got != first {
+		t.Fatalf("expected to store first, got %v %v", got, loaded)
+	}
+	if got, loaded := m.LoadOrStore("a", newDoc("a")); !loaded || got != first {
+		t.Errorf("expected to load first, got %v %v", got, loaded)
+	}
+	runtime.KeepAlive(first)
+	first = nil
+
+	collect(t, func() bool { return m.Len() == 0 })
+	second := newDoc("a")
+	if got, loaded := m.LoadOrStore("a", second); loaded || got != second {
+		t.Errorf("expected a fresh store after collection, got %v %v", got, loaded)
+	}
+	runtime.KeepAlive(second)
+}
+
+func TestConcurrentLoadOrStore(t *testing.T) {
+	const goroutines, keys = 8, 200
+	m := New[int, doc]()
+	got := make([][]*doc, goroutines)
+	for g := range got {
+		got[g] = make([]*doc, keys)
+	}
+	// Every goroutine asks for a key at once, then they all move on to the
+	// next one together.
+	for key := 0; key < keys; key++ {
+		start := make(chan struct{})
+		var wg sync.WaitGroup
+		for g := range got {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				<-start
+				got[g][key], _ = m.LoadOrStore(key, newDoc(fmt.Sprint(key)))
+			}(g)
+		}
+		close(start)
+		wg.Wait()
+	}
+
+	// Every caller holds what it got, so no value can have been collected
+	// and each key must have been canonicalized to one instance.
+	for key := 0; key < keys; key++ {
+		for g := 1; g < goroutines; g++ {
+			if got[g][key] != got[0][key] {
+				t.Fatalf("key %d: expected every caller to share one value, got %p and %p", key, got[0][key], got[g][key])
+			}
+		}
+		if v, ok := m.Get(key); !ok || v != got[0][key] {
+			t.Fatalf("key %d: expected the shared value to be stored, got %p", key, v)
+		}
+	}
+	runtime.KeepAlive(got)
+}
+
+func TestConcurrentUse(t *testing.T) {
+	m := New[int, doc]()
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			hold := make(map[int]*doc)
+			for i := 0; i < 2000; i++ {
+				key := g*2000 + i
+				d := newDoc(fmt.Sprint(key))
+				m.Set(key, d)
+				if i%10 == 0 {
+					hold[key] = d
+					runtime.GC()
+				}
+				m.Get(key - 1)
+			}
+			for key, d := range hold {
+				if got, ok := m.Get(key); !ok || got != d {
+					t.Errorf("expected held value %d to survive, got %v %v", key, got, ok)
+				}
+			}
+			runtime.KeepAlive(hold)
+		}(g)
+	}
+	wg.Wait()
+	collect(t, func() bool { return m.Len() == 0 })
+	if m.Expired() != 8000 {
+		t.Errorf("expected all 8000 entries to expire, got %d", m.Expired())
+	}
+}
@@ -0,0 +1,19 @@
+//go:build go1.23
+
+package window
+
+import "iter"
+
+// All returns an iterator over the samples in the window from oldest to
+// newest, after dropping those that have aged out. Samples must not be added
+// during iteration.
+func (a *Aggregator) All() iter.Seq[Sample] {
+	return func(yield func(Sample) bool) {
+		a.expire(a.now())
+		for s := range a.samples.All() {
+			if !yield(s.Sample) {
+				return
+			}
+		}
+	}
+}
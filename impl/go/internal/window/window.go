@@ -0,0 +1,223 @@
+// Package window aggregates a stream of samples over a sliding window: the
+// last N samples, the samples of the last T, or both. Count, sum and mean are
+// maintained incrementally; min and max come from monotonic deques, so every
+// query is O(1) and each sample is pushed and popped at most once per deque.
+package window
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dsa-lab/go/internal/errs"
+	"github.com/dsa-lab/go/internal/queue"
+)
+
+// Sample is a value observed at a point in time.
+type Sample struct {
+	At    time.Time
+	Value float64
+}
+
+// entry is a sample numbered in arrival order, which tells apart equal
+// samples recorded at the same time.
+type entry struct {
+	Sample
+	seq uint64
+}
+
+// Aggregator keeps the samples inside its window. With only a sample count
+// it never expires samples by age; with only a span it holds every sample
+// of the last span. It is not safe for concurrent use.
+type Aggregator struct {
+	size int
+	span time.Duration
+	now  func() time.Time
+
+	samples *queue.Deque[entry]
+	seq     uint64
+	sum     float64
+	// mins holds samples in ascending value order from front to back, each
+	// newer than the one before it; the front is the window minimum. maxs
+	// mirrors it for the maximum.
+	mins, maxs *queue.Deque[entry]
+
+	// optErr is set by an option given an invalid argument.
+	optErr error
+}
+
+// Option configures an Aggregator at construction time.
+type Option func(*Aggregator)
+
+// WithLastN bounds the window to the n most recent samples, at least 1.
+func WithLastN(n int) Option {
+	return func(a *Aggregator) {
+		if n < 1 {
+			a.optErr = fmt.Errorf("window: WithLastN(%d): %w", n, errs.ErrInvalidOption)
+			return
+		}
+		a.size = n
+	}
+}
+
+// WithLast bounds the window to samples no older than d, which must be
+// positive.
+func WithLast(d time.Duration) Option {
+	return func(a *Aggregator) {
+		if d <= 0 {
+			a.optErr = fmt.Errorf("window: WithLast(%v): %w", d, errs.ErrInvalidOption)
+			return
+		}
+		a.span = d
+	}
+}
+
+// WithClock replaces time.Now, for tests and simulations.
+func WithClock(now func() time.Time) Option {
+	return func(a *Aggregator) {
+		a.now = now
+	}
+}
+
+// New creates an empty Aggregator. Without WithLastN or WithLast the window
+// is unbounded. An invalid option is an error matching errs.ErrInvalidOption.
+func New(opts ...Option) (*Aggregator, error) {
+	a := &Aggregator{
+		now:     time.Now,
+		samples: queue.NewDeque[entry](),
+		mins:    queue.NewDeque[entry](),
+		maxs:    queue.NewDeque[entry](),
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	if a.optErr != nil {
+		return nil, a.optErr
+	}
+	return a, nil
+}
+
+// MustNew is like New but panics if an option is invalid.
+func MustNew(opts ...Option) *Aggregator {
+	return errs.Must(New(opts...))
+}
+
+// Add records v at the current time.
+func (a *Aggregator) Add(v float64) {
+	a.AddAt(a.now(), v)
+}
+
+// AddAt records v at t. Samples must be added in time order; a sample older
+// than the newest is recorded at the newest sample's time.
+func (a *Aggregator) AddAt(t time.Time, v float64) {
+	if last, ok := a.samples.Back(); ok && t.Before(last.At) {
+		t = last.At
+	}
+	s := entry{Sample: Sample{At: t, Value: v}, seq: a.seq}
+	a.seq++
+	a.samples.PushBack(s)
+	a.sum += v
+	for back, ok := a.mins.Back(); ok && back.Value >= v; back, ok = a.mins.Back() {
+		a.mins.PopBack()
+	}
+	a.mins.PushBack(s)
+	for back, ok := a.maxs.Back(); ok && back.Value <= v; back, ok = a.maxs.Back() {
+		a.maxs.PopBack()
+	}
+	a.maxs.PushBack(s)
+
+	if a.size > 0 && a.samples.Len() > a.size {
+		a.evict()
+	}
+	a.expire(t)
+}
+
+// evict drops the oldest sample. The front of a monotonic deque is the
+// oldest sample it still holds, so it is the evicted sample or newer.
+func (a *Aggregator) evict() {
+	s, _ := a.samples.PopFront()
+	a.sum -= s.Value
+	if a.samples.Len() == 0 {
+		// Resetting here keeps rounding error from accumulating in sum.
+		a.sum = 0
+	}
+	if front, ok := a.mins.Front(); ok && front.seq == s.seq {
+		a.mins.PopFront()
+	}
+	if front, ok := a.maxs.Front(); ok && front.seq == s.seq {
+		a.maxs.PopFront()
+	}
+}
+
+// expire drops the samples older than the span as of now.
+func (a *Aggregator) expire(now time.Time) {
+	if a.span <= 0 {
+		return
+	}
+	cutoff := now.Add(-a.span)
+	for front, ok := a.samples.Front(); ok && !front.At.After(cutoff); front, ok = a.samples.Front() {
+		a.evict()
+	}
+}
+
+// Count returns the number of samples in the window.
+func (a *Aggregator) Count() int {
+	a.expire(a.now())
+	return a.samples.Len()
+}
+
+// Sum returns the sum of the samples in the window.
+func (a *Aggregator) Sum() float64 {
+	a.expire(a.now())
+	return a.sum
+}
+
+// Mean returns the average of the samples in the window, or false if it is
+// empty.
+func (a *Aggregator) Mean() (float64, bool) {
+	if n := a.Count(); n > 0 {
+		return a.sum / float64(n), true
+	}
+	return 0, false
+}
+
+// Min returns the smallest sample in the window, or false if it is empty.
+func (a *Aggregator) Min() (float64, bool) {
+	a.expire(a.now())
+	s, ok := a.mins.Front()
+	return s.Value, ok
+}
+
+// Max returns the largest sample in the window, or false if it is empty.
+func (a *Aggregator) Max() (float64, bool) {
+	a.expire(a.now())
+	s, ok := a.maxs.Front()
+	return s.Value, ok
+}
+
+// Rate returns the sum of the window per second of its span, such as
+// operations per second when each sample counts operations. Without a span
+// it divides by the time between the oldest sample and now.
+func (a *Aggregator) Rate() float64 {
+	now := a.now()
+	a.expire(now)
+	elapsed := a.span
+	if elapsed <= 0 {
+		oldest, ok := a.samples.Front()
+		if !ok {
+			return 0
+		}
+		elapsed = now.Sub(oldest.At)
+	}
+	if elapsed <= 0 {
+		return 0
+	}
+	return a.sum / elapsed.Seconds()
+}
+
+// Reset empties the window.
+func (a *Aggregator) Reset() {
+	a.samples.Clear()
+	a.mins.Clear()
+	a.maxs.Clear()
+	a.sum = 0
+}
@@ -0,0 +1,104 @@
+package window
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/dsa-lab/go/internal/errs"
+)
+
+func TestLastN(t *testing.T) {
+	a := MustNew(WithLastN(3))
+	if _, ok := a.Min(); ok {
+		t.Error("expected no minimum in an empty window")
+	}
+	for _, v := range []float64{5, 1, 4, 4, 2} {
+		a.Add(v)
+	}
+	// The window holds 4, 4, 2.
+	if a.Count() != 3 || a.Sum() != 10 {
+		t.Errorf("expected 3 samples summing to 10, got %d and %v", a.Count(), a.Sum())
+	}
+	if v, _ := a.Min(); v != 2 {
+		t.Errorf("expected min 2, got %v", v)
+	}
+	if v, _ := a.Max(); v != 4 {
+		t.Errorf("expected max 4, got %v", v)
+	}
+	a.Add(1)
+	a.Add(1)
+	// Equal samples leave the window one at a time.
+	if v, _ := a.Max(); v != 2 {
+		t.Errorf("expected max 2, got %v", v)
+	}
+	a.Add(3)
+	if v, _ := a.Min(); v != 1 {
+		t.Errorf("expected min 1 while one 1 remains, got %v", v)
+	}
+}
+
+func TestLastDuration(t *testing.T) {
+	now := time.Unix(0, 0)
+	a := MustNew(WithLast(time.Second), WithClock(func() time.Time { return now }))
+	for i := 0; i < 10; i++ {
+		a.Add(100)
+		now = now.Add(200 * time.Millisecond)
+	}
+	// It is now 2s: samples at 1.2s to 1.8s remain, and the one at exactly
+	// 1.0s is a full span old and has left.
+	if a.Count() != 4 || a.Rate() != 400 {
+		t.Errorf("expected 4 samples at 400/s, got %d at %v/s", a.Count(), a.Rate())
+	}
+	if mean, _ := a.Mean(); mean != 100 {
+		t.Errorf("expected mean 100, got %v", mean)
+	}
+	now = now.Add(time.Second)
+	if a.Count() != 0 || a.Rate() != 0 {
+		t.Errorf("expected every sample to expire, got %d", a.Count())
+	}
+}
+
+func TestAgainstRecompute(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	now := time.Unix(0, 0)
+	const span = 50 * time.Millisecond
+	a := MustNew(WithLastN(16), WithLast(span), WithClock(func() time.Time { return now }))
+	var all []Sample
+
+	for i := 0; i < 5000; i++ {
+		now = now.Add(time.Duration(rng.Intn(10)) * time.Millisecond)
+		v := float64(rng.Intn(20))
+		a.Add(v)
+		all = append(all, Sample{At: now, Value: v})
+
+		var want []float64
+		for j := len(all) - 1; j >= 0 && len(want) < 16 && all[j].At.After(now.Add(-span)); j-- {
+			want = append(want, all[j].Value)
+		}
+		lo, hi, sum := math.Inf(1), math.Inf(-1), 0.0
+		for _, x := range want {
+			lo, hi, sum = min(lo, x), max(hi, x), sum+x
+		}
+		gotLo, _ := a.Min()
+		gotHi, _ := a.Max()
+		if a.Count() != len(want) || gotLo != lo || gotHi != hi || math.Abs(a.Sum()-sum) > 1e-9 {
+			t.Fatalf("step %d: expected %d samples in [%v, %v] summing to %v, got %d in [%v, %v] summing to %v",
+				i, len(want), lo, hi, sum, a.Count(), gotLo, gotHi, a.Sum())
+		}
+	}
+}
+
+func TestInvalidOptions(t *testing.T) {
+	for name, opt := range map[string]Option{
+		"WithLastN(0)": WithLastN(0),
+		"WithLast(0)":  WithLast(0),
+		"WithLast(-1)": WithLast(-time.Second),
+	} {
+		if _, err := New(opt); !errors.Is(err, errs.ErrInvalidOption) {
+			t.Errorf("%s: expected ErrInvalidOption, got %v", name, err)
+		}
+	}
+}
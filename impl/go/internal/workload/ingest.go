@@ -0,0 +1,207 @@
+package workload
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// OpGetOrInsert is an Ingest.DefaultOp for key streams without operations:
+// the first occurrence of a key becomes an insert and every later one a get,
+// the access pattern of a cache or a word-count table fed by the stream.
+const OpGetOrInsert = "get-or-insert"
+
+// ErrNoKey is returned when an ingested record has no key column.
+var ErrNoKey = errors.New("workload: record has no key column")
+
+// opAliases maps the operation spellings found in real logs to workload ops,
+// including HTTP methods so web-server access logs can be replayed directly.
+var opAliases = map[string]string{
+	"insert": OpInsert, "put": OpInsert, "set": OpInsert, "post": OpInsert,
+	"get": OpGet, "read": OpGet, "head": OpGet,
+	"delete": OpDelete, "del": OpDelete, "remove": OpDelete,
+}
+
+// Ingest describes how to turn a CSV file or a line-delimited key stream,
+// such as an access log or a word list, into a workload. Column indices are
+// zero-based; a negative OpColumn or ValueColumn means the column is absent.
+type Ingest struct {
+	// Lines treats each non-blank line as a key instead of parsing CSV.
+	Lines bool
+	// Comma is the CSV field delimiter; zero means ','.
+	Comma  rune
+	Header bool
+
+	KeyColumn   int
+	OpColumn    int
+	ValueColumn int
+
+	// DefaultOp is used for records without an op column: OpInsert, OpGet,
+	// OpDelete or OpGetOrInsert. Empty means OpGetOrInsert.
+	DefaultOp string
+	// Limit stops after this many operations when positive.
+	Limit int
+}
+
+// DefaultIngest returns the settings Open uses for path: line-delimited keys
+// for .txt, .log and .lst files, CSV otherwise, tab-separated for .tsv.
+// Each record is a key in column 0 replayed as get-or-insert.
+func DefaultIngest(path string) Ingest {
+	in := Ingest{OpColumn: -1, ValueColumn: -1}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".txt", ".log", ".lst":
+		in.Lines = true
+	case ".tsv":
+		in.Comma = '\t'
+	}
+	return in
+}
+
+// ParseColumns applies a column spec such as "op=0,key=2,value=3" to in.
+func (in *Ingest) ParseColumns(spec string) error {
+	for _, field := range strings.Split(spec, ",") {
+		name, index, ok := strings.Cut(strings.TrimSpace(field), "=")
+		n, err := strconv.Atoi(index)
+		if !ok || err != nil || n < 0 {
+			return fmt.Errorf("workload: bad column %q, want name=index", field)
+		}
+		switch name {
+		case "op":
+			in.OpColumn = n
+		case "key":
+			in.KeyColumn = n
+		case "value":
+			in.ValueColumn = n
+		default:
+			return fmt.Errorf("workload: unknown column %q", name)
+		}
+	}
+	return nil
+}
+
+// Open loads a workload from path: JSON workloads as Load does, anything else
+// through DefaultIngest.
+func Open(path string) (*Workload, error) {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return Load(path)
+	}
+	return DefaultIngest(path).Load(path)
+}
+
+// Load reads the file at path and converts it with in.
+func (in Ingest) Load(path string) (*Workload, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return in.Read(f, name)
+}
+
+// Read converts the records read from r into a workload named name. Inserts
+// without a value column store the key as the value. Expected results are
+// filled in by Annotate, so the workload can be verified like a generated one.
+func (in Ingest) Read(r io.Reader, name string) (*Workload, error) {
+	defaultOp := in.DefaultOp
+	if defaultOp == "" {
+		defaultOp = OpGetOrInsert
+	}
+	seen := make(map[string]bool)
+	var ops []Operation
+
+	add := func(record []string, line int) error {
+		if in.KeyColumn >= len(record) {
+			return fmt.Errorf("line %d: %w", line, ErrNoKey)
+		}
+		op := Operation{Op: defaultOp, Key: record[in.KeyColumn], Value: record[in.KeyColumn]}
+		if in.OpColumn >= 0 {
+			if in.OpColumn >= len(record) {
+				return fmt.Errorf("line %d: no op column %d", line, in.OpColumn)
+			}
+			mapped, ok := opAliases[strings.ToLower(strings.TrimSpace(record[in.OpColumn]))]
+			if !ok {
+				return fmt.Errorf("line %d: unknown op %q", line, record[in.OpColumn])
+			}
+			op.Op = mapped
+		}
+		if in.ValueColumn >= 0 && in.ValueColumn < len(record) {
+			op.Value = record[in.ValueColumn]
+		}
+		if op.Op == OpGetOrInsert {
+			op.Op = OpGet
+			if !seen[op.Key] {
+				op.Op = OpInsert
+			}
+		}
+		if op.Op != OpInsert {
+			op.Value = ""
+		}
+		seen[op.Key] = true
+		ops = append(ops, op)
+		return nil
+	}
+
+	if in.Lines {
+		scanner := bufio.NewScanner(r)
+		for line := 1; scanner.Scan(); line++ {
+			text := strings.TrimSpace(scanner.Text())
+			if text == "" || (in.Header && line == 1) {
+				continue
+			}
+			if err := add([]string{text}, line); err != nil {
+				return nil, err
+			}
+			if in.Limit > 0 && len(ops) == in.Limit {
+				break
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	} else {
+		cr := csv.NewReader(r)
+		if in.Comma != 0 {
+			cr.Comma = in.Comma
+		}
+		cr.FieldsPerRecord = -1
+		cr.ReuseRecord = true
+		for line := 1; ; line++ {
+			record, err := cr.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			if in.Header && line == 1 {
+				continue
+			}
+			if err := add(record, line); err != nil {
+				return nil, err
+			}
+			if in.Limit > 0 && len(ops) == in.Limit {
+				break
+			}
+		}
+	}
+
+	w := &Workload{
+		Name:             name,
+		Description:      "ingested from " + name,
+		Size:             len(ops),
+		Distribution:     "ingested",
+		OperationWeights: Weights(ops),
+		Operations:       ops,
+	}
+	if err := Annotate(w); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
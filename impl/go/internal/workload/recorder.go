@@ -0,0 +1,56 @@
+package workload
+
+import (
+	"github.com/dsa-lab/go/internal/kv"
+)
+
+// Recorder wraps a Map and records every Insert, Get and Remove applied
+// through it, so organic traffic can be saved as a workload and replayed.
+// Each recorded operation carries the wrapped map's result as its expectation.
+type Recorder struct {
+	kv.Map
+	ops []Operation
+}
+
+// NewRecorder creates a Recorder around m.
+func NewRecorder(m kv.Map) *Recorder {
+	return &Recorder{Map: m}
+}
+
+// Insert records and forwards an insert.
+func (r *Recorder) Insert(key, value string) (string, bool) {
+	old, found := r.Map.Insert(key, value)
+	r.ops = append(r.ops, Operation{Op: OpInsert, Key: key, Value: value, Expect: &Result{Found: found, Value: old}})
+	return old, found
+}
+
+// Get records and forwards a lookup.
+func (r *Recorder) Get(key string) (string, bool) {
+	value, found := r.Map.Get(key)
+	r.ops = append(r.ops, Operation{Op: OpGet, Key: key, Expect: &Result{Found: found, Value: value}})
+	return value, found
+}
+
+// Remove records and forwards a removal.
+func (r *Recorder) Remove(key string) (string, bool) {
+	value, found := r.Map.Remove(key)
+	r.ops = append(r.ops, Operation{Op: OpDelete, Key: key, Expect: &Result{Found: found, Value: value}})
+	return value, found
+}
+
+// Operations returns the operations recorded so far.
+func (r *Recorder) Operations() []Operation {
+	return r.ops
+}
+
+// Workload packages the recorded operations as a named workload.
+func (r *Recorder) Workload(name, description string) *Workload {
+	return &Workload{
+		Name:             name,
+		Description:      description,
+		Size:             len(r.ops),
+		Distribution:     "recorded",
+		OperationWeights: Weights(r.ops),
+		Operations:       r.ops,
+	}
+}
@@ -0,0 +1,237 @@
+package workload
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/dsa-lab/go/internal/kv"
+)
+
+// ErrNotWorkload is returned when a stream is not a JSON object.
+var ErrNotWorkload = errors.New("workload: stream is not a JSON object")
+
+// streamBatch is how many operations Stream hands over at a time. Batches
+// amortize the channel handoff between the decoding and replaying
+// goroutines; a few of them in flight bound the memory a stream needs.
+const (
+	streamBatch    = 1024
+	streamInFlight = 4
+)
+
+// Decoder reads a workload file one operation at a time, so a replay holds
+// a single operation in memory instead of the whole file. The top-level
+// fields are collected as they are met: those before "operations" are in
+// Header as soon as the first operation is read, and any after it once Next
+// has returned io.EOF.
+type Decoder struct {
+	dec    *json.Decoder
+	fields map[string]json.RawMessage
+	header Workload
+	inOps  bool
+	done   bool
+}
+
+// NewDecoder returns a Decoder reading from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{dec: json.NewDecoder(r), fields: make(map[string]json.RawMessage)}
+}
+
+// Header returns the workload's top-level fields read so far, with no
+// operations.
+func (d *Decoder) Header() *Workload {
+	h := d.header
+	return &h
+}
+
+// Next returns the next operation, or io.EOF after the last one.
+func (d *Decoder) Next() (Operation, error) {
+	if d.done {
+		return Operation{}, io.EOF
+	}
+	if !d.inOps {
+		if err := d.start(); err != nil {
+			return Operation{}, err
+		}
+	}
+	if d.inOps && d.dec.More() {
+		var op Operation
+		if err := d.dec.Decode(&op); err != nil {
+			return Operation{}, err
+		}
+		return op, nil
+	}
+	if err := d.finish(); err != nil {
+		return Operation{}, err
+	}
+	return Operation{}, io.EOF
+}
+
+// start reads the opening brace and every field up to the operations array.
+func (d *Decoder) start() error {
+	tok, err := d.dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != json.Delim('{') {
+		return ErrNotWorkload
+	}
+	return d.readFields()
+}
+
+// finish reads past the operations array and any fields after it.
+func (d *Decoder) finish() error {
+	d.done = true
+	if d.inOps {
+		if _, err := d.dec.Token(); err != nil {
+			return err
+		}
+		d.inOps = false
+		if err := d.readFields(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readFields collects top-level fields until it enters the operations array
+// or reaches the closing brace, then refreshes the header.
+func (d *Decoder) readFields() error {
+	for d.dec.More() {
+		tok, err := d.dec.Token()
+		if err != nil {
+			return err
+		}
+		name, ok := tok.(string)
+		if !ok {
+			return fmt.Errorf("workload: unexpected %v", tok)
+		}
+		if name == "operations" {
+			if tok, err = d.dec.Token(); err != nil {
+				return err
+			}
+			if tok != json.Delim('[') {
+				return fmt.Errorf("workload: operations is %v, want an array", tok)
+			}
+			d.inOps = true
+			return d.decodeHeader()
+		}
+		var raw json.RawMessage
+		if err := d.dec.Decode(&raw); err != nil {
+			return err
+		}
+		d.fields[name] = raw
+	}
+	if _, err := d.dec.Token(); err != nil {
+		return err
+	}
+	return d.decodeHeader()
+}
+
+func (d *Decoder) decodeHeader() error {
+	data, err := json.Marshal(d.fields)
+	if err != nil {
+		return err
+	}
+	d.header = Workload{}
+	return json.Unmarshal(data, &d.header)
+}
+
+// Stream decodes the workload read from r on its own goroutine and calls f
+// on the calling goroutine with successive batches of its operations, so
+// decoding overlaps with whatever f does and at most a few batches are held
+// at once. f must not keep the slice it is given. Stream stops at the first
+// error from decoding, from f or from ctx, and returns the workload's
+// top-level fields otherwise. After an early return the decoding goroutine
+// may finish reading one operation from r before it exits.
+func Stream(ctx context.Context, r io.Reader, f func(ops []Operation) error) (*Workload, error) {
+	type batch struct {
+		ops []Operation
+		err error
+	}
+	full := make(chan batch, streamInFlight)
+	free := make(chan []Operation, streamInFlight+1)
+	stop := make(chan struct{})
+	dec := NewDecoder(r)
+
+	go func() {
+		defer close(full)
+		ops := make([]Operation, 0, streamBatch)
+		send := func(b batch) bool {
+			select {
+			case full <- b:
+				return true
+			case <-stop:
+				return false
+			}
+		}
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			op, err := dec.Next()
+			if err == io.EOF {
+				if len(ops) > 0 {
+					send(batch{ops: ops})
+				}
+				return
+			}
+			if err != nil {
+				send(batch{err: err})
+				return
+			}
+			ops = append(ops, op)
+			if len(ops) < streamBatch {
+				continue
+			}
+			if !send(batch{ops: ops}) {
+				return
+			}
+			select {
+			case ops = <-free:
+			default:
+				ops = make([]Operation, 0, streamBatch)
+			}
+		}
+	}()
+	defer close(stop)
+
+	for b := range full {
+		if b.err != nil {
+			return nil, b.err
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := f(b.ops); err != nil {
+			return nil, err
+		}
+		clear(b.ops)
+		select {
+		case free <- b.ops[:0]:
+		default:
+		}
+	}
+	return dec.Header(), nil
+}
+
+// ReplayStream applies the operations of the workload read from r to m as
+// they are decoded, returning how many it applied. Unlike Load followed by
+// Replay, memory use does not grow with the workload's length.
+func ReplayStream(ctx context.Context, m kv.Map, r io.Reader) (int, error) {
+	n := 0
+	_, err := Stream(ctx, r, func(ops []Operation) error {
+		for _, op := range ops {
+			if _, err := Apply(m, op); err != nil {
+				return fmt.Errorf("operation %d: %w", n, err)
+			}
+			n++
+		}
+		return nil
+	})
+	return n, err
+}
@@ -0,0 +1,165 @@
+package workload
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// Schema describes which workload files are valid. Workload files carry no
+// version number, so a file is checked against the schema its consumer
+// expects. Version 1 is the original map schema: insert, get and delete, with
+// an expectation of found and value. Version 2 adds scan operations and the
+// count in their expectations; every version 1 file is also valid version 2.
+type Schema struct {
+	Version int
+	// ExtraOps are accepted besides the map operations, for domain
+	// workloads such as the leaderboard and spatial ones. Only their names
+	// are checked.
+	ExtraOps []string
+}
+
+// Schema versions understood by Validate.
+var (
+	SchemaV1 = Schema{Version: 1}
+	SchemaV2 = Schema{Version: 2}
+)
+
+// weightTolerance is how far declared operation weights may sum from 1; the
+// generator rounds each weight to two places.
+const weightTolerance = 0.011
+
+// Problem is one way a workload breaks its schema. Index is the offending
+// operation, or -1 for a top-level field.
+type Problem struct {
+	Index int
+	Msg   string
+}
+
+func (p Problem) String() string {
+	if p.Index < 0 {
+		return p.Msg
+	}
+	return fmt.Sprintf("operation %d: %s", p.Index, p.Msg)
+}
+
+func (s Schema) mapOp(op string) bool {
+	switch op {
+	case OpInsert, OpGet, OpDelete:
+		return true
+	case OpScan:
+		return s.Version >= 2
+	}
+	return false
+}
+
+func (s Schema) knownOp(op string) bool {
+	return s.mapOp(op) || slices.Contains(s.ExtraOps, op)
+}
+
+// Validate returns every problem found in w, in file order, or nil if w
+// conforms to s. Replaying a workload skips nothing that Validate accepts.
+func (s Schema) Validate(w *Workload) []Problem {
+	var problems []Problem
+	top := func(format string, args ...any) {
+		problems = append(problems, Problem{Index: -1, Msg: fmt.Sprintf(format, args...)})
+	}
+	if s.Version != 1 && s.Version != 2 {
+		top("unknown schema version %d", s.Version)
+		return problems
+	}
+
+	if w.Name == "" {
+		top("name is empty")
+	}
+	if w.Size < 0 {
+		top("size %d is negative", w.Size)
+	}
+	if len(w.OperationWeights) > 0 {
+		sum := 0.0
+		for _, op := range sortedKeys(w.OperationWeights) {
+			weight := w.OperationWeights[op]
+			if !s.knownOp(op) {
+				top("operation_weights names unknown op %q", op)
+			}
+			if weight < 0 || weight > 1 {
+				top("operation_weights[%q] = %g is outside [0, 1]", op, weight)
+			}
+			sum += weight
+		}
+		if math.Abs(sum-1) > weightTolerance {
+			top("operation_weights sum to %.3g, want 1", sum)
+		}
+	}
+	if len(w.Operations) == 0 {
+		top("operations is empty")
+	}
+
+	withExpect := 0
+	for i, op := range w.Operations {
+		add := func(format string, args ...any) {
+			problems = append(problems, Problem{Index: i, Msg: fmt.Sprintf(format, args...)})
+		}
+		if !s.knownOp(op.Op) {
+			add("unknown op %q", op.Op)
+			continue
+		}
+		if !s.mapOp(op.Op) {
+			continue
+		}
+		if op.Key == "" {
+			add("key is empty")
+		}
+		switch op.Op {
+		case OpGet, OpDelete:
+			if op.Value != "" {
+				add("%s has a value", op.Op)
+			}
+		case OpScan:
+			if op.Value < op.Key {
+				add("scan range [%q, %q) is reversed", op.Key, op.Value)
+			}
+		}
+		e := op.Expect
+		if e == nil {
+			continue
+		}
+		withExpect++
+		if !e.Found && e.Value != "" {
+			add("expect has a value but found is false")
+		}
+		if op.Op == OpScan {
+			if e.Value != "" {
+				add("scan expect has a value")
+			}
+			if e.Count < 0 || e.Found != (e.Count > 0) {
+				add("scan expect has found %v with count %d", e.Found, e.Count)
+			}
+		} else if e.Count != 0 {
+			add("%s expect has a count", op.Op)
+		}
+	}
+	if withExpect > 0 && withExpect < countMapOps(s, w.Operations) {
+		top("expect is on %d of %d map operations, want all or none", withExpect, countMapOps(s, w.Operations))
+	}
+	return problems
+}
+
+func countMapOps(s Schema, ops []Operation) int {
+	n := 0
+	for _, op := range ops {
+		if s.mapOp(op.Op) {
+			n++
+		}
+	}
+	return n
+}
+
+func sortedKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,125 @@
+package workload
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dsa-lab/go/internal/kv"
+)
+
+// Apply runs op against m and returns its result.
+func Apply(m kv.Map, op Operation) (Result, error) {
+	var r Result
+	switch op.Op {
+	case OpInsert:
+		r.Value, r.Found = m.Insert(op.Key, op.Value)
+	case OpGet:
+		r.Value, r.Found = m.Get(op.Key)
+	case OpDelete:
+		r.Value, r.Found = m.Remove(op.Key)
+	case OpScan:
+		s, ok := m.(kv.Scanner)
+		if !ok {
+			return Result{}, fmt.Errorf("op %q needs an ordered map, got %T", op.Op, m)
+		}
+		// Count into a local so the closure does not move r to the heap on
+		// every call.
+		count := 0
+		s.Scan(op.Key, op.Value, func(_, _ string) bool {
+			count++
+			return true
+		})
+		r.Count, r.Found = count, count > 0
+	default:
+		return Result{}, fmt.Errorf("unknown op %q", op.Op)
+	}
+	if !r.Found {
+		r.Value = ""
+	}
+	return r, nil
+}
+
+// checkEvery is how many operations Replay and VerifyContext apply between
+// cancellation checks; checking on every operation would show up in replay
+// timings.
+const checkEvery = 256
+
+// Replay applies ops to m in order and returns how many it applied. It stops
+// with ctx's error once ctx is done, leaving m holding the effects of the
+// operations applied so far.
+func Replay(ctx context.Context, m kv.Map, ops []Operation) (int, error) {
+	for i, op := range ops {
+		if i%checkEvery == 0 {
+			if err := ctx.Err(); err != nil {
+				return i, err
+			}
+		}
+		if _, err := Apply(m, op); err != nil {
+			return i, fmt.Errorf("operation %d: %w", i, err)
+		}
+	}
+	return len(ops), nil
+}
+
+// Annotate sets the expected result of every operation in w by replaying it
+// against a fresh builtin map.
+func Annotate(w *Workload) error {
+	m := kv.NewBuiltin()
+	for i := range w.Operations {
+		r, err := Apply(m, w.Operations[i])
+		if err != nil {
+			return fmt.Errorf("operation %d: %w", i, err)
+		}
+		w.Operations[i].Expect = &r
+	}
+	return nil
+}
+
+// MismatchError reports the first operation whose result differed from the
+// workload's expectation.
+type MismatchError struct {
+	Index int
+	Op    Operation
+	Want  Result
+	Got   Result
+}
+
+func (e *MismatchError) Error() string {
+	return fmt.Sprintf("operation %d (%s %q): expected %s, got %s", e.Index, e.Op.Op, e.Op.Key, describe(e.Want), describe(e.Got))
+}
+
+func describe(r Result) string {
+	if r.Count > 0 {
+		return fmt.Sprintf("%d keys", r.Count)
+	}
+	if !r.Found {
+		return "not found"
+	}
+	return fmt.Sprintf("found %q", r.Value)
+}
+
+// Verify replays w against m, checking every operation that carries an
+// expectation. It returns a *MismatchError for the first divergence.
+func Verify(m kv.Map, w *Workload) error {
+	return VerifyContext(context.Background(), m, w)
+}
+
+// VerifyContext is Verify for long workloads, returning ctx's error if ctx
+// is done before every operation has been checked.
+func VerifyContext(ctx context.Context, m kv.Map, w *Workload) error {
+	for i, op := range w.Operations {
+		if i%checkEvery == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		got, err := Apply(m, op)
+		if err != nil {
+			return fmt.Errorf("operation %d: %w", i, err)
+		}
+		if op.Expect != nil && got != *op.Expect {
+			return &MismatchError{Index: i, Op: op, Want: *op.Expect, Got: got}
+		}
+	}
+	return nil
+}
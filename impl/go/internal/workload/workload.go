@@ -0,0 +1,100 @@
+// Package workload defines the JSON workload schema shared with the generator
+// in tools/gen_workloads.py, along with helpers to load, write and record
+// workloads from Go.
+package workload
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+)
+
+// Operation names used in workload files.
+const (
+	OpInsert = "insert"
+	OpGet    = "get"
+	OpDelete = "delete"
+	// OpScan visits the keys in the half-open range [Key, Value) of an
+	// ordered map. Its expected result records how many keys it visits.
+	OpScan = "scan"
+)
+
+// Operation is a single map operation in a workload.
+type Operation struct {
+	Op    string `json:"op"`
+	Key   string `json:"key"`
+	Value string `json:"value,omitempty"`
+	// Expect, when present, is the result any conforming implementation must
+	// return for this operation.
+	Expect *Result `json:"expect,omitempty"`
+}
+
+// Result is the outcome of an operation as defined by docs/CONTRACT.md:
+// whether the key was present, and if so the previous value (insert), the
+// current value (get) or the removed value (delete). For a scan, Count is the
+// number of keys in range and Found reports whether there were any.
+type Result struct {
+	Found bool   `json:"found"`
+	Value string `json:"value,omitempty"`
+	Count int    `json:"count,omitempty"`
+}
+
+// Workload is a named, reproducible sequence of operations.
+type Workload struct {
+	Name             string             `json:"name"`
+	Description      string             `json:"description,omitempty"`
+	Size             int                `json:"size"`
+	Distribution     string             `json:"distribution,omitempty"`
+	OperationWeights map[string]float64 `json:"operation_weights,omitempty"`
+	Seed             int64              `json:"seed,omitempty"`
+	Operations       []Operation        `json:"operations"`
+}
+
+// Load reads a workload from a JSON file.
+func Load(path string) (*Workload, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var w Workload
+	if err := json.Unmarshal(data, &w); err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// Write encodes the workload as indented JSON, matching the generator's layout.
+func Write(out io.Writer, w *Workload) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(w)
+}
+
+// Save writes the workload to a JSON file.
+func Save(path string, w *Workload) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := Write(f, w); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Weights computes the fraction of each operation type in ops.
+func Weights(ops []Operation) map[string]float64 {
+	weights := map[string]float64{OpInsert: 0, OpGet: 0, OpDelete: 0}
+	if len(ops) == 0 {
+		return weights
+	}
+	for _, op := range ops {
+		weights[op.Op]++
+	}
+	for op := range weights {
+		weights[op] /= float64(len(ops))
+	}
+	return weights
+}
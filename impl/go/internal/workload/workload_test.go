@@ -0,0 +1,454 @@
+package workload
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dsa-lab/go/internal/kv"
+)
+
+func TestRecorder(t *testing.T) {
+	r := NewRecorder(kv.NewBuiltin())
+	r.Insert("a", "1")
+	r.Get("a")
+	r.Get("b")
+	r.Remove("a")
+
+	if r.Len() != 0 {
+		t.Errorf("expected forwarded operations to leave map empty, got %d", r.Len())
+	}
+
+	w := r.Workload("trace", "test trace")
+	if w.Size != 4 || len(w.Operations) != 4 {
+		t.Fatalf("expected 4 operations, got %d", len(w.Operations))
+	}
+	if op := w.Operations[0]; op.Op != OpInsert || op.Key != "a" || op.Value != "1" || *op.Expect != (Result{}) {
+		t.Errorf("unexpected first operation %+v", op)
+	}
+	if got := *w.Operations[1].Expect; got != (Result{Found: true, Value: "1"}) {
+		t.Errorf("expected recorded get to find 1, got %+v", got)
+	}
+	if w.OperationWeights[OpGet] != 0.5 {
+		t.Errorf("expected get weight 0.5, got %v", w.OperationWeights[OpGet])
+	}
+}
+
+func TestWriteRoundTrip(t *testing.T) {
+	w := &Workload{
+		Name:       "roundtrip",
+		Size:       2,
+		Operations: []Operation{{Op: OpInsert, Key: "k", Value: "v"}, {Op: OpGet, Key: "k"}},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, w); err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded Workload
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Name != w.Name || len(decoded.Operations) != 2 || decoded.Operations[0].Key != w.Operations[0].Key {
+		t.Errorf("round trip mismatch: %+v", decoded)
+	}
+}
+
+// wrongValue returns a stale value for one key, to check Verify catches it.
+type wrongValue struct {
+	kv.Map
+}
+
+func (w wrongValue) Get(key string) (string, bool) {
+	if key == "b" {
+		return "stale", true
+	}
+	return w.Map.Get(key)
+}
+
+func TestAnnotateAndVerify(t *testing.T) {
+	w := &Workload{Operations: []Operation{
+		{Op: OpInsert, Key: "a", Value: "1"},
+		{Op: OpInsert, Key: "a", Value: "2"},
+		{Op: OpGet, Key: "a"},
+		{Op: OpGet, Key: "b"},
+		{Op: OpDelete, Key: "a"},
+		{Op: OpDelete, Key: "a"},
+	}}
+	if err := Annotate(w); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Result{{}, {Found: true, Value: "1"}, {Found: true, Value: "2"}, {}, {Found: true, Value: "2"}, {}}
+	for i, op := range w.Operations {
+		if *op.Expect != want[i] {
+			t.Errorf("op %d: expected %+v, got %+v", i, want[i], *op.Expect)
+		}
+	}
+
+	for _, impl := range kv.Implementations() {
+		if err := Verify(impl.New(), w); err != nil {
+			t.Errorf("%s: %v", impl.Name, err)
+		}
+	}
+
+	err := Verify(wrongValue{kv.NewBuiltin()}, w)
+	mismatch, ok := err.(*MismatchError)
+	if !ok || mismatch.Index != 3 {
+		t.Fatalf("expected mismatch at operation 3, got %v", err)
+	}
+	if got := err.Error(); got != `operation 3 (get "b"): expected not found, got found "stale"` {
+		t.Errorf("unexpected message %q", got)
+	}
+
+	w.Operations = append(w.Operations, Operation{Op: "upsert"})
+	if err := Annotate(w); err == nil {
+		t.Error("expected error for unknown op")
+	}
+}
+
+// cancelOnInsert cancels a context once n inserts have reached the map.
+type cancelOnInsert struct {
+	kv.Map
+	n      int
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnInsert) Insert(key, value string) (string, bool) {
+	if c.n--; c.n == 0 {
+		c.cancel()
+	}
+	return c.Map.Insert(key, value)
+}
+
+func TestReplayCancel(t *testing.T) {
+	ops := make([]Operation, 10*checkEvery)
+	for i := range ops {
+		ops[i] = Operation{Op: OpInsert, Key: fmt.Sprint(i), Value: "v"}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &cancelOnInsert{Map: kv.NewBuiltin(), n: 3 * checkEvery / 2, cancel: cancel}
+	n, err := Replay(ctx, m, ops)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if n != 2*checkEvery || m.Len() != n {
+		t.Errorf("expected replay to stop at the next check after %d ops, applied %d with %d keys", 3*checkEvery/2, n, m.Len())
+	}
+
+	if n, err := Replay(context.Background(), kv.NewBuiltin(), ops); err != nil || n != len(ops) {
+		t.Errorf("expected all %d ops applied, got %d, %v", len(ops), n, err)
+	}
+
+	w := &Workload{Operations: ops}
+	if err := Annotate(w); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), -time.Second)
+	defer cancel()
+	if err := VerifyContext(ctx, kv.NewBuiltin(), w); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestScan(t *testing.T) {
+	w := &Workload{Operations: []Operation{
+		{Op: OpInsert, Key: "b", Value: "1"},
+		{Op: OpInsert, Key: "c", Value: "2"},
+		{Op: OpInsert, Key: "e", Value: "3"},
+		{Op: OpScan, Key: "a", Value: "d"},
+		{Op: OpScan, Key: "c", Value: "e"},
+		{Op: OpScan, Key: "f", Value: "z"},
+	}}
+	if err := Annotate(w); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Result{{Found: true, Count: 2}, {Found: true, Count: 1}, {}}
+	for i, op := range w.Operations[3:] {
+		if *op.Expect != want[i] {
+			t.Errorf("scan %d: expected %+v, got %+v", i, want[i], *op.Expect)
+		}
+	}
+
+	for _, impl := range kv.Implementations() {
+		m := impl.New()
+		if _, ok := m.(kv.Scanner); !ok {
+			if _, err := Apply(m, w.Operations[3]); err == nil {
+				t.Errorf("%s: expected scan on an unordered map to fail", impl.Name)
+			}
+			continue
+		}
+		if err := Verify(m, w); err != nil {
+			t.Errorf("%s: %v", impl.Name, err)
+		}
+	}
+}
+
+func TestIngestCSV(t *testing.T) {
+	const log = `method,path,status
+GET,/a,200
+PUT,/a,201
+GET,/a,200
+DELETE,/a,204
+GET,/b,404
+`
+	in := Ingest{Header: true, OpColumn: -1, ValueColumn: -1}
+	if err := in.ParseColumns("op=0,key=1,value=2"); err != nil {
+		t.Fatal(err)
+	}
+	w, err := in.Read(strings.NewReader(log), "access")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantOps := []string{OpGet, OpInsert, OpGet, OpDelete, OpGet}
+	if len(w.Operations) != len(wantOps) {
+		t.Fatalf("expected %d operations, got %d", len(wantOps), len(w.Operations))
+	}
+	for i, op := range w.Operations {
+		if op.Op != wantOps[i] {
+			t.Errorf("op %d: expected %s, got %s", i, wantOps[i], op.Op)
+		}
+	}
+	if got := *w.Operations[2].Expect; got != (Result{Found: true, Value: "201"}) {
+		t.Errorf("expected get after put to find 201, got %+v", got)
+	}
+
+	if _, err := in.Read(strings.NewReader("h\nPATCH,/a,1\n"), "bad"); err == nil {
+		t.Error("expected error for unknown op")
+	}
+	if err := in.ParseColumns("path=1"); err == nil {
+		t.Error("expected error for unknown column name")
+	}
+}
+
+func TestIngestLines(t *testing.T) {
+	in := DefaultIngest("words.txt")
+	w, err := in.Read(strings.NewReader("the\ncat\n\nthe\nhat\nthe\n"), "words")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantOps := []string{OpInsert, OpInsert, OpGet, OpInsert, OpGet}
+	for i, op := range w.Operations {
+		if op.Op != wantOps[i] {
+			t.Errorf("op %d: expected %s, got %s", i, wantOps[i], op.Op)
+		}
+	}
+	if w.Size != 5 || w.OperationWeights[OpGet] != 0.4 {
+		t.Errorf("expected 5 operations with get weight 0.4, got %d and %v", w.Size, w.OperationWeights[OpGet])
+	}
+
+	in.Limit = 2
+	if w, _ := in.Read(strings.NewReader("a\nb\nc\n"), "words"); len(w.Operations) != 2 {
+		t.Errorf("expected limit to stop at 2 operations, got %d", len(w.Operations))
+	}
+	if in := DefaultIngest("data.tsv"); in.Lines || in.Comma != '\t' {
+		t.Errorf("expected tab-separated CSV for .tsv, got %+v", in)
+	}
+}
+
+func TestDecoder(t *testing.T) {
+	w := &Workload{Name: "stream", Size: 3, Seed: 7, Operations: []Operation{
+		{Op: OpInsert, Key: "a", Value: "1"},
+		{Op: OpGet, Key: "a"},
+		{Op: OpDelete, Key: "b"},
+	}}
+	if err := Annotate(w); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, w); err != nil {
+		t.Fatal(err)
+	}
+
+	dec := NewDecoder(&buf)
+	var ops []Operation
+	for {
+		op, err := dec.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ops) == 0 && dec.Header().Name != "stream" {
+			t.Errorf("expected the header before the first operation, got %+v", dec.Header())
+		}
+		ops = append(ops, op)
+	}
+	if len(ops) != len(w.Operations) {
+		t.Fatalf("expected %d operations, got %d", len(w.Operations), len(ops))
+	}
+	for i, op := range ops {
+		want := w.Operations[i]
+		if op.Op != want.Op || op.Key != want.Key || op.Value != want.Value || *op.Expect != *want.Expect {
+			t.Errorf("op %d: expected %+v, got %+v", i, want, op)
+		}
+	}
+
+	// Fields after the operations array reach the header at the end.
+	dec = NewDecoder(strings.NewReader(`{"name": "late", "operations": [{"op": "get", "key": "k"}], "seed": 9}`))
+	for {
+		if _, err := dec.Next(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if h := dec.Header(); h.Name != "late" || h.Seed != 9 || h.Operations != nil {
+		t.Errorf("expected header with trailing seed, got %+v", h)
+	}
+
+	for _, bad := range []string{`[]`, `{"operations": 3}`, `{"operations": [{"op": 1}]}`, `{"name": "x", "operations": [`} {
+		dec := NewDecoder(strings.NewReader(bad))
+		var err error
+		for err == nil {
+			_, err = dec.Next()
+		}
+		if err == io.EOF {
+			t.Errorf("%s: expected a decode error", bad)
+		}
+	}
+}
+
+func TestStream(t *testing.T) {
+	ops := make([]Operation, 5*streamBatch/2)
+	for i := range ops {
+		ops[i] = Operation{Op: OpInsert, Key: fmt.Sprint(i % 1000), Value: fmt.Sprint(i)}
+	}
+	w := &Workload{Name: "big", Operations: ops}
+	var buf bytes.Buffer
+	if err := Write(&buf, w); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	m := kv.NewBuiltin()
+	n, err := ReplayStream(context.Background(), m, bytes.NewReader(data))
+	if err != nil || n != len(ops) || m.Len() != 1000 {
+		t.Fatalf("expected %d ops leaving 1000 keys, got %d ops, %d keys, %v", len(ops), n, m.Len(), err)
+	}
+	last := ops[len(ops)-1]
+	if v, _ := m.Get(last.Key); v != last.Value {
+		t.Errorf("expected the last insert to win, got %q", v)
+	}
+
+	// The first batch arrives while the writer still holds back the rest
+	// of the file, so replay overlaps with reading and decoding.
+	pr, pw := io.Pipe()
+	firstBatch := make(chan struct{})
+	go func() {
+		split := bytes.Index(data, []byte(fmt.Sprintf(`"value": "%d"`, streamBatch+10)))
+		split += bytes.IndexByte(data[split:], '}') + 1
+		pw.Write(data[:split])
+		<-firstBatch
+		pw.Write(data[split:])
+		pw.Close()
+	}()
+	batches := 0
+	h, err := Stream(context.Background(), pr, func(ops []Operation) error {
+		if batches == 0 {
+			close(firstBatch)
+		}
+		batches++
+		return nil
+	})
+	if err != nil || h.Name != "big" || batches != 3 {
+		t.Errorf("expected 3 batches of workload big, got %d of %+v, %v", batches, h, err)
+	}
+
+	stopErr := errors.New("stop")
+	calls := 0
+	if _, err := Stream(context.Background(), bytes.NewReader(data), func([]Operation) error {
+		calls++
+		return stopErr
+	}); err != stopErr || calls != 1 {
+		t.Errorf("expected f's error after one call, got %v after %d", err, calls)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ReplayStream(ctx, kv.NewBuiltin(), bytes.NewReader(data)); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if _, err := ReplayStream(context.Background(), kv.NewBuiltin(), strings.NewReader(`{"operations": [{"op": "upsert"}]}`)); err == nil {
+		t.Error("expected error for unknown op")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	found := &Result{Found: true, Value: "1"}
+	valid := &Workload{
+		Name:             "ok",
+		OperationWeights: map[string]float64{OpInsert: 0.5, OpGet: 0.5},
+		Operations: []Operation{
+			{Op: OpInsert, Key: "a", Value: "1", Expect: &Result{}},
+			{Op: OpGet, Key: "a", Expect: found},
+		},
+	}
+	if problems := SchemaV1.Validate(valid); problems != nil {
+		t.Errorf("expected no problems, got %v", problems)
+	}
+
+	scan := &Workload{Name: "scan", Operations: []Operation{
+		{Op: OpInsert, Key: "b", Value: "1"},
+		{Op: OpScan, Key: "a", Value: "c"},
+	}}
+	if problems := SchemaV2.Validate(scan); problems != nil {
+		t.Errorf("expected scan to be valid in version 2, got %v", problems)
+	}
+	if problems := SchemaV1.Validate(scan); len(problems) != 1 || problems[0].Index != 1 {
+		t.Errorf("expected scan to be rejected in version 1, got %v", problems)
+	}
+
+	domain := &Workload{Name: "board", Operations: []Operation{{Op: "top", Value: "10"}}}
+	if problems := (Schema{Version: 2, ExtraOps: []string{"top"}}).Validate(domain); problems != nil {
+		t.Errorf("expected extra op to be accepted, got %v", problems)
+	}
+
+	bad := &Workload{
+		Size:             -1,
+		OperationWeights: map[string]float64{OpInsert: 0.5, OpGet: 0.2, "upsert": 0.1},
+		Operations: []Operation{
+			{Op: "upsert", Key: "a"},
+			{Op: OpInsert, Value: "1", Expect: &Result{}},
+			{Op: OpGet, Key: "a", Value: "x", Expect: &Result{Value: "1"}},
+			{Op: OpScan, Key: "b", Value: "a", Expect: &Result{Found: true}},
+			{Op: OpDelete, Key: "a"},
+		},
+	}
+	want := []string{
+		"name is empty",
+		"size -1 is negative",
+		`operation_weights names unknown op "upsert"`,
+		"operation_weights sum to 0.8, want 1",
+		`operation 0: unknown op "upsert"`,
+		"operation 1: key is empty",
+		"operation 2: get has a value",
+		"operation 2: expect has a value but found is false",
+		`operation 3: scan range ["b", "a") is reversed`,
+		"operation 3: scan expect has found true with count 0",
+		"expect is on 3 of 4 map operations, want all or none",
+	}
+	problems := SchemaV2.Validate(bad)
+	if len(problems) != len(want) {
+		t.Fatalf("expected %d problems, got %d: %v", len(want), len(problems), problems)
+	}
+	for i, p := range problems {
+		if p.String() != want[i] {
+			t.Errorf("problem %d: expected %q, got %q", i, want[i], p)
+		}
+	}
+
+	if problems := (Schema{Version: 3}).Validate(valid); len(problems) != 1 {
+		t.Errorf("expected unknown version to be reported, got %v", problems)
+	}
+}
@@ -0,0 +1,57 @@
+// Package bloom is the public API of the lab's Bloom filter.
+package bloom
+
+import (
+	"github.com/dsa-lab/go/internal/bloom"
+	"github.com/dsa-lab/go/internal/errs"
+)
+
+// ErrInvalidOption is matched, via errors.Is, by the error New returns for
+// arguments out of range.
+var ErrInvalidOption = errs.ErrInvalidOption
+
+// Filter is a set of strings with no false negatives and a tunable
+// false-positive rate. Create one with New or decode one into a zero Filter
+// with UnmarshalBinary. A Filter is not safe for concurrent use.
+type Filter struct {
+	f *bloom.Filter
+}
+
+// New creates a filter sized for n keys at false-positive rate p. It returns
+// an error matching ErrInvalidOption unless n >= 1 and 0 < p < 1.
+func New(n int, p float64) (*Filter, error) {
+	f, err := bloom.New(n, p)
+	if err != nil {
+		return nil, err
+	}
+	return &Filter{f: f}, nil
+}
+
+// MustNew is like New but panics if n or p is invalid.
+func MustNew(n int, p float64) *Filter {
+	return errs.Must(New(n, p))
+}
+
+// Add inserts key into the filter.
+func (f *Filter) Add(key string) {
+	f.f.Add(key)
+}
+
+// MayContain reports whether key may have been added. A false result is
+// definite; a true result is wrong with probability about p.
+func (f *Filter) MayContain(key string) bool {
+	return f.f.MayContain(key)
+}
+
+// MarshalBinary encodes the filter for storage or transfer.
+func (f *Filter) MarshalBinary() ([]byte, error) {
+	return f.f.MarshalBinary()
+}
+
+// UnmarshalBinary replaces the filter with one decoded from data.
+func (f *Filter) UnmarshalBinary(data []byte) error {
+	if f.f == nil {
+		f.f = new(bloom.Filter)
+	}
+	return f.f.UnmarshalBinary(data)
+}
@@ -0,0 +1,27 @@
+package bloom
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestFilterRoundTrip(t *testing.T) {
+	f := MustNew(1000, 0.01)
+	for i := 0; i < 1000; i++ {
+		f.Add(strconv.Itoa(i))
+	}
+
+	data, err := f.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary: %v", err)
+	}
+	var g Filter
+	if err := g.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary: %v", err)
+	}
+	for i := 0; i < 1000; i++ {
+		if !g.MayContain(strconv.Itoa(i)) {
+			t.Fatalf("expected key %d to be present after round trip", i)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+// Package hashmap is the public API of the lab's open-addressing hash map.
+//
+// Map wraps the implementation in internal/hashmap and exposes only the
+// operations meant for use outside the repository. Instrumentation such as
+// counters, observers and table dumps stays internal, where experiments can
+// change it freely.
+package hashmap
+
+import (
+	"github.com/dsa-lab/go/internal/errs"
+	"github.com/dsa-lab/go/internal/hashmap"
+)
+
+// ErrInvalidOption is matched, via errors.Is, by the error New and BulkLoad
+// return when an option is given an argument out of range.
+var ErrInvalidOption = errs.ErrInvalidOption
+
+// Map is a string-to-string hash map using open addressing with linear
+// probing. Insert, Get and Remove run in O(1) average time.
+//
+// A Map is not safe for concurrent use.
+type Map struct {
+	m *hashmap.HashMap
+}
+
+// Pair is a key-value pair returned by Entries and Extract.
+type Pair = hashmap.Pair
+
+// Stats reports the occupancy and probe-length profile of a Map.
+type Stats = hashmap.Stats
+
+type config struct {
+	capacity int
+	sized    bool
+	opts     []hashmap.Option
+}
+
+// Option configures a Map at construction time.
+type Option func(*config)
+
+// WithCapacity presizes the table to exactly n slots, which must be at least
+// 4.
+func WithCapacity(n int) Option {
+	return func(c *config) {
+		c.capacity, c.sized = n, true
+	}
+}
+
+// WithIncrementalResize spreads each table resize over subsequent
+// operations, moving up to step slots at a time, so no single operation pays
+// for rehashing the whole table. The step must be at least 1.
+func WithIncrementalResize(step int) Option {
+	return func(c *config) {
+		c.opts = append(c.opts, hashmap.WithIncrementalResize(step))
+	}
+}
+
+// WithCachedHashes keeps each key's hash next to the table, trading 8 bytes
+// per slot for resizes that never rehash keys.
+func WithCachedHashes() Option {
+	return func(c *config) {
+		c.opts = append(c.opts, hashmap.WithCachedHashes())
+	}
+}
+
+// WithMaxProbe rebuilds the table under a fresh random hash seed whenever an
+// insert lands more than n slots from its home, defending lookups against
+// keys chosen to collide. n must be at least 1.
+func WithMaxProbe(n int) Option {
+	return func(c *config) {
+		c.opts = append(c.opts, hashmap.WithMaxProbe(n))
+	}
+}
+
+// WithInterning stores keys and values through a private interner, so a
+// value inserted many times is kept once. It helps when long strings such as
+// paths or user agents repeat across entries.
+func WithInterning() Option {
+	return func(c *config) {
+		c.opts = append(c.opts, hashmap.WithInterner(nil))
+	}
+}
+
+// WithKeyNormalizer rewrites every key with normalize before it is hashed,
+// compared or stored, so the map can serve case-insensitive or path-style
+// lookups without callers normalizing each key. normalize must be
+// idempotent; FoldCase, path.Clean and textproto.CanonicalMIMEHeaderKey all
+// qualify.
+func WithKeyNormalizer(normalize func(key string) string) Option {
+	return func(c *config) {
+		c.opts = append(c.opts, hashmap.WithKeyNormalizer(normalize))
+	}
+}
+
+// WithProbeQuantiles tracks streaming estimates of the given quantiles of
+// probe length, the number of slots a lookup examines, readable with
+// ProbeQuantile. With no arguments it tracks the median, p90 and p99. Every
+// quantile must lie in (0, 1).
+func WithProbeQuantiles(qs ...float64) Option {
+	return func(c *config) {
+		c.opts = append(c.opts, hashmap.WithProbeQuantiles(qs...))
+	}
+}
+
+// FoldCase returns key under Unicode simple case folding, for use with
+// WithKeyNormalizer.
+func FoldCase(key string) string {
+	return hashmap.FoldCase(key)
+}
+
+// New creates an empty Map. It returns an error matching ErrInvalidOption
+// if an option is given an argument out of range.
+func New(opts ...Option) (*Map, error) {
+	var c config
+	for _, opt := range opts {
+		opt(&c)
+	}
+	var m *hashmap.HashMap
+	var err error
+	if c.sized {
+		m, err = hashmap.NewWithCapacity(c.capacity, c.opts...)
+	} else {
+		m, err = hashmap.New(c.opts...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Map{m: m}, nil
+}
+
+// MustNew is like New but panics if an option is invalid.
+func MustNew(opts ...Option) *Map {
+	return errs.Must(New(opts...))
+}
+
+// BulkLoad creates a Map holding pairs, as if each were inserted in order, so
+// the last of several pairs with one key wins. It allocates the table once,
+// sized for pairs, and on large inputs hashes and places keys on several
+// goroutines, so a key normalizer it is given must be safe for concurrent
+// use. WithCapacity has no effect here. Invalid options are reported as by
+// New.
+func BulkLoad(pairs []Pair, opts ...Option) (*Map, error) {
+	var c config
+	for _, opt := range opts {
+		opt(&c)
+	}
+	m, err := hashmap.BulkLoad(pairs, c.opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Map{m: m}, nil
+}
+
+// MustBulkLoad is like BulkLoad but panics if an option is invalid.
+func MustBulkLoad(pairs []Pair, opts ...Option) *Map {
+	return errs.Must(BulkLoad(pairs, opts...))
+}
+
+// Equal reports whether a and b hold the same key-value pairs.
+func Equal(a, b *Map) bool {
+	return hashmap.Equal(a.m, b.m)
+}
+
+// EqualFunc reports whether a and b hold the same keys with values that eq
+// considers equal.
+func EqualFunc(a, b *Map, eq func(x, y string) bool) bool {
+	return hashmap.EqualFunc(a.m, b.m, eq)
+}
+
+// Diff compares a and b by key, returning the sorted keys present only in a,
+// only in b, and in both with different values.
+func Diff(a, b *Map) (onlyA, onlyB, changed []string) {
+	return hashmap.Diff(a.m, b.m)
+}
+
+// Len returns the number of elements in the map.
+func (m *Map) Len() int {
+	return m.m.Len()
+}
+
+// Insert inserts or updates a key-value pair.
+// Returns the previous value and true if the key existed.
+func (m *Map) Insert(key, value string) (string, bool) {
+	return m.m.Insert(key, value)
+}
+
+// Get returns the value for key and true if it is present.
+func (m *Map) Get(key string) (string, bool) {
+	return m.m.Get(key)
+}
+
+// Remove deletes key from the map.
+// Returns the removed value and true if the key existed.
+func (m *Map) Remove(key string) (string, bool) {
+	return m.m.Remove(key)
+}
+
+// Contains reports whether key is present.
+func (m *Map) Contains(key string) bool {
+	return m.m.Contains(key)
+}
+
+// Clear removes all elements from the map.
+func (m *Map) Clear() {
+	m.m.Clear()
+}
+
+// Range calls f for each key-value pair in unspecified order.
+// If f returns false, iteration stops.
+func (m *Map) Range(f func(key, value string) bool) {
+	m.m.Range(f)
+}
+
+// Entries returns all key-value pairs in unspecified order.
+func (m *Map) Entries() []Pair {
+	return m.m.Entries()
+}
+
+// PopAny removes and returns an arbitrary entry, or false if the map is empty.
+func (m *Map) PopAny() (key, value string, ok bool) {
+	return m.m.PopAny()
+}
+
+// Extract removes every entry for which match returns true and returns the
+// removed pairs. match must not modify the map.
+func (m *Map) Extract(match func(key, value string) bool) []Pair {
+	return m.m.Extract(match)
+}
+
+// Stats scans the table and returns probe and clustering statistics.
+func (m *Map) Stats() Stats {
+	return m.m.Stats()
+}
+
+// Capacity returns the number of slots in the table.
+func (m *Map) Capacity() int {
+	return m.m.Capacity()
+}
+
+// Resizes returns the number of times the table has grown or been rebuilt.
+func (m *Map) Resizes() int {
+	return m.m.Resizes()
+}
+
+// ProbeQuantile returns the estimated q-quantile of probe length and true if
+// q was requested with WithProbeQuantiles. It is NaN before any lookup.
+func (m *Map) ProbeQuantile(q float64) (float64, bool) {
+	return m.m.ProbeQuantile(q)
+}
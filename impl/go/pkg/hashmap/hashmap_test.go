@@ -0,0 +1,166 @@
+package hashmap
+
+import (
+	"errors"
+	"net/textproto"
+	"path"
+	"strconv"
+	"testing"
+)
+
+func TestMap(t *testing.T) {
+	m := MustNew(WithCapacity(4), WithIncrementalResize(8), WithCachedHashes(), WithMaxProbe(32), WithInterning())
+	for i := 0; i < 1000; i++ {
+		m.Insert(strconv.Itoa(i), "v"+strconv.Itoa(i))
+	}
+	if m.Len() != 1000 {
+		t.Fatalf("expected len 1000, got %d", m.Len())
+	}
+
+	if old, ok := m.Insert("7", "seven"); !ok || old != "v7" {
+		t.Errorf("expected (v7, true), got (%q, %v)", old, ok)
+	}
+	if v, ok := m.Get("7"); !ok || v != "seven" {
+		t.Errorf("expected (seven, true), got (%q, %v)", v, ok)
+	}
+	if v, ok := m.Remove("8"); !ok || v != "v8" {
+		t.Errorf("expected (v8, true), got (%q, %v)", v, ok)
+	}
+	if m.Contains("8") {
+		t.Error("expected removed key to be absent")
+	}
+
+	n := 0
+	m.Range(func(key, value string) bool {
+		n++
+		return true
+	})
+	if n != 999 {
+		t.Errorf("expected Range to visit 999 pairs, got %d", n)
+	}
+	if s := m.Stats(); s.Len != 999 {
+		t.Errorf("expected Stats.Len 999, got %d", s.Len)
+	}
+	if m.Capacity() < 999 || m.Resizes() == 0 {
+		t.Errorf("expected a grown table, got capacity %d after %d resizes", m.Capacity(), m.Resizes())
+	}
+	if _, ok := m.ProbeQuantile(0.99); ok {
+		t.Error("expected no probe quantiles without WithProbeQuantiles")
+	}
+
+	if pairs := m.Entries(); len(pairs) != 999 {
+		t.Errorf("expected 999 entries, got %d", len(pairs))
+	}
+	if removed := m.Extract(func(key, _ string) bool { return len(key) == 1 }); len(removed) != 9 {
+		t.Errorf("expected 9 single-digit keys extracted, got %d", len(removed))
+	}
+	if _, _, ok := m.PopAny(); !ok || m.Len() != 989 {
+		t.Errorf("expected PopAny to leave 989 entries, got %d", m.Len())
+	}
+
+	other := MustNew()
+	m.Range(func(key, value string) bool {
+		other.Insert(key, value)
+		return true
+	})
+	if !Equal(m, other) {
+		t.Error("expected copy to be equal")
+	}
+	other.Insert("new", "v")
+	if _, onlyB, _ := Diff(m, other); len(onlyB) != 1 || onlyB[0] != "new" {
+		t.Errorf("expected onlyB [new], got %v", onlyB)
+	}
+
+	m.Clear()
+	if m.Len() != 0 || m.Contains("1") {
+		t.Error("expected empty map after Clear")
+	}
+}
+
+func TestKeyNormalizer(t *testing.T) {
+	headers := MustNew(WithKeyNormalizer(textproto.CanonicalMIMEHeaderKey))
+	headers.Insert("content-type", "text/plain")
+	if v, ok := headers.Get("CONTENT-TYPE"); !ok || v != "text/plain" {
+		t.Errorf("expected (text/plain, true), got (%q, %v)", v, ok)
+	}
+
+	paths := MustNew(WithKeyNormalizer(path.Clean))
+	paths.Insert("/a/b/../c/", "handler")
+	if !paths.Contains("/a/c") || !paths.Contains("/a/./c") {
+		t.Error("expected cleaned paths to match")
+	}
+
+	folded := MustNew(WithKeyNormalizer(FoldCase))
+	folded.Insert("Key", "1")
+	plain := MustNew()
+	plain.Insert("key", "01")
+	if !EqualFunc(plain, folded, func(x, y string) bool {
+		a, _ := strconv.Atoi(x)
+		b, _ := strconv.Atoi(y)
+		return a == b
+	}) {
+		t.Error("expected maps equal under numeric comparison")
+	}
+}
+
+func TestBulkLoad(t *testing.T) {
+	pairs := make([]Pair, 20000)
+	for i := range pairs {
+		pairs[i] = Pair{Key: strconv.Itoa(i % 15000), Value: strconv.Itoa(i)}
+	}
+	m := MustBulkLoad(pairs, WithCachedHashes())
+	if m.Len() != 15000 {
+		t.Fatalf("expected len 15000, got %d", m.Len())
+	}
+	for i := 0; i < 15000; i++ {
+		want := strconv.Itoa(i)
+		if i < 5000 {
+			want = strconv.Itoa(i + 15000)
+		}
+		if v, ok := m.Get(strconv.Itoa(i)); !ok || v != want {
+			t.Fatalf("expected (%s, true), got (%q, %v)", want, v, ok)
+		}
+	}
+}
+
+func TestWithCapacityIsExact(t *testing.T) {
+	for _, n := range []int{4, 5, 16, 100} {
+		if got := MustNew(WithCapacity(n)).Capacity(); got != n {
+			t.Errorf("WithCapacity(%d): expected capacity %d, got %d", n, n, got)
+		}
+	}
+	if got := MustNew().Capacity(); got != 16 {
+		t.Errorf("expected a default capacity of 16, got %d", got)
+	}
+}
+
+func TestInvalidOptions(t *testing.T) {
+	for name, opt := range map[string]Option{
+		"WithCapacity(3)":          WithCapacity(3),
+		"WithCapacity(-1)":         WithCapacity(-1),
+		"WithIncrementalResize(0)": WithIncrementalResize(0),
+		"WithMaxProbe(0)":          WithMaxProbe(0),
+	} {
+		if _, err := New(opt); !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("%s: expected ErrInvalidOption, got %v", name, err)
+		}
+	}
+	if _, err := BulkLoad(nil, WithMaxProbe(-3)); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("expected ErrInvalidOption from BulkLoad, got %v", err)
+	}
+}
+
+func TestProbeQuantiles(t *testing.T) {
+	m := MustNew(WithProbeQuantiles(0.5, 0.99))
+	for i := 0; i < 100; i++ {
+		m.Insert(strconv.Itoa(i), "v")
+	}
+	p50, ok50 := m.ProbeQuantile(0.5)
+	p99, ok99 := m.ProbeQuantile(0.99)
+	if !ok50 || !ok99 || p50 < 1 || p99 < p50 {
+		t.Errorf("expected 1 <= p50 <= p99, got %v (%v) and %v (%v)", p50, ok50, p99, ok99)
+	}
+	if _, err := New(WithProbeQuantiles(1)); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("expected ErrInvalidOption for quantile 1, got %v", err)
+	}
+}
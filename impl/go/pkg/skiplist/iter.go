@@ -0,0 +1,16 @@
+//go:build go1.23
+
+package skiplist
+
+import "iter"
+
+// All returns an iterator over all key-value pairs in ascending key order.
+func (l *List[K, V]) All() iter.Seq2[K, V] {
+	return l.s.All()
+}
+
+// Between returns an iterator over the keys in [lo, hi) in ascending order,
+// the iterator form of Scan.
+func (l *List[K, V]) Between(lo, hi K) iter.Seq2[K, V] {
+	return l.s.Between(lo, hi)
+}
@@ -0,0 +1,108 @@
+// Package skiplist is the public API of the lab's indexable skip list: an
+// ordered map that also answers rank and positional queries in O(log n)
+// expected time.
+package skiplist
+
+import (
+	"cmp"
+
+	"github.com/dsa-lab/go/internal/skiplist"
+)
+
+// List is an ordered map from K to V. It is not safe for concurrent use.
+type List[K, V any] struct {
+	s *skiplist.SkipList[K, V]
+}
+
+// New creates an empty List ordered by the natural ordering of K.
+func New[K cmp.Ordered, V any]() *List[K, V] {
+	return &List[K, V]{s: skiplist.MustNew[K, V]()}
+}
+
+// NewFunc creates an empty List ordered by compare, which must return a
+// negative number, zero or a positive number when a < b, a == b or a > b.
+func NewFunc[K, V any](compare func(a, b K) int) *List[K, V] {
+	return &List[K, V]{s: skiplist.MustNewFunc[K, V](compare)}
+}
+
+// Len returns the number of elements in the list.
+func (l *List[K, V]) Len() int {
+	return l.s.Len()
+}
+
+// Insert inserts or updates a key-value pair.
+// Returns the previous value and true if the key existed.
+func (l *List[K, V]) Insert(key K, value V) (V, bool) {
+	return l.s.Insert(key, value)
+}
+
+// Get returns the value for key and true if it is present.
+func (l *List[K, V]) Get(key K) (V, bool) {
+	return l.s.Get(key)
+}
+
+// Remove deletes key from the list.
+// Returns the removed value and true if the key existed.
+func (l *List[K, V]) Remove(key K) (V, bool) {
+	return l.s.Remove(key)
+}
+
+// Contains reports whether key is present.
+func (l *List[K, V]) Contains(key K) bool {
+	return l.s.Contains(key)
+}
+
+// Rank returns the zero-based position of key in sorted order and true if
+// key is present.
+func (l *List[K, V]) Rank(key K) (int, bool) {
+	return l.s.Rank(key)
+}
+
+// At returns the key and value at zero-based position index, and false if
+// index is out of range.
+func (l *List[K, V]) At(index int) (K, V, bool) {
+	return l.s.At(index)
+}
+
+// Floor returns the greatest key less than or equal to key.
+func (l *List[K, V]) Floor(key K) (K, V, bool) {
+	return l.s.Floor(key)
+}
+
+// Ceiling returns the least key greater than or equal to key.
+func (l *List[K, V]) Ceiling(key K) (K, V, bool) {
+	return l.s.Ceiling(key)
+}
+
+// Lower returns the greatest key strictly less than key.
+func (l *List[K, V]) Lower(key K) (K, V, bool) {
+	return l.s.Lower(key)
+}
+
+// Higher returns the least key strictly greater than key.
+func (l *List[K, V]) Higher(key K) (K, V, bool) {
+	return l.s.Higher(key)
+}
+
+// Scan iterates in ascending order over the keys in [lo, hi).
+// If f returns false, iteration stops.
+func (l *List[K, V]) Scan(lo, hi K, f func(key K, value V) bool) {
+	l.s.Scan(lo, hi, f)
+}
+
+// Clear removes all elements from the list.
+func (l *List[K, V]) Clear() {
+	l.s.Clear()
+}
+
+// Range iterates over all pairs in ascending key order.
+// If f returns false, iteration stops.
+func (l *List[K, V]) Range(f func(key K, value V) bool) {
+	l.s.Range(f)
+}
+
+// RangeFrom iterates in ascending order starting at zero-based position start.
+// If f returns false, iteration stops.
+func (l *List[K, V]) RangeFrom(start int, f func(key K, value V) bool) {
+	l.s.RangeFrom(start, f)
+}
@@ -0,0 +1,53 @@
+package skiplist
+
+import "testing"
+
+func TestList(t *testing.T) {
+	l := New[int, string]()
+	for _, k := range []int{50, 10, 40, 20, 30} {
+		l.Insert(k, "")
+	}
+	if l.Len() != 5 {
+		t.Fatalf("expected len 5, got %d", l.Len())
+	}
+	if r, ok := l.Rank(40); !ok || r != 3 {
+		t.Errorf("expected rank (3, true), got (%d, %v)", r, ok)
+	}
+	if k, _, ok := l.At(1); !ok || k != 20 {
+		t.Errorf("expected key 20 at index 1, got %d", k)
+	}
+	if _, ok := l.Remove(20); !ok || l.Contains(20) {
+		t.Error("expected 20 to be removed")
+	}
+
+	if k, _, ok := l.Floor(35); !ok || k != 30 {
+		t.Errorf("expected floor 30, got %d", k)
+	}
+	if k, _, ok := l.Higher(40); !ok || k != 50 {
+		t.Errorf("expected higher 50, got %d", k)
+	}
+	n := 0
+	l.Scan(30, 50, func(int, string) bool {
+		n++
+		return true
+	})
+	if n != 2 {
+		t.Errorf("expected scan [30, 50) to visit 2 keys, got %d", n)
+	}
+
+	var got []int
+	l.RangeFrom(1, func(k int, _ string) bool {
+		got = append(got, k)
+		return true
+	})
+	if len(got) != 3 || got[0] != 30 || got[2] != 50 {
+		t.Errorf("expected [30 40 50], got %v", got)
+	}
+
+	desc := NewFunc[int, int](func(a, b int) int { return b - a })
+	desc.Insert(1, 1)
+	desc.Insert(2, 2)
+	if k, _, _ := desc.At(0); k != 2 {
+		t.Errorf("expected 2 first in descending order, got %d", k)
+	}
+}
@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/concurrent/lockfree"
+	"github.com/dsa-lab/go/internal/concurrent/sharded"
+	"github.com/dsa-lab/go/internal/kv"
+)
+
+// concurrentMaps are the implementations that are safe for concurrent use.
+// The lock-free map is listed directly so it is exercised without the
+// experimental build tag.
+var concurrentMaps = []kv.Implementation{
+	{Name: "sharded", New: func() kv.Map { return sharded.MustNew() }},
+	{Name: "sharded_1", New: func() kv.Map { return sharded.MustNew(sharded.WithShards(1)) }},
+	{Name: "syncmap", New: func() kv.Map { return kv.NewSyncMap() }},
+	{Name: "lockfree", New: func() kv.Map { return lockfree.New() }},
+}
+
+// TestConcurrentMaps runs writers over disjoint key ranges alongside readers
+// of a shared, fixed key range, then checks the final contents. Run with
+// -race to check the synchronization as well as the results.
+func TestConcurrentMaps(t *testing.T) {
+	const (
+		writers = 8
+		readers = 4
+		shared  = 100
+	)
+	keys := 2000
+	if testing.Short() {
+		keys = 200
+	}
+
+	for _, impl := range concurrentMaps {
+		t.Run(impl.Name, func(t *testing.T) {
+			m := impl.New()
+			for i := 0; i < shared; i++ {
+				m.Insert(fmt.Sprintf("shared_%d", i), "fixed")
+			}
+
+			var wg sync.WaitGroup
+			errs := make(chan error, writers+readers)
+			done := make(chan struct{})
+			for w := 0; w < writers; w++ {
+				wg.Add(1)
+				go func(w int) {
+					defer wg.Done()
+					for i := 0; i < keys; i++ {
+						key := fmt.Sprintf("w%d_%d", w, i)
+						if _, existed := m.Insert(key, "a"); existed {
+							errs <- fmt.Errorf("%s existed before first insert", key)
+							return
+						}
+						if old, existed := m.Insert(key, "b"); !existed || old != "a" {
+							errs <- fmt.Errorf("%s: expected overwrite of a, got (%q, %v)", key, old, existed)
+							return
+						}
+						// Remove every other key.
+						if i%2 == 0 {
+							if old, existed := m.Remove(key); !existed || old != "b" {
+								errs <- fmt.Errorf("%s: expected removal of b, got (%q, %v)", key, old, existed)
+								return
+							}
+						}
+					}
+				}(w)
+			}
+			var readersWG sync.WaitGroup
+			for r := 0; r < readers; r++ {
+				readersWG.Add(1)
+				go func(r int) {
+					defer readersWG.Done()
+					for i := r; ; i++ {
+						select {
+						case <-done:
+							return
+						default:
+						}
+						key := fmt.Sprintf("shared_%d", i%shared)
+						if v, ok := m.Get(key); !ok || v != "fixed" {
+							errs <- fmt.Errorf("%s: expected fixed, got (%q, %v)", key, v, ok)
+							return
+						}
+					}
+				}(r)
+			}
+			wg.Wait()
+			close(done)
+			readersWG.Wait()
+			close(errs)
+			for err := range errs {
+				t.Fatal(err)
+			}
+
+			if want := shared + writers*keys/2; m.Len() != want {
+				t.Errorf("expected %d entries, got %d", want, m.Len())
+			}
+			for w := 0; w < writers; w++ {
+				for i := 0; i < keys; i++ {
+					_, ok := m.Get(fmt.Sprintf("w%d_%d", w, i))
+					if ok != (i%2 == 1) {
+						t.Fatalf("w%d_%d: expected present=%v", w, i, i%2 == 1)
+					}
+				}
+			}
+		})
+	}
+}
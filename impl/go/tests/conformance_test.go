@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/kv"
+	"github.com/dsa-lab/go/internal/workload"
+)
+
+// TestWorkloadConformance replays every map workload against every registered
+// implementation and checks each result against the expectation embedded by
+// the generator.
+func TestWorkloadConformance(t *testing.T) {
+	paths, err := filepath.Glob("../../../workloads/map/*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Skip("no workloads found; run `just gen`")
+	}
+
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), ".json")
+		if name == "manifest" || (testing.Short() && strings.HasSuffix(name, "_large")) {
+			continue
+		}
+		w, err := workload.Load(path)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		t.Run(name, func(t *testing.T) {
+			for i, op := range w.Operations {
+				if op.Expect == nil {
+					t.Fatalf("operation %d has no expected result", i)
+				}
+			}
+			for _, impl := range kv.Implementations() {
+				if err := workload.Verify(impl.New(), w); err != nil {
+					t.Errorf("%s: %v", impl.Name, err)
+				}
+			}
+		})
+	}
+}
+
+// TestRangeWorkloadConformance replays the range-scan workloads against every
+// registered implementation that supports ordered scans.
+func TestRangeWorkloadConformance(t *testing.T) {
+	paths, err := filepath.Glob("../../../workloads/range/*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Skip("no range workloads found; run `just gen`")
+	}
+
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), ".json")
+		w, err := workload.Load(path)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		t.Run(name, func(t *testing.T) {
+			scanners := 0
+			for _, impl := range kv.Implementations() {
+				m := impl.New()
+				if _, ok := m.(kv.Scanner); !ok {
+					continue
+				}
+				scanners++
+				if err := workload.Verify(m, w); err != nil {
+					t.Errorf("%s: %v", impl.Name, err)
+				}
+			}
+			if scanners == 0 {
+				t.Fatal("no registered implementation supports scans")
+			}
+		})
+	}
+}
@@ -0,0 +1,423 @@
+package tests
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/diskhash"
+	"github.com/dsa-lab/go/internal/lsm"
+	"github.com/dsa-lab/go/internal/vfs"
+	"github.com/dsa-lab/go/internal/vfs/faultfs"
+	"github.com/dsa-lab/go/internal/wal"
+)
+
+// The crash tests run a workload against a persistent structure on faultfs,
+// cut the power or shorten a write at a random operation, then reopen what
+// survived and check it against what the workload was promised. Each trial
+// is replayable from its seed, which every failure reports.
+
+func crashTrials() int {
+	if testing.Short() {
+		return 40
+	}
+	return 300
+}
+
+// injectFault arms one fault at a random one of the ops operations a
+// workload performs and describes it.
+func injectFault(fsys *faultfs.FS, rng *rand.Rand, ops int) string {
+	k := rng.Intn(ops)
+	if rng.Intn(4) == 0 {
+		fsys.ShortWriteAt(k)
+		return fmt.Sprintf("short write at operation %d of %d", k+1, ops)
+	}
+	fsys.CrashAfter(k)
+	return fmt.Sprintf("power cut after operation %d of %d", k, ops)
+}
+
+// walOp is one step of a log workload: an append of a record padded by pad
+// bytes, a Sync or a Reset.
+type walOp struct {
+	kind byte // 'a', 's' or 'r'
+	pad  int
+}
+
+// walRun is what a log workload was promised before it stopped. Records are
+// numbered from 1 in append order.
+type walRun struct {
+	next      int  // number of the next record to append
+	base      int  // first record appended after the last successful Reset
+	durable   int  // last record a successful Sync covered
+	resetting bool // the workload stopped in a Reset
+}
+
+func walOptions(fsys *faultfs.FS) []wal.Option {
+	return []wal.Option{wal.WithFS(fsys), wal.WithSegmentSize(4096)}
+}
+
+func runWALWorkload(fsys *faultfs.FS, ops []walOp) (walRun, error) {
+	run := walRun{next: 1, base: 1}
+	l, err := wal.Open("/wal", walOptions(fsys)...)
+	if err != nil {
+		return run, err
+	}
+	for _, op := range ops {
+		switch op.kind {
+		case 'a':
+			err = l.Append([]byte(fmt.Sprintf("%d:%s", run.next, strings.Repeat("x", op.pad))))
+			if err == nil {
+				run.next++
+			}
+		case 's':
+			if err = l.Sync(); err == nil {
+				run.durable = run.next - 1
+			}
+		case 'r':
+			if err = l.Reset(); err == nil {
+				run.base = run.next
+			}
+			run.resetting = err != nil
+		}
+		if err != nil {
+			return run, err
+		}
+	}
+	if err := l.Close(); err != nil {
+		return run, err
+	}
+	run.durable = run.next - 1
+	return run, nil
+}
+
+// TestWALCrashRecovery checks that a log recovered from any crash reads as
+// an unbroken run of records, starting where the last completed Reset left
+// it and reaching at least the last synced record.
+func TestWALCrashRecovery(t *testing.T) {
+	for seed := int64(1); seed <= int64(crashTrials()); seed++ {
+		rng := rand.New(rand.NewSource(seed))
+		ops := make([]walOp, 150)
+		for i := range ops {
+			switch r := rng.Intn(20); {
+			case r == 0:
+				ops[i] = walOp{kind: 'r'}
+			case r < 5:
+				ops[i] = walOp{kind: 's'}
+			default:
+				ops[i] = walOp{kind: 'a', pad: rng.Intn(300)}
+			}
+		}
+
+		dry := faultfs.New(seed)
+		if _, err := runWALWorkload(dry, ops); err != nil {
+			t.Fatalf("seed %d: workload without faults: %v", seed, err)
+		}
+		fsys := faultfs.New(seed)
+		fault := injectFault(fsys, rng, dry.Ops())
+		run, _ := runWALWorkload(fsys, ops)
+
+		after := fsys.Crash()
+		l, err := wal.Open("/wal", walOptions(after)...)
+		if err != nil {
+			t.Fatalf("seed %d, %s: reopening: %v", seed, fault, err)
+		}
+		var seqs []int
+		it := l.Iterator()
+		for it.Next() {
+			prefix, _, _ := strings.Cut(string(it.Record()), ":")
+			seq, err := strconv.Atoi(prefix)
+			if err != nil {
+				t.Fatalf("seed %d, %s: expected numbered records, got %q", seed, fault, it.Record())
+			}
+			seqs = append(seqs, seq)
+		}
+		it.Close()
+		if err := it.Err(); err != nil {
+			t.Fatalf("seed %d, %s: reading: %v", seed, fault, err)
+		}
+
+		for i, seq := range seqs {
+			if i > 0 && seq != seqs[i-1]+1 || seq < run.base || seq > run.next {
+				t.Fatalf("seed %d, %s: expected an unbroken run of records from %d, got %v", seed, fault, run.base, seqs)
+			}
+		}
+		if !run.resetting && len(seqs) > 0 && seqs[0] != run.base {
+			t.Fatalf("seed %d, %s: expected records to start at %d after the last reset, got %d", seed, fault, run.base, seqs[0])
+		}
+		if !run.resetting && run.durable >= run.base && (len(seqs) == 0 || seqs[len(seqs)-1] < run.durable) {
+			t.Fatalf("seed %d, %s: expected records through %d to be durable, got %v", seed, fault, run.durable, seqs)
+		}
+
+		// The recovered log carries on after its last intact record.
+		if err := l.Append([]byte("after-crash")); err != nil {
+			t.Fatalf("seed %d, %s: appending after recovery: %v", seed, fault, err)
+		}
+		var last string
+		it = l.Iterator()
+		for it.Next() {
+			last = string(it.Record())
+		}
+		it.Close()
+		if it.Err() != nil || last != "after-crash" {
+			t.Fatalf("seed %d, %s: expected the new record last, got %q (%v)", seed, fault, last, it.Err())
+		}
+		l.Close()
+
+		// Damage that intact records follow is corruption, not a torn tail,
+		// and must not be truncated away.
+		damaged, err := damageFirstRecord(after, "/wal")
+		if err != nil {
+			t.Fatalf("seed %d, %s: damaging the log: %v", seed, fault, err)
+		}
+		if _, err := wal.Open("/wal", walOptions(after)...); damaged && !errors.Is(err, wal.ErrCorrupt) {
+			t.Fatalf("seed %d, %s: expected ErrCorrupt for a damaged record before intact ones, got %v", seed, fault, err)
+		}
+	}
+}
+
+// damageFirstRecord flips a data byte of the first record in the newest
+// segment under dir, if other records follow it, and reports whether it did.
+func damageFirstRecord(fsys vfs.FS, dir string) (bool, error) {
+	segments, err := vfs.Glob(fsys, dir, "*.wal")
+	if err != nil || len(segments) == 0 {
+		return false, err
+	}
+	f, err := fsys.OpenFile(segments[len(segments)-1], os.O_RDWR, 0)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return false, err
+	}
+	var header [8]byte
+	if _, err := f.ReadAt(header[:], 0); err != nil {
+		return false, err
+	}
+	n := int64(binary.LittleEndian.Uint32(header[4:]))
+	if n == 0 || int64(len(header))+n >= info.Size() {
+		return false, nil
+	}
+	var b [1]byte
+	if _, err := f.ReadAt(b[:], int64(len(header))); err != nil {
+		return false, err
+	}
+	b[0] ^= 0xff
+	_, err = f.WriteAt(b[:], int64(len(header)))
+	return err == nil, err
+}
+
+// kvWrite is one write of a key-value workload; a step of the workload is a
+// batch of them, applied atomically, or a nil batch asking for a flush.
+type kvWrite struct {
+	key, value string
+	del        bool
+}
+
+// crashStore adapts a persistent map to the key-value crash workload.
+type crashStore interface {
+	write(batch []kvWrite) error
+	contents() (map[string]string, error)
+	close() error
+}
+
+type diskHashStore struct{ d *diskhash.DiskHash }
+
+func (s diskHashStore) write(batch []kvWrite) error {
+	for _, w := range batch {
+		var err error
+		if w.del {
+			_, _, err = s.d.Remove(w.key)
+		} else {
+			_, _, err = s.d.Insert(w.key, w.value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s diskHashStore) contents() (map[string]string, error) {
+	m := map[string]string{}
+	err := s.d.Range(func(k, v string) bool {
+		m[k] = v
+		return true
+	})
+	if err == nil && len(m) != s.d.Len() {
+		err = fmt.Errorf("Len %d for %d records", s.d.Len(), len(m))
+	}
+	return m, err
+}
+
+func (s diskHashStore) close() error { return s.d.Close() }
+
+type lsmStore struct{ db *lsm.DB }
+
+func (s lsmStore) write(batch []kvWrite) error {
+	switch {
+	case batch == nil:
+		return s.db.Flush()
+	case len(batch) == 1 && batch[0].del:
+		return s.db.Delete(batch[0].key)
+	case len(batch) == 1:
+		return s.db.Put(batch[0].key, batch[0].value)
+	}
+	txn := s.db.Txn()
+	for _, w := range batch {
+		if w.del {
+			txn.Delete(w.key)
+		} else {
+			txn.Put(w.key, w.value)
+		}
+	}
+	return txn.Commit()
+}
+
+func (s lsmStore) contents() (map[string]string, error) {
+	m := map[string]string{}
+	err := s.db.Range(func(k, v string) bool {
+		m[k] = v
+		return true
+	})
+	return m, err
+}
+
+func (s lsmStore) close() error { return s.db.Close() }
+
+// kvSteps generates n steps over a small key space, with values long enough
+// to overflow pages and memtables. Batches of several writes and flushes
+// appear only when batches is set.
+func kvSteps(rng *rand.Rand, n int, batches bool) [][]kvWrite {
+	one := func(i int) kvWrite {
+		key := fmt.Sprintf("k%02d", rng.Intn(30))
+		if rng.Intn(5) == 0 {
+			return kvWrite{key: key, del: true}
+		}
+		return kvWrite{key: key, value: fmt.Sprintf("%d-%s", i, strings.Repeat("v", rng.Intn(400)))}
+	}
+	steps := make([][]kvWrite, n)
+	for i := range steps {
+		switch r := rng.Intn(20); {
+		case batches && r == 0:
+			steps[i] = nil
+		case batches && r < 3:
+			for j := 2 + rng.Intn(3); j > 0; j-- {
+				steps[i] = append(steps[i], one(i))
+			}
+		default:
+			steps[i] = []kvWrite{one(i)}
+		}
+	}
+	return steps
+}
+
+// runKVWorkload applies steps until one fails and returns how many
+// succeeded.
+func runKVWorkload(fsys *faultfs.FS, open func(*faultfs.FS) (crashStore, error), steps [][]kvWrite) (int, error) {
+	s, err := open(fsys)
+	if err != nil {
+		return 0, err
+	}
+	for i, step := range steps {
+		if err := s.write(step); err != nil {
+			return i, err
+		}
+	}
+	return len(steps), s.close()
+}
+
+func sameContents(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || w != v {
+			return false
+		}
+	}
+	return true
+}
+
+// testKVCrashRecovery checks a store opened with synchronous writes recovers
+// from any crash with exactly the contents after its acknowledged steps,
+// plus possibly the one in flight, and stays usable.
+func testKVCrashRecovery(t *testing.T, open func(*faultfs.FS) (crashStore, error), batches bool) {
+	for seed := int64(1); seed <= int64(crashTrials()); seed++ {
+		rng := rand.New(rand.NewSource(seed))
+		steps := kvSteps(rng, 120, batches)
+		states := []map[string]string{{}}
+		for _, step := range steps {
+			next := map[string]string{}
+			for k, v := range states[len(states)-1] {
+				next[k] = v
+			}
+			for _, w := range step {
+				if w.del {
+					delete(next, w.key)
+				} else {
+					next[w.key] = w.value
+				}
+			}
+			states = append(states, next)
+		}
+
+		dry := faultfs.New(seed)
+		if _, err := runKVWorkload(dry, open, steps); err != nil {
+			t.Fatalf("seed %d: workload without faults: %v", seed, err)
+		}
+		fsys := faultfs.New(seed)
+		fault := injectFault(fsys, rng, dry.Ops())
+		acked, _ := runKVWorkload(fsys, open, steps)
+
+		after := fsys.Crash()
+		s, err := open(after)
+		if err != nil {
+			t.Fatalf("seed %d, %s: reopening: %v", seed, fault, err)
+		}
+		got, err := s.contents()
+		if err != nil {
+			t.Fatalf("seed %d, %s: reading: %v", seed, fault, err)
+		}
+		if !sameContents(got, states[acked]) && (acked == len(steps) || !sameContents(got, states[acked+1])) {
+			t.Fatalf("seed %d, %s: expected the contents after step %d or %d, got %d keys where step %d has %d",
+				seed, fault, acked, acked+1, len(got), acked, len(states[acked]))
+		}
+
+		if err := s.write([]kvWrite{{key: "after-crash", value: "ok"}}); err != nil {
+			t.Fatalf("seed %d, %s: writing after recovery: %v", seed, fault, err)
+		}
+		if err := s.close(); err != nil {
+			t.Fatalf("seed %d, %s: closing after recovery: %v", seed, fault, err)
+		}
+		if s, err = open(after); err != nil {
+			t.Fatalf("seed %d, %s: reopening after recovery: %v", seed, fault, err)
+		}
+		if got, err := s.contents(); err != nil || got["after-crash"] != "ok" {
+			t.Fatalf("seed %d, %s: expected the write after recovery to persist (%v)", seed, fault, err)
+		}
+		s.close()
+	}
+}
+
+func TestDiskHashCrashRecovery(t *testing.T) {
+	testKVCrashRecovery(t, func(fsys *faultfs.FS) (crashStore, error) {
+		d, err := diskhash.Open("/index", diskhash.WithFS(fsys), diskhash.WithSyncWrites(),
+			diskhash.WithBuckets(2), diskhash.WithCheckpointSize(32<<10))
+		return diskHashStore{d}, err
+	}, false)
+}
+
+func TestLSMCrashRecovery(t *testing.T) {
+	testKVCrashRecovery(t, func(fsys *faultfs.FS) (crashStore, error) {
+		db, err := lsm.Open("/db", lsm.WithFS(fsys), lsm.WithSyncWrites(),
+			lsm.WithMemtableSize(2<<10), lsm.WithTableSize(2<<10))
+		return lsmStore{db}, err
+	}, true)
+}
@@ -0,0 +1,236 @@
+package tests
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/hashmap"
+	"github.com/dsa-lab/go/internal/kv"
+	"github.com/dsa-lab/go/internal/queue"
+	"github.com/dsa-lab/go/internal/workload"
+)
+
+// The differential fuzz targets run one decoded operation stream against
+// every implementation of an interface at once and fail as soon as any of
+// them answers differently from the first. Unlike FuzzHashMapOps, which
+// checks one map against a model, they catch drift between variants that a
+// shared model would have to anticipate, such as one map reporting a
+// different previous value after a resize or a tombstone reuse.
+
+// Differential map inputs are a stream of operations: an opcode byte and a
+// key byte, plus a value byte for inserts and an upper bound key byte for
+// scans. The first three opcodes match the FuzzHashMapOps format, so the
+// same workload seeds apply. Clear is the rare opcode 0xff, so random
+// inputs still build up large maps.
+const (
+	diffInsert = iota
+	diffGet
+	diffRemove
+	diffContains
+	diffScan
+	diffOpcodes
+
+	diffClear = 0xff
+)
+
+// diffMap is one map under differential test.
+type diffMap struct {
+	name string
+	m    kv.Map
+}
+
+// differentialMaps returns a fresh instance of every registered map, then of
+// hash map configurations the registry does not cover.
+func differentialMaps() []diffMap {
+	var maps []diffMap
+	for _, impl := range kv.Implementations() {
+		maps = append(maps, diffMap{impl.Name, impl.New()})
+	}
+	return append(maps,
+		diffMap{"hashmap/incremental", hashmap.MustNew(hashmap.WithIncrementalResize(1))},
+		diffMap{"hashmap/cached-hashes", hashmap.MustNew(hashmap.WithCachedHashes())},
+	)
+}
+
+// diffKey keeps keys of one width so their string order is their numeric
+// order and scan bounds cover predictable ranges.
+func diffKey(b byte) string {
+	return fmt.Sprintf("k%03d", b)
+}
+
+// contents returns the entries of m sorted by key, for maps that can range.
+func contents(m kv.Map) ([]string, bool) {
+	r, ok := m.(kv.Ranger)
+	if !ok {
+		return nil, false
+	}
+	var entries []string
+	r.Range(func(k, v string) bool {
+		entries = append(entries, k+"="+v)
+		return true
+	})
+	slices.Sort(entries)
+	return entries, true
+}
+
+func FuzzMapDifferential(f *testing.F) {
+	f.Add([]byte{diffInsert, 1, 1, diffRemove, 1, diffGet, 1, diffContains, 1})
+	f.Add([]byte{diffInsert, 3, 1, diffInsert, 1, 2, diffInsert, 2, 3, diffScan, 1, 3, diffClear, 0, diffScan, 0, 255})
+
+	matches, _ := filepath.Glob(filepath.Join("..", "..", "..", "workloads", "map", "*_small.json"))
+	for _, path := range matches {
+		w, err := workload.Load(path)
+		if err != nil {
+			f.Fatalf("loading seed workload %s: %v", path, err)
+		}
+		f.Add(encodeWorkload(0, w.Operations)[1:])
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		maps := differentialMaps()
+		ref := maps[0]
+		var trace []string
+
+		// diverged fails the input, showing the call the maps disagreed on
+		// and the operations just before it.
+		diverged := func(call, nameA, a, nameB, b string) {
+			recent := trace[max(len(trace)-10, 0):]
+			t.Fatalf("%s: %s returned %s, %s returned %s\nlast %d of %d operations: %s",
+				call, nameA, a, nameB, b, len(recent), len(trace), strings.Join(recent, ", "))
+		}
+		// agree fails unless every map gave the reference's result for call.
+		agree := func(call string, results []string) {
+			for i, r := range results[1:] {
+				if r != results[0] {
+					diverged(call, ref.name, results[0], maps[i+1].name, r)
+				}
+			}
+		}
+
+		for i := 0; i+1 < len(data); {
+			opcode, key := data[i], diffKey(data[i+1])
+			i += 2
+			results := make([]string, len(maps))
+
+			switch op := opcode % diffOpcodes; {
+			case opcode == diffClear:
+				trace = append(trace, "Clear()")
+				for _, dm := range maps {
+					dm.m.Clear()
+				}
+			case op == diffInsert:
+				if i >= len(data) {
+					return
+				}
+				value := fmt.Sprintf("v%d", data[i])
+				i++
+				trace = append(trace, fmt.Sprintf("Insert(%q, %q)", key, value))
+				for j, dm := range maps {
+					old, existed := dm.m.Insert(key, value)
+					results[j] = fmt.Sprintf("(%q, %v)", old, existed)
+				}
+			case op == diffGet:
+				trace = append(trace, fmt.Sprintf("Get(%q)", key))
+				for j, dm := range maps {
+					value, found := dm.m.Get(key)
+					results[j] = fmt.Sprintf("(%q, %v)", value, found)
+				}
+			case op == diffRemove:
+				trace = append(trace, fmt.Sprintf("Remove(%q)", key))
+				for j, dm := range maps {
+					old, existed := dm.m.Remove(key)
+					results[j] = fmt.Sprintf("(%q, %v)", old, existed)
+				}
+			case op == diffContains:
+				trace = append(trace, fmt.Sprintf("Contains(%q)", key))
+				for j, dm := range maps {
+					results[j] = fmt.Sprint(dm.m.Contains(key))
+				}
+			case op == diffScan:
+				if i >= len(data) {
+					return
+				}
+				hi := diffKey(data[i])
+				i++
+				trace = append(trace, fmt.Sprintf("Scan(%q, %q)", key, hi))
+				var scanned []string
+				for _, dm := range maps {
+					s, ok := dm.m.(kv.Scanner)
+					if !ok {
+						continue
+					}
+					var keys []string
+					s.Scan(key, hi, func(k, v string) bool {
+						keys = append(keys, k+"="+v)
+						return true
+					})
+					if got := fmt.Sprint(keys); scanned == nil {
+						scanned = []string{dm.name, got}
+					} else if got != scanned[1] {
+						diverged(trace[len(trace)-1], scanned[0], scanned[1], dm.name, got)
+					}
+				}
+			}
+			agree(trace[len(trace)-1], results)
+
+			for j, dm := range maps {
+				results[j] = fmt.Sprint(dm.m.Len())
+			}
+			agree("Len()", results)
+		}
+
+		want, _ := contents(ref.m)
+		for _, dm := range maps[1:] {
+			if got, ok := contents(dm.m); ok && !slices.Equal(got, want) {
+				diverged("Range", ref.name, fmt.Sprint(want), dm.name, fmt.Sprint(got))
+			}
+		}
+	})
+}
+
+// differentialQueues lists the FIFO queues, each created with room for at
+// least capacity elements so the bounded ones never refuse a push the others
+// accept.
+var differentialQueues = []struct {
+	name string
+	new  func(capacity int) queue.Queue[int]
+}{
+	{"twostack", func(int) queue.Queue[int] { return queue.NewTwoStack[int]() }},
+	{"deque", func(int) queue.Queue[int] { return queue.NewDeque[int]() }},
+	{"mpsc", func(capacity int) queue.Queue[int] { return queue.MustNewMPSC[int](max(capacity, 2)) }},
+}
+
+// FuzzQueueDifferential reads each input byte as an operation: an even byte
+// pops and an odd byte pushes itself. Every queue must pop the same elements
+// and report the same length throughout.
+func FuzzQueueDifferential(f *testing.F) {
+	f.Add([]byte{1, 3, 0, 5, 0, 0, 0, 7})
+	f.Add([]byte{1, 3, 5, 7, 9, 11, 0, 0, 13, 0, 0, 0, 0, 0})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		queues := make([]queue.Queue[int], len(differentialQueues))
+		for i, q := range differentialQueues {
+			queues[i] = q.new(len(data))
+		}
+		for i, b := range data {
+			results := make([]string, len(queues))
+			for j, q := range queues {
+				if b%2 == 1 {
+					results[j] = fmt.Sprint(q.Push(int(b)), q.Len())
+				} else {
+					x, ok := q.Pop()
+					results[j] = fmt.Sprint(x, ok, q.Len())
+				}
+			}
+			for j, r := range results[1:] {
+				if r != results[0] {
+					t.Fatalf("operation %d of %v: %s returned %s, %s returned %s",
+						i, data, differentialQueues[0].name, results[0], differentialQueues[j+1].name, r)
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"fmt"
+	"hash/fnv"
+	"path/filepath"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/hashmap"
+	"github.com/dsa-lab/go/internal/workload"
+)
+
+// Fuzz inputs are a variant byte followed by operations. Each operation is an
+// opcode byte and a key byte; inserts carry an extra value byte. Keys come from
+// a 256-key space so small tables see dense collisions and tombstone reuse.
+const (
+	fuzzInsert = iota
+	fuzzGet
+	fuzzRemove
+	fuzzOpcodes
+)
+
+var fuzzVariants = []func() *hashmap.HashMap{
+	func() *hashmap.HashMap { return hashmap.MustNew() },
+	func() *hashmap.HashMap { return hashmap.MustNew(hashmap.WithIncrementalResize(1)) },
+	func() *hashmap.HashMap { return hashmap.MustNewWithCapacity(1024) },
+}
+
+// encodeWorkload converts a workload into the fuzz byte format, folding its
+// keys and values into single bytes.
+func encodeWorkload(variant byte, ops []workload.Operation) []byte {
+	fold := func(s string) byte {
+		h := fnv.New32a()
+		h.Write([]byte(s))
+		return byte(h.Sum32())
+	}
+
+	data := []byte{variant}
+	for _, op := range ops {
+		switch op.Op {
+		case workload.OpInsert:
+			data = append(data, fuzzInsert, fold(op.Key), fold(op.Value))
+		case workload.OpGet:
+			data = append(data, fuzzGet, fold(op.Key))
+		case workload.OpDelete:
+			data = append(data, fuzzRemove, fold(op.Key))
+		}
+	}
+	return data
+}
+
+func FuzzHashMapOps(f *testing.F) {
+	f.Add([]byte{0, fuzzInsert, 1, 1, fuzzRemove, 1, fuzzGet, 1})
+	f.Add([]byte{1, fuzzInsert, 1, 1, fuzzInsert, 2, 2, fuzzRemove, 1, fuzzInsert, 1, 3})
+
+	matches, _ := filepath.Glob(filepath.Join("..", "..", "..", "workloads", "map", "*_small.json"))
+	for i, path := range matches {
+		w, err := workload.Load(path)
+		if err != nil {
+			f.Fatalf("loading seed workload %s: %v", path, err)
+		}
+		f.Add(encodeWorkload(byte(i), w.Operations))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if len(data) == 0 {
+			return
+		}
+		m := fuzzVariants[int(data[0])%len(fuzzVariants)]()
+		ref := make(map[string]string)
+
+		for i := 1; i+1 < len(data); {
+			op := data[i] % fuzzOpcodes
+			key := fmt.Sprintf("k%d", data[i+1])
+			i += 2
+
+			switch op {
+			case fuzzInsert:
+				if i >= len(data) {
+					return
+				}
+				value := fmt.Sprintf("v%d", data[i])
+				i++
+				got, gotExisted := m.Insert(key, value)
+				want, wantExisted := ref[key]
+				if got != want || gotExisted != wantExisted {
+					t.Fatalf("Insert(%q): got (%q, %v), want (%q, %v)", key, got, gotExisted, want, wantExisted)
+				}
+				ref[key] = value
+			case fuzzGet:
+				got, gotFound := m.Get(key)
+				want, wantFound := ref[key]
+				if got != want || gotFound != wantFound {
+					t.Fatalf("Get(%q): got (%q, %v), want (%q, %v)", key, got, gotFound, want, wantFound)
+				}
+			case fuzzRemove:
+				got, gotExisted := m.Remove(key)
+				want, wantExisted := ref[key]
+				if got != want || gotExisted != wantExisted {
+					t.Fatalf("Remove(%q): got (%q, %v), want (%q, %v)", key, got, gotExisted, want, wantExisted)
+				}
+				delete(ref, key)
+			}
+
+			if m.Len() != len(ref) {
+				t.Fatalf("Len() = %d, want %d", m.Len(), len(ref))
+			}
+		}
+
+		if err := m.CheckInvariants(); err != nil {
+			t.Fatal(err)
+		}
+		for key, want := range ref {
+			if got, found := m.Get(key); !found || got != want {
+				t.Fatalf("final Get(%q): got (%q, %v), want %q", key, got, found, want)
+			}
+		}
+	})
+}
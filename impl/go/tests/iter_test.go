@@ -0,0 +1,490 @@
+//go:build go1.23
+
+package tests
+
+import (
+	"fmt"
+	"iter"
+	"maps"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dsa-lab/go/internal/bitset"
+	"github.com/dsa-lab/go/internal/cache"
+	"github.com/dsa-lab/go/internal/concurrent/lockfree"
+	"github.com/dsa-lab/go/internal/concurrent/rcu"
+	"github.com/dsa-lab/go/internal/concurrent/sharded"
+	"github.com/dsa-lab/go/internal/graph"
+	"github.com/dsa-lab/go/internal/hashmap"
+	"github.com/dsa-lab/go/internal/heap"
+	"github.com/dsa-lab/go/internal/interval"
+	"github.com/dsa-lab/go/internal/kv"
+	"github.com/dsa-lab/go/internal/merkle"
+	"github.com/dsa-lab/go/internal/multiset"
+	"github.com/dsa-lab/go/internal/mvcc"
+	"github.com/dsa-lab/go/internal/orderlist"
+	"github.com/dsa-lab/go/internal/pvector"
+	"github.com/dsa-lab/go/internal/queue"
+	"github.com/dsa-lab/go/internal/rangequery"
+	"github.com/dsa-lab/go/internal/sched"
+	"github.com/dsa-lab/go/internal/seq"
+	"github.com/dsa-lab/go/internal/sketch"
+	"github.com/dsa-lab/go/internal/skiplist"
+	"github.com/dsa-lab/go/internal/smallmap"
+	"github.com/dsa-lab/go/internal/sparseset"
+	"github.com/dsa-lab/go/internal/spatial"
+	"github.com/dsa-lab/go/internal/streaming"
+	"github.com/dsa-lab/go/internal/succinct"
+	"github.com/dsa-lab/go/internal/symtab"
+	"github.com/dsa-lab/go/internal/text"
+	"github.com/dsa-lab/go/internal/veb"
+	"github.com/dsa-lab/go/internal/weakmap"
+	"github.com/dsa-lab/go/internal/window"
+	pkghashmap "github.com/dsa-lab/go/pkg/hashmap"
+	pkgskiplist "github.com/dsa-lab/go/pkg/skiplist"
+)
+
+// TestStringMapIterators checks that All yields exactly the pairs Range
+// visits for every string map, and that breaking out stops iteration.
+func TestStringMapIterators(t *testing.T) {
+	type stringMap interface {
+		Insert(key, value string) (string, bool)
+		Range(f func(key, value string) bool)
+		All() iter.Seq2[string, string]
+	}
+	builtin := kv.NewBuiltin()
+	maps := map[string]stringMap{
+		"hashmap":     hashmap.MustNew(),
+		"builtin":     builtin,
+		"syncmap":     kv.NewSyncMap(),
+		"sharded":     sharded.MustNew(),
+		"lockfree":    lockfree.New(),
+		"rcu":         rcu.New(),
+		"smallmap":    smallmap.New(),
+		"pkg/hashmap": pkghashmap.MustNew(),
+	}
+	sl := skiplist.MustNew[string, string]()
+	for i := 0; i < 200; i++ {
+		key, value := fmt.Sprintf("key%03d", i), fmt.Sprint(i)
+		sl.Insert(key, value)
+		for _, m := range maps {
+			m.Insert(key, value)
+		}
+	}
+	for name, m := range maps {
+		want := map[string]string{}
+		m.Range(func(key, value string) bool {
+			want[key] = value
+			return true
+		})
+		got := map[string]string{}
+		for key, value := range m.All() {
+			got[key] = value
+		}
+		if len(got) != len(want) || len(got) != 200 {
+			t.Errorf("%s: expected 200 pairs, got %d", name, len(got))
+		}
+		for key, value := range want {
+			if got[key] != value {
+				t.Errorf("%s: expected %s=%s, got %q", name, key, value, got[key])
+			}
+		}
+		n := 0
+		for range m.All() {
+			if n++; n == 10 {
+				break
+			}
+		}
+		if n != 10 {
+			t.Errorf("%s: expected to stop after 10 pairs, got %d", name, n)
+		}
+	}
+
+	if got := seq.Collect(seq.Keys(sl.All())); !slices.Equal(got, sl.Keys()) {
+		t.Errorf("skiplist: expected All keys to equal Keys")
+	}
+	between := seq.Collect(seq.Keys(sl.Between("key010", "key015")))
+	want := []string{"key010", "key011", "key012", "key013", "key014"}
+	if !slices.Equal(between, want) {
+		t.Errorf("skiplist: expected %v, got %v", want, between)
+	}
+	if got := seq.Collect(seq.Keys(builtin.Between("key010", "key015"))); !slices.Equal(got, want) {
+		t.Errorf("builtin: expected %v, got %v", want, got)
+	}
+
+	list := pkgskiplist.New[string, string]()
+	for key, value := range sl.All() {
+		list.Insert(key, value)
+	}
+	if got := seq.Collect(seq.Keys(list.All())); !slices.Equal(got, sl.Keys()) {
+		t.Errorf("pkg/skiplist: expected All keys to equal the skip list's")
+	}
+	if got := seq.Collect(seq.Keys(list.Between("key010", "key015"))); !slices.Equal(got, want) {
+		t.Errorf("pkg/skiplist: expected %v, got %v", want, got)
+	}
+}
+
+func TestCollectionIterators(t *testing.T) {
+	bits := bitset.MustNew(100)
+	for _, i := range []int{3, 64, 65, 99} {
+		bits.Set(i)
+	}
+	if got := seq.Collect(bits.All()); !slices.Equal(got, []int{3, 64, 65, 99}) {
+		t.Errorf("bitset: expected [3 64 65 99], got %v", got)
+	}
+
+	v := pvector.New[int]()
+	for i := 0; i < 100; i++ {
+		v = v.PushBack(i * i)
+	}
+	if got := seq.Collect(v.Values()); !slices.Equal(got, v.ToSlice()) {
+		t.Errorf("pvector: expected Values to equal ToSlice")
+	}
+	for i, x := range v.All() {
+		if x != i*i {
+			t.Fatalf("pvector: expected %d at %d, got %d", i*i, i, x)
+		}
+	}
+
+	ms := multiset.New()
+	for _, k := range []string{"a", "b", "a", "c", "a"} {
+		ms.Add(k)
+	}
+	counts := map[string]int{}
+	for k, n := range ms.All() {
+		counts[k] = n
+	}
+	if counts["a"] != 3 || counts["b"] != 1 || seq.Count(ms.Keys()) != 3 {
+		t.Errorf("multiset: expected a=3 b=1 and 3 distinct keys, got %v", counts)
+	}
+
+	sm := sparseset.NewMap[string](50)
+	for _, k := range []int{7, 3, 42} {
+		sm.Insert(k, fmt.Sprint("v", k))
+	}
+	if got := seq.Collect(sm.Keys()); !slices.Equal(got, []int{7, 3, 42}) {
+		t.Errorf("sparseset: expected keys [7 3 42], got %v", got)
+	}
+	if got := seq.Collect(sm.Values()); !slices.Equal(got, []string{"v7", "v3", "v42"}) {
+		t.Errorf("sparseset: expected values [v7 v3 v42], got %v", got)
+	}
+
+	set := interval.NewSet[int]()
+	set.Insert(interval.Interval[int]{Start: 10, End: 20})
+	set.Insert(interval.Interval[int]{Start: 0, End: 5})
+	if got := seq.Collect(set.All()); !slices.Equal(got, set.Ranges()) {
+		t.Errorf("interval set: expected All to equal Ranges, got %v", got)
+	}
+
+	h := heap.NewOrdered[int]()
+	for _, x := range []int{5, 1, 4, 2, 3} {
+		h.Push(x)
+	}
+	if seq.Count(h.All()) != 5 {
+		t.Errorf("heap: expected All to yield 5 elements")
+	}
+	if got := seq.Collect(seq.Take(h.Drain(), 3)); !slices.Equal(got, []int{1, 2, 3}) || h.Len() != 2 {
+		t.Errorf("heap: expected to drain [1 2 3] leaving 2, got %v leaving %d", got, h.Len())
+	}
+
+	// Popping from the front while pushing at the back wraps the ring.
+	d := queue.NewDeque[int]()
+	for i := 0; i < 20; i++ {
+		d.PushBack(i)
+		if i%2 == 1 {
+			d.PopFront()
+		}
+	}
+	d.PushFront(-1)
+	if got := seq.Collect(d.All()); !slices.Equal(got, []int{-1, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19}) {
+		t.Errorf("deque: expected -1 then 10..19, got %v", got)
+	}
+	q := queue.NewTwoStack[int]()
+	for i := 0; i < 5; i++ {
+		q.Push(i)
+	}
+	q.Pop()
+	q.Push(5)
+	if got := seq.Collect(q.All()); !slices.Equal(got, []int{1, 2, 3, 4, 5}) {
+		t.Errorf("two-stack: expected [1 2 3 4 5], got %v", got)
+	}
+
+	tree, err := veb.New(16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, x := range []uint64{900, 2, 65535, 17} {
+		tree.Insert(x)
+	}
+	if got := seq.Collect(tree.All()); !slices.Equal(got, []uint64{2, 17, 900, 65535}) {
+		t.Errorf("veb: expected [2 17 900 65535], got %v", got)
+	}
+
+	ol := orderlist.New[string]()
+	b := ol.PushBack("b")
+	ol.InsertBefore(b, "a")
+	ol.PushBack("x")
+	ol.PushBack("c")
+	for e := range ol.All() {
+		if e.Value == "x" {
+			ol.Remove(e)
+		}
+	}
+	if got := seq.Collect(ol.Values()); !slices.Equal(got, []string{"a", "b", "c"}) {
+		t.Errorf("orderlist: expected [a b c] after removing x mid-iteration, got %v", got)
+	}
+
+	syms := symtab.New()
+	for _, name := range []string{"b", "a", "b", "c"} {
+		syms.Intern(name)
+	}
+	for id, name := range syms.All() {
+		if syms.Name(id) != name {
+			t.Errorf("symtab: expected %d to name %q, got %q", id, syms.Name(id), name)
+		}
+	}
+	if got := seq.Collect(seq.Values(syms.All())); !slices.Equal(got, []string{"b", "a", "c"}) {
+		t.Errorf("symtab: expected first-interned order [b a c], got %v", got)
+	}
+
+	top := streaming.MustNewTopKOrdered[int](3)
+	med := streaming.NewMedianOrdered[int]()
+	for _, x := range []int{5, 1, 9, 3, 7, 2} {
+		top.Add(x)
+		med.Add(x)
+	}
+	kept := seq.Collect(top.All())
+	slices.Sort(kept)
+	if !slices.Equal(kept, []int{5, 7, 9}) {
+		t.Errorf("streaming topk: expected [5 7 9], got %v", kept)
+	}
+	recorded := seq.Collect(med.All())
+	if lo, hi := slices.Max(recorded[:3]), slices.Min(recorded[3:]); lo != 3 || hi != 5 {
+		t.Errorf("streaming median: expected halves split between 3 and 5, got %v", recorded)
+	}
+
+	for name, s := range map[string]sched.Scheduler[int]{
+		"wheel":    sched.NewWheel[int](0),
+		"calendar": sched.NewCalendar[int](0),
+	} {
+		var timers []*sched.Timer[int]
+		for i, d := range []uint64{0, 70, 5000, 3, 1 << 40} {
+			timers = append(timers, s.Add(d, i))
+		}
+		s.Cancel(timers[2])
+		all := s.(interface {
+			All() iter.Seq[*sched.Timer[int]]
+		})
+		var values []int
+		for tm := range all.All() {
+			if !tm.Pending() {
+				t.Errorf("%s: expected only pending timers, got %d", name, tm.Value())
+			}
+			values = append(values, tm.Value())
+		}
+		slices.Sort(values)
+		if !slices.Equal(values, []int{0, 1, 3, 4}) {
+			t.Errorf("%s: expected timers [0 1 3 4], got %v", name, values)
+		}
+	}
+}
+
+// TestIndexIterators checks the iterators of structures that are not plain
+// collections against what their own queries report.
+func TestIndexIterators(t *testing.T) {
+	points := []spatial.Point{{1, 1}, {9, 2}, {4, 7}, {6, 6}, {2, 8}, {5, 5}, {8, 9}, {3, 3}, {7, 1}, {0, 4}}
+	kd := spatial.MustNewKDTree[int](2)
+	quad := spatial.MustNewQuadtree[int](spatial.Rect{Min: spatial.Point{0, 0}, Max: spatial.Point{10, 10}}, 2)
+	rt := spatial.MustNewRTree[int](2, 4)
+	for i, p := range points {
+		kd.Insert(p, i)
+		quad.Insert(p, i)
+		rt.Insert(spatial.Rect{Min: p, Max: p}, i)
+	}
+	for name, got := range map[string][]int{
+		"kdtree":   seq.Collect(seq.Values(kd.All())),
+		"quadtree": seq.Collect(seq.Values(quad.All())),
+		"rtree":    seq.Collect(seq.Values(rt.All())),
+	} {
+		slices.Sort(got)
+		if len(got) != len(points) || got[0] != 0 || got[len(got)-1] != len(points)-1 {
+			t.Errorf("%s: expected every value once, got %v", name, got)
+		}
+	}
+	for p, i := range kd.All() {
+		if !slices.Equal(p, points[i]) {
+			t.Errorf("kdtree: expected %v for value %d, got %v", points[i], i, p)
+		}
+	}
+
+	for _, impl := range text.Implementations() {
+		buf := impl.New("hello world")
+		if err := text.Apply(buf, []text.Edit{{Pos: 5, Insert: ","}, {Pos: 0, Delete: 1, Insert: "J"}}); err != nil {
+			t.Fatal(err)
+		}
+		chunks, ok := buf.(interface{ Chunks() iter.Seq[string] })
+		if !ok {
+			t.Errorf("%s: expected a Chunks iterator", impl.Name)
+			continue
+		}
+		if got := strings.Join(seq.Collect(chunks.Chunks()), ""); got != buf.String() {
+			t.Errorf("%s: expected chunks to join to %q, got %q", impl.Name, buf.String(), got)
+		}
+	}
+
+	agg := window.MustNew(window.WithLastN(3))
+	for i := 1; i <= 5; i++ {
+		agg.Add(float64(i))
+	}
+	var values []float64
+	for s := range agg.All() {
+		values = append(values, s.Value)
+	}
+	if !slices.Equal(values, []float64{3, 4, 5}) {
+		t.Errorf("window: expected [3 4 5], got %v", values)
+	}
+
+	topk := sketch.MustNewTopK(3)
+	for _, k := range []string{"a", "b", "a", "c", "a", "b"} {
+		topk.Add(k)
+	}
+	counts := maps.Collect(topk.All())
+	if counts["a"] != 3 || counts["b"] != 2 || counts["c"] != 1 || len(counts) != 3 {
+		t.Errorf("topk: expected a=3 b=2 c=1, got %v", counts)
+	}
+
+	mt := merkle.New([]merkle.Pair{{Key: "b", Value: "2"}, {Key: "a", Value: "1"}, {Key: "c", Value: "3"}})
+	if got := seq.Collect(seq.Keys(mt.All())); !slices.Equal(got, []string{"a", "b", "c"}) {
+		t.Errorf("merkle: expected keys [a b c], got %v", got)
+	}
+
+	xs := []int{5, 2, 8, 1}
+	st := rangequery.NewSegmentTree(xs, func(a, b int) int { return a + b })
+	st.Set(2, 7)
+	if got := seq.Collect(seq.Values(st.All())); !slices.Equal(got, []int{5, 2, 7, 1}) {
+		t.Errorf("segment tree: expected [5 2 7 1], got %v", got)
+	}
+	if got := seq.Collect(seq.Values(rangequery.NewMinTable(xs).All())); !slices.Equal(got, xs) {
+		t.Errorf("sparse table: expected %v, got %v", xs, got)
+	}
+
+	// 0 -> 1 -> 3 and 0 -> 2 -> 3; 4 is unreachable.
+	g := graph.New(5, true)
+	for _, e := range [][2]int{{0, 1}, {0, 2}, {1, 3}, {2, 3}, {4, 0}} {
+		g.AddEdge(e[0], e[1], 1)
+	}
+	if got := seq.Collect(g.All()); !slices.Equal(got, g.Edges()) {
+		t.Errorf("graph: expected All to equal Edges, got %v", got)
+	}
+	if got := seq.Collect(g.BFS(0)); !slices.Equal(got, []int{0, 1, 2, 3}) {
+		t.Errorf("graph: expected BFS [0 1 2 3], got %v", got)
+	}
+	if got := seq.Collect(g.DFS(0)); !slices.Equal(got, []int{0, 1, 3, 2}) {
+		t.Errorf("graph: expected DFS [0 1 3 2], got %v", got)
+	}
+	if got := seq.Collect(seq.Take(g.DFS(4), 2)); !slices.Equal(got, []int{4, 0}) {
+		t.Errorf("graph: expected DFS from 4 to start [4 0], got %v", got)
+	}
+
+	keys := []string{"tea", "to", "ten", "a", "inn", "in"}
+	trie := succinct.Build(keys, []string{"1", "2", "3", "4", "5", "6"})
+	sorted := slices.Sorted(slices.Values(keys))
+	if got := seq.Collect(trie.Keys()); !slices.Equal(got, sorted) {
+		t.Errorf("trie: expected keys %v, got %v", sorted, got)
+	}
+	for key, value := range trie.All() {
+		if v, _ := trie.Get(key); v != value {
+			t.Errorf("trie: expected %s=%s, got %q", key, v, value)
+		}
+	}
+	data := []byte("abracadabra")
+	var access []byte
+	for i, c := range succinct.NewWaveletMatrix(data).All() {
+		if i != len(access) {
+			t.Fatalf("wavelet: expected position %d, got %d", len(access), i)
+		}
+		access = append(access, c)
+	}
+	if string(access) != string(data) {
+		t.Errorf("wavelet: expected %q, got %q", data, access)
+	}
+
+	versions := mvcc.New()
+	versions.Put("a", "1")
+	versions.Put("b", "2")
+	versions.Delete("a")
+	versions.Put("c", "3")
+	if got := maps.Collect(versions.All()); len(got) != 2 || got["b"] != "2" || got["c"] != "3" {
+		t.Errorf("mvcc: expected b=2 c=3 at the latest version, got %v", got)
+	}
+
+	weak := weakmap.New[string, int]()
+	held := []*int{new(int), new(int)}
+	weak.Set("x", held[0])
+	weak.Set("y", held[1])
+	for key := range weak.All() {
+		weak.Delete(key)
+	}
+	if weak.Len() != 0 {
+		t.Errorf("weakmap: expected deleting during iteration to empty the map, got %d", weak.Len())
+	}
+	runtime.KeepAlive(held)
+}
+
+// TestCacheIterators checks that every policy iterates exactly its cached
+// entries without counting the visits as accesses.
+func TestCacheIterators(t *testing.T) {
+	for _, p := range cache.Policies() {
+		c := p.MustNew(4)
+		for i := 0; i < 6; i++ {
+			c.Put(fmt.Sprint("k", i), fmt.Sprint(i))
+		}
+		c.Get("k5")
+		all, ok := c.(interface {
+			All() iter.Seq2[string, string]
+		})
+		if !ok {
+			t.Errorf("%s: expected an All iterator", p.Name)
+			continue
+		}
+		stats := c.Stats()
+		got := maps.Collect(all.All())
+		if len(got) != c.Len() {
+			t.Errorf("%s: expected %d entries, got %v", p.Name, c.Len(), got)
+		}
+		for key, value := range got {
+			if v, found := c.Peek(key); !found || v != value {
+				t.Errorf("%s: expected %s=%s to be cached, got (%q, %v)", p.Name, key, value, v, found)
+			}
+		}
+		if c.Stats() != stats {
+			t.Errorf("%s: expected iteration to leave stats at %+v, got %+v", p.Name, stats, c.Stats())
+		}
+	}
+
+	lru := cache.MustNewLRU(3)
+	for _, k := range []string{"a", "b", "c"} {
+		lru.Put(k, k)
+	}
+	lru.Get("a")
+	if got := seq.Collect(seq.Keys(lru.All())); !slices.Equal(got, []string{"a", "c", "b"}) {
+		t.Errorf("lru: expected most recent first [a c b], got %v", got)
+	}
+
+	now := time.Unix(0, 0)
+	ttl := cache.MustNewTTL(time.Minute, cache.WithNegativeTTL(time.Minute), cache.WithClock(func() time.Time { return now }))
+	ttl.Set("old", "0")
+	now = now.Add(time.Minute)
+	ttl.Set("fresh", "1")
+	ttl.SetMissing("gone")
+	stats := ttl.Stats()
+	if got := maps.Collect(ttl.All()); len(got) != 1 || got["fresh"] != "1" {
+		t.Errorf("ttl: expected only fresh=1, got %v", got)
+	}
+	if ttl.Stats() != stats || ttl.Len() != 3 {
+		t.Errorf("ttl: expected iteration to leave stats and entries alone")
+	}
+}
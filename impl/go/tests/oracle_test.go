@@ -6,10 +6,13 @@ import (
 	"testing"
 
 	"github.com/dsa-lab/go/internal/hashmap"
+	"github.com/dsa-lab/go/internal/kv"
+	"github.com/dsa-lab/go/internal/multiset"
+	"github.com/dsa-lab/go/internal/trace"
 )
 
 func TestOracleInsertGet(t *testing.T) {
-	ourMap := hashmap.New()
+	ourMap := hashmap.MustNew()
 	stdMap := make(map[string]string)
 
 	for i := 0; i < 1000; i++ {
@@ -46,7 +49,7 @@ func TestOracleInsertGet(t *testing.T) {
 }
 
 func TestOracleOverwrite(t *testing.T) {
-	ourMap := hashmap.New()
+	ourMap := hashmap.MustNew()
 	stdMap := make(map[string]string)
 
 	// Insert initial values
@@ -79,7 +82,7 @@ func TestOracleOverwrite(t *testing.T) {
 }
 
 func TestOracleRemove(t *testing.T) {
-	ourMap := hashmap.New()
+	ourMap := hashmap.MustNew()
 	stdMap := make(map[string]string)
 
 	// Insert
@@ -117,7 +120,7 @@ func TestOracleRemove(t *testing.T) {
 
 func TestOracleMixedOperations(t *testing.T) {
 	rng := rand.New(rand.NewSource(42))
-	ourMap := hashmap.New()
+	ourMap := hashmap.MustNew()
 	stdMap := make(map[string]string)
 
 	for i := 0; i < 10000; i++ {
@@ -144,9 +147,162 @@ func TestOracleMixedOperations(t *testing.T) {
 			ourMap.Remove(key)
 			delete(stdMap, key)
 		}
+
+		if i%100 == 99 {
+			if err := ourMap.CheckInvariants(); err != nil {
+				t.Fatalf("invariant violated at iteration %d: %v", i, err)
+			}
+		}
 	}
 
 	if ourMap.Len() != len(stdMap) {
 		t.Errorf("final length mismatch: our=%d, std=%d", ourMap.Len(), len(stdMap))
 	}
 }
+
+func TestOracleIncrementalResize(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+	ourMap := hashmap.MustNew(hashmap.WithIncrementalResize(1))
+	stdMap := make(map[string]string)
+
+	for i := 0; i < 20000; i++ {
+		op := rng.Intn(3)
+		key := fmt.Sprintf("key_%d", rng.Intn(2000))
+		value := fmt.Sprintf("value_%d", rng.Intn(1000))
+
+		switch op {
+		case 0: // Insert
+			ourOld, ourExisted := ourMap.Insert(key, value)
+			stdOld, stdExisted := stdMap[key]
+			if ourExisted != stdExisted || ourOld != stdOld {
+				t.Errorf("insert mismatch for key %s at iteration %d", key, i)
+			}
+			stdMap[key] = value
+
+		case 1: // Get
+			ourValue, ourFound := ourMap.Get(key)
+			stdValue, stdFound := stdMap[key]
+			if ourFound != stdFound || ourValue != stdValue {
+				t.Errorf("get mismatch for key %s at iteration %d", key, i)
+			}
+
+		case 2: // Remove
+			ourOld, ourExisted := ourMap.Remove(key)
+			stdOld, stdExisted := stdMap[key]
+			if ourExisted != stdExisted || ourOld != stdOld {
+				t.Errorf("remove mismatch for key %s at iteration %d", key, i)
+			}
+			delete(stdMap, key)
+		}
+
+		if i%100 == 99 {
+			if err := ourMap.CheckInvariants(); err != nil {
+				t.Fatalf("invariant violated at iteration %d: %v", i, err)
+			}
+		}
+	}
+
+	if ourMap.Len() != len(stdMap) {
+		t.Errorf("final length mismatch: our=%d, std=%d", ourMap.Len(), len(stdMap))
+	}
+	if len(ourMap.Keys()) != len(stdMap) {
+		t.Errorf("key count mismatch: our=%d, std=%d", len(ourMap.Keys()), len(stdMap))
+	}
+}
+
+func TestOracleImplementations(t *testing.T) {
+	for _, impl := range kv.Implementations() {
+		t.Run(impl.Name, func(t *testing.T) {
+			rng := rand.New(rand.NewSource(42))
+			ourMap := trace.NewTracer(impl.New())
+			stdMap := make(map[string]string)
+			defer reportTrace(t, ourMap, impl.New)
+
+			for i := 0; i < 10000; i++ {
+				op := rng.Intn(3)
+				key := fmt.Sprintf("key_%d", rng.Intn(100))
+				value := fmt.Sprintf("value_%d", rng.Intn(1000))
+
+				switch op {
+				case 0: // Insert
+					ourOld, ourExisted := ourMap.Insert(key, value)
+					stdOld, stdExisted := stdMap[key]
+					if ourExisted != stdExisted || ourOld != stdOld {
+						t.Errorf("insert mismatch for key %s at iteration %d", key, i)
+					}
+					stdMap[key] = value
+
+				case 1: // Get
+					ourValue, ourFound := ourMap.Get(key)
+					stdValue, stdFound := stdMap[key]
+					if ourFound != stdFound || ourValue != stdValue {
+						t.Errorf("get mismatch for key %s at iteration %d", key, i)
+					}
+
+				case 2: // Remove
+					ourOld, ourExisted := ourMap.Remove(key)
+					stdOld, stdExisted := stdMap[key]
+					if ourExisted != stdExisted || ourOld != stdOld {
+						t.Errorf("remove mismatch for key %s at iteration %d", key, i)
+					}
+					delete(stdMap, key)
+				}
+			}
+
+			if ourMap.Len() != len(stdMap) {
+				t.Errorf("final length mismatch: our=%d, std=%d", ourMap.Len(), len(stdMap))
+			}
+		})
+	}
+}
+
+func TestOracleMultiset(t *testing.T) {
+	rng := rand.New(rand.NewSource(5))
+	bag := multiset.New()
+	ref := make(map[string]int)
+	total := 0
+
+	for i := 0; i < 20000; i++ {
+		key := fmt.Sprintf("key_%d", rng.Intn(200))
+		n := rng.Intn(4)
+		switch rng.Intn(3) {
+		case 0:
+			bag.AddN(key, n)
+			ref[key] += n
+			total += n
+			if ref[key] == 0 {
+				delete(ref, key)
+			}
+		case 1:
+			removed := bag.RemoveN(key, n)
+			want := min(n, ref[key])
+			if removed != want {
+				t.Fatalf("RemoveN(%s, %d) = %d, expected %d at step %d", key, n, removed, want, i)
+			}
+			ref[key] -= want
+			total -= want
+			if ref[key] == 0 {
+				delete(ref, key)
+			}
+		case 2:
+			if got := bag.Count(key); got != ref[key] {
+				t.Fatalf("Count(%s) = %d, expected %d at step %d", key, got, ref[key], i)
+			}
+		}
+		if bag.Distinct() != len(ref) || bag.TotalLen() != total {
+			t.Fatalf("step %d: distinct/total %d/%d, expected %d/%d", i, bag.Distinct(), bag.TotalLen(), len(ref), total)
+		}
+	}
+
+	seen := 0
+	bag.Range(func(key string, count int) bool {
+		seen++
+		if ref[key] != count {
+			t.Errorf("Range yielded %s=%d, expected %d", key, count, ref[key])
+		}
+		return true
+	})
+	if seen != len(ref) {
+		t.Errorf("Range visited %d elements, expected %d", seen, len(ref))
+	}
+}
@@ -0,0 +1,74 @@
+package tests
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/order"
+	"github.com/dsa-lab/go/internal/skiplist"
+)
+
+// orderedSet is the subset of operations shared by the ordered structures.
+type orderedSet interface {
+	Insert(key int, value struct{}) (struct{}, bool)
+	Remove(key int) (struct{}, bool)
+	Range(f func(key int, value struct{}) bool)
+}
+
+// orderedStructures lists every ordered structure that accepts a comparator,
+// so each is held to the same ordering semantics.
+var orderedStructures = []struct {
+	name string
+	new  func(order.Comparator[int]) orderedSet
+}{
+	{"skiplist", func(c order.Comparator[int]) orderedSet { return skiplist.MustNewFunc[int, struct{}](c) }},
+}
+
+// TestOrderedComparators checks that iteration order follows the comparator
+// for natural, reversed and composed orderings.
+func TestOrderedComparators(t *testing.T) {
+	comparators := []struct {
+		name    string
+		compare order.Comparator[int]
+	}{
+		{"natural", order.Natural[int]()},
+		{"reverse", order.Natural[int]().Reverse()},
+		{"last-digit", order.By(func(x int) int { return x % 10 }).Then(order.Natural[int]().Reverse())},
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	for _, s := range orderedStructures {
+		for _, c := range comparators {
+			t.Run(s.name+"/"+c.name, func(t *testing.T) {
+				set := s.new(c.compare)
+				present := make(map[int]bool)
+				for i := 0; i < 2000; i++ {
+					x := rng.Intn(500)
+					if rng.Intn(4) == 0 {
+						set.Remove(x)
+						delete(present, x)
+					} else {
+						set.Insert(x, struct{}{})
+						present[x] = true
+					}
+				}
+
+				var want []int
+				for x := range present {
+					want = append(want, x)
+				}
+				slices.SortFunc(want, c.compare)
+
+				var got []int
+				set.Range(func(x int, _ struct{}) bool {
+					got = append(got, x)
+					return true
+				})
+				if !slices.Equal(got, want) {
+					t.Errorf("expected %d keys in comparator order, got %d in order %v", len(want), len(got), got[:min(len(got), 10)])
+				}
+			})
+		}
+	}
+}
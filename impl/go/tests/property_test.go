@@ -0,0 +1,49 @@
+package tests
+
+import (
+	"testing"
+	"testing/quick"
+
+	"github.com/dsa-lab/go/internal/hashmap"
+	"github.com/dsa-lab/go/internal/kv"
+	"github.com/dsa-lab/go/internal/proptest"
+	"github.com/dsa-lab/go/internal/skiplist"
+)
+
+var propertyConfig = &quick.Config{MaxCount: 200, MaxCountScale: 1}
+
+func TestPropertyImplementations(t *testing.T) {
+	for _, impl := range kv.Implementations() {
+		t.Run(impl.Name, func(t *testing.T) {
+			proptest.CheckMap(t, impl.New, propertyConfig)
+		})
+	}
+}
+
+func TestPropertyHashMapVariants(t *testing.T) {
+	variants := []struct {
+		name string
+		new  func() kv.Map
+	}{
+		{"small_capacity", func() kv.Map { return hashmap.MustNewWithCapacity(4) }},
+		{"incremental_resize", func() kv.Map { return hashmap.MustNew(hashmap.WithIncrementalResize(1)) }},
+	}
+
+	for _, v := range variants {
+		t.Run(v.name, func(t *testing.T) {
+			proptest.CheckMap(t, v.new, propertyConfig)
+		})
+	}
+}
+
+func TestPropertyConcurrentMaps(t *testing.T) {
+	for _, impl := range concurrentMaps {
+		t.Run(impl.Name, func(t *testing.T) {
+			proptest.CheckMap(t, impl.New, propertyConfig)
+		})
+	}
+}
+
+func TestPropertySkipList(t *testing.T) {
+	proptest.CheckMap(t, func() kv.Map { return skiplist.MustNew[string, string]() }, propertyConfig)
+}
@@ -0,0 +1,73 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dsa-lab/go/internal/cache"
+	"github.com/dsa-lab/go/internal/ratelimit"
+	"github.com/dsa-lab/go/internal/sim"
+)
+
+var simStart = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// TestTokenBucketOnSimulatedTraffic replays two clients with the same mean
+// rate, one steady and one bursty, through a token bucket running on the
+// simulated clock. The bucket's rate covers both on average, so it should
+// pass nearly all of the steady client's requests and throttle the bursts.
+func TestTokenBucketOnSimulatedTraffic(t *testing.T) {
+	requests := sim.Generate(simStart, 10*time.Minute, 1,
+		sim.Client{Name: "steady", Arrivals: sim.Poisson{Rate: 10}, Keys: sim.UniformKeys("k", 10)},
+		sim.Client{Name: "bursty", Arrivals: &sim.OnOff{OnRate: 50, On: 2 * time.Second, Off: 8 * time.Second}, Keys: sim.UniformKeys("k", 10)},
+	)
+	var now time.Time
+	limiter := ratelimit.MustNewTokenBucket(15, 30, ratelimit.WithClock(func() time.Time { return now }))
+	total := map[string]int{}
+	rejected := map[string]int{}
+	for _, r := range requests {
+		now = r.At
+		total[r.Client]++
+		if !limiter.Allow(r.Client) {
+			rejected[r.Client]++
+		}
+	}
+	steady := float64(rejected["steady"]) / float64(total["steady"])
+	bursty := float64(rejected["bursty"]) / float64(total["bursty"])
+	if steady > 0.01 {
+		t.Errorf("expected under 1%% of steady requests rejected, got %.3f", steady)
+	}
+	if bursty < 0.3 {
+		t.Errorf("expected at least 30%% of bursty requests rejected, got %.3f", bursty)
+	}
+}
+
+// TestCacheHitRatesOnSimulatedTraffic replays skewed and uniform access
+// streams from the simulator through every cache policy. Skew is what makes
+// a small cache useful, so each policy should hit far more often on the
+// Zipfian stream.
+func TestCacheHitRatesOnSimulatedTraffic(t *testing.T) {
+	skewed := sim.Workload("zipf", sim.Generate(simStart, time.Hour, 2,
+		sim.Client{Name: "web", Arrivals: sim.Diurnal{Base: 10, Amplitude: 8, Period: time.Hour}, Keys: sim.ZipfKeys("page", 10000, 1.1)}))
+	uniform := sim.Workload("uniform", sim.Generate(simStart, time.Hour, 2,
+		sim.Client{Name: "scan", Arrivals: sim.Poisson{Rate: 10}, Keys: sim.UniformKeys("page", 10000)}))
+
+	for _, p := range cache.Policies() {
+		rates := map[string]float64{}
+		for _, w := range []string{"zipf", "uniform"} {
+			ops := skewed.Operations
+			if w == "uniform" {
+				ops = uniform.Operations
+			}
+			c := p.MustNew(500)
+			for _, op := range ops {
+				if _, ok := c.Get(op.Key); !ok {
+					c.Put(op.Key, op.Key)
+				}
+			}
+			rates[w] = c.Stats().HitRate()
+		}
+		if rates["zipf"] < 0.3 || rates["uniform"] > 0.1 {
+			t.Errorf("%s: expected hit rates above 0.3 on zipf and below 0.1 on uniform, got %.3f and %.3f", p.Name, rates["zipf"], rates["uniform"])
+		}
+	}
+}
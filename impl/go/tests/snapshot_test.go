@@ -0,0 +1,78 @@
+package tests
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/diskhash"
+	"github.com/dsa-lab/go/internal/lsm"
+	"github.com/dsa-lab/go/internal/merkle"
+)
+
+// store is the subset of operations shared by the disk-backed structures.
+type store interface {
+	Range(f func(key, value string) bool) error
+	Close() error
+}
+
+// TestSnapshotRootsSurviveReopen writes the same data to each disk-backed
+// structure, and checks that the Merkle root of its contents is the same
+// for every structure and unchanged by closing and reopening it.
+func TestSnapshotRootsSurviveReopen(t *testing.T) {
+	var want []merkle.Pair
+	for i := 0; i < 2000; i++ {
+		want = append(want, merkle.Pair{Key: fmt.Sprint("key", i), Value: fmt.Sprint(i * i)})
+	}
+	root := merkle.New(want).Root()
+
+	stores := map[string]func(dir string) (store, error){
+		"diskhash": func(dir string) (store, error) {
+			d, err := diskhash.Open(filepath.Join(dir, "index"))
+			return d, err
+		},
+		"lsm": func(dir string) (store, error) {
+			db, err := lsm.Open(dir, lsm.WithMemtableSize(4<<10))
+			return db, err
+		},
+	}
+	for name, open := range stores {
+		dir := t.TempDir()
+		s, err := open(dir)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		for _, p := range want {
+			switch s := s.(type) {
+			case *diskhash.DiskHash:
+				_, _, err = s.Insert(p.Key, p.Value)
+			case *lsm.DB:
+				err = s.Put(p.Key, p.Value)
+			}
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+		}
+
+		for round := 0; round < 2; round++ {
+			tree, err := merkle.FromRange(s.Range)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if tree.Root() != root {
+				t.Fatalf("%s: snapshot root differs from the data written (round %d)", name, round)
+			}
+			proof, _ := tree.Prove("key42")
+			if !merkle.Verify(root, proof) || proof.Value != "1764" {
+				t.Errorf("%s: expected a verified proof of key42=1764", name)
+			}
+			if err := s.Close(); err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if s, err = open(dir); err != nil {
+				t.Fatalf("%s: reopen: %v", name, err)
+			}
+		}
+		s.Close()
+	}
+}
@@ -0,0 +1,141 @@
+package tests
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/spatial"
+	"github.com/dsa-lab/go/internal/workload"
+)
+
+// spatialExtent matches SPATIAL_EXTENT in tools/gen_workloads.py.
+var spatialExtent = spatial.Rect{Min: spatial.Point{0, 0}, Max: spatial.Point{1000, 1000}}
+
+// TestSpatialWorkloadOracle replays the generated spatial workloads against
+// the quadtree (points) and R-tree (rects) and checks every window query
+// against a linear scan of the live objects.
+func TestSpatialWorkloadOracle(t *testing.T) {
+	paths, err := filepath.Glob("../../../workloads/spatial/*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Skip("no spatial workloads found; run `just gen`")
+	}
+
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), ".json")
+		if strings.HasSuffix(name, "_large") {
+			continue
+		}
+		w, err := workload.Load(path)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		t.Run(name, func(t *testing.T) {
+			if strings.HasPrefix(name, "points") {
+				replaySpatialPoints(t, w)
+			} else {
+				replaySpatialRects(t, w)
+			}
+		})
+	}
+}
+
+func replaySpatialPoints(t *testing.T, w *workload.Workload) {
+	q, err := spatial.NewQuadtree[string](spatialExtent, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	live := make(map[string]spatial.Point)
+	for i, op := range w.Operations {
+		switch op.Op {
+		case workload.OpInsert:
+			p, err := spatial.ParsePoint(op.Value)
+			if err != nil {
+				t.Fatalf("operation %d: %v", i, err)
+			}
+			if err := q.Insert(p, op.Key); err != nil {
+				t.Fatalf("operation %d: %v", i, err)
+			}
+			live[op.Key] = p
+		case workload.OpDelete:
+			if !q.Remove(live[op.Key], op.Key) {
+				t.Fatalf("operation %d: delete %s found nothing", i, op.Key)
+			}
+			delete(live, op.Key)
+		case spatial.OpQuery:
+			window, err := spatial.ParseRect(op.Value)
+			if err != nil {
+				t.Fatalf("operation %d: %v", i, err)
+			}
+			var got, want []string
+			q.Search(window, func(_ spatial.Point, id string) bool {
+				got = append(got, id)
+				return true
+			})
+			for id, p := range live {
+				if window.Contains(p) {
+					want = append(want, id)
+				}
+			}
+			compareIDs(t, i, got, want)
+		}
+	}
+	if err := q.CheckInvariants(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func replaySpatialRects(t *testing.T, w *workload.Workload) {
+	tree := spatial.MustNewRTree[string](2, 0)
+	live := make(map[string]spatial.Rect)
+	for i, op := range w.Operations {
+		switch op.Op {
+		case workload.OpInsert:
+			r, err := spatial.ParseRect(op.Value)
+			if err != nil {
+				t.Fatalf("operation %d: %v", i, err)
+			}
+			if err := tree.Insert(r, op.Key); err != nil {
+				t.Fatalf("operation %d: %v", i, err)
+			}
+			live[op.Key] = r
+		case workload.OpDelete:
+			if !tree.Remove(live[op.Key], op.Key) {
+				t.Fatalf("operation %d: delete %s found nothing", i, op.Key)
+			}
+			delete(live, op.Key)
+		case spatial.OpQuery:
+			window, err := spatial.ParseRect(op.Value)
+			if err != nil {
+				t.Fatalf("operation %d: %v", i, err)
+			}
+			var got, want []string
+			tree.Search(window, func(_ spatial.Rect, id string) bool {
+				got = append(got, id)
+				return true
+			})
+			for id, r := range live {
+				if window.Intersects(r) {
+					want = append(want, id)
+				}
+			}
+			compareIDs(t, i, got, want)
+		}
+	}
+	if err := tree.CheckInvariants(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func compareIDs(t *testing.T, op int, got, want []string) {
+	t.Helper()
+	sort.Strings(got)
+	sort.Strings(want)
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("operation %d: expected %d results %v, got %d %v", op, len(want), want, len(got), got)
+	}
+}
@@ -0,0 +1,64 @@
+package tests
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dsa-lab/go/internal/hashmap"
+	"github.com/dsa-lab/go/internal/kv"
+	"github.com/dsa-lab/go/internal/trace"
+)
+
+// TestRegressionTraces replays every trace in testdata/traces against all
+// registered implementations. Minimized traces from failing randomized tests
+// belong in that directory.
+func TestRegressionTraces(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "traces", "*.trace"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no regression traces found")
+	}
+
+	impls := append(kv.Implementations(), kv.Implementation{
+		Name: "hashmap_incremental",
+		New:  func() kv.Map { return hashmap.MustNew(hashmap.WithIncrementalResize(1)) },
+	})
+
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		events, err := trace.Read(f)
+		f.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+
+		for _, impl := range impls {
+			t.Run(filepath.Base(path)+"/"+impl.Name, func(t *testing.T) {
+				if err := trace.Replay(impl.New(), events); err != nil {
+					t.Error(err)
+				}
+			})
+		}
+	}
+}
+
+// reportTrace logs a minimized trace of a failing randomized run in the
+// regression trace format, ready to be saved under testdata/traces.
+func reportTrace(t *testing.T, tracer *trace.Tracer, newMap func() kv.Map) {
+	t.Helper()
+	if !t.Failed() {
+		return
+	}
+	var buf bytes.Buffer
+	if err := trace.Write(&buf, trace.Minimize(tracer.Events(), newMap)); err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("minimized trace:\n%s", buf.String())
+}
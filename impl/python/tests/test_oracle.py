@@ -1,8 +1,15 @@
 """Oracle tests comparing HashMap against built-in dict."""
 
+import json
 import random
+from pathlib import Path
+
+import pytest
+
 from dsa_lab import HashMap
 
+WORKLOADS_DIR = Path(__file__).resolve().parents[3] / "workloads" / "map"
+
 
 class TestOracleComparison:
     """Compare HashMap against Python's built-in dict."""
@@ -94,3 +101,29 @@ class TestOracleComparison:
                 assert our_result == std_result
 
         assert len(our_map) == len(std_map)
+
+
+class TestWorkloadConformance:
+    """Check HashMap against the expected results embedded in the workloads."""
+
+    @pytest.mark.parametrize(
+        "path",
+        sorted(WORKLOADS_DIR.glob("*_small.json")),
+        ids=lambda p: p.stem,
+    )
+    def test_expected_results(self, path: Path) -> None:
+        with open(path) as f:
+            workload = json.load(f)
+
+        our_map = HashMap()
+        for i, op in enumerate(workload["operations"]):
+            if op["op"] == "insert":
+                result = our_map.insert(op["key"], op["value"])
+            elif op["op"] == "get":
+                result = our_map.get(op["key"])
+            else:
+                result = our_map.remove(op["key"])
+
+            expect = op["expect"]
+            expected = expect.get("value", "") if expect["found"] else None
+            assert result == expected, f"operation {i}: {op}"
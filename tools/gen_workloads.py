@@ -6,6 +6,7 @@ Generates deterministic workload files for benchmarking hash map implementations
 All workloads use fixed seeds for reproducibility.
 """
 
+import argparse
 import json
 import random
 import math
@@ -35,6 +36,25 @@ SIZES = {
 OP_INSERT = "insert"
 OP_GET = "get"
 OP_DELETE = "delete"
+OP_SCAN = "scan"
+
+# Leaderboard operation types
+OP_UPDATE = "update"
+OP_RANK = "rank"
+OP_TOP = "top"
+
+LEADERBOARD_SEED = 50
+
+# Spatial operation types
+OP_QUERY = "query"
+
+SPATIAL_SEED = 60
+
+RANGE_SEED = 70
+RANGE_SIZES = {name: SIZES[name] for name in ("small", "medium")}
+RANGE_MAX_WIDTH = 100
+SPATIAL_EXTENT = 1000.0
+SPATIAL_CLUSTERS = 10
 
 
 def zipf_distribution(n: int, s: float = 1.0, seed: int = 0) -> List[int]:
@@ -96,6 +116,49 @@ def generate_values(n: int, seed: int) -> List[str]:
     return [f"value_{rng.randint(0, 1_000_000)}" for _ in range(n)]
 
 
+def annotate_expected(operations: List[Dict[str, Any]]) -> None:
+    """
+    Attach the expected result to every map operation, in place.
+
+    Operations are replayed against a dict. Each gains an "expect" object with
+    "found" and, when found, "value": the previous value for insert, the
+    current value for get and the removed value for delete. A scan over the
+    half-open range [key, value) instead expects "count", the number of keys
+    in range, with "found" set when it is non-zero. This turns a workload into
+    a conformance suite any implementation can check itself against.
+    """
+    model: Dict[str, str] = {}
+    for op in operations:
+        key = op["key"]
+        if op["op"] == OP_SCAN:
+            count = sum(1 for k in model if key <= k < op["value"])
+            op["expect"] = {"found": count > 0, "count": count} if count else {"found": False}
+            continue
+
+        found = key in model
+        expect: Dict[str, Any] = {"found": found}
+        if found:
+            expect["value"] = model[key]
+
+        if op["op"] == OP_INSERT:
+            model[key] = op["value"]
+        elif op["op"] == OP_DELETE:
+            model.pop(key, None)
+        elif op["op"] != OP_GET:
+            raise ValueError(f"Unknown map operation: {op['op']}")
+
+        op["expect"] = expect
+
+
+def annotate_file(path: Path) -> None:
+    """Add expected results to an existing map workload file in place."""
+    with open(path) as f:
+        workload = json.load(f)
+    annotate_expected(workload["operations"])
+    with open(path, "w") as f:
+        json.dump(workload, f, indent=2)
+
+
 def generate_workload(
     name: str,
     size: int,
@@ -168,6 +231,8 @@ def generate_workload(
                 "key": key,
             })
 
+    annotate_expected(operations)
+
     return {
         "name": name,
         "description": f"{name} workload with {distribution} key distribution",
@@ -179,8 +244,267 @@ def generate_workload(
     }
 
 
+def generate_leaderboard_workload(name: str, size: int, seed: int) -> Dict[str, Any]:
+    """
+    Generate a score-update workload for the leaderboard example.
+
+    Players are drawn from a Zipf distribution so a few active players
+    dominate updates. Update values are score deltas; top values are page sizes.
+
+    Args:
+        name: Workload name
+        size: Number of operations
+        seed: Random seed
+
+    Returns:
+        Workload specification dict
+    """
+    rng = random.Random(seed)
+    op_weights = {OP_UPDATE: 0.80, OP_RANK: 0.15, OP_TOP: 0.05}
+    players = [f"player_{i}" for i in zipf_distribution(size, s=1.0, seed=seed)]
+
+    operations = []
+    seen = []
+    seen_set = set()
+
+    for i in range(size):
+        r = rng.random()
+        if r < op_weights[OP_UPDATE] or not seen:
+            player = players[i]
+            operations.append({
+                "op": OP_UPDATE,
+                "key": player,
+                "value": str(rng.randint(1, 100)),
+            })
+            if player not in seen_set:
+                seen_set.add(player)
+                seen.append(player)
+        elif r < op_weights[OP_UPDATE] + op_weights[OP_RANK]:
+            operations.append({
+                "op": OP_RANK,
+                "key": rng.choice(seen),
+            })
+        else:
+            operations.append({
+                "op": OP_TOP,
+                "key": "",
+                "value": str(rng.choice([10, 50, 100])),
+            })
+
+    return {
+        "name": name,
+        "description": f"{name} leaderboard score-update workload",
+        "size": size,
+        "distribution": "zipf",
+        "operation_weights": op_weights,
+        "seed": seed,
+        "operations": operations,
+    }
+
+
+def generate_leaderboard_workloads(root: Path) -> List[str]:
+    """Generate leaderboard workloads for every size into workloads/leaderboard."""
+    out_dir = root / "workloads" / "leaderboard"
+    out_dir.mkdir(parents=True, exist_ok=True)
+
+    generated = []
+    for size_name, size in SIZES.items():
+        name = f"score_updates_{size_name}"
+        print(f"Generating {name}...")
+        workload = generate_leaderboard_workload(name, size, LEADERBOARD_SEED + size)
+
+        filename = f"{name}.json"
+        with open(out_dir / filename, "w") as f:
+            json.dump(workload, f, indent=2)
+        generated.append(filename)
+
+    return generated
+
+
+def generate_range_workload(name: str, size: int, seed: int) -> Dict[str, Any]:
+    """
+    Generate an insert/get/delete/scan workload for ordered maps.
+
+    Keys are uniform. Each scan covers the half-open range between two keys of
+    the sorted key universe at most RANGE_MAX_WIDTH apart, so scans visit a
+    bounded number of live keys regardless of workload size.
+
+    Args:
+        name: Workload name
+        size: Number of operations
+        seed: Random seed
+
+    Returns:
+        Workload specification dict
+    """
+    rng = random.Random(seed)
+    op_weights = {OP_INSERT: 0.50, OP_GET: 0.20, OP_DELETE: 0.10, OP_SCAN: 0.20}
+    keys = generate_keys(size, "uniform", seed)
+    values = generate_values(size, seed + 1000)
+    universe = sorted(set(keys))
+
+    operations = []
+    for i in range(size):
+        r = rng.random()
+        if r < op_weights[OP_INSERT]:
+            operations.append({"op": OP_INSERT, "key": keys[i], "value": values[i]})
+        elif r < op_weights[OP_INSERT] + op_weights[OP_GET]:
+            operations.append({"op": OP_GET, "key": rng.choice(keys[: i + 1])})
+        elif r < 1 - op_weights[OP_SCAN]:
+            operations.append({"op": OP_DELETE, "key": rng.choice(keys[: i + 1])})
+        else:
+            lo = rng.randrange(len(universe))
+            hi = min(lo + rng.randint(1, RANGE_MAX_WIDTH), len(universe) - 1)
+            operations.append({"op": OP_SCAN, "key": universe[lo], "value": universe[hi]})
+
+    annotate_expected(operations)
+
+    return {
+        "name": name,
+        "description": f"{name} ordered-map workload with bounded range scans",
+        "size": size,
+        "distribution": "uniform",
+        "operation_weights": op_weights,
+        "seed": seed,
+        "operations": operations,
+    }
+
+
+def generate_range_workloads(root: Path) -> List[str]:
+    """Generate range-scan workloads into workloads/range."""
+    out_dir = root / "workloads" / "range"
+    out_dir.mkdir(parents=True, exist_ok=True)
+
+    generated = []
+    for size_name, size in RANGE_SIZES.items():
+        name = f"scan_uniform_{size_name}"
+        print(f"Generating {name}...")
+        workload = generate_range_workload(name, size, RANGE_SEED + size)
+
+        filename = f"{name}.json"
+        with open(out_dir / filename, "w") as f:
+            json.dump(workload, f, indent=2)
+        generated.append(filename)
+
+    return generated
+
+
+def spatial_point(rng: random.Random, centres: List[Tuple[float, float]]) -> Tuple[float, float]:
+    """Draw a point near a random cluster centre, clamped to the extent."""
+    cx, cy = rng.choice(centres)
+    x = min(max(rng.gauss(cx, SPATIAL_EXTENT * 0.03), 0.0), SPATIAL_EXTENT)
+    y = min(max(rng.gauss(cy, SPATIAL_EXTENT * 0.03), 0.0), SPATIAL_EXTENT)
+    return x, y
+
+
+def format_coords(*coords: float) -> str:
+    return ",".join(f"{c:.2f}" for c in coords)
+
+
+def generate_spatial_workload(name: str, shape: str, size: int, seed: int) -> Dict[str, Any]:
+    """
+    Generate an insert/delete/window-query workload over 2-D points or rects.
+
+    Geometry is clustered around a handful of Gaussian centres, as real
+    location data is. Keys are object ids; insert values are "x,y" for points
+    or "minx,miny,maxx,maxy" for rects, delete removes a live id, and query
+    values are windows in rect form.
+
+    Args:
+        name: Workload name
+        shape: "points" or "rects"
+        size: Number of operations
+        seed: Random seed
+
+    Returns:
+        Workload specification dict
+    """
+    rng = random.Random(seed)
+    op_weights = {OP_INSERT: 0.60, OP_QUERY: 0.30, OP_DELETE: 0.10}
+    centres = [
+        (rng.uniform(0, SPATIAL_EXTENT), rng.uniform(0, SPATIAL_EXTENT))
+        for _ in range(SPATIAL_CLUSTERS)
+    ]
+
+    operations = []
+    live: List[str] = []
+    next_id = 0
+
+    for _ in range(size):
+        r = rng.random()
+        if r < op_weights[OP_INSERT] or not live:
+            x, y = spatial_point(rng, centres)
+            if shape == "points":
+                value = format_coords(x, y)
+            else:
+                w, h = rng.uniform(1, 20), rng.uniform(1, 20)
+                value = format_coords(x, y, min(x + w, SPATIAL_EXTENT), min(y + h, SPATIAL_EXTENT))
+            key = f"obj_{next_id}"
+            next_id += 1
+            live.append(key)
+            operations.append({"op": OP_INSERT, "key": key, "value": value})
+        elif r < op_weights[OP_INSERT] + op_weights[OP_QUERY]:
+            x, y = spatial_point(rng, centres)
+            side = rng.uniform(10, 100)
+            operations.append({
+                "op": OP_QUERY,
+                "key": "",
+                "value": format_coords(x, y, min(x + side, SPATIAL_EXTENT), min(y + side, SPATIAL_EXTENT)),
+            })
+        else:
+            # Swap-remove keeps the draw O(1) without biasing towards any id.
+            i = rng.randrange(len(live))
+            live[i], live[-1] = live[-1], live[i]
+            operations.append({"op": OP_DELETE, "key": live.pop()})
+
+    return {
+        "name": name,
+        "description": f"{name} clustered 2-D {shape} workload",
+        "size": size,
+        "distribution": "clustered",
+        "operation_weights": op_weights,
+        "seed": seed,
+        "operations": operations,
+    }
+
+
+def generate_spatial_workloads(root: Path) -> List[str]:
+    """Generate point and rect workloads for every size into workloads/spatial."""
+    out_dir = root / "workloads" / "spatial"
+    out_dir.mkdir(parents=True, exist_ok=True)
+
+    generated = []
+    for offset, shape in enumerate(["points", "rects"]):
+        for size_name, size in SIZES.items():
+            name = f"{shape}_clustered_{size_name}"
+            print(f"Generating {name}...")
+            workload = generate_spatial_workload(name, shape, size, SPATIAL_SEED + offset + size)
+
+            filename = f"{name}.json"
+            with open(out_dir / filename, "w") as f:
+                json.dump(workload, f, indent=2)
+            generated.append(filename)
+
+    return generated
+
+
 def main():
     """Generate all workloads."""
+    parser = argparse.ArgumentParser(description=__doc__)
+    parser.add_argument(
+        "--annotate",
+        nargs="+",
+        type=Path,
+        metavar="FILE",
+        help="add expected results to existing map workloads instead of generating",
+    )
+    args = parser.parse_args()
+    if args.annotate:
+        for path in args.annotate:
+            print(f"Annotating {path}...")
+            annotate_file(path)
+        return
+
     root = Path(__file__).parent.parent
     workloads_dir = root / "workloads" / "map"
     workloads_dir.mkdir(parents=True, exist_ok=True)
@@ -240,6 +564,15 @@ def main():
     print(f"\nGenerated {len(generated)} workloads in {workloads_dir}")
     print("Manifest written to manifest.json")
 
+    leaderboard = generate_leaderboard_workloads(root)
+    print(f"Generated {len(leaderboard)} leaderboard workloads")
+
+    spatial = generate_spatial_workloads(root)
+    print(f"Generated {len(spatial)} spatial workloads")
+
+    ranges = generate_range_workloads(root)
+    print(f"Generated {len(ranges)} range workloads")
+
 
 if __name__ == "__main__":
     main()
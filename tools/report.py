@@ -70,6 +70,15 @@ def parse_google_bench_results(json_file: Path) -> Dict[str, Any]:
     return results
 
 
+# Go benchmark metrics reported alongside ns/op, keyed by their unit.
+GO_MEMORY_METRICS = {
+    "B/op": "bytes_per_op",
+    "allocs/op": "allocs_per_op",
+    "gc/op": "gc_per_op",
+    "peak-heap-B": "peak_heap_bytes",
+}
+
+
 def parse_go_bench_results(txt_file: Path) -> Dict[str, Any]:
     """Parse Go benchmark text output."""
     results = {}
@@ -79,9 +88,9 @@ def parse_go_bench_results(txt_file: Path) -> Dict[str, Any]:
 
     with open(txt_file) as f:
         for line in f:
-            # Match: BenchmarkName-8    1000000    1234 ns/op    456 B/op    7 allocs/op
+            # Match: BenchmarkName/impl=x-8    1000000    1234 ns/op    456 B/op    7 allocs/op
             match = re.match(
-                r"(Benchmark\w+)[-\d]*\s+\d+\s+([\d.]+)\s+(ns|us|ms)/op",
+                r"(Benchmark\S+?)(?:-\d+)?\s+\d+\s+([\d.]+)\s+(ns|us|ms)/op",
                 line
             )
             if match:
@@ -101,6 +110,10 @@ def parse_go_bench_results(txt_file: Path) -> Dict[str, Any]:
                     "mean_ms": time_val / 1_000_000,
                 }
 
+                # Memory columns from -benchmem and the harness's own metrics
+                for value, metric in re.findall(r"([\d.]+)\s+(B/op|allocs/op|gc/op|peak-heap-B)", line):
+                    results[name][GO_MEMORY_METRICS[metric]] = float(value)
+
     return results
 
 
@@ -140,6 +153,24 @@ def format_time(ns: float) -> str:
         return f"{ns/1_000_000_000:.2f} s"
 
 
+
+def format_bytes(n: Optional[float]) -> str:
+    """Format a byte count in binary units, or '-' when not measured."""
+    if n is None:
+        return "-"
+    for unit in ("B", "KiB", "MiB"):
+        if n < 1024:
+            return f"{n:.0f} {unit}" if unit == "B" else f"{n:.1f} {unit}"
+        n /= 1024
+    return f"{n:.1f} GiB"
+
+
+def format_count(n: Optional[float]) -> str:
+    """Format a per-op count, or '-' when not measured."""
+    if n is None:
+        return "-"
+    return f"{n:g}"
+
 def load_env_info(env_file: Path) -> Dict[str, str]:
     """Load environment info from JSON file."""
     if not env_file.exists():
@@ -245,11 +276,17 @@ def generate_report(root: Path) -> str:
         lines.extend([
             "## Go (testing.B)",
             "",
-            "| Benchmark | Mean Time |",
-            "|-----------|-----------|",
+            "| Benchmark | Mean Time | Bytes/op | Allocs/op | Peak Heap | GC/op |",
+            "|-----------|-----------|----------|-----------|-----------|-------|",
         ])
         for name, data in sorted(go_results.items()):
-            lines.append(f"| {name} | {format_time(data['mean_ns'])} |")
+            lines.append(
+                f"| {name} | {format_time(data['mean_ns'])} "
+                f"| {format_bytes(data.get('bytes_per_op'))} "
+                f"| {format_count(data.get('allocs_per_op'))} "
+                f"| {format_bytes(data.get('peak_heap_bytes'))} "
+                f"| {format_count(data.get('gc_per_op'))} |"
+            )
         lines.append("")
 
     if python_results: